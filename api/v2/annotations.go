@@ -16,7 +16,11 @@ limitations under the License.
 
 package v2
 
-import "github.com/fluxcd/pkg/apis/meta"
+import (
+	"strings"
+
+	"github.com/fluxcd/pkg/apis/meta"
+)
 
 const (
 	// ForceRequestAnnotation is the annotation used for triggering a one-off forced
@@ -30,8 +34,74 @@ const (
 	// The value is interpreted as a token, and must equal the value of
 	// meta.ReconcileRequestAnnotation in order to reset the failure counts.
 	ResetRequestAnnotation string = "reconcile.fluxcd.io/resetAt"
+
+	// RollbackRequestAnnotation is the annotation used for triggering a
+	// one-off rollback of the Helm release to the previous release,
+	// independent of the remediation strategy of the HelmRelease.
+	// The value is interpreted as a token, and must equal the value of
+	// meta.ReconcileRequestAnnotation in order to trigger a rollback.
+	RollbackRequestAnnotation string = "reconcile.fluxcd.io/rollbackAt"
+
+	// RestoreRequestAnnotation is the annotation used for triggering a
+	// one-off restore of the Helm release from the latest backup recorded
+	// in the history of the HelmRelease, which is taken before an upgrade
+	// when Upgrade.Backup is enabled.
+	// The value is interpreted as a token, and must equal the value of
+	// meta.ReconcileRequestAnnotation in order to trigger a restore.
+	RestoreRequestAnnotation string = "reconcile.fluxcd.io/restoreAt"
+
+	// ForcePullRequestAnnotation is the annotation used for triggering a
+	// one-off fresh pull of the chart artifact, bypassing any cache.
+	// The value is interpreted as a token, and must equal the value of
+	// meta.ReconcileRequestAnnotation in order to trigger a pull.
+	ForcePullRequestAnnotation string = "reconcile.fluxcd.io/forcePull"
+
+	// TestRequestAnnotation is the annotation used for triggering a one-off
+	// run of the tests of the current Helm release, without performing any
+	// other Helm action.
+	// The value is interpreted as a token, and must equal the value of
+	// meta.ReconcileRequestAnnotation in order to trigger the tests.
+	TestRequestAnnotation string = "reconcile.fluxcd.io/testAt"
+
+	// SummaryPriorityAnnotation is the annotation used for configuring the
+	// priority of the Remediated, TestSuccess and Released conditions when
+	// they are summarized into the Ready condition. The value is a
+	// comma-separated list of condition types in order of descending
+	// priority, condition types which are not listed follow in their default
+	// order.
+	SummaryPriorityAnnotation string = "helm.toolkit.fluxcd.io/summaryPriority"
+
+	// PauseAnnotation is the annotation used for pausing the reconciliation
+	// of a HelmRelease. While the value is "true", no Helm actions are
+	// performed, but drift is still detected and reported.
+	PauseAnnotation string = "reconcile.fluxcd.io/pause"
+
+	// PruneAnnotation is the annotation used on the resources of a Helm
+	// release to exclude them from being pruned when Upgrade.Prune is
+	// enabled, by setting it to PruneDisabledValue.
+	PruneAnnotation string = "helm.toolkit.fluxcd.io/prune"
+
+	// PruneDisabledValue is the value of the PruneAnnotation which excludes
+	// a resource from being pruned.
+	PruneDisabledValue string = "disabled"
+
+	// EmbeddedChartLabel is the label used on a ConfigMap or Secret holding
+	// an embedded chart, by setting it to EmbeddedChartEnabledValue. Unless
+	// ConfigMaps and Secrets are cached, only the ones with this label are
+	// watched for changes.
+	EmbeddedChartLabel string = "helm.toolkit.fluxcd.io/embedded-chart"
+
+	// EmbeddedChartEnabledValue is the value of the EmbeddedChartLabel which
+	// enables watching a ConfigMap or Secret for changes.
+	EmbeddedChartEnabledValue string = "enabled"
 )
 
+// IsPaused returns true if the reconciliation of the HelmRelease is paused
+// using the PauseAnnotation.
+func IsPaused(obj *HelmRelease) bool {
+	return strings.EqualFold(obj.GetAnnotations()[PauseAnnotation], "true")
+}
+
 // ShouldHandleResetRequest returns true if the HelmRelease has a reset request
 // annotation, and the value of the annotation matches the value of the
 // meta.ReconcileRequestAnnotation annotation.
@@ -56,6 +126,67 @@ func ShouldHandleForceRequest(obj *HelmRelease) bool {
 	return handleRequest(obj, ForceRequestAnnotation, &obj.Status.LastHandledForceAt)
 }
 
+// ShouldHandleRollbackRequest returns true if the HelmRelease has a rollback
+// request annotation, and the value of the annotation matches the value of the
+// meta.ReconcileRequestAnnotation annotation.
+//
+// To ensure that the rollback request is handled only once, the value of
+// HelmReleaseStatus.LastHandledRollbackAt is updated to match the value of
+// the rollback request annotation (even if the rollback request is not
+// handled because the value of the meta.ReconcileRequestAnnotation annotation
+// does not match).
+func ShouldHandleRollbackRequest(obj *HelmRelease) bool {
+	return handleRequest(obj, RollbackRequestAnnotation, &obj.Status.LastHandledRollbackAt)
+}
+
+// ShouldHandleRestoreRequest returns true if the HelmRelease has a restore
+// request annotation, and the value of the annotation matches the value of the
+// meta.ReconcileRequestAnnotation annotation.
+//
+// To ensure that the restore request is handled only once, the value of
+// HelmReleaseStatus.LastHandledRestoreAt is updated to match the value of
+// the restore request annotation (even if the restore request is not
+// handled because the value of the meta.ReconcileRequestAnnotation annotation
+// does not match).
+func ShouldHandleRestoreRequest(obj *HelmRelease) bool {
+	return handleRequest(obj, RestoreRequestAnnotation, &obj.Status.LastHandledRestoreAt)
+}
+
+// PendingForcePullRequest returns the value of the force pull request
+// annotation of the HelmRelease, and true if the request has not been handled
+// yet, and the value of the annotation matches the value of the
+// meta.ReconcileRequestAnnotation annotation.
+//
+// Unlike the other requests, a force pull request spans multiple
+// reconciliations, as it waits for the chart source to rebuild its artifact.
+// The caller must therefore update HelmReleaseStatus.LastHandledForcePullAt
+// to the returned value once the request has been handled. A request of which
+// the value does not match the meta.ReconcileRequestAnnotation annotation is
+// recorded as handled right away, to not act on it later.
+func PendingForcePullRequest(obj *HelmRelease) (string, bool) {
+	requestAt, requestOk := obj.GetAnnotations()[ForcePullRequestAnnotation]
+	if !requestOk || requestAt == obj.Status.LastHandledForcePullAt {
+		return "", false
+	}
+	if reconcileAt, reconcileOk := meta.ReconcileAnnotationValue(obj.GetAnnotations()); !reconcileOk || requestAt != reconcileAt {
+		obj.Status.LastHandledForcePullAt = requestAt
+		return "", false
+	}
+	return requestAt, true
+}
+
+// ShouldHandleTestRequest returns true if the HelmRelease has a test request
+// annotation, and the value of the annotation matches the value of the
+// meta.ReconcileRequestAnnotation annotation.
+//
+// To ensure that the test request is handled only once, the value of
+// HelmReleaseStatus.LastHandledTestAt is updated to match the value of the
+// test request annotation (even if the test request is not handled because
+// the value of the meta.ReconcileRequestAnnotation annotation does not match).
+func ShouldHandleTestRequest(obj *HelmRelease) bool {
+	return handleRequest(obj, TestRequestAnnotation, &obj.Status.LastHandledTestAt)
+}
+
 // handleRequest returns true if the HelmRelease has a request annotation, and
 // the value of the annotation matches the value of the meta.ReconcileRequestAnnotation
 // annotation.
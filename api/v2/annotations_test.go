@@ -163,3 +163,145 @@ func Test_handleRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestShouldHandleRollbackRequest(t *testing.T) {
+	t.Run("should handle rollback request", func(t *testing.T) {
+		obj := &HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					meta.ReconcileRequestAnnotation: "b",
+					RollbackRequestAnnotation:       "b",
+				},
+			},
+			Status: HelmReleaseStatus{
+				LastHandledRollbackAt: "a",
+				ReconcileRequestStatus: meta.ReconcileRequestStatus{
+					LastHandledReconcileAt: "a",
+				},
+			},
+		}
+
+		if !ShouldHandleRollbackRequest(obj) {
+			t.Error("ShouldHandleRollbackRequest() = false")
+		}
+
+		if obj.Status.LastHandledRollbackAt != "b" {
+			t.Error("ShouldHandleRollbackRequest did not update LastHandledRollbackAt")
+		}
+
+		if ShouldHandleRollbackRequest(obj) {
+			t.Error("ShouldHandleRollbackRequest() = true for already handled request")
+		}
+	})
+}
+
+func TestShouldHandleTestRequest(t *testing.T) {
+	t.Run("should handle test request", func(t *testing.T) {
+		obj := &HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					meta.ReconcileRequestAnnotation: "b",
+					TestRequestAnnotation:           "b",
+				},
+			},
+			Status: HelmReleaseStatus{
+				LastHandledTestAt: "a",
+				ReconcileRequestStatus: meta.ReconcileRequestStatus{
+					LastHandledReconcileAt: "a",
+				},
+			},
+		}
+
+		if !ShouldHandleTestRequest(obj) {
+			t.Error("ShouldHandleTestRequest() = false")
+		}
+
+		if obj.Status.LastHandledTestAt != "b" {
+			t.Error("ShouldHandleTestRequest did not update LastHandledTestAt")
+		}
+
+		if ShouldHandleTestRequest(obj) {
+			t.Error("ShouldHandleTestRequest() = true for already handled request")
+		}
+	})
+}
+
+func TestPendingForcePullRequest(t *testing.T) {
+	t.Run("returns pending force pull request", func(t *testing.T) {
+		obj := &HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					meta.ReconcileRequestAnnotation: "b",
+					ForcePullRequestAnnotation:      "b",
+				},
+			},
+			Status: HelmReleaseStatus{
+				LastHandledForcePullAt: "a",
+				ReconcileRequestStatus: meta.ReconcileRequestStatus{
+					LastHandledReconcileAt: "b",
+				},
+			},
+		}
+
+		if token, ok := PendingForcePullRequest(obj); !ok || token != "b" {
+			t.Errorf("PendingForcePullRequest() = %q, %v", token, ok)
+		}
+
+		if obj.Status.LastHandledForcePullAt != "a" {
+			t.Error("PendingForcePullRequest updated LastHandledForcePullAt of pending request")
+		}
+
+		obj.Status.LastHandledForcePullAt = "b"
+		if _, ok := PendingForcePullRequest(obj); ok {
+			t.Error("PendingForcePullRequest() = true for already handled request")
+		}
+	})
+
+	t.Run("records mismatching force pull request as handled", func(t *testing.T) {
+		obj := &HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					meta.ReconcileRequestAnnotation: "b",
+					ForcePullRequestAnnotation:      "c",
+				},
+			},
+			Status: HelmReleaseStatus{
+				LastHandledForcePullAt: "a",
+			},
+		}
+
+		if _, ok := PendingForcePullRequest(obj); ok {
+			t.Error("PendingForcePullRequest() = true for mismatching request")
+		}
+
+		if obj.Status.LastHandledForcePullAt != "c" {
+			t.Error("PendingForcePullRequest did not update LastHandledForcePullAt")
+		}
+	})
+}
+
+func TestIsPaused(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        bool
+	}{
+		{name: "without annotation"},
+		{name: "paused", annotations: map[string]string{PauseAnnotation: "true"}, want: true},
+		{name: "paused (case-insensitive)", annotations: map[string]string{PauseAnnotation: "True"}, want: true},
+		{name: "not paused", annotations: map[string]string{PauseAnnotation: "false"}},
+		{name: "invalid value", annotations: map[string]string{PauseAnnotation: "yes"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tt.annotations,
+				},
+			}
+			if got := IsPaused(obj); got != tt.want {
+				t.Errorf("IsPaused() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -29,6 +29,23 @@ const (
 	// (uninstall/rollback) due to a failure of the last release attempt against the
 	// latest desired state.
 	RemediatedCondition string = "Remediated"
+
+	// ChartDeprecatedCondition represents the fact that the chart of the last
+	// release attempt is marked as deprecated in its metadata.
+	ChartDeprecatedCondition string = "ChartDeprecated"
+
+	// SourceSuspendedCondition represents the fact that the source of the
+	// chart of the HelmRelease is suspended.
+	SourceSuspendedCondition string = "SourceSuspended"
+
+	// ManifestExportedCondition represents the status of the export of the
+	// manifest of the latest successful Helm release to a ConfigMap.
+	ManifestExportedCondition string = "ManifestExported"
+
+	// HistoryLimitReachedCondition represents the fact that the release
+	// history of the HelmRelease has reached its MaxHistory, and the release
+	// a rollback would target is pruned by the next Helm release action.
+	HistoryLimitReachedCondition string = "HistoryLimitReached"
 )
 
 const (
@@ -64,6 +81,19 @@ const (
 	// HelmRelease failed.
 	RollbackFailedReason string = "RollbackFailed"
 
+	// ManualRollbackSucceededReason represents the fact that the Helm rollback
+	// requested through the RollbackRequestAnnotation succeeded.
+	ManualRollbackSucceededReason string = "ManualRollbackSucceeded"
+
+	// ManualRollbackFailedReason represents the fact that the Helm rollback
+	// requested through the RollbackRequestAnnotation failed, or could not
+	// be performed.
+	ManualRollbackFailedReason string = "ManualRollbackFailed"
+
+	// TestRequestFailedReason represents the fact that the Helm test
+	// requested through the TestRequestAnnotation could not be performed.
+	TestRequestFailedReason string = "TestRequestFailed"
+
 	// UninstallSucceededReason represents the fact that the Helm uninstall for the
 	// HelmRelease succeeded.
 	UninstallSucceededReason string = "UninstallSucceeded"
@@ -79,4 +109,225 @@ const (
 	// DependencyNotReadyReason represents the fact that
 	// one of the dependencies is not ready.
 	DependencyNotReadyReason string = "DependencyNotReady"
+
+	// DependencyCycleReason represents the fact that the dependencies of the
+	// HelmRelease form a cycle, which can never be satisfied.
+	DependencyCycleReason string = "DependencyCycle"
+
+	// DependencyRemediatedReason represents the fact that one of the
+	// dependencies has been remediated after a failure, which blocks the
+	// release until the dependency has recovered.
+	DependencyRemediatedReason string = "DependencyRemediated"
+
+	// WaitingForDependentsReason represents the fact that the uninstall of
+	// the Helm release is delayed until the HelmReleases depending on it have
+	// been deleted.
+	WaitingForDependentsReason string = "WaitingForDependents"
+
+	// NamespaceTerminatingReason represents the fact that the target namespace
+	// of the HelmRelease is being terminated.
+	NamespaceTerminatingReason string = "NamespaceTerminating"
+
+	// ChartDeprecatedReason represents the fact that the chart of the
+	// HelmRelease is marked as deprecated.
+	ChartDeprecatedReason string = "ChartDeprecated"
+
+	// MaxHistoryReachedReason represents the fact that the number of releases
+	// stored by Helm for the HelmRelease has reached its MaxHistory.
+	MaxHistoryReachedReason string = "MaxHistoryReached"
+
+	// ResourceQuotaExceededReason represents the fact that the estimated
+	// resource usage of the Helm release exceeds a ResourceQuota in the
+	// target namespace.
+	ResourceQuotaExceededReason string = "ResourceQuotaExceeded"
+
+	// MissingRequiredLabelsReason represents the fact that one or more
+	// resources of the Helm release do not have the required labels.
+	MissingRequiredLabelsReason string = "MissingRequiredLabels"
+
+	// ResourceConflictReason represents the fact that one or more resources
+	// of the Helm release are managed by another Helm release.
+	ResourceConflictReason string = "ResourceConflict"
+
+	// DiffOnlyReason represents the fact that the Helm release was not
+	// installed or upgraded, as only a diff of the changes is desired.
+	DiffOnlyReason string = "DiffOnly"
+
+	// DiffFailedReason represents the fact that the dry-run of the Helm
+	// action to compute a diff failed.
+	DiffFailedReason string = "DiffFailed"
+
+	// CapabilityProfileFailedReason represents the fact that the Helm release
+	// failed to render against one or more capability profiles.
+	CapabilityProfileFailedReason string = "CapabilityProfileFailed"
+
+	// ArtifactAuthFailedReason represents the fact that pulling the chart
+	// artifact failed due to an authentication or authorization error.
+	ArtifactAuthFailedReason string = "ArtifactAuthFailed"
+
+	// DowngradeBlockedReason represents the fact that the upgrade of the Helm
+	// release was blocked, as the chart version is lower than the version
+	// of the deployed release.
+	DowngradeBlockedReason string = "DowngradeBlocked"
+
+	// SourceSuspendedReason represents the fact that the source of the chart
+	// of the HelmRelease is suspended.
+	SourceSuspendedReason string = "SourceSuspended"
+
+	// OutsideMaintenanceWindowReason represents the fact that a Helm action
+	// for the HelmRelease is deferred until the next maintenance window opens.
+	OutsideMaintenanceWindowReason string = "OutsideMaintenanceWindow"
+
+	// InvalidMaintenanceWindowReason represents the fact that the schedule of
+	// a maintenance window of the HelmRelease is invalid.
+	InvalidMaintenanceWindowReason string = "InvalidMaintenanceWindow"
+
+	// InvalidRemediationReason represents the fact that the remediation
+	// configuration of the HelmRelease conflicts with its strategy.
+	InvalidRemediationReason string = "InvalidRemediation"
+
+	// InvalidStorageDriverReason represents the fact that the storage driver
+	// of the HelmRelease is not supported.
+	InvalidStorageDriverReason string = "InvalidStorageDriver"
+
+	// OverrideNotAllowedReason represents the fact that the HelmRelease
+	// overrides a controller level default which is not allowed to be
+	// overridden, e.g. the default service account.
+	OverrideNotAllowedReason string = "OverrideNotAllowed"
+
+	// APIWarningsReason represents the fact that the Kubernetes API server
+	// returned warnings while reconciling the HelmRelease, for example about
+	// the use of deprecated APIs.
+	APIWarningsReason string = "APIWarnings"
+
+	// MembersNotReadyReason represents the fact that one or more members of
+	// a HelmReleaseGroup are not ready.
+	MembersNotReadyReason string = "MembersNotReady"
+
+	// InvalidSelectorReason represents the fact that the selector of a
+	// HelmReleaseGroup is invalid.
+	InvalidSelectorReason string = "InvalidSelector"
+
+	// NoMembersReason represents the fact that no HelmReleases match the
+	// selector of a HelmReleaseGroup.
+	NoMembersReason string = "NoMembers"
+
+	// HealthCheckFailedReason represents the fact that the resources of the
+	// Helm release do not meet the readiness threshold of the HelmRelease.
+	HealthCheckFailedReason string = "HealthCheckFailed"
+
+	// ReconcileHooksFailedReason represents the fact that the reconcile
+	// hooks of the HelmRelease could not be configured, e.g. because their
+	// address is not allowed.
+	ReconcileHooksFailedReason string = "ReconcileHooksFailed"
+
+	// PreReconcileHookFailedReason represents the fact that the pre reconcile
+	// hook of the HelmRelease failed, blocking the Helm action.
+	PreReconcileHookFailedReason string = "PreReconcileHookFailed"
+
+	// PostReconcileHookFailedReason represents the fact that the post
+	// reconcile hook of the HelmRelease failed.
+	PostReconcileHookFailedReason string = "PostReconcileHookFailed"
+
+	// VerificationFailedReason represents the fact that the signature of the
+	// chart artifact could not be verified as configured in the ChartRef.
+	VerificationFailedReason string = "VerificationFailed"
+
+	// ClusterUnreachableReason represents the fact that the remote cluster
+	// targeted by the KubeConfig of the HelmRelease can not be reached.
+	ClusterUnreachableReason string = "ClusterUnreachable"
+
+	// PausedReason represents the fact that the reconciliation of the
+	// HelmRelease is paused using the PauseAnnotation.
+	PausedReason string = "Paused"
+
+	// ReleasePinnedReason represents the fact that the upgrade of the Helm
+	// release is held back, as the release is pinned by the HelmRelease.
+	ReleasePinnedReason string = "ReleasePinned"
+
+	// ValuesSchemaInvalidReason represents the fact that the values of the
+	// HelmRelease do not match the JSON schema of the chart.
+	ValuesSchemaInvalidReason string = "ValuesSchemaInvalid"
+
+	// CRDConflictReason represents the fact that a CustomResourceDefinition
+	// of the chart already exists with a spec it can not be replaced with.
+	CRDConflictReason string = "CRDConflict"
+
+	// InvalidChartReferenceReason represents the fact that the HelmRelease
+	// does not reference a chart in a supported way.
+	InvalidChartReferenceReason string = "InvalidChartReference"
+
+	// ValuesDeniedReason represents the fact that the values of the
+	// HelmRelease set paths which are denied by the controller.
+	ValuesDeniedReason string = "ValuesDenied"
+
+	// AdoptSucceededReason represents the fact that an existing Helm release
+	// which was not produced by the controller has been adopted.
+	AdoptSucceededReason string = "AdoptSucceeded"
+
+	// ArtifactNotReadyReason represents the fact that the source of the
+	// chart has not produced an artifact yet.
+	ArtifactNotReadyReason string = "ArtifactNotReady"
+
+	// ManifestExportSucceededReason represents the fact that the manifest of
+	// the latest Helm release has been exported to a ConfigMap.
+	ManifestExportSucceededReason string = "ManifestExportSucceeded"
+
+	// ManifestExportFailedReason represents the fact that the manifest of
+	// the latest Helm release could not be exported to a ConfigMap.
+	ManifestExportFailedReason string = "ManifestExportFailed"
+
+	// BackupFailedReason represents the fact that the backup of the current
+	// Helm release could not be taken before an upgrade.
+	BackupFailedReason string = "BackupFailed"
+
+	// RestoreSucceededReason represents the fact that the Helm release
+	// has been restored from a backup on request.
+	RestoreSucceededReason string = "RestoreSucceeded"
+
+	// RestoreFailedReason represents the fact that the restore of the Helm
+	// release from a backup requested through the RestoreRequestAnnotation
+	// failed, or could not be performed.
+	RestoreFailedReason string = "RestoreFailed"
+
+	// ReleaseNameCollisionReason represents the fact that the Helm release
+	// of the HelmRelease is owned by another HelmRelease.
+	ReleaseNameCollisionReason string = "ReleaseNameCollision"
+
+	// ConcurrencyLimitedReason represents the fact that the Helm actions of
+	// the HelmRelease are deferred, because the maximum number of
+	// HelmReleases sharing its chart source are running Helm actions.
+	ConcurrencyLimitedReason string = "ConcurrencyLimited"
+
+	// InvalidEmbeddedChartReason represents the fact that the chart
+	// embedded in the ConfigMap or Secret referenced by the HelmRelease
+	// could not be loaded.
+	InvalidEmbeddedChartReason string = "InvalidEmbeddedChart"
+
+	// FailedResourcesKeptReason represents the fact that a failed Helm
+	// upgrade was not remediated, to keep the resources of the failed
+	// release in place for inspection.
+	FailedResourcesKeptReason string = "FailedResourcesKept"
+
+	// ImmutableResourcesRecreatedReason represents the fact that resources
+	// of the Helm release are recreated, as an immutable field of them
+	// conflicts with the desired state.
+	ImmutableResourcesRecreatedReason string = "ImmutableResourcesRecreated"
+
+	// DependencyUpdateFailedReason represents the fact that the dependencies
+	// of the chart which are not vendored in it could not be built.
+	DependencyUpdateFailedReason string = "DependencyUpdateFailed"
+
+	// ResourcesPrunedReason represents the fact that orphaned resources of
+	// the Helm release have been pruned.
+	ResourcesPrunedReason string = "ResourcesPruned"
+
+	// PruneFailedReason represents the fact that orphaned resources of the
+	// Helm release could not be pruned.
+	PruneFailedReason string = "PruneFailed"
+
+	// PruneSkippedReason represents the fact that orphaned resources of the
+	// Helm release have not been pruned, as they are owned by another
+	// release.
+	PruneSkippedReason string = "PruneSkipped"
 )
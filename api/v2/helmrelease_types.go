@@ -17,6 +17,7 @@ limitations under the License.
 package v2
 
 import (
+	"errors"
 	"strings"
 	"time"
 
@@ -42,6 +43,14 @@ const (
 	defaultMaxHistory = 5
 )
 
+const (
+	// StorageDriverSecret stores the Helm release information in Secrets.
+	StorageDriverSecret = "secret"
+	// StorageDriverConfigMap stores the Helm release information in
+	// ConfigMaps.
+	StorageDriverConfigMap = "configmap"
+)
+
 // Kustomize Helm PostRenderer specification.
 type Kustomize struct {
 	// Strategic merge and JSON patches, defined as inline YAML objects,
@@ -56,11 +65,23 @@ type Kustomize struct {
 	Images []kustomize.Image `json:"images,omitempty" json:"images,omitempty"`
 }
 
-// PostRenderer contains a Helm PostRenderer specification.
+// PostRenderer contains a Helm PostRenderer specification. Only one kind of
+// PostRenderer can be set per entry.
+// +kubebuilder:validation:XValidation:rule="(has(self.kustomize) ? 1 : 0) + (has(self.labels) ? 1 : 0) + (has(self.annotations) ? 1 : 0) <= 1", message="only one of kustomize, labels or annotations can be set"
 type PostRenderer struct {
 	// Kustomization to apply as PostRenderer.
 	// +optional
 	Kustomize *Kustomize `json:"kustomize,omitempty"`
+
+	// Labels to set on all the rendered resources, overwriting any existing
+	// label with the same key.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to set on all the rendered resources, overwriting any
+	// existing annotation with the same key.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
 // HelmReleaseSpec defines the desired state of a Helm release.
@@ -76,12 +97,77 @@ type HelmReleaseSpec struct {
 	// +optional
 	ChartRef *CrossNamespaceSourceReference `json:"chartRef,omitempty"`
 
+	// ChartDeprecationPolicy defines how the controller handles a chart which
+	// is marked as deprecated in its metadata. 'Warn' reports the deprecation
+	// using the ChartDeprecated condition and an event, 'Block' additionally
+	// stalls the reconciliation. Defaults to 'Warn'.
+	// +kubebuilder:validation:Enum=Warn;Block
+	// +optional
+	ChartDeprecationPolicy ChartDeprecationPolicy `json:"chartDeprecationPolicy,omitempty"`
+
+	// SourceSuspendedPolicy defines how the controller handles a source of
+	// the chart which is suspended. 'Continue' reconciles the Helm release
+	// using the last artifact of the source, 'Pause' prevents any Helm action
+	// other than the correction of drift. In both cases, the suspension is
+	// reported using the SourceSuspended condition. Defaults to 'Continue'.
+	// +kubebuilder:validation:Enum=Continue;Pause
+	// +optional
+	SourceSuspendedPolicy SourceSuspendedPolicy `json:"sourceSuspendedPolicy,omitempty"`
+
+	// ChartPullAuthFailurePolicy defines how the controller handles a source
+	// of the chart which failed to authenticate with its upstream, as
+	// reported by the 'AuthenticationFailed' reason of its Ready condition.
+	// 'Retry' retries with backoff, assuming credentials are being rotated,
+	// 'Fail' stops retrying and marks the HelmRelease as stalled until it or
+	// its source changes. Other failures are always retried. Defaults to
+	// 'Retry'.
+	// +kubebuilder:validation:Enum=Retry;Fail
+	// +optional
+	ChartPullAuthFailurePolicy ChartPullAuthFailurePolicy `json:"chartPullAuthFailurePolicy,omitempty"`
+
 	// Interval at which to reconcile the Helm release.
 	// +kubebuilder:validation:Type=string
 	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
 	// +required
 	Interval metav1.Duration `json:"interval"`
 
+	// MaintenanceWindows restricts the Helm install and upgrade actions, and
+	// the correction of drift, to the defined windows. Outside a window, the
+	// actions are deferred until the next window opens. When empty, actions
+	// are not restricted.
+	// +optional
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+
+	// MaintenanceWindowBypass allows the correction of drift, and releases
+	// forced using the ForceRequestAnnotation, to be performed outside the
+	// MaintenanceWindows.
+	// +optional
+	MaintenanceWindowBypass bool `json:"maintenanceWindowBypass,omitempty"`
+
+	// AdaptiveRequeue adapts the interval at which the Helm release is
+	// reconciled after a successful reconciliation to the stability of the
+	// HelmRelease. When set, the Interval is shortened when the Ready
+	// condition recently transitioned, and lengthened when it has been
+	// stable.
+	// +optional
+	AdaptiveRequeue *AdaptiveRequeue `json:"adaptiveRequeue,omitempty"`
+
+	// StableInterval configures a longer interval at which the Helm release
+	// is reconciled once it has been stable for a number of consecutive
+	// reconciliations. Any change to the HelmRelease or the chart and values
+	// of the release, or any Helm action (e.g. to correct drift), resets the
+	// interval to the Interval. Takes precedence over the AdaptiveRequeue
+	// while the release is stable.
+	// +optional
+	StableInterval *StableInterval `json:"stableInterval,omitempty"`
+
+	// RetryBackoff configures an exponential backoff with jitter for retrying
+	// the Helm release after a failed release or remediation attempt. When
+	// not set, failed attempts are retried using the rate limiter of the
+	// controller.
+	// +optional
+	RetryBackoff *RetryBackoff `json:"retryBackoff,omitempty"`
+
 	// KubeConfig for reconciling the HelmRelease on a remote cluster.
 	// When used in combination with HelmReleaseSpec.ServiceAccountName,
 	// forces the controller to act on behalf of that Service Account at the
@@ -113,6 +199,14 @@ type HelmReleaseSpec struct {
 	// +optional
 	TargetNamespace string `json:"targetNamespace,omitempty"`
 
+	// NamespaceTerminationPolicy defines how the controller handles a target
+	// namespace which is in the Terminating phase. 'Wait' requeues the
+	// reconciliation until the namespace has been removed, 'Abort' stalls the
+	// reconciliation immediately. Defaults to 'Wait'.
+	// +kubebuilder:validation:Enum=Wait;Abort
+	// +optional
+	NamespaceTerminationPolicy NamespaceTerminationPolicy `json:"namespaceTerminationPolicy,omitempty"`
+
 	// StorageNamespace used for the Helm storage.
 	// Defaults to the namespace of the HelmRelease.
 	// +kubebuilder:validation:MinLength=1
@@ -121,6 +215,12 @@ type HelmReleaseSpec struct {
 	// +optional
 	StorageNamespace string `json:"storageNamespace,omitempty"`
 
+	// StorageDriver is the Helm storage driver used to store the release
+	// information. Defaults to 'secret'.
+	// +kubebuilder:validation:Enum=secret;configmap
+	// +optional
+	StorageDriver string `json:"storageDriver,omitempty"`
+
 	// DependsOn may contain a meta.NamespacedObjectReference slice with
 	// references to HelmRelease resources that must be ready before this HelmRelease
 	// can be reconciled.
@@ -134,11 +234,23 @@ type HelmReleaseSpec struct {
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
 
+	// DisableWait overrides the controller level default for waiting for all
+	// resources to be ready during the performance of Helm install, upgrade,
+	// rollback and uninstall actions. When the action specific DisableWait is
+	// set to true, it takes precedence.
+	// +optional
+	DisableWait *bool `json:"disableWait,omitempty"`
+
 	// MaxHistory is the number of revisions saved by Helm for this HelmRelease.
 	// Use '0' for an unlimited number of revisions; defaults to '5'.
 	// +optional
 	MaxHistory *int `json:"maxHistory,omitempty"`
 
+	// HistoryRetention configures the retention of the releases in the
+	// Status.History and the Helm storage beyond the MaxHistory count.
+	// +optional
+	HistoryRetention *HistoryRetention `json:"historyRetention,omitempty"`
+
 	// The name of the Kubernetes service account to impersonate
 	// when reconciling this HelmRelease.
 	// +kubebuilder:validation:MinLength=1
@@ -167,6 +279,27 @@ type HelmReleaseSpec struct {
 	// +optional
 	DriftDetection *DriftDetection `json:"driftDetection,omitempty"`
 
+	// Preflight holds the configuration for checks performed before a Helm
+	// install or upgrade action.
+	// +optional
+	Preflight *Preflight `json:"preflight,omitempty"`
+
+	// Readiness holds the configuration for computing the readiness of the
+	// HelmRelease from the health of the resources of the Helm release.
+	// +optional
+	Readiness *Readiness `json:"readiness,omitempty"`
+
+	// HealthChecks holds the configuration for checking the health of the
+	// resources of the Helm release after a successful release action, with
+	// the results recorded in the status.
+	// +optional
+	HealthChecks *HealthChecks `json:"healthChecks,omitempty"`
+
+	// ReconcileHooks holds the configuration for external webhooks invoked by
+	// the controller before and after performing a Helm install or upgrade.
+	// +optional
+	ReconcileHooks *ReconcileHooks `json:"reconcileHooks,omitempty"`
+
 	// Install holds the configuration for Helm install actions for this HelmRelease.
 	// +optional
 	Install *Install `json:"install,omitempty"`
@@ -175,6 +308,31 @@ type HelmReleaseSpec struct {
 	// +optional
 	Upgrade *Upgrade `json:"upgrade,omitempty"`
 
+	// AllowDowngrade allows the Helm release to be upgraded to a chart with a
+	// lower version than the chart of the deployed release. When false, the
+	// upgrade is blocked and the object is marked as stalled until the chart
+	// version is no longer lower, or this is set to true.
+	// Charts sourced from an OCIRepository which references the chart by
+	// digest are never blocked.
+	// +optional
+	AllowDowngrade bool `json:"allowDowngrade,omitempty"`
+
+	// DiffOnly instructs the controller to not perform a Helm install or
+	// upgrade, but to run a server-side dry-run of the action instead, and
+	// record the changes it would make to the resources of the release in
+	// the Status.LastDiff.
+	// +optional
+	DiffOnly bool `json:"diffOnly,omitempty"`
+
+	// PinRelease holds on to the deployed Helm release, and prevents it from
+	// being upgraded to a new chart version or configuration. A pinned
+	// release is still tested, and checked and corrected for drift against
+	// the deployed release. Failed releases are still remediated, but not
+	// upgraded, and an upgrade can still be forced using the
+	// ForceRequestAnnotation.
+	// +optional
+	PinRelease bool `json:"pinRelease,omitempty"`
+
 	// Test holds the configuration for Helm test actions for this HelmRelease.
 	// +optional
 	Test *Test `json:"test,omitempty"`
@@ -195,12 +353,140 @@ type HelmReleaseSpec struct {
 	// +optional
 	Values *apiextensionsv1.JSON `json:"values,omitempty"`
 
+	// Subcharts enables or disables the subcharts of the chart, by setting
+	// the value of the condition defined for the dependency in the chart.
+	// This takes precedence over Values and ValuesFrom.
+	// +optional
+	Subcharts []Subchart `json:"subcharts,omitempty"`
+
 	// PostRenderers holds an array of Helm PostRenderers, which will be applied in order
-	// of their definition.
+	// of their definition. The output of a PostRenderer is the input of the next one.
 	// +optional
 	PostRenderers []PostRenderer `json:"postRenderers,omitempty"`
+
+	// RequiredLabels defines the labels which must be set on all resources
+	// of the Helm release.
+	// +optional
+	RequiredLabels *RequiredLabels `json:"requiredLabels,omitempty"`
+
+	// CommonMetadata defines the labels and annotations which are set on all
+	// resources of the Helm release, including the resources of its hooks.
+	// +optional
+	CommonMetadata *CommonMetadata `json:"commonMetadata,omitempty"`
+
+	// ManifestExport holds the configuration for exporting the manifest of
+	// the latest successful Helm release to a ConfigMap.
+	// +optional
+	ManifestExport *ManifestExport `json:"manifestExport,omitempty"`
+}
+
+// ManifestExport defines the ConfigMap the manifest of the latest successful
+// Helm release is exported to, e.g. for review or external policy scanning.
+type ManifestExport struct {
+	// ConfigMapName is the name of the ConfigMap in the namespace of the
+	// HelmRelease to write the manifest to. The ConfigMap is created if it
+	// does not exist, and is owned by the HelmRelease.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	// +required
+	ConfigMapName string `json:"configMapName"`
+}
+
+// RequiredLabels defines a policy for the labels of the resources of a Helm
+// release.
+type RequiredLabels struct {
+	// Keys of the labels which must be set on all resources of the Helm
+	// release. A Helm install or upgrade is blocked when a rendered resource
+	// does not have one of these labels.
+	// +required
+	Keys []string `json:"keys"`
+
+	// Inject holds the values of labels to set on the resources of the Helm
+	// release which do not have them. Labels set by the chart are not
+	// overwritten, and injected labels are excluded from drift detection.
+	// +optional
+	Inject map[string]string `json:"inject,omitempty"`
+}
+
+// CommonMetadata defines the labels and annotations which are set on all
+// resources of a Helm release.
+type CommonMetadata struct {
+	// Labels to set on all resources of the Helm release. The labels
+	// overwrite any labels with the same key set by the chart.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations to set on all resources of the Helm release. The
+	// annotations overwrite any annotations with the same key set by the
+	// chart.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
 }
 
+// ChartDeprecationPolicy defines how the controller handles a deprecated
+// chart.
+type ChartDeprecationPolicy string
+
+const (
+	// ChartDeprecationWarn instructs the controller to report the deprecation
+	// of the chart without blocking the Helm release.
+	ChartDeprecationWarn ChartDeprecationPolicy = "Warn"
+
+	// ChartDeprecationBlock instructs the controller to stall the
+	// reconciliation when the chart is deprecated.
+	ChartDeprecationBlock ChartDeprecationPolicy = "Block"
+)
+
+// SourceSuspendedPolicy defines how the controller handles a suspended
+// source of the chart.
+type SourceSuspendedPolicy string
+
+const (
+	// SourceSuspendedContinue instructs the controller to continue to
+	// reconcile the Helm release using the last artifact of the source.
+	SourceSuspendedContinue SourceSuspendedPolicy = "Continue"
+
+	// SourceSuspendedPause instructs the controller to pause any Helm
+	// action other than the correction of drift while the source is
+	// suspended.
+	SourceSuspendedPause SourceSuspendedPolicy = "Pause"
+)
+
+// ChartPullAuthFailurePolicy defines how the controller handles a source of
+// the chart which failed to authenticate with its upstream.
+type ChartPullAuthFailurePolicy string
+
+const (
+	// ChartPullAuthFailureRetry instructs the controller to retry with
+	// backoff until the source recovers.
+	ChartPullAuthFailureRetry ChartPullAuthFailurePolicy = "Retry"
+
+	// ChartPullAuthFailureFail instructs the controller to stop retrying,
+	// and to mark the HelmRelease as stalled.
+	ChartPullAuthFailureFail ChartPullAuthFailurePolicy = "Fail"
+)
+
+// NamespaceTerminationPolicy defines how the controller handles a target
+// namespace which is being terminated.
+type NamespaceTerminationPolicy string
+
+const (
+	// NamespaceTerminationWait instructs the controller to wait for the
+	// namespace to be removed before attempting any Helm action. If the
+	// namespace remains in the Terminating phase for longer than
+	// NamespaceTerminationStallThreshold, the reconciliation is stalled.
+	NamespaceTerminationWait NamespaceTerminationPolicy = "Wait"
+
+	// NamespaceTerminationAbort instructs the controller to stall the
+	// reconciliation as soon as the namespace is observed to be terminating.
+	NamespaceTerminationAbort NamespaceTerminationPolicy = "Abort"
+)
+
+// NamespaceTerminationStallThreshold is the duration after which a namespace
+// stuck in the Terminating phase causes the reconciliation to stall, when
+// NamespaceTerminationWait is used.
+const NamespaceTerminationStallThreshold = 10 * time.Minute
+
 // DriftDetectionMode represents the modes in which a controller can detect and
 // handle differences between the manifest in the Helm storage and the resources
 // currently existing in the cluster.
@@ -269,6 +555,23 @@ type DriftDetection struct {
 	// during diffing.
 	// +optional
 	Ignore []IgnoreRule `json:"ignore,omitempty"`
+
+	// CoolDown is the period after the latest Helm release was deployed
+	// during which drift is not corrected, to allow resources to settle.
+	// Drift detected during this period is still reported.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	CoolDown *metav1.Duration `json:"coolDown,omitempty"`
+
+	// Selector limits drift correction to the resources of the release
+	// matching the label selector. Drift of resources outside the selector
+	// is still detected and reported, but not corrected.
+	// Correcting only a subset of the resources may leave the release in a
+	// partially drifted state, with the corrected resources depending on
+	// resources which are not.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
 // GetMode returns the DiffMode set on the Diff, or DiffModeDisabled if not
@@ -286,6 +589,355 @@ func (d DriftDetection) MustDetectChanges() bool {
 	return d.GetMode() == DriftDetectionEnabled || d.GetMode() == DriftDetectionWarn
 }
 
+// AdaptiveRequeue defines the bounds of the adaptive requeue interval of a
+// HelmRelease.
+type AdaptiveRequeue struct {
+	// MinInterval is the lower bound of the requeue interval, used directly
+	// after the Ready condition transitioned. Defaults to a quarter of the
+	// Interval.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	MinInterval *metav1.Duration `json:"minInterval,omitempty"`
+
+	// MaxInterval is the upper bound of the requeue interval, used when the
+	// Ready condition has been stable for at least this period. Defaults to
+	// four times the Interval.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	MaxInterval *metav1.Duration `json:"maxInterval,omitempty"`
+}
+
+// GetMinInterval returns the configured MinInterval, or a quarter of the
+// given interval.
+func (in AdaptiveRequeue) GetMinInterval(interval time.Duration) time.Duration {
+	if in.MinInterval == nil {
+		return interval / 4
+	}
+	return in.MinInterval.Duration
+}
+
+// GetMaxInterval returns the configured MaxInterval, or four times the
+// given interval. The returned value is never lower than the minimum
+// interval.
+func (in AdaptiveRequeue) GetMaxInterval(interval time.Duration) time.Duration {
+	maxInterval := interval * 4
+	if in.MaxInterval != nil {
+		maxInterval = in.MaxInterval.Duration
+	}
+	return max(maxInterval, in.GetMinInterval(interval))
+}
+
+// StableInterval defines the interval at which a stable HelmRelease is
+// reconciled.
+type StableInterval struct {
+	// Interval at which the Helm release is reconciled once it is stable.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +required
+	Interval metav1.Duration `json:"interval"`
+
+	// Threshold is the number of consecutive successful reconciliations in
+	// which the Helm release was in-sync without running any Helm action,
+	// after which the release is considered stable. Defaults to '3'.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	Threshold *int64 `json:"threshold,omitempty"`
+}
+
+// DefaultStableIntervalThreshold is the default Threshold of a
+// StableInterval.
+const DefaultStableIntervalThreshold = 3
+
+// GetThreshold returns the configured Threshold, or
+// DefaultStableIntervalThreshold.
+func (in StableInterval) GetThreshold() int64 {
+	if in.Threshold == nil {
+		return DefaultStableIntervalThreshold
+	}
+	return *in.Threshold
+}
+
+// RetryBackoff defines the exponential backoff for retrying a failed Helm
+// release.
+type RetryBackoff struct {
+	// BaseInterval is the interval after which the first failed attempt is
+	// retried. The interval is doubled for every consecutive failed attempt.
+	// Defaults to '10s'.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	BaseInterval *metav1.Duration `json:"baseInterval,omitempty"`
+
+	// MaxInterval is the upper bound of the interval. Defaults to the
+	// Interval of the HelmRelease.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	MaxInterval *metav1.Duration `json:"maxInterval,omitempty"`
+
+	// Jitter is the maximum percentage by which the interval is randomly
+	// shortened or lengthened, to spread the retries of HelmReleases which
+	// failed at the same time. Defaults to 10.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	Jitter *int `json:"jitter,omitempty"`
+}
+
+// DefaultRetryBackoffBaseInterval is the default BaseInterval of a
+// RetryBackoff.
+const DefaultRetryBackoffBaseInterval = 10 * time.Second
+
+// DefaultRetryBackoffJitter is the default Jitter of a RetryBackoff.
+const DefaultRetryBackoffJitter = 10
+
+// GetBaseInterval returns the configured BaseInterval, or
+// DefaultRetryBackoffBaseInterval.
+func (in RetryBackoff) GetBaseInterval() time.Duration {
+	if in.BaseInterval == nil {
+		return DefaultRetryBackoffBaseInterval
+	}
+	return in.BaseInterval.Duration
+}
+
+// GetMaxInterval returns the configured MaxInterval, or the given interval.
+// The returned value is never lower than the base interval.
+func (in RetryBackoff) GetMaxInterval(interval time.Duration) time.Duration {
+	maxInterval := interval
+	if in.MaxInterval != nil {
+		maxInterval = in.MaxInterval.Duration
+	}
+	return max(maxInterval, in.GetBaseInterval())
+}
+
+// GetJitter returns the configured Jitter, or DefaultRetryBackoffJitter.
+func (in RetryBackoff) GetJitter() int {
+	if in.Jitter == nil {
+		return DefaultRetryBackoffJitter
+	}
+	return *in.Jitter
+}
+
+// MaintenanceWindow defines a recurring period of time in which Helm
+// actions may be performed.
+type MaintenanceWindow struct {
+	// Schedule is the cron expression at which the window opens, in the
+	// standard five field format (e.g. '0 22 * * 1-5'), optionally prefixed
+	// with 'CRON_TZ=<timezone>'. Defaults to UTC.
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	Schedule string `json:"schedule"`
+
+	// Duration is the period of time the window remains open after it
+	// opened.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +required
+	Duration metav1.Duration `json:"duration"`
+}
+
+// HistoryRetention defines the retention of the release history of a
+// HelmRelease.
+type HistoryRetention struct {
+	// MaxAge is the maximum age of a release, based on the time it was last
+	// deployed. Older releases are removed from the Status.History and the
+	// Helm storage after an install or upgrade. The latest release and the
+	// latest successfully deployed release are always retained.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+
+	// MaxCount is the number of revisions saved by Helm for this
+	// HelmRelease. When set, it takes precedence over MaxHistory.
+	// Use '0' for an unlimited number of revisions.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxCount *int `json:"maxCount,omitempty"`
+}
+
+// Subchart enables or disables a subchart of the chart.
+type Subchart struct {
+	// Name of the subchart, as defined by the name or alias of the
+	// dependency in the Chart.yaml of the chart.
+	// +required
+	Name string `json:"name"`
+
+	// Enabled indicates if the subchart should be enabled.
+	// +required
+	Enabled bool `json:"enabled"`
+}
+
+// Preflight defines the checks performed before a Helm install or upgrade
+// action.
+type Preflight struct {
+	// ResourceQuota enables checking the estimated resource requests of the
+	// rendered release against the ResourceQuotas in the target namespace.
+	// The estimate is approximate, and can not account for changes made
+	// concurrently by others.
+	// +optional
+	ResourceQuota bool `json:"resourceQuota,omitempty"`
+
+	// CapabilityProfiles is a list of Kubernetes capability profiles to
+	// render the release against, in addition to the capabilities of the
+	// target cluster. The result for each profile is recorded in the
+	// Status.CapabilityProfiles, a failure does not block the Helm action.
+	// +optional
+	CapabilityProfiles []CapabilityProfile `json:"capabilityProfiles,omitempty"`
+
+	// Hooks enables rendering the hooks of the chart, and recording them in
+	// the Status.Hooks for inspection before they are run.
+	// +optional
+	Hooks bool `json:"hooks,omitempty"`
+
+	// Conflicts enables checking if any of the rendered resources is
+	// already managed by another Helm release, in which case the action is
+	// refused. Resources are never adopted from another release.
+	// +optional
+	Conflicts bool `json:"conflicts,omitempty"`
+}
+
+// CapabilityProfile defines the Kubernetes version and API versions to
+// render a Helm release against.
+type CapabilityProfile struct {
+	// Name of the profile. When KubeVersion is not set, the name refers to a
+	// profile configured at controller level.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +required
+	Name string `json:"name"`
+
+	// KubeVersion is the Kubernetes version to render against, e.g.
+	// '1.28.0'.
+	// +optional
+	KubeVersion string `json:"kubeVersion,omitempty"`
+
+	// APIVersions is a list of API versions available in addition to the
+	// default ones of Helm, in the format of e.g. 'monitoring.coreos.com/v1'
+	// or 'monitoring.coreos.com/v1/ServiceMonitor'.
+	// +optional
+	APIVersions []string `json:"apiVersions,omitempty"`
+}
+
+// CapabilityProfileResult holds the result of rendering a Helm release
+// against a CapabilityProfile.
+type CapabilityProfileResult struct {
+	// Name of the profile.
+	// +required
+	Name string `json:"name"`
+
+	// Passed indicates if the release rendered successfully against the
+	// profile.
+	// +required
+	Passed bool `json:"passed"`
+
+	// Message holds the error which caused the release to fail rendering
+	// against the profile.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// Readiness defines how the readiness of a HelmRelease is computed from the
+// health of the resources in the manifest of the Helm release.
+type Readiness struct {
+	// Threshold is the minimum percentage of resources in the manifest of the
+	// Helm release which must be healthy for the HelmRelease to be considered
+	// ready.
+	// When set, the Helm install and upgrade actions do not wait for all
+	// resources to become ready. Instead, the health of the resources is
+	// assessed by the controller after the release has been made.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +required
+	Threshold int `json:"threshold"`
+
+	// Critical is a list of selectors for resources which must be healthy
+	// regardless of the Threshold.
+	// +optional
+	Critical []kustomize.Selector `json:"critical,omitempty"`
+}
+
+// HealthChecks defines which resources of the Helm release are checked for
+// their health after a successful release action.
+// +kubebuilder:validation:XValidation:rule="(has(self.all) && self.all) || (has(self.resources) && size(self.resources) > 0)",message="either all or resources must be set"
+type HealthChecks struct {
+	// All enables the health check of all resources of the Helm release.
+	// +optional
+	All bool `json:"all,omitempty"`
+
+	// Resources is a list of selectors for the resources of the Helm release
+	// to check. Ignored when All is set.
+	// +optional
+	Resources []kustomize.Selector `json:"resources,omitempty"`
+
+	// Timeout is the time to wait for the resources to become healthy after
+	// the release action, after which the HelmRelease is marked with
+	// Released=False. Defaults to the timeout of the HelmRelease.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// GetTimeout returns the configured timeout for the health checks, or the
+// given default.
+func (in HealthChecks) GetTimeout(defaultTimeout metav1.Duration) metav1.Duration {
+	if in.Timeout == nil {
+		return defaultTimeout
+	}
+	return *in.Timeout
+}
+
+// ReconcileHooks defines the external webhooks which are invoked by the
+// controller around Helm install and upgrade actions. These are distinct from
+// Helm chart hooks, and are intended for integration with e.g. change
+// management or notification systems.
+type ReconcileHooks struct {
+	// Pre is invoked before a Helm install or upgrade action is performed.
+	// A failure blocks the action from being performed.
+	// +optional
+	Pre *ReconcileHook `json:"pre,omitempty"`
+
+	// Post is invoked after a Helm install or upgrade action has been
+	// performed. A failure results in a warning event, but does not undo
+	// the action.
+	// +optional
+	Post *ReconcileHook `json:"post,omitempty"`
+}
+
+// ReconcileHook defines an external webhook invoked by the controller.
+type ReconcileHook struct {
+	// Address is the HTTP(S) address the webhook request is sent to.
+	// +kubebuilder:validation:Pattern="^(http|https)://.*$"
+	// +required
+	Address string `json:"address"`
+
+	// SecretRef holds the name of a Secret in the same namespace as the
+	// HelmRelease, containing the credentials for the webhook. Supported keys
+	// are 'token' (bearer token), 'username' and 'password' (basic auth), and
+	// 'ca.crt' (CA certificate).
+	// +optional
+	SecretRef *meta.LocalObjectReference `json:"secretRef,omitempty"`
+
+	// Timeout is the time to wait for the webhook to respond. Defaults to
+	// '30s'.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+// GetTimeout returns the configured timeout for the webhook, or the default
+// of 30s.
+func (in ReconcileHook) GetTimeout() metav1.Duration {
+	if in.Timeout == nil {
+		return metav1.Duration{Duration: 30 * time.Second}
+	}
+	return *in.Timeout
+}
+
 // HelmChartTemplate defines the template from which the controller will
 // generate a v1.HelmChart object in the same namespace as the referenced
 // v1.Source.
@@ -297,6 +949,14 @@ type HelmChartTemplate struct {
 	// Spec holds the template for the v1.HelmChartSpec for this HelmRelease.
 	// +required
 	Spec HelmChartTemplateSpec `json:"spec"`
+
+	// DependencyUpdate makes the controller build the dependencies listed
+	// in the Chart.yaml of the chart which are not vendored in its charts/
+	// directory, before the chart is rendered. The dependencies are resolved
+	// from their HTTP(S) chart repositories, using the credentials of a
+	// HelmRepository with the same URL in the namespace of the HelmRelease.
+	// +optional
+	DependencyUpdate bool `json:"dependencyUpdate,omitempty"`
 }
 
 // HelmChartTemplateObjectMeta defines the template for the ObjectMeta of a
@@ -410,10 +1070,13 @@ type Remediation interface {
 	GetRetries() int
 	MustIgnoreTestFailures(bool) bool
 	MustRemediateLastFailure() bool
+	MustRemediateFailureClass(FailureClass) bool
+	MustKeepFailedResources() bool
 	GetStrategy() RemediationStrategy
 	GetFailureCount(hr *HelmRelease) int64
 	IncrementFailureCount(hr *HelmRelease)
 	RetriesExhausted(hr *HelmRelease) bool
+	GetRetryDelay() time.Duration
 }
 
 // Install holds the configuration for Helm install actions performed for this
@@ -432,6 +1095,14 @@ type Install struct {
 	// +optional
 	Remediation *InstallRemediation `json:"remediation,omitempty"`
 
+	// Atomic uninstalls the release when the Helm install action fails, to
+	// remove any resources created before the failure, and purges it from
+	// the Helm storage. The next install attempt starts from a clean state.
+	// The uninstall is independent of the Remediation, and the failure still
+	// counts towards its retries.
+	// +optional
+	Atomic bool `json:"atomic,omitempty"`
+
 	// DisableWait disables the waiting for resources to be ready after a Helm
 	// install has been performed.
 	// +optional
@@ -442,10 +1113,35 @@ type Install struct {
 	// +optional
 	DisableWaitForJobs bool `json:"disableWaitForJobs,omitempty"`
 
+	// WaitForJobs waits for the Jobs of the release to complete after a Helm
+	// install has been performed, independent of waiting for all resources to
+	// be ready. This allows waiting for e.g. a migration Job while DisableWait
+	// is set. The Jobs which do not complete within the timeout fail the
+	// release with a JobTimeout failure class.
+	// +optional
+	WaitForJobs bool `json:"waitForJobs,omitempty"`
+
+	// ReadinessGracePeriod is the time after a Helm install during which the
+	// resources of the release which are not ready yet are reported as
+	// progressing, instead of failing the release. When set, the Helm install
+	// action does not wait for the resources, their readiness is assessed by
+	// the controller instead. Once the period has passed, a release of which
+	// the resources are not ready is marked as failed.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	ReadinessGracePeriod *metav1.Duration `json:"readinessGracePeriod,omitempty"`
+
 	// DisableHooks prevents hooks from running during the Helm install action.
 	// +optional
 	DisableHooks bool `json:"disableHooks,omitempty"`
 
+	// DisabledHooks is a list of hook types (e.g. 'pre-install') or hook names
+	// to prevent from running during the Helm install action, while any other
+	// hooks do run. It has no effect when DisableHooks is set.
+	// +optional
+	DisabledHooks []string `json:"disabledHooks,omitempty"`
+
 	// DisableOpenAPIValidation prevents the Helm install action from validating
 	// rendered templates against the Kubernetes OpenAPI Schema.
 	// +optional
@@ -491,11 +1187,27 @@ type Install struct {
 	// +optional
 	CRDs CRDsPolicy `json:"crds,omitempty"`
 
-	// CreateNamespace tells the Helm install action to create the
-	// HelmReleaseSpec.TargetNamespace if it does not exist yet.
-	// On uninstall, the namespace will not be garbage collected.
+	// CreateNamespace tells the controller to create the
+	// HelmReleaseSpec.TargetNamespace before the Helm install action, if it
+	// does not exist yet. On uninstall, the namespace is only deleted if it
+	// was created by the controller, and CreateNamespaceOptions.DeletePolicy
+	// is 'Delete'.
 	// +optional
 	CreateNamespace bool `json:"createNamespace,omitempty"`
+
+	// CreateNamespaceOptions holds the options for the creation of the
+	// HelmReleaseSpec.TargetNamespace when CreateNamespace is enabled.
+	// +optional
+	CreateNamespaceOptions *CreateNamespaceOptions `json:"createNamespaceOptions,omitempty"`
+
+	// Adopt tells the controller to adopt an existing Helm release with the
+	// same name, which was not produced by the controller, instead of taking
+	// it over with an upgrade. The release is only adopted if it is deployed,
+	// and was produced from a chart with the same name as the chart of the
+	// HelmRelease. Once adopted, the release is upgraded when it differs
+	// from the desired state.
+	// +optional
+	Adopt bool `json:"adopt,omitempty"`
 }
 
 // GetTimeout returns the configured timeout for the Helm install action,
@@ -533,6 +1245,16 @@ type InstallRemediation struct {
 	// no retries remain. Defaults to 'false'.
 	// +optional
 	RemediateLastFailure *bool `json:"remediateLastFailure,omitempty"`
+
+	// RetryDelay is the time after the first failure of the action during
+	// which failures are not counted towards the Retries, and the action is
+	// retried without remediation. This allows transient failures, such as a
+	// webhook which is not ready yet, to resolve without remediation.
+	// Defaults to '0', which counts every failure.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	RetryDelay *metav1.Duration `json:"retryDelay,omitempty"`
 }
 
 // GetRetries returns the number of retries that should be attempted on
@@ -559,11 +1281,32 @@ func (in InstallRemediation) MustRemediateLastFailure() bool {
 	return *in.RemediateLastFailure
 }
 
+// MustRemediateFailureClass returns true, as install failures are remediated
+// independent of their FailureClass.
+func (in InstallRemediation) MustRemediateFailureClass(_ FailureClass) bool {
+	return true
+}
+
+// MustKeepFailedResources returns false, as the resources of a failed install
+// are always remediated.
+func (in InstallRemediation) MustKeepFailedResources() bool {
+	return false
+}
+
 // GetStrategy returns the strategy to use for failure remediation.
 func (in InstallRemediation) GetStrategy() RemediationStrategy {
 	return UninstallRemediationStrategy
 }
 
+// GetRetryDelay returns the time after the first failure during which
+// failures are not counted.
+func (in InstallRemediation) GetRetryDelay() time.Duration {
+	if in.RetryDelay == nil {
+		return 0
+	}
+	return in.RetryDelay.Duration
+}
+
 // GetFailureCount gets the failure count.
 func (in InstallRemediation) GetFailureCount(hr *HelmRelease) int64 {
 	return hr.Status.InstallFailures
@@ -596,6 +1339,7 @@ const (
 
 // Upgrade holds the configuration for Helm upgrade actions for this
 // HelmRelease.
+// +kubebuilder:validation:XValidation:rule="!(has(self.resetValues) && self.resetValues && ((has(self.reuseValues) && self.reuseValues) || (has(self.preserveValues) && self.preserveValues)))", message="resetValues can not be combined with reuseValues or preserveValues"
 type Upgrade struct {
 	// Timeout is the time to wait for any individual Kubernetes operation (like
 	// Jobs for hooks) during the performance of a Helm upgrade action. Defaults to
@@ -620,10 +1364,24 @@ type Upgrade struct {
 	// +optional
 	DisableWaitForJobs bool `json:"disableWaitForJobs,omitempty"`
 
+	// WaitForJobs waits for the Jobs of the release to complete after a Helm
+	// upgrade has been performed, independent of waiting for all resources to
+	// be ready. This allows waiting for e.g. a migration Job while DisableWait
+	// is set. The Jobs which do not complete within the timeout fail the
+	// release with a JobTimeout failure class.
+	// +optional
+	WaitForJobs bool `json:"waitForJobs,omitempty"`
+
 	// DisableHooks prevents hooks from running during the Helm upgrade action.
 	// +optional
 	DisableHooks bool `json:"disableHooks,omitempty"`
 
+	// DisabledHooks is a list of hook types (e.g. 'pre-upgrade') or hook names
+	// to prevent from running during the Helm upgrade action, while any other
+	// hooks do run. It has no effect when DisableHooks is set.
+	// +optional
+	DisabledHooks []string `json:"disabledHooks,omitempty"`
+
 	// DisableOpenAPIValidation prevents the Helm upgrade action from validating
 	// rendered templates against the Kubernetes OpenAPI Schema.
 	// +optional
@@ -638,12 +1396,46 @@ type Upgrade struct {
 	// +optional
 	Force bool `json:"force,omitempty"`
 
+	// RecreateImmutable makes the controller recreate the resources of which
+	// an immutable field conflicts with the desired state, when the Helm
+	// upgrade action fails due to such conflict. The conflicting resources
+	// are deleted, after which the upgrade is retried once. Unlike 'Force',
+	// this only replaces the conflicting resources.
+	// +optional
+	RecreateImmutable bool `json:"recreateImmutable,omitempty"`
+
+	// Prune makes the controller delete the resources of the previous
+	// Status.Inventory which are absent from the inventory of the upgraded
+	// release, or of the release installed after a change of the release
+	// target, covering resources Helm does not remove itself, e.g. after a
+	// failed deletion. On a change of the release target, the resources of
+	// the previous release are handed over to the new release instead of
+	// being uninstalled. Resources annotated with
+	// 'helm.toolkit.fluxcd.io/prune: disabled' are skipped.
+	// +optional
+	Prune bool `json:"prune,omitempty"`
+
 	// PreserveValues will make Helm reuse the last release's values and merge in
 	// overrides from 'Values'. Setting this flag makes the HelmRelease
 	// non-declarative.
+	// Deprecated: Use 'ReuseValues' instead.
 	// +optional
 	PreserveValues bool `json:"preserveValues,omitempty"`
 
+	// ResetValues makes Helm reset the values to the ones built into the
+	// chart, before merging in the values composed from 'ValuesFrom' and
+	// 'Values'. This is the default when 'ReuseValues' is not set, and can
+	// not be combined with 'ReuseValues' or 'PreserveValues'.
+	// +optional
+	ResetValues bool `json:"resetValues,omitempty"`
+
+	// ReuseValues makes Helm reuse the last release's values and merge in
+	// the values composed from 'ValuesFrom' and 'Values'. Setting this flag
+	// makes the HelmRelease non-declarative, as values removed from the
+	// HelmRelease are retained in the release.
+	// +optional
+	ReuseValues bool `json:"reuseValues,omitempty"`
+
 	// CleanupOnFail allows deletion of new resources created during the Helm
 	// upgrade action when it fails.
 	// +optional
@@ -668,6 +1460,25 @@ type Upgrade struct {
 	// +kubebuilder:validation:Enum=Skip;Create;CreateReplace
 	// +optional
 	CRDs CRDsPolicy `json:"crds,omitempty"`
+
+	// ApplyBatchSize is the maximum number of resources submitted to the
+	// Kubernetes API at once while applying the release, with a brief pause
+	// between batches. The resources are applied in the order Helm installs
+	// them, after any CRDs. The pauses are included in the upgrade timeout.
+	// Defaults to '0', which applies all resources at once.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	ApplyBatchSize int `json:"applyBatchSize,omitempty"`
+
+	// Backup makes the controller back up the current release before
+	// performing a Helm upgrade, so that it can be restored using the
+	// 'reconcile.fluxcd.io/restoreAt' annotation even after it has been
+	// pruned from the Helm release history. The backup is stored next to
+	// the Helm storage using the same kind of object as the storage driver,
+	// and is removed once the release is no longer in the history of the
+	// HelmRelease.
+	// +optional
+	Backup bool `json:"backup,omitempty"`
 }
 
 // GetTimeout returns the configured timeout for the Helm upgrade action, or the
@@ -679,6 +1490,21 @@ func (in Upgrade) GetTimeout(defaultTimeout metav1.Duration) metav1.Duration {
 	return *in.Timeout
 }
 
+// GetReuseValues returns if the values of the last release should be reused
+// during the Helm upgrade action.
+func (in Upgrade) GetReuseValues() bool {
+	return in.ReuseValues || in.PreserveValues
+}
+
+// ValidateValuesOptions returns an error if the values options of the Helm
+// upgrade action are mutually exclusive.
+func (in Upgrade) ValidateValuesOptions() error {
+	if in.ResetValues && in.GetReuseValues() {
+		return errors.New("resetValues can not be combined with reuseValues or preserveValues")
+	}
+	return nil
+}
+
 // GetRemediation returns the configured Remediation for the Helm upgrade
 // action.
 func (in Upgrade) GetRemediation() Remediation {
@@ -711,6 +1537,32 @@ type UpgradeRemediation struct {
 	// +kubebuilder:validation:Enum=rollback;uninstall
 	// +optional
 	Strategy *RemediationStrategy `json:"strategy,omitempty"`
+
+	// KeepFailedResources tells the controller to not remediate a failed
+	// upgrade, but to keep the resources of the failed release in place for
+	// inspection. The failure is recorded, and the upgrade is not retried
+	// until the chart or values change, or an upgrade is forced. Takes
+	// precedence over 'Strategy' and 'Retries'.
+	// +optional
+	KeepFailedResources bool `json:"keepFailedResources,omitempty"`
+
+	// RetryDelay is the time after the first failure of the action during
+	// which failures are not counted towards the Retries, and the action is
+	// retried without remediation. This allows transient failures, such as a
+	// webhook which is not ready yet, to resolve without remediation.
+	// Defaults to '0', which counts every failure.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	RetryDelay *metav1.Duration `json:"retryDelay,omitempty"`
+
+	// RemediateOn is the list of classes of upgrade failures which trigger
+	// the remediation 'Strategy'. A failure of any other class marks the
+	// release as failed, and is retried with an upgrade without remediation.
+	// Defaults to all classes.
+	// +kubebuilder:validation:items:Enum=Render;Storage;Rollout;Timeout;JobTimeout;ImmutableField
+	// +optional
+	RemediateOn []FailureClass `json:"remediateOn,omitempty"`
 }
 
 // GetRetries returns the number of retries that should be attempted on
@@ -737,6 +1589,27 @@ func (in UpgradeRemediation) MustRemediateLastFailure() bool {
 	return *in.RemediateLastFailure
 }
 
+// MustRemediateFailureClass returns whether a failure of the given class
+// triggers remediation. This is the case when RemediateOn is empty or
+// contains the class, or the class of the failure is unknown.
+func (in UpgradeRemediation) MustRemediateFailureClass(class FailureClass) bool {
+	if len(in.RemediateOn) == 0 || class == "" {
+		return true
+	}
+	for _, c := range in.RemediateOn {
+		if c == class {
+			return true
+		}
+	}
+	return false
+}
+
+// MustKeepFailedResources returns whether to keep the resources of a failed
+// upgrade in place, instead of remediating it.
+func (in UpgradeRemediation) MustKeepFailedResources() bool {
+	return in.KeepFailedResources
+}
+
 // GetStrategy returns the strategy to use for failure remediation.
 func (in UpgradeRemediation) GetStrategy() RemediationStrategy {
 	if in.Strategy == nil {
@@ -745,6 +1618,15 @@ func (in UpgradeRemediation) GetStrategy() RemediationStrategy {
 	return *in.Strategy
 }
 
+// GetRetryDelay returns the time after the first failure during which
+// failures are not counted.
+func (in UpgradeRemediation) GetRetryDelay() time.Duration {
+	if in.RetryDelay == nil {
+		return 0
+	}
+	return in.RetryDelay.Duration
+}
+
 // GetFailureCount gets the failure count.
 func (in UpgradeRemediation) GetFailureCount(hr *HelmRelease) int64 {
 	return hr.Status.UpgradeFailures
@@ -774,6 +1656,36 @@ const (
 	UninstallRemediationStrategy RemediationStrategy = "uninstall"
 )
 
+// FailureClass is the classification of the failure of a Helm release action.
+type FailureClass string
+
+const (
+	// FailureClassRender represents a failure to render the chart, for example
+	// due to a template error or values which do not match the schema.
+	FailureClassRender FailureClass = "Render"
+
+	// FailureClassStorage represents a failure to read or write the release
+	// in the Helm storage.
+	FailureClassStorage FailureClass = "Storage"
+
+	// FailureClassRollout represents a failure to roll out the resources of
+	// the release, for example due to a rejected resource or a failed hook.
+	FailureClassRollout FailureClass = "Rollout"
+
+	// FailureClassTimeout represents a failure due to the resources or hooks
+	// of the release not becoming ready within the timeout.
+	FailureClassTimeout FailureClass = "Timeout"
+
+	// FailureClassJobTimeout represents a failure due to the Jobs of the
+	// release not completing within the timeout, when waiting for Jobs
+	// independent of the other resources with WaitForJobs.
+	FailureClassJobTimeout FailureClass = "JobTimeout"
+
+	// FailureClassImmutableField represents a failure to update a resource
+	// of the release, due to a change of a field which is immutable.
+	FailureClassImmutableField FailureClass = "ImmutableField"
+)
+
 // Test holds the configuration for Helm test actions for this HelmRelease.
 type Test struct {
 	// Enable enables Helm test actions for this HelmRelease after an Helm install
@@ -796,6 +1708,29 @@ type Test struct {
 
 	// Filters is a list of tests to run or exclude from running.
 	Filters *[]Filter `json:"filters,omitempty"`
+
+	// Retries is the number of times the Helm tests are retried when a test
+	// fails, before the failure is reported. All retries are performed
+	// within the Timeout of the test action. Defaults to '0', which reports
+	// the first failure.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Retries int `json:"retries,omitempty"`
+
+	// RetainConditionWhenDisabled retains the TestSuccess condition of the
+	// last Helm test action when tests are disabled, marking it as stale
+	// instead of removing it. The condition is not taken into account for the
+	// Ready condition while tests are disabled, and is removed by the next
+	// Helm install or upgrade.
+	// +optional
+	RetainConditionWhenDisabled bool `json:"retainConditionWhenDisabled,omitempty"`
+
+	// ExcludeFromManifest excludes the test hooks from the release when it
+	// is installed or upgraded, so that they are not persisted in the Helm
+	// storage. When the Helm test action runs, the test hooks are rendered
+	// from the chart and values of the release, and created on demand.
+	// +optional
+	ExcludeFromManifest bool `json:"excludeFromManifest,omitempty"`
 }
 
 // GetTimeout returns the configured timeout for the Helm test action,
@@ -865,6 +1800,14 @@ type Rollback struct {
 	// rollback action when it fails.
 	// +optional
 	CleanupOnFail bool `json:"cleanupOnFail,omitempty"`
+
+	// ToVersion is the version of the Helm release to roll back to when
+	// remediating a failed upgrade, instead of the previous successful
+	// release. The version must be present in the Status.History or in the
+	// Helm storage.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	ToVersion int `json:"toVersion,omitempty"`
 }
 
 // GetTimeout returns the configured timeout for the Helm rollback action, or
@@ -907,6 +1850,34 @@ type Uninstall struct {
 	// +kubebuilder:validation:Enum=background;foreground;orphan
 	// +optional
 	DeletionPropagation *string `json:"deletionPropagation,omitempty"`
+
+	// WaitForDependents delays the Helm uninstall of the release until the
+	// HelmReleases which depend on it through their DependsOn have been
+	// deleted, or WaitForDependentsTimeout has passed since the deletion of
+	// the HelmRelease was requested.
+	// +optional
+	WaitForDependents bool `json:"waitForDependents,omitempty"`
+
+	// WaitForDependentsTimeout is the time to wait for dependents to be
+	// deleted, after which the release is uninstalled regardless. Defaults to
+	// '10m'.
+	// +kubebuilder:validation:Type=string
+	// +kubebuilder:validation:Pattern="^([0-9]+(\\.[0-9]+)?(ms|s|m|h))+$"
+	// +optional
+	WaitForDependentsTimeout *metav1.Duration `json:"waitForDependentsTimeout,omitempty"`
+}
+
+// DefaultWaitForDependentsTimeout is the default time to wait for dependents
+// to be deleted before uninstalling a Helm release.
+const DefaultWaitForDependentsTimeout = 10 * time.Minute
+
+// GetWaitForDependentsTimeout returns the configured time to wait for
+// dependents to be deleted, or DefaultWaitForDependentsTimeout.
+func (in Uninstall) GetWaitForDependentsTimeout() time.Duration {
+	if in.WaitForDependentsTimeout == nil {
+		return DefaultWaitForDependentsTimeout
+	}
+	return in.WaitForDependentsTimeout.Duration
 }
 
 // GetTimeout returns the configured timeout for the Helm uninstall action, or
@@ -943,8 +1914,8 @@ type HelmReleaseStatus struct {
 	// +optional
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
-	// ObservedPostRenderersDigest is the digest for the post-renderers of
-	// the last successful reconciliation attempt.
+	// ObservedPostRenderersDigest is the digest for the post-renderers and
+	// the common metadata of the last successful reconciliation attempt.
 	// +optional
 	ObservedPostRenderersDigest string `json:"observedPostRenderersDigest,omitempty"`
 
@@ -970,6 +1941,10 @@ type HelmReleaseStatus struct {
 	// +optional
 	StorageNamespace string `json:"storageNamespace,omitempty"`
 
+	// StorageDriver is the Helm storage driver of the current release.
+	// +optional
+	StorageDriver string `json:"storageDriver,omitempty"`
+
 	// History holds the history of Helm releases performed for this HelmRelease
 	// up to the last successfully completed release.
 	// +optional
@@ -996,6 +1971,39 @@ type HelmReleaseStatus struct {
 	// +optional
 	UpgradeFailures int64 `json:"upgradeFailures,omitempty"`
 
+	// RemediationRetriesRemaining is the number of retries remaining for the
+	// remediation strategy of the last attempted release action, before the
+	// controller stops retrying. It is computed from the configured retries
+	// and the failure count of the action, and is not set when the retries
+	// are unlimited or the latest release succeeded without remediation.
+	// +optional
+	RemediationRetriesRemaining *int64 `json:"remediationRetriesRemaining,omitempty"`
+
+	// FirstFailureTime is the time of the first failure of a release action
+	// since the last successful release. It is used to determine if a failure
+	// occurred within the RetryDelay of the active remediation strategy.
+	// +optional
+	FirstFailureTime *metav1.Time `json:"firstFailureTime,omitempty"`
+
+	// ConsecutiveFailures is the number of consecutive reconciliations in
+	// which a release or remediation attempt failed, since the last
+	// successful release. It is used to compute the RetryBackoff.
+	// +optional
+	ConsecutiveFailures int64 `json:"consecutiveFailures,omitempty"`
+
+	// StableReconciliations is the number of consecutive successful
+	// reconciliations in which the Helm release was in-sync without running
+	// any Helm action. It is reset by any change to the HelmRelease or the
+	// chart and values of the release, and is used to determine when the
+	// StableInterval applies.
+	// +optional
+	StableReconciliations int64 `json:"stableReconciliations,omitempty"`
+
+	// LastFailureClass is the class of the last failure of a Helm install or
+	// upgrade action. It is reset after a successful release.
+	// +optional
+	LastFailureClass FailureClass `json:"lastFailureClass,omitempty"`
+
 	// LastAttemptedRevision is the Source revision of the last reconciliation
 	// attempt. For OCIRepository  sources, the 12 first characters of the digest are
 	// appended to the chart version e.g. "1.2.3+1234567890ab".
@@ -1007,6 +2015,12 @@ type HelmReleaseStatus struct {
 	// +optional
 	LastAttemptedRevisionDigest string `json:"lastAttemptedRevisionDigest,omitempty"`
 
+	// AvailableChartVersion is the version of the chart available from the
+	// Source, while the upgrade of the release to it or to the latest
+	// configuration is held back by Spec.PinRelease.
+	// +optional
+	AvailableChartVersion string `json:"availableChartVersion,omitempty"`
+
 	// LastAttemptedValuesChecksum is the SHA1 checksum for the values of the last
 	// reconciliation attempt.
 	// Deprecated: Use LastAttemptedConfigDigest instead.
@@ -1023,6 +2037,28 @@ type HelmReleaseStatus struct {
 	// +optional
 	LastAttemptedConfigDigest string `json:"lastAttemptedConfigDigest,omitempty"`
 
+	// EffectiveConfig holds the configuration of the HelmRelease as resolved
+	// from the spec and the controller level defaults during the last
+	// reconciliation attempt.
+	// +optional
+	EffectiveConfig *EffectiveConfig `json:"effectiveConfig,omitempty"`
+
+	// ClientIdentity holds the identity used by the controller to perform
+	// the last Helm action for the HelmRelease.
+	// +optional
+	ClientIdentity *ClientIdentity `json:"clientIdentity,omitempty"`
+
+	// HealthyPercentage is the percentage of healthy resources in the
+	// manifest of the latest release, as last assessed for the Readiness
+	// threshold.
+	// +optional
+	HealthyPercentage *int `json:"healthyPercentage,omitempty"`
+
+	// LastAppliedHealth is the result of the last health checks of the
+	// resources of the latest release, as configured by HealthChecks.
+	// +optional
+	LastAppliedHealth *ReleaseHealth `json:"lastAppliedHealth,omitempty"`
+
 	// LastHandledForceAt holds the value of the most recent force request
 	// value, so a change of the annotation value can be detected.
 	// +optional
@@ -1033,19 +2069,453 @@ type HelmReleaseStatus struct {
 	// +optional
 	LastHandledResetAt string `json:"lastHandledResetAt,omitempty"`
 
+	// LastHandledRollbackAt holds the value of the most recent rollback
+	// request value, so a change of the annotation value can be detected.
+	// +optional
+	LastHandledRollbackAt string `json:"lastHandledRollbackAt,omitempty"`
+
+	// LastHandledRestoreAt holds the value of the most recent restore
+	// request value, so a change of the annotation value can be detected.
+	// +optional
+	LastHandledRestoreAt string `json:"lastHandledRestoreAt,omitempty"`
+
+	// LastHandledTestAt holds the value of the most recent test request
+	// value, so a change of the annotation value can be detected.
+	// +optional
+	LastHandledTestAt string `json:"lastHandledTestAt,omitempty"`
+
+	// LastHandledForcePullAt holds the value of the most recent force pull
+	// request value, so a change of the annotation value can be detected.
+	// +optional
+	LastHandledForcePullAt string `json:"lastHandledForcePullAt,omitempty"`
+
+	// ManualRollbackActive indicates that the Helm release was rolled back
+	// on request, and that no upgrade is performed until the chart or the
+	// values change, or a force or reset is requested.
+	// +optional
+	ManualRollbackActive bool `json:"manualRollbackActive,omitempty"`
+
+	// LastReconcileDuration is the duration of the last reconciliation of
+	// the Helm release.
+	// +optional
+	LastReconcileDuration *metav1.Duration `json:"lastReconcileDuration,omitempty"`
+
+	// LastReconcilePhases holds the duration of each phase which ran during
+	// the last reconciliation of the Helm release, in the order they ran.
+	// +optional
+	LastReconcilePhases []ReconcilePhase `json:"lastReconcilePhases,omitempty"`
+
+	// CapabilityProfiles holds the results of the last render of the Helm
+	// release against the Spec.Preflight.CapabilityProfiles.
+	// +optional
+	CapabilityProfiles []CapabilityProfileResult `json:"capabilityProfiles,omitempty"`
+
+	// Hooks holds the hooks of the chart as last rendered for the Helm
+	// release, when enabled by Spec.Preflight.Hooks.
+	// +optional
+	Hooks *ChartHooks `json:"hooks,omitempty"`
+
+	// Remediations holds the remediation actions taken for the latest
+	// desired state, with the most recent first. It is reset together with
+	// the failure counters, and holds at most MaxRemediationRecords entries.
+	// +optional
+	Remediations []RemediationRecord `json:"remediations,omitempty"`
+
+	// ChartDependencies holds the resolved dependency tree of the chart as
+	// last prepared for the Helm release, flattened in depth-first order.
+	// +optional
+	ChartDependencies []ChartDependency `json:"chartDependencies,omitempty"`
+
+	// LastDiff holds the changes to the resources of the release of the last
+	// dry-run performed when Spec.DiffOnly is enabled.
+	// +optional
+	LastDiff *ReleaseDiff `json:"lastDiff,omitempty"`
+
+	// LastReleaseNotes holds the notes rendered from the NOTES.txt of the
+	// chart for the last Helm install or upgrade, truncated to at most
+	// MaxReleaseNotesSize bytes.
+	// +optional
+	LastReleaseNotes string `json:"lastReleaseNotes,omitempty"`
+
+	// CreatedNamespace is the name of the target namespace which was created
+	// by the controller for the Helm release, as opposed to a namespace which
+	// already existed. It is removed when the namespace is deleted.
+	// +optional
+	CreatedNamespace string `json:"createdNamespace,omitempty"`
+
+	// Inventory holds the resources of the Helm release as last successfully
+	// installed or upgraded, excluding hooks. It is removed when the release
+	// is uninstalled.
+	// +optional
+	Inventory *ResourceInventory `json:"inventory,omitempty"`
+
 	meta.ReconcileRequestStatus `json:",inline"`
 }
 
+const (
+	// ResourceChangeCreate indicates a resource would be created.
+	ResourceChangeCreate = "create"
+	// ResourceChangeUpdate indicates a resource would be updated.
+	ResourceChangeUpdate = "update"
+	// ResourceChangeDelete indicates a resource would be deleted.
+	ResourceChangeDelete = "delete"
+)
+
+// ReleaseDiff holds the changes a Helm release action would make to the
+// resources of the release.
+type ReleaseDiff struct {
+	// Action is the Helm action which was dry-run, either 'install' or
+	// 'upgrade'.
+	// +required
+	Action ReleaseAction `json:"action"`
+
+	// ChartName is the name of the chart of the candidate release.
+	// +required
+	ChartName string `json:"chartName"`
+
+	// ChartVersion is the version of the chart of the candidate release.
+	// +required
+	ChartVersion string `json:"chartVersion"`
+
+	// ConfigDigest is the checksum of the values of the candidate release.
+	// +optional
+	ConfigDigest string `json:"configDigest,omitempty"`
+
+	// Time is when the dry-run was performed.
+	// +required
+	Time metav1.Time `json:"time"`
+
+	// Changes holds the resources which would be changed by the action.
+	// +optional
+	Changes []ResourceChange `json:"changes,omitempty"`
+}
+
+// ResourceChange describes a change to a resource of a Helm release.
+type ResourceChange struct {
+	// Type of the change.
+	// +kubebuilder:validation:Enum=create;update;delete
+	// +required
+	Type string `json:"type"`
+
+	// APIVersion of the resource.
+	// +required
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the resource.
+	// +required
+	Kind string `json:"kind"`
+
+	// Namespace of the resource, if namespaced.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the resource.
+	// +required
+	Name string `json:"name"`
+}
+
+// ChartDependency holds the details of a resolved dependency (subchart) of
+// a chart.
+type ChartDependency struct {
+	// Name of the dependency chart.
+	// +required
+	Name string `json:"name"`
+
+	// Alias of the dependency, if set.
+	// +optional
+	Alias string `json:"alias,omitempty"`
+
+	// Path of the dependency in the dependency tree, as the slash separated
+	// names (or aliases) of its parents and itself. For example,
+	// 'backend/redis' for the 'redis' dependency of the 'backend' subchart.
+	// +required
+	Path string `json:"path"`
+
+	// Version of the resolved dependency chart. When the dependency chart
+	// is not packaged with the chart, this is the version constraint.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// Repository of the dependency, as defined in the chart.
+	// +optional
+	Repository string `json:"repository,omitempty"`
+
+	// Enabled indicates if the dependency is enabled, based on its condition
+	// and tags, and the enablement of its parents.
+	// +required
+	Enabled bool `json:"enabled"`
+}
+
+// ChartHooks holds the hooks of a chart, as rendered for a Helm release.
+type ChartHooks struct {
+	// ChartName is the name of the chart the hooks were rendered from.
+	// +required
+	ChartName string `json:"chartName"`
+
+	// ChartVersion is the version of the chart the hooks were rendered from.
+	// +required
+	ChartVersion string `json:"chartVersion"`
+
+	// Items holds the hook resources of the chart, in the order they were
+	// rendered.
+	// +optional
+	Items []HookResource `json:"items,omitempty"`
+}
+
+// HookResource holds the details of a rendered Helm hook resource.
+type HookResource struct {
+	// Name of the hook resource.
+	// +required
+	Name string `json:"name"`
+
+	// Kind of the hook resource.
+	// +required
+	Kind string `json:"kind"`
+
+	// Path of the template the hook resource was rendered from.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// Events are the Helm events the hook runs for, e.g. 'pre-install'.
+	// +optional
+	Events []string `json:"events,omitempty"`
+
+	// Weight of the hook, which determines the order in which hooks for the
+	// same event are run.
+	// +optional
+	Weight int `json:"weight,omitempty"`
+
+	// DeletePolicies define when the hook resource is deleted, e.g.
+	// 'before-hook-creation'.
+	// +optional
+	DeletePolicies []string `json:"deletePolicies,omitempty"`
+}
+
+// NamespaceDeletePolicy determines whether a namespace created by the
+// controller is deleted on uninstall.
+type NamespaceDeletePolicy string
+
+const (
+	// NamespaceDeletePolicyRetain retains the namespace on uninstall.
+	NamespaceDeletePolicyRetain NamespaceDeletePolicy = "Retain"
+	// NamespaceDeletePolicyDelete deletes the namespace on uninstall, if it
+	// was created by the controller.
+	NamespaceDeletePolicyDelete NamespaceDeletePolicy = "Delete"
+)
+
+// CreateNamespaceOptions holds the options for the creation of the target
+// namespace of a Helm release.
+type CreateNamespaceOptions struct {
+	// CommonMetadata sets the labels and annotations of the
+	// HelmReleaseSpec.CommonMetadata on the created namespace.
+	// +optional
+	CommonMetadata bool `json:"commonMetadata,omitempty"`
+
+	// DeletePolicy determines whether the namespace is deleted when the
+	// Helm release is uninstalled on the deletion of the HelmRelease. Only
+	// a namespace created by the controller is deleted. Defaults to 'Retain'.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +optional
+	DeletePolicy NamespaceDeletePolicy `json:"deletePolicy,omitempty"`
+}
+
+// MustDeleteNamespace returns true if a namespace created by the controller
+// must be deleted on uninstall.
+func (in *CreateNamespaceOptions) MustDeleteNamespace() bool {
+	return in != nil && in.DeletePolicy == NamespaceDeletePolicyDelete
+}
+
+// MaxRemediationRecords is the maximum number of RemediationRecords kept in
+// the HelmReleaseStatus.
+const MaxRemediationRecords = 10
+
+// MaxReleaseNotesSize is the maximum size in bytes of the
+// HelmReleaseStatus.LastReleaseNotes.
+const MaxReleaseNotesSize = 4096
+
+// MaxInventoryEntries is the maximum number of entries listed in the
+// HelmReleaseStatus.Inventory. Larger inventories are only recorded by
+// their count and digest.
+const MaxInventoryEntries = 1000
+
+// ResourceInventory holds the resources managed by a Helm release.
+type ResourceInventory struct {
+	// Count is the number of resources in the inventory.
+	// +required
+	Count int `json:"count"`
+
+	// Digest is the digest of the sorted IDs of the resources in the
+	// inventory, which changes when a resource is added or removed.
+	// +required
+	Digest string `json:"digest"`
+
+	// Entries holds the references to the resources, sorted by ID. It is
+	// omitted when the inventory holds more than MaxInventoryEntries
+	// resources.
+	// +optional
+	Entries []ResourceRef `json:"entries,omitempty"`
+
+	// Release is the Helm release the resources belong to, in the format
+	// '<namespace>/<name>'. It is used to confirm the ownership of the
+	// resources before they are pruned.
+	// +optional
+	Release string `json:"release,omitempty"`
+}
+
+// ResourceRef is a reference to a Kubernetes resource object.
+type ResourceRef struct {
+	// ID is the string representation of the Kubernetes resource object's
+	// metadata, in the format '<namespace>_<name>_<group>_<kind>'.
+	// +required
+	ID string `json:"id"`
+
+	// Version is the API version of the Kubernetes resource object's kind.
+	// +required
+	Version string `json:"v"`
+}
+
+// RemediationRecord holds the details of a remediation action taken for a
+// failed Helm release.
+type RemediationRecord struct {
+	// Attempt is the sequence number of the remediation for the latest
+	// desired state, starting at 1.
+	// +required
+	Attempt int64 `json:"attempt"`
+
+	// Action is the remediation action which was taken.
+	// +kubebuilder:validation:Enum=rollback;uninstall
+	// +required
+	Action string `json:"action"`
+
+	// Release is the name of the Helm release the remediation action
+	// targeted, in the format '<namespace>/<name>.v<version>'. For a
+	// rollback, this is the release which was rolled back to.
+	// +optional
+	Release string `json:"release,omitempty"`
+
+	// Cause is the message of the failure which triggered the remediation.
+	// +optional
+	Cause string `json:"cause,omitempty"`
+
+	// Succeeded indicates if the remediation action succeeded.
+	// +required
+	Succeeded bool `json:"succeeded"`
+
+	// Time is the time at which the remediation action completed.
+	// +required
+	Time metav1.Time `json:"time"`
+}
+
+const (
+	// RemediationActionRollback is the RemediationRecord action for a
+	// rollback remediation.
+	RemediationActionRollback = "rollback"
+
+	// RemediationActionUninstall is the RemediationRecord action for an
+	// uninstall remediation.
+	RemediationActionUninstall = "uninstall"
+)
+
+// EffectiveConfig holds the configuration of a HelmRelease as resolved from
+// its spec and the controller level defaults.
+type EffectiveConfig struct {
+	// ServiceAccountName is the name of the service account impersonated
+	// while reconciling the HelmRelease. Empty when no service account is
+	// impersonated.
+	// +optional
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// DisableWait indicates if waiting for resources to be ready is disabled
+	// by default for the Helm actions of the HelmRelease.
+	// +optional
+	DisableWait bool `json:"disableWait,omitempty"`
+}
+
+// ClientIdentity holds the identity used by the controller to access the
+// cluster the Helm release is applied to. It never contains credentials.
+type ClientIdentity struct {
+	// Impersonate is the username impersonated by the controller, e.g.
+	// 'system:serviceaccount:<namespace>:<name>'. Empty when no user is
+	// impersonated, in which case the identity of the controller or the
+	// KubeConfig user is used.
+	// +optional
+	Impersonate string `json:"impersonate,omitempty"`
+
+	// KubeConfigSecret is the name of the Secret the KubeConfig was loaded
+	// from. Empty when the cluster the controller runs in is targeted.
+	// +optional
+	KubeConfigSecret string `json:"kubeConfigSecret,omitempty"`
+
+	// KubeConfigContext is the name of the current context of the
+	// KubeConfig.
+	// +optional
+	KubeConfigContext string `json:"kubeConfigContext,omitempty"`
+
+	// KubeConfigUser is the name of the user of the current context of the
+	// KubeConfig.
+	// +optional
+	KubeConfigUser string `json:"kubeConfigUser,omitempty"`
+}
+
+// ReconcilePhase holds the duration of a phase of a reconciliation.
+type ReconcilePhase struct {
+	// Name of the phase, e.g. 'fetch', 'compose', or the name of the Helm
+	// action which ran (which includes rendering, applying and waiting).
+	// +required
+	Name string `json:"name"`
+
+	// Duration of the phase.
+	// +required
+	Duration metav1.Duration `json:"duration"`
+}
+
+const (
+	// ReconcilePhaseFetch is the name of the phase in which the chart
+	// artifact is fetched and loaded.
+	ReconcilePhaseFetch = "fetch"
+
+	// ReconcilePhaseCompose is the name of the phase in which the values
+	// are composed from the spec and references.
+	ReconcilePhaseCompose = "compose"
+)
+
+// AddReconcilePhase records the duration of a phase of the reconciliation.
+func (in *HelmReleaseStatus) AddReconcilePhase(name string, d time.Duration) {
+	in.LastReconcilePhases = append(in.LastReconcilePhases, ReconcilePhase{
+		Name:     name,
+		Duration: metav1.Duration{Duration: d.Round(time.Millisecond)},
+	})
+}
+
 // ClearHistory clears the History.
 func (in *HelmReleaseStatus) ClearHistory() {
 	in.History = nil
 }
 
-// ClearFailures clears the failure counters.
+// ClearFailures clears the failure counters and the remediation records.
 func (in *HelmReleaseStatus) ClearFailures() {
 	in.Failures = 0
 	in.InstallFailures = 0
 	in.UpgradeFailures = 0
+	in.RemediationRetriesRemaining = nil
+	in.FirstFailureTime = nil
+	in.ConsecutiveFailures = 0
+	in.LastFailureClass = ""
+	in.Remediations = nil
+}
+
+// RecordRemediation prepends the given RemediationRecord to the
+// Remediations, with its Attempt set to one more than the most recent
+// record. Records exceeding MaxRemediationRecords are dropped.
+func (in *HelmReleaseStatus) RecordRemediation(rec RemediationRecord) {
+	rec.Attempt = 1
+	if len(in.Remediations) > 0 {
+		rec.Attempt = in.Remediations[0].Attempt + 1
+	}
+	in.Remediations = append([]RemediationRecord{rec}, in.Remediations...)
+	if len(in.Remediations) > MaxRemediationRecords {
+		in.Remediations = in.Remediations[:MaxRemediationRecords]
+	}
 }
 
 // GetHelmChart returns the namespace and name of the HelmChart.
@@ -1067,6 +2537,9 @@ const (
 	// SourceIndexKey is the key used for indexing HelmReleases based on
 	// their sources.
 	SourceIndexKey string = ".metadata.source"
+	// EmbeddedChartIndexKey is the key used for indexing HelmReleases based
+	// on the ConfigMap or Secret their chart is embedded in.
+	EmbeddedChartIndexKey string = ".metadata.embeddedChart"
 )
 
 // +genclient
@@ -1088,6 +2561,15 @@ type HelmRelease struct {
 	Status HelmReleaseStatus `json:"status,omitempty"`
 }
 
+// GetPreflight returns the configuration for checks performed before a Helm
+// install or upgrade action.
+func (in *HelmRelease) GetPreflight() Preflight {
+	if in.Spec.Preflight == nil {
+		return Preflight{}
+	}
+	return *in.Spec.Preflight
+}
+
 // GetDriftDetection returns the configuration for detecting and handling
 // differences between the manifest in the Helm storage and the resources
 // currently existing in the cluster.
@@ -1098,6 +2580,93 @@ func (in *HelmRelease) GetDriftDetection() DriftDetection {
 	return *in.Spec.DriftDetection
 }
 
+// GetChartDeprecationPolicy returns the configured ChartDeprecationPolicy,
+// or ChartDeprecationWarn if not set.
+func (in *HelmRelease) GetChartDeprecationPolicy() ChartDeprecationPolicy {
+	if in.Spec.ChartDeprecationPolicy == "" {
+		return ChartDeprecationWarn
+	}
+	return in.Spec.ChartDeprecationPolicy
+}
+
+// GetSourceSuspendedPolicy returns the configured SourceSuspendedPolicy,
+// or SourceSuspendedContinue if not set.
+func (in *HelmRelease) GetSourceSuspendedPolicy() SourceSuspendedPolicy {
+	if in.Spec.SourceSuspendedPolicy == "" {
+		return SourceSuspendedContinue
+	}
+	return in.Spec.SourceSuspendedPolicy
+}
+
+// GetChartPullAuthFailurePolicy returns the configured
+// ChartPullAuthFailurePolicy, or ChartPullAuthFailureRetry if not set.
+func (in *HelmRelease) GetChartPullAuthFailurePolicy() ChartPullAuthFailurePolicy {
+	if in.Spec.ChartPullAuthFailurePolicy == "" {
+		return ChartPullAuthFailureRetry
+	}
+	return in.Spec.ChartPullAuthFailurePolicy
+}
+
+// GetNamespaceTerminationPolicy returns the configured
+// NamespaceTerminationPolicy, or NamespaceTerminationWait if not set.
+func (in *HelmRelease) GetNamespaceTerminationPolicy() NamespaceTerminationPolicy {
+	if in.Spec.NamespaceTerminationPolicy == "" {
+		return NamespaceTerminationWait
+	}
+	return in.Spec.NamespaceTerminationPolicy
+}
+
+// ReleaseHealth is the result of the health checks of the resources of a
+// Helm release.
+type ReleaseHealth struct {
+	// Version is the version of the Helm release the resources belong to.
+	// +required
+	Version int `json:"version"`
+
+	// LastChecked is the time at which the health checks were last
+	// performed.
+	// +required
+	LastChecked metav1.Time `json:"lastChecked"`
+
+	// Healthy is true if all checked resources are healthy.
+	// +required
+	Healthy bool `json:"healthy"`
+
+	// Resources holds the health of the individual checked resources.
+	// +optional
+	Resources []ResourceHealthStatus `json:"resources,omitempty"`
+}
+
+// ResourceHealthStatus is the health of a single resource of a Helm release.
+type ResourceHealthStatus struct {
+	// Name is the name of the resource, in the format
+	// '<kind>/<namespace>/<name>'.
+	// +required
+	Name string `json:"name"`
+
+	// Status is the computed status of the resource, one of 'Current',
+	// 'InProgress', 'Failed', 'Terminating', 'NotFound' or 'Unknown'.
+	// +required
+	Status string `json:"status"`
+
+	// Message holds details about the status of the resource.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// UsesHealthChecks returns true if the health of the resources of the
+// HelmRelease is checked after a release action.
+func (in *HelmRelease) UsesHealthChecks() bool {
+	return in.Spec.HealthChecks != nil && (in.Spec.HealthChecks.All || len(in.Spec.HealthChecks.Resources) > 0)
+}
+
+// UsesReadinessThreshold returns true if the readiness of the HelmRelease is
+// computed from a threshold of healthy resources, instead of Helm waiting for
+// all resources to become ready.
+func (in *HelmRelease) UsesReadinessThreshold() bool {
+	return in.Spec.Readiness != nil && in.Spec.Readiness.Threshold > 0
+}
+
 // GetInstall returns the configuration for Helm install actions for the
 // HelmRelease.
 func (in *HelmRelease) GetInstall() Install {
@@ -1201,6 +2770,15 @@ func (in HelmRelease) GetStorageNamespace() string {
 	return in.Namespace
 }
 
+// GetStorageDriver returns the configured StorageDriver, or the default
+// StorageDriverSecret.
+func (in HelmRelease) GetStorageDriver() string {
+	if in.Spec.StorageDriver != "" {
+		return in.Spec.StorageDriver
+	}
+	return StorageDriverSecret
+}
+
 // GetHelmChartName returns the name used by the controller for the HelmChart creation.
 func (in HelmRelease) GetHelmChartName() string {
 	return strings.Join([]string{in.Namespace, in.Name}, "-")
@@ -1214,8 +2792,12 @@ func (in HelmRelease) GetTimeout() metav1.Duration {
 	return *in.Spec.Timeout
 }
 
-// GetMaxHistory returns the configured MaxHistory, or the default of 5.
+// GetMaxHistory returns the configured HistoryRetention.MaxCount or
+// MaxHistory, or the default of 5.
 func (in HelmRelease) GetMaxHistory() int {
+	if in.Spec.HistoryRetention != nil && in.Spec.HistoryRetention.MaxCount != nil {
+		return *in.Spec.HistoryRetention.MaxCount
+	}
 	if in.Spec.MaxHistory == nil {
 		return defaultMaxHistory
 	}
@@ -1256,6 +2838,12 @@ func (in *HelmRelease) HasChartTemplate() bool {
 	return in.Spec.Chart != nil
 }
 
+// MustUpdateDependencies returns true if the dependencies of the chart
+// which are not vendored in it must be built before it is rendered.
+func (in *HelmRelease) MustUpdateDependencies() bool {
+	return in.Spec.Chart != nil && in.Spec.Chart.DependencyUpdate
+}
+
 // +kubebuilder:object:root=true
 
 // HelmReleaseList contains a list of HelmRelease objects.
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// HelmReleaseGroupKind is the kind in string format.
+	HelmReleaseGroupKind = "HelmReleaseGroup"
+)
+
+// HelmReleaseGroupSpec defines the desired state of a HelmReleaseGroup.
+type HelmReleaseGroupSpec struct {
+	// Selector selects the HelmReleases in the namespace of the
+	// HelmReleaseGroup which are members of the group, based on their labels.
+	// An empty selector selects all HelmReleases in the namespace.
+	// +required
+	Selector metav1.LabelSelector `json:"selector"`
+}
+
+// HelmReleaseGroupStatus defines the observed state of a HelmReleaseGroup.
+type HelmReleaseGroupStatus struct {
+	// ObservedGeneration is the last observed generation.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Conditions holds the conditions for the HelmReleaseGroup.
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Total is the number of HelmReleases which are members of the group.
+	// +optional
+	Total int `json:"total"`
+
+	// Ready is the number of members of the group which are Ready.
+	// +optional
+	Ready int `json:"ready"`
+
+	// FailingMembers holds the members of the group which are not Ready,
+	// sorted by name.
+	// +optional
+	FailingMembers []HelmReleaseGroupMember `json:"failingMembers,omitempty"`
+}
+
+// HelmReleaseGroupMember holds the readiness of a member of a
+// HelmReleaseGroup.
+type HelmReleaseGroupMember struct {
+	// Name of the HelmRelease.
+	// +required
+	Name string `json:"name"`
+
+	// Status of the Ready condition of the HelmRelease.
+	// +required
+	Status metav1.ConditionStatus `json:"status"`
+
+	// Reason of the Ready condition of the HelmRelease.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// Message of the Ready condition of the HelmRelease.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// GetConditions returns the status conditions of the object.
+func (in HelmReleaseGroup) GetConditions() []metav1.Condition {
+	return in.Status.Conditions
+}
+
+// SetConditions sets the status conditions on the object.
+func (in *HelmReleaseGroup) SetConditions(conditions []metav1.Condition) {
+	in.Status.Conditions = conditions
+}
+
+// +genclient
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:shortName=hrg
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+// +kubebuilder:printcolumn:name="Members",type="integer",JSONPath=".status.total",description=""
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status",description=""
+// +kubebuilder:printcolumn:name="Status",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].message",description=""
+
+// HelmReleaseGroup is the Schema for the helmreleasegroups API, which
+// aggregates the readiness of a group of HelmReleases.
+type HelmReleaseGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec HelmReleaseGroupSpec `json:"spec,omitempty"`
+	// +kubebuilder:default:={"observedGeneration":-1}
+	Status HelmReleaseGroupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// HelmReleaseGroupList contains a list of HelmReleaseGroup objects.
+type HelmReleaseGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []HelmReleaseGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&HelmReleaseGroup{}, &HelmReleaseGroupList{})
+}
@@ -16,6 +16,10 @@ limitations under the License.
 
 package v2
 
+import (
+	"github.com/fluxcd/pkg/apis/meta"
+)
+
 // CrossNamespaceObjectReference contains enough information to let you locate
 // the typed referenced object at cluster level.
 type CrossNamespaceObjectReference struct {
@@ -44,13 +48,17 @@ type CrossNamespaceObjectReference struct {
 
 // CrossNamespaceSourceReference contains enough information to let you locate
 // the typed referenced object at cluster level.
+// +kubebuilder:validation:XValidation:rule="!has(self.verify) || self.kind == 'OCIRepository'",message="verify is only supported for the OCIRepository kind"
+// +kubebuilder:validation:XValidation:rule="!has(self.key) || self.kind == 'ConfigMap' || self.kind == 'Secret'",message="key is only supported for the ConfigMap and Secret kinds"
 type CrossNamespaceSourceReference struct {
 	// APIVersion of the referent.
 	// +optional
 	APIVersion string `json:"apiVersion,omitempty"`
 
-	// Kind of the referent.
-	// +kubebuilder:validation:Enum=OCIRepository;HelmChart
+	// Kind of the referent. A ConfigMap or Secret refers to a packaged chart
+	// embedded in the data of the referent at Key, which allows installing a
+	// chart without a source-controller.
+	// +kubebuilder:validation:Enum=OCIRepository;HelmChart;ConfigMap;Secret
 	// +required
 	Kind string `json:"kind"`
 
@@ -67,10 +75,78 @@ type CrossNamespaceSourceReference struct {
 	// +kubebuilder:validation:Optional
 	// +optional
 	Namespace string `json:"namespace,omitempty"`
+
+	// Verify configures the verification of the signature of the chart
+	// artifact before it is released. The signature must have been verified
+	// by the source-controller using the same provider and trusted public
+	// keys. This field is only supported for the OCIRepository kind.
+	// +optional
+	Verify *ChartRefVerification `json:"verify,omitempty"`
+
+	// Key is the data key of the ConfigMap or Secret at which the packaged
+	// chart can be found, either as a gzipped tarball or base64 encoded.
+	// Defaults to 'chart.tgz'. This field is only supported for the
+	// ConfigMap and Secret kinds.
+	// +kubebuilder:validation:MaxLength=253
+	// +kubebuilder:validation:Pattern=`^[\-._a-zA-Z0-9]+$`
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// DefaultEmbeddedChartKey is the default data key of a ConfigMap or Secret
+// referenced by a ChartRef at which the packaged chart can be found.
+const DefaultEmbeddedChartKey = "chart.tgz"
+
+// GetKey returns the configured Key, or DefaultEmbeddedChartKey.
+func (in CrossNamespaceSourceReference) GetKey() string {
+	if in.Key == "" {
+		return DefaultEmbeddedChartKey
+	}
+	return in.Key
+}
+
+// ChartRefVerification configures the verification of the signature of the
+// chart artifact referenced by a ChartRef.
+type ChartRefVerification struct {
+	// Provider specifies the technology used to sign the chart artifact.
+	// +kubebuilder:validation:Enum=cosign;notation
+	// +kubebuilder:default:=cosign
+	Provider string `json:"provider"`
+
+	// SecretRef specifies the Kubernetes Secret containing the trusted
+	// public keys. When set, the signature must have been verified using
+	// only public keys from this Secret.
+	// +optional
+	SecretRef *meta.LocalObjectReference `json:"secretRef,omitempty"`
 }
 
+const (
+	// MergeStrategyDeepMerge merges the values of a ValuesReference into the
+	// values composed so far, by recursively merging maps. Any other value,
+	// including a list, replaces the value composed so far.
+	MergeStrategyDeepMerge = "deepMerge"
+	// MergeStrategyReplace replaces the top-level keys of the values composed
+	// so far with the keys in the values of a ValuesReference, without
+	// merging nested maps.
+	MergeStrategyReplace = "replace"
+	// MergeStrategyJSONPatch applies the values of a ValuesReference as a
+	// JSON Patch (RFC 6902) to the values composed so far.
+	MergeStrategyJSONPatch = "jsonPatch"
+)
+
+const (
+	// ValueTypeJSON sets the value of a ValuesReference at its TargetPath
+	// after parsing it as JSON.
+	ValueTypeJSON = "json"
+	// ValueTypeFile sets the raw value of a ValuesReference as a string at
+	// its TargetPath.
+	ValueTypeFile = "file"
+)
+
 // ValuesReference contains a reference to a resource containing Helm values,
 // and optionally the key they can be found at.
+// +kubebuilder:validation:XValidation:rule="!has(self.targetPath) || !has(self.mergeStrategy) || self.mergeStrategy == 'deepMerge'",message="mergeStrategy can not be combined with targetPath"
+// +kubebuilder:validation:XValidation:rule="!has(self.valueType) || has(self.targetPath)",message="valueType requires targetPath"
 type ValuesReference struct {
 	// Kind of the values referent, valid values are ('Secret', 'ConfigMap').
 	// +kubebuilder:validation:Enum=Secret;ConfigMap
@@ -78,12 +154,20 @@ type ValuesReference struct {
 	Kind string `json:"kind"`
 
 	// Name of the values referent. Should reside in the same namespace as the
-	// referring resource.
+	// referring resource, unless Namespace is set.
 	// +kubebuilder:validation:MinLength=1
 	// +kubebuilder:validation:MaxLength=253
 	// +required
 	Name string `json:"name"`
 
+	// Namespace of the values referent, defaults to the namespace of the
+	// referring resource. Referring to a resource in another namespace
+	// requires cross-namespace references to be allowed on the controller.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
 	// ValuesKey is the data key where the values.yaml or a specific value can be
 	// found at. Defaults to 'values.yaml'.
 	// +kubebuilder:validation:MaxLength=253
@@ -99,6 +183,35 @@ type ValuesReference struct {
 	// +optional
 	TargetPath string `json:"targetPath,omitempty"`
 
+	// ValueType is the type of the value at the ValuesKey, which determines
+	// how it is set at the TargetPath. Valid values are 'json' and 'file'.
+	// Defaults to 'None', which parses the value like Helm's --set flag.
+	//
+	// json: the value is parsed as JSON, and the result is set at the
+	// TargetPath, like Helm's --set-json flag.
+	//
+	// file: the raw value is set as a string at the TargetPath, like Helm's
+	// --set-file flag.
+	// +kubebuilder:validation:Enum=json;file
+	// +optional
+	ValueType string `json:"valueType,omitempty"`
+
+	// MergeStrategy is the strategy used to combine the values with the values
+	// composed from the previous references. Valid values are 'deepMerge',
+	// 'replace' and 'jsonPatch'. Defaults to 'deepMerge'.
+	//
+	// deepMerge: maps are merged recursively, any other value (including a
+	// list) replaces the value composed so far.
+	//
+	// replace: the top-level keys replace the keys composed so far, without
+	// merging nested maps.
+	//
+	// jsonPatch: the data is a JSON Patch (RFC 6902) document in JSON or YAML,
+	// which is applied to the values composed so far.
+	// +kubebuilder:validation:Enum=deepMerge;replace;jsonPatch
+	// +optional
+	MergeStrategy string `json:"mergeStrategy,omitempty"`
+
 	// Optional marks this ValuesReference as optional. When set, a not found error
 	// for the values reference is ignored, but any ValuesKey, TargetPath or
 	// transient error will still result in a reconciliation failure.
@@ -106,6 +219,24 @@ type ValuesReference struct {
 	Optional bool `json:"optional,omitempty"`
 }
 
+// GetNamespace returns the defined Namespace, or the given default namespace
+// of the referring resource.
+func (in ValuesReference) GetNamespace(defaultNamespace string) string {
+	if in.Namespace == "" {
+		return defaultNamespace
+	}
+	return in.Namespace
+}
+
+// GetMergeStrategy returns the defined MergeStrategy, or the default
+// (MergeStrategyDeepMerge).
+func (in ValuesReference) GetMergeStrategy() string {
+	if in.MergeStrategy == "" {
+		return MergeStrategyDeepMerge
+	}
+	return in.MergeStrategy
+}
+
 // GetValuesKey returns the defined ValuesKey, or the default ('values.yaml').
 func (in ValuesReference) GetValuesKey() string {
 	if in.ValuesKey == "" {
@@ -19,6 +19,7 @@ package v2
 import (
 	"fmt"
 	"sort"
+	"time"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -30,6 +31,12 @@ const (
 	// snapshotStatusSuperseded indicates that the release the snapshot was taken
 	// from has been superseded by a newer release.
 	snapshotStatusSuperseded = "superseded"
+	// snapshotStatusFailed indicates that the release the snapshot was taken
+	// from has failed.
+	snapshotStatusFailed = "failed"
+	// snapshotStatusUninstalled indicates that the release the snapshot was
+	// taken from has been uninstalled.
+	snapshotStatusUninstalled = "uninstalled"
 
 	// snapshotTestPhaseFailed indicates that the test of the release the snapshot
 	// was taken from has failed.
@@ -107,6 +114,35 @@ func (in *Snapshots) Truncate(ignoreTests bool) {
 	}
 }
 
+// PruneDeployedBefore removes the Snapshots which were last deployed before
+// the given time, and returns the removed Snapshots. The Latest Snapshot and
+// the most recent Snapshot with a status of "deployed" or "superseded" are
+// always retained.
+func (in *Snapshots) PruneDeployedBefore(t time.Time) Snapshots {
+	if in.Len() < 2 {
+		return nil
+	}
+
+	in.SortByVersion()
+	var (
+		retained, removed Snapshots
+		successful        bool
+	)
+	for i, s := range *in {
+		keep := i == 0 || !s.LastDeployed.Time.Before(t)
+		if !successful && (s.Status == snapshotStatusDeployed || s.Status == snapshotStatusSuperseded) {
+			successful, keep = true, true
+		}
+		if keep {
+			retained = append(retained, s)
+			continue
+		}
+		removed = append(removed, s)
+	}
+	*in = retained
+	return removed
+}
+
 // Snapshot captures a point-in-time copy of the status information for a Helm release,
 // as managed by the controller.
 type Snapshot struct {
@@ -131,6 +167,11 @@ type Snapshot struct {
 	// Status is the current state of the release.
 	// +required
 	Status string `json:"status"`
+	// StatusReason is the reason for the current state of the release, as
+	// described by Helm (e.g. 'Upgrade complete', or the error which caused
+	// the release to fail), truncated to 256 characters.
+	// +optional
+	StatusReason string `json:"statusReason,omitempty"`
 	// ChartName is the chart name of the release object in storage.
 	// +required
 	ChartName string `json:"chartName"`
@@ -138,6 +179,18 @@ type Snapshot struct {
 	// storage.
 	// +required
 	ChartVersion string `json:"chartVersion"`
+	// ChartSourceRevision is the revision of the source artifact (HelmChart
+	// or OCIRepository) from which the chart of the release was loaded.
+	// +optional
+	ChartSourceRevision string `json:"chartSourceRevision,omitempty"`
+	// ChartDigest is the digest of the source artifact from which the chart
+	// of the release was loaded.
+	// +optional
+	ChartDigest string `json:"chartDigest,omitempty"`
+	// CRDsPolicy is the policy with which the CustomResourceDefinitions of
+	// the chart were applied by the Helm action which produced the release.
+	// +optional
+	CRDsPolicy CRDsPolicy `json:"crdsPolicy,omitempty"`
 	// AppVersion is the chart app version of the release object in storage.
 	// +optional
 	AppVersion string `json:"appVersion,omitempty"`
@@ -162,6 +215,49 @@ type Snapshot struct {
 	// OCIDigest is the digest of the OCI artifact associated with the release.
 	// +optional
 	OCIDigest string `json:"ociDigest,omitempty"`
+	// ValuesDigests holds the checksum of the config (better known as
+	// "values") of the release object in storage per top-level key, to
+	// allow attributing config changes to a revision without storing the
+	// values themselves.
+	// Each digest has the format of `<algo>:<checksum>`.
+	// +optional
+	ValuesDigests map[string]string `json:"valuesDigests,omitempty"`
+	// SupersededBy is the version of the release which superseded this
+	// release, either by an upgrade or a rollback, as observed by the
+	// controller.
+	// +optional
+	SupersededBy int `json:"supersededBy,omitempty"`
+	// Duration is the time the Helm action which produced the release took,
+	// from the start of the action to the release being written to the
+	// storage, as observed by the controller.
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+	// ValuesChecksum is the checksum of the fully merged values of the
+	// release, which are the values of the release object in storage merged
+	// with the default values of the chart, as composed by the controller.
+	// It has the format of `<algo>:<checksum>`.
+	// +optional
+	ValuesChecksum string `json:"valuesChecksum,omitempty"`
+	// Values is a YAML snapshot of the fully merged values of the release,
+	// truncated to 2048 bytes. Values sourced from Secrets are replaced by
+	// their checksum. It is only recorded when the ValuesSnapshotInHistory
+	// feature gate is enabled.
+	// +optional
+	Values string `json:"values,omitempty"`
+	// VerifiedDigest is the digest of the OCI artifact associated with the
+	// release, of which the signature was verified as configured in the
+	// ChartRef of the HelmRelease.
+	// +optional
+	VerifiedDigest string `json:"verifiedDigest,omitempty"`
+	// VerificationDigest is the digest of the verification configuration in
+	// the ChartRef of the HelmRelease, including the data of the Secret with
+	// the trusted public keys, with which the VerifiedDigest was verified.
+	// +optional
+	VerificationDigest string `json:"verificationDigest,omitempty"`
+	// Backup is the name of the backup of the release object taken before
+	// it was upgraded, when backups are enabled for the Helm upgrade action.
+	// +optional
+	Backup string `json:"backup,omitempty"`
 }
 
 // FullReleaseName returns the full name of the release in the format
@@ -224,6 +320,29 @@ func (in *Snapshot) Targets(name, namespace string, version int) bool {
 	return false
 }
 
+// ChangedValues returns the sorted list of top-level values keys of which
+// the digest differs between the Snapshot and the given previous Snapshot,
+// including keys which have been added or removed. It returns nil if either
+// Snapshot does not have ValuesDigests.
+func (in *Snapshot) ChangedValues(prev *Snapshot) []string {
+	if in == nil || prev == nil || in.ValuesDigests == nil || prev.ValuesDigests == nil {
+		return nil
+	}
+	var changed []string
+	for k, v := range in.ValuesDigests {
+		if prev.ValuesDigests[k] != v {
+			changed = append(changed, k)
+		}
+	}
+	for k := range prev.ValuesDigests {
+		if _, ok := in.ValuesDigests[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
 // TestHookStatus holds the status information for a test hook as observed
 // to be run by the controller.
 type TestHookStatus struct {
@@ -236,4 +355,12 @@ type TestHookStatus struct {
 	// Phase the test hook was observed to be in.
 	// +optional
 	Phase string `json:"phase,omitempty"`
+	// Attempts is the number of times the test hook was run during the
+	// last test action, including retries.
+	// +optional
+	Attempts int `json:"attempts,omitempty"`
+	// FailedAttempts is the number of runs of the test hook which failed
+	// during the last test action.
+	// +optional
+	FailedAttempts int `json:"failedAttempts,omitempty"`
 }
@@ -19,6 +19,9 @@ package v2
 import (
 	"reflect"
 	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestSnapshots_Sort(t *testing.T) {
@@ -296,3 +299,127 @@ func TestSnapshots_Truncate(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshots_PruneDeployedBefore(t *testing.T) {
+	now := time.Now()
+	deployed := func(version int, status string, age time.Duration) *Snapshot {
+		return &Snapshot{Version: version, Status: status, LastDeployed: metav1.NewTime(now.Add(-age))}
+	}
+	versions := func(in Snapshots) []int {
+		var v []int
+		for _, s := range in {
+			v = append(v, s.Version)
+		}
+		return v
+	}
+
+	tests := []struct {
+		name        string
+		in          Snapshots
+		want        []int
+		wantRemoved []int
+	}{
+		{
+			name: "single snapshot",
+			in: Snapshots{
+				deployed(1, snapshotStatusDeployed, 48*time.Hour),
+			},
+			want: []int{1},
+		},
+		{
+			name: "removes snapshots older than cutoff",
+			in: Snapshots{
+				deployed(1, snapshotStatusSuperseded, 72*time.Hour),
+				deployed(2, snapshotStatusSuperseded, 48*time.Hour),
+				deployed(3, snapshotStatusSuperseded, 12*time.Hour),
+				deployed(4, snapshotStatusDeployed, time.Hour),
+			},
+			want:        []int{4, 3},
+			wantRemoved: []int{2, 1},
+		},
+		{
+			name: "retains latest and latest successful snapshot",
+			in: Snapshots{
+				deployed(1, snapshotStatusSuperseded, 96*time.Hour),
+				deployed(2, snapshotStatusDeployed, 72*time.Hour),
+				deployed(3, snapshotStatusFailed, 48*time.Hour),
+				deployed(4, snapshotStatusFailed, 36*time.Hour),
+			},
+			want:        []int{4, 2},
+			wantRemoved: []int{3, 1},
+		},
+		{
+			name: "mixed timestamps",
+			in: Snapshots{
+				deployed(6, snapshotStatusFailed, 30*time.Minute),
+				deployed(2, snapshotStatusSuperseded, 20*time.Hour),
+				deployed(5, snapshotStatusSuperseded, 26*time.Hour),
+				deployed(3, snapshotStatusFailed, 50*time.Hour),
+				deployed(4, snapshotStatusFailed, 2*time.Hour),
+				deployed(1, snapshotStatusSuperseded, 60*time.Hour),
+			},
+			want:        []int{6, 5, 4, 2},
+			wantRemoved: []int{3, 1},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			removed := tt.in.PruneDeployedBefore(now.Add(-24 * time.Hour))
+
+			if got := versions(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("PruneDeployedBefore() retained %v, want %v", got, tt.want)
+			}
+			if got := versions(removed); !reflect.DeepEqual(got, tt.wantRemoved) {
+				t.Errorf("PruneDeployedBefore() removed %v, want %v", got, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestSnapshot_ChangedValues(t *testing.T) {
+	tests := []struct {
+		name string
+		in   *Snapshot
+		prev *Snapshot
+		want []string
+	}{
+		{
+			name: "no previous snapshot",
+			in:   &Snapshot{ValuesDigests: map[string]string{"image": "sha256:a"}},
+			want: nil,
+		},
+		{
+			name: "previous snapshot without digests",
+			in:   &Snapshot{ValuesDigests: map[string]string{"image": "sha256:a"}},
+			prev: &Snapshot{},
+			want: nil,
+		},
+		{
+			name: "unchanged values",
+			in:   &Snapshot{ValuesDigests: map[string]string{"image": "sha256:a"}},
+			prev: &Snapshot{ValuesDigests: map[string]string{"image": "sha256:a"}},
+			want: nil,
+		},
+		{
+			name: "changed, added and removed values",
+			in: &Snapshot{ValuesDigests: map[string]string{
+				"image":    "sha256:b",
+				"replicas": "sha256:c",
+				"service":  "sha256:d",
+			}},
+			prev: &Snapshot{ValuesDigests: map[string]string{
+				"image":    "sha256:a",
+				"ingress":  "sha256:e",
+				"replicas": "sha256:c",
+			}},
+			want: []string{"image", "ingress", "service"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.in.ChangedValues(tt.prev); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChangedValues() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"sort"
+	"strings"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TimelineEventType is the type of lifecycle event recorded in a Timeline.
+type TimelineEventType string
+
+const (
+	// TimelineEventInstall represents the installation of a release.
+	TimelineEventInstall TimelineEventType = "Install"
+	// TimelineEventUpgrade represents the upgrade of a release.
+	TimelineEventUpgrade TimelineEventType = "Upgrade"
+	// TimelineEventRollback represents a release version produced by a
+	// rollback to an earlier version.
+	TimelineEventRollback TimelineEventType = "Rollback"
+	// TimelineEventTest represents the completion of the tests of a release.
+	TimelineEventTest TimelineEventType = "Test"
+	// TimelineEventRemediation represents a remediation (rollback or
+	// uninstall) of a failed release.
+	TimelineEventRemediation TimelineEventType = "Remediation"
+	// TimelineEventUninstall represents the uninstallation of a release.
+	TimelineEventUninstall TimelineEventType = "Uninstall"
+)
+
+// TimelineEvent is a single entry in the lifecycle Timeline of a HelmRelease.
+// +kubebuilder:object:generate=false
+type TimelineEvent struct {
+	// Time at which the event happened.
+	Time metav1.Time `json:"time"`
+	// Type of the event.
+	Type TimelineEventType `json:"type"`
+	// Release is the full name of the release the event applies to, in the
+	// format of '<namespace>/<name>.<version>'. It is empty for events which
+	// can not be attributed to a specific release version.
+	Release string `json:"release,omitempty"`
+	// Chart is the name and version of the chart of the release, in the
+	// format of '<name>@<version>'.
+	Chart string `json:"chart,omitempty"`
+	// Succeeded indicates if the event describes a successful action.
+	Succeeded bool `json:"succeeded"`
+	// Reason is a short descriptive reason of the event, e.g. the status of
+	// the release or the reason of a condition.
+	Reason string `json:"reason,omitempty"`
+	// Message is a human-readable description of the event.
+	Message string `json:"message,omitempty"`
+}
+
+// Timeline is a chronologically ordered list of TimelineEvent objects.
+// +kubebuilder:object:generate=false
+type Timeline []TimelineEvent
+
+// Timeline returns the lifecycle of the HelmRelease in chronological order,
+// as reconstructed from the Status.History and Status.Remediations.
+//
+// The Timeline is an aggregation based on the timestamps recorded in the
+// status. As such, it only contains events for which data is still available.
+// When the history has been truncated, the installation of the release is
+// reconstructed from the first deployment time which is retained across
+// release versions.
+func (in HelmReleaseStatus) Timeline() Timeline {
+	var timeline Timeline
+
+	history := make(Snapshots, len(in.History))
+	copy(history, in.History)
+	history.SortByVersion()
+
+	for i := len(history) - 1; i >= 0; i-- {
+		snap := history[i]
+		if snap == nil {
+			continue
+		}
+
+		// The oldest retained snapshot is not the first version, which means
+		// the history has been truncated. Reconstruct the installation from
+		// the first deployment time.
+		if i == len(history)-1 && snap.Version > 1 && !snap.FirstDeployed.IsZero() &&
+			snap.FirstDeployed.Before(&snap.LastDeployed) {
+			timeline = append(timeline, TimelineEvent{
+				Time:      snap.FirstDeployed,
+				Type:      TimelineEventInstall,
+				Succeeded: true,
+				Reason:    snapshotStatusDeployed,
+				Message:   "release was first deployed (details have been pruned from history)",
+			})
+		}
+
+		eventType := TimelineEventUpgrade
+		switch {
+		case snap.Version == 1:
+			eventType = TimelineEventInstall
+		case isRollbackSnapshot(snap):
+			eventType = TimelineEventRollback
+		}
+		timeline = append(timeline, TimelineEvent{
+			Time:      snap.LastDeployed,
+			Type:      eventType,
+			Release:   snap.FullReleaseName(),
+			Chart:     snap.VersionedChartName(),
+			Succeeded: snap.Status != snapshotStatusFailed,
+			Reason:    snap.Status,
+		})
+
+		if snap.HasBeenTested() {
+			var completed metav1.Time
+			for _, h := range snap.GetTestHooks() {
+				if h != nil && completed.Before(&h.LastCompleted) {
+					completed = h.LastCompleted
+				}
+			}
+			if !completed.IsZero() {
+				timeline = append(timeline, TimelineEvent{
+					Time:      completed,
+					Type:      TimelineEventTest,
+					Release:   snap.FullReleaseName(),
+					Chart:     snap.VersionedChartName(),
+					Succeeded: !snap.HasTestInPhase(snapshotTestPhaseFailed),
+				})
+			}
+		}
+
+		if !snap.Deleted.IsZero() {
+			timeline = append(timeline, TimelineEvent{
+				Time:      snap.Deleted,
+				Type:      TimelineEventUninstall,
+				Release:   snap.FullReleaseName(),
+				Chart:     snap.VersionedChartName(),
+				Succeeded: true,
+				Reason:    snapshotStatusUninstalled,
+			})
+		}
+	}
+
+	for _, rec := range in.Remediations {
+		timeline = append(timeline, TimelineEvent{
+			Time:      rec.Time,
+			Type:      TimelineEventRemediation,
+			Release:   rec.Release,
+			Succeeded: rec.Succeeded,
+			Reason:    remediationReason(rec),
+			Message:   rec.Cause,
+		})
+	}
+
+	// Statuses written before the remediations were recorded only hold the
+	// outcome of the most recent remediation in the Remediated condition.
+	if len(in.Remediations) == 0 {
+		if c := apimeta.FindStatusCondition(in.Conditions, RemediatedCondition); c != nil {
+			timeline = append(timeline, TimelineEvent{
+				Time:      c.LastTransitionTime,
+				Type:      TimelineEventRemediation,
+				Succeeded: c.Status == metav1.ConditionTrue,
+				Reason:    c.Reason,
+				Message:   c.Message,
+			})
+		}
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Time.Before(&timeline[j].Time)
+	})
+	return timeline
+}
+
+// isRollbackSnapshot returns true if the release version of the Snapshot was
+// produced by a rollback, based on the description Helm gives to the release
+// ('Rollback to <version>', or the error of a failed rollback).
+func isRollbackSnapshot(snap *Snapshot) bool {
+	return strings.HasPrefix(snap.StatusReason, "Rollback ")
+}
+
+// remediationReason returns the reason of the RemediationRecord, based on
+// its action and outcome.
+func remediationReason(rec RemediationRecord) string {
+	switch rec.Action {
+	case RemediationActionRollback:
+		if rec.Succeeded {
+			return RollbackSucceededReason
+		}
+		return RollbackFailedReason
+	case RemediationActionUninstall:
+		if rec.Succeeded {
+			return UninstallSucceededReason
+		}
+		return UninstallFailedReason
+	default:
+		return rec.Action
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v2
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHelmReleaseStatus_Timeline(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(m int) metav1.Time {
+		return metav1.NewTime(base.Add(time.Duration(m) * time.Minute))
+	}
+
+	tests := []struct {
+		name   string
+		status HelmReleaseStatus
+		want   []TimelineEventType
+	}{
+		{
+			name:   "empty status",
+			status: HelmReleaseStatus{},
+			want:   nil,
+		},
+		{
+			name: "install, test and upgrade",
+			status: HelmReleaseStatus{
+				History: Snapshots{
+					{Name: "a", Namespace: "b", Version: 2, Status: snapshotStatusDeployed, FirstDeployed: at(0), LastDeployed: at(10)},
+					{
+						Name: "a", Namespace: "b", Version: 1, Status: snapshotStatusSuperseded, FirstDeployed: at(0), LastDeployed: at(0),
+						TestHooks: &map[string]*TestHookStatus{
+							"test": {LastStarted: at(1), LastCompleted: at(2), Phase: "Succeeded"},
+						},
+					},
+				},
+			},
+			want: []TimelineEventType{TimelineEventInstall, TimelineEventTest, TimelineEventUpgrade},
+		},
+		{
+			name: "failed upgrade with remediation",
+			status: HelmReleaseStatus{
+				History: Snapshots{
+					{Name: "a", Namespace: "b", Version: 1, Status: snapshotStatusDeployed, FirstDeployed: at(0), LastDeployed: at(0)},
+					{Name: "a", Namespace: "b", Version: 2, Status: snapshotStatusFailed, FirstDeployed: at(0), LastDeployed: at(5)},
+				},
+				Conditions: []metav1.Condition{
+					{Type: RemediatedCondition, Status: metav1.ConditionTrue, Reason: RollbackSucceededReason, LastTransitionTime: at(6)},
+				},
+			},
+			want: []TimelineEventType{TimelineEventInstall, TimelineEventUpgrade, TimelineEventRemediation},
+		},
+		{
+			name: "rollback remediations from records",
+			status: HelmReleaseStatus{
+				History: Snapshots{
+					{Name: "a", Namespace: "b", Version: 1, Status: snapshotStatusSuperseded, FirstDeployed: at(0), LastDeployed: at(0)},
+					{Name: "a", Namespace: "b", Version: 2, Status: snapshotStatusFailed, FirstDeployed: at(0), LastDeployed: at(5)},
+					{Name: "a", Namespace: "b", Version: 3, Status: snapshotStatusSuperseded, StatusReason: "Rollback to 1", FirstDeployed: at(0), LastDeployed: at(6)},
+					{Name: "a", Namespace: "b", Version: 4, Status: snapshotStatusFailed, FirstDeployed: at(0), LastDeployed: at(10)},
+					{Name: "a", Namespace: "b", Version: 5, Status: snapshotStatusDeployed, StatusReason: "Rollback to 3", FirstDeployed: at(0), LastDeployed: at(11)},
+				},
+				Remediations: []RemediationRecord{
+					{Attempt: 2, Action: RemediationActionRollback, Release: "b/a.v3", Succeeded: true, Time: at(12)},
+					{Attempt: 1, Action: RemediationActionRollback, Release: "b/a.v1", Succeeded: true, Time: at(7)},
+				},
+				Conditions: []metav1.Condition{
+					{Type: RemediatedCondition, Status: metav1.ConditionTrue, Reason: RollbackSucceededReason, LastTransitionTime: at(12)},
+				},
+			},
+			want: []TimelineEventType{
+				TimelineEventInstall, TimelineEventUpgrade, TimelineEventRollback, TimelineEventRemediation,
+				TimelineEventUpgrade, TimelineEventRollback, TimelineEventRemediation,
+			},
+		},
+		{
+			name: "truncated history reconstructs install",
+			status: HelmReleaseStatus{
+				History: Snapshots{
+					{Name: "a", Namespace: "b", Version: 4, Status: snapshotStatusDeployed, FirstDeployed: at(0), LastDeployed: at(30)},
+					{Name: "a", Namespace: "b", Version: 3, Status: snapshotStatusSuperseded, FirstDeployed: at(0), LastDeployed: at(20)},
+				},
+			},
+			want: []TimelineEventType{TimelineEventInstall, TimelineEventUpgrade, TimelineEventUpgrade},
+		},
+		{
+			name: "uninstalled release",
+			status: HelmReleaseStatus{
+				History: Snapshots{
+					{Name: "a", Namespace: "b", Version: 1, Status: snapshotStatusUninstalled, FirstDeployed: at(0), LastDeployed: at(0), Deleted: at(5)},
+				},
+			},
+			want: []TimelineEventType{TimelineEventInstall, TimelineEventUninstall},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []TimelineEventType
+			timeline := tt.status.Timeline()
+			for i, e := range timeline {
+				if i > 0 && e.Time.Before(&timeline[i-1].Time) {
+					t.Errorf("Timeline() not in chronological order at index %d", i)
+				}
+				got = append(got, e.Type)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Timeline() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
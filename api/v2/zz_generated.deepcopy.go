@@ -28,6 +28,182 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AdaptiveRequeue) DeepCopyInto(out *AdaptiveRequeue) {
+	*out = *in
+	if in.MinInterval != nil {
+		in, out := &in.MinInterval, &out.MinInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxInterval != nil {
+		in, out := &in.MaxInterval, &out.MaxInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdaptiveRequeue.
+func (in *AdaptiveRequeue) DeepCopy() *AdaptiveRequeue {
+	if in == nil {
+		return nil
+	}
+	out := new(AdaptiveRequeue)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapabilityProfile) DeepCopyInto(out *CapabilityProfile) {
+	*out = *in
+	if in.APIVersions != nil {
+		in, out := &in.APIVersions, &out.APIVersions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapabilityProfile.
+func (in *CapabilityProfile) DeepCopy() *CapabilityProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(CapabilityProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CapabilityProfileResult) DeepCopyInto(out *CapabilityProfileResult) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CapabilityProfileResult.
+func (in *CapabilityProfileResult) DeepCopy() *CapabilityProfileResult {
+	if in == nil {
+		return nil
+	}
+	out := new(CapabilityProfileResult)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChartDependency) DeepCopyInto(out *ChartDependency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartDependency.
+func (in *ChartDependency) DeepCopy() *ChartDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(ChartDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChartHooks) DeepCopyInto(out *ChartHooks) {
+	*out = *in
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HookResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartHooks.
+func (in *ChartHooks) DeepCopy() *ChartHooks {
+	if in == nil {
+		return nil
+	}
+	out := new(ChartHooks)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChartRefVerification) DeepCopyInto(out *ChartRefVerification) {
+	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(meta.LocalObjectReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChartRefVerification.
+func (in *ChartRefVerification) DeepCopy() *ChartRefVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(ChartRefVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientIdentity) DeepCopyInto(out *ClientIdentity) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientIdentity.
+func (in *ClientIdentity) DeepCopy() *ClientIdentity {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientIdentity)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CommonMetadata) DeepCopyInto(out *CommonMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommonMetadata.
+func (in *CommonMetadata) DeepCopy() *CommonMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(CommonMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CreateNamespaceOptions) DeepCopyInto(out *CreateNamespaceOptions) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CreateNamespaceOptions.
+func (in *CreateNamespaceOptions) DeepCopy() *CreateNamespaceOptions {
+	if in == nil {
+		return nil
+	}
+	out := new(CreateNamespaceOptions)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrossNamespaceObjectReference) DeepCopyInto(out *CrossNamespaceObjectReference) {
 	*out = *in
@@ -46,6 +222,11 @@ func (in *CrossNamespaceObjectReference) DeepCopy() *CrossNamespaceObjectReferen
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrossNamespaceSourceReference) DeepCopyInto(out *CrossNamespaceSourceReference) {
 	*out = *in
+	if in.Verify != nil {
+		in, out := &in.Verify, &out.Verify
+		*out = new(ChartRefVerification)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrossNamespaceSourceReference.
@@ -68,6 +249,16 @@ func (in *DriftDetection) DeepCopyInto(out *DriftDetection) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CoolDown != nil {
+		in, out := &in.CoolDown, &out.CoolDown
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DriftDetection.
@@ -80,6 +271,21 @@ func (in *DriftDetection) DeepCopy() *DriftDetection {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EffectiveConfig) DeepCopyInto(out *EffectiveConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EffectiveConfig.
+func (in *EffectiveConfig) DeepCopy() *EffectiveConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EffectiveConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Filter) DeepCopyInto(out *Filter) {
 	*out = *in
@@ -95,6 +301,31 @@ func (in *Filter) DeepCopy() *Filter {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HealthChecks) DeepCopyInto(out *HealthChecks) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]kustomize.Selector, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthChecks.
+func (in *HealthChecks) DeepCopy() *HealthChecks {
+	if in == nil {
+		return nil
+	}
+	out := new(HealthChecks)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *HelmChartTemplate) DeepCopyInto(out *HelmChartTemplate) {
 	*out = *in
@@ -224,31 +455,58 @@ func (in *HelmRelease) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HelmReleaseList) DeepCopyInto(out *HelmReleaseList) {
+func (in *HelmReleaseGroup) DeepCopyInto(out *HelmReleaseGroup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmReleaseGroup.
+func (in *HelmReleaseGroup) DeepCopy() *HelmReleaseGroup {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmReleaseGroup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HelmReleaseGroup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmReleaseGroupList) DeepCopyInto(out *HelmReleaseGroupList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]HelmRelease, len(*in))
+		*out = make([]HelmReleaseGroup, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmReleaseList.
-func (in *HelmReleaseList) DeepCopy() *HelmReleaseList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmReleaseGroupList.
+func (in *HelmReleaseGroupList) DeepCopy() *HelmReleaseGroupList {
 	if in == nil {
 		return nil
 	}
-	out := new(HelmReleaseList)
+	out := new(HelmReleaseGroupList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *HelmReleaseList) DeepCopyObject() runtime.Object {
+func (in *HelmReleaseGroupList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -256,40 +514,160 @@ func (in *HelmReleaseList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HelmReleaseSpec) DeepCopyInto(out *HelmReleaseSpec) {
+func (in *HelmReleaseGroupMember) DeepCopyInto(out *HelmReleaseGroupMember) {
 	*out = *in
-	if in.Chart != nil {
-		in, out := &in.Chart, &out.Chart
-		*out = new(HelmChartTemplate)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmReleaseGroupMember.
+func (in *HelmReleaseGroupMember) DeepCopy() *HelmReleaseGroupMember {
+	if in == nil {
+		return nil
 	}
-	if in.ChartRef != nil {
-		in, out := &in.ChartRef, &out.ChartRef
-		*out = new(CrossNamespaceSourceReference)
-		**out = **in
+	out := new(HelmReleaseGroupMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmReleaseGroupSpec) DeepCopyInto(out *HelmReleaseGroupSpec) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmReleaseGroupSpec.
+func (in *HelmReleaseGroupSpec) DeepCopy() *HelmReleaseGroupSpec {
+	if in == nil {
+		return nil
 	}
-	out.Interval = in.Interval
-	if in.KubeConfig != nil {
-		in, out := &in.KubeConfig, &out.KubeConfig
-		*out = new(meta.KubeConfigReference)
-		**out = **in
+	out := new(HelmReleaseGroupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmReleaseGroupStatus) DeepCopyInto(out *HelmReleaseGroupStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.DependsOn != nil {
-		in, out := &in.DependsOn, &out.DependsOn
-		*out = make([]meta.NamespacedObjectReference, len(*in))
+	if in.FailingMembers != nil {
+		in, out := &in.FailingMembers, &out.FailingMembers
+		*out = make([]HelmReleaseGroupMember, len(*in))
 		copy(*out, *in)
 	}
-	if in.Timeout != nil {
-		in, out := &in.Timeout, &out.Timeout
-		*out = new(v1.Duration)
-		**out = **in
-	}
-	if in.MaxHistory != nil {
-		in, out := &in.MaxHistory, &out.MaxHistory
-		*out = new(int)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmReleaseGroupStatus.
+func (in *HelmReleaseGroupStatus) DeepCopy() *HelmReleaseGroupStatus {
+	if in == nil {
+		return nil
 	}
-	if in.PersistentClient != nil {
+	out := new(HelmReleaseGroupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmReleaseList) DeepCopyInto(out *HelmReleaseList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]HelmRelease, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmReleaseList.
+func (in *HelmReleaseList) DeepCopy() *HelmReleaseList {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmReleaseList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *HelmReleaseList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmReleaseSpec) DeepCopyInto(out *HelmReleaseSpec) {
+	*out = *in
+	if in.Chart != nil {
+		in, out := &in.Chart, &out.Chart
+		*out = new(HelmChartTemplate)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ChartRef != nil {
+		in, out := &in.ChartRef, &out.ChartRef
+		*out = new(CrossNamespaceSourceReference)
+		(*in).DeepCopyInto(*out)
+	}
+	out.Interval = in.Interval
+	if in.AdaptiveRequeue != nil {
+		in, out := &in.AdaptiveRequeue, &out.AdaptiveRequeue
+		*out = new(AdaptiveRequeue)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StableInterval != nil {
+		in, out := &in.StableInterval, &out.StableInterval
+		*out = new(StableInterval)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetryBackoff != nil {
+		in, out := &in.RetryBackoff, &out.RetryBackoff
+		*out = new(RetryBackoff)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+	if in.KubeConfig != nil {
+		in, out := &in.KubeConfig, &out.KubeConfig
+		*out = new(meta.KubeConfigReference)
+		**out = **in
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]meta.NamespacedObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DisableWait != nil {
+		in, out := &in.DisableWait, &out.DisableWait
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxHistory != nil {
+		in, out := &in.MaxHistory, &out.MaxHistory
+		*out = new(int)
+		**out = **in
+	}
+	if in.HistoryRetention != nil {
+		in, out := &in.HistoryRetention, &out.HistoryRetention
+		*out = new(HistoryRetention)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PersistentClient != nil {
 		in, out := &in.PersistentClient, &out.PersistentClient
 		*out = new(bool)
 		**out = **in
@@ -299,6 +677,26 @@ func (in *HelmReleaseSpec) DeepCopyInto(out *HelmReleaseSpec) {
 		*out = new(DriftDetection)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Preflight != nil {
+		in, out := &in.Preflight, &out.Preflight
+		*out = new(Preflight)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(Readiness)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthChecks != nil {
+		in, out := &in.HealthChecks, &out.HealthChecks
+		*out = new(HealthChecks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReconcileHooks != nil {
+		in, out := &in.ReconcileHooks, &out.ReconcileHooks
+		*out = new(ReconcileHooks)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Install != nil {
 		in, out := &in.Install, &out.Install
 		*out = new(Install)
@@ -324,187 +722,681 @@ func (in *HelmReleaseSpec) DeepCopyInto(out *HelmReleaseSpec) {
 		*out = new(Uninstall)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.ValuesFrom != nil {
-		in, out := &in.ValuesFrom, &out.ValuesFrom
-		*out = make([]ValuesReference, len(*in))
-		copy(*out, *in)
-	}
-	if in.Values != nil {
-		in, out := &in.Values, &out.Values
-		*out = new(apiextensionsv1.JSON)
-		(*in).DeepCopyInto(*out)
+	if in.ValuesFrom != nil {
+		in, out := &in.ValuesFrom, &out.ValuesFrom
+		*out = make([]ValuesReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = new(apiextensionsv1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Subcharts != nil {
+		in, out := &in.Subcharts, &out.Subcharts
+		*out = make([]Subchart, len(*in))
+		copy(*out, *in)
+	}
+	if in.PostRenderers != nil {
+		in, out := &in.PostRenderers, &out.PostRenderers
+		*out = make([]PostRenderer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RequiredLabels != nil {
+		in, out := &in.RequiredLabels, &out.RequiredLabels
+		*out = new(RequiredLabels)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CommonMetadata != nil {
+		in, out := &in.CommonMetadata, &out.CommonMetadata
+		*out = new(CommonMetadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ManifestExport != nil {
+		in, out := &in.ManifestExport, &out.ManifestExport
+		*out = new(ManifestExport)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmReleaseSpec.
+func (in *HelmReleaseSpec) DeepCopy() *HelmReleaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmReleaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HelmReleaseStatus) DeepCopyInto(out *HelmReleaseStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make(Snapshots, len(*in))
+		for i := range *in {
+			if (*in)[i] != nil {
+				in, out := &(*in)[i], &(*out)[i]
+				*out = new(Snapshot)
+				(*in).DeepCopyInto(*out)
+			}
+		}
+	}
+	if in.RemediationRetriesRemaining != nil {
+		in, out := &in.RemediationRetriesRemaining, &out.RemediationRetriesRemaining
+		*out = new(int64)
+		**out = **in
+	}
+	if in.FirstFailureTime != nil {
+		in, out := &in.FirstFailureTime, &out.FirstFailureTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EffectiveConfig != nil {
+		in, out := &in.EffectiveConfig, &out.EffectiveConfig
+		*out = new(EffectiveConfig)
+		**out = **in
+	}
+	if in.ClientIdentity != nil {
+		in, out := &in.ClientIdentity, &out.ClientIdentity
+		*out = new(ClientIdentity)
+		**out = **in
+	}
+	if in.HealthyPercentage != nil {
+		in, out := &in.HealthyPercentage, &out.HealthyPercentage
+		*out = new(int)
+		**out = **in
+	}
+	if in.LastAppliedHealth != nil {
+		in, out := &in.LastAppliedHealth, &out.LastAppliedHealth
+		*out = new(ReleaseHealth)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastReconcileDuration != nil {
+		in, out := &in.LastReconcileDuration, &out.LastReconcileDuration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.LastReconcilePhases != nil {
+		in, out := &in.LastReconcilePhases, &out.LastReconcilePhases
+		*out = make([]ReconcilePhase, len(*in))
+		copy(*out, *in)
+	}
+	if in.CapabilityProfiles != nil {
+		in, out := &in.CapabilityProfiles, &out.CapabilityProfiles
+		*out = make([]CapabilityProfileResult, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hooks != nil {
+		in, out := &in.Hooks, &out.Hooks
+		*out = new(ChartHooks)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Remediations != nil {
+		in, out := &in.Remediations, &out.Remediations
+		*out = make([]RemediationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ChartDependencies != nil {
+		in, out := &in.ChartDependencies, &out.ChartDependencies
+		*out = make([]ChartDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.LastDiff != nil {
+		in, out := &in.LastDiff, &out.LastDiff
+		*out = new(ReleaseDiff)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = new(ResourceInventory)
+		(*in).DeepCopyInto(*out)
+	}
+	out.ReconcileRequestStatus = in.ReconcileRequestStatus
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmReleaseStatus.
+func (in *HelmReleaseStatus) DeepCopy() *HelmReleaseStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(HelmReleaseStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HistoryRetention) DeepCopyInto(out *HistoryRetention) {
+	*out = *in
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxCount != nil {
+		in, out := &in.MaxCount, &out.MaxCount
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HistoryRetention.
+func (in *HistoryRetention) DeepCopy() *HistoryRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(HistoryRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *HookResource) DeepCopyInto(out *HookResource) {
+	*out = *in
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeletePolicies != nil {
+		in, out := &in.DeletePolicies, &out.DeletePolicies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HookResource.
+func (in *HookResource) DeepCopy() *HookResource {
+	if in == nil {
+		return nil
+	}
+	out := new(HookResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IgnoreRule) DeepCopyInto(out *IgnoreRule) {
+	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(kustomize.Selector)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IgnoreRule.
+func (in *IgnoreRule) DeepCopy() *IgnoreRule {
+	if in == nil {
+		return nil
+	}
+	out := new(IgnoreRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Install) DeepCopyInto(out *Install) {
+	*out = *in
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Remediation != nil {
+		in, out := &in.Remediation, &out.Remediation
+		*out = new(InstallRemediation)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReadinessGracePeriod != nil {
+		in, out := &in.ReadinessGracePeriod, &out.ReadinessGracePeriod
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.DisabledHooks != nil {
+		in, out := &in.DisabledHooks, &out.DisabledHooks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CreateNamespaceOptions != nil {
+		in, out := &in.CreateNamespaceOptions, &out.CreateNamespaceOptions
+		*out = new(CreateNamespaceOptions)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Install.
+func (in *Install) DeepCopy() *Install {
+	if in == nil {
+		return nil
+	}
+	out := new(Install)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstallRemediation) DeepCopyInto(out *InstallRemediation) {
+	*out = *in
+	if in.IgnoreTestFailures != nil {
+		in, out := &in.IgnoreTestFailures, &out.IgnoreTestFailures
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RemediateLastFailure != nil {
+		in, out := &in.RemediateLastFailure, &out.RemediateLastFailure
+		*out = new(bool)
+		**out = **in
+	}
+	if in.RetryDelay != nil {
+		in, out := &in.RetryDelay, &out.RetryDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallRemediation.
+func (in *InstallRemediation) DeepCopy() *InstallRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(InstallRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Kustomize) DeepCopyInto(out *Kustomize) {
+	*out = *in
+	if in.Patches != nil {
+		in, out := &in.Patches, &out.Patches
+		*out = make([]kustomize.Patch, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Images != nil {
+		in, out := &in.Images, &out.Images
+		*out = make([]kustomize.Image, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kustomize.
+func (in *Kustomize) DeepCopy() *Kustomize {
+	if in == nil {
+		return nil
+	}
+	out := new(Kustomize)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestExport) DeepCopyInto(out *ManifestExport) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestExport.
+func (in *ManifestExport) DeepCopy() *ManifestExport {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestExport)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostRenderer) DeepCopyInto(out *PostRenderer) {
+	*out = *in
+	if in.Kustomize != nil {
+		in, out := &in.Kustomize, &out.Kustomize
+		*out = new(Kustomize)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.PostRenderers != nil {
-		in, out := &in.PostRenderers, &out.PostRenderers
-		*out = make([]PostRenderer, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmReleaseSpec.
-func (in *HelmReleaseSpec) DeepCopy() *HelmReleaseSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostRenderer.
+func (in *PostRenderer) DeepCopy() *PostRenderer {
 	if in == nil {
 		return nil
 	}
-	out := new(HelmReleaseSpec)
+	out := new(PostRenderer)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *HelmReleaseStatus) DeepCopyInto(out *HelmReleaseStatus) {
+func (in *Preflight) DeepCopyInto(out *Preflight) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
+	if in.CapabilityProfiles != nil {
+		in, out := &in.CapabilityProfiles, &out.CapabilityProfiles
+		*out = make([]CapabilityProfile, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.History != nil {
-		in, out := &in.History, &out.History
-		*out = make(Snapshots, len(*in))
-		for i := range *in {
-			if (*in)[i] != nil {
-				in, out := &(*in)[i], &(*out)[i]
-				*out = new(Snapshot)
-				(*in).DeepCopyInto(*out)
-			}
-		}
-	}
-	out.ReconcileRequestStatus = in.ReconcileRequestStatus
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HelmReleaseStatus.
-func (in *HelmReleaseStatus) DeepCopy() *HelmReleaseStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Preflight.
+func (in *Preflight) DeepCopy() *Preflight {
 	if in == nil {
 		return nil
 	}
-	out := new(HelmReleaseStatus)
+	out := new(Preflight)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *IgnoreRule) DeepCopyInto(out *IgnoreRule) {
+func (in *Readiness) DeepCopyInto(out *Readiness) {
 	*out = *in
-	if in.Paths != nil {
-		in, out := &in.Paths, &out.Paths
-		*out = make([]string, len(*in))
+	if in.Critical != nil {
+		in, out := &in.Critical, &out.Critical
+		*out = make([]kustomize.Selector, len(*in))
 		copy(*out, *in)
 	}
-	if in.Target != nil {
-		in, out := &in.Target, &out.Target
-		*out = new(kustomize.Selector)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IgnoreRule.
-func (in *IgnoreRule) DeepCopy() *IgnoreRule {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Readiness.
+func (in *Readiness) DeepCopy() *Readiness {
 	if in == nil {
 		return nil
 	}
-	out := new(IgnoreRule)
+	out := new(Readiness)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Install) DeepCopyInto(out *Install) {
+func (in *ReconcileHook) DeepCopyInto(out *ReconcileHook) {
 	*out = *in
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(meta.LocalObjectReference)
+		**out = **in
+	}
 	if in.Timeout != nil {
 		in, out := &in.Timeout, &out.Timeout
 		*out = new(v1.Duration)
 		**out = **in
 	}
-	if in.Remediation != nil {
-		in, out := &in.Remediation, &out.Remediation
-		*out = new(InstallRemediation)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileHook.
+func (in *ReconcileHook) DeepCopy() *ReconcileHook {
+	if in == nil {
+		return nil
+	}
+	out := new(ReconcileHook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReconcileHooks) DeepCopyInto(out *ReconcileHooks) {
+	*out = *in
+	if in.Pre != nil {
+		in, out := &in.Pre, &out.Pre
+		*out = new(ReconcileHook)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Post != nil {
+		in, out := &in.Post, &out.Post
+		*out = new(ReconcileHook)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Install.
-func (in *Install) DeepCopy() *Install {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcileHooks.
+func (in *ReconcileHooks) DeepCopy() *ReconcileHooks {
 	if in == nil {
 		return nil
 	}
-	out := new(Install)
+	out := new(ReconcileHooks)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstallRemediation) DeepCopyInto(out *InstallRemediation) {
+func (in *ReconcilePhase) DeepCopyInto(out *ReconcilePhase) {
 	*out = *in
-	if in.IgnoreTestFailures != nil {
-		in, out := &in.IgnoreTestFailures, &out.IgnoreTestFailures
-		*out = new(bool)
-		**out = **in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReconcilePhase.
+func (in *ReconcilePhase) DeepCopy() *ReconcilePhase {
+	if in == nil {
+		return nil
 	}
-	if in.RemediateLastFailure != nil {
-		in, out := &in.RemediateLastFailure, &out.RemediateLastFailure
-		*out = new(bool)
-		**out = **in
+	out := new(ReconcilePhase)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReleaseDiff) DeepCopyInto(out *ReleaseDiff) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Changes != nil {
+		in, out := &in.Changes, &out.Changes
+		*out = make([]ResourceChange, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstallRemediation.
-func (in *InstallRemediation) DeepCopy() *InstallRemediation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseDiff.
+func (in *ReleaseDiff) DeepCopy() *ReleaseDiff {
 	if in == nil {
 		return nil
 	}
-	out := new(InstallRemediation)
+	out := new(ReleaseDiff)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Kustomize) DeepCopyInto(out *Kustomize) {
+func (in *ReleaseHealth) DeepCopyInto(out *ReleaseHealth) {
 	*out = *in
-	if in.Patches != nil {
-		in, out := &in.Patches, &out.Patches
-		*out = make([]kustomize.Patch, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	in.LastChecked.DeepCopyInto(&out.LastChecked)
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]ResourceHealthStatus, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReleaseHealth.
+func (in *ReleaseHealth) DeepCopy() *ReleaseHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(ReleaseHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemediationRecord) DeepCopyInto(out *RemediationRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemediationRecord.
+func (in *RemediationRecord) DeepCopy() *RemediationRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(RemediationRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RequiredLabels) DeepCopyInto(out *RequiredLabels) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Inject != nil {
+		in, out := &in.Inject, &out.Inject
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
 		}
 	}
-	if in.Images != nil {
-		in, out := &in.Images, &out.Images
-		*out = make([]kustomize.Image, len(*in))
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RequiredLabels.
+func (in *RequiredLabels) DeepCopy() *RequiredLabels {
+	if in == nil {
+		return nil
+	}
+	out := new(RequiredLabels)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceChange) DeepCopyInto(out *ResourceChange) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceChange.
+func (in *ResourceChange) DeepCopy() *ResourceChange {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceHealthStatus) DeepCopyInto(out *ResourceHealthStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceHealthStatus.
+func (in *ResourceHealthStatus) DeepCopy() *ResourceHealthStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceHealthStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceInventory) DeepCopyInto(out *ResourceInventory) {
+	*out = *in
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]ResourceRef, len(*in))
 		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Kustomize.
-func (in *Kustomize) DeepCopy() *Kustomize {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceInventory.
+func (in *ResourceInventory) DeepCopy() *ResourceInventory {
 	if in == nil {
 		return nil
 	}
-	out := new(Kustomize)
+	out := new(ResourceInventory)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostRenderer) DeepCopyInto(out *PostRenderer) {
+func (in *ResourceRef) DeepCopyInto(out *ResourceRef) {
 	*out = *in
-	if in.Kustomize != nil {
-		in, out := &in.Kustomize, &out.Kustomize
-		*out = new(Kustomize)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceRef.
+func (in *ResourceRef) DeepCopy() *ResourceRef {
+	if in == nil {
+		return nil
 	}
+	out := new(ResourceRef)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostRenderer.
-func (in *PostRenderer) DeepCopy() *PostRenderer {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetryBackoff) DeepCopyInto(out *RetryBackoff) {
+	*out = *in
+	if in.BaseInterval != nil {
+		in, out := &in.BaseInterval, &out.BaseInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.MaxInterval != nil {
+		in, out := &in.MaxInterval, &out.MaxInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Jitter != nil {
+		in, out := &in.Jitter, &out.Jitter
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetryBackoff.
+func (in *RetryBackoff) DeepCopy() *RetryBackoff {
 	if in == nil {
 		return nil
 	}
-	out := new(PostRenderer)
+	out := new(RetryBackoff)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -555,6 +1447,18 @@ func (in *Snapshot) DeepCopyInto(out *Snapshot) {
 			}
 		}
 	}
+	if in.ValuesDigests != nil {
+		in, out := &in.ValuesDigests, &out.ValuesDigests
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Snapshot.
@@ -567,6 +1471,42 @@ func (in *Snapshot) DeepCopy() *Snapshot {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StableInterval) DeepCopyInto(out *StableInterval) {
+	*out = *in
+	out.Interval = in.Interval
+	if in.Threshold != nil {
+		in, out := &in.Threshold, &out.Threshold
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StableInterval.
+func (in *StableInterval) DeepCopy() *StableInterval {
+	if in == nil {
+		return nil
+	}
+	out := new(StableInterval)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Subchart) DeepCopyInto(out *Subchart) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Subchart.
+func (in *Subchart) DeepCopy() *Subchart {
+	if in == nil {
+		return nil
+	}
+	out := new(Subchart)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in Snapshots) DeepCopyInto(out *Snapshots) {
 	{
@@ -651,6 +1591,11 @@ func (in *Uninstall) DeepCopyInto(out *Uninstall) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.WaitForDependentsTimeout != nil {
+		in, out := &in.WaitForDependentsTimeout, &out.WaitForDependentsTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Uninstall.
@@ -676,6 +1621,11 @@ func (in *Upgrade) DeepCopyInto(out *Upgrade) {
 		*out = new(UpgradeRemediation)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DisabledHooks != nil {
+		in, out := &in.DisabledHooks, &out.DisabledHooks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Upgrade.
@@ -706,6 +1656,16 @@ func (in *UpgradeRemediation) DeepCopyInto(out *UpgradeRemediation) {
 		*out = new(RemediationStrategy)
 		**out = **in
 	}
+	if in.RetryDelay != nil {
+		in, out := &in.RetryDelay, &out.RetryDelay
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RemediateOn != nil {
+		in, out := &in.RemediateOn, &out.RemediateOn
+		*out = make([]FailureClass, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeRemediation.
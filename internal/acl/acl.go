@@ -18,6 +18,10 @@ package acl
 
 import (
 	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -29,6 +33,20 @@ var (
 	// AllowCrossNamespaceRef is a global flag that can be used to allow
 	// cross-namespace references.
 	AllowCrossNamespaceRef = false
+
+	// AllowedSourceKinds is a global list of source kinds a HelmRelease is
+	// allowed to reference. When empty, all source kinds are allowed.
+	AllowedSourceKinds []string
+
+	// DeniedValuesPaths is a global list of dot-notation paths of values a
+	// HelmRelease is not allowed to set. A "*" segment matches any single
+	// key, and a "**" segment matches any number of nested keys.
+	DeniedValuesPaths []string
+
+	// AllowedReconcileHookHosts is a global list of hosts the reconcile hooks
+	// of a HelmRelease are allowed to send requests to. When empty, reconcile
+	// hooks are not allowed.
+	AllowedReconcileHookHosts []string
 )
 
 // AllowsAccessTo returns an error if the object does not allow access to the
@@ -41,3 +59,141 @@ func AllowsAccessTo(obj client.Object, kind string, ref types.NamespacedName) er
 	}
 	return nil
 }
+
+// AllowsSourceKind returns an error if the given source kind is not allowed
+// by AllowedSourceKinds.
+func AllowsSourceKind(kind string) error {
+	if len(AllowedSourceKinds) == 0 {
+		return nil
+	}
+	for _, k := range AllowedSourceKinds {
+		if k == kind {
+			return nil
+		}
+	}
+	return acl.AccessDeniedError(fmt.Sprintf("source kind '%s' is not allowed: must be one of [%s]",
+		kind, strings.Join(AllowedSourceKinds, ", "),
+	))
+}
+
+// AllowsReconcileHookAddress returns an error if the host of the given
+// address is not in AllowedReconcileHookHosts. A host is matched by its
+// hostname, or by its hostname and port.
+func AllowsReconcileHookAddress(address string) error {
+	if len(AllowedReconcileHookHosts) == 0 {
+		return acl.AccessDeniedError("reconcile hooks are not allowed: no hosts are allowed by the controller")
+	}
+	u, err := url.Parse(address)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return acl.AccessDeniedError(fmt.Sprintf("reconcile hook address '%s' is not an absolute HTTP(S) URL", address))
+	}
+	for _, h := range AllowedReconcileHookHosts {
+		if strings.EqualFold(h, u.Hostname()) || strings.EqualFold(h, u.Host) {
+			return nil
+		}
+	}
+	return acl.AccessDeniedError(fmt.Sprintf("reconcile hook host '%s' is not allowed: must be one of [%s]",
+		u.Host, strings.Join(AllowedReconcileHookHosts, ", "),
+	))
+}
+
+// AllowsValues returns an error naming the offending paths if the given
+// values set any of the DeniedValuesPaths. Nested maps and lists are
+// traversed, with list items being matched by their index.
+func AllowsValues(values map[string]interface{}) error {
+	if len(DeniedValuesPaths) == 0 {
+		return nil
+	}
+
+	patterns := make([][]string, 0, len(DeniedValuesPaths))
+	for _, p := range DeniedValuesPaths {
+		patterns = append(patterns, strings.Split(p, "."))
+	}
+
+	var denied []string
+	walkValues(values, nil, func(path []valuesPathElement) bool {
+		for _, pattern := range patterns {
+			if matchValuesPath(pattern, path) {
+				denied = append(denied, formatValuesPath(path))
+				return true
+			}
+		}
+		return false
+	})
+	if len(denied) == 0 {
+		return nil
+	}
+
+	sort.Strings(denied)
+	return acl.AccessDeniedError(fmt.Sprintf("values are not allowed to set [%s]: denied paths are [%s]",
+		strings.Join(denied, ", "), strings.Join(DeniedValuesPaths, ", "),
+	))
+}
+
+// valuesPathElement is an element of the path of a value, which is either a
+// map key or a list index.
+type valuesPathElement struct {
+	key   string
+	index bool
+}
+
+// walkValues calls fn for the path of every key and list item in the given
+// value, depth-first. The children of a path are not visited if fn returns
+// true for it.
+func walkValues(value interface{}, path []valuesPathElement, fn func(path []valuesPathElement) bool) {
+	visit := func(elem valuesPathElement, child interface{}) {
+		p := append(path[:len(path):len(path)], elem)
+		if !fn(p) {
+			walkValues(child, p, fn)
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			visit(valuesPathElement{key: key}, child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			visit(valuesPathElement{key: strconv.Itoa(i), index: true}, child)
+		}
+	}
+}
+
+// matchValuesPath returns true if the given path matches the pattern. Map
+// keys and list indexes are both matched by their string form.
+func matchValuesPath(pattern []string, path []valuesPathElement) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(path); i++ {
+			if matchValuesPath(pattern[1:], path[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(path) == 0 || (pattern[0] != "*" && pattern[0] != path[0].key) {
+		return false
+	}
+	return matchValuesPath(pattern[1:], path[1:])
+}
+
+// formatValuesPath returns the dot-notation of the given path, with list
+// indexes formatted as "[i]". Map keys are always dot-separated, including
+// numeric ones.
+func formatValuesPath(path []valuesPathElement) string {
+	var b strings.Builder
+	for i, elem := range path {
+		if elem.index {
+			b.WriteString("[" + elem.key + "]")
+			continue
+		}
+		if i > 0 {
+			b.WriteString(".")
+		}
+		b.WriteString(elem.key)
+	}
+	return b.String()
+}
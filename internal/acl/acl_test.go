@@ -17,6 +17,7 @@ limitations under the License.
 package acl
 
 import (
+	"strings"
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -93,3 +94,188 @@ func TestAllowsAccessTo(t *testing.T) {
 		})
 	}
 }
+
+func TestAllowsSourceKind(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		kind    string
+		wantErr bool
+	}{
+		{
+			name:    "no policy allows any kind",
+			allowed: nil,
+			kind:    "HelmRepository",
+			wantErr: false,
+		},
+		{
+			name:    "allowed kind",
+			allowed: []string{"OCIRepository", "HelmChart"},
+			kind:    "OCIRepository",
+			wantErr: false,
+		},
+		{
+			name:    "disallowed kind",
+			allowed: []string{"OCIRepository"},
+			kind:    "HelmRepository",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			curAllowed := AllowedSourceKinds
+			AllowedSourceKinds = tt.allowed
+			t.Cleanup(func() { AllowedSourceKinds = curAllowed })
+
+			if err := AllowsSourceKind(tt.kind); (err != nil) != tt.wantErr {
+				t.Errorf("AllowsSourceKind() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAllowsReconcileHookAddress(t *testing.T) {
+	tests := []struct {
+		name    string
+		allowed []string
+		address string
+		wantErr bool
+	}{
+		{
+			name:    "no policy denies any address",
+			allowed: nil,
+			address: "https://hooks.example.com/approve",
+			wantErr: true,
+		},
+		{
+			name:    "allowed hostname",
+			allowed: []string{"hooks.example.com"},
+			address: "https://hooks.example.com:8443/approve",
+			wantErr: false,
+		},
+		{
+			name:    "allowed host and port",
+			allowed: []string{"hooks.example.com:8443"},
+			address: "https://hooks.example.com:8443/approve",
+			wantErr: false,
+		},
+		{
+			name:    "disallowed port",
+			allowed: []string{"hooks.example.com:8443"},
+			address: "https://hooks.example.com/approve",
+			wantErr: true,
+		},
+		{
+			name:    "disallowed host",
+			allowed: []string{"hooks.example.com"},
+			address: "http://169.254.169.254/latest/meta-data",
+			wantErr: true,
+		},
+		{
+			name:    "relative address",
+			allowed: []string{"hooks.example.com"},
+			address: "/approve",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			curAllowed := AllowedReconcileHookHosts
+			AllowedReconcileHookHosts = tt.allowed
+			t.Cleanup(func() { AllowedReconcileHookHosts = curAllowed })
+
+			if err := AllowsReconcileHookAddress(tt.address); (err != nil) != tt.wantErr {
+				t.Errorf("AllowsReconcileHookAddress() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAllowsValues(t *testing.T) {
+	values := map[string]interface{}{
+		"hostNetwork": true,
+		"controller": map[string]interface{}{
+			"hostNetwork": false,
+			"securityContext": map[string]interface{}{
+				"privileged": true,
+			},
+		},
+		"containers": []interface{}{
+			map[string]interface{}{
+				"securityContext": map[string]interface{}{},
+			},
+		},
+		"image": "podinfo",
+		"ports": map[string]interface{}{
+			"8080": map[string]interface{}{
+				"hostPort": 80,
+			},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		denied  []string
+		wantErr string
+	}{
+		{
+			name:   "no policy allows any values",
+			denied: nil,
+		},
+		{
+			name:    "denied top-level key",
+			denied:  []string{"hostNetwork"},
+			wantErr: "values are not allowed to set [hostNetwork]",
+		},
+		{
+			name:    "denied nested key",
+			denied:  []string{"controller.securityContext.privileged"},
+			wantErr: "values are not allowed to set [controller.securityContext.privileged]",
+		},
+		{
+			name:    "denied key at single wildcard",
+			denied:  []string{"*.hostNetwork"},
+			wantErr: "values are not allowed to set [controller.hostNetwork]",
+		},
+		{
+			name:    "denied key at any depth",
+			denied:  []string{"**.securityContext"},
+			wantErr: "values are not allowed to set [containers[0].securityContext, controller.securityContext]",
+		},
+		{
+			name:    "denied key below list index",
+			denied:  []string{"containers.0.securityContext"},
+			wantErr: "values are not allowed to set [containers[0].securityContext]",
+		},
+		{
+			name:    "denied key below numeric map key",
+			denied:  []string{"ports.*.hostPort"},
+			wantErr: "values are not allowed to set [ports.8080.hostPort]",
+		},
+		{
+			name:   "unset denied key",
+			denied: []string{"image.tag", "hostPID"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			curDenied := DeniedValuesPaths
+			DeniedValuesPaths = tt.denied
+			t.Cleanup(func() { DeniedValuesPaths = curDenied })
+
+			err := AllowsValues(values)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("AllowsValues() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("AllowsValues() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+const (
+	// backupOwner is the value of the owner label of backup objects, which
+	// distinguishes them from the release objects of the Helm storage.
+	backupOwner = "helm-controller"
+	// backupDataKey is the key of the encoded release in a backup object.
+	backupDataKey = "release"
+	// backupSecretType is the type of backup Secrets.
+	backupSecretType corev1.SecretType = "helm.toolkit.fluxcd.io/release-backup"
+)
+
+// ErrBackupNotFound is returned when a backup does not exist.
+var ErrBackupNotFound = errors.New("backup not found")
+
+// BackupStore persists backups of Helm releases outside the Helm storage,
+// so that a release can be restored after it has been pruned from the
+// release history.
+type BackupStore interface {
+	// Save persists a backup of the given release, and returns the name of
+	// the backup.
+	Save(rls *helmrelease.Release) (string, error)
+	// Load returns the release from the backup with the given name.
+	Load(name string) (*helmrelease.Release, error)
+	// List returns the names of the backups of the release with the given
+	// name.
+	List(releaseName string) ([]string, error)
+	// Delete removes the backup with the given name.
+	Delete(name string) error
+}
+
+// BackupName returns the name of the backup of the given release version.
+func BackupName(releaseName string, version int) string {
+	return fmt.Sprintf("sh.fluxcd.backup.v1.%s.v%d", releaseName, version)
+}
+
+// backupLabels returns the labels of the backup object of the given release.
+func backupLabels(rls *helmrelease.Release) map[string]string {
+	return map[string]string{
+		"owner":   backupOwner,
+		"name":    rls.Name,
+		"version": strconv.Itoa(rls.Version),
+	}
+}
+
+// backupSelector returns the label selector for the backup objects of the
+// release with the given name.
+func backupSelector(releaseName string) string {
+	return labels.Set{"owner": backupOwner, "name": releaseName}.AsSelector().String()
+}
+
+// encodeBackup returns the gzipped JSON encoding of the given release.
+func encodeBackup(rls *helmrelease.Release) ([]byte, error) {
+	b, err := json.Marshal(rls)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err = w.Write(b); err != nil {
+		return nil, err
+	}
+	if err = w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeBackup returns the release from the given gzipped JSON encoding.
+func decodeBackup(data []byte) (*helmrelease.Release, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var rls helmrelease.Release
+	if err = json.Unmarshal(b, &rls); err != nil {
+		return nil, err
+	}
+	return &rls, nil
+}
+
+// secretBackupStore is a BackupStore which stores backups in Secrets, for
+// use with the Helm Secrets storage driver.
+type secretBackupStore struct {
+	client corev1client.SecretInterface
+}
+
+// NewSecretBackupStore returns a BackupStore which stores backups in
+// Secrets using the given client.
+func NewSecretBackupStore(client corev1client.SecretInterface) BackupStore {
+	return &secretBackupStore{client: client}
+}
+
+func (s *secretBackupStore) Save(rls *helmrelease.Release) (string, error) {
+	data, err := encodeBackup(rls)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode backup of release %s.v%d: %w", rls.Name, rls.Version, err)
+	}
+	obj := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: BackupName(rls.Name, rls.Version), Labels: backupLabels(rls)},
+		Type:       backupSecretType,
+		Data:       map[string][]byte{backupDataKey: data},
+	}
+	if _, err = s.client.Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return "", err
+		}
+		if _, err = s.client.Update(context.Background(), obj, metav1.UpdateOptions{}); err != nil {
+			return "", err
+		}
+	}
+	return obj.Name, nil
+}
+
+func (s *secretBackupStore) Load(name string) (*helmrelease.Release, error) {
+	obj, err := s.client.Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrBackupNotFound, name)
+		}
+		return nil, err
+	}
+	return decodeBackup(obj.Data[backupDataKey])
+}
+
+func (s *secretBackupStore) List(releaseName string) ([]string, error) {
+	list, err := s.client.List(context.Background(), metav1.ListOptions{LabelSelector: backupSelector(releaseName)})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	return names, nil
+}
+
+func (s *secretBackupStore) Delete(name string) error {
+	if err := s.client.Delete(context.Background(), name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// configMapBackupStore is a BackupStore which stores backups in ConfigMaps,
+// for use with the Helm ConfigMaps storage driver.
+type configMapBackupStore struct {
+	client corev1client.ConfigMapInterface
+}
+
+// NewConfigMapBackupStore returns a BackupStore which stores backups in
+// ConfigMaps using the given client.
+func NewConfigMapBackupStore(client corev1client.ConfigMapInterface) BackupStore {
+	return &configMapBackupStore{client: client}
+}
+
+func (s *configMapBackupStore) Save(rls *helmrelease.Release) (string, error) {
+	data, err := encodeBackup(rls)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode backup of release %s.v%d: %w", rls.Name, rls.Version, err)
+	}
+	obj := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: BackupName(rls.Name, rls.Version), Labels: backupLabels(rls)},
+		BinaryData: map[string][]byte{backupDataKey: data},
+	}
+	if _, err = s.client.Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return "", err
+		}
+		if _, err = s.client.Update(context.Background(), obj, metav1.UpdateOptions{}); err != nil {
+			return "", err
+		}
+	}
+	return obj.Name, nil
+}
+
+func (s *configMapBackupStore) Load(name string) (*helmrelease.Release, error) {
+	obj, err := s.client.Get(context.Background(), name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("%w: %s", ErrBackupNotFound, name)
+		}
+		return nil, err
+	}
+	return decodeBackup(obj.BinaryData[backupDataKey])
+}
+
+func (s *configMapBackupStore) List(releaseName string) ([]string, error) {
+	list, err := s.client.List(context.Background(), metav1.ListOptions{LabelSelector: backupSelector(releaseName)})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	return names, nil
+}
+
+func (s *configMapBackupStore) Delete(name string) error {
+	if err := s.client.Delete(context.Background(), name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// memoryBackupStore is a BackupStore which keeps backups in memory, for use
+// with the Helm Memory storage driver.
+type memoryBackupStore struct {
+	mu      sync.Mutex
+	backups map[string][]byte
+	names   map[string]string
+}
+
+// NewMemoryBackupStore returns a BackupStore which keeps backups in memory.
+func NewMemoryBackupStore() BackupStore {
+	return &memoryBackupStore{backups: make(map[string][]byte), names: make(map[string]string)}
+}
+
+func (s *memoryBackupStore) Save(rls *helmrelease.Release) (string, error) {
+	data, err := encodeBackup(rls)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode backup of release %s.v%d: %w", rls.Name, rls.Version, err)
+	}
+	name := BackupName(rls.Name, rls.Version)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.backups[name] = data
+	s.names[name] = rls.Name
+	return name, nil
+}
+
+func (s *memoryBackupStore) Load(name string) (*helmrelease.Release, error) {
+	s.mu.Lock()
+	data, ok := s.backups[name]
+	s.mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrBackupNotFound, name)
+	}
+	return decodeBackup(data)
+}
+
+func (s *memoryBackupStore) List(releaseName string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var names []string
+	for name, rlsName := range s.names {
+		if rlsName == releaseName {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (s *memoryBackupStore) Delete(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.backups, name)
+	delete(s.names, name)
+	return nil
+}
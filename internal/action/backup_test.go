@@ -0,0 +1,90 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+
+	"github.com/fluxcd/helm-controller/internal/testutil"
+)
+
+func Test_memoryBackupStore(t *testing.T) {
+	g := NewWithT(t)
+
+	store := NewMemoryBackupStore()
+
+	rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+		Name:      "backup",
+		Namespace: "default",
+		Version:   2,
+		Status:    helmrelease.StatusDeployed,
+	})
+	other := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+		Name:      "other",
+		Namespace: "default",
+		Version:   1,
+		Status:    helmrelease.StatusDeployed,
+	})
+
+	name, err := store.Save(rls)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(name).To(Equal(BackupName(rls.Name, rls.Version)))
+	_, err = store.Save(other)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	got, err := store.Load(name)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got.Name).To(Equal(rls.Name))
+	g.Expect(got.Version).To(Equal(rls.Version))
+	g.Expect(got.Manifest).To(Equal(rls.Manifest))
+	g.Expect(got.Info.Status).To(Equal(rls.Info.Status))
+
+	names, err := store.List(rls.Name)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(names).To(Equal([]string{name}))
+
+	g.Expect(store.Delete(name)).To(Succeed())
+	_, err = store.Load(name)
+	g.Expect(err).To(MatchError(ErrBackupNotFound))
+	names, err = store.List(rls.Name)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(names).To(BeEmpty())
+}
+
+func Test_encodeBackup(t *testing.T) {
+	g := NewWithT(t)
+
+	rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+		Name:      "backup",
+		Namespace: "default",
+		Version:   1,
+	})
+
+	data, err := encodeBackup(rls)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	got, err := decodeBackup(data)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got.Name).To(Equal(rls.Name))
+	g.Expect(got.Chart.Metadata).To(Equal(rls.Chart.Metadata))
+
+	_, err = decodeBackup([]byte("invalid"))
+	g.Expect(err).To(HaveOccurred())
+}
@@ -0,0 +1,216 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	helmaction "helm.sh/helm/v3/pkg/action"
+	helmkube "helm.sh/helm/v3/pkg/kube"
+)
+
+// ApplyBatchPause is the pause between the batches of resources applied by
+// a batchKubeClient.
+var ApplyBatchPause = time.Second
+
+// batchKubeClient is a Helm kube.Interface which applies the target
+// resources of an update in batches of a maximum size, pausing between
+// batches to reduce the pressure on the Kubernetes API server.
+//
+// The pauses are included in the timeout of the action: the time spent
+// pausing is subtracted from the timeout of the waits which follow the
+// update, and the update fails when the pauses alone exceed the timeout.
+type batchKubeClient struct {
+	helmkube.Interface
+
+	ctx      context.Context
+	size     int
+	pause    time.Duration
+	timeout  time.Duration
+	paused   time.Duration
+	log      func(format string, v ...interface{})
+	progress BatchProgress
+}
+
+// BatchProgress is the progress of an update of the resources of a release
+// in batches.
+type BatchProgress struct {
+	// Applied is the number of batches which have been applied.
+	Applied int
+	// Total is the total number of batches.
+	Total int
+}
+
+// String returns the progress in the form of "applied N/M batches".
+func (p BatchProgress) String() string {
+	return fmt.Sprintf("applied %d/%d batches", p.Applied, p.Total)
+}
+
+// AppliedBatches returns the BatchProgress of the last update of the
+// resources made by a Helm action run with the given configuration. It
+// returns the zero value when the resources were not applied in batches.
+func AppliedBatches(config *helmaction.Configuration) BatchProgress {
+	if config == nil {
+		return BatchProgress{}
+	}
+	if c, ok := config.KubeClient.(*batchKubeClient); ok {
+		return c.progress
+	}
+	return BatchProgress{}
+}
+
+// newBatchKubeClient returns a batchKubeClient wrapping the given client,
+// applying resources in batches of the given size. Pausing between batches
+// stops when the given context is done, and the pauses count towards the
+// given timeout of the action. A timeout of zero disables the latter.
+func newBatchKubeClient(ctx context.Context, client helmkube.Interface, size int, timeout time.Duration,
+	log func(format string, v ...interface{})) *batchKubeClient {
+	if log == nil {
+		log = func(string, ...interface{}) {}
+	}
+	return &batchKubeClient{
+		Interface: client,
+		ctx:       ctx,
+		size:      size,
+		pause:     ApplyBatchPause,
+		timeout:   timeout,
+		log:       log,
+	}
+}
+
+// Update updates the target resources in batches, in the order of the
+// target list which is the order in which Helm installs resources. The
+// original resources which are not part of the target are deleted after
+// all batches have been applied successfully.
+//
+// The progress of the update is recorded, and can be retrieved using
+// AppliedBatches. When a batch fails, the returned error includes the
+// progress made before the failure.
+func (c *batchKubeClient) Update(original, target helmkube.ResourceList, force bool) (*helmkube.Result, error) {
+	c.progress = BatchProgress{}
+	c.paused = 0
+	if c.size <= 0 || len(target) <= c.size {
+		return c.Interface.Update(original, target, force)
+	}
+
+	result := &helmkube.Result{}
+	batches := (len(target) + c.size - 1) / c.size
+	c.progress.Total = batches
+	for i := 0; i < batches; i++ {
+		start, end := i*c.size, min((i+1)*c.size, len(target))
+		if i > 0 {
+			if err := c.waitBetweenBatches(); err != nil {
+				return result, fmt.Errorf("failed to apply batch %d/%d after applying %d/%d resources: %w",
+					i+1, batches, start, len(target), err)
+			}
+		}
+
+		batch := target[start:end]
+
+		// Only provide the original resources of the batch, to prevent the
+		// deletion of resources which are part of the other batches.
+		res, err := c.Interface.Update(original.Intersect(batch), batch, force)
+		mergeResult(result, res)
+		if err != nil {
+			return result, fmt.Errorf("failed to apply batch %d/%d after applying %d/%d resources: %w",
+				i+1, batches, start, len(target), err)
+		}
+		c.progress.Applied = i + 1
+		c.log("applied batch %d/%d (%d/%d resources)", i+1, batches, end, len(target))
+	}
+
+	// Delete the original resources which are no longer part of the target.
+	if stale := original.Difference(target); len(stale) > 0 {
+		res, err := c.Interface.Update(stale, helmkube.ResourceList{}, force)
+		mergeResult(result, res)
+		if err != nil {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// waitBetweenBatches pauses before the next batch is applied. It returns an
+// error when the context is done, or when the pause would exceed the
+// timeout of the action.
+func (c *batchKubeClient) waitBetweenBatches() error {
+	if c.pause <= 0 {
+		return nil
+	}
+	if c.timeout > 0 && c.paused+c.pause >= c.timeout {
+		return fmt.Errorf("pausing between batches exceeds the timeout of %s", c.timeout)
+	}
+
+	timer := time.NewTimer(c.pause)
+	defer timer.Stop()
+	select {
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	case <-timer.C:
+		c.paused += c.pause
+		return nil
+	}
+}
+
+// remaining returns the given timeout minus the time spent pausing between
+// batches.
+func (c *batchKubeClient) remaining(timeout time.Duration) time.Duration {
+	if c.timeout <= 0 {
+		return timeout
+	}
+	return max(timeout-c.paused, 0)
+}
+
+// Wait waits for the given resources to be ready, within the given timeout
+// minus the time spent pausing between batches.
+func (c *batchKubeClient) Wait(resources helmkube.ResourceList, timeout time.Duration) error {
+	return c.Interface.Wait(resources, c.remaining(timeout))
+}
+
+// WaitWithJobs waits for the given resources to be ready and the Jobs to
+// complete, within the given timeout minus the time spent pausing between
+// batches.
+func (c *batchKubeClient) WaitWithJobs(resources helmkube.ResourceList, timeout time.Duration) error {
+	return c.Interface.WaitWithJobs(resources, c.remaining(timeout))
+}
+
+// WatchUntilReady watches the given (hook) resources until they are ready,
+// within the given timeout minus the time spent pausing between batches.
+func (c *batchKubeClient) WatchUntilReady(resources helmkube.ResourceList, timeout time.Duration) error {
+	return c.Interface.WatchUntilReady(resources, c.remaining(timeout))
+}
+
+// WaitForDelete waits for the given resources to be deleted, if supported
+// by the wrapped client.
+func (c *batchKubeClient) WaitForDelete(resources helmkube.ResourceList, timeout time.Duration) error {
+	if ext, ok := c.Interface.(helmkube.InterfaceExt); ok {
+		return ext.WaitForDelete(resources, c.remaining(timeout))
+	}
+	return nil
+}
+
+// mergeResult appends the resources of src to dst.
+func mergeResult(dst, src *helmkube.Result) {
+	if src == nil {
+		return
+	}
+	dst.Created = append(dst.Created, src.Created...)
+	dst.Updated = append(dst.Updated, src.Updated...)
+	dst.Deleted = append(dst.Deleted, src.Deleted...)
+}
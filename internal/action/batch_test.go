@@ -0,0 +1,207 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	helmaction "helm.sh/helm/v3/pkg/action"
+	helmkube "helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// recordingKubeClient records the names of the original and target
+// resources of each update.
+type recordingKubeClient struct {
+	*kubefake.PrintingKubeClient
+
+	originals [][]string
+	targets   [][]string
+	failAt    int
+}
+
+func (c *recordingKubeClient) Update(original, target helmkube.ResourceList, _ bool) (*helmkube.Result, error) {
+	c.originals = append(c.originals, resourceNames(original))
+	c.targets = append(c.targets, resourceNames(target))
+	if c.failAt > 0 && len(c.targets) == c.failAt {
+		return &helmkube.Result{}, errors.New("update error")
+	}
+	return &helmkube.Result{Updated: target, Deleted: original.Difference(target)}, nil
+}
+
+func resourceNames(list helmkube.ResourceList) []string {
+	names := []string{}
+	for _, info := range list {
+		names = append(names, info.Name)
+	}
+	return names
+}
+
+func mockResourceList(names ...string) helmkube.ResourceList {
+	var list helmkube.ResourceList
+	for _, name := range names {
+		list = append(list, &resource.Info{
+			Name:      name,
+			Namespace: "default",
+			Mapping: &meta.RESTMapping{
+				GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+			},
+		})
+	}
+	return list
+}
+
+func Test_batchKubeClient_Update(t *testing.T) {
+	tests := []struct {
+		name          string
+		size          int
+		original      helmkube.ResourceList
+		target        helmkube.ResourceList
+		failAt        int
+		wantOriginals [][]string
+		wantTargets   [][]string
+		wantLogs      []string
+		wantProgress  BatchProgress
+		wantErr       string
+	}{
+		{
+			name:          "target within batch size",
+			size:          3,
+			original:      mockResourceList("a", "b"),
+			target:        mockResourceList("a", "b", "c"),
+			wantOriginals: [][]string{{"a", "b"}},
+			wantTargets:   [][]string{{"a", "b", "c"}},
+		},
+		{
+			name:          "applies batches in order",
+			size:          2,
+			original:      mockResourceList("a", "c"),
+			target:        mockResourceList("a", "b", "c", "d", "e"),
+			wantOriginals: [][]string{{"a"}, {"c"}, {}},
+			wantTargets:   [][]string{{"a", "b"}, {"c", "d"}, {"e"}},
+			wantLogs: []string{
+				"applied batch 1/3 (2/5 resources)",
+				"applied batch 2/3 (4/5 resources)",
+				"applied batch 3/3 (5/5 resources)",
+			},
+			wantProgress: BatchProgress{Applied: 3, Total: 3},
+		},
+		{
+			name:          "deletes stale resources after batches",
+			size:          1,
+			original:      mockResourceList("a", "x"),
+			target:        mockResourceList("a", "b"),
+			wantOriginals: [][]string{{"a"}, {}, {"x"}},
+			wantTargets:   [][]string{{"a"}, {"b"}, {}},
+			wantLogs: []string{
+				"applied batch 1/2 (1/2 resources)",
+				"applied batch 2/2 (2/2 resources)",
+			},
+			wantProgress: BatchProgress{Applied: 2, Total: 2},
+		},
+		{
+			name:          "stops at failed batch",
+			size:          1,
+			original:      mockResourceList("x"),
+			target:        mockResourceList("a", "b", "c"),
+			failAt:        2,
+			wantOriginals: [][]string{{}, {}},
+			wantTargets:   [][]string{{"a"}, {"b"}},
+			wantLogs:      []string{"applied batch 1/3 (1/3 resources)"},
+			wantProgress:  BatchProgress{Applied: 1, Total: 3},
+			wantErr:       "failed to apply batch 2/3 after applying 1/3 resources: update error",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			client := &recordingKubeClient{
+				PrintingKubeClient: &kubefake.PrintingKubeClient{Out: io.Discard},
+				failAt:             tt.failAt,
+			}
+			var logs []string
+			c := newBatchKubeClient(context.TODO(), client, tt.size, 0, func(format string, v ...interface{}) {
+				logs = append(logs, fmt.Sprintf(format, v...))
+			})
+			c.pause = 0
+
+			_, err := c.Update(tt.original, tt.target, false)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(tt.wantErr))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+			g.Expect(client.originals).To(Equal(tt.wantOriginals))
+			g.Expect(client.targets).To(Equal(tt.wantTargets))
+			g.Expect(logs).To(Equal(tt.wantLogs))
+			g.Expect(AppliedBatches(&helmaction.Configuration{KubeClient: c})).To(Equal(tt.wantProgress))
+		})
+	}
+}
+
+func Test_batchKubeClient_Update_Pause(t *testing.T) {
+	t.Run("stops pausing when the context is done", func(t *testing.T) {
+		g := NewWithT(t)
+
+		ctx, cancel := context.WithCancel(context.TODO())
+		cancel()
+
+		client := &recordingKubeClient{PrintingKubeClient: &kubefake.PrintingKubeClient{Out: io.Discard}}
+		c := newBatchKubeClient(ctx, client, 1, time.Hour, nil)
+		c.pause = time.Hour
+
+		_, err := c.Update(nil, mockResourceList("a", "b"), false)
+		g.Expect(err).To(MatchError(context.Canceled))
+		g.Expect(client.targets).To(Equal([][]string{{"a"}}))
+		g.Expect(c.progress).To(Equal(BatchProgress{Applied: 1, Total: 2}))
+	})
+
+	t.Run("fails when pausing exceeds the timeout", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &recordingKubeClient{PrintingKubeClient: &kubefake.PrintingKubeClient{Out: io.Discard}}
+		c := newBatchKubeClient(context.TODO(), client, 1, time.Second, nil)
+		c.pause = time.Second
+
+		_, err := c.Update(nil, mockResourceList("a", "b"), false)
+		g.Expect(err).To(MatchError("failed to apply batch 2/2 after applying 1/2 resources: " +
+			"pausing between batches exceeds the timeout of 1s"))
+		g.Expect(client.targets).To(Equal([][]string{{"a"}}))
+	})
+
+	t.Run("subtracts the pauses from the timeout", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &recordingKubeClient{PrintingKubeClient: &kubefake.PrintingKubeClient{Out: io.Discard}}
+		c := newBatchKubeClient(context.TODO(), client, 1, time.Second, nil)
+		c.pause = 10 * time.Millisecond
+
+		_, err := c.Update(nil, mockResourceList("a", "b", "c"), false)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.remaining(time.Second)).To(Equal(time.Second - 20*time.Millisecond))
+	})
+}
@@ -25,6 +25,7 @@ import (
 	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
+	v2 "github.com/fluxcd/helm-controller/api/v2"
 	"github.com/fluxcd/helm-controller/internal/storage"
 )
 
@@ -48,6 +49,9 @@ type ConfigFactory struct {
 	KubeClient *helmkube.Client
 	// Driver to use for the Helm action.
 	Driver helmdriver.Driver
+	// Backups is the store for backups of releases, which uses the same
+	// kind of storage as the Driver.
+	Backups BackupStore
 	// StorageLog is the logger to use for the Helm storage driver.
 	StorageLog helmaction.DebugLog
 }
@@ -75,7 +79,8 @@ func NewConfigFactory(getter genericclioptions.RESTClientGetter, opts ...ConfigF
 }
 
 // WithStorage configures the ConfigFactory.Driver by constructing a new Helm
-// driver.Driver using the provided driver name and namespace.
+// driver.Driver using the provided driver name and namespace, and the
+// ConfigFactory.Backups with a BackupStore for the same kind of storage.
 // It supports driver.ConfigMapsDriverName, driver.SecretsDriverName and
 // driver.MemoryDriverName.
 // It returns an error when the driver name is not supported, or the client
@@ -98,14 +103,17 @@ func WithStorage(driver, namespace string) ConfigFactoryOption {
 			}
 			if driver == helmdriver.ConfigMapsDriverName {
 				f.Driver = helmdriver.NewConfigMaps(clientSet.CoreV1().ConfigMaps(namespace))
+				f.Backups = NewConfigMapBackupStore(clientSet.CoreV1().ConfigMaps(namespace))
 			}
 			if driver == helmdriver.SecretsDriverName {
 				f.Driver = helmdriver.NewSecrets(clientSet.CoreV1().Secrets(namespace))
+				f.Backups = NewSecretBackupStore(clientSet.CoreV1().Secrets(namespace))
 			}
 		case helmdriver.MemoryDriverName:
 			driver := helmdriver.NewMemory()
 			driver.SetNamespace(namespace)
 			f.Driver = driver
+			f.Backups = NewMemoryBackupStore()
 		default:
 			return fmt.Errorf("unsupported Helm storage driver '%s'", driver)
 		}
@@ -113,6 +121,23 @@ func WithStorage(driver, namespace string) ConfigFactoryOption {
 	}
 }
 
+// StorageDriverName returns the Helm storage driver name for the given
+// v2.HelmRelease storage driver, for use with WithStorage. An empty driver
+// results in the DefaultStorageDriver, while an unknown driver is returned
+// as is.
+func StorageDriverName(driver string) string {
+	switch driver {
+	case "":
+		return DefaultStorageDriver
+	case v2.StorageDriverSecret:
+		return helmdriver.SecretsDriverName
+	case v2.StorageDriverConfigMap:
+		return helmdriver.ConfigMapsDriverName
+	default:
+		return driver
+	}
+}
+
 // WithDriver sets the ConfigFactory.Driver.
 func WithDriver(driver helmdriver.Driver) ConfigFactoryOption {
 	return func(f *ConfigFactory) error {
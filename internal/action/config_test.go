@@ -28,6 +28,7 @@ import (
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	cmdtest "k8s.io/kubectl/pkg/cmd/testing"
 
+	v2 "github.com/fluxcd/helm-controller/api/v2"
 	"github.com/fluxcd/helm-controller/internal/kube"
 	"github.com/fluxcd/helm-controller/internal/storage"
 )
@@ -153,6 +154,7 @@ func TestWithStorage(t *testing.T) {
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(factory.Driver).ToNot(BeNil())
 			g.Expect(factory.Driver.Name()).To(Equal(tt.wantDriver))
+			g.Expect(factory.Backups).ToNot(BeNil())
 		})
 	}
 }
@@ -327,3 +329,22 @@ func TestConfigFactory_Valid(t *testing.T) {
 		})
 	}
 }
+
+func TestStorageDriverName(t *testing.T) {
+	tests := []struct {
+		driver string
+		want   string
+	}{
+		{driver: "", want: DefaultStorageDriver},
+		{driver: v2.StorageDriverSecret, want: helmdriver.SecretsDriverName},
+		{driver: v2.StorageDriverConfigMap, want: helmdriver.ConfigMapsDriverName},
+		{driver: "memory", want: "memory"},
+		{driver: "unknown", want: "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.driver, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(StorageDriverName(tt.driver)).To(Equal(tt.want))
+		})
+	}
+}
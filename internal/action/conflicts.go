@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	helmaction "helm.sh/helm/v3/pkg/action"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ssautil "github.com/fluxcd/pkg/ssa/utils"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/diff"
+)
+
+// ErrResourceConflict is returned by CheckResourceConflicts when a resource
+// of the release is managed by another Helm release.
+var ErrResourceConflict = errors.New("resource conflict")
+
+// CheckResourceConflicts checks if any of the resources in the given
+// manifest exists in the cluster while being managed by a Helm release other
+// than the release with the given name and namespace. It returns an error
+// wrapping ErrResourceConflict describing the conflicts, if any.
+//
+// Resources which do not exist, or are not managed by Helm, are not
+// considered to be in conflict.
+func CheckResourceConflicts(ctx context.Context, config *helmaction.Configuration, manifest,
+	releaseName, releaseNamespace string) error {
+	objects, err := ssautil.ReadObjects(strings.NewReader(manifest))
+	if err != nil {
+		return fmt.Errorf("failed to read objects from release manifest: %w", err)
+	}
+
+	cfg, err := config.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return err
+	}
+
+	var conflicts []string
+	for _, obj := range objects {
+		ns := obj.GetNamespace()
+		if ns == "" {
+			ns = releaseNamespace
+		}
+		existing := &unstructured.Unstructured{}
+		existing.SetGroupVersionKind(obj.GroupVersionKind())
+		if err = c.Get(ctx, client.ObjectKey{Namespace: ns, Name: obj.GetName()}, existing); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return fmt.Errorf("failed to get %s: %w", diff.ResourceName(obj), err)
+		}
+		if msg := resourceConflict(existing, releaseName, releaseNamespace); msg != "" {
+			conflicts = append(conflicts, msg)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: %s", ErrResourceConflict, strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// resourceConflict returns a message describing the conflict if the given
+// resource is managed by a Helm release other than the release with the
+// given name and namespace, or an empty string.
+func resourceConflict(obj *unstructured.Unstructured, releaseName, releaseNamespace string) string {
+	annotations := obj.GetAnnotations()
+	name, ns := annotations[helmReleaseNameAnnotation], annotations[helmReleaseNamespaceAnnotation]
+	if name == "" || (name == releaseName && ns == releaseNamespace) {
+		return ""
+	}
+
+	owner := fmt.Sprintf("Helm release %s/%s", ns, name)
+	labels := obj.GetLabels()
+	if hrName, hrNS := labels[v2.GroupVersion.Group+"/name"], labels[v2.GroupVersion.Group+"/namespace"]; hrName != "" {
+		owner = fmt.Sprintf("HelmRelease %s/%s (%s)", hrNS, hrName, owner)
+	}
+	return fmt.Sprintf("%s is managed by %s", diff.ResourceName(obj), owner)
+}
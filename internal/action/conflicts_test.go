@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func Test_resourceConflict(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		labels      map[string]string
+		want        string
+	}{
+		{
+			name: "not managed by Helm",
+		},
+		{
+			name: "managed by same release",
+			annotations: map[string]string{
+				helmReleaseNameAnnotation:      "podinfo",
+				helmReleaseNamespaceAnnotation: "default",
+			},
+		},
+		{
+			name: "managed by release with same name in other namespace",
+			annotations: map[string]string{
+				helmReleaseNameAnnotation:      "podinfo",
+				helmReleaseNamespaceAnnotation: "other",
+			},
+			want: "ConfigMap/default/config is managed by Helm release other/podinfo",
+		},
+		{
+			name: "managed by other HelmRelease",
+			annotations: map[string]string{
+				helmReleaseNameAnnotation:      "other",
+				helmReleaseNamespaceAnnotation: "default",
+			},
+			labels: map[string]string{
+				"helm.toolkit.fluxcd.io/name":      "other",
+				"helm.toolkit.fluxcd.io/namespace": "flux-system",
+			},
+			want: "ConfigMap/default/config is managed by HelmRelease flux-system/other (Helm release default/other)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &unstructured.Unstructured{}
+			obj.SetKind("ConfigMap")
+			obj.SetNamespace("default")
+			obj.SetName("config")
+			obj.SetAnnotations(tt.annotations)
+			obj.SetLabels(tt.labels)
+
+			g.Expect(resourceConflict(obj, "podinfo", "default")).To(Equal(tt.want))
+		})
+	}
+}
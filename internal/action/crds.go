@@ -19,13 +19,17 @@ package action
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	helmaction "helm.sh/helm/v3/pkg/action"
 	helmchart "helm.sh/helm/v3/pkg/chart"
 	helmkube "helm.sh/helm/v3/pkg/kube"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apiextension "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiextensionsclientv1 "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset/typed/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,6 +45,12 @@ const (
 	DefaultCRDPolicy = v2.Create
 )
 
+// ErrCRDConflict is returned by Install and Upgrade when a
+// CustomResourceDefinition of the chart already exists in the cluster with
+// a spec it can not be replaced with, e.g. because the change would remove
+// a stored version.
+var ErrCRDConflict = errors.New("CustomResourceDefinition conflict")
+
 var accessor = apimeta.NewAccessor()
 
 // crdPolicy returns the CRD policy for the given CRD.
@@ -168,6 +178,13 @@ func applyCRDs(cfg *helmaction.Configuration, policy v2.CRDsPolicy, chrt *helmch
 			}
 		}
 
+		// Verify the existing CustomResourceDefinitions can be replaced,
+		// to surface a conflict instead of the error of a partial update.
+		if err = dryRunReplaceCRDs(client, original, allCRDs); err != nil {
+			cfg.Log(err.Error())
+			return err
+		}
+
 		// Send them to Kubernetes...
 		if rr, err := cfg.KubeClient.Update(original, allCRDs, true); err != nil {
 			err = fmt.Errorf("failed to update CustomResourceDefinition(s): %w", err)
@@ -214,6 +231,55 @@ func applyCRDs(cfg *helmaction.Configuration, policy v2.CRDsPolicy, chrt *helmch
 	return nil
 }
 
+// dryRunReplaceCRDs performs a server-side dry-run of the replacement of the
+// existing CustomResourceDefinitions with the desired ones. It returns an
+// error wrapping ErrCRDConflict if the API server rejects the replacement of
+// any of them. Desired objects of an API version other than v1 are skipped.
+func dryRunReplaceCRDs(client apiextensionsclientv1.CustomResourceDefinitionInterface, existing, desired helmkube.ResourceList) error {
+	resourceVersions := make(map[string]string, len(existing))
+	for _, e := range existing {
+		resourceVersions[e.Name] = e.ResourceVersion
+	}
+
+	var conflicts []string
+	for _, d := range desired {
+		rv, ok := resourceVersions[d.Name]
+		if !ok || d.Mapping.GroupVersionKind.Version != apiextensionsv1.SchemeGroupVersion.Version {
+			continue
+		}
+
+		var content map[string]interface{}
+		if u, ok := d.Object.(apiruntime.Unstructured); ok {
+			content = u.UnstructuredContent()
+		} else {
+			var err error
+			if content, err = apiruntime.DefaultUnstructuredConverter.ToUnstructured(d.Object); err != nil {
+				return fmt.Errorf("failed to convert CustomResourceDefinition %s: %w", d.Name, err)
+			}
+		}
+		crd := &apiextensionsv1.CustomResourceDefinition{}
+		if err := apiruntime.DefaultUnstructuredConverter.FromUnstructured(content, crd); err != nil {
+			return fmt.Errorf("failed to convert CustomResourceDefinition %s: %w", d.Name, err)
+		}
+		crd.ResourceVersion = rv
+
+		_, err := client.Update(context.TODO(), crd, metav1.UpdateOptions{DryRun: []string{metav1.DryRunAll}})
+		switch {
+		case err == nil:
+		case apierrors.IsInvalid(err):
+			conflicts = append(conflicts, fmt.Sprintf("%s: %s", d.Name, err))
+		default:
+			return fmt.Errorf("failed to verify update of CustomResourceDefinition %s: %w", d.Name, err)
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%w: existing CustomResourceDefinition(s) can not be replaced: %s",
+			ErrCRDConflict, strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
 func setOriginVisitor(group, namespace, name string) resource.VisitorFunc {
 	return func(info *resource.Info, err error) error {
 		if err != nil {
@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	helmaction "helm.sh/helm/v3/pkg/action"
+	helmchart "helm.sh/helm/v3/pkg/chart"
+	helmchartutil "helm.sh/helm/v3/pkg/chartutil"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	"k8s.io/client-go/discovery"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/release"
+)
+
+// dryRunServer is the Helm dry-run option which renders the release while
+// allowing the chart to interact with the cluster, without persisting
+// anything.
+const dryRunServer = "server"
+
+// DryRunInstall runs a server-side dry-run of the Helm install action with
+// the provided config, using the v2.HelmReleaseSpec of the given object to
+// determine the candidate release.
+//
+// Contrary to Install, it does not apply the CRDs of the chart, nor does it
+// wait for the resources to become ready. The returned release is not
+// written to the Helm storage.
+func DryRunInstall(ctx context.Context, config *helmaction.Configuration, obj *v2.HelmRelease,
+	chrt *helmchart.Chart, vals helmchartutil.Values) (*helmrelease.Release, error) {
+	install := newInstall(config, obj, []InstallOption{func(install *helmaction.Install) {
+		install.DryRun = true
+		install.DryRunOption = dryRunServer
+		install.Wait = false
+		install.WaitForJobs = false
+		install.CreateNamespace = false
+	}})
+	return install.RunWithContext(ctx, chrt, vals.AsMap())
+}
+
+// DryRunUpgrade runs a server-side dry-run of the Helm upgrade action with
+// the provided config, using the v2.HelmReleaseSpec of the given object to
+// determine the candidate release.
+//
+// Contrary to Upgrade, it does not apply the CRDs of the chart, nor does it
+// wait for the resources to become ready. The returned release is not
+// written to the Helm storage.
+func DryRunUpgrade(ctx context.Context, config *helmaction.Configuration, obj *v2.HelmRelease,
+	chrt *helmchart.Chart, vals helmchartutil.Values) (*helmrelease.Release, error) {
+	if err := obj.GetUpgrade().ValidateValuesOptions(); err != nil {
+		return nil, err
+	}
+
+	upgrade := newUpgrade(config, obj, []UpgradeOption{func(upgrade *helmaction.Upgrade) {
+		upgrade.DryRun = true
+		upgrade.DryRunOption = dryRunServer
+		upgrade.Wait = false
+		upgrade.WaitForJobs = false
+	}})
+	return upgrade.RunWithContext(ctx, release.ShortenName(obj.GetReleaseName()), chrt, vals.AsMap())
+}
+
+// RenderRelease renders the manifest of the Helm release for the given chart
+// and values without performing any changes to the cluster or the Helm
+// storage, using the capabilities of the cluster.
+//
+// The given configuration is modified by Helm to use an in-memory storage
+// and a no-op client, the caller is expected to provide a configuration
+// which is not used for any other action.
+func RenderRelease(ctx context.Context, config *helmaction.Configuration, obj *v2.HelmRelease,
+	chrt *helmchart.Chart, vals helmchartutil.Values) (*helmrelease.Release, error) {
+	dc, err := config.RESTClientGetter.ToDiscoveryClient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	sv, err := dc.ServerVersion()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine server version: %w", err)
+	}
+	kubeVersion, err := helmchartutil.ParseKubeVersion(sv.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse server version: %w", err)
+	}
+	apiVersions, err := helmaction.GetVersionSet(dc)
+	if err != nil {
+		// A partial discovery result still holds the API versions of the
+		// groups which could be discovered, which suffices to render.
+		var groupErr *discovery.ErrGroupDiscoveryFailed
+		if !errors.As(err, &groupErr) {
+			return nil, fmt.Errorf("failed to determine API versions: %w", err)
+		}
+	}
+	return renderRelease(ctx, config, obj, chrt, vals, kubeVersion, apiVersions)
+}
+
+// RenderReleaseForProfile renders the manifest of the Helm release for the
+// given chart and values without performing any changes to the cluster or
+// the Helm storage, using the Kubernetes version and API versions of the
+// given profile. The API versions of the profile are added to the default
+// API versions of Helm.
+//
+// The same restrictions on the given configuration as for RenderRelease
+// apply.
+func RenderReleaseForProfile(ctx context.Context, config *helmaction.Configuration, obj *v2.HelmRelease,
+	chrt *helmchart.Chart, vals helmchartutil.Values, profile v2.CapabilityProfile) (*helmrelease.Release, error) {
+	kubeVersion, err := helmchartutil.ParseKubeVersion(profile.KubeVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse kubeVersion of profile '%s': %w", profile.Name, err)
+	}
+	return renderRelease(ctx, config, obj, chrt, vals, kubeVersion, profile.APIVersions)
+}
+
+// renderRelease performs a client-only dry-run install of the Helm release
+// using the given capabilities.
+func renderRelease(ctx context.Context, config *helmaction.Configuration, obj *v2.HelmRelease,
+	chrt *helmchart.Chart, vals helmchartutil.Values, kubeVersion *helmchartutil.KubeVersion,
+	apiVersions helmchartutil.VersionSet) (*helmrelease.Release, error) {
+	install := newInstall(config, obj, []InstallOption{func(install *helmaction.Install) {
+		install.DryRun = true
+		install.ClientOnly = true
+		install.IsUpgrade = true
+		install.Replace = true
+		install.DisableHooks = true
+		install.KubeVersion = kubeVersion
+		install.APIVersions = apiVersions
+	}})
+	return install.RunWithContext(ctx, chrt, vals.AsMap())
+}
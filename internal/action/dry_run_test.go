@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	helmaction "helm.sh/helm/v3/pkg/action"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/testutil"
+)
+
+func TestRenderReleaseForProfile(t *testing.T) {
+	chrt := testutil.BuildChart()
+	chrt.Metadata.KubeVersion = ">=1.25.0-0"
+
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "render",
+			Namespace: "render-ns",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		profile v2.CapabilityProfile
+		wantErr string
+	}{
+		{
+			name:    "compatible version",
+			profile: v2.CapabilityProfile{Name: "current", KubeVersion: "1.28.0"},
+		},
+		{
+			name:    "incompatible version",
+			profile: v2.CapabilityProfile{Name: "legacy", KubeVersion: "1.24.0"},
+			wantErr: "chart requires kubeVersion",
+		},
+		{
+			name:    "invalid version",
+			profile: v2.CapabilityProfile{Name: "invalid", KubeVersion: "latest"},
+			wantErr: "failed to parse kubeVersion of profile 'invalid'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cfg := &helmaction.Configuration{Log: func(string, ...interface{}) {}}
+			rls, err := RenderReleaseForProfile(context.TODO(), cfg, obj, chrt, nil, tt.profile)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(rls.Manifest).ToNot(BeEmpty())
+		})
+	}
+}
@@ -0,0 +1,258 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	helmaction "helm.sh/helm/v3/pkg/action"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/fluxcd/cli-utils/pkg/kstatus/status"
+	"github.com/fluxcd/pkg/apis/kustomize"
+	"github.com/fluxcd/pkg/ssa/jsondiff"
+	ssautil "github.com/fluxcd/pkg/ssa/utils"
+
+	"github.com/fluxcd/helm-controller/internal/diff"
+)
+
+// ResourceHealth is the result of a health assessment of the resources in
+// the manifest of a Helm release.
+type ResourceHealth struct {
+	// Total is the number of assessed resources.
+	Total int
+	// Healthy is the number of resources with a current status.
+	Healthy int
+	// Unhealthy holds the names of the resources which are not healthy.
+	Unhealthy []string
+	// UnhealthyCritical holds the names of the resources which are not
+	// healthy, and have been marked as critical.
+	UnhealthyCritical []string
+}
+
+// Percentage returns the percentage of healthy resources, rounded down.
+// It returns 100 if no resources have been assessed.
+func (h ResourceHealth) Percentage() int {
+	if h.Total == 0 {
+		return 100
+	}
+	return h.Healthy * 100 / h.Total
+}
+
+// Meets returns true if the percentage of healthy resources is equal to or
+// higher than the given threshold, and none of the critical resources are
+// unhealthy.
+func (h ResourceHealth) Meets(threshold int) bool {
+	return len(h.UnhealthyCritical) == 0 && h.Percentage() >= threshold
+}
+
+// String returns a summary of the ResourceHealth.
+func (h ResourceHealth) String() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%d%% of resources healthy (%d/%d)", h.Percentage(), h.Healthy, h.Total))
+	if len(h.UnhealthyCritical) > 0 {
+		sb.WriteString(fmt.Sprintf(", unhealthy critical resources: %s", strings.Join(h.UnhealthyCritical, ", ")))
+	}
+	return sb.String()
+}
+
+// AssessHealth computes the health of the resources in the manifest of the
+// given Helm release, by computing the kstatus of the objects in the cluster.
+// Objects matching any of the critical selectors are recorded in
+// ResourceHealth.UnhealthyCritical when they are not healthy.
+func AssessHealth(ctx context.Context, config *helmaction.Configuration, rls *helmrelease.Release, critical ...kustomize.Selector) (*ResourceHealth, error) {
+	c, err := newHealthClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	selectors, err := selectorRegexes(critical)
+	if err != nil {
+		return nil, fmt.Errorf("invalid critical resource selector: %w", err)
+	}
+
+	objects, err := releaseObjects(c, rls)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &ResourceHealth{}
+	for _, obj := range objects {
+		isCritical := matchesAny(selectors, obj)
+
+		health.Total++
+		if res, err := computeStatus(ctx, c, obj); err != nil {
+			return nil, err
+		} else if res.Status == status.CurrentStatus {
+			health.Healthy++
+			continue
+		}
+
+		name := diff.ResourceName(obj)
+		health.Unhealthy = append(health.Unhealthy, name)
+		if isCritical {
+			health.UnhealthyCritical = append(health.UnhealthyCritical, name)
+		}
+	}
+	return health, nil
+}
+
+// ResourceStatus is the computed status of a resource in the manifest of a
+// Helm release.
+type ResourceStatus struct {
+	// Name is the name of the resource, as returned by diff.ResourceName.
+	Name string
+	// Status is the kstatus of the resource, or status.NotFoundStatus if it
+	// does not exist in the cluster.
+	Status status.Status
+	// Message holds details about the status.
+	Message string
+}
+
+// Healthy returns true if the resource is healthy.
+func (s ResourceStatus) Healthy() bool {
+	return s.Status == status.CurrentStatus
+}
+
+// CheckHealth computes the status of the resources in the manifest of the
+// given Helm release which match any of the given selectors, by computing
+// the kstatus of the objects in the cluster. When no selectors are given,
+// the status of all resources is computed.
+func CheckHealth(ctx context.Context, config *helmaction.Configuration, rls *helmrelease.Release, selectors ...kustomize.Selector) ([]ResourceStatus, error) {
+	c, err := newHealthClient(config)
+	if err != nil {
+		return nil, err
+	}
+
+	regexes, err := selectorRegexes(selectors)
+	if err != nil {
+		return nil, fmt.Errorf("invalid health check selector: %w", err)
+	}
+
+	objects, err := releaseObjects(c, rls)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []ResourceStatus
+	for _, obj := range objects {
+		if len(regexes) > 0 && !matchesAny(regexes, obj) {
+			continue
+		}
+		res, err := computeStatus(ctx, c, obj)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, ResourceStatus{
+			Name:    diff.ResourceName(obj),
+			Status:  res.Status,
+			Message: res.Message,
+		})
+	}
+	return result, nil
+}
+
+// newHealthClient returns a client for the cluster targeted by the given
+// Helm configuration.
+func newHealthClient(config *helmaction.Configuration) (client.Client, error) {
+	cfg, err := config.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	return client.New(cfg, client.Options{})
+}
+
+// selectorRegexes compiles the given selectors.
+func selectorRegexes(selectors []kustomize.Selector) ([]*jsondiff.SelectorRegex, error) {
+	regexes := make([]*jsondiff.SelectorRegex, 0, len(selectors))
+	for i := range selectors {
+		s := selectors[i]
+		sr, err := jsondiff.NewSelectorRegex(&jsondiff.Selector{
+			Group:              s.Group,
+			Version:            s.Version,
+			Kind:               s.Kind,
+			Name:               s.Name,
+			Namespace:          s.Namespace,
+			AnnotationSelector: s.AnnotationSelector,
+			LabelSelector:      s.LabelSelector,
+		})
+		if err != nil {
+			return nil, err
+		}
+		regexes = append(regexes, sr)
+	}
+	return regexes, nil
+}
+
+// matchesAny returns true if the object matches any of the given selectors.
+func matchesAny(selectors []*jsondiff.SelectorRegex, obj *unstructured.Unstructured) bool {
+	for _, s := range selectors {
+		if s.MatchUnstructured(obj) {
+			return true
+		}
+	}
+	return false
+}
+
+// releaseObjects returns the objects in the manifest of the given Helm
+// release, with the namespace of the release set on namespaced objects
+// without a namespace.
+func releaseObjects(c client.Client, rls *helmrelease.Release) ([]*unstructured.Unstructured, error) {
+	objects, err := ssautil.ReadObjects(strings.NewReader(rls.Manifest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read objects from release manifest: %w", err)
+	}
+	for _, obj := range objects {
+		if obj.GetNamespace() != "" {
+			continue
+		}
+		namespaced, err := apiutil.IsObjectNamespaced(obj, c.Scheme(), c.RESTMapper())
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine if %s is namespace scoped: %w",
+				obj.GetObjectKind().GroupVersionKind().Kind, err)
+		}
+		if namespaced {
+			obj.SetNamespace(rls.Namespace)
+		}
+	}
+	return objects, nil
+}
+
+// computeStatus returns the kstatus of the object in the cluster. When the
+// object does not exist, status.NotFoundStatus is returned. When the status
+// can not be computed, status.UnknownStatus is returned.
+func computeStatus(ctx context.Context, c client.Client, obj *unstructured.Unstructured) (*status.Result, error) {
+	live := &unstructured.Unstructured{}
+	live.SetGroupVersionKind(obj.GroupVersionKind())
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), live); err != nil {
+		if apierrors.IsNotFound(err) {
+			return &status.Result{Status: status.NotFoundStatus, Message: "resource not found"}, nil
+		}
+		return nil, fmt.Errorf("failed to get %s: %w", diff.ResourceName(obj), err)
+	}
+	res, err := status.Compute(live)
+	if err != nil {
+		return &status.Result{Status: status.UnknownStatus, Message: err.Error()}, nil
+	}
+	return res, nil
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestResourceHealth_Meets(t *testing.T) {
+	tests := []struct {
+		name           string
+		health         ResourceHealth
+		threshold      int
+		wantPercentage int
+		want           bool
+	}{
+		{
+			name:           "no resources",
+			health:         ResourceHealth{},
+			threshold:      100,
+			wantPercentage: 100,
+			want:           true,
+		},
+		{
+			name:           "all resources healthy",
+			health:         ResourceHealth{Total: 10, Healthy: 10},
+			threshold:      100,
+			wantPercentage: 100,
+			want:           true,
+		},
+		{
+			name:           "above threshold",
+			health:         ResourceHealth{Total: 10, Healthy: 9, Unhealthy: []string{"Deployment/default/a"}},
+			threshold:      90,
+			wantPercentage: 90,
+			want:           true,
+		},
+		{
+			name:           "below threshold",
+			health:         ResourceHealth{Total: 10, Healthy: 8, Unhealthy: []string{"Deployment/default/a", "Deployment/default/b"}},
+			threshold:      90,
+			wantPercentage: 80,
+			want:           false,
+		},
+		{
+			name: "unhealthy critical resource above threshold",
+			health: ResourceHealth{
+				Total: 10, Healthy: 9,
+				Unhealthy:         []string{"Deployment/default/a"},
+				UnhealthyCritical: []string{"Deployment/default/a"},
+			},
+			threshold:      50,
+			wantPercentage: 90,
+			want:           false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(tt.health.Percentage()).To(Equal(tt.wantPercentage))
+			g.Expect(tt.health.Meets(tt.threshold)).To(Equal(tt.want))
+		})
+	}
+}
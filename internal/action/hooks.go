@@ -0,0 +1,222 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"fmt"
+
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/postrender"
+	"github.com/fluxcd/helm-controller/internal/release"
+)
+
+// hookFilterDriver is a helmdriver.Driver which removes the disabled hooks
+// from a release before it is created in the embedded driver.
+//
+// Helm runs the hooks of the release object it creates in the storage, which
+// allows the selection of the hooks to run without changes to the chart.
+// As the hooks are removed before the release is persisted, the release in
+// the storage reflects the hooks which actually ran, including e.g. the test
+// hooks which are later run by the Helm test action.
+type hookFilterDriver struct {
+	helmdriver.Driver
+
+	disabled []string
+}
+
+// newHookFilterDriver returns a hookFilterDriver which removes the hooks
+// matching any of the disabled hook types (e.g. "pre-install") or names from
+// a release, before creating it in the given driver.
+func newHookFilterDriver(driver helmdriver.Driver, disabled []string) *hookFilterDriver {
+	return &hookFilterDriver{Driver: driver, disabled: disabled}
+}
+
+// Create removes the disabled hooks from the release, and creates it in the
+// embedded driver.
+func (d *hookFilterDriver) Create(key string, rls *helmrelease.Release) error {
+	if rls != nil {
+		rls.Hooks = filterHooks(rls.Hooks, d.disabled)
+	}
+	return d.Driver.Create(key, rls)
+}
+
+// testHookExcludeDriver is a helmdriver.Driver which removes the test hooks
+// from a release before it is created in the embedded driver.
+//
+// Contrary to a hookFilterDriver with the "test" hook type disabled, the
+// test hooks are not considered to be disabled: they are added to the
+// release again by a testHookInjectDriver when the Helm test action runs.
+type testHookExcludeDriver struct {
+	helmdriver.Driver
+}
+
+// newTestHookExcludeDriver returns a testHookExcludeDriver which removes the
+// test hooks from a release, before creating it in the given driver.
+func newTestHookExcludeDriver(driver helmdriver.Driver) *testHookExcludeDriver {
+	return &testHookExcludeDriver{Driver: driver}
+}
+
+// Create removes the test hooks from the release, and creates it in the
+// embedded driver.
+func (d *testHookExcludeDriver) Create(key string, rls *helmrelease.Release) error {
+	if rls != nil {
+		rls.Hooks = withoutTestHooks(rls.Hooks)
+	}
+	return d.Driver.Create(key, rls)
+}
+
+// testHookInjectDriver is a helmdriver.Driver which adds the given test hooks
+// to the targeted release when it is read from the embedded driver, replacing
+// any test hooks the release holds.
+//
+// This allows the Helm test action to run test hooks which were excluded
+// from the release when it was installed or upgraded. As Helm updates the
+// release after running the tests, the release in the storage holds the
+// test hooks which actually ran afterwards.
+type testHookInjectDriver struct {
+	helmdriver.Driver
+
+	name    string
+	version int
+	hooks   []*helmrelease.Hook
+}
+
+// newTestHookInjectDriver returns a testHookInjectDriver which adds the given
+// test hooks to the release with the given name and version, when it is read
+// from the given driver.
+func newTestHookInjectDriver(driver helmdriver.Driver, name string, version int, hooks []*helmrelease.Hook) *testHookInjectDriver {
+	return &testHookInjectDriver{Driver: driver, name: name, version: version, hooks: hooks}
+}
+
+// Get returns the release for the given key from the embedded driver, with
+// the test hooks added if it is the targeted release.
+func (d *testHookInjectDriver) Get(key string) (*helmrelease.Release, error) {
+	rls, err := d.Driver.Get(key)
+	if err != nil {
+		return rls, err
+	}
+	return d.inject(rls), nil
+}
+
+// Query returns the releases matching the given labels from the embedded
+// driver, with the test hooks added to the targeted release.
+func (d *testHookInjectDriver) Query(labels map[string]string) ([]*helmrelease.Release, error) {
+	results, err := d.Driver.Query(labels)
+	if err != nil {
+		return results, err
+	}
+	for i := range results {
+		results[i] = d.inject(results[i])
+	}
+	return results, nil
+}
+
+// inject returns a copy of the given release with the test hooks replaced by
+// the test hooks of the driver, or the release as-is if it is not targeted.
+func (d *testHookInjectDriver) inject(rls *helmrelease.Release) *helmrelease.Release {
+	if rls == nil || rls.Name != d.name || rls.Version != d.version {
+		return rls
+	}
+	injected := *rls
+	injected.Hooks = append(withoutTestHooks(rls.Hooks), d.hooks...)
+	return &injected
+}
+
+// hookMetadataDriver is a helmdriver.Driver which sets the common metadata
+// on the hooks of a release before it is created in the embedded driver.
+//
+// Helm does not pass the manifests of hooks through the post renderers, but
+// runs the hooks of the release object it creates in the storage. Setting
+// the metadata on this object ensures the resources of the hooks carry it
+// as well.
+type hookMetadataDriver struct {
+	helmdriver.Driver
+
+	metadata *v2.CommonMetadata
+}
+
+// newHookMetadataDriver returns a hookMetadataDriver which sets the given
+// common metadata on the hooks of a release, before creating it in the given
+// driver.
+func newHookMetadataDriver(driver helmdriver.Driver, metadata *v2.CommonMetadata) *hookMetadataDriver {
+	return &hookMetadataDriver{Driver: driver, metadata: metadata}
+}
+
+// Create sets the common metadata on the hooks of the release, and creates
+// it in the embedded driver.
+func (d *hookMetadataDriver) Create(key string, rls *helmrelease.Release) error {
+	if rls != nil {
+		for _, h := range rls.Hooks {
+			manifest, err := postrender.ApplyCommonMetadata(h.Manifest, d.metadata)
+			if err != nil {
+				return fmt.Errorf("failed to set common metadata on hook %s: %w", h.Name, err)
+			}
+			h.Manifest = manifest
+		}
+	}
+	return d.Driver.Create(key, rls)
+}
+
+// filterHooks returns the hooks which do not match any of the disabled hook
+// types or names.
+func filterHooks(hooks []*helmrelease.Hook, disabled []string) []*helmrelease.Hook {
+	if len(disabled) == 0 {
+		return hooks
+	}
+	var enabled []*helmrelease.Hook
+	for _, h := range hooks {
+		if !isHookDisabled(h, disabled) {
+			enabled = append(enabled, h)
+		}
+	}
+	return enabled
+}
+
+// isHookDisabled returns true if the name or any of the types of the hook
+// is in the disabled list.
+func isHookDisabled(h *helmrelease.Hook, disabled []string) bool {
+	for _, d := range disabled {
+		if h.Name == d {
+			return true
+		}
+		for _, e := range h.Events {
+			if e.String() == d {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// withoutTestHooks returns the hooks which are not test hooks.
+func withoutTestHooks(hooks []*helmrelease.Hook) []*helmrelease.Hook {
+	var filtered []*helmrelease.Hook
+	for _, h := range hooks {
+		if !isTestHook(h) {
+			filtered = append(filtered, h)
+		}
+	}
+	return filtered
+}
+
+// isTestHook returns true if the hook fires on the test event.
+func isTestHook(h *helmrelease.Hook) bool {
+	return release.IsHookForEvent(h, helmrelease.HookTest)
+}
@@ -0,0 +1,240 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/release"
+)
+
+func Test_hookFilterDriver(t *testing.T) {
+	hooks := func() []*helmrelease.Hook {
+		return []*helmrelease.Hook{
+			{Name: "migration", Events: []helmrelease.HookEvent{helmrelease.HookPreInstall, helmrelease.HookPreUpgrade}},
+			{Name: "notify", Events: []helmrelease.HookEvent{helmrelease.HookPostInstall}},
+			{Name: "smoke-test", Events: []helmrelease.HookEvent{helmrelease.HookTest}},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		disabled      []string
+		want          []string
+		wantTestHooks int
+	}{
+		{
+			name:          "no disabled hooks",
+			disabled:      nil,
+			want:          []string{"migration", "notify", "smoke-test"},
+			wantTestHooks: 1,
+		},
+		{
+			name:          "disabled hook name",
+			disabled:      []string{"migration"},
+			want:          []string{"notify", "smoke-test"},
+			wantTestHooks: 1,
+		},
+		{
+			name:          "disabled hook type",
+			disabled:      []string{"post-install"},
+			want:          []string{"migration", "smoke-test"},
+			wantTestHooks: 1,
+		},
+		{
+			name:          "disabled hook type of hook with multiple types",
+			disabled:      []string{"pre-upgrade"},
+			want:          []string{"notify", "smoke-test"},
+			wantTestHooks: 1,
+		},
+		{
+			name:     "disabled test hooks",
+			disabled: []string{"test"},
+			want:     []string{"migration", "notify"},
+		},
+		{
+			name:          "unknown hook",
+			disabled:      []string{"pre-delete", "other"},
+			want:          []string{"migration", "notify", "smoke-test"},
+			wantTestHooks: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			driver := helmdriver.NewMemory()
+			rls := &helmrelease.Release{
+				Name:    "release",
+				Version: 1,
+				Info:    &helmrelease.Info{Status: helmrelease.StatusPendingInstall},
+				Hooks:   hooks(),
+			}
+			g.Expect(newHookFilterDriver(driver, tt.disabled).Create("release.v1", rls)).To(Succeed())
+
+			// The release Helm runs the hooks of is filtered.
+			var got []string
+			for _, h := range rls.Hooks {
+				got = append(got, h.Name)
+			}
+			g.Expect(got).To(Equal(tt.want))
+
+			// The persisted release is filtered, which keeps the test hooks
+			// recorded for the release consistent with the hooks which ran.
+			stored, err := driver.Get("release.v1")
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(stored.Hooks).To(HaveLen(len(tt.want)))
+			g.Expect(release.TestHooksFromRelease(stored)).To(HaveLen(tt.wantTestHooks))
+		})
+	}
+}
+
+func Test_testHookExcludeDriver(t *testing.T) {
+	g := NewWithT(t)
+
+	driver := helmdriver.NewMemory()
+	rls := &helmrelease.Release{
+		Name:    "release",
+		Version: 1,
+		Info:    &helmrelease.Info{Status: helmrelease.StatusPendingInstall},
+		Hooks: []*helmrelease.Hook{
+			{Name: "migration", Events: []helmrelease.HookEvent{helmrelease.HookPreInstall}},
+			{Name: "smoke-test", Events: []helmrelease.HookEvent{helmrelease.HookTest}},
+			// A hook named after the hook type is not a test hook.
+			{Name: "test", Events: []helmrelease.HookEvent{helmrelease.HookPostInstall}},
+		},
+	}
+	g.Expect(newTestHookExcludeDriver(driver).Create("release.v1", rls)).To(Succeed())
+
+	stored, err := driver.Get("release.v1")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(stored.Hooks).To(HaveLen(2))
+	g.Expect(stored.Hooks[0].Name).To(Equal("migration"))
+	g.Expect(stored.Hooks[1].Name).To(Equal("test"))
+	g.Expect(release.TestHooksFromRelease(stored)).To(BeEmpty())
+}
+
+func Test_testHookInjectDriver(t *testing.T) {
+	newRelease := func(version int, hooks ...*helmrelease.Hook) *helmrelease.Release {
+		return &helmrelease.Release{
+			Name:    "release",
+			Version: version,
+			Info:    &helmrelease.Info{Status: helmrelease.StatusDeployed},
+			Hooks:   hooks,
+		}
+	}
+	hooks := []*helmrelease.Hook{
+		{Name: "smoke-test", Events: []helmrelease.HookEvent{helmrelease.HookTest}},
+	}
+
+	g := NewWithT(t)
+
+	driver := helmdriver.NewMemory()
+	g.Expect(driver.Create("release.v1", newRelease(1))).To(Succeed())
+	g.Expect(driver.Create("release.v2", newRelease(2,
+		&helmrelease.Hook{Name: "migration", Events: []helmrelease.HookEvent{helmrelease.HookPreUpgrade}},
+		&helmrelease.Hook{Name: "old-test", Events: []helmrelease.HookEvent{helmrelease.HookTest}},
+	))).To(Succeed())
+
+	injectDriver := newTestHookInjectDriver(driver, "release", 2, hooks)
+
+	// The targeted release holds the given test hooks, replacing the
+	// persisted test hooks.
+	got, err := injectDriver.Get("release.v2")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got.Hooks).To(HaveLen(2))
+	g.Expect(got.Hooks[0].Name).To(Equal("migration"))
+	g.Expect(got.Hooks[1].Name).To(Equal("smoke-test"))
+
+	// Other releases are returned as-is.
+	got, err = injectDriver.Get("release.v1")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got.Hooks).To(BeEmpty())
+
+	results, err := injectDriver.Query(map[string]string{"name": "release", "owner": "helm"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(results).To(HaveLen(2))
+	for _, r := range results {
+		if r.Version == 2 {
+			g.Expect(release.TestHooksFromRelease(r)).To(HaveKey("smoke-test"))
+		} else {
+			g.Expect(release.TestHooksFromRelease(r)).To(BeEmpty())
+		}
+	}
+
+	// The persisted release is not modified.
+	stored, err := driver.Get("release.v2")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(release.TestHooksFromRelease(stored)).To(HaveKey("old-test"))
+	g.Expect(release.TestHooksFromRelease(stored)).ToNot(HaveKey("smoke-test"))
+}
+
+func Test_hookMetadataDriver(t *testing.T) {
+	g := NewWithT(t)
+
+	driver := helmdriver.NewMemory()
+	rls := &helmrelease.Release{
+		Name:    "release",
+		Version: 1,
+		Info:    &helmrelease.Info{Status: helmrelease.StatusPendingInstall},
+		Hooks: []*helmrelease.Hook{
+			{
+				Name:     "migration",
+				Events:   []helmrelease.HookEvent{helmrelease.HookPreInstall},
+				Manifest: "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migration\n  annotations:\n    helm.sh/hook: pre-install\n",
+			},
+			{
+				Name:     "config",
+				Events:   []helmrelease.HookEvent{helmrelease.HookPostInstall},
+				Manifest: "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  labels:\n    team: other\n",
+			},
+		},
+	}
+	metadata := &v2.CommonMetadata{
+		Labels:      map[string]string{"team": "platform"},
+		Annotations: map[string]string{"cost-center": "1234"},
+	}
+	g.Expect(newHookMetadataDriver(driver, metadata).Create("release.v1", rls)).To(Succeed())
+
+	// The hooks Helm runs, and the persisted hooks, carry the metadata.
+	stored, err := driver.Get("release.v1")
+	g.Expect(err).ToNot(HaveOccurred())
+	for _, hooks := range [][]*helmrelease.Hook{rls.Hooks, stored.Hooks} {
+		g.Expect(hooks).To(HaveLen(2))
+		for _, h := range hooks {
+			g.Expect(h.Manifest).To(ContainSubstring("team: platform"))
+			g.Expect(h.Manifest).To(ContainSubstring(`cost-center: "1234"`))
+		}
+		g.Expect(hooks[0].Manifest).To(ContainSubstring("helm.sh/hook: pre-install"))
+		g.Expect(hooks[1].Manifest).ToNot(ContainSubstring("team: other"))
+	}
+
+	invalid := &helmrelease.Release{
+		Name:    "invalid",
+		Version: 1,
+		Info:    &helmrelease.Info{Status: helmrelease.StatusPendingInstall},
+		Hooks:   []*helmrelease.Hook{{Name: "invalid", Manifest: "invalid: ["}},
+	}
+	err = newHookMetadataDriver(driver, metadata).Create("invalid.v1", invalid)
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("failed to set common metadata on hook invalid"))
+}
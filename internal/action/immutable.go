@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	helmaction "helm.sh/helm/v3/pkg/action"
+	helmkube "helm.sh/helm/v3/pkg/kube"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// immutableFieldMessages are the (partial) messages with which the
+// Kubernetes API server rejects the update of an immutable field.
+var immutableFieldMessages = []string{
+	"field is immutable",
+	"updates to statefulset spec for fields other than",
+}
+
+// patchErrorRegexp matches the error returned by Helm for a resource which
+// could not be updated, capturing the name and kind of the resource.
+var patchErrorRegexp = regexp.MustCompile(`cannot patch "([^"]+)" with kind ([A-Za-z0-9]+): `)
+
+// ImmutableResource identifies a resource of a release which could not be
+// updated due to a change of an immutable field.
+type ImmutableResource struct {
+	// Kind of the resource.
+	Kind string
+	// Name of the resource.
+	Name string
+}
+
+// String returns the resource as "Kind/Name".
+func (r ImmutableResource) String() string {
+	return r.Kind + "/" + r.Name
+}
+
+// IsImmutableFieldError returns true if the given error reports the change
+// of an immutable field of a resource.
+func IsImmutableFieldError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	for _, m := range immutableFieldMessages {
+		if strings.Contains(msg, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// ImmutableFieldConflicts returns the resources which the Helm upgrade action
+// failed to update with the given error, due to a change of an immutable
+// field. Helm joins the errors of multiple resources with " && ".
+func ImmutableFieldConflicts(err error) []ImmutableResource {
+	if !IsImmutableFieldError(err) {
+		return nil
+	}
+
+	var (
+		conflicts []ImmutableResource
+		seen      = make(map[ImmutableResource]struct{})
+	)
+	for _, part := range strings.Split(err.Error(), " && ") {
+		if !IsImmutableFieldError(errors.New(part)) {
+			continue
+		}
+		m := patchErrorRegexp.FindStringSubmatch(part)
+		if m == nil {
+			continue
+		}
+		r := ImmutableResource{Kind: m[2], Name: m[1]}
+		if _, ok := seen[r]; ok {
+			continue
+		}
+		seen[r] = struct{}{}
+		conflicts = append(conflicts, r)
+	}
+	return conflicts
+}
+
+// DeleteImmutableResources deletes the given resources of the given release
+// manifest, and waits for up to the given timeout for them to be removed.
+// This allows a subsequent Helm upgrade action to recreate the resources with
+// the changed immutable fields. It returns an error if any of the resources
+// can not be found in the manifest, or fails to be deleted.
+func DeleteImmutableResources(config *helmaction.Configuration, manifest string, resources []ImmutableResource, timeout time.Duration) error {
+	if len(resources) == 0 {
+		return nil
+	}
+
+	all, err := config.KubeClient.Build(bytes.NewBufferString(manifest), false)
+	if err != nil {
+		return fmt.Errorf("failed to build resources from release manifest: %w", err)
+	}
+
+	want := make(map[ImmutableResource]struct{}, len(resources))
+	for _, r := range resources {
+		want[r] = struct{}{}
+	}
+	targets := all.Filter(func(info *resource.Info) bool {
+		_, ok := want[ImmutableResource{Kind: info.Mapping.GroupVersionKind.Kind, Name: info.Name}]
+		return ok
+	})
+	if len(targets) != len(want) {
+		return fmt.Errorf("found %d out of %d resources to recreate in release manifest", len(targets), len(want))
+	}
+
+	if _, errs := config.KubeClient.Delete(targets); len(errs) > 0 {
+		return fmt.Errorf("failed to delete resources to recreate: %w", errors.Join(errs...))
+	}
+	if kubeClient, ok := config.KubeClient.(helmkube.InterfaceExt); ok {
+		if err = kubeClient.WaitForDelete(targets, timeout); err != nil {
+			return fmt.Errorf("failed to wait for resources to recreate to be deleted: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestImmutableFieldConflicts(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []ImmutableResource
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name: "other error",
+			err:  errors.New(`cannot patch "app" with kind Deployment: admission webhook "validate" denied the request`),
+		},
+		{
+			name: "immutable Job template",
+			err:  errors.New(`cannot patch "migrate" with kind Job: Job.batch "migrate" is invalid: spec.template: Invalid value: core.PodTemplateSpec{}: field is immutable`),
+			want: []ImmutableResource{{Kind: "Job", Name: "migrate"}},
+		},
+		{
+			name: "multiple resources",
+			err: errors.New(`cannot patch "db" with kind StatefulSet: StatefulSet.apps "db" is invalid: spec: Forbidden: updates to statefulset spec for fields other than 'replicas' are forbidden` +
+				` && cannot patch "app" with kind Deployment: admission webhook "validate" denied the request` +
+				` && cannot patch "migrate" with kind Job: Job.batch "migrate" is invalid: spec.template: field is immutable` +
+				` && cannot patch "migrate" with kind Job: Job.batch "migrate" is invalid: spec.selector: field is immutable`),
+			want: []ImmutableResource{{Kind: "StatefulSet", Name: "db"}, {Kind: "Job", Name: "migrate"}},
+		},
+		{
+			name: "immutable error without resource",
+			err:  errors.New("field is immutable"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(ImmutableFieldConflicts(tt.err)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestIsImmutableFieldError(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(IsImmutableFieldError(nil)).To(BeFalse())
+	g.Expect(IsImmutableFieldError(errors.New("timed out waiting for the condition"))).To(BeFalse())
+	g.Expect(IsImmutableFieldError(errors.New(`ConfigMap "cm" is invalid: data: Forbidden: field is immutable when immutable is set`))).To(BeTrue())
+}
+
+func TestImmutableResource_String(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(ImmutableResource{Kind: "Job", Name: "migrate"}.String()).To(Equal("Job/migrate"))
+}
@@ -26,6 +26,7 @@ import (
 	helmrelease "helm.sh/helm/v3/pkg/release"
 
 	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/defaults"
 	"github.com/fluxcd/helm-controller/internal/features"
 	"github.com/fluxcd/helm-controller/internal/postrender"
 	"github.com/fluxcd/helm-controller/internal/release"
@@ -59,7 +60,31 @@ func Install(ctx context.Context, config *helmaction.Configuration, obj *v2.Helm
 		return nil, fmt.Errorf("failed to apply CustomResourceDefinitions: %w", err)
 	}
 
-	return install.RunWithContext(ctx, chrt, vals.AsMap())
+	// Skip the disabled hooks, unless all hooks are disabled.
+	if hooks := obj.GetInstall().DisabledHooks; len(hooks) > 0 && !install.DisableHooks {
+		config.Releases.Driver = newHookFilterDriver(config.Releases.Driver, hooks)
+	}
+
+	// Remove the test hooks, which are rendered again when the Helm test
+	// action runs.
+	if obj.GetTest().ExcludeFromManifest {
+		config.Releases.Driver = newTestHookExcludeDriver(config.Releases.Driver)
+	}
+
+	// Set the common metadata on the hooks, which Helm does not pass through
+	// the post renderers.
+	if obj.Spec.CommonMetadata != nil && !install.DisableHooks {
+		config.Releases.Driver = newHookMetadataDriver(config.Releases.Driver, obj.Spec.CommonMetadata)
+	}
+
+	rls, err := install.RunWithContext(ctx, chrt, vals.AsMap())
+	if err != nil || install.DryRun || !mustWaitForJobsOnly(install.Wait, install.WaitForJobs, obj.GetInstall().WaitForJobs) {
+		return rls, err
+	}
+
+	// Wait for the Jobs of the release to complete, while Helm did not
+	// wait for all resources to be ready.
+	return rls, waitForJobs(config, rls, install.Timeout)
 }
 
 func newInstall(config *helmaction.Configuration, obj *v2.HelmRelease, opts []InstallOption) *helmaction.Install {
@@ -68,7 +93,11 @@ func newInstall(config *helmaction.Configuration, obj *v2.HelmRelease, opts []In
 	install.ReleaseName = release.ShortenName(obj.GetReleaseName())
 	install.Namespace = obj.GetReleaseNamespace()
 	install.Timeout = obj.GetInstall().GetTimeout(obj.GetTimeout()).Duration
-	install.Wait = !obj.GetInstall().DisableWait
+	// When a readiness threshold or grace period is configured, the health of
+	// the resources is assessed by the controller after the action has been
+	// performed.
+	install.Wait = !defaults.MustDisableWait(obj, obj.GetInstall().DisableWait) && !obj.UsesReadinessThreshold() &&
+		obj.GetInstall().ReadinessGracePeriod == nil
 	install.WaitForJobs = !obj.GetInstall().DisableWaitForJobs
 	install.DisableHooks = obj.GetInstall().DisableHooks
 	install.DisableOpenAPIValidation = obj.GetInstall().DisableOpenAPIValidation
@@ -77,10 +106,11 @@ func newInstall(config *helmaction.Configuration, obj *v2.HelmRelease, opts []In
 	install.Devel = true
 	install.SkipCRDs = true
 	install.TakeOwnership = true
+	install.Labels = ReleaseOwnerLabels(obj)
 
-	if obj.Spec.TargetNamespace != "" {
-		install.CreateNamespace = obj.GetInstall().CreateNamespace
-	}
+	// The target namespace is created by the caller using CreateNamespace,
+	// to be able to record whether it was created for the release.
+	install.CreateNamespace = false
 
 	// If the user opted-in to allow DNS lookups, enable it.
 	if allowDNS, _ := features.Enabled(features.AllowDNSLookups); allowDNS {
@@ -95,3 +95,52 @@ func Test_newInstall(t *testing.T) {
 		g.Expect(got.DryRun).To(BeTrue())
 	})
 }
+
+func Test_newInstall_timeout(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v2.HelmReleaseSpec
+		want time.Duration
+	}{
+		{
+			name: "install timeout takes precedence",
+			spec: v2.HelmReleaseSpec{
+				Timeout: &metav1.Duration{Duration: time.Minute},
+				Install: &v2.Install{
+					Timeout: &metav1.Duration{Duration: 10 * time.Second},
+				},
+			},
+			want: 10 * time.Second,
+		},
+		{
+			name: "falls back to release timeout",
+			spec: v2.HelmReleaseSpec{
+				Timeout: &metav1.Duration{Duration: time.Minute},
+				Install: &v2.Install{},
+			},
+			want: time.Minute,
+		},
+		{
+			name: "falls back to default timeout",
+			spec: v2.HelmReleaseSpec{},
+			want: 5 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "install",
+					Namespace: "install-ns",
+				},
+				Spec: tt.spec,
+			}
+
+			got := newInstall(&helmaction.Configuration{}, obj, nil)
+			g.Expect(got).ToNot(BeNil())
+			g.Expect(got.Timeout).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	helmaction "helm.sh/helm/v3/pkg/action"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/digest"
+)
+
+// Inventory returns the v2.ResourceInventory of the resources in the given
+// release manifest. The resources are resolved using the KubeClient of the
+// given configuration, which assigns the namespace of the release to
+// namespaced resources without a namespace in the manifest.
+//
+// The entries of the inventory are omitted when the manifest holds more
+// than v2.MaxInventoryEntries resources.
+func Inventory(config *helmaction.Configuration, manifest string) (*v2.ResourceInventory, error) {
+	resources, err := config.KubeClient.Build(bytes.NewBufferString(manifest), false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build resources from release manifest: %w", err)
+	}
+
+	seen := make(map[string]struct{}, len(resources))
+	entries := make([]v2.ResourceRef, 0, len(resources))
+	for _, info := range resources {
+		gvk := info.Mapping.GroupVersionKind
+		id := strings.Join([]string{info.Namespace, info.Name, gvk.Group, gvk.Kind}, "_")
+		if _, ok := seen[id]; ok {
+			continue
+		}
+		seen[id] = struct{}{}
+		entries = append(entries, v2.ResourceRef{ID: id, Version: gvk.Version})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID < entries[j].ID
+	})
+
+	ids := make([]string, 0, len(entries))
+	for _, e := range entries {
+		ids = append(ids, e.ID)
+	}
+
+	inventory := &v2.ResourceInventory{
+		Count:  len(entries),
+		Digest: digest.Canonical.FromString(strings.Join(ids, "\n")).String(),
+	}
+	if len(entries) <= v2.MaxInventoryEntries {
+		inventory.Entries = entries
+	}
+	return inventory, nil
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	helmaction "helm.sh/helm/v3/pkg/action"
+	helmkube "helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// buildingKubeClient returns a fixed set of resources for any manifest.
+type buildingKubeClient struct {
+	*kubefake.PrintingKubeClient
+
+	resources helmkube.ResourceList
+	err       error
+}
+
+func (c *buildingKubeClient) Build(_ io.Reader, _ bool) (helmkube.ResourceList, error) {
+	return c.resources, c.err
+}
+
+func inventoryResource(gvk schema.GroupVersionKind, namespace, name string) *resource.Info {
+	return &resource.Info{
+		Name:      name,
+		Namespace: namespace,
+		Mapping:   &meta.RESTMapping{GroupVersionKind: gvk},
+	}
+}
+
+func TestInventory(t *testing.T) {
+	var (
+		deployment  = schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}
+		configMap   = schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+		clusterRole = schema.GroupVersionKind{Group: "rbac.authorization.k8s.io", Version: "v1", Kind: "ClusterRole"}
+	)
+
+	newConfig := func(resources helmkube.ResourceList, err error) *helmaction.Configuration {
+		return &helmaction.Configuration{KubeClient: &buildingKubeClient{
+			PrintingKubeClient: &kubefake.PrintingKubeClient{Out: io.Discard},
+			resources:          resources,
+			err:                err,
+		}}
+	}
+
+	t.Run("lists sorted resources", func(t *testing.T) {
+		g := NewWithT(t)
+
+		got, err := Inventory(newConfig(helmkube.ResourceList{
+			inventoryResource(deployment, "default", "app"),
+			inventoryResource(configMap, "default", "app"),
+			inventoryResource(clusterRole, "", "app"),
+			inventoryResource(configMap, "default", "app"),
+		}, nil), "")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(got.Count).To(Equal(3))
+		g.Expect(got.Digest).To(HavePrefix("sha256:"))
+		g.Expect(got.Entries).To(Equal([]v2.ResourceRef{
+			{ID: "_app_rbac.authorization.k8s.io_ClusterRole", Version: "v1"},
+			{ID: "default_app__ConfigMap", Version: "v1"},
+			{ID: "default_app_apps_Deployment", Version: "v1"},
+		}))
+	})
+
+	t.Run("digest is independent of order", func(t *testing.T) {
+		g := NewWithT(t)
+
+		a, err := Inventory(newConfig(helmkube.ResourceList{
+			inventoryResource(deployment, "default", "app"),
+			inventoryResource(configMap, "default", "app"),
+		}, nil), "")
+		g.Expect(err).ToNot(HaveOccurred())
+		b, err := Inventory(newConfig(helmkube.ResourceList{
+			inventoryResource(configMap, "default", "app"),
+			inventoryResource(deployment, "default", "app"),
+		}, nil), "")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(a.Digest).To(Equal(b.Digest))
+
+		c, err := Inventory(newConfig(helmkube.ResourceList{
+			inventoryResource(configMap, "default", "app"),
+		}, nil), "")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Digest).ToNot(Equal(a.Digest))
+	})
+
+	t.Run("omits entries of large inventory", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var resources helmkube.ResourceList
+		for i := 0; i <= v2.MaxInventoryEntries; i++ {
+			resources = append(resources, inventoryResource(configMap, "default", fmt.Sprintf("cm-%d", i)))
+		}
+		got, err := Inventory(newConfig(resources, nil), "")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(got.Count).To(Equal(v2.MaxInventoryEntries + 1))
+		g.Expect(got.Digest).ToNot(BeEmpty())
+		g.Expect(got.Entries).To(BeNil())
+	})
+
+	t.Run("build error", func(t *testing.T) {
+		g := NewWithT(t)
+
+		got, err := Inventory(newConfig(nil, errors.New("no matches for kind")), "")
+		g.Expect(err).To(MatchError(ContainSubstring("no matches for kind")))
+		g.Expect(got).To(BeNil())
+	})
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	helmaction "helm.sh/helm/v3/pkg/action"
+	helmkube "helm.sh/helm/v3/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// CreateNamespace creates the target namespace of the given object, when
+// Install.CreateNamespace is enabled and the namespace does not exist yet.
+// The namespace is labeled with the ReleaseOwnerLabels of the object, and
+// with the CommonMetadata of the object when configured in the
+// Install.CreateNamespaceOptions. It returns true if the namespace was
+// created, and false if it already existed or must not be created.
+func CreateNamespace(config *helmaction.Configuration, obj *v2.HelmRelease) (bool, error) {
+	if obj.Spec.TargetNamespace == "" || !obj.GetInstall().CreateNamespace {
+		return false, nil
+	}
+
+	resources, err := buildNamespace(config, namespaceForRelease(obj))
+	if err != nil {
+		return false, err
+	}
+	if err = resources[0].Get(); err == nil {
+		return false, nil
+	} else if !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to get namespace '%s': %w", obj.Spec.TargetNamespace, err)
+	}
+
+	if _, err = config.KubeClient.Create(resources); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create namespace '%s': %w", obj.Spec.TargetNamespace, err)
+	}
+	return true, nil
+}
+
+// DeleteCreatedNamespace deletes the namespace recorded in the
+// Status.CreatedNamespace of the given object, when the
+// Install.CreateNamespaceOptions allow it to be deleted. A namespace which is
+// not labeled as owned by the object is retained, as it has been replaced by
+// a namespace which was not created by the controller. It returns true if
+// the namespace was deleted.
+func DeleteCreatedNamespace(config *helmaction.Configuration, obj *v2.HelmRelease) (bool, error) {
+	name := obj.Status.CreatedNamespace
+	if name == "" || !obj.GetInstall().CreateNamespaceOptions.MustDeleteNamespace() {
+		return false, nil
+	}
+
+	resources, err := buildNamespace(config, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	if err != nil {
+		return false, err
+	}
+	if err = resources[0].Get(); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get namespace '%s': %w", name, err)
+	}
+
+	accessor, err := meta.Accessor(resources[0].Object)
+	if err != nil {
+		return false, err
+	}
+	labels := accessor.GetLabels()
+	for k, v := range ReleaseOwnerLabels(obj) {
+		if labels[k] != v {
+			return false, nil
+		}
+	}
+
+	if _, errs := config.KubeClient.Delete(resources); len(errs) > 0 {
+		return false, fmt.Errorf("failed to delete namespace '%s': %w", name, errors.Join(errs...))
+	}
+	return true, nil
+}
+
+// namespaceForRelease returns the target namespace of the given object, with
+// the labels and annotations it is created with.
+func namespaceForRelease(obj *v2.HelmRelease) *corev1.Namespace {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   obj.Spec.TargetNamespace,
+			Labels: make(map[string]string),
+		},
+	}
+	if opts := obj.GetInstall().CreateNamespaceOptions; opts != nil && opts.CommonMetadata && obj.Spec.CommonMetadata != nil {
+		for k, v := range obj.Spec.CommonMetadata.Labels {
+			ns.Labels[k] = v
+		}
+		if len(obj.Spec.CommonMetadata.Annotations) > 0 {
+			ns.Annotations = make(map[string]string, len(obj.Spec.CommonMetadata.Annotations))
+			for k, v := range obj.Spec.CommonMetadata.Annotations {
+				ns.Annotations[k] = v
+			}
+		}
+	}
+	for k, v := range ReleaseOwnerLabels(obj) {
+		ns.Labels[k] = v
+	}
+	return ns
+}
+
+// buildNamespace returns the helmkube.ResourceList for the given namespace.
+func buildNamespace(config *helmaction.Configuration, ns *corev1.Namespace) (helmkube.ResourceList, error) {
+	ns.TypeMeta = metav1.TypeMeta{APIVersion: corev1.SchemeGroupVersion.String(), Kind: "Namespace"}
+	b, err := yaml.Marshal(ns)
+	if err != nil {
+		return nil, err
+	}
+	resources, err := config.KubeClient.Build(bytes.NewBuffer(b), true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build namespace '%s': %w", ns.Name, err)
+	}
+	if len(resources) != 1 {
+		return nil, fmt.Errorf("failed to build namespace '%s': unexpected number of resources", ns.Name)
+	}
+	return resources, nil
+}
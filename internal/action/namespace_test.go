@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func Test_namespaceForRelease(t *testing.T) {
+	newObj := func(opts *v2.CreateNamespaceOptions) *v2.HelmRelease {
+		return &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "flux-system"},
+			Spec: v2.HelmReleaseSpec{
+				TargetNamespace: "apps",
+				CommonMetadata: &v2.CommonMetadata{
+					Labels:      map[string]string{"team": "a", OwnerNameLabel: "other"},
+					Annotations: map[string]string{"owner": "team-a"},
+				},
+				Install: &v2.Install{CreateNamespace: true, CreateNamespaceOptions: opts},
+			},
+		}
+	}
+
+	t.Run("without common metadata", func(t *testing.T) {
+		g := NewWithT(t)
+
+		ns := namespaceForRelease(newObj(nil))
+		g.Expect(ns.Name).To(Equal("apps"))
+		g.Expect(ns.Labels).To(Equal(map[string]string{
+			OwnerNameLabel:      "release",
+			OwnerNamespaceLabel: "flux-system",
+		}))
+		g.Expect(ns.Annotations).To(BeNil())
+	})
+
+	t.Run("with common metadata", func(t *testing.T) {
+		g := NewWithT(t)
+
+		ns := namespaceForRelease(newObj(&v2.CreateNamespaceOptions{CommonMetadata: true}))
+		g.Expect(ns.Labels).To(Equal(map[string]string{
+			"team":              "a",
+			OwnerNameLabel:      "release",
+			OwnerNamespaceLabel: "flux-system",
+		}))
+		g.Expect(ns.Annotations).To(Equal(map[string]string{"owner": "team-a"}))
+	})
+}
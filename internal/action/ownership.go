@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+
+	helmrelease "helm.sh/helm/v3/pkg/release"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+var (
+	// OwnerNameLabel is the label on a Helm release with the name of the
+	// HelmRelease which owns it.
+	OwnerNameLabel = v2.GroupVersion.Group + "/name"
+	// OwnerNamespaceLabel is the label on a Helm release with the namespace
+	// of the HelmRelease which owns it.
+	OwnerNamespaceLabel = v2.GroupVersion.Group + "/namespace"
+)
+
+// ErrReleaseNameCollision is returned by VerifyReleaseOwner when a Helm
+// release is owned by another HelmRelease.
+var ErrReleaseNameCollision = errors.New("release name collision")
+
+// ReleaseOwnerLabels returns the labels which identify the given object as
+// the owner of its Helm release.
+func ReleaseOwnerLabels(obj *v2.HelmRelease) map[string]string {
+	return map[string]string{
+		OwnerNameLabel:      obj.GetName(),
+		OwnerNamespaceLabel: obj.GetNamespace(),
+	}
+}
+
+// VerifyReleaseOwner returns an error of type ErrReleaseNameCollision if the
+// given release is labeled as owned by a HelmRelease other than the given
+// object. A release without owner labels, e.g. because it was made by the
+// Helm CLI or before the labels were introduced, is not considered to be
+// owned by another HelmRelease.
+func VerifyReleaseOwner(obj *v2.HelmRelease, rls *helmrelease.Release) error {
+	if rls == nil {
+		return nil
+	}
+	name, namespace := rls.Labels[OwnerNameLabel], rls.Labels[OwnerNamespaceLabel]
+	if name == "" || (name == obj.GetName() && namespace == obj.GetNamespace()) {
+		return nil
+	}
+	return fmt.Errorf("%w: release %s/%s.v%d is owned by HelmRelease %s/%s",
+		ErrReleaseNameCollision, rls.Namespace, rls.Name, rls.Version, namespace, name)
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func TestVerifyReleaseOwner(t *testing.T) {
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "app",
+			Namespace: "team-a",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		labels  map[string]string
+		wantErr string
+	}{
+		{
+			name:   "owned by object",
+			labels: ReleaseOwnerLabels(obj),
+		},
+		{
+			name: "without owner labels",
+		},
+		{
+			name:   "with other labels",
+			labels: map[string]string{"team": "a"},
+		},
+		{
+			name: "owned by object in other namespace",
+			labels: map[string]string{
+				OwnerNameLabel:      "app",
+				OwnerNamespaceLabel: "team-b",
+			},
+			wantErr: "release name collision: release default/app.v1 is owned by HelmRelease team-b/app",
+		},
+		{
+			name: "owned by other object",
+			labels: map[string]string{
+				OwnerNameLabel:      "other",
+				OwnerNamespaceLabel: "team-a",
+			},
+			wantErr: "release name collision: release default/app.v1 is owned by HelmRelease team-a/other",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			rls := &helmrelease.Release{Name: "app", Namespace: "default", Version: 1, Labels: tt.labels}
+			err := VerifyReleaseOwner(obj, rls)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ErrReleaseNameCollision))
+				g.Expect(err.Error()).To(Equal(tt.wantErr))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
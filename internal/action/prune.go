@@ -0,0 +1,199 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	helmaction "helm.sh/helm/v3/pkg/action"
+	helmkube "helm.sh/helm/v3/pkg/kube"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// Orphans returns the entries of the previous inventory which are absent from
+// the current inventory. It returns an error if the entries of either
+// inventory have been omitted, as the orphans can then not be determined.
+func Orphans(previous, current *v2.ResourceInventory) ([]v2.ResourceRef, error) {
+	if previous == nil || len(previous.Entries) == 0 {
+		return nil, nil
+	}
+	if current == nil || current.Count != len(current.Entries) || previous.Count != len(previous.Entries) {
+		return nil, fmt.Errorf("inventory exceeds %d entries", v2.MaxInventoryEntries)
+	}
+
+	keep := make(map[string]struct{}, len(current.Entries))
+	for _, e := range current.Entries {
+		keep[e.ID] = struct{}{}
+	}
+	var orphans []v2.ResourceRef
+	for _, e := range previous.Entries {
+		if _, ok := keep[e.ID]; !ok {
+			orphans = append(orphans, e)
+		}
+	}
+	return orphans, nil
+}
+
+// PruneOrphans deletes the resources of the previous inventory which are
+// absent from the current inventory. Resources which no longer exist are
+// ignored, and resources annotated with v2.PruneAnnotation set to
+// v2.PruneDisabledValue, or with the Helm resource policy to keep them, are
+// skipped. Resources of which the Helm ownership annotations do not refer to
+// the release recorded in the previous inventory, or the release with the
+// given name and namespace if none is recorded, are not deleted either. For
+// example, because they have been adopted by another release.
+// It returns the resources which were deleted, the resources which were not
+// deleted because they are owned by another release, and any errors which
+// occurred.
+func PruneOrphans(config *helmaction.Configuration, releaseName, releaseNamespace string,
+	previous, current *v2.ResourceInventory) (pruned, disowned []v2.ResourceRef, err error) {
+	orphans, err := Orphans(previous, current)
+	if err != nil || len(orphans) == 0 {
+		return nil, nil, err
+	}
+	releaseName, releaseNamespace = inventoryRelease(previous, releaseName, releaseNamespace)
+
+	var errs []error
+	for _, ref := range orphans {
+		result, err := pruneResource(config, releaseName, releaseNamespace, ref)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to prune %s: %w", ref.ID, err))
+			continue
+		}
+		switch result {
+		case pruneDeleted:
+			pruned = append(pruned, ref)
+		case pruneDisowned:
+			disowned = append(disowned, ref)
+		}
+	}
+	return pruned, disowned, errors.Join(errs...)
+}
+
+// pruneResult is the result of pruning a single resource.
+type pruneResult int
+
+const (
+	// pruneSkipped indicates the resource no longer exists, or is annotated
+	// to be skipped.
+	pruneSkipped pruneResult = iota
+	// pruneDeleted indicates the resource was deleted.
+	pruneDeleted
+	// pruneDisowned indicates the resource was not deleted, as it is not
+	// owned by the release.
+	pruneDisowned
+)
+
+// pruneResource deletes the resource referenced by the given ResourceRef,
+// unless it no longer exists, is annotated to be skipped, or is not owned by
+// the release with the given name and namespace.
+func pruneResource(config *helmaction.Configuration, releaseName, releaseNamespace string, ref v2.ResourceRef) (pruneResult, error) {
+	gvk, namespace, name, err := parseResourceRef(ref)
+	if err != nil {
+		return pruneSkipped, err
+	}
+
+	manifest := fmt.Sprintf("apiVersion: %s\nkind: %s\nmetadata:\n  name: %s\n", gvk.GroupVersion().String(), gvk.Kind, name)
+	if namespace != "" {
+		manifest += fmt.Sprintf("  namespace: %s\n", namespace)
+	}
+	resources, err := config.KubeClient.Build(strings.NewReader(manifest), false)
+	if err != nil {
+		return pruneSkipped, err
+	}
+
+	var (
+		targets  helmkube.ResourceList
+		disowned bool
+	)
+	for _, info := range resources {
+		if err = info.Get(); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return pruneSkipped, err
+		}
+		accessor, err := meta.Accessor(info.Object)
+		if err != nil {
+			return pruneSkipped, err
+		}
+		annotations := accessor.GetAnnotations()
+		if skipPrune(annotations) {
+			continue
+		}
+		if !ownedByRelease(annotations, releaseName, releaseNamespace) {
+			disowned = true
+			continue
+		}
+		targets = append(targets, info)
+	}
+	if len(targets) == 0 {
+		if disowned {
+			return pruneDisowned, nil
+		}
+		return pruneSkipped, nil
+	}
+
+	if _, errs := config.KubeClient.Delete(targets); len(errs) > 0 {
+		return pruneSkipped, errors.Join(errs...)
+	}
+	return pruneDeleted, nil
+}
+
+// inventoryRelease returns the name and namespace of the release recorded in
+// the given inventory, or the given name and namespace if none is recorded.
+func inventoryRelease(inventory *v2.ResourceInventory, name, namespace string) (string, string) {
+	if inventory == nil {
+		return name, namespace
+	}
+	ns, n, ok := strings.Cut(inventory.Release, "/")
+	if !ok || n == "" {
+		return name, namespace
+	}
+	return n, ns
+}
+
+// ownedByRelease returns true if the given annotations of a resource mark it
+// as owned by the Helm release with the given name and namespace.
+func ownedByRelease(annotations map[string]string, releaseName, releaseNamespace string) bool {
+	return annotations[helmReleaseNameAnnotation] == releaseName &&
+		annotations[helmReleaseNamespaceAnnotation] == releaseNamespace
+}
+
+// skipPrune returns true if the given annotations of a resource exclude it
+// from being pruned.
+func skipPrune(annotations map[string]string) bool {
+	return annotations[v2.PruneAnnotation] == v2.PruneDisabledValue ||
+		annotations[helmkube.ResourcePolicyAnno] == helmkube.KeepPolicy
+}
+
+// parseResourceRef returns the GroupVersionKind, namespace and name of the
+// resource referenced by the given ResourceRef.
+func parseResourceRef(ref v2.ResourceRef) (schema.GroupVersionKind, string, string, error) {
+	parts := strings.Split(ref.ID, "_")
+	if len(parts) != 4 || parts[1] == "" || parts[3] == "" {
+		return schema.GroupVersionKind{}, "", "", fmt.Errorf("invalid resource ID '%s'", ref.ID)
+	}
+	gvk := schema.GroupVersionKind{Group: parts[2], Version: ref.Version, Kind: parts[3]}
+	return gvk, parts[0], parts[1], nil
+}
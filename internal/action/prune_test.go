@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	helmkube "helm.sh/helm/v3/pkg/kube"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func TestOrphans(t *testing.T) {
+	var (
+		cm     = v2.ResourceRef{ID: "default_app__ConfigMap", Version: "v1"}
+		deploy = v2.ResourceRef{ID: "default_app_apps_Deployment", Version: "v1"}
+		role   = v2.ResourceRef{ID: "_app_rbac.authorization.k8s.io_ClusterRole", Version: "v1"}
+	)
+	inventory := func(entries ...v2.ResourceRef) *v2.ResourceInventory {
+		return &v2.ResourceInventory{Count: len(entries), Entries: entries}
+	}
+
+	tests := []struct {
+		name     string
+		previous *v2.ResourceInventory
+		current  *v2.ResourceInventory
+		want     []v2.ResourceRef
+		wantErr  bool
+	}{
+		{
+			name:    "no previous inventory",
+			current: inventory(cm),
+		},
+		{
+			name:     "no orphans",
+			previous: inventory(cm, deploy),
+			current:  inventory(cm, deploy, role),
+		},
+		{
+			name:     "orphans",
+			previous: inventory(role, cm, deploy),
+			current:  inventory(cm),
+			want:     []v2.ResourceRef{role, deploy},
+		},
+		{
+			name:     "changed API version is not an orphan",
+			previous: inventory(v2.ResourceRef{ID: deploy.ID, Version: "v1beta1"}),
+			current:  inventory(deploy),
+		},
+		{
+			name:     "current entries omitted",
+			previous: inventory(cm),
+			current:  &v2.ResourceInventory{Count: v2.MaxInventoryEntries + 1},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := Orphans(tt.previous, tt.current)
+			g.Expect(err != nil).To(Equal(tt.wantErr))
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_parseResourceRef(t *testing.T) {
+	g := NewWithT(t)
+
+	gvk, ns, name, err := parseResourceRef(v2.ResourceRef{ID: "default_app_apps_Deployment", Version: "v1"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(gvk).To(Equal(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}))
+	g.Expect(ns).To(Equal("default"))
+	g.Expect(name).To(Equal("app"))
+
+	gvk, ns, _, err = parseResourceRef(v2.ResourceRef{ID: "_app__Namespace", Version: "v1"})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(gvk.GroupVersion().String()).To(Equal("v1"))
+	g.Expect(ns).To(BeEmpty())
+
+	_, _, _, err = parseResourceRef(v2.ResourceRef{ID: "invalid", Version: "v1"})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func Test_skipPrune(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(skipPrune(nil)).To(BeFalse())
+	g.Expect(skipPrune(map[string]string{v2.PruneAnnotation: "enabled"})).To(BeFalse())
+	g.Expect(skipPrune(map[string]string{v2.PruneAnnotation: v2.PruneDisabledValue})).To(BeTrue())
+	g.Expect(skipPrune(map[string]string{helmkube.ResourcePolicyAnno: helmkube.KeepPolicy})).To(BeTrue())
+}
+
+func Test_ownedByRelease(t *testing.T) {
+	g := NewWithT(t)
+
+	owner := map[string]string{
+		helmReleaseNameAnnotation:      "app",
+		helmReleaseNamespaceAnnotation: "default",
+	}
+	g.Expect(ownedByRelease(owner, "app", "default")).To(BeTrue())
+	g.Expect(ownedByRelease(owner, "other", "default")).To(BeFalse())
+	g.Expect(ownedByRelease(owner, "app", "other")).To(BeFalse())
+	g.Expect(ownedByRelease(nil, "app", "default")).To(BeFalse())
+}
+
+func Test_inventoryRelease(t *testing.T) {
+	g := NewWithT(t)
+
+	name, ns := inventoryRelease(nil, "app", "default")
+	g.Expect(name).To(Equal("app"))
+	g.Expect(ns).To(Equal("default"))
+
+	name, ns = inventoryRelease(&v2.ResourceInventory{}, "app", "default")
+	g.Expect(name).To(Equal("app"))
+	g.Expect(ns).To(Equal("default"))
+
+	name, ns = inventoryRelease(&v2.ResourceInventory{Release: "other/old"}, "app", "default")
+	g.Expect(name).To(Equal("old"))
+	g.Expect(ns).To(Equal("other"))
+
+	name, ns = inventoryRelease(&v2.ResourceInventory{Release: "invalid"}, "app", "default")
+	g.Expect(name).To(Equal("app"))
+	g.Expect(ns).To(Equal("default"))
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	helmaction "helm.sh/helm/v3/pkg/action"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	ssautil "github.com/fluxcd/pkg/ssa/utils"
+)
+
+// ErrResourceQuotaExceeded is returned by CheckResourceQuota when the
+// estimated resource usage would exceed a ResourceQuota.
+var ErrResourceQuotaExceeded = errors.New("resource quota exceeded")
+
+// EstimateResourceUsage estimates the compute resources requested by the
+// workloads in the given manifest which target the given namespace, in terms
+// of the resource names used by a ResourceQuota.
+//
+// The estimate is approximate: it takes the replica count of workloads into
+// account, but counts a DaemonSet as a single replica, and ignores CronJobs
+// and resources which are defaulted by e.g. a LimitRange.
+func EstimateResourceUsage(manifest, namespace string) (corev1.ResourceList, error) {
+	objects, err := ssautil.ReadObjects(strings.NewReader(manifest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read objects from release manifest: %w", err)
+	}
+
+	usage := corev1.ResourceList{}
+	for _, obj := range objects {
+		if ns := obj.GetNamespace(); ns != "" && ns != namespace {
+			continue
+		}
+		spec, replicas, err := podSpecOf(obj)
+		if err != nil {
+			return nil, err
+		}
+		if spec == nil || replicas == 0 {
+			continue
+		}
+		for name, q := range podUsage(spec) {
+			q.Mul(replicas)
+			addQuantity(usage, name, q)
+		}
+	}
+	return usage, nil
+}
+
+// CheckResourceQuota checks the desired resource usage against the
+// ResourceQuotas in the given namespace. The current usage, if any, is
+// subtracted from the desired usage as it is already accounted for by the
+// ResourceQuota. It returns an error wrapping ErrResourceQuotaExceeded
+// describing the violations, if any.
+func CheckResourceQuota(ctx context.Context, config *helmaction.Configuration, namespace string,
+	desired, current corev1.ResourceList) error {
+	cfg, err := config.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return err
+	}
+	c, err := client.New(cfg, client.Options{})
+	if err != nil {
+		return err
+	}
+
+	quotas := &corev1.ResourceQuotaList{}
+	if err = c.List(ctx, quotas, client.InNamespace(namespace)); err != nil {
+		return fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+
+	if violations := exceedsQuota(quotas.Items, resourceDelta(desired, current)); len(violations) > 0 {
+		return fmt.Errorf("%w: %s", ErrResourceQuotaExceeded, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// exceedsQuota returns a description of each resource of which the usage
+// increase would exceed the hard limit of any of the given quotas.
+func exceedsQuota(quotas []corev1.ResourceQuota, increase corev1.ResourceList) []string {
+	var violations []string
+	for _, quota := range quotas {
+		names := make([]string, 0, len(quota.Status.Hard))
+		for name := range quota.Status.Hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+
+		for _, n := range names {
+			name := corev1.ResourceName(n)
+			requested, ok := increase[quotaResourceName(name)]
+			if !ok || requested.Sign() <= 0 {
+				continue
+			}
+			hard := quota.Status.Hard[name]
+			used := quota.Status.Used[name]
+			total := used.DeepCopy()
+			total.Add(requested)
+			if total.Cmp(hard) > 0 {
+				violations = append(violations, fmt.Sprintf("exceeded quota '%s': requested %s=%s, used %s, limited %s",
+					quota.Name, name, requested.String(), used.String(), hard.String()))
+			}
+		}
+	}
+	return violations
+}
+
+// quotaResourceName returns the name under which EstimateResourceUsage
+// records the given ResourceQuota resource name.
+func quotaResourceName(name corev1.ResourceName) corev1.ResourceName {
+	switch name {
+	case corev1.ResourceCPU:
+		return corev1.ResourceRequestsCPU
+	case corev1.ResourceMemory:
+		return corev1.ResourceRequestsMemory
+	default:
+		return name
+	}
+}
+
+// resourceDelta returns the desired resources minus the current resources.
+func resourceDelta(desired, current corev1.ResourceList) corev1.ResourceList {
+	delta := desired.DeepCopy()
+	for name, q := range current {
+		d := delta[name]
+		d.Sub(q)
+		delta[name] = d
+	}
+	return delta
+}
+
+// podSpecOf returns the pod spec of the given workload object, and the
+// number of replicas it is expected to run. It returns nil for objects which
+// are not (immediately) running pods.
+func podSpecOf(obj *unstructured.Unstructured) (*corev1.PodSpec, int64, error) {
+	var (
+		path     []string
+		replicas = int64(1)
+	)
+	switch obj.GetKind() {
+	case "Pod":
+		path = []string{"spec"}
+	case "Deployment", "ReplicaSet", "StatefulSet", "ReplicationController":
+		path = []string{"spec", "template", "spec"}
+		if r, ok, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); ok {
+			replicas = r
+		}
+	case "DaemonSet":
+		path = []string{"spec", "template", "spec"}
+	case "Job":
+		path = []string{"spec", "template", "spec"}
+		if p, ok, _ := unstructured.NestedInt64(obj.Object, "spec", "parallelism"); ok {
+			replicas = p
+		}
+	default:
+		return nil, 0, nil
+	}
+
+	m, ok, err := unstructured.NestedMap(obj.Object, path...)
+	if err != nil || !ok {
+		return nil, 0, err
+	}
+	spec := &corev1.PodSpec{}
+	if err = runtime.DefaultUnstructuredConverter.FromUnstructured(m, spec); err != nil {
+		return nil, 0, fmt.Errorf("failed to convert pod spec of %s/%s: %w", obj.GetKind(), obj.GetName(), err)
+	}
+	return spec, replicas, nil
+}
+
+// podUsage returns the resource usage of a single pod with the given spec,
+// taking the highest request of the init containers into account.
+func podUsage(spec *corev1.PodSpec) corev1.ResourceList {
+	usage := corev1.ResourceList{
+		corev1.ResourcePods: *resource.NewQuantity(1, resource.DecimalSI),
+	}
+	for _, c := range spec.Containers {
+		addContainerUsage(usage, c.Resources)
+	}
+	for _, c := range spec.InitContainers {
+		init := corev1.ResourceList{}
+		addContainerUsage(init, c.Resources)
+		for name, q := range init {
+			if cur, ok := usage[name]; !ok || q.Cmp(cur) > 0 {
+				usage[name] = q
+			}
+		}
+	}
+	return usage
+}
+
+// addContainerUsage adds the requests and limits of a container to the given
+// usage.
+func addContainerUsage(usage corev1.ResourceList, r corev1.ResourceRequirements) {
+	for name, q := range r.Requests {
+		addQuantity(usage, corev1.ResourceName(corev1.DefaultResourceRequestsPrefix+string(name)), q)
+	}
+	for name, q := range r.Limits {
+		addQuantity(usage, corev1.ResourceName("limits."+string(name)), q)
+	}
+}
+
+// addQuantity adds the quantity to the named resource in the given list.
+func addQuantity(list corev1.ResourceList, name corev1.ResourceName, q resource.Quantity) {
+	cur := list[name]
+	cur.Add(q)
+	list[name] = cur
+}
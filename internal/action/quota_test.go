@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestEstimateResourceUsage(t *testing.T) {
+	g := NewWithT(t)
+
+	manifest := `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+  template:
+    spec:
+      initContainers:
+      - name: init
+        resources:
+          requests:
+            memory: 512Mi
+      containers:
+      - name: web
+        resources:
+          requests:
+            cpu: 100m
+            memory: 128Mi
+          limits:
+            cpu: 200m
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  schedule: "@daily"
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: backup
+            resources:
+              requests:
+                cpu: "4"
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: other
+  namespace: other
+spec:
+  containers:
+  - name: other
+    resources:
+      requests:
+        cpu: "1"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`
+
+	usage, err := EstimateResourceUsage(manifest, "default")
+	g.Expect(err).ToNot(HaveOccurred())
+
+	expect := func(name corev1.ResourceName, want string) {
+		q := usage[name]
+		g.Expect(q.Cmp(resource.MustParse(want))).To(Equal(0), "%s: got %s, want %s", name, q.String(), want)
+	}
+	expect(corev1.ResourcePods, "3")
+	expect(corev1.ResourceRequestsCPU, "300m")
+	expect(corev1.ResourceRequestsMemory, "1536Mi")
+	expect(corev1.ResourceLimitsCPU, "600m")
+}
+
+func Test_exceedsQuota(t *testing.T) {
+	quota := corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("2"),
+				corev1.ResourcePods: resource.MustParse("10"),
+			},
+			Used: corev1.ResourceList{
+				corev1.ResourceCPU:  resource.MustParse("1500m"),
+				corev1.ResourcePods: resource.MustParse("4"),
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		desired  corev1.ResourceList
+		current  corev1.ResourceList
+		wantMsgs []string
+	}{
+		{
+			name: "within quota",
+			desired: corev1.ResourceList{
+				corev1.ResourceRequestsCPU: resource.MustParse("500m"),
+				corev1.ResourcePods:        resource.MustParse("2"),
+			},
+		},
+		{
+			name: "exceeds quota",
+			desired: corev1.ResourceList{
+				corev1.ResourceRequestsCPU: resource.MustParse("1"),
+				corev1.ResourcePods:        resource.MustParse("2"),
+			},
+			wantMsgs: []string{"exceeded quota 'compute': requested cpu=1, used 1500m, limited 2"},
+		},
+		{
+			name: "current usage is accounted for",
+			desired: corev1.ResourceList{
+				corev1.ResourceRequestsCPU: resource.MustParse("1"),
+			},
+			current: corev1.ResourceList{
+				corev1.ResourceRequestsCPU: resource.MustParse("800m"),
+			},
+		},
+		{
+			name: "decrease in usage",
+			desired: corev1.ResourceList{
+				corev1.ResourcePods: resource.MustParse("1"),
+			},
+			current: corev1.ResourceList{
+				corev1.ResourcePods: resource.MustParse("8"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got := exceedsQuota([]corev1.ResourceQuota{quota}, resourceDelta(tt.desired, tt.current))
+			g.Expect(got).To(Equal(tt.wantMsgs))
+		})
+	}
+}
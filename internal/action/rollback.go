@@ -20,6 +20,7 @@ import (
 	helmaction "helm.sh/helm/v3/pkg/action"
 
 	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/defaults"
 )
 
 // RollbackOption can be used to modify Helm's action.Rollback after the
@@ -59,7 +60,7 @@ func newRollback(config *helmaction.Configuration, obj *v2.HelmRelease, opts []R
 	rollback := helmaction.NewRollback(config)
 
 	rollback.Timeout = obj.GetRollback().GetTimeout(obj.GetTimeout()).Duration
-	rollback.Wait = !obj.GetRollback().DisableWait
+	rollback.Wait = !defaults.MustDisableWait(obj, obj.GetRollback().DisableWait)
 	rollback.WaitForJobs = !obj.GetRollback().DisableWaitForJobs
 	rollback.DisableHooks = obj.GetRollback().DisableHooks
 	rollback.Force = obj.GetRollback().Force
@@ -109,3 +109,52 @@ func Test_newRollback(t *testing.T) {
 		g.Expect(got.DryRun).To(BeTrue())
 	})
 }
+
+func Test_newRollback_timeout(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v2.HelmReleaseSpec
+		want time.Duration
+	}{
+		{
+			name: "rollback timeout takes precedence",
+			spec: v2.HelmReleaseSpec{
+				Timeout: &metav1.Duration{Duration: time.Minute},
+				Rollback: &v2.Rollback{
+					Timeout: &metav1.Duration{Duration: 10 * time.Second},
+				},
+			},
+			want: 10 * time.Second,
+		},
+		{
+			name: "falls back to release timeout",
+			spec: v2.HelmReleaseSpec{
+				Timeout:  &metav1.Duration{Duration: time.Minute},
+				Rollback: &v2.Rollback{},
+			},
+			want: time.Minute,
+		},
+		{
+			name: "falls back to default timeout",
+			spec: v2.HelmReleaseSpec{},
+			want: 5 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "rollback",
+					Namespace: "rollback-ns",
+				},
+				Spec: tt.spec,
+			}
+
+			got := newRollback(&helmaction.Configuration{}, obj, nil)
+			g.Expect(got).ToNot(BeNil())
+			g.Expect(got.Timeout).To(Equal(tt.want))
+		})
+	}
+}
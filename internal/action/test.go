@@ -18,11 +18,14 @@ package action
 
 import (
 	"context"
+	"fmt"
+	"time"
 
 	helmaction "helm.sh/helm/v3/pkg/action"
 	helmrelease "helm.sh/helm/v3/pkg/release"
 
 	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/postrender"
 )
 
 // TestOption can be used to modify Helm's action.ReleaseTesting after the
@@ -30,6 +33,16 @@ import (
 // example useful to enable the dry-run setting as a CLI.
 type TestOption func(action *helmaction.ReleaseTesting)
 
+// WithTestTimeout sets the timeout of the Helm test action to the given
+// duration, if it is shorter than the configured timeout.
+func WithTestTimeout(timeout time.Duration) TestOption {
+	return func(action *helmaction.ReleaseTesting) {
+		if timeout < action.Timeout {
+			action.Timeout = timeout
+		}
+	}
+}
+
 // Test runs the Helm test action with the provided config, using the
 // v2.HelmReleaseSpec of the given object to determine the target release
 // and test configuration.
@@ -38,11 +51,58 @@ type TestOption func(action *helmaction.ReleaseTesting)
 // expected to be done by the caller. In addition, it does not take note of the
 // action result. The caller is expected to listen to this using a
 // storage.ObserveFunc, which provides superior access to Helm storage writes.
-func Test(_ context.Context, config *helmaction.Configuration, obj *v2.HelmRelease, opts ...TestOption) (*helmrelease.Release, error) {
+//
+// When the test hooks are excluded from the release manifest, they are
+// rendered from the chart and values of the latest release, and added to the
+// release before the tests are run.
+func Test(ctx context.Context, config *helmaction.Configuration, obj *v2.HelmRelease, opts ...TestOption) (*helmrelease.Release, error) {
+	if obj.GetTest().ExcludeFromManifest {
+		if err := injectTestHooks(ctx, config, obj); err != nil {
+			return nil, err
+		}
+	}
+
 	test := newTest(config, obj, opts)
 	return test.Run(obj.GetReleaseName())
 }
 
+// injectTestHooks renders the test hooks of the latest release of the given
+// object using the chart and values stored in the release, and configures
+// the storage of the given config to add them to the release when it is
+// read by the Helm test action.
+func injectTestHooks(ctx context.Context, config *helmaction.Configuration, obj *v2.HelmRelease) error {
+	rls, err := config.Releases.Last(obj.GetReleaseName())
+	if err != nil {
+		return err
+	}
+
+	// Render using a copy of the config, as Helm replaces the storage and
+	// client of the config it renders with.
+	renderConfig := *config
+	rendered, err := RenderRelease(ctx, &renderConfig, obj, rls.Chart, rls.Config)
+	if err != nil {
+		return fmt.Errorf("failed to render test hooks: %w", err)
+	}
+
+	var hooks []*helmrelease.Hook
+	for _, h := range rendered.Hooks {
+		if !isTestHook(h) {
+			continue
+		}
+		// Set the common metadata, as done for the hooks of an install or
+		// upgrade.
+		if obj.Spec.CommonMetadata != nil {
+			if h.Manifest, err = postrender.ApplyCommonMetadata(h.Manifest, obj.Spec.CommonMetadata); err != nil {
+				return fmt.Errorf("failed to set common metadata on hook %s: %w", h.Name, err)
+			}
+		}
+		hooks = append(hooks, h)
+	}
+
+	config.Releases.Driver = newTestHookInjectDriver(config.Releases.Driver, rls.Name, rls.Version, hooks)
+	return nil
+}
+
 func newTest(config *helmaction.Configuration, obj *v2.HelmRelease, opts []TestOption) *helmaction.ReleaseTesting {
 	test := helmaction.NewReleaseTesting(config)
 
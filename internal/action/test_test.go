@@ -106,3 +106,52 @@ func Test_newTest(t *testing.T) {
 		g.Expect(got.Filters).To(HaveLen(2))
 	})
 }
+
+func Test_newTest_timeout(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v2.HelmReleaseSpec
+		want time.Duration
+	}{
+		{
+			name: "test timeout takes precedence",
+			spec: v2.HelmReleaseSpec{
+				Timeout: &metav1.Duration{Duration: time.Minute},
+				Test: &v2.Test{
+					Timeout: &metav1.Duration{Duration: 10 * time.Second},
+				},
+			},
+			want: 10 * time.Second,
+		},
+		{
+			name: "falls back to release timeout",
+			spec: v2.HelmReleaseSpec{
+				Timeout: &metav1.Duration{Duration: time.Minute},
+				Test:    &v2.Test{},
+			},
+			want: time.Minute,
+		},
+		{
+			name: "falls back to default timeout",
+			spec: v2.HelmReleaseSpec{},
+			want: 5 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test",
+					Namespace: "test-ns",
+				},
+				Spec: tt.spec,
+			}
+
+			got := newTest(&helmaction.Configuration{}, obj, nil)
+			g.Expect(got).ToNot(BeNil())
+			g.Expect(got.Timeout).To(Equal(tt.want))
+		})
+	}
+}
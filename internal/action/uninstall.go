@@ -18,11 +18,15 @@ package action
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	helmaction "helm.sh/helm/v3/pkg/action"
 	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
 
 	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/defaults"
 )
 
 // UninstallOption can be used to modify Helm's action.Uninstall after the
@@ -30,6 +34,14 @@ import (
 // example useful to enable the dry-run setting as a CLI.
 type UninstallOption func(cfg *helmaction.Uninstall)
 
+// WithoutUninstallHistory purges the release history from the Helm storage
+// on uninstall, independent of the KeepHistory setting of the v2.HelmRelease.
+func WithoutUninstallHistory() UninstallOption {
+	return func(action *helmaction.Uninstall) {
+		action.KeepHistory = false
+	}
+}
+
 // Uninstall runs the Helm uninstall action with the provided config, using the
 // v2.HelmReleaseSpec of the given object to determine the target release
 // and uninstall configuration.
@@ -49,7 +61,7 @@ func newUninstall(config *helmaction.Configuration, obj *v2.HelmRelease, opts []
 	uninstall.Timeout = obj.GetUninstall().GetTimeout(obj.GetTimeout()).Duration
 	uninstall.DisableHooks = obj.GetUninstall().DisableHooks
 	uninstall.KeepHistory = obj.GetUninstall().KeepHistory
-	uninstall.Wait = !obj.GetUninstall().DisableWait
+	uninstall.Wait = !defaults.MustDisableWait(obj, obj.GetUninstall().DisableWait)
 	uninstall.DeletionPropagation = obj.GetUninstall().GetDeletionPropagation()
 
 	for _, opt := range opts {
@@ -58,3 +70,24 @@ func newUninstall(config *helmaction.Configuration, obj *v2.HelmRelease, opts []
 
 	return uninstall
 }
+
+// ForgetRelease removes all versions of the release with the given name from
+// the Helm storage of the provided config, without deleting the resources of
+// the release from the cluster. It can be used to hand over the resources to
+// another release, which takes ownership of them on install.
+// It does not return an error if the release does not exist.
+func ForgetRelease(config *helmaction.Configuration, releaseName string) error {
+	releases, err := config.Releases.History(releaseName)
+	if err != nil {
+		if errors.Is(err, helmdriver.ErrReleaseNotFound) {
+			return nil
+		}
+		return fmt.Errorf("failed to get release history: %w", err)
+	}
+	for _, rls := range releases {
+		if _, err = config.Releases.Delete(rls.Name, rls.Version); err != nil && !errors.Is(err, helmdriver.ErrReleaseNotFound) {
+			return fmt.Errorf("failed to delete release %s/%s.v%d: %w", rls.Namespace, rls.Name, rls.Version, err)
+		}
+	}
+	return nil
+}
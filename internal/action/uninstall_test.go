@@ -22,9 +22,13 @@ import (
 
 	. "github.com/onsi/gomega"
 	helmaction "helm.sh/helm/v3/pkg/action"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmstorage "helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/testutil"
 )
 
 func Test_newUninstall(t *testing.T) {
@@ -93,3 +97,74 @@ func Test_newUninstall(t *testing.T) {
 		g.Expect(got.DisableHooks).To(BeTrue())
 	})
 }
+
+func Test_newUninstall_timeout(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v2.HelmReleaseSpec
+		want time.Duration
+	}{
+		{
+			name: "uninstall timeout takes precedence",
+			spec: v2.HelmReleaseSpec{
+				Timeout: &metav1.Duration{Duration: time.Minute},
+				Uninstall: &v2.Uninstall{
+					Timeout: &metav1.Duration{Duration: 10 * time.Second},
+				},
+			},
+			want: 10 * time.Second,
+		},
+		{
+			name: "falls back to release timeout",
+			spec: v2.HelmReleaseSpec{
+				Timeout:   &metav1.Duration{Duration: time.Minute},
+				Uninstall: &v2.Uninstall{},
+			},
+			want: time.Minute,
+		},
+		{
+			name: "falls back to default timeout",
+			spec: v2.HelmReleaseSpec{},
+			want: 5 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "uninstall",
+					Namespace: "uninstall-ns",
+				},
+				Spec: tt.spec,
+			}
+
+			got := newUninstall(&helmaction.Configuration{}, obj, nil)
+			g.Expect(got).ToNot(BeNil())
+			g.Expect(got.Timeout).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestForgetRelease(t *testing.T) {
+	g := NewWithT(t)
+
+	s := helmstorage.Init(driver.NewMemory())
+	for _, opts := range []*helmrelease.MockReleaseOptions{
+		{Name: "release", Version: 1, Status: helmrelease.StatusSuperseded, Namespace: "default"},
+		{Name: "release", Version: 2, Status: helmrelease.StatusDeployed, Namespace: "default"},
+		{Name: "other", Version: 1, Status: helmrelease.StatusDeployed, Namespace: "default"},
+	} {
+		g.Expect(s.Create(testutil.BuildRelease(opts))).To(Succeed())
+	}
+	config := &helmaction.Configuration{Releases: s}
+
+	g.Expect(ForgetRelease(config, "release")).To(Succeed())
+	_, err := s.History("release")
+	g.Expect(err).To(MatchError(driver.ErrReleaseNotFound))
+	g.Expect(s.History("other")).To(HaveLen(1))
+
+	// Forgetting a release which does not exist is a no-op.
+	g.Expect(ForgetRelease(config, "release")).To(Succeed())
+}
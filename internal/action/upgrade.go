@@ -26,6 +26,7 @@ import (
 	helmrelease "helm.sh/helm/v3/pkg/release"
 
 	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/defaults"
 	"github.com/fluxcd/helm-controller/internal/features"
 	"github.com/fluxcd/helm-controller/internal/postrender"
 	"github.com/fluxcd/helm-controller/internal/release"
@@ -49,6 +50,10 @@ type UpgradeOption func(upgrade *helmaction.Upgrade)
 // storage.ObserveFunc, which provides superior access to Helm storage writes.
 func Upgrade(ctx context.Context, config *helmaction.Configuration, obj *v2.HelmRelease, chrt *helmchart.Chart,
 	vals helmchartutil.Values, opts ...UpgradeOption) (*helmrelease.Release, error) {
+	if err := obj.GetUpgrade().ValidateValuesOptions(); err != nil {
+		return nil, err
+	}
+
 	upgrade := newUpgrade(config, obj, opts)
 
 	policy, err := crdPolicyOrDefault(obj.GetUpgrade().CRDs)
@@ -59,25 +64,60 @@ func Upgrade(ctx context.Context, config *helmaction.Configuration, obj *v2.Helm
 		return nil, fmt.Errorf("failed to apply CustomResourceDefinitions: %w", err)
 	}
 
-	return upgrade.RunWithContext(ctx, release.ShortenName(obj.GetReleaseName()), chrt, vals.AsMap())
+	// Apply the resources of the release in batches, after the CRDs have
+	// been applied in full.
+	if size := obj.GetUpgrade().ApplyBatchSize; size > 0 {
+		config.KubeClient = newBatchKubeClient(ctx, config.KubeClient, size, upgrade.Timeout, config.Log)
+	}
+
+	// Skip the disabled hooks, unless all hooks are disabled.
+	if hooks := obj.GetUpgrade().DisabledHooks; len(hooks) > 0 && !upgrade.DisableHooks {
+		config.Releases.Driver = newHookFilterDriver(config.Releases.Driver, hooks)
+	}
+
+	// Remove the test hooks, which are rendered again when the Helm test
+	// action runs.
+	if obj.GetTest().ExcludeFromManifest {
+		config.Releases.Driver = newTestHookExcludeDriver(config.Releases.Driver)
+	}
+
+	// Set the common metadata on the hooks, which Helm does not pass through
+	// the post renderers.
+	if obj.Spec.CommonMetadata != nil && !upgrade.DisableHooks {
+		config.Releases.Driver = newHookMetadataDriver(config.Releases.Driver, obj.Spec.CommonMetadata)
+	}
+
+	rls, err := upgrade.RunWithContext(ctx, release.ShortenName(obj.GetReleaseName()), chrt, vals.AsMap())
+	if err != nil || upgrade.DryRun || !mustWaitForJobsOnly(upgrade.Wait, upgrade.WaitForJobs, obj.GetUpgrade().WaitForJobs) {
+		return rls, err
+	}
+
+	// Wait for the Jobs of the release to complete, while Helm did not
+	// wait for all resources to be ready.
+	return rls, waitForJobs(config, rls, upgrade.Timeout)
 }
 
 func newUpgrade(config *helmaction.Configuration, obj *v2.HelmRelease, opts []UpgradeOption) *helmaction.Upgrade {
 	upgrade := helmaction.NewUpgrade(config)
 	upgrade.Namespace = obj.GetReleaseNamespace()
-	upgrade.ResetValues = !obj.GetUpgrade().PreserveValues
-	upgrade.ReuseValues = obj.GetUpgrade().PreserveValues
+	upgrade.ReuseValues = obj.GetUpgrade().GetReuseValues()
+	upgrade.ResetValues = !upgrade.ReuseValues
 	upgrade.MaxHistory = obj.GetMaxHistory()
 	upgrade.Timeout = obj.GetUpgrade().GetTimeout(obj.GetTimeout()).Duration
-	upgrade.Wait = !obj.GetUpgrade().DisableWait
+	// When a readiness threshold is configured, the health of the resources
+	// is assessed by the controller after the action has been performed.
+	upgrade.Wait = !defaults.MustDisableWait(obj, obj.GetUpgrade().DisableWait) && !obj.UsesReadinessThreshold()
 	upgrade.WaitForJobs = !obj.GetUpgrade().DisableWaitForJobs
 	upgrade.DisableHooks = obj.GetUpgrade().DisableHooks
 	upgrade.DisableOpenAPIValidation = obj.GetUpgrade().DisableOpenAPIValidation
 	upgrade.SkipSchemaValidation = obj.GetUpgrade().DisableSchemaValidation
 	upgrade.Force = obj.GetUpgrade().Force
-	upgrade.CleanupOnFail = obj.GetUpgrade().CleanupOnFail
+	// The resources of a failed upgrade must not be cleaned up when they
+	// are kept for inspection.
+	upgrade.CleanupOnFail = obj.GetUpgrade().CleanupOnFail && !obj.GetUpgrade().GetRemediation().MustKeepFailedResources()
 	upgrade.Devel = true
 	upgrade.TakeOwnership = true
+	upgrade.Labels = ReleaseOwnerLabels(obj)
 
 	// If the user opted-in to allow DNS lookups, enable it.
 	if allowDNS, _ := features.Enabled(features.AllowDNSLookups); allowDNS {
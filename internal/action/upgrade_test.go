@@ -17,6 +17,7 @@ limitations under the License.
 package action
 
 import (
+	"context"
 	"testing"
 	"time"
 
@@ -94,4 +95,129 @@ func Test_newUpgrade(t *testing.T) {
 		g.Expect(got.Install).To(BeTrue())
 		g.Expect(got.DryRun).To(BeTrue())
 	})
+	t.Run("keep failed resources disables cleanup on fail", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "upgrade",
+				Namespace: "upgrade-ns",
+			},
+			Spec: v2.HelmReleaseSpec{
+				Upgrade: &v2.Upgrade{
+					CleanupOnFail: true,
+				},
+			},
+		}
+
+		got := newUpgrade(&helmaction.Configuration{}, obj, nil)
+		g.Expect(got.CleanupOnFail).To(BeTrue())
+
+		obj.Spec.Upgrade.Remediation = &v2.UpgradeRemediation{KeepFailedResources: true}
+		got = newUpgrade(&helmaction.Configuration{}, obj, nil)
+		g.Expect(got.CleanupOnFail).To(BeFalse())
+	})
+	t.Run("values options", func(t *testing.T) {
+		tests := []struct {
+			name      string
+			upgrade   *v2.Upgrade
+			wantReset bool
+			wantReuse bool
+		}{
+			{name: "default", upgrade: nil, wantReset: true},
+			{name: "reset values", upgrade: &v2.Upgrade{ResetValues: true}, wantReset: true},
+			{name: "reuse values", upgrade: &v2.Upgrade{ReuseValues: true}, wantReuse: true},
+			{name: "preserve values", upgrade: &v2.Upgrade{PreserveValues: true}, wantReuse: true},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				g := NewWithT(t)
+
+				obj := &v2.HelmRelease{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "upgrade",
+						Namespace: "upgrade-ns",
+					},
+					Spec: v2.HelmReleaseSpec{
+						Upgrade: tt.upgrade,
+					},
+				}
+
+				got := newUpgrade(&helmaction.Configuration{}, obj, nil)
+				g.Expect(got).ToNot(BeNil())
+				g.Expect(got.ResetValues).To(Equal(tt.wantReset))
+				g.Expect(got.ReuseValues).To(Equal(tt.wantReuse))
+			})
+		}
+	})
+}
+
+func Test_newUpgrade_timeout(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v2.HelmReleaseSpec
+		want time.Duration
+	}{
+		{
+			name: "upgrade timeout takes precedence",
+			spec: v2.HelmReleaseSpec{
+				Timeout: &metav1.Duration{Duration: time.Minute},
+				Upgrade: &v2.Upgrade{
+					Timeout: &metav1.Duration{Duration: 10 * time.Second},
+				},
+			},
+			want: 10 * time.Second,
+		},
+		{
+			name: "falls back to release timeout",
+			spec: v2.HelmReleaseSpec{
+				Timeout: &metav1.Duration{Duration: time.Minute},
+				Upgrade: &v2.Upgrade{},
+			},
+			want: time.Minute,
+		},
+		{
+			name: "falls back to default timeout",
+			spec: v2.HelmReleaseSpec{},
+			want: 5 * time.Minute,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "upgrade",
+					Namespace: "upgrade-ns",
+				},
+				Spec: tt.spec,
+			}
+
+			got := newUpgrade(&helmaction.Configuration{}, obj, nil)
+			g.Expect(got).ToNot(BeNil())
+			g.Expect(got.Timeout).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestUpgrade_conflictingValuesOptions(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "upgrade",
+			Namespace: "upgrade-ns",
+		},
+		Spec: v2.HelmReleaseSpec{
+			Upgrade: &v2.Upgrade{
+				ResetValues: true,
+				ReuseValues: true,
+			},
+		},
+	}
+
+	rls, err := Upgrade(context.TODO(), &helmaction.Configuration{}, obj, nil, nil)
+	g.Expect(err).To(MatchError(ContainSubstring("resetValues can not be combined")))
+	g.Expect(rls).To(BeNil())
 }
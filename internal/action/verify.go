@@ -43,6 +43,7 @@ var (
 
 const (
 	targetStorageNamespace = "storage namespace"
+	targetStorageDriver    = "storage driver"
 	targetReleaseNamespace = "release namespace"
 	targetReleaseName      = "release name"
 	targetChartName        = "chart name"
@@ -51,7 +52,7 @@ const (
 // ReleaseTargetChanged returns a reason and true if the given release and/or
 // chart name have been mutated in such a way that it no longer has the same
 // release target as recorded in the Status.History of the object, by comparing
-// the (storage) namespace, and release and chart names.
+// the (storage) namespace, storage driver, and release and chart names.
 // This can be used to e.g. trigger a garbage collection of the old release
 // before installing the new one.
 // If no change is detected, an empty string is returned along with false.
@@ -62,6 +63,8 @@ func ReleaseTargetChanged(obj *v2.HelmRelease, chartName string) (string, bool)
 		return "", false
 	case obj.GetStorageNamespace() != obj.Status.StorageNamespace:
 		return targetStorageNamespace, true
+	case StorageDriverName(obj.GetStorageDriver()) != StorageDriverName(obj.Status.StorageDriver):
+		return targetStorageDriver, true
 	case obj.GetReleaseNamespace() != cur.Namespace:
 		return targetReleaseNamespace, true
 	case release.ShortenName(obj.GetReleaseName()) != cur.Name:
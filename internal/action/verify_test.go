@@ -114,6 +114,42 @@ func TestReleaseTargetChanged(t *testing.T) {
 			wantReason: targetStorageNamespace,
 			want:       true,
 		},
+		{
+			name:      "different storage driver",
+			chartName: defaultChartName,
+			spec: v2.HelmReleaseSpec{
+				StorageDriver: v2.StorageDriverConfigMap,
+			},
+			status: v2.HelmReleaseStatus{
+				History: v2.Snapshots{
+					{
+						Name:      defaultName,
+						Namespace: defaultNamespace,
+						ChartName: defaultChartName,
+					},
+				},
+				StorageNamespace: defaultNamespace,
+			},
+			wantReason: targetStorageDriver,
+			want:       true,
+		},
+		{
+			name:      "default storage driver",
+			chartName: defaultChartName,
+			spec:      v2.HelmReleaseSpec{},
+			status: v2.HelmReleaseStatus{
+				History: v2.Snapshots{
+					{
+						Name:      defaultName,
+						Namespace: defaultNamespace,
+						ChartName: defaultChartName,
+					},
+				},
+				StorageNamespace: defaultNamespace,
+				StorageDriver:    v2.StorageDriverSecret,
+			},
+			want: false,
+		},
 		{
 			name:      "different release namespace",
 			chartName: defaultChartName,
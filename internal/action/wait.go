@@ -0,0 +1,75 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	helmaction "helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// ErrJobsNotComplete is returned when the Jobs of a Helm release did not
+// complete within the timeout of the action, or failed.
+var ErrJobsNotComplete = errors.New("jobs of release did not complete")
+
+// mustWaitForJobsOnly returns if the controller must wait for the Jobs of
+// the release after the Helm action, because waiting for Jobs is requested
+// while Helm does not wait for them as part of waiting for all resources.
+func mustWaitForJobsOnly(wait, helmWaitForJobs, waitForJobs bool) bool {
+	return waitForJobs && !(wait && helmWaitForJobs)
+}
+
+// waitForJobs waits for the Jobs in the manifest of the given release to
+// complete within the given timeout, without waiting for any of the other
+// resources of the release. When the Jobs do not complete, the release is
+// marked as failed in the Helm storage in the same way as Helm does when
+// waiting fails, and an error of type ErrJobsNotComplete is returned.
+func waitForJobs(config *helmaction.Configuration, rls *helmrelease.Release, timeout time.Duration) error {
+	resources, err := config.KubeClient.Build(bytes.NewBufferString(rls.Manifest), false)
+	if err != nil {
+		return fmt.Errorf("unable to build kubernetes objects from release manifest: %w", err)
+	}
+
+	jobs := filterJobs(resources)
+	if len(jobs) == 0 {
+		return nil
+	}
+
+	if err = config.KubeClient.WaitWithJobs(jobs, timeout); err != nil {
+		err = fmt.Errorf("%w: %w", ErrJobsNotComplete, err)
+		rls.SetStatus(helmrelease.StatusFailed, fmt.Sprintf("Release %q failed: %s", rls.Name, err.Error()))
+		if updateErr := config.Releases.Update(rls); updateErr != nil {
+			return fmt.Errorf("%w (failed to mark release as failed: %s)", err, updateErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// filterJobs returns the Jobs in the given resources.
+func filterJobs(resources kube.ResourceList) kube.ResourceList {
+	return resources.Filter(func(info *resource.Info) bool {
+		return info.Mapping != nil && info.Mapping.GroupVersionKind.GroupKind() == batchv1.SchemeGroupVersion.WithKind("Job").GroupKind()
+	})
+}
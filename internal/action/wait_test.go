@@ -0,0 +1,183 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package action
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	helmaction "helm.sh/helm/v3/pkg/action"
+	helmkube "helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmstorage "helm.sh/helm/v3/pkg/storage"
+	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// waitingKubeClient builds a fixed list of resources, and records the
+// resources it is asked to wait for.
+type waitingKubeClient struct {
+	*kubefake.PrintingKubeClient
+
+	resources helmkube.ResourceList
+	waitErr   error
+	waited    helmkube.ResourceList
+}
+
+func (c *waitingKubeClient) Build(_ io.Reader, _ bool) (helmkube.ResourceList, error) {
+	return c.resources, nil
+}
+
+func (c *waitingKubeClient) WaitWithJobs(resources helmkube.ResourceList, _ time.Duration) error {
+	c.waited = resources
+	return c.waitErr
+}
+
+func newWaitTestResource(kind, name string) *resource.Info {
+	gvk := schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: kind}
+	if kind == "Job" {
+		gvk.Group = "batch"
+	}
+	return &resource.Info{
+		Name:      name,
+		Namespace: "default",
+		Mapping:   &meta.RESTMapping{GroupVersionKind: gvk},
+	}
+}
+
+func Test_mustWaitForJobsOnly(t *testing.T) {
+	tests := []struct {
+		name            string
+		wait            bool
+		helmWaitForJobs bool
+		waitForJobs     bool
+		want            bool
+	}{
+		{name: "wait for jobs without wait", waitForJobs: true, want: true},
+		{name: "wait for jobs while Helm waits for jobs", wait: true, helmWaitForJobs: true, waitForJobs: true},
+		{name: "wait for jobs while Helm waits without jobs", wait: true, waitForJobs: true, want: true},
+		{name: "wait without wait for jobs", wait: true, helmWaitForJobs: true},
+		{name: "no wait"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(mustWaitForJobsOnly(tt.wait, tt.helmWaitForJobs, tt.waitForJobs)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_newInstallUpgrade_waitForJobs(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{Name: "wait", Namespace: "wait-ns"},
+		Spec: v2.HelmReleaseSpec{
+			Install: &v2.Install{DisableWait: true, WaitForJobs: true},
+			Upgrade: &v2.Upgrade{WaitForJobs: false},
+		},
+	}
+
+	install := newInstall(&helmaction.Configuration{}, obj, nil)
+	g.Expect(install.Wait).To(BeFalse())
+	g.Expect(mustWaitForJobsOnly(install.Wait, install.WaitForJobs, obj.GetInstall().WaitForJobs)).To(BeTrue())
+
+	upgrade := newUpgrade(&helmaction.Configuration{}, obj, nil)
+	g.Expect(upgrade.Wait).To(BeTrue())
+	g.Expect(mustWaitForJobsOnly(upgrade.Wait, upgrade.WaitForJobs, obj.GetUpgrade().WaitForJobs)).To(BeFalse())
+
+	obj.Spec.Upgrade = &v2.Upgrade{DisableWait: true}
+	upgrade = newUpgrade(&helmaction.Configuration{}, obj, nil)
+	g.Expect(upgrade.Wait).To(BeFalse())
+	g.Expect(mustWaitForJobsOnly(upgrade.Wait, upgrade.WaitForJobs, obj.GetUpgrade().WaitForJobs)).To(BeFalse())
+}
+
+func Test_waitForJobs(t *testing.T) {
+	newConfig := func(client *waitingKubeClient, rls *helmrelease.Release) *helmaction.Configuration {
+		store := helmstorage.Init(helmdriver.NewMemory())
+		if err := store.Create(rls); err != nil {
+			t.Fatal(err)
+		}
+		return &helmaction.Configuration{KubeClient: client, Releases: store}
+	}
+	newRelease := func() *helmrelease.Release {
+		return &helmrelease.Release{
+			Name:      "wait",
+			Namespace: "default",
+			Version:   1,
+			Info:      &helmrelease.Info{Status: helmrelease.StatusDeployed},
+		}
+	}
+
+	t.Run("waits for jobs only", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &waitingKubeClient{
+			PrintingKubeClient: &kubefake.PrintingKubeClient{Out: io.Discard},
+			resources: helmkube.ResourceList{
+				newWaitTestResource("Deployment", "app"),
+				newWaitTestResource("Job", "migrate"),
+			},
+		}
+		rls := newRelease()
+		g.Expect(waitForJobs(newConfig(client, rls), rls, time.Second)).To(Succeed())
+		g.Expect(client.waited).To(HaveLen(1))
+		g.Expect(client.waited[0].Name).To(Equal("migrate"))
+		g.Expect(rls.Info.Status).To(Equal(helmrelease.StatusDeployed))
+	})
+
+	t.Run("without jobs", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &waitingKubeClient{
+			PrintingKubeClient: &kubefake.PrintingKubeClient{Out: io.Discard},
+			resources:          helmkube.ResourceList{newWaitTestResource("Deployment", "app")},
+			waitErr:            context.DeadlineExceeded,
+		}
+		rls := newRelease()
+		g.Expect(waitForJobs(newConfig(client, rls), rls, time.Second)).To(Succeed())
+		g.Expect(client.waited).To(BeNil())
+	})
+
+	t.Run("marks release failed when jobs do not complete", func(t *testing.T) {
+		g := NewWithT(t)
+
+		client := &waitingKubeClient{
+			PrintingKubeClient: &kubefake.PrintingKubeClient{Out: io.Discard},
+			resources:          helmkube.ResourceList{newWaitTestResource("Job", "migrate")},
+			waitErr:            context.DeadlineExceeded,
+		}
+		rls := newRelease()
+		cfg := newConfig(client, rls)
+		err := waitForJobs(cfg, rls, time.Second)
+		g.Expect(err).To(MatchError(ErrJobsNotComplete))
+		g.Expect(err).To(MatchError(context.DeadlineExceeded))
+
+		got, err := cfg.Releases.Get(rls.Name, rls.Version)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(got.Info.Status).To(Equal(helmrelease.StatusFailed))
+	})
+}
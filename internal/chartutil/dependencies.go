@@ -0,0 +1,113 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// DependencyTree returns the dependency tree of the given chart, flattened
+// in depth-first order. The enablement of each dependency is determined from
+// its condition and tags evaluated against the given values, the same way
+// Helm does when rendering the chart. Dependencies of a disabled dependency
+// are reported as disabled.
+func DependencyTree(chrt *chart.Chart, values chartutil.Values) ([]v2.ChartDependency, error) {
+	if chrt == nil || chrt.Metadata == nil || len(chrt.Metadata.Dependencies) == 0 {
+		return nil, nil
+	}
+
+	cvals, err := chartutil.CoalesceValues(chrt, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to coalesce values: %w", err)
+	}
+	tags, _ := cvals.Table("tags")
+
+	var deps []v2.ChartDependency
+	var walk func(c *chart.Chart, path string, parentEnabled bool)
+	walk = func(c *chart.Chart, path string, parentEnabled bool) {
+		if c == nil || c.Metadata == nil {
+			return
+		}
+		for _, d := range c.Metadata.Dependencies {
+			if d == nil {
+				continue
+			}
+			name := dependencyName(d)
+			dep := v2.ChartDependency{
+				Name:       d.Name,
+				Alias:      d.Alias,
+				Path:       strings.TrimPrefix(path+"/"+name, "/"),
+				Version:    d.Version,
+				Repository: d.Repository,
+				Enabled:    parentEnabled && dependencyEnabled(d, cvals, tags, valuesPath(path)),
+			}
+			sub := findDependencyChart(c, d.Name)
+			if sub != nil && sub.Metadata != nil {
+				dep.Version = sub.Metadata.Version
+			}
+			deps = append(deps, dep)
+			walk(sub, dep.Path, dep.Enabled)
+		}
+	}
+	walk(chrt, "", true)
+	return deps, nil
+}
+
+// dependencyEnabled returns if the given dependency is enabled by its tags
+// and condition. The first condition which resolves to a boolean takes
+// precedence over the tags.
+func dependencyEnabled(d *chart.Dependency, cvals chartutil.Values, tags chartutil.Values, prefix string) bool {
+	enabled := true
+	var hasTrue, hasFalse bool
+	for _, t := range d.Tags {
+		if b, ok := tags[t].(bool); ok {
+			hasTrue = hasTrue || b
+			hasFalse = hasFalse || !b
+		}
+	}
+	if !hasTrue && hasFalse {
+		enabled = false
+	}
+
+	for _, c := range strings.Split(strings.TrimSpace(d.Condition), ",") {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			continue
+		}
+		if v, err := cvals.PathValue(prefix + c); err == nil {
+			if b, ok := v.(bool); ok {
+				return b
+			}
+		}
+	}
+	return enabled
+}
+
+// valuesPath returns the values path prefix for the conditions of the
+// dependencies of the chart at the given dependency tree path.
+func valuesPath(path string) string {
+	if path == "" {
+		return ""
+	}
+	return strings.ReplaceAll(path, "/", ".") + "."
+}
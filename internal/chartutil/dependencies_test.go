@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func TestDependencyTree(t *testing.T) {
+	tests := []struct {
+		name   string
+		chart  *chart.Chart
+		values chartutil.Values
+		want   []v2.ChartDependency
+	}{
+		{
+			name:  "chart without dependencies",
+			chart: &chart.Chart{Metadata: &chart.Metadata{Name: "foo", Version: "1.0.0"}},
+		},
+		{
+			name:  "all enabled by default",
+			chart: umbrellaTestChart(),
+			want: []v2.ChartDependency{
+				{Name: "postgresql", Path: "postgresql", Version: "1.0.0", Enabled: true},
+				{Name: "redis", Alias: "cache", Path: "cache", Version: "1.0.0", Enabled: true},
+				{Name: "backend", Path: "backend", Version: "1.0.0", Enabled: true},
+				{Name: "postgresql", Path: "backend/postgresql", Version: "1.0.0", Enabled: true},
+				{Name: "monitoring", Path: "monitoring", Version: "1.0.0", Enabled: true},
+			},
+		},
+		{
+			name:  "disabled by condition",
+			chart: umbrellaTestChart(),
+			values: chartutil.Values{
+				"cache":   map[string]interface{}{"enabled": false},
+				"backend": map[string]interface{}{"enabled": false},
+			},
+			want: []v2.ChartDependency{
+				{Name: "postgresql", Path: "postgresql", Version: "1.0.0", Enabled: true},
+				{Name: "redis", Alias: "cache", Path: "cache", Version: "1.0.0", Enabled: false},
+				{Name: "backend", Path: "backend", Version: "1.0.0", Enabled: false},
+				{Name: "postgresql", Path: "backend/postgresql", Version: "1.0.0", Enabled: false},
+				{Name: "monitoring", Path: "monitoring", Version: "1.0.0", Enabled: true},
+			},
+		},
+		{
+			name:  "first resolvable condition takes precedence",
+			chart: umbrellaTestChart(),
+			values: chartutil.Values{
+				"global": map[string]interface{}{"postgresql": map[string]interface{}{"enabled": false}},
+			},
+			want: []v2.ChartDependency{
+				{Name: "postgresql", Path: "postgresql", Version: "1.0.0", Enabled: false},
+				{Name: "redis", Alias: "cache", Path: "cache", Version: "1.0.0", Enabled: true},
+				{Name: "backend", Path: "backend", Version: "1.0.0", Enabled: true},
+				{Name: "postgresql", Path: "backend/postgresql", Version: "1.0.0", Enabled: true},
+				{Name: "monitoring", Path: "monitoring", Version: "1.0.0", Enabled: true},
+			},
+		},
+		{
+			name: "disabled by tags",
+			chart: &chart.Chart{
+				Metadata: &chart.Metadata{
+					Name:    "foo",
+					Version: "1.0.0",
+					Dependencies: []*chart.Dependency{
+						{Name: "bar", Version: "~1.0", Repository: "https://example.com", Tags: []string{"extra"}},
+						{Name: "baz", Version: "~2.0", Tags: []string{"extra"}, Condition: "baz.enabled"},
+					},
+				},
+			},
+			values: chartutil.Values{
+				"tags": map[string]interface{}{"extra": false},
+				"baz":  map[string]interface{}{"enabled": true},
+			},
+			want: []v2.ChartDependency{
+				{Name: "bar", Path: "bar", Version: "~1.0", Repository: "https://example.com", Enabled: false},
+				{Name: "baz", Path: "baz", Version: "~2.0", Enabled: true},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := DependencyTree(tt.chart, tt.values)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
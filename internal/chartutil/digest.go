@@ -35,6 +35,21 @@ func DigestValues(algo digest.Algorithm, values chartutil.Values) digest.Digest
 	return digester.Digest()
 }
 
+// DigestValuesByKey calculates the digest of the values per top-level key
+// using the provided algorithm. The digest of a key includes the key itself,
+// so moving a value to another key results in a different digest.
+// It returns nil if the values are empty.
+func DigestValuesByKey(algo digest.Algorithm, values chartutil.Values) map[string]string {
+	if values = valuesOrNil(values); values == nil {
+		return nil
+	}
+	digests := make(map[string]string, len(values))
+	for k, v := range values {
+		digests[k] = DigestValues(algo, chartutil.Values{k: v}).String()
+	}
+	return digests
+}
+
 // VerifyValues verifies the digest of the values against the provided digest.
 func VerifyValues(digest digest.Digest, values chartutil.Values) bool {
 	if digest.Validate() != nil {
@@ -137,6 +137,43 @@ func TestDigestValues(t *testing.T) {
 	}
 }
 
+func TestDigestValuesByKey(t *testing.T) {
+	if got := DigestValuesByKey(digest.SHA256, chartutil.Values{}); got != nil {
+		t.Errorf("DigestValuesByKey() = %v, want nil", got)
+	}
+
+	values := chartutil.Values{
+		"replicas": 3,
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "latest",
+		},
+	}
+	got := DigestValuesByKey(digest.SHA256, values)
+	if len(got) != len(values) {
+		t.Fatalf("DigestValuesByKey() returned %d digests, want %d", len(got), len(values))
+	}
+	for k, v := range values {
+		if want := DigestValues(digest.SHA256, chartutil.Values{k: v}).String(); got[k] != want {
+			t.Errorf("DigestValuesByKey()[%q] = %v, want %v", k, got[k], want)
+		}
+	}
+
+	changed := DigestValuesByKey(digest.SHA256, chartutil.Values{
+		"replicas": 3,
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "stable",
+		},
+	})
+	if changed["replicas"] != got["replicas"] {
+		t.Errorf("DigestValuesByKey()[\"replicas\"] changed while value did not")
+	}
+	if changed["image"] == got["image"] {
+		t.Errorf("DigestValuesByKey()[\"image\"] did not change while value did")
+	}
+}
+
 func TestVerifyValues(t *testing.T) {
 	tests := []struct {
 		name   string
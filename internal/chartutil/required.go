@@ -0,0 +1,131 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+var (
+	// requiredArgRegexp matches the use of the 'required' template function
+	// with a values path as argument, e.g. `required "msg" .Values.foo`.
+	requiredArgRegexp = regexp.MustCompile("required\\s+(?:\"(?:[^\"\\\\]|\\\\.)*\"|`[^`]*`)\\s+\\(?\\s*\\$?\\.Values((?:\\.[A-Za-z0-9_-]+)+)")
+	// requiredPipeRegexp matches the use of the 'required' template function
+	// with a piped values path, e.g. `.Values.foo | required "msg"`.
+	requiredPipeRegexp = regexp.MustCompile(`\$?\.Values((?:\.[A-Za-z0-9_-]+)+)\s*\|\s*required\b`)
+)
+
+// IsRenderError returns true if the given error is the result of a template
+// failing to render, for example due to a missing required value.
+func IsRenderError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "execution error at (") || strings.Contains(msg, "template: ")
+}
+
+// MissingRequiredValues returns the sorted paths of the values which are
+// passed to the 'required' template function in the templates of the given
+// chart (and its dependencies), but are missing or empty in the values
+// coalesced with the chart defaults.
+//
+// This is a best-effort static analysis, and does not detect values which
+// are required conditionally or through named templates taking a different
+// scope.
+func MissingRequiredValues(chrt *chart.Chart, values chartutil.Values) []string {
+	if chrt == nil {
+		return nil
+	}
+	coalesced, err := chartutil.CoalesceValues(chrt, values)
+	if err != nil {
+		return nil
+	}
+
+	missing := make(map[string]struct{})
+	missingRequiredValues(chrt, coalesced, "", missing)
+
+	paths := make([]string, 0, len(missing))
+	for p := range missing {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// missingRequiredValues records the missing required values of the given
+// chart with the given scope in missing, prefixing the paths with prefix.
+func missingRequiredValues(chrt *chart.Chart, scope map[string]interface{}, prefix string, missing map[string]struct{}) {
+	for _, t := range chrt.Templates {
+		if t == nil {
+			continue
+		}
+		data := string(t.Data)
+		for _, re := range []*regexp.Regexp{requiredArgRegexp, requiredPipeRegexp} {
+			for _, m := range re.FindAllStringSubmatch(data, -1) {
+				path := strings.TrimPrefix(m[1], ".")
+				if !hasValue(scope, strings.Split(path, ".")) {
+					missing[prefix+path] = struct{}{}
+				}
+			}
+		}
+	}
+
+	for _, dep := range chrt.Dependencies() {
+		depScope, _ := scope[dep.Name()].(map[string]interface{})
+		missingRequiredValues(dep, depScope, prefix+dep.Name()+".", missing)
+	}
+}
+
+// hasValue returns true if the value at the given path exists and is not
+// empty, following the semantics of the 'required' template function.
+func hasValue(scope map[string]interface{}, path []string) bool {
+	var cur interface{} = scope
+	for _, key := range path {
+		m, ok := toMap(cur)
+		if !ok {
+			return false
+		}
+		if cur, ok = m[key]; !ok {
+			return false
+		}
+	}
+	if cur == nil {
+		return false
+	}
+	if s, ok := cur.(string); ok && s == "" {
+		return false
+	}
+	return true
+}
+
+// toMap returns the given value as a map, if it is one.
+func toMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case map[string]interface{}:
+		return m, true
+	case chartutil.Values:
+		return m, true
+	default:
+		return nil, false
+	}
+}
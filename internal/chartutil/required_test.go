@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/engine"
+)
+
+func requiredTestChart() *chart.Chart {
+	sub := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "database", Version: "0.1.0"},
+		Values: map[string]interface{}{
+			"auth": map[string]interface{}{
+				"password": "",
+			},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/secret.yaml", Data: []byte(`password: {{ .Values.auth.password | required "auth.password is required" }}`)},
+		},
+	}
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "app", Version: "0.1.0"},
+		Values: map[string]interface{}{
+			"image": map[string]interface{}{
+				"repository": "nginx",
+				"tag":        "",
+			},
+			"ingress": map[string]interface{}{},
+		},
+		Templates: []*chart.File{
+			{Name: "templates/deployment.yaml", Data: []byte(`image: {{ required "image.repository is required" .Values.image.repository }}:{{ required "image.tag is required" .Values.image.tag }}
+host: {{ required ` + "`ingress.host is required`" + ` $.Values.ingress.host }}`)},
+		},
+	}
+	chrt.AddDependency(sub)
+	return chrt
+}
+
+func TestMissingRequiredValues(t *testing.T) {
+	tests := []struct {
+		name   string
+		values chartutil.Values
+		want   []string
+	}{
+		{
+			name:   "all required values missing",
+			values: nil,
+			want:   []string{"database.auth.password", "image.tag", "ingress.host"},
+		},
+		{
+			name: "some required values set",
+			values: chartutil.Values{
+				"image":   map[string]interface{}{"tag": "1.25"},
+				"ingress": map[string]interface{}{"host": "example.com"},
+			},
+			want: []string{"database.auth.password"},
+		},
+		{
+			name: "all required values set",
+			values: chartutil.Values{
+				"image":    map[string]interface{}{"tag": "1.25"},
+				"ingress":  map[string]interface{}{"host": "example.com"},
+				"database": map[string]interface{}{"auth": map[string]interface{}{"password": "secret"}},
+			},
+			want: []string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			chrt := requiredTestChart()
+			g.Expect(MissingRequiredValues(chrt, tt.values)).To(Equal(tt.want))
+
+			// Confirm Helm agrees on the chart failing to render.
+			vals, err := chartutil.ToRenderValues(chrt, tt.values, chartutil.ReleaseOptions{Name: "app"}, nil)
+			g.Expect(err).ToNot(HaveOccurred())
+			_, err = engine.Render(chrt, vals)
+			g.Expect(IsRenderError(err)).To(Equal(len(tt.want) > 0))
+		})
+	}
+}
+
+func TestIsRenderError(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(IsRenderError(nil)).To(BeFalse())
+	g.Expect(IsRenderError(errors.New("context deadline exceeded"))).To(BeFalse())
+	g.Expect(IsRenderError(errors.New(`template: app/templates/deployment.yaml:2:28: executing "app/templates/deployment.yaml" at <$.Values.ingress.host>: nil pointer evaluating interface {}.host`))).To(BeTrue())
+	g.Expect(IsRenderError(errors.New(`execution error at (app/templates/deployment.yaml:1:10): image.tag is required`))).To(BeTrue())
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// ValidateValuesSchema validates the given values coalesced with the chart
+// defaults against the JSON schema (values.schema.json) of the chart. It
+// returns an error naming the path of each invalid value, or nil if the
+// values are valid or the chart has no schema.
+//
+// The schemas of the dependencies of the chart are not validated, as they
+// may be disabled by the values.
+func ValidateValuesSchema(chrt *chart.Chart, values chartutil.Values) error {
+	if chrt == nil || len(chrt.Schema) == 0 {
+		return nil
+	}
+	coalesced, err := chartutil.CoalesceValues(chrt, values)
+	if err != nil {
+		return fmt.Errorf("failed to merge values with chart defaults: %w", err)
+	}
+
+	if err = chartutil.ValidateAgainstSingleSchema(coalesced, chrt.Schema); err != nil {
+		var invalid []string
+		for _, line := range strings.Split(strings.TrimSpace(err.Error()), "\n") {
+			if line = strings.TrimSpace(strings.TrimPrefix(line, "- ")); line != "" {
+				invalid = append(invalid, line)
+			}
+		}
+		return fmt.Errorf("values do not match the schema of chart '%s': %s", chrt.Name(), strings.Join(invalid, "; "))
+	}
+	return nil
+}
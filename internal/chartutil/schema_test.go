@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestValidateValuesSchema(t *testing.T) {
+	schema := []byte(`{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "type": "object",
+  "properties": {
+    "image": {
+      "type": "object",
+      "properties": {
+        "tag": {"type": "string"}
+      }
+    },
+    "replicas": {"type": "integer", "minimum": 1}
+  }
+}`)
+
+	tests := []struct {
+		name    string
+		schema  []byte
+		values  chartutil.Values
+		wantErr []string
+	}{
+		{
+			name:   "valid values",
+			schema: schema,
+			values: chartutil.Values{"image": map[string]interface{}{"tag": "1.0.0"}},
+		},
+		{
+			name:    "invalid nested value",
+			schema:  schema,
+			values:  chartutil.Values{"image": map[string]interface{}{"tag": 1}},
+			wantErr: []string{"values do not match the schema of chart 'app'", "image.tag: Invalid type"},
+		},
+		{
+			name:   "multiple invalid values",
+			schema: schema,
+			values: chartutil.Values{
+				"image":    map[string]interface{}{"tag": true},
+				"replicas": 0,
+			},
+			wantErr: []string{"image.tag: Invalid type", "; ", "replicas: Must be greater than or equal to 1"},
+		},
+		{
+			name:   "chart without schema",
+			values: chartutil.Values{"image": map[string]interface{}{"tag": 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			chrt := &chart.Chart{
+				Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "app", Version: "0.1.0"},
+				Values: map[string]interface{}{
+					"image":    map[string]interface{}{"tag": "latest"},
+					"replicas": 1,
+				},
+				Schema: tt.schema,
+			}
+
+			err := ValidateValuesSchema(chrt, tt.values)
+			if len(tt.wantErr) == 0 {
+				g.Expect(err).ToNot(HaveOccurred())
+				return
+			}
+			g.Expect(err).To(HaveOccurred())
+			for _, want := range tt.wantErr {
+				g.Expect(err.Error()).To(ContainSubstring(want))
+			}
+			g.Expect(err.Error()).ToNot(ContainSubstring("\n"))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"bytes"
+
+	"github.com/opencontainers/go-digest"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	intyaml "github.com/fluxcd/helm-controller/internal/yaml"
+)
+
+const (
+	// MaxValuesSnapshotSize is the maximum size in bytes of a values
+	// snapshot as returned by SnapshotValues.
+	MaxValuesSnapshotSize = 2048
+
+	// truncatedMarker is appended to a values snapshot which exceeded
+	// the maximum size.
+	truncatedMarker = "# truncated\n"
+)
+
+// MergeChartValues returns the given values merged with the default values
+// of the chart and its dependencies. Neither the chart nor the values are
+// modified.
+func MergeChartValues(chrt *chart.Chart, values chartutil.Values) (chartutil.Values, error) {
+	return chartutil.CoalesceValues(chrt, values)
+}
+
+// SnapshotValues returns a YAML snapshot of the given values, in which any
+// value at a path which is also set in the sensitive values is replaced by
+// its digest, calculated using the provided algorithm. When the snapshot
+// exceeds the given size limit, it is truncated at the last complete line
+// within the limit, followed by a "# truncated" comment.
+func SnapshotValues(algo digest.Algorithm, values, sensitive chartutil.Values, limit int) (string, error) {
+	if values = valuesOrNil(values); values == nil {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	if err := intyaml.Encode(&buf, RedactValues(algo, values, sensitive), intyaml.SortMapSlice); err != nil {
+		return "", err
+	}
+	if buf.Len() <= limit {
+		return buf.String(), nil
+	}
+
+	snapshot := buf.Bytes()[:max(limit-len(truncatedMarker), 0)]
+	if i := bytes.LastIndexByte(snapshot, '\n'); i >= 0 {
+		snapshot = snapshot[:i+1]
+	} else {
+		snapshot = nil
+	}
+	return string(snapshot) + truncatedMarker, nil
+}
+
+// RedactValues returns a copy of the given values, in which any value at a
+// path which is also set in the sensitive values is replaced by its digest,
+// calculated using the provided algorithm. The digest includes the key of
+// the value.
+func RedactValues(algo digest.Algorithm, values, sensitive map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		s, ok := sensitive[k]
+		if !ok {
+			redacted[k] = v
+			continue
+		}
+		vm, vok := toMap(v)
+		sm, sok := toMap(s)
+		if vok && sok {
+			redacted[k] = RedactValues(algo, vm, sm)
+			continue
+		}
+		redacted[k] = DigestValues(algo, chartutil.Values{k: v}).String()
+	}
+	return redacted
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+func TestMergeChartValues(t *testing.T) {
+	g := NewWithT(t)
+
+	chrt := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "chart"},
+		Values: map[string]interface{}{
+			"replicas": 1,
+			"image":    map[string]interface{}{"repository": "nginx", "tag": "latest"},
+		},
+	}
+	values := chartutil.Values{
+		"image": map[string]interface{}{"tag": "1.25"},
+	}
+
+	got, err := MergeChartValues(chrt, values)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(HaveKeyWithValue("replicas", 1))
+	g.Expect(got).To(HaveKeyWithValue("image", map[string]interface{}{"repository": "nginx", "tag": "1.25"}))
+
+	// Neither the chart defaults nor the given values are modified.
+	g.Expect(chrt.Values["image"]).To(Equal(map[string]interface{}{"repository": "nginx", "tag": "latest"}))
+	g.Expect(values).To(Equal(chartutil.Values{"image": map[string]interface{}{"tag": "1.25"}}))
+}
+
+func TestSnapshotValues(t *testing.T) {
+	values := chartutil.Values{
+		"replicas": 2,
+		"auth": map[string]interface{}{
+			"username": "admin",
+			"password": "s3cr3t",
+		},
+		"token": map[string]interface{}{
+			"value": "t0k3n",
+		},
+	}
+	sensitive := chartutil.Values{
+		"auth": map[string]interface{}{
+			"password": "s3cr3t",
+		},
+		"token": "t0k3n",
+	}
+
+	tests := []struct {
+		name      string
+		values    chartutil.Values
+		sensitive chartutil.Values
+		limit     int
+		want      string
+	}{
+		{
+			name:   "empty",
+			values: chartutil.Values{},
+			limit:  MaxValuesSnapshotSize,
+			want:   "",
+		},
+		{
+			name:   "values",
+			values: values,
+			limit:  MaxValuesSnapshotSize,
+			want: `auth:
+  password: s3cr3t
+  username: admin
+replicas: 2
+token:
+  value: t0k3n
+`,
+		},
+		{
+			name:      "redacted sensitive values",
+			values:    values,
+			sensitive: sensitive,
+			limit:     MaxValuesSnapshotSize,
+			want: `auth:
+  password: ` + DigestValues(digest.SHA256, chartutil.Values{"password": "s3cr3t"}).String() + `
+  username: admin
+replicas: 2
+token: ` + DigestValues(digest.SHA256, chartutil.Values{"token": map[string]interface{}{"value": "t0k3n"}}).String() + `
+`,
+		},
+		{
+			name:   "truncated",
+			values: values,
+			limit:  50,
+			want: `auth:
+  password: s3cr3t
+# truncated
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := SnapshotValues(digest.SHA256, tt.values, tt.sensitive, tt.limit)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+			g.Expect(len(got)).To(BeNumerically("<=", max(tt.limit, len(truncatedMarker))))
+			for _, secret := range []string{"s3cr3t", "t0k3n"} {
+				if tt.sensitive != nil {
+					g.Expect(strings.Contains(got, secret)).To(BeFalse())
+				}
+			}
+		})
+	}
+}
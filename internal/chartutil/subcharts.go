@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/strvals"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// ApplySubcharts sets the values of the conditions of the dependencies of the
+// given chart to enable or disable the given subcharts. It returns an error
+// if a subchart is not a dependency of the chart, or does not have a
+// condition which can be used to toggle it.
+//
+// In addition, it returns a warning for each enabled subchart which depends
+// on a subchart which is disabled.
+func ApplySubcharts(chrt *chart.Chart, values chartutil.Values, subcharts []v2.Subchart) (chartutil.Values, []string, error) {
+	if len(subcharts) == 0 {
+		return values, nil, nil
+	}
+	if chrt == nil || chrt.Metadata == nil {
+		return nil, nil, fmt.Errorf("chart has no metadata")
+	}
+
+	deps := make(map[string]*chart.Dependency, len(chrt.Metadata.Dependencies))
+	for _, d := range chrt.Metadata.Dependencies {
+		if d == nil {
+			continue
+		}
+		deps[dependencyName(d)] = d
+	}
+
+	if values == nil {
+		values = chartutil.Values{}
+	}
+	enabled := make(map[string]bool, len(subcharts))
+	for _, s := range subcharts {
+		d, ok := deps[s.Name]
+		if !ok {
+			return nil, nil, fmt.Errorf("subchart '%s' is not a dependency of chart '%s'", s.Name, chrt.Name())
+		}
+		condition := strings.TrimSpace(strings.Split(d.Condition, ",")[0])
+		if condition == "" {
+			return nil, nil, fmt.Errorf("subchart '%s' can not be toggled: no condition defined for dependency in chart '%s'",
+				s.Name, chrt.Name())
+		}
+		if err := strvals.ParseInto(fmt.Sprintf("%s=%t", condition, s.Enabled), values); err != nil {
+			return nil, nil, fmt.Errorf("failed to set condition '%s' for subchart '%s': %w", condition, s.Name, err)
+		}
+		enabled[s.Name] = s.Enabled
+	}
+
+	return values, disabledDependencyWarnings(chrt, deps, enabled), nil
+}
+
+// disabledDependencyWarnings returns a warning for each subchart which is not
+// disabled, and depends on a chart of a subchart which is disabled.
+func disabledDependencyWarnings(chrt *chart.Chart, deps map[string]*chart.Dependency, enabled map[string]bool) []string {
+	disabled := make(map[string]string)
+	for name, e := range enabled {
+		if !e {
+			disabled[deps[name].Name] = name
+		}
+	}
+	if len(disabled) == 0 {
+		return nil
+	}
+
+	var warnings []string
+	for _, d := range chrt.Metadata.Dependencies {
+		if d == nil {
+			continue
+		}
+		name := dependencyName(d)
+		if e, ok := enabled[name]; ok && !e {
+			continue
+		}
+		sub := findDependencyChart(chrt, d.Name)
+		if sub == nil || sub.Metadata == nil {
+			continue
+		}
+		for _, sd := range sub.Metadata.Dependencies {
+			if sd == nil {
+				continue
+			}
+			if dn, ok := disabled[sd.Name]; ok {
+				warnings = append(warnings, fmt.Sprintf("subchart '%s' depends on disabled subchart '%s'", name, dn))
+			}
+		}
+	}
+	return warnings
+}
+
+// dependencyName returns the name of the given dependency as used in the
+// values, i.e. the alias if set.
+func dependencyName(d *chart.Dependency) string {
+	if d.Alias != "" {
+		return d.Alias
+	}
+	return d.Name
+}
+
+// findDependencyChart returns the loaded chart of the dependency with the
+// given name, or nil.
+func findDependencyChart(chrt *chart.Chart, name string) *chart.Chart {
+	for _, c := range chrt.Dependencies() {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package chartutil
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func umbrellaTestChart() *chart.Chart {
+	database := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "postgresql", Version: "1.0.0"},
+	}
+	cache := &chart.Chart{
+		Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "redis", Version: "1.0.0"},
+	}
+	backend := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "backend",
+			Version:    "1.0.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "postgresql", Version: "1.0.0"},
+			},
+		},
+	}
+	umbrella := &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "umbrella",
+			Version:    "1.0.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "postgresql", Version: "1.0.0", Condition: "postgresql.enabled,global.postgresql.enabled"},
+				{Name: "redis", Version: "1.0.0", Alias: "cache", Condition: "cache.enabled"},
+				{Name: "backend", Version: "1.0.0", Condition: "backend.enabled"},
+				{Name: "monitoring", Version: "1.0.0"},
+			},
+		},
+	}
+	umbrella.AddDependency(database, cache, backend)
+	return umbrella
+}
+
+func TestApplySubcharts(t *testing.T) {
+	tests := []struct {
+		name         string
+		values       chartutil.Values
+		subcharts    []v2.Subchart
+		want         chartutil.Values
+		wantWarnings []string
+		wantErr      string
+	}{
+		{
+			name:   "no subcharts",
+			values: chartutil.Values{"foo": "bar"},
+			want:   chartutil.Values{"foo": "bar"},
+		},
+		{
+			name:   "toggles subcharts using condition",
+			values: chartutil.Values{"postgresql": map[string]interface{}{"auth": "x"}},
+			subcharts: []v2.Subchart{
+				{Name: "postgresql", Enabled: true},
+				{Name: "cache", Enabled: false},
+			},
+			want: chartutil.Values{
+				"postgresql": map[string]interface{}{"auth": "x", "enabled": true},
+				"cache":      map[string]interface{}{"enabled": false},
+			},
+		},
+		{
+			name: "overrides values",
+			values: chartutil.Values{
+				"backend": map[string]interface{}{"enabled": false},
+			},
+			subcharts: []v2.Subchart{
+				{Name: "backend", Enabled: true},
+			},
+			want: chartutil.Values{
+				"backend": map[string]interface{}{"enabled": true},
+			},
+		},
+		{
+			name: "warns about disabled dependency",
+			subcharts: []v2.Subchart{
+				{Name: "postgresql", Enabled: false},
+			},
+			want: chartutil.Values{
+				"postgresql": map[string]interface{}{"enabled": false},
+			},
+			wantWarnings: []string{"subchart 'backend' depends on disabled subchart 'postgresql'"},
+		},
+		{
+			name: "unknown subchart",
+			subcharts: []v2.Subchart{
+				{Name: "postgres", Enabled: true},
+			},
+			wantErr: "subchart 'postgres' is not a dependency of chart 'umbrella'",
+		},
+		{
+			name: "alias must be used",
+			subcharts: []v2.Subchart{
+				{Name: "redis", Enabled: true},
+			},
+			wantErr: "subchart 'redis' is not a dependency of chart 'umbrella'",
+		},
+		{
+			name: "subchart without condition",
+			subcharts: []v2.Subchart{
+				{Name: "monitoring", Enabled: true},
+			},
+			wantErr: "no condition defined",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, warnings, err := ApplySubcharts(umbrellaTestChart(), tt.values, tt.subcharts)
+			if tt.wantErr != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tt.wantErr))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+			g.Expect(warnings).To(Equal(tt.wantWarnings))
+		})
+	}
+}
@@ -18,10 +18,12 @@ package chartutil
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
 
+	jsonpatch "github.com/evanphx/json-patch/v5"
 	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/strvals"
 	corev1 "k8s.io/api/core/v1"
@@ -29,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	kubeclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
 
 	"github.com/fluxcd/pkg/runtime/transform"
 
@@ -149,6 +152,7 @@ const (
 
 // ChartValuesFromReferences attempts to construct new chart values by resolving
 // the provided references using the client, merging them in the order given.
+// References without a namespace are resolved in the given namespace.
 // If provided, the values map is merged in last overwriting values from references,
 // unless a reference has a targetPath specified, in which case it will overwrite all.
 // It returns the merged values, or an ErrValuesReference error.
@@ -161,7 +165,7 @@ func ChartValuesFromReferences(ctx context.Context, client kubeclient.Client, na
 	resources := make(map[string]kubeclient.Object)
 
 	for _, ref := range refs {
-		namespacedName := types.NamespacedName{Namespace: namespace, Name: ref.Name}
+		namespacedName := types.NamespacedName{Namespace: ref.GetNamespace(namespace), Name: ref.Name}
 		var valuesData []byte
 
 		switch ref.Kind {
@@ -237,12 +241,37 @@ func ChartValuesFromReferences(ctx context.Context, client kubeclient.Client, na
 		if ref.TargetPath != "" {
 			result = transform.MergeMaps(result, values)
 
-			// TODO(hidde): this is a bit of hack, as it mimics the way the option string is passed
-			// 	to Helm from a CLI perspective. Given the parser is however not publicly accessible
-			// 	while it contains all logic around parsing the target path, it is a fair trade-off.
-			if err := ReplacePathValue(result, ref.TargetPath, string(valuesData)); err != nil {
+			switch ref.ValueType {
+			case v2.ValueTypeJSON:
+				var value interface{}
+				if err := json.Unmarshal(valuesData, &value); err != nil {
+					return nil, NewErrValuesReference(namespacedName, ref, ErrValuesDataRead,
+						fmt.Errorf("invalid JSON value: %w", err))
+				}
+				if err := SetPathValue(result, ref.TargetPath, value); err != nil {
+					return nil, NewErrValuesReference(namespacedName, ref, ErrValueMerge, err)
+				}
+			case v2.ValueTypeFile:
+				if err := SetPathValue(result, ref.TargetPath, string(valuesData)); err != nil {
+					return nil, NewErrValuesReference(namespacedName, ref, ErrValueMerge, err)
+				}
+			default:
+				// TODO(hidde): this is a bit of hack, as it mimics the way the option string is passed
+				// 	to Helm from a CLI perspective. Given the parser is however not publicly accessible
+				// 	while it contains all logic around parsing the target path, it is a fair trade-off.
+				if err := ReplacePathValue(result, ref.TargetPath, string(valuesData)); err != nil {
+					return nil, NewErrValuesReference(namespacedName, ref, ErrValueMerge, err)
+				}
+			}
+			continue
+		}
+
+		if ref.GetMergeStrategy() == v2.MergeStrategyJSONPatch {
+			patched, err := PatchValues(result, valuesData)
+			if err != nil {
 				return nil, NewErrValuesReference(namespacedName, ref, ErrValueMerge, err)
 			}
+			result = patched
 			continue
 		}
 
@@ -250,11 +279,45 @@ func ChartValuesFromReferences(ctx context.Context, client kubeclient.Client, na
 		if err != nil {
 			return nil, NewErrValuesReference(namespacedName, ref, ErrValuesDataRead, err)
 		}
+		if ref.GetMergeStrategy() == v2.MergeStrategyReplace {
+			for k, v := range values {
+				result[k] = v
+			}
+			continue
+		}
 		result = transform.MergeMaps(result, values)
 	}
 	return transform.MergeMaps(result, values), nil
 }
 
+// PatchValues applies the JSON Patch (RFC 6902) document in the given JSON or
+// YAML data to a copy of the values, and returns the result.
+func PatchValues(values chartutil.Values, data []byte) (chartutil.Values, error) {
+	patchJSON, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read patch: %w", err)
+	}
+	patch, err := jsonpatch.DecodePatch(patchJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode patch: %w", err)
+	}
+
+	valuesJSON, err := json.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	patchedJSON, err := patch.Apply(valuesJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply patch: %w", err)
+	}
+
+	patched := chartutil.Values{}
+	if err = json.Unmarshal(patchedJSON, &patched); err != nil {
+		return nil, err
+	}
+	return patched, nil
+}
+
 // ReplacePathValue replaces the value at the dot notation path with the given
 // value using Helm's string value parser using strvals.ParseInto. Single or
 // double-quoted values are merged using strvals.ParseIntoString.
@@ -273,3 +336,15 @@ func ReplacePathValue(values chartutil.Values, path string, value string) error
 	value = path + "=" + value
 	return strvals.ParseInto(value, values)
 }
+
+// SetPathValue sets the given value as-is at the dot notation path, using
+// Helm's file value parser strvals.ParseIntoFile. This mirrors Helm's
+// --set-file flag, and its --set-json flag for a value parsed from JSON.
+func SetPathValue(values chartutil.Values, path string, value interface{}) error {
+	// The parser passes the runes after the "=" to the reader, which are
+	// the path of the file to read for --set-file. As the value is given,
+	// a placeholder is used.
+	return strvals.ParseIntoFile(path+"=-", values, func([]rune) (interface{}, error) {
+		return value, nil
+	})
+}
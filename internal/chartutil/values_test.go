@@ -42,6 +42,7 @@ func TestChartValuesFromReferences(t *testing.T) {
 		values     string
 		want       chartutil.Values
 		wantErr    bool
+		wantErrMsg string
 	}{
 		{
 			name: "merges",
@@ -80,6 +81,27 @@ other: values
 				"other":  "values",
 			},
 		},
+		{
+			name: "from other namespace",
+			resources: []runtime.Object{
+				mockConfigMap("values", map[string]string{"values.yaml": "local: value\n"}),
+				&corev1.ConfigMap{
+					TypeMeta:   metav1.TypeMeta{Kind: kindConfigMap, APIVersion: "v1"},
+					ObjectMeta: metav1.ObjectMeta{Name: "values", Namespace: "config"},
+					Data:       map[string]string{"values.yaml": "shared: value\n"},
+				},
+			},
+			references: []v2.ValuesReference{
+				{
+					Kind:      kindConfigMap,
+					Name:      "values",
+					Namespace: "config",
+				},
+			},
+			want: chartutil.Values{
+				"shared": "value",
+			},
+		},
 		{
 			name: "with target path",
 			resources: []runtime.Object{
@@ -263,6 +285,249 @@ other: values
 			},
 			wantErr: true,
 		},
+		{
+			name: "deep merge replaces lists",
+			resources: []runtime.Object{
+				mockConfigMap("values", map[string]string{
+					"values.yaml": `list: [a, b]
+nested:
+  list: [a]
+  key: value
+`,
+					"override.yaml": `list: [c]
+nested:
+  list: [b]
+`,
+				}),
+			},
+			references: []v2.ValuesReference{
+				{
+					Kind: kindConfigMap,
+					Name: "values",
+				},
+				{
+					Kind:          kindConfigMap,
+					Name:          "values",
+					ValuesKey:     "override.yaml",
+					MergeStrategy: v2.MergeStrategyDeepMerge,
+				},
+			},
+			want: chartutil.Values{
+				"list": []interface{}{"c"},
+				"nested": map[string]interface{}{
+					"list": []interface{}{"b"},
+					"key":  "value",
+				},
+			},
+		},
+		{
+			name: "replace does not merge nested maps",
+			resources: []runtime.Object{
+				mockConfigMap("values", map[string]string{
+					"values.yaml": `list: [a, b]
+nested:
+  list: [a]
+  key: value
+`,
+					"override.yaml": `nested:
+  list: [b]
+`,
+				}),
+			},
+			references: []v2.ValuesReference{
+				{
+					Kind: kindConfigMap,
+					Name: "values",
+				},
+				{
+					Kind:          kindConfigMap,
+					Name:          "values",
+					ValuesKey:     "override.yaml",
+					MergeStrategy: v2.MergeStrategyReplace,
+				},
+			},
+			want: chartutil.Values{
+				"list": []interface{}{"a", "b"},
+				"nested": map[string]interface{}{
+					"list": []interface{}{"b"},
+				},
+			},
+		},
+		{
+			name: "json patch appends to lists",
+			resources: []runtime.Object{
+				mockConfigMap("values", map[string]string{
+					"values.yaml": `list: [a, b]
+nested:
+  list: [a]
+  key: value
+`,
+					"patch.yaml": `- op: add
+  path: /list/-
+  value: c
+- op: add
+  path: /nested/list/0
+  value: b
+- op: remove
+  path: /nested/key
+`,
+				}),
+			},
+			references: []v2.ValuesReference{
+				{
+					Kind: kindConfigMap,
+					Name: "values",
+				},
+				{
+					Kind:          kindConfigMap,
+					Name:          "values",
+					ValuesKey:     "patch.yaml",
+					MergeStrategy: v2.MergeStrategyJSONPatch,
+				},
+			},
+			values: `
+other: values
+`,
+			want: chartutil.Values{
+				"list": []interface{}{"a", "b", "c"},
+				"nested": map[string]interface{}{
+					"list": []interface{}{"b", "a"},
+				},
+				"other": "values",
+			},
+		},
+		{
+			name: "json patch of missing path",
+			resources: []runtime.Object{
+				mockConfigMap("values", map[string]string{
+					"patch.yaml": `- op: replace
+  path: /missing
+  value: c
+`,
+				}),
+			},
+			references: []v2.ValuesReference{
+				{
+					Kind:          kindConfigMap,
+					Name:          "values",
+					ValuesKey:     "patch.yaml",
+					MergeStrategy: v2.MergeStrategyJSONPatch,
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "json value at target path",
+			resources: []runtime.Object{
+				mockConfigMap("values", map[string]string{
+					"config.json": `{"replicas": 2, "hosts": ["a", "b"], "tls": {"enabled": true}}`,
+				}),
+			},
+			references: []v2.ValuesReference{
+				{
+					Kind:       kindConfigMap,
+					Name:       "values",
+					ValuesKey:  "config.json",
+					TargetPath: "app.config",
+					ValueType:  v2.ValueTypeJSON,
+				},
+			},
+			values: `
+app:
+  config:
+    replicas: 1
+  name: app
+`,
+			want: chartutil.Values{
+				"app": map[string]interface{}{
+					"config": map[string]interface{}{
+						"replicas": float64(2),
+						"hosts":    []interface{}{"a", "b"},
+						"tls": map[string]interface{}{
+							"enabled": true,
+						},
+					},
+					"name": "app",
+				},
+			},
+		},
+		{
+			name: "json value at list item",
+			resources: []runtime.Object{
+				mockSecret("values", map[string][]byte{"item.json": []byte(`{"name": "b"}`)}),
+			},
+			references: []v2.ValuesReference{
+				{
+					Kind:       kindSecret,
+					Name:       "values",
+					ValuesKey:  "item.json",
+					TargetPath: "items[1]",
+					ValueType:  v2.ValueTypeJSON,
+				},
+			},
+			want: chartutil.Values{
+				"items": []interface{}{nil, map[string]interface{}{"name": "b"}},
+			},
+		},
+		{
+			name: "malformed json value",
+			resources: []runtime.Object{
+				mockConfigMap("values", map[string]string{"config.json": `{"replicas": 2,`}),
+			},
+			references: []v2.ValuesReference{
+				{
+					Kind:       kindConfigMap,
+					Name:       "values",
+					ValuesKey:  "config.json",
+					TargetPath: "app.config",
+					ValueType:  v2.ValueTypeJSON,
+				},
+			},
+			wantErr:    true,
+			wantErrMsg: "could not resolve ConfigMap chart values reference '/values' with key 'config.json': invalid JSON value: unexpected end of JSON input",
+		},
+		{
+			name: "file value at target path",
+			resources: []runtime.Object{
+				mockSecret("values", map[string][]byte{
+					"ca.crt": []byte("-----BEGIN CERTIFICATE-----\nMII,{a}=b\n-----END CERTIFICATE-----\n"),
+				}),
+			},
+			references: []v2.ValuesReference{
+				{
+					Kind:       kindSecret,
+					Name:       "values",
+					ValuesKey:  "ca.crt",
+					TargetPath: "tls.ca",
+					ValueType:  v2.ValueTypeFile,
+				},
+			},
+			want: chartutil.Values{
+				"tls": map[string]interface{}{
+					"ca": "-----BEGIN CERTIFICATE-----\nMII,{a}=b\n-----END CERTIFICATE-----\n",
+				},
+			},
+		},
+		{
+			name: "file value of json",
+			resources: []runtime.Object{
+				mockConfigMap("values", map[string]string{"config.json": `{"replicas": 2}`}),
+			},
+			references: []v2.ValuesReference{
+				{
+					Kind:       kindConfigMap,
+					Name:       "values",
+					ValuesKey:  "config.json",
+					TargetPath: "app.config",
+					ValueType:  v2.ValueTypeFile,
+				},
+			},
+			want: chartutil.Values{
+				"app": map[string]interface{}{
+					"config": `{"replicas": 2}`,
+				},
+			},
+		},
 		{
 			name: "invalid values",
 			resources: []runtime.Object{
@@ -296,6 +561,9 @@ invalid`,
 			got, err := ChartValuesFromReferences(ctx, c.Build(), tt.namespace, values, tt.references...)
 			if tt.wantErr {
 				g.Expect(err).To(HaveOccurred())
+				if tt.wantErrMsg != "" {
+					g.Expect(err.Error()).To(Equal(tt.wantErrMsg))
+				}
 				g.Expect(got).To(BeNil())
 				return
 			}
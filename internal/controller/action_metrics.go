@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// actionDurationSeconds records the duration of the Helm action which
+// produced the latest release of a HelmRelease, as observed in the
+// Status.History.
+var actionDurationSeconds = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "gotk_release_action_duration_seconds",
+		Help: "The duration of the Helm action which produced the latest release of an object.",
+	},
+	[]string{"kind", "name", "namespace"},
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(actionDurationSeconds)
+}
+
+// recordActionDuration sets the actionDurationSeconds metric for the given
+// HelmRelease to the Duration of the latest Snapshot in its Status.History.
+// The metric is left untouched if the latest Snapshot has no Duration, which
+// is the case for releases which were not produced by the controller.
+func recordActionDuration(obj *v2.HelmRelease) {
+	latest := obj.Status.History.Latest()
+	if latest == nil || latest.Duration == nil {
+		return
+	}
+	actionDurationSeconds.WithLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace).Set(latest.Duration.Seconds())
+}
+
+// deleteActionDuration removes the actionDurationSeconds metric for the
+// given HelmRelease.
+func deleteActionDuration(obj *v2.HelmRelease) {
+	actionDurationSeconds.DeleteLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)
+}
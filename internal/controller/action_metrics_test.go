@@ -0,0 +1,60 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func Test_recordActionDuration(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "action-duration",
+			Namespace: "default",
+		},
+	}
+	gauge := actionDurationSeconds.WithLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)
+	t.Cleanup(func() { deleteActionDuration(obj) })
+
+	// Without history, nothing is recorded.
+	recordActionDuration(obj)
+	g.Expect(promtestutil.ToFloat64(gauge)).To(BeZero())
+
+	obj.Status.History = v2.Snapshots{
+		{Version: 2, Duration: &metav1.Duration{Duration: 90 * time.Second}},
+		{Version: 1, Duration: &metav1.Duration{Duration: 30 * time.Second}},
+	}
+	recordActionDuration(obj)
+	g.Expect(promtestutil.ToFloat64(gauge)).To(Equal(float64(90)))
+
+	// A latest release without duration does not reset the metric.
+	obj.Status.History = append(v2.Snapshots{{Version: 3}}, obj.Status.History...)
+	recordActionDuration(obj)
+	g.Expect(promtestutil.ToFloat64(gauge)).To(Equal(float64(90)))
+
+	deleteActionDuration(obj)
+	g.Expect(actionDurationSeconds.DeleteLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)).To(BeFalse())
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/fluxcd/pkg/runtime/conditions"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcev1beta2 "github.com/fluxcd/source-controller/api/v1beta2"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/digest"
+)
+
+// verifyChart verifies the signature of the artifact with the given OCI
+// digest of the given source as configured in the ChartRef of the given
+// object, unless the latest release of the object has been verified for the
+// same digest with the same verification configuration. It returns the
+// verified digest, and the digest of the verification configuration.
+func (r *HelmReleaseReconciler) verifyChart(ctx context.Context, obj *v2.HelmRelease, source sourcev1.Source,
+	ociDigest string) (string, string, error) {
+	trusted, err := r.getTrustedPublicKeys(ctx, obj)
+	if err != nil {
+		return "", "", err
+	}
+	verifyDigest, err := verificationDigest(obj.Spec.ChartRef.Verify, trusted)
+	if err != nil {
+		return "", "", err
+	}
+
+	if latest := obj.Status.History.Latest(); ociDigest != "" && latest != nil &&
+		latest.VerifiedDigest == ociDigest && latest.VerificationDigest == verifyDigest {
+		return ociDigest, verifyDigest, nil
+	}
+	if err = r.verifyChartRef(ctx, obj, source, trusted); err != nil {
+		return "", "", err
+	}
+	return ociDigest, verifyDigest, nil
+}
+
+// getTrustedPublicKeys returns the Secret with the trusted public keys
+// referenced in the verification configuration of the ChartRef of the given
+// object, or nil if none is referenced.
+func (r *HelmReleaseReconciler) getTrustedPublicKeys(ctx context.Context, obj *v2.HelmRelease) (*corev1.Secret, error) {
+	verify := obj.Spec.ChartRef.Verify
+	if verify.SecretRef == nil {
+		return nil, nil
+	}
+	trusted := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: obj.Namespace, Name: verify.SecretRef.Name}, trusted); err != nil {
+		return nil, fmt.Errorf("failed to get trusted public keys Secret '%s': %w", verify.SecretRef.Name, err)
+	}
+	return trusted, nil
+}
+
+// verifyChartRef verifies that the signature of the artifact of the given
+// source has been verified as configured in the ChartRef of the given object,
+// with the given Secret holding the trusted public keys (which may be nil).
+//
+// The helm-controller does not pull the artifact from the registry itself,
+// hence it relies on the source-controller to verify the signature. It
+// confirms the source is configured to verify the signature with the same
+// provider, using only trusted public keys from the Secret referenced in the
+// ChartRef, and that the source reports the verification of its current
+// artifact to have succeeded.
+func (r *HelmReleaseReconciler) verifyChartRef(ctx context.Context, obj *v2.HelmRelease, source sourcev1.Source, trusted *corev1.Secret) error {
+	or, ok := source.(*sourcev1beta2.OCIRepository)
+	if !ok {
+		return fmt.Errorf("signature verification is only supported for %s chart references", sourcev1beta2.OCIRepositoryKind)
+	}
+
+	var sourceTrusted *corev1.Secret
+	if trusted != nil && or.Spec.Verify != nil && or.Spec.Verify.SecretRef != nil {
+		sourceTrusted = &corev1.Secret{}
+		if err := r.Client.Get(ctx, types.NamespacedName{Namespace: or.Namespace, Name: or.Spec.Verify.SecretRef.Name}, sourceTrusted); err != nil {
+			return fmt.Errorf("failed to get public keys Secret '%s' of %s '%s/%s': %w",
+				or.Spec.Verify.SecretRef.Name, sourcev1beta2.OCIRepositoryKind, or.Namespace, or.Name, err)
+		}
+	}
+	return verifyOCIRepository(obj.Spec.ChartRef.Verify, or, trusted, sourceTrusted)
+}
+
+// verificationDigest returns the digest of the given ChartRefVerification
+// and the data of the given Secret with the trusted public keys (which may
+// be nil). A change of the digest requires the artifact to be verified
+// again.
+func verificationDigest(verify *v2.ChartRefVerification, trusted *corev1.Secret) (string, error) {
+	var data map[string][]byte
+	if trusted != nil {
+		data = trusted.Data
+	}
+	b, err := json.Marshal(struct {
+		Verify *v2.ChartRefVerification `json:"verify"`
+		Data   map[string][]byte        `json:"data,omitempty"`
+	}{verify, data})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute digest of verification configuration: %w", err)
+	}
+	return digest.Canonical.FromBytes(b).String(), nil
+}
+
+// verifyOCIRepository verifies that the given OCIRepository is configured to
+// verify the signature of its artifact as described by the given
+// ChartRefVerification, and that the verification of its current artifact
+// succeeded. If trusted is not nil, the public keys in sourceTrusted, which is
+// the Secret referenced by the OCIRepository, must all be present in trusted.
+func verifyOCIRepository(verify *v2.ChartRefVerification, or *sourcev1beta2.OCIRepository, trusted, sourceTrusted *corev1.Secret) error {
+	ref := fmt.Sprintf("%s '%s/%s'", sourcev1beta2.OCIRepositoryKind, or.Namespace, or.Name)
+
+	if or.Spec.Verify == nil || or.Spec.Verify.Provider != verify.Provider {
+		return fmt.Errorf("%s is not configured to verify the signature of the artifact using provider '%s'", ref, verify.Provider)
+	}
+
+	if trusted != nil {
+		if sourceTrusted == nil || len(sourceTrusted.Data) == 0 {
+			return fmt.Errorf("%s is not configured to verify the signature of the artifact using trusted public keys", ref)
+		}
+		keys := secretDataDigests(trusted)
+		for k, v := range sourceTrusted.Data {
+			if !keys[fmt.Sprintf("%x", sha256.Sum256(v))] {
+				return fmt.Errorf("%s trusts public key '%s' which is not in Secret '%s'", ref, k, trusted.Name)
+			}
+		}
+	}
+
+	cond := conditions.Get(or, sourcev1.SourceVerifiedCondition)
+	switch {
+	case cond == nil:
+		return fmt.Errorf("signature of the artifact of %s has not been verified", ref)
+	case cond.Status != metav1.ConditionTrue:
+		return fmt.Errorf("signature verification of the artifact of %s failed: %s", ref, cond.Message)
+	case cond.ObservedGeneration != or.Generation:
+		return fmt.Errorf("signature verification of the artifact of %s is not up-to-date", ref)
+	case or.GetArtifact() == nil || !strings.Contains(cond.Message, revisionDigest(or.GetArtifact().Revision)):
+		return fmt.Errorf("signature verification of %s does not refer to the revision of its current artifact", ref)
+	}
+	return nil
+}
+
+// revisionDigest returns the digest of the given artifact revision in the
+// format "<tag>@<digest>", or the revision itself if it holds no digest.
+func revisionDigest(revision string) string {
+	if i := strings.LastIndex(revision, "@"); i >= 0 {
+		return revision[i+1:]
+	}
+	return revision
+}
+
+// secretDataDigests returns the set of digests of the values of the data of
+// the given Secret.
+func secretDataDigests(secret *corev1.Secret) map[string]bool {
+	digests := make(map[string]bool, len(secret.Data))
+	for _, v := range secret.Data {
+		digests[fmt.Sprintf("%x", sha256.Sum256(v))] = true
+	}
+	return digests
+}
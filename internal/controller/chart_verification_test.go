@@ -0,0 +1,226 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcev1beta2 "github.com/fluxcd/source-controller/api/v1beta2"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func Test_verifyOCIRepository(t *testing.T) {
+	verified := []metav1.Condition{{
+		Type:               sourcev1.SourceVerifiedCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: 2,
+		Message:            "verified signature of revision latest@sha256:abc",
+	}}
+
+	tests := []struct {
+		name          string
+		verify        *v2.ChartRefVerification
+		sourceVerify  *sourcev1.OCIRepositoryVerification
+		conditions    []metav1.Condition
+		trusted       *corev1.Secret
+		sourceTrusted *corev1.Secret
+		wantErr       string
+	}{
+		{
+			name:         "verified",
+			verify:       &v2.ChartRefVerification{Provider: "cosign"},
+			sourceVerify: &sourcev1.OCIRepositoryVerification{Provider: "cosign"},
+			conditions:   verified,
+		},
+		{
+			name:    "source without verification",
+			verify:  &v2.ChartRefVerification{Provider: "cosign"},
+			wantErr: "is not configured to verify the signature of the artifact using provider 'cosign'",
+		},
+		{
+			name:         "provider mismatch",
+			verify:       &v2.ChartRefVerification{Provider: "notation"},
+			sourceVerify: &sourcev1.OCIRepositoryVerification{Provider: "cosign"},
+			conditions:   verified,
+			wantErr:      "using provider 'notation'",
+		},
+		{
+			name: "trusted public keys",
+			verify: &v2.ChartRefVerification{
+				Provider:  "cosign",
+				SecretRef: &meta.LocalObjectReference{Name: "keys"},
+			},
+			sourceVerify: &sourcev1.OCIRepositoryVerification{
+				Provider:  "cosign",
+				SecretRef: &meta.LocalObjectReference{Name: "source-keys"},
+			},
+			conditions: verified,
+			trusted: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "keys"},
+				Data:       map[string][]byte{"a.pub": []byte("a"), "b.pub": []byte("b")},
+			},
+			sourceTrusted: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "source-keys"},
+				Data:       map[string][]byte{"other.pub": []byte("b")},
+			},
+		},
+		{
+			name: "untrusted public key",
+			verify: &v2.ChartRefVerification{
+				Provider:  "cosign",
+				SecretRef: &meta.LocalObjectReference{Name: "keys"},
+			},
+			sourceVerify: &sourcev1.OCIRepositoryVerification{
+				Provider:  "cosign",
+				SecretRef: &meta.LocalObjectReference{Name: "source-keys"},
+			},
+			conditions: verified,
+			trusted: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "keys"},
+				Data:       map[string][]byte{"a.pub": []byte("a")},
+			},
+			sourceTrusted: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "source-keys"},
+				Data:       map[string][]byte{"a.pub": []byte("a"), "c.pub": []byte("c")},
+			},
+			wantErr: "trusts public key 'c.pub' which is not in Secret 'keys'",
+		},
+		{
+			name: "source without public keys",
+			verify: &v2.ChartRefVerification{
+				Provider:  "cosign",
+				SecretRef: &meta.LocalObjectReference{Name: "keys"},
+			},
+			sourceVerify: &sourcev1.OCIRepositoryVerification{Provider: "cosign"},
+			conditions:   verified,
+			trusted: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "keys"},
+				Data:       map[string][]byte{"a.pub": []byte("a")},
+			},
+			wantErr: "using trusted public keys",
+		},
+		{
+			name:         "not verified",
+			verify:       &v2.ChartRefVerification{Provider: "cosign"},
+			sourceVerify: &sourcev1.OCIRepositoryVerification{Provider: "cosign"},
+			wantErr:      "has not been verified",
+		},
+		{
+			name:         "verification failed",
+			verify:       &v2.ChartRefVerification{Provider: "cosign"},
+			sourceVerify: &sourcev1.OCIRepositoryVerification{Provider: "cosign"},
+			conditions: []metav1.Condition{{
+				Type:               sourcev1.SourceVerifiedCondition,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 2,
+				Message:            "no matching signatures",
+			}},
+			wantErr: "failed: no matching signatures",
+		},
+		{
+			name:         "verification outdated",
+			verify:       &v2.ChartRefVerification{Provider: "cosign"},
+			sourceVerify: &sourcev1.OCIRepositoryVerification{Provider: "cosign"},
+			conditions: []metav1.Condition{{
+				Type:               sourcev1.SourceVerifiedCondition,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: 1,
+			}},
+			wantErr: "is not up-to-date",
+		},
+		{
+			name:         "verification of another revision",
+			verify:       &v2.ChartRefVerification{Provider: "cosign"},
+			sourceVerify: &sourcev1.OCIRepositoryVerification{Provider: "cosign"},
+			conditions: []metav1.Condition{{
+				Type:               sourcev1.SourceVerifiedCondition,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: 2,
+				Message:            "verified signature of revision latest@sha256:def",
+			}},
+			wantErr: "does not refer to the revision of its current artifact",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			or := &sourcev1beta2.OCIRepository{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:       "chart",
+					Namespace:  "flux-system",
+					Generation: 2,
+				},
+				Spec: sourcev1beta2.OCIRepositorySpec{
+					Verify: tt.sourceVerify,
+				},
+				Status: sourcev1beta2.OCIRepositoryStatus{
+					Conditions: tt.conditions,
+					Artifact:   &sourcev1.Artifact{Revision: "latest@sha256:abc"},
+				},
+			}
+
+			err := verifyOCIRepository(tt.verify, or, tt.trusted, tt.sourceTrusted)
+			if tt.wantErr != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tt.wantErr))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+func Test_verificationDigest(t *testing.T) {
+	g := NewWithT(t)
+
+	verify := &v2.ChartRefVerification{
+		Provider:  "cosign",
+		SecretRef: &meta.LocalObjectReference{Name: "keys"},
+	}
+	trusted := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "keys"},
+		Data:       map[string][]byte{"a.pub": []byte("a")},
+	}
+
+	d, err := verificationDigest(verify, trusted)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(d).ToNot(BeEmpty())
+
+	same, err := verificationDigest(verify.DeepCopy(), trusted.DeepCopy())
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(same).To(Equal(d))
+
+	rotated := trusted.DeepCopy()
+	rotated.Data["a.pub"] = []byte("b")
+	other, err := verificationDigest(verify, rotated)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(other).ToNot(Equal(d))
+
+	provider := verify.DeepCopy()
+	provider.Provider = "notation"
+	other, err = verificationDigest(provider, trusted)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(other).ToNot(Equal(d))
+}
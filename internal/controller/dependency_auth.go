@@ -0,0 +1,129 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/loader"
+)
+
+// dependencyRepositoryAuth returns a loader.RepositoryAuthFunc which resolves
+// the credentials of a chart repository from the HelmRepository with the same
+// URL in the namespace of the given HelmRelease, the same way the
+// source-controller does for the chart itself. Repositories without a
+// matching HelmRepository are accessed anonymously.
+func (r *HelmReleaseReconciler) dependencyRepositoryAuth(obj *v2.HelmRelease) loader.RepositoryAuthFunc {
+	return func(ctx context.Context, repoURL string) (*loader.RepositoryAuth, error) {
+		var repos sourcev1.HelmRepositoryList
+		if err := r.Client.List(ctx, &repos, client.InNamespace(obj.Namespace)); err != nil {
+			return nil, fmt.Errorf("failed to list %s objects: %w", sourcev1.HelmRepositoryKind, err)
+		}
+		for i := range repos.Items {
+			repo := &repos.Items[i]
+			if strings.TrimSuffix(repo.Spec.URL, "/") != strings.TrimSuffix(repoURL, "/") {
+				continue
+			}
+			if repo.Spec.SecretRef == nil && repo.Spec.CertSecretRef == nil {
+				return nil, nil
+			}
+			return r.helmRepositoryAuth(ctx, repo)
+		}
+		return nil, nil
+	}
+}
+
+// helmRepositoryAuth returns the loader.RepositoryAuth configured by the
+// Secrets referenced by the given HelmRepository. The identity of the
+// credentials is composed of the UID of the HelmRepository, and the UID and
+// resource version of the Secrets.
+func (r *HelmReleaseReconciler) helmRepositoryAuth(ctx context.Context, repo *sourcev1.HelmRepository) (*loader.RepositoryAuth, error) {
+	auth := &loader.RepositoryAuth{PassCredentials: repo.Spec.PassCredentials}
+	identity := []string{string(repo.UID)}
+	if repo.Spec.SecretRef != nil {
+		secret, err := r.getRepositorySecret(ctx, repo, repo.Spec.SecretRef.Name)
+		if err != nil {
+			return nil, err
+		}
+		auth.Username, auth.Password = string(secret.Data["username"]), string(secret.Data["password"])
+		identity = append(identity, string(secret.UID)+"@"+secret.ResourceVersion)
+	}
+	if repo.Spec.CertSecretRef != nil {
+		secret, err := r.getRepositorySecret(ctx, repo, repo.Spec.CertSecretRef.Name)
+		if err != nil {
+			return nil, err
+		}
+		identity = append(identity, string(secret.UID)+"@"+secret.ResourceVersion)
+		if auth.TLSConfig, err = tlsConfigFromSecret(secret); err != nil {
+			return nil, fmt.Errorf("invalid TLS data in Secret '%s' of %s '%s/%s': %w",
+				secret.Name, sourcev1.HelmRepositoryKind, repo.Namespace, repo.Name, err)
+		}
+	}
+	auth.Identity = strings.Join(identity, ",")
+	return auth, nil
+}
+
+// getRepositorySecret returns the Secret with the given name in the namespace
+// of the given HelmRepository.
+func (r *HelmReleaseReconciler) getRepositorySecret(ctx context.Context, repo *sourcev1.HelmRepository, name string) (*corev1.Secret, error) {
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: repo.Namespace, Name: name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get Secret '%s' of %s '%s/%s': %w",
+			name, sourcev1.HelmRepositoryKind, repo.Namespace, repo.Name, err)
+	}
+	return secret, nil
+}
+
+// tlsConfigFromSecret returns the TLS configuration held by the "ca.crt",
+// "tls.crt" and "tls.key" keys of the given Secret.
+func tlsConfigFromSecret(secret *corev1.Secret) (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if ca, ok := secret.Data["ca.crt"]; ok {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New("failed to parse CA certificate")
+		}
+		cfg.RootCAs = pool
+	}
+
+	certPEM, hasCert := secret.Data["tls.crt"]
+	keyPEM, hasKey := secret.Data["tls.key"]
+	switch {
+	case hasCert && hasKey:
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	case hasCert || hasKey:
+		return nil, errors.New("both 'tls.crt' and 'tls.key' must be set for a client certificate")
+	}
+	return cfg, nil
+}
@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v3/pkg/chart"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	intacl "github.com/fluxcd/helm-controller/internal/acl"
+	"github.com/fluxcd/helm-controller/internal/digest"
+	"github.com/fluxcd/helm-controller/internal/loader"
+)
+
+const (
+	// embeddedChartConfigMapKind is the ChartRef kind of a chart embedded
+	// in a ConfigMap.
+	embeddedChartConfigMapKind = "ConfigMap"
+	// embeddedChartSecretKind is the ChartRef kind of a chart embedded in a
+	// Secret.
+	embeddedChartSecretKind = "Secret"
+)
+
+// embeddedChartSource is a sourcev1.Source for a packaged chart embedded in
+// the data of a ConfigMap or Secret. The artifact of the source is composed
+// from the digest of the embedded data, and has no URL to fetch it from.
+type embeddedChartSource struct {
+	client.Object
+
+	data     []byte
+	artifact *sourcev1.Artifact
+}
+
+// GetRequeueAfter returns zero, as the source is not reconciled.
+func (s *embeddedChartSource) GetRequeueAfter() time.Duration {
+	return 0
+}
+
+// GetArtifact returns the artifact composed from the embedded data.
+func (s *embeddedChartSource) GetArtifact() *sourcev1.Artifact {
+	return s.artifact
+}
+
+// Load loads the embedded chart. It returns an error of type
+// loader.ErrInvalidEmbeddedChart if the data is malformed or exceeds
+// loader.MaxEmbeddedChartSize.
+func (s *embeddedChartSource) Load() (*chart.Chart, error) {
+	return loader.LoadEmbeddedChart(s.data, loader.MaxEmbeddedChartSize)
+}
+
+// isEmbeddedChartKind returns true if the given ChartRef kind refers to a
+// chart embedded in a ConfigMap or Secret.
+func isEmbeddedChartKind(kind string) bool {
+	return kind == embeddedChartConfigMapKind || kind == embeddedChartSecretKind
+}
+
+// embeddedChartIndexValue returns the v2.EmbeddedChartIndexKey value of the
+// ConfigMap or Secret of the given kind with the given reference.
+func embeddedChartIndexValue(kind string, ref types.NamespacedName) string {
+	return kind + "/" + ref.String()
+}
+
+// indexEmbeddedChartRef returns the v2.EmbeddedChartIndexKey values of the
+// given HelmRelease, which is the ConfigMap or Secret its ChartRef refers
+// to. It returns nil if the chart is not embedded.
+func indexEmbeddedChartRef(o client.Object) []string {
+	obj, ok := o.(*v2.HelmRelease)
+	if !ok || !obj.HasChartRef() || !isEmbeddedChartKind(obj.Spec.ChartRef.Kind) {
+		return nil
+	}
+	namespacedName, err := getNamespacedName(obj)
+	if err != nil {
+		return nil
+	}
+	return []string{embeddedChartIndexValue(obj.Spec.ChartRef.Kind, namespacedName)}
+}
+
+// EmbeddedChartCacheByObject returns the cache configuration which restricts
+// the ConfigMaps and Secrets watched for changes to embedded charts to the
+// ones labeled with v2.EmbeddedChartLabel. It must not be used when
+// ConfigMaps and Secrets are read from the cache, as the reads would then be
+// restricted as well.
+func EmbeddedChartCacheByObject() map[client.Object]cache.ByObject {
+	selector := labels.SelectorFromSet(labels.Set{v2.EmbeddedChartLabel: v2.EmbeddedChartEnabledValue})
+	return map[client.Object]cache.ByObject{
+		&corev1.ConfigMap{}: {Label: selector},
+		&corev1.Secret{}:    {Label: selector},
+	}
+}
+
+// requestsForEmbeddedChartChange returns a handler.MapFunc which returns the
+// requests for the HelmReleases with a chart embedded in the changed
+// ConfigMap or Secret of the given kind.
+func (r *HelmReleaseReconciler) requestsForEmbeddedChartChange(kind string) handler.MapFunc {
+	return func(ctx context.Context, o client.Object) []reconcile.Request {
+		var list v2.HelmReleaseList
+		if err := r.List(ctx, &list, client.MatchingFields{
+			v2.EmbeddedChartIndexKey: embeddedChartIndexValue(kind, client.ObjectKeyFromObject(o)),
+		}); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, fmt.Sprintf("failed to list HelmReleases for %s change", kind))
+			return nil
+		}
+
+		var reqs []reconcile.Request
+		for i := range list.Items {
+			reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+		}
+		return reqs
+	}
+}
+
+// getSourceFromEmbeddedRef returns an embeddedChartSource for the ConfigMap
+// or Secret referenced by the ChartRef of the given HelmRelease. It returns
+// an error if the referent can not be accessed, or does not have the
+// configured key.
+func (r *HelmReleaseReconciler) getSourceFromEmbeddedRef(ctx context.Context, obj *v2.HelmRelease) (sourcev1.Source, error) {
+	name, namespace := obj.Spec.ChartRef.Name, obj.Spec.ChartRef.Namespace
+	if namespace == "" {
+		namespace = obj.GetNamespace()
+	}
+	ref := types.NamespacedName{Namespace: namespace, Name: name}
+	kind, key := obj.Spec.ChartRef.Kind, obj.Spec.ChartRef.GetKey()
+
+	if err := intacl.AllowsAccessTo(obj, kind, ref); err != nil {
+		return nil, err
+	}
+
+	var (
+		o    client.Object
+		data []byte
+		ok   bool
+	)
+	switch kind {
+	case embeddedChartConfigMapKind:
+		cm := &corev1.ConfigMap{}
+		if err := r.Client.Get(ctx, ref, cm); err != nil {
+			return nil, err
+		}
+		if data, ok = cm.BinaryData[key]; !ok {
+			var s string
+			if s, ok = cm.Data[key]; ok {
+				data = []byte(s)
+			}
+		}
+		o = cm
+	case embeddedChartSecretKind:
+		secret := &corev1.Secret{}
+		if err := r.Client.Get(ctx, ref, secret); err != nil {
+			return nil, err
+		}
+		data, ok = secret.Data[key]
+		o = secret
+	default:
+		return nil, fmt.Errorf("unsupported embedded chart kind '%s'", kind)
+	}
+	if !ok {
+		return nil, fmt.Errorf("%s '%s' does not have a chart at key '%s'", kind, ref, key)
+	}
+
+	size := int64(len(data))
+	dig := digest.Canonical.FromBytes(data).String()
+	return &embeddedChartSource{
+		Object: o,
+		data:   data,
+		artifact: &sourcev1.Artifact{
+			Revision: dig,
+			Digest:   dig,
+			Size:     &size,
+		},
+	}, nil
+}
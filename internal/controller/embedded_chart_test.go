@@ -0,0 +1,220 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/fluxcd/pkg/runtime/acl"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/digest"
+	"github.com/fluxcd/helm-controller/internal/testutil"
+)
+
+func Test_getSourceFromEmbeddedRef(t *testing.T) {
+	g := NewWithT(t)
+
+	chartPath, err := testutil.SaveChart(testutil.BuildChart(), t.TempDir())
+	g.Expect(err).ToNot(HaveOccurred())
+	b, err := os.ReadFile(chartPath)
+	g.Expect(err).ToNot(HaveOccurred())
+	encoded := base64.StdEncoding.EncodeToString(b)
+
+	tests := []struct {
+		name       string
+		objects    []client.Object
+		ref        v2.CrossNamespaceSourceReference
+		wantData   []byte
+		wantErr    string
+		wantDenied bool
+	}{
+		{
+			name: "ConfigMap with binary data",
+			objects: []client.Object{&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "chart", Namespace: "mock"},
+				BinaryData: map[string][]byte{v2.DefaultEmbeddedChartKey: b},
+			}},
+			ref:      v2.CrossNamespaceSourceReference{Kind: "ConfigMap", Name: "chart"},
+			wantData: b,
+		},
+		{
+			name: "ConfigMap with base64 encoded data at custom key",
+			objects: []client.Object{&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "chart", Namespace: "mock"},
+				Data:       map[string]string{"hello.tgz": encoded},
+			}},
+			ref:      v2.CrossNamespaceSourceReference{Kind: "ConfigMap", Name: "chart", Key: "hello.tgz"},
+			wantData: []byte(encoded),
+		},
+		{
+			name: "Secret",
+			objects: []client.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "chart", Namespace: "mock"},
+				Data:       map[string][]byte{v2.DefaultEmbeddedChartKey: b},
+			}},
+			ref:      v2.CrossNamespaceSourceReference{Kind: "Secret", Name: "chart"},
+			wantData: b,
+		},
+		{
+			name: "missing key",
+			objects: []client.Object{&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "chart", Namespace: "mock"},
+				Data:       map[string][]byte{"other": b},
+			}},
+			ref:     v2.CrossNamespaceSourceReference{Kind: "Secret", Name: "chart"},
+			wantErr: "Secret 'mock/chart' does not have a chart at key 'chart.tgz'",
+		},
+		{
+			name:    "missing referent",
+			ref:     v2.CrossNamespaceSourceReference{Kind: "ConfigMap", Name: "chart"},
+			wantErr: "not found",
+		},
+		{
+			name:       "cross-namespace reference",
+			ref:        v2.CrossNamespaceSourceReference{Kind: "ConfigMap", Name: "chart", Namespace: "other"},
+			wantErr:    "cross-namespace references are not allowed",
+			wantDenied: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ref := tt.ref
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "mock"},
+				Spec:       v2.HelmReleaseSpec{ChartRef: &ref},
+			}
+
+			r := &HelmReleaseReconciler{
+				Client: fake.NewClientBuilder().
+					WithScheme(NewTestScheme()).
+					WithObjects(tt.objects...).
+					Build(),
+			}
+
+			got, err := r.getSource(context.TODO(), obj)
+			if tt.wantErr != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tt.wantErr))
+				g.Expect(acl.IsAccessDenied(err)).To(Equal(tt.wantDenied))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			embedded, ok := got.(*embeddedChartSource)
+			g.Expect(ok).To(BeTrue())
+			g.Expect(embedded.GetArtifact().Digest).To(Equal(digest.Canonical.FromBytes(tt.wantData).String()))
+			g.Expect(embedded.GetArtifact().Revision).To(Equal(embedded.GetArtifact().Digest))
+
+			ready, _ := isSourceReady(got)
+			g.Expect(ready).To(BeTrue())
+
+			c, err := embedded.Load()
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(c.Metadata.Name).To(Equal("hello"))
+			g.Expect(c.Metadata.Version).To(Equal("0.1.0"))
+		})
+	}
+}
+
+func TestHelmReleaseReconciler_requestsForEmbeddedChartChange(t *testing.T) {
+	g := NewWithT(t)
+
+	releases := []client.Object{
+		&v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{Name: "configmap", Namespace: "mock"},
+			Spec: v2.HelmReleaseSpec{
+				ChartRef: &v2.CrossNamespaceSourceReference{Kind: "ConfigMap", Name: "chart"},
+			},
+		},
+		&v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret", Namespace: "mock"},
+			Spec: v2.HelmReleaseSpec{
+				ChartRef: &v2.CrossNamespaceSourceReference{Kind: "Secret", Name: "chart"},
+			},
+		},
+		&v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{Name: "cross-namespace", Namespace: "other"},
+			Spec: v2.HelmReleaseSpec{
+				ChartRef: &v2.CrossNamespaceSourceReference{Kind: "ConfigMap", Name: "chart", Namespace: "mock"},
+			},
+		},
+		&v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{Name: "oci", Namespace: "mock"},
+			Spec: v2.HelmReleaseSpec{
+				ChartRef: &v2.CrossNamespaceSourceReference{Kind: "OCIRepository", Name: "chart"},
+			},
+		},
+	}
+
+	r := &HelmReleaseReconciler{
+		Client: fake.NewClientBuilder().
+			WithScheme(NewTestScheme()).
+			WithObjects(releases...).
+			WithIndex(&v2.HelmRelease{}, v2.EmbeddedChartIndexKey, indexEmbeddedChartRef).
+			Build(),
+	}
+
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "chart", Namespace: "mock"}}
+	g.Expect(r.requestsForEmbeddedChartChange("ConfigMap")(context.TODO(), cm)).To(ConsistOf(
+		reconcile.Request{NamespacedName: types.NamespacedName{Name: "configmap", Namespace: "mock"}},
+		reconcile.Request{NamespacedName: types.NamespacedName{Name: "cross-namespace", Namespace: "other"}},
+	))
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "chart", Namespace: "mock"}}
+	g.Expect(r.requestsForEmbeddedChartChange("Secret")(context.TODO(), secret)).To(ConsistOf(
+		reconcile.Request{NamespacedName: types.NamespacedName{Name: "secret", Namespace: "mock"}},
+	))
+
+	other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "mock"}}
+	g.Expect(r.requestsForEmbeddedChartChange("ConfigMap")(context.TODO(), other)).To(BeEmpty())
+}
+
+func TestEmbeddedChartCacheByObject(t *testing.T) {
+	g := NewWithT(t)
+
+	byObject := EmbeddedChartCacheByObject()
+	g.Expect(byObject).To(HaveLen(2))
+
+	var kinds []string
+	for obj, config := range byObject {
+		switch obj.(type) {
+		case *corev1.ConfigMap:
+			kinds = append(kinds, "ConfigMap")
+		case *corev1.Secret:
+			kinds = append(kinds, "Secret")
+		}
+		g.Expect(config.Label.Matches(labels.Set{v2.EmbeddedChartLabel: v2.EmbeddedChartEnabledValue})).To(BeTrue())
+		g.Expect(config.Label.Matches(labels.Set{v2.EmbeddedChartLabel: "disabled"})).To(BeFalse())
+		g.Expect(config.Label.Matches(labels.Set{})).To(BeFalse())
+	}
+	g.Expect(kinds).To(ConsistOf("ConfigMap", "Secret"))
+}
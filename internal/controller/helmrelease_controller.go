@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand/v2"
+	"slices"
 	"strings"
 	"time"
 
@@ -27,6 +29,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	apierrutil "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -62,24 +65,29 @@ import (
 	intacl "github.com/fluxcd/helm-controller/internal/acl"
 	"github.com/fluxcd/helm-controller/internal/action"
 	"github.com/fluxcd/helm-controller/internal/chartutil"
+	"github.com/fluxcd/helm-controller/internal/defaults"
 	"github.com/fluxcd/helm-controller/internal/digest"
 	interrors "github.com/fluxcd/helm-controller/internal/errors"
 	"github.com/fluxcd/helm-controller/internal/features"
+	"github.com/fluxcd/helm-controller/internal/hook"
 	"github.com/fluxcd/helm-controller/internal/kube"
 	"github.com/fluxcd/helm-controller/internal/loader"
 	"github.com/fluxcd/helm-controller/internal/postrender"
 	intpredicates "github.com/fluxcd/helm-controller/internal/predicates"
 	intreconcile "github.com/fluxcd/helm-controller/internal/reconcile"
 	"github.com/fluxcd/helm-controller/internal/release"
+	"github.com/fluxcd/helm-controller/internal/schedule"
+	"github.com/fluxcd/helm-controller/internal/validation"
 )
 
 // +kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleases,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleases/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleases/finalizers,verbs=get;create;update;patch;delete
-// +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=helmcharts,verbs=get;list;watch
+// +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=helmcharts,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=helmcharts/status,verbs=get
-// +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=ocirepositories,verbs=get;list;watch
+// +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=ocirepositories,verbs=get;list;watch;patch
 // +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=ocirepositories/status,verbs=get
+// +kubebuilder:rbac:groups=source.toolkit.fluxcd.io,resources=helmrepositories,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 // HelmReleaseReconciler reconciles a HelmRelease object.
@@ -95,20 +103,32 @@ type HelmReleaseReconciler struct {
 
 	FieldManager          string
 	DefaultServiceAccount string
+	SummaryEvents         bool
+
+	// TimelineEventRecorder records the start and end of each Helm action
+	// as native Kubernetes events, when set.
+	TimelineEventRecorder kuberecorder.EventRecorder
 
 	requeueDependency    time.Duration
 	artifactFetchRetries int
+	sourceLimiter        *sourceLimiter
+	dependencyCache      *loader.DependencyCache
 }
 
 type HelmReleaseReconcilerOptions struct {
 	HTTPRetry                 int
 	DependencyRequeueInterval time.Duration
 	RateLimiter               workqueue.TypedRateLimiter[reconcile.Request]
+	// ConcurrentPerSource is the maximum number of HelmReleases sharing the
+	// same chart source which run Helm actions at the same time. Zero or
+	// less disables the limit.
+	ConcurrentPerSource int
 }
 
 var (
-	errWaitForDependency = errors.New("must wait for dependency")
-	errWaitForChart      = errors.New("must wait for chart")
+	errWaitForDependency    = errors.New("must wait for dependency")
+	errDependencyRemediated = errors.New("waiting on remediated dependency")
+	errWaitForChart         = errors.New("must wait for chart")
 )
 
 func (r *HelmReleaseReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, opts HelmReleaseReconcilerOptions) error {
@@ -128,8 +148,17 @@ func (r *HelmReleaseReconciler) SetupWithManager(ctx context.Context, mgr ctrl.M
 		return err
 	}
 
+	// Index the HelmRelease by the ConfigMap or Secret their chart is
+	// embedded in.
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &v2.HelmRelease{}, v2.EmbeddedChartIndexKey,
+		indexEmbeddedChartRef); err != nil {
+		return err
+	}
+
 	r.requeueDependency = opts.DependencyRequeueInterval
 	r.artifactFetchRetries = opts.HTTPRetry
+	r.sourceLimiter = newSourceLimiter(opts.ConcurrentPerSource)
+	r.dependencyCache = loader.NewDependencyCache(loader.DefaultDependencyCacheSize, loader.DefaultDependencyCacheMaxBytes)
 
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&v2.HelmRelease{}, builder.WithPredicates(
@@ -145,8 +174,25 @@ func (r *HelmReleaseReconciler) SetupWithManager(ctx context.Context, mgr ctrl.M
 			handler.EnqueueRequestsFromMapFunc(r.requestsForOCIRrepositoryChange),
 			builder.WithPredicates(intpredicates.SourceRevisionChangePredicate{}),
 		).
+		// Only the metadata of ConfigMaps and Secrets is watched, to not
+		// keep the data of all of them in memory. Unless they are cached,
+		// the watch is restricted to the ones labeled to hold an embedded
+		// chart, see EmbeddedChartCacheByObject.
+		Watches(
+			&corev1.ConfigMap{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForEmbeddedChartChange(embeddedChartConfigMapKind)),
+			builder.OnlyMetadata,
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForEmbeddedChartChange(embeddedChartSecretKind)),
+			builder.OnlyMetadata,
+			builder.WithPredicates(predicate.ResourceVersionChangedPredicate{}),
+		).
 		WithOptions(controller.Options{
 			RateLimiter: opts.RateLimiter,
+			NewQueue:    newQueueWaitRecordingQueue,
 		}).
 		Complete(r)
 }
@@ -161,10 +207,6 @@ func (r *HelmReleaseReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, client.IgnoreNotFound(err)
 	}
 
-	if !isValidChartRef(obj) {
-		return ctrl.Result{}, reconcile.TerminalError(fmt.Errorf("invalid Chart reference"))
-	}
-
 	// Initialize the patch helper with the current version of the object.
 	patchHelper := patch.NewSerialPatcher(obj, r.Client)
 
@@ -209,6 +251,17 @@ func (r *HelmReleaseReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		r.Metrics.RecordDuration(ctx, obj, start)
 	}()
 
+	// Confirm the chart is referenced in a supported way. This is done after
+	// the deferred patch is set up, to report the handled reconcile request
+	// in the status.
+	if !isValidChartRef(obj) {
+		err := fmt.Errorf("invalid Chart reference")
+		conditions.MarkStalled(obj, v2.InvalidChartReferenceReason, "%s", err)
+		conditions.MarkFalse(obj, meta.ReadyCondition, v2.InvalidChartReferenceReason, "%s", err)
+		conditions.Delete(obj, meta.ReconcilingCondition)
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	}
+
 	// Examine if the object is under deletion.
 	if !obj.ObjectMeta.DeletionTimestamp.IsZero() {
 		return r.reconcileDelete(ctx, obj)
@@ -229,6 +282,20 @@ func (r *HelmReleaseReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		return ctrl.Result{}, nil
 	}
 
+	// Confirm the kind of the referenced source is allowed by the policy of
+	// the controller.
+	if err := intacl.AllowsSourceKind(getSourceKind(obj)); err != nil {
+		conditions.MarkStalled(obj, aclv1.AccessDeniedReason, "%s", err)
+		conditions.MarkFalse(obj, meta.ReadyCondition, aclv1.AccessDeniedReason, "%s", err)
+		conditions.Delete(obj, meta.ReconcilingCondition)
+		r.Eventf(obj, corev1.EventTypeWarning, aclv1.AccessDeniedReason, err.Error())
+
+		// Recovering from this is not possible without a restart of the
+		// controller or a change of spec, both triggering a new
+		// reconciliation.
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	}
+
 	// Reconcile the HelmChart template.
 	if err := r.reconcileChartTemplate(ctx, obj); err != nil {
 		return ctrl.Result{}, err
@@ -238,6 +305,13 @@ func (r *HelmReleaseReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 }
 
 func (r *HelmReleaseReconciler) reconcileRelease(ctx context.Context, patchHelper *patch.SerialPatcher, obj *v2.HelmRelease) (ctrl.Result, error) {
+	// Record the duration of the reconciliation, and the phases which ran.
+	start := time.Now()
+	obj.Status.LastReconcilePhases = nil
+	defer func() {
+		obj.Status.LastReconcileDuration = &metav1.Duration{Duration: time.Since(start).Round(time.Millisecond)}
+	}()
+
 	log := ctrl.LoggerFrom(ctx)
 
 	// Mark the resource as under reconciliation.
@@ -251,6 +325,29 @@ func (r *HelmReleaseReconciler) reconcileRelease(ctx context.Context, patchHelpe
 		log.Info(fmt.Sprintf("checking %d dependencies", c))
 
 		if err := r.checkDependencies(ctx, obj); err != nil {
+			// Detect dependency cycles, as these would otherwise strand the
+			// HelmRelease in a waiting state without any further indication.
+			if cycle := r.detectDependencyCycle(ctx, obj); len(cycle) > 0 {
+				msg := fmt.Sprintf("dependencies form a cycle which can not be satisfied: %s", formatDependencyCycle(cycle))
+				conditions.MarkFalse(obj, meta.ReadyCondition, v2.DependencyCycleReason, "%s", msg)
+				r.Eventf(obj, corev1.EventTypeWarning, v2.DependencyCycleReason, msg)
+				log.Info(msg)
+				return ctrl.Result{RequeueAfter: r.requeueDependency}, errWaitForDependency
+			}
+
+			// Block the release while a dependency is remediated, as the
+			// release would otherwise be built on top of a failed release.
+			// The event is only emitted once, to not repeat it on every
+			// retry.
+			if errors.Is(err, errDependencyRemediated) {
+				if !conditions.HasAnyReason(obj, meta.ReadyCondition, v2.DependencyRemediatedReason) {
+					r.Eventf(obj, corev1.EventTypeWarning, v2.DependencyRemediatedReason, err.Error())
+				}
+				conditions.MarkFalse(obj, meta.ReadyCondition, v2.DependencyRemediatedReason, "%s", err)
+				log.Info(fmt.Sprintf("%s: retrying in %s", err.Error(), r.requeueDependency.String()))
+				return ctrl.Result{RequeueAfter: r.requeueDependency}, errWaitForDependency
+			}
+
 			msg := fmt.Sprintf("dependencies do not meet ready condition (%s): retrying in %s",
 				err.Error(), r.requeueDependency.String())
 			conditions.MarkFalse(obj, meta.ReadyCondition, v2.DependencyNotReadyReason, "%s", err)
@@ -265,7 +362,8 @@ func (r *HelmReleaseReconciler) reconcileRelease(ctx context.Context, patchHelpe
 		log.Info("all dependencies are ready")
 	}
 	// Remove any stale corresponding Ready=False condition with Unknown.
-	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.DependencyNotReadyReason) {
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.DependencyNotReadyReason, v2.DependencyCycleReason,
+		v2.DependencyRemediatedReason) {
 		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
 	}
 
@@ -293,21 +391,129 @@ func (r *HelmReleaseReconciler) reconcileRelease(ctx context.Context, patchHelpe
 		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
 	}
 
+	// Authentication failures of the source are either permanent or caused
+	// by the rotation of credentials, the policy determines which to assume.
+	authFailed, authMsg := isSourceAuthFailed(source)
+	if authFailed && obj.GetChartPullAuthFailurePolicy() == v2.ChartPullAuthFailureFail {
+		conditions.MarkStalled(obj, v2.ArtifactAuthFailedReason, "%s", authMsg)
+		conditions.MarkFalse(obj, meta.ReadyCondition, v2.ArtifactAuthFailedReason, "%s", authMsg)
+		r.Eventf(obj, corev1.EventTypeWarning, v2.ArtifactAuthFailedReason, authMsg)
+		return ctrl.Result{}, reconcile.TerminalError(errors.New(authMsg))
+	}
+	if conditions.HasAnyReason(obj, meta.StalledCondition, v2.ArtifactAuthFailedReason) {
+		conditions.Delete(obj, meta.StalledCondition)
+	}
+
 	// Check if the source is ready.
 	if ready, msg := isSourceReady(source); !ready {
 		log.Info(msg)
-		conditions.MarkFalse(obj, meta.ReadyCondition, "SourceNotReady", "%s", msg)
+		if authFailed {
+			conditions.MarkFalse(obj, meta.ReadyCondition, v2.ArtifactAuthFailedReason,
+				"Source not ready, retrying with backoff: %s", authMsg)
+			r.Eventf(obj, corev1.EventTypeWarning, v2.ArtifactAuthFailedReason, authMsg)
+			return ctrl.Result{}, errors.New(authMsg)
+		}
+		// A source which has not produced an artifact yet is still being
+		// reconciled, which is not a failure of the release.
+		if pending, pendingMsg := isArtifactPending(source); pending {
+			conditions.MarkUnknown(obj, meta.ReadyCondition, v2.ArtifactNotReadyReason, "%s", pendingMsg)
+		} else {
+			conditions.MarkFalse(obj, meta.ReadyCondition, "SourceNotReady", "%s", msg)
+		}
 		// Do not requeue immediately, when the artifact is created
 		// the watcher should trigger a reconciliation.
 		return jitter.JitteredRequeueInterval(ctrl.Result{RequeueAfter: obj.GetRequeueAfter()}), errWaitForChart
 	}
 	// Remove any stale corresponding Ready=False condition with Unknown.
-	if conditions.HasAnyReason(obj, meta.ReadyCondition, "SourceNotReady") {
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, "SourceNotReady", v2.ArtifactNotReadyReason, v2.ArtifactAuthFailedReason) {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
+	// Report the suspension of the source, and pause Helm actions if the
+	// policy of the HelmRelease requires so.
+	var releaseOpts []intreconcile.AtomicReleaseOption
+	if suspended, msg := isSourceSuspended(source); suspended {
+		conditions.MarkTrue(obj, v2.SourceSuspendedCondition, v2.SourceSuspendedReason, "%s", msg)
+		if obj.GetSourceSuspendedPolicy() == v2.SourceSuspendedPause {
+			releaseOpts = append(releaseOpts, intreconcile.WithReleaseActionsPaused(v2.SourceSuspendedReason,
+				fmt.Sprintf("%s: Helm actions are paused per source suspended policy", msg)))
+		}
+	} else {
+		conditions.Delete(obj, v2.SourceSuspendedCondition)
+	}
+
+	// Pause all Helm actions while the reconciliation is paused, only
+	// reporting drift. Any other step which changes state is skipped as
+	// well.
+	paused := v2.IsPaused(obj)
+	if paused {
+		releaseOpts = append(releaseOpts, intreconcile.WithReconciliationPaused())
+	}
+
+	// Confirm the storage driver is supported, for objects stored before
+	// the CRD schema restricted it.
+	if err := validation.StorageDriver(obj).ToAggregate(); err != nil {
+		conditions.MarkStalled(obj, v2.InvalidStorageDriverReason, "%s", err)
+		conditions.MarkFalse(obj, meta.ReadyCondition, v2.InvalidStorageDriverReason, "%s", err)
+		conditions.Delete(obj, meta.ReconcilingCondition)
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	}
+	// Remove any stale corresponding Ready=False condition with Unknown.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.InvalidStorageDriverReason) {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
+	// Confirm the remediation configuration does not conflict with the
+	// remediation strategy.
+	if err := validation.Remediation(obj).ToAggregate(); err != nil {
+		conditions.MarkStalled(obj, v2.InvalidRemediationReason, "%s", err)
+		conditions.MarkFalse(obj, meta.ReadyCondition, v2.InvalidRemediationReason, "%s", err)
+		conditions.Delete(obj, meta.ReconcilingCondition)
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	}
+	// Remove any stale corresponding Ready=False condition with Unknown.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.InvalidRemediationReason) {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
+	// Defer Helm actions until the next maintenance window opens if the
+	// HelmRelease restricts them to maintenance windows.
+	var windowNextOpen time.Time
+	if len(obj.Spec.MaintenanceWindows) > 0 {
+		open, nextOpen, err := schedule.Evaluate(obj.Spec.MaintenanceWindows, time.Now())
+		if err != nil {
+			conditions.MarkStalled(obj, v2.InvalidMaintenanceWindowReason, "%s", err)
+			conditions.MarkFalse(obj, meta.ReadyCondition, v2.InvalidMaintenanceWindowReason, "%s", err)
+			conditions.Delete(obj, meta.ReconcilingCondition)
+			return ctrl.Result{}, reconcile.TerminalError(err)
+		}
+		if !open {
+			windowNextOpen = nextOpen
+			releaseOpts = append(releaseOpts, intreconcile.WithMaintenanceWindowClosed(nextOpen, obj.Spec.MaintenanceWindowBypass))
+		}
+	}
+	// Remove any stale corresponding Ready=False condition with Unknown.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.InvalidMaintenanceWindowReason) {
 		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
 	}
 
+	// Check the values references are allowed to be accessed.
+	if err := checkValuesReferences(obj); err != nil {
+		conditions.MarkStalled(obj, aclv1.AccessDeniedReason, "%s", err)
+		conditions.MarkFalse(obj, meta.ReadyCondition, aclv1.AccessDeniedReason, "%s", err)
+		conditions.Delete(obj, meta.ReconcilingCondition)
+		r.Eventf(obj, corev1.EventTypeWarning, aclv1.AccessDeniedReason, err.Error())
+
+		// Recovering from this is not possible without a restart of the
+		// controller or a change of spec, both triggering a new
+		// reconciliation.
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	}
+
 	// Compose values based from the spec and references.
+	phaseStart := time.Now()
 	values, err := chartutil.ChartValuesFromReferences(ctx, r.Client, obj.Namespace, obj.GetValues(), obj.Spec.ValuesFrom...)
+	obj.Status.AddReconcilePhase(v2.ReconcilePhaseCompose, time.Since(phaseStart))
 	if err != nil {
 		conditions.MarkFalse(obj, meta.ReadyCondition, "ValuesError", "%s", err)
 		r.Eventf(obj, corev1.EventTypeWarning, "ValuesError", err.Error())
@@ -318,9 +524,61 @@ func (r *HelmReleaseReconciler) reconcileRelease(ctx context.Context, patchHelpe
 		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
 	}
 
-	// Load chart from artifact.
-	loadedChart, err := loader.SecureLoadChartFromURL(loader.NewRetryableHTTPClient(ctx, r.artifactFetchRetries), source.GetArtifact().URL, source.GetArtifact().Digest)
+	// Confirm the composed values do not set any path denied by the policy
+	// of the controller.
+	if err := intacl.AllowsValues(values); err != nil {
+		conditions.MarkFalse(obj, meta.ReadyCondition, v2.ValuesDeniedReason, "%s", err)
+		r.Eventf(obj, corev1.EventTypeWarning, v2.ValuesDeniedReason, err.Error())
+		return ctrl.Result{}, err
+	}
+	// Remove any stale corresponding Ready=False condition with Unknown.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.ValuesDeniedReason) {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
+	// Handle a request to pull a fresh copy of the chart, by requesting the
+	// chart source to rebuild its artifact. As the rebuild happens
+	// asynchronously, the request is only recorded as handled once the
+	// source reports to have handled it. A request made while the
+	// reconciliation is paused is handled once it resumes.
+	if token, ok := v2.PendingForcePullRequest(obj); ok && !paused {
+		handled, err := r.requestSourceRebuild(ctx, source, token)
+		if err != nil {
+			conditions.MarkFalse(obj, meta.ReadyCondition, v2.ArtifactFailedReason, "Could not request rebuild of chart source: %s", err)
+			r.Eventf(obj, corev1.EventTypeWarning, v2.ArtifactFailedReason, err.Error())
+			return ctrl.Result{}, err
+		}
+		if !handled {
+			msg := fmt.Sprintf("Waiting for chart source to rebuild artifact for force pull request '%s'. Retrying in %s",
+				token, r.requeueDependency.String())
+			conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "%s", msg)
+			log.Info(msg)
+			return ctrl.Result{RequeueAfter: r.requeueDependency}, errWaitForDependency
+		}
+		obj.Status.LastHandledForcePullAt = token
+		r.Eventf(obj, corev1.EventTypeNormal, "ForcePull", "Pulling fresh chart artifact '%s'", source.GetArtifact().Revision)
+	}
+
+	// Load chart from artifact, or from the data of the ConfigMap or Secret
+	// it is embedded in.
+	phaseStart = time.Now()
+	var loadedChart *chart.Chart
+	if embedded, ok := source.(*embeddedChartSource); ok {
+		loadedChart, err = embedded.Load()
+	} else {
+		loadedChart, err = loader.SecureLoadChartFromURL(loader.NewRetryableHTTPClient(ctx, r.artifactFetchRetries),
+			source.GetArtifact().URL, source.GetArtifact().Digest)
+	}
+	obj.Status.AddReconcilePhase(v2.ReconcilePhaseFetch, time.Since(phaseStart))
 	if err != nil {
+		// A malformed embedded chart can only be recovered from by a change
+		// to the referent, which is picked up on the next attempt.
+		if errors.Is(err, loader.ErrInvalidEmbeddedChart) {
+			conditions.MarkFalse(obj, meta.ReadyCondition, v2.InvalidEmbeddedChartReason, "Could not load chart: %s", err)
+			r.Eventf(obj, corev1.EventTypeWarning, v2.InvalidEmbeddedChartReason, err.Error())
+			return ctrl.Result{}, err
+		}
+
 		if errors.Is(err, loader.ErrFileNotFound) {
 			msg := fmt.Sprintf("Source not ready: artifact not found. Retrying in %s", r.requeueDependency.String())
 			conditions.MarkFalse(obj, meta.ReadyCondition, v2.ArtifactFailedReason, "%s", msg)
@@ -333,7 +591,22 @@ func (r *HelmReleaseReconciler) reconcileRelease(ctx context.Context, patchHelpe
 		return ctrl.Result{}, err
 	}
 	// Remove any stale corresponding Ready=False condition with Unknown.
-	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.ArtifactFailedReason) {
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.ArtifactFailedReason, v2.InvalidEmbeddedChartReason) {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
+	// Build the dependencies of the chart which are not vendored in it.
+	if obj.MustUpdateDependencies() {
+		depBuilder := loader.NewDependencyBuilder(loader.NewRetryableHTTPClient(ctx, r.artifactFetchRetries),
+			obj.Namespace, r.dependencyRepositoryAuth(obj), r.dependencyCache)
+		if err = depBuilder.Build(ctx, loadedChart); err != nil {
+			conditions.MarkFalse(obj, meta.ReadyCondition, v2.DependencyUpdateFailedReason, "Could not build chart dependencies: %s", err)
+			r.Eventf(obj, corev1.EventTypeWarning, v2.DependencyUpdateFailedReason, err.Error())
+			return ctrl.Result{}, err
+		}
+	}
+	// Remove any stale corresponding Ready=False condition with Unknown.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.DependencyUpdateFailedReason) {
 		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
 	}
 
@@ -343,14 +616,133 @@ func (r *HelmReleaseReconciler) reconcileRelease(ctx context.Context, patchHelpe
 		return ctrl.Result{}, err
 	}
 
-	// Build the REST client getter.
-	getter, err := r.buildRESTClientGetter(ctx, obj)
+	// Verify the signature of the chart artifact, unless it has already
+	// been verified for the current release with the same configuration.
+	var verifiedDigest, verifyDigest string
+	if obj.HasChartRef() && obj.Spec.ChartRef.Verify != nil {
+		if verifiedDigest, verifyDigest, err = r.verifyChart(ctx, obj, source, ociDigest); err != nil {
+			conditions.MarkFalse(obj, meta.ReadyCondition, v2.VerificationFailedReason, "%s", err)
+			r.Eventf(obj, corev1.EventTypeWarning, v2.VerificationFailedReason, err.Error())
+			return ctrl.Result{}, err
+		}
+	}
+	// Remove any stale corresponding Ready=False condition with Unknown.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.VerificationFailedReason) {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
+	// Enable or disable the subcharts of the chart.
+	values, warnings, err := chartutil.ApplySubcharts(loadedChart, values, obj.Spec.Subcharts)
+	if err != nil {
+		conditions.MarkFalse(obj, meta.ReadyCondition, "SubchartsError", "%s", err)
+		r.Eventf(obj, corev1.EventTypeWarning, "SubchartsError", err.Error())
+		return ctrl.Result{}, err
+	}
+	for _, w := range warnings {
+		r.Eventf(obj, corev1.EventTypeWarning, "SubchartsWarning", w)
+	}
+	// Remove any stale corresponding Ready=False condition with Unknown.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, "SubchartsError") {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
+	// Record the resolved dependency tree of the chart.
+	deps, err := chartutil.DependencyTree(loadedChart, values)
+	if err != nil {
+		log.Error(err, "failed to resolve chart dependency tree")
+	}
+	obj.Status.ChartDependencies = deps
+
+	// Report the deprecation of the chart, and stall if the policy of the
+	// HelmRelease does not allow deprecated charts to be released.
+	if msg, block := checkChartDeprecation(obj, loadedChart.Metadata); msg != "" {
+		if !conditions.IsTrue(obj, v2.ChartDeprecatedCondition) {
+			r.Eventf(obj, corev1.EventTypeWarning, v2.ChartDeprecatedReason, msg)
+		}
+		conditions.MarkTrue(obj, v2.ChartDeprecatedCondition, v2.ChartDeprecatedReason, "%s", msg)
+		if block {
+			conditions.MarkStalled(obj, v2.ChartDeprecatedReason, "%s", msg)
+			conditions.MarkFalse(obj, meta.ReadyCondition, v2.ChartDeprecatedReason, "%s", msg)
+			conditions.Delete(obj, meta.ReconcilingCondition)
+			return ctrl.Result{}, reconcile.TerminalError(errors.New(msg))
+		}
+	} else {
+		conditions.Delete(obj, v2.ChartDeprecatedCondition)
+	}
+	// Remove any stale corresponding Ready=False condition with Unknown.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.ChartDeprecatedReason) {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
+	// Build the REST client getter, collecting any warnings returned by the
+	// Kubernetes API server (e.g. about deprecated APIs) while reconciling.
+	apiWarnings := kube.NewWarningCollector()
+	getter, err := r.buildRESTClientGetter(ctx, obj, kube.WithWarningHandler(apiWarnings))
 	if err != nil {
+		if errors.As(err, new(defaults.OverrideNotAllowedError)) {
+			conditions.MarkStalled(obj, v2.OverrideNotAllowedReason, "%s", err)
+			conditions.MarkFalse(obj, meta.ReadyCondition, v2.OverrideNotAllowedReason, "%s", err)
+			conditions.Delete(obj, meta.ReconcilingCondition)
+			r.Eventf(obj, corev1.EventTypeWarning, v2.OverrideNotAllowedReason, err.Error())
+
+			// Recovering from this is not possible without a restart of the
+			// controller or a change of spec, both triggering a new
+			// reconciliation.
+			return ctrl.Result{}, reconcile.TerminalError(err)
+		}
 		conditions.MarkFalse(obj, meta.ReadyCondition, "RESTClientError", "%s", err)
 		return ctrl.Result{}, err
 	}
+	defer func() {
+		if warnings := apiWarnings.Warnings(); len(warnings) > 0 {
+			r.Eventf(obj, corev1.EventTypeWarning, v2.APIWarningsReason,
+				"Kubernetes API server returned warnings:\n%s", strings.Join(warnings, "\n"))
+		}
+	}()
+	// Remove any stale corresponding Ready=False condition with Unknown.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, "RESTClientError", v2.OverrideNotAllowedReason) {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
+	// Confirm a remote cluster can be reached, as any Helm action would
+	// otherwise fail and be remediated while the release itself is fine.
+	if obj.Spec.KubeConfig != nil {
+		if err := checkClusterReachable(ctx, getter); err != nil {
+			msg := fmt.Sprintf("Remote cluster is unreachable: %s. Retrying in %s", err, r.requeueDependency.String())
+			conditions.MarkFalse(obj, meta.ReadyCondition, v2.ClusterUnreachableReason, "%s", msg)
+			log.Info(msg)
+			return ctrl.Result{RequeueAfter: r.requeueDependency}, errWaitForDependency
+		}
+	}
+	// Remove any stale corresponding Ready=False condition with Unknown.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.ClusterUnreachableReason) {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
+	// Confirm the target namespace is not being terminated, as any Helm action
+	// would otherwise fail with an error which is hard to reason about.
+	// The check is skipped when the namespace can not be retrieved, as
+	// this does not have to prevent the Helm action from running.
+	ns, err := getTargetNamespace(ctx, getter, obj.GetReleaseNamespace())
+	if err != nil {
+		log.V(logger.DebugLevel).Info(fmt.Sprintf("unable to determine phase of target namespace '%s': %s",
+			obj.GetReleaseNamespace(), err))
+	}
+	if ns != nil {
+		if msg, stall := checkNamespaceTermination(obj, ns, time.Now()); msg != "" {
+			conditions.MarkFalse(obj, meta.ReadyCondition, v2.NamespaceTerminatingReason, "%s", msg)
+			if stall {
+				conditions.MarkStalled(obj, v2.NamespaceTerminatingReason, "%s", msg)
+				conditions.Delete(obj, meta.ReconcilingCondition)
+				r.Eventf(obj, corev1.EventTypeWarning, v2.NamespaceTerminatingReason, msg)
+				return ctrl.Result{}, reconcile.TerminalError(errors.New(msg))
+			}
+			log.Info(msg)
+			return ctrl.Result{RequeueAfter: r.requeueDependency}, errWaitForDependency
+		}
+	}
 	// Remove any stale corresponding Ready=False condition with Unknown.
-	if conditions.HasAnyReason(obj, meta.ReadyCondition, "RESTClientError") {
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.NamespaceTerminatingReason) {
 		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
 	}
 
@@ -368,39 +760,100 @@ func (r *HelmReleaseReconciler) reconcileRelease(ctx context.Context, patchHelpe
 	}
 
 	// If the release target configuration has changed, we need to uninstall the
-	// previous release target first. If we did not do this, the installation would
-	// fail due to resources already existing.
+	// previous release target first, or hand over its resources when pruning
+	// is enabled. If we did not do this, the installation would fail due to
+	// resources already existing.
 	if reason, changed := action.ReleaseTargetChanged(obj, loadedChart.Name()); changed {
-		log.Info(fmt.Sprintf("release target configuration changed (%s): running uninstall for current release", reason))
-		if err = r.reconcileUninstall(ctx, getter, obj); err != nil && !errors.Is(err, intreconcile.ErrNoLatest) {
-			return ctrl.Result{}, err
+		// The uninstall of the previous release target is deferred while
+		// the reconciliation is paused.
+		if paused {
+			log.Info(fmt.Sprintf("release target configuration changed (%s): not running uninstall while reconciliation is paused", reason))
+			conditions.MarkUnknown(obj, meta.ReadyCondition, v2.PausedReason,
+				"Reconciliation is paused using the %s annotation: release target configuration changed (%s)",
+				v2.PauseAnnotation, reason)
+			conditions.Delete(obj, meta.ReconcilingCondition)
+			return jitter.JitteredRequeueInterval(ctrl.Result{RequeueAfter: requeueAfter(obj, time.Now())}), nil
+		}
+		prevInventory := obj.Status.Inventory
+		if mustHandOverRelease(obj) {
+			// When pruning is enabled, the resources of the current release
+			// are handed over to the release for the new target instead.
+			// The new release takes ownership of the resources it renders
+			// on install, after which the remaining resources of the
+			// current release are pruned.
+			log.Info(fmt.Sprintf("release target configuration changed (%s): handing over resources of current release", reason))
+			if err = r.reconcileReleaseHandOver(ctx, getter, obj); err != nil {
+				return ctrl.Result{}, err
+			}
+		} else {
+			log.Info(fmt.Sprintf("release target configuration changed (%s): running uninstall for current release", reason))
+			if err = r.reconcileUninstall(ctx, getter, obj); err != nil && !errors.Is(err, intreconcile.ErrNoLatest) {
+				return ctrl.Result{}, err
+			}
+		}
+		// Keep the inventory of the previous release when pruning is
+		// enabled, to prune the resources it left behind once the release
+		// has been installed for the new target.
+		if obj.GetUpgrade().Prune {
+			obj.Status.Inventory = prevInventory
 		}
 		obj.Status.ClearHistory()
 		obj.Status.ClearFailures()
 		obj.Status.StorageNamespace = ""
+		obj.Status.StorageDriver = ""
+		// The namespace created for the release is never deleted on a change
+		// of the release target, as it may still hold the new release. It
+		// remains on record while it is the target namespace, to be deleted
+		// together with the HelmRelease.
+		if obj.Status.CreatedNamespace != obj.Spec.TargetNamespace {
+			obj.Status.CreatedNamespace = ""
+		}
 		return ctrl.Result{Requeue: true}, nil
 	}
 
-	// Set current storage namespace.
+	// Set current storage namespace and driver.
 	obj.Status.StorageNamespace = obj.GetStorageNamespace()
+	obj.Status.StorageDriver = obj.GetStorageDriver()
 
 	// Reset the failure count if the chart or values have changed.
+	// This also releases the hold on a release which was rolled back on
+	// request.
 	if reason, ok := action.MustResetFailures(obj, loadedChart.Metadata, values); ok {
 		log.V(logger.DebugLevel).Info(fmt.Sprintf("resetting failure count (%s)", reason))
 		obj.Status.ClearFailures()
+		obj.Status.ManualRollbackActive = false
+	}
+
+	// Reset the count of stable reconciliations if the object, or the chart
+	// or values of the release changed since the last attempt.
+	configDigest := chartutil.DigestValues(digest.Canonical, values).String()
+	if obj.Status.LastAttemptedGeneration != obj.Generation ||
+		obj.Status.LastAttemptedRevision != loadedChart.Metadata.Version ||
+		obj.Status.LastAttemptedRevisionDigest != ociDigest ||
+		obj.Status.LastAttemptedConfigDigest != configDigest {
+		obj.Status.StableReconciliations = 0
 	}
 
 	// Set last attempt values.
 	obj.Status.LastAttemptedGeneration = obj.Generation
 	obj.Status.LastAttemptedRevision = loadedChart.Metadata.Version
 	obj.Status.LastAttemptedRevisionDigest = ociDigest
-	obj.Status.LastAttemptedConfigDigest = chartutil.DigestValues(digest.Canonical, values).String()
+	obj.Status.LastAttemptedConfigDigest = configDigest
 	obj.Status.LastAttemptedValuesChecksum = ""
 	obj.Status.LastReleaseRevision = 0
 
+	// Compose the checksum and snapshot of the values merged with the
+	// chart defaults, to record them with the release.
+	valuesChecksum, valuesSnapshot, err := r.observeValues(ctx, obj, loadedChart, values)
+	if err != nil {
+		conditions.MarkFalse(obj, meta.ReadyCondition, "ValuesError", "%s", err)
+		r.Eventf(obj, corev1.EventTypeWarning, "ValuesError", err.Error())
+		return ctrl.Result{}, err
+	}
+
 	// Construct config factory for any further Helm actions.
 	cfg, err := action.NewConfigFactory(getter,
-		action.WithStorage(action.DefaultStorageDriver, obj.Status.StorageNamespace),
+		action.WithStorage(action.StorageDriverName(obj.Status.StorageDriver), obj.Status.StorageNamespace),
 		action.WithStorageLog(action.NewDebugLog(ctrl.LoggerFrom(ctx).V(logger.TraceLevel))),
 	)
 	if err != nil {
@@ -412,21 +865,106 @@ func (r *HelmReleaseReconciler) reconcileRelease(ctx context.Context, patchHelpe
 		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
 	}
 
+	// Build the webhooks to invoke around the Helm actions.
+	preHook, postHook, err := r.buildReconcileHooks(ctx, obj)
+	if err != nil {
+		conditions.MarkFalse(obj, meta.ReadyCondition, v2.ReconcileHooksFailedReason, "%s", err)
+		if acl.IsAccessDenied(err) {
+			conditions.MarkStalled(obj, v2.ReconcileHooksFailedReason, "%s", err)
+			conditions.Delete(obj, meta.ReconcilingCondition)
+			r.Eventf(obj, corev1.EventTypeWarning, v2.ReconcileHooksFailedReason, err.Error())
+
+			// Recovering from this is not possible without a restart of the
+			// controller or a change of spec, both triggering a new
+			// reconciliation.
+			return ctrl.Result{}, reconcile.TerminalError(err)
+		}
+		return ctrl.Result{}, err
+	}
+	// Remove any stale corresponding Ready=False condition with Unknown.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.ReconcileHooksFailedReason) {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
+	// Limit the number of HelmReleases sharing the chart source which run
+	// Helm actions at the same time, requeueing the rest.
+	if key, err := sourceLimiterKey(obj); err == nil {
+		if !r.sourceLimiter.TryAcquire(key) {
+			msg := fmt.Sprintf("Concurrency limit of chart source %s reached. Retrying in %s",
+				key, r.requeueDependency.String())
+			conditions.MarkUnknown(obj, meta.ReadyCondition, v2.ConcurrencyLimitedReason, "%s", msg)
+			log.Info(msg)
+			return jitter.JitteredRequeueInterval(ctrl.Result{RequeueAfter: r.requeueDependency}), errWaitForDependency
+		}
+		defer r.sourceLimiter.Release(key)
+	}
+	// Remove any stale corresponding Ready=Unknown condition.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.ConcurrencyLimitedReason) {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason, "reconciliation in progress")
+	}
+
 	// Off we go!
-	if err = intreconcile.NewAtomicRelease(patchHelper, cfg, r.EventRecorder, r.FieldManager).Reconcile(ctx, &intreconcile.Request{
-		Object: obj,
-		Chart:  loadedChart,
-		Values: values,
-	}); err != nil {
+	releaseOpts = append(releaseOpts, intreconcile.WithReconcileHooks(preHook, postHook))
+	if r.SummaryEvents {
+		releaseOpts = append(releaseOpts, intreconcile.WithSummaryEvents())
+	}
+	if r.TimelineEventRecorder != nil {
+		releaseOpts = append(releaseOpts, intreconcile.WithTimelineEvents(r.TimelineEventRecorder))
+	}
+	failures := obj.Status.Failures
+	err = intreconcile.NewAtomicRelease(patchHelper, cfg, r.EventRecorder, r.FieldManager,
+		releaseOpts...).Reconcile(ctx, &intreconcile.Request{
+		Object:              obj,
+		Chart:               loadedChart,
+		Values:              values,
+		ValuesChecksum:      valuesChecksum,
+		ValuesSnapshot:      valuesSnapshot,
+		VerifiedDigest:      verifiedDigest,
+		VerificationDigest:  verifyDigest,
+		ChartSourceRevision: source.GetArtifact().Revision,
+		ChartDigest:         source.GetArtifact().Digest,
+		ChartPinnedByDigest: isPinnedByDigest(source),
+	})
+	recordActionDuration(obj)
+	failed := obj.Status.Failures > failures
+	if failed {
+		obj.Status.ConsecutiveFailures++
+	}
+	if err != nil {
 		if errors.Is(err, intreconcile.ErrMustRequeue) {
+			// Retry a failed attempt with an exponential backoff, if
+			// configured.
+			if failed && obj.Spec.RetryBackoff != nil {
+				backoff := retryBackoff(obj, rand.Float64())
+				markRetryBackoff(obj, backoff, time.Now())
+				return ctrl.Result{Requeue: true, RequeueAfter: backoff}, nil
+			}
 			return ctrl.Result{Requeue: true}, nil
 		}
-		if interrors.IsOneOf(err, intreconcile.ErrExceededMaxRetries, intreconcile.ErrMissingRollbackTarget) {
+		if errors.Is(err, intreconcile.ErrWithinRetryDelay) {
+			// Retry the failed release once the retry delay has passed.
+			return ctrl.Result{RequeueAfter: max(intreconcile.RetryDelayRemaining(obj, time.Now()), time.Second)}, nil
+		}
+		if interrors.IsOneOf(err, intreconcile.ErrExceededMaxRetries, intreconcile.ErrMissingRollbackTarget, intreconcile.ErrDowngradeBlocked) {
 			err = reconcile.TerminalError(err)
 		}
 		return ctrl.Result{}, err
 	}
-	return jitter.JitteredRequeueInterval(ctrl.Result{RequeueAfter: obj.GetRequeueAfter()}), nil
+
+	// Export the manifest of the latest successful release, if configured
+	// and the reconciliation is not paused.
+	if !paused {
+		r.reconcileManifestExport(ctx, obj, cfg)
+	}
+
+	// Requeue when the next maintenance window opens if a Helm action was
+	// deferred, and this is before the regular interval.
+	if conditions.HasAnyReason(obj, meta.ReadyCondition, v2.OutsideMaintenanceWindowReason) && !windowNextOpen.IsZero() {
+		if untilOpen := time.Until(windowNextOpen); untilOpen < requeueAfter(obj, time.Now()) {
+			return ctrl.Result{RequeueAfter: max(untilOpen, time.Second)}, nil
+		}
+	}
+	return jitter.JitteredRequeueInterval(ctrl.Result{RequeueAfter: requeueAfter(obj, time.Now())}), nil
 }
 
 // reconcileDelete deletes the v1beta2.HelmChart of the v2.HelmRelease,
@@ -435,6 +973,17 @@ func (r *HelmReleaseReconciler) reconcileDelete(ctx context.Context, obj *v2.Hel
 	// Only uninstall the release and delete the HelmChart resource if the
 	// resource is not suspended.
 	if !obj.Spec.Suspend {
+		// Delay the uninstall while other releases still depend on it.
+		if obj.GetUninstall().WaitForDependents {
+			wait, err := r.waitForDependents(ctx, obj, time.Now())
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if wait {
+				return ctrl.Result{RequeueAfter: r.requeueDependency}, nil
+			}
+		}
+
 		if err := r.reconcileReleaseDeletion(ctx, obj); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -447,6 +996,8 @@ func (r *HelmReleaseReconciler) reconcileDelete(ctx context.Context, obj *v2.Hel
 	if !obj.DeletionTimestamp.IsZero() {
 		// Remove our finalizer from the list.
 		controllerutil.RemoveFinalizer(obj, v2.HelmReleaseFinalizer)
+		deleteActionDuration(obj)
+		intreconcile.DeleteDriftMetrics(obj)
 
 		// Stop reconciliation as the object is being deleted.
 		return ctrl.Result{}, nil
@@ -491,6 +1042,16 @@ func (r *HelmReleaseReconciler) reconcileReleaseDeletion(ctx context.Context, ob
 			return nil
 		}
 
+		// Refuse to uninstall the release using a service account which
+		// is not allowed, as the uninstall and its hooks would otherwise
+		// run with its permissions.
+		if errors.As(err, new(defaults.OverrideNotAllowedError)) {
+			conditions.MarkStalled(obj, v2.OverrideNotAllowedReason, "%s", err)
+			conditions.MarkFalse(obj, meta.ReadyCondition, v2.OverrideNotAllowedReason, "%s", err)
+			r.Eventf(obj, corev1.EventTypeWarning, v2.OverrideNotAllowedReason, err.Error())
+			return reconcile.TerminalError(err)
+		}
+
 		conditions.MarkFalse(obj, meta.ReadyCondition, v2.UninstallFailedReason,
 			"failed to build REST client getter to uninstall release: %s", err)
 		return err
@@ -540,9 +1101,13 @@ func (r *HelmReleaseReconciler) reconcileReleaseDeletion(ctx context.Context, ob
 		ctrl.LoggerFrom(ctx).Info("uninstalled Helm release for deleted resource")
 	}
 
+	// Delete the target namespace if it was created by the controller.
+	r.reconcileNamespaceDeletion(ctx, getter, obj)
+
 	// Truncate the current release details in the status.
 	obj.Status.ClearHistory()
 	obj.Status.StorageNamespace = ""
+	obj.Status.StorageDriver = ""
 
 	return nil
 }
@@ -556,10 +1121,45 @@ func (r *HelmReleaseReconciler) reconcileChartTemplate(ctx context.Context, obj
 	})
 }
 
+// observeValues returns the checksum of the given values merged with the
+// default values of the chart. When the ValuesSnapshotInHistory feature is
+// enabled, it also returns a snapshot of the merged values, in which the
+// values sourced from Secrets are replaced by their checksum.
+func (r *HelmReleaseReconciler) observeValues(ctx context.Context, obj *v2.HelmRelease,
+	chrt *chart.Chart, values map[string]interface{}) (string, string, error) {
+	merged, err := chartutil.MergeChartValues(chrt, values)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to merge values with chart defaults: %w", err)
+	}
+	checksum := chartutil.DigestValues(digest.Canonical, merged).String()
+
+	if ok, _ := features.Enabled(features.ValuesSnapshotInHistory); !ok {
+		return checksum, "", nil
+	}
+
+	var secretRefs []v2.ValuesReference
+	for _, ref := range obj.Spec.ValuesFrom {
+		if ref.Kind == "Secret" {
+			secretRefs = append(secretRefs, ref)
+		}
+	}
+	var secretValues map[string]interface{}
+	if len(secretRefs) > 0 {
+		if secretValues, err = chartutil.ChartValuesFromReferences(ctx, r.Client, obj.Namespace, nil, secretRefs...); err != nil {
+			return "", "", err
+		}
+	}
+	snapshot, err := chartutil.SnapshotValues(digest.Canonical, merged, secretValues, chartutil.MaxValuesSnapshotSize)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to snapshot values: %w", err)
+	}
+	return checksum, snapshot, nil
+}
+
 func (r *HelmReleaseReconciler) reconcileUninstall(ctx context.Context, getter genericclioptions.RESTClientGetter, obj *v2.HelmRelease) error {
 	// Construct config factory for current release.
 	cfg, err := action.NewConfigFactory(getter,
-		action.WithStorage(action.DefaultStorageDriver, obj.Status.StorageNamespace),
+		action.WithStorage(action.StorageDriverName(obj.Status.StorageDriver), obj.Status.StorageNamespace),
 		action.WithStorageLog(action.NewDebugLog(ctrl.LoggerFrom(ctx).V(logger.TraceLevel))),
 	)
 	if err != nil {
@@ -571,10 +1171,74 @@ func (r *HelmReleaseReconciler) reconcileUninstall(ctx context.Context, getter g
 	return intreconcile.NewUninstall(cfg, r.EventRecorder).Reconcile(ctx, &intreconcile.Request{Object: obj})
 }
 
+// mustHandOverRelease returns true if the resources of the current release of
+// the given v2.HelmRelease can be handed over to the release for a new
+// target, instead of uninstalling the current release. This requires pruning
+// to be enabled, and the inventory of the current release to hold all its
+// resources.
+func mustHandOverRelease(obj *v2.HelmRelease) bool {
+	inventory := obj.Status.Inventory
+	return obj.GetUpgrade().Prune && obj.Status.History.Latest() != nil &&
+		inventory != nil && inventory.Count == len(inventory.Entries)
+}
+
+// reconcileReleaseHandOver removes the current release of the given
+// v2.HelmRelease from the Helm storage, while keeping its resources in the
+// cluster. The release is recorded in the inventory, to allow the resources
+// which are not taken over by the release for the new target to be pruned.
+// Contrary to an uninstall, the uninstall hooks of the release are not run.
+func (r *HelmReleaseReconciler) reconcileReleaseHandOver(ctx context.Context, getter genericclioptions.RESTClientGetter, obj *v2.HelmRelease) error {
+	cfg, err := action.NewConfigFactory(getter,
+		action.WithStorage(action.StorageDriverName(obj.Status.StorageDriver), obj.Status.StorageNamespace),
+		action.WithStorageLog(action.NewDebugLog(ctrl.LoggerFrom(ctx).V(logger.TraceLevel))),
+	)
+	if err != nil {
+		conditions.MarkFalse(obj, meta.ReadyCondition, "ConfigFactoryErr", "%s", err)
+		return err
+	}
+
+	cur := obj.Status.History.Latest()
+	if err = action.ForgetRelease(cfg.Build(nil), cur.Name); err != nil {
+		return err
+	}
+	if obj.Status.Inventory.Release == "" {
+		obj.Status.Inventory.Release = cur.Namespace + "/" + cur.Name
+	}
+	return nil
+}
+
+// reconcileNamespaceDeletion deletes the target namespace of the given
+// v2.HelmRelease if it was created by the controller, and the policy allows
+// it. It must only be called when the HelmRelease itself is deleted.
+// A failure does not block the deletion of the HelmRelease, as the release
+// itself has been uninstalled.
+func (r *HelmReleaseReconciler) reconcileNamespaceDeletion(ctx context.Context, getter genericclioptions.RESTClientGetter, obj *v2.HelmRelease) {
+	if obj.Status.CreatedNamespace == "" {
+		return
+	}
+
+	cfg, err := action.NewConfigFactory(getter,
+		action.WithStorage(action.StorageDriverName(obj.Status.StorageDriver), obj.Status.StorageNamespace),
+	)
+	if err == nil {
+		var deleted bool
+		if deleted, err = action.DeleteCreatedNamespace(cfg.Build(nil), obj); err == nil && deleted {
+			r.Eventf(obj, corev1.EventTypeNormal, v2.UninstallSucceededReason,
+				"Deleted namespace '%s' created for release", obj.Status.CreatedNamespace)
+		}
+	}
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to delete namespace created for release")
+		r.Eventf(obj, corev1.EventTypeWarning, v2.UninstallFailedReason, err.Error())
+	}
+	obj.Status.CreatedNamespace = ""
+}
+
 // checkDependencies checks if the dependencies of the given v2.HelmRelease
 // are Ready.
 // It returns an error if a dependency can not be retrieved or is not Ready,
-// otherwise nil.
+// otherwise nil. If a dependency has been remediated, the error wraps
+// errDependencyRemediated.
 func (r *HelmReleaseReconciler) checkDependencies(ctx context.Context, obj *v2.HelmRelease) error {
 	for _, d := range obj.Spec.DependsOn {
 		ref := types.NamespacedName{
@@ -590,6 +1254,11 @@ func (r *HelmReleaseReconciler) checkDependencies(ctx context.Context, obj *v2.H
 			return fmt.Errorf("unable to get '%s' dependency: %w", ref, err)
 		}
 
+		if conditions.IsTrue(dHr, v2.RemediatedCondition) {
+			return fmt.Errorf("%w '%s': %s", errDependencyRemediated, ref,
+				conditions.GetMessage(dHr, v2.RemediatedCondition))
+		}
+
 		if dHr.Generation != dHr.Status.ObservedGeneration || !conditions.IsTrue(dHr, meta.ReadyCondition) {
 			return fmt.Errorf("dependency '%s' is not ready", ref)
 		}
@@ -636,9 +1305,13 @@ func (r *HelmReleaseReconciler) adoptLegacyRelease(ctx context.Context, getter g
 	// Convert it to a v2 release snapshot.
 	snap := release.ObservedToSnapshot(release.ObserveRelease(rls))
 
-	// If tests are enabled, include them as well.
+	// If tests are enabled, include them as well. Unless the test hooks are
+	// excluded from the release and did not run yet, in which case the
+	// release would be recorded as tested without any tests.
 	if obj.GetTest().Enable {
-		snap.SetTestHooks(release.TestHooksFromRelease(rls))
+		if hooks := release.TestHooksFromRelease(rls); len(hooks) > 0 || !obj.GetTest().ExcludeFromManifest {
+			snap.SetTestHooks(hooks)
+		}
 	}
 
 	// Adopt it as the current release in the history.
@@ -665,16 +1338,28 @@ func (*HelmReleaseReconciler) adoptPostRenderersStatus(obj *v2.HelmRelease) {
 	}
 }
 
-func (r *HelmReleaseReconciler) buildRESTClientGetter(ctx context.Context, obj *v2.HelmRelease) (genericclioptions.RESTClientGetter, error) {
+// buildRESTClientGetter returns a REST client getter for the Helm actions of
+// the given HelmRelease. It resolves the effective configuration of the
+// HelmRelease, and returns a defaults.OverrideNotAllowedError if it
+// overrides the default service account while this is not allowed. This
+// applies to the actions on reconciliation and deletion alike.
+func (r *HelmReleaseReconciler) buildRESTClientGetter(ctx context.Context, obj *v2.HelmRelease, extraOpts ...kube.Option) (genericclioptions.RESTClientGetter, error) {
+	effectiveConfig, err := defaults.Resolve(obj)
+	if err != nil {
+		return nil, err
+	}
+	obj.Status.EffectiveConfig = effectiveConfig
+
 	opts := []kube.Option{
 		kube.WithNamespace(obj.GetReleaseNamespace()),
 		kube.WithClientOptions(r.ClientOpts),
 		// When ServiceAccountName is empty, it will fall back to the configured
 		// default. If this is not configured either, this option will result in
 		// a no-op.
-		kube.WithImpersonate(obj.Spec.ServiceAccountName, obj.GetNamespace()),
+		kube.WithImpersonate(effectiveConfig.ServiceAccountName, obj.GetNamespace()),
 		kube.WithPersistent(obj.UsePersistentClient()),
 	}
+	opts = append(opts, extraOpts...)
 	if obj.Spec.KubeConfig != nil {
 		secretName := types.NamespacedName{
 			Namespace: obj.GetNamespace(),
@@ -688,14 +1373,70 @@ func (r *HelmReleaseReconciler) buildRESTClientGetter(ctx context.Context, obj *
 		if err != nil {
 			return nil, err
 		}
-		return kube.NewMemoryRESTClientGetter(kubeConfig, opts...), nil
+		kubeContext, kubeUser, err := kube.ContextFromSecret(&secret, obj.Spec.KubeConfig.SecretRef.Key)
+		if err != nil {
+			return nil, err
+		}
+		getter := kube.NewMemoryRESTClientGetter(kubeConfig, opts...)
+		obj.Status.ClientIdentity = &v2.ClientIdentity{
+			Impersonate:       getter.Impersonate(),
+			KubeConfigSecret:  secret.Name,
+			KubeConfigContext: kubeContext,
+			KubeConfigUser:    kubeUser,
+		}
+		return getter, nil
 	}
 
 	cfg, err := r.GetClusterConfig()
 	if err != nil {
 		return nil, fmt.Errorf("could not get in-cluster REST config: %w", err)
 	}
-	return kube.NewMemoryRESTClientGetter(cfg, opts...), nil
+	getter := kube.NewMemoryRESTClientGetter(cfg, opts...)
+	obj.Status.ClientIdentity = &v2.ClientIdentity{
+		Impersonate: getter.Impersonate(),
+	}
+	return getter, nil
+}
+
+// buildReconcileHooks returns the pre and post reconcile webhooks configured
+// for the HelmRelease, resolving the credentials from any referenced Secret.
+func (r *HelmReleaseReconciler) buildReconcileHooks(ctx context.Context, obj *v2.HelmRelease) (pre, post *hook.Webhook, err error) {
+	if obj.Spec.ReconcileHooks == nil {
+		return nil, nil, nil
+	}
+	if h := obj.Spec.ReconcileHooks.Pre; h != nil {
+		if pre, err = r.buildWebhook(ctx, obj, *h); err != nil {
+			return nil, nil, err
+		}
+	}
+	if h := obj.Spec.ReconcileHooks.Post; h != nil {
+		if post, err = r.buildWebhook(ctx, obj, *h); err != nil {
+			return nil, nil, err
+		}
+	}
+	return pre, post, nil
+}
+
+// buildWebhook returns a hook.Webhook for the given v2.ReconcileHook. It
+// returns an access denied error if the address of the hook is not allowed.
+func (r *HelmReleaseReconciler) buildWebhook(ctx context.Context, obj *v2.HelmRelease, h v2.ReconcileHook) (*hook.Webhook, error) {
+	if err := intacl.AllowsReconcileHookAddress(h.Address); err != nil {
+		return nil, err
+	}
+
+	var data map[string][]byte
+	if h.SecretRef != nil {
+		secretName := types.NamespacedName{
+			Namespace: obj.GetNamespace(),
+			Name:      h.SecretRef.Name,
+		}
+		var secret corev1.Secret
+		if err := r.Get(ctx, secretName, &secret); err != nil {
+			return nil, fmt.Errorf("could not get reconcile hook secret '%s': %w", secretName, err)
+		}
+		data = secret.Data
+	}
+	return hook.NewWebhook(h, data), nil
 }
 
 // getSource returns the source object containing the HelmChart, either by
@@ -708,6 +1449,9 @@ func (r *HelmReleaseReconciler) getSource(ctx context.Context, obj *v2.HelmRelea
 		if obj.Spec.ChartRef.Kind == sourcev1beta2.OCIRepositoryKind {
 			return r.getSourceFromOCIRef(ctx, obj)
 		}
+		if isEmbeddedChartKind(obj.Spec.ChartRef.Kind) {
+			return r.getSourceFromEmbeddedRef(ctx, obj)
+		}
 		name, namespace = obj.Spec.ChartRef.Name, obj.Spec.ChartRef.Namespace
 		if namespace == "" {
 			namespace = obj.GetNamespace()
@@ -729,6 +1473,55 @@ func (r *HelmReleaseReconciler) getSource(ctx context.Context, obj *v2.HelmRelea
 	return &hc, nil
 }
 
+// checkValuesReferences returns an access denied error if any of the values
+// references of the given HelmRelease refers to a resource in another
+// namespace, while cross-namespace references are not allowed.
+func checkValuesReferences(obj *v2.HelmRelease) error {
+	for _, ref := range obj.Spec.ValuesFrom {
+		name := types.NamespacedName{Namespace: ref.GetNamespace(obj.GetNamespace()), Name: ref.Name}
+		if err := intacl.AllowsAccessTo(obj, ref.Kind, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// requestSourceRebuild requests source-controller to rebuild the artifact of
+// the given chart source, by setting the reconcile request annotation to the
+// given token. It returns true once the source has handled the request,
+// i.e. its last handled reconcile request matches the token. Sources which
+// are not built by source-controller, like embedded charts, have no artifact
+// to rebuild, and are considered to have handled the request.
+func (r *HelmReleaseReconciler) requestSourceRebuild(ctx context.Context, source sourcev1.Source, token string) (bool, error) {
+	var (
+		obj         client.Object
+		lastHandled string
+	)
+	switch s := source.(type) {
+	case *sourcev1.HelmChart:
+		obj, lastHandled = s, s.Status.GetLastHandledReconcileRequest()
+	case *sourcev1beta2.OCIRepository:
+		obj, lastHandled = s, s.Status.GetLastHandledReconcileRequest()
+	default:
+		return true, nil
+	}
+	if lastHandled == token {
+		return true, nil
+	}
+	if obj.GetAnnotations()[meta.ReconcileRequestAnnotation] == token {
+		return false, nil
+	}
+
+	mergePatch := client.MergeFrom(obj.DeepCopyObject().(client.Object))
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string, 1)
+	}
+	annotations[meta.ReconcileRequestAnnotation] = token
+	obj.SetAnnotations(annotations)
+	return false, r.Client.Patch(ctx, obj, mergePatch, client.FieldOwner(r.FieldManager))
+}
+
 func (r *HelmReleaseReconciler) getSourceFromOCIRef(ctx context.Context, obj *v2.HelmRelease) (sourcev1.Source, error) {
 	name, namespace := obj.Spec.ChartRef.Name, obj.Spec.ChartRef.Namespace
 	if namespace == "" {
@@ -837,7 +1630,342 @@ func (r *HelmReleaseReconciler) requestsForOCIRrepositoryChange(ctx context.Cont
 	return reqs
 }
 
+// getTargetNamespace returns the namespace with the given name, using the
+// REST client of the discovery client of the provided getter. It returns nil
+// without an error if the namespace does not exist, in which case the Helm
+// action itself is expected to surface the issue, or if the getter is not
+// allowed to get it, which is common for a service account scoped to the
+// namespace. The phase of the namespace is then unknown, which is logged at
+// debug level.
+func getTargetNamespace(ctx context.Context, getter genericclioptions.RESTClientGetter, name string) (*corev1.Namespace, error) {
+	dc, err := getter.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	ns := &corev1.Namespace{}
+	if err = dc.RESTClient().Get().AbsPath("/api/v1/namespaces", name).Do(ctx).Into(ns); err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+			ctrl.LoggerFrom(ctx).V(logger.DebugLevel).Info(
+				fmt.Sprintf("unable to determine phase of target namespace '%s': %s", name, err))
+			return nil, nil
+		}
+		return nil, err
+	}
+	return ns, nil
+}
+
+// clusterReachableTimeout is the time allowed for the Kubernetes API server
+// to respond to the reachability check of checkClusterReachable.
+const clusterReachableTimeout = 10 * time.Second
+
+// checkClusterReachable confirms the Kubernetes API server of the cluster
+// targeted by the given getter can be reached, by requesting its version.
+func checkClusterReachable(ctx context.Context, getter genericclioptions.RESTClientGetter) error {
+	dc, err := getter.ToDiscoveryClient()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, clusterReachableTimeout)
+	defer cancel()
+	return dc.RESTClient().Get().AbsPath("/version").Do(ctx).Error()
+}
+
+// detectDependencyCycle walks the dependency graph of the given
+// v2.HelmRelease across namespaces, and returns the first cycle found. It
+// returns nil if no cycle is found, or if the graph could not be fully
+// resolved.
+func (r *HelmReleaseReconciler) detectDependencyCycle(ctx context.Context, obj *v2.HelmRelease) []types.NamespacedName {
+	root := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	cycle, err := findDependencyCycle(root, func(ref types.NamespacedName) ([]types.NamespacedName, error) {
+		if ref == root {
+			return dependencyRefs(obj), nil
+		}
+		dHr := &v2.HelmRelease{}
+		if err := r.APIReader.Get(ctx, ref, dHr); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, err
+		}
+		return dependencyRefs(dHr), nil
+	})
+	if err != nil {
+		ctrl.LoggerFrom(ctx).V(logger.DebugLevel).Info(fmt.Sprintf("unable to detect dependency cycles: %s", err))
+		return nil
+	}
+	return cycle
+}
+
+// waitForDependents returns true if the uninstall of the given v2.HelmRelease
+// must be delayed, because other HelmReleases which depend on it have not
+// been deleted yet. Dependents which are part of a dependency cycle with the
+// HelmRelease are not waited for, as they may be waiting for the HelmRelease
+// in turn. Once the timeout since the deletion of the HelmRelease has passed,
+// the dependents are no longer waited for.
+func (r *HelmReleaseReconciler) waitForDependents(ctx context.Context, obj *v2.HelmRelease, now time.Time) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	var list v2.HelmReleaseList
+	if err := r.List(ctx, &list); err != nil {
+		return false, fmt.Errorf("failed to list HelmReleases to determine dependents: %w", err)
+	}
+
+	root := types.NamespacedName{Namespace: obj.GetNamespace(), Name: obj.GetName()}
+	dependents := dependentRefs(root, list.Items)
+	if len(dependents) == 0 {
+		return false, nil
+	}
+
+	// Surface, and break, any deadlock caused by a mutual dependency.
+	if cycle := r.detectDependencyCycle(ctx, obj); len(cycle) > 0 {
+		var remaining []types.NamespacedName
+		for _, d := range dependents {
+			if slices.Contains(cycle, d) {
+				continue
+			}
+			remaining = append(remaining, d)
+		}
+		if len(remaining) != len(dependents) {
+			msg := fmt.Sprintf("not waiting for dependents in dependency cycle: %s", formatDependencyCycle(cycle))
+			log.Info(msg)
+			r.Eventf(obj, corev1.EventTypeWarning, v2.DependencyCycleReason, msg)
+		}
+		dependents = remaining
+	}
+	if len(dependents) == 0 {
+		return false, nil
+	}
+
+	names := make([]string, 0, len(dependents))
+	for _, d := range dependents {
+		names = append(names, d.String())
+	}
+
+	timeout := obj.GetUninstall().GetWaitForDependentsTimeout()
+	if ts := obj.GetDeletionTimestamp(); ts != nil && now.Sub(ts.Time) >= timeout {
+		msg := fmt.Sprintf("timeout of %s waiting for dependents to be deleted has passed: uninstalling while dependents '%s' still exist",
+			timeout.String(), strings.Join(names, "', '"))
+		log.Info(msg)
+		r.Eventf(obj, corev1.EventTypeWarning, v2.WaitingForDependentsReason, msg)
+		return false, nil
+	}
+
+	conditions.MarkFalse(obj, meta.ReadyCondition, v2.WaitingForDependentsReason,
+		"Waiting for dependents '%s' to be deleted before uninstalling", strings.Join(names, "', '"))
+	log.Info(fmt.Sprintf("waiting for dependents '%s' to be deleted before uninstalling, retrying in %s",
+		strings.Join(names, "', '"), r.requeueDependency.String()))
+	return true, nil
+}
+
+// dependentRefs returns the namespaced names of the HelmReleases in the given
+// list which depend on the HelmRelease with the given name, sorted by name.
+func dependentRefs(root types.NamespacedName, list []v2.HelmRelease) []types.NamespacedName {
+	var refs []types.NamespacedName
+	for i := range list {
+		if slices.Contains(dependencyRefs(&list[i]), root) {
+			refs = append(refs, types.NamespacedName{Namespace: list[i].GetNamespace(), Name: list[i].GetName()})
+		}
+	}
+	slices.SortFunc(refs, func(a, b types.NamespacedName) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return refs
+}
+
+// dependencyRefs returns the dependencies of the given v2.HelmRelease as
+// namespaced names, defaulting to the namespace of the HelmRelease.
+func dependencyRefs(obj *v2.HelmRelease) []types.NamespacedName {
+	refs := make([]types.NamespacedName, 0, len(obj.Spec.DependsOn))
+	for _, d := range obj.Spec.DependsOn {
+		ref := types.NamespacedName{Namespace: d.Namespace, Name: d.Name}
+		if ref.Namespace == "" {
+			ref.Namespace = obj.GetNamespace()
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+// findDependencyCycle performs a depth-first search of the dependency graph
+// starting at root, using getDeps to resolve the dependencies of a node. It
+// returns the path of the first cycle found, starting and ending with the
+// same node, or nil if the graph is acyclic.
+func findDependencyCycle(root types.NamespacedName, getDeps func(types.NamespacedName) ([]types.NamespacedName, error)) ([]types.NamespacedName, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[types.NamespacedName]int)
+	var path []types.NamespacedName
+
+	var visit func(n types.NamespacedName) ([]types.NamespacedName, error)
+	visit = func(n types.NamespacedName) ([]types.NamespacedName, error) {
+		state[n] = visiting
+		path = append(path, n)
+
+		deps, err := getDeps(n)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range deps {
+			switch state[d] {
+			case visiting:
+				for i := range path {
+					if path[i] == d {
+						return append(append([]types.NamespacedName{}, path[i:]...), d), nil
+					}
+				}
+			case visited:
+				continue
+			default:
+				if cycle, err := visit(d); cycle != nil || err != nil {
+					return cycle, err
+				}
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[n] = visited
+		return nil, nil
+	}
+	return visit(root)
+}
+
+// formatDependencyCycle returns a human-readable representation of the given
+// dependency cycle.
+func formatDependencyCycle(cycle []types.NamespacedName) string {
+	s := make([]string, len(cycle))
+	for i := range cycle {
+		s[i] = cycle[i].String()
+	}
+	return strings.Join(s, " -> ")
+}
+
+// checkChartDeprecation returns a message describing the deprecation of the
+// chart with the given metadata, and whether the reconciliation of the
+// HelmRelease should be stalled according to its ChartDeprecationPolicy. An
+// empty message indicates the chart is not deprecated.
+func checkChartDeprecation(obj *v2.HelmRelease, metadata *chart.Metadata) (string, bool) {
+	if metadata == nil || !metadata.Deprecated {
+		return "", false
+	}
+
+	msg := fmt.Sprintf("chart '%s' version '%s' is deprecated", metadata.Name, metadata.Version)
+	if obj.GetChartDeprecationPolicy() == v2.ChartDeprecationBlock {
+		return msg + ": blocking release per chart deprecation policy", true
+	}
+	return msg, false
+}
+
+// requeueAfter returns the interval after which the given HelmRelease should
+// be reconciled again after a successful reconciliation. When the release
+// has been stable for the threshold of the stable interval, this is the
+// stable interval. When adaptive requeueing is enabled, this is the period
+// the Ready condition has been stable, bounded by the minimum and maximum
+// interval. Otherwise, it is the configured interval.
+func requeueAfter(obj *v2.HelmRelease, now time.Time) time.Duration {
+	if stable := obj.Spec.StableInterval; stable != nil && obj.Status.StableReconciliations >= stable.GetThreshold() {
+		return stable.Interval.Duration
+	}
+
+	interval := obj.GetRequeueAfter()
+	if obj.Spec.AdaptiveRequeue == nil {
+		return interval
+	}
+
+	minInterval := obj.Spec.AdaptiveRequeue.GetMinInterval(interval)
+	maxInterval := obj.Spec.AdaptiveRequeue.GetMaxInterval(interval)
+	ready := conditions.Get(obj, meta.ReadyCondition)
+	if ready == nil {
+		return minInterval
+	}
+	return min(max(now.Sub(ready.LastTransitionTime.Time), minInterval), maxInterval)
+}
+
+// retryBackoff returns the interval after which a failed attempt of the
+// given HelmRelease must be retried. The interval grows exponentially with
+// the number of consecutive failures, up to the maximum interval of the
+// v2.RetryBackoff, and is shortened or lengthened by the jitter percentage
+// of the v2.RetryBackoff using the given random number in [0.0, 1.0).
+func retryBackoff(obj *v2.HelmRelease, random float64) time.Duration {
+	backoff := obj.Spec.RetryBackoff
+	maxInterval := backoff.GetMaxInterval(obj.GetRequeueAfter())
+
+	interval := backoff.GetBaseInterval()
+	for i := int64(1); i < obj.Status.ConsecutiveFailures && interval < maxInterval; i++ {
+		interval *= 2
+	}
+	interval = min(interval, maxInterval)
+
+	jitter := float64(interval) * float64(backoff.GetJitter()) / 100
+	return interval + time.Duration(jitter*(2*random-1))
+}
+
+// markRetryBackoff adds the time at which a failed attempt of the given
+// HelmRelease is retried to the message of the Ready and Reconciling
+// conditions.
+func markRetryBackoff(obj *v2.HelmRelease, backoff time.Duration, now time.Time) {
+	suffix := fmt.Sprintf(" (retrying in %s at %s)", backoff.Round(time.Second).String(),
+		now.Add(backoff).UTC().Format(time.RFC3339))
+	for _, t := range []string{meta.ReadyCondition, meta.ReconcilingCondition} {
+		if c := conditions.Get(obj, t); c != nil {
+			c.Message += suffix
+			conditions.Set(obj, c)
+		}
+	}
+}
+
+// checkNamespaceTermination returns a message describing the termination of
+// the given namespace if it is in the Terminating phase, and whether the
+// reconciliation of the HelmRelease should be stalled according to its
+// NamespaceTerminationPolicy. An empty message indicates the namespace is not
+// being terminated.
+func checkNamespaceTermination(obj *v2.HelmRelease, ns *corev1.Namespace, now time.Time) (string, bool) {
+	if ns.Status.Phase != corev1.NamespaceTerminating && ns.DeletionTimestamp.IsZero() {
+		return "", false
+	}
+
+	if obj.GetNamespaceTerminationPolicy() == v2.NamespaceTerminationAbort {
+		return fmt.Sprintf("target namespace '%s' is terminating: aborting reconciliation per namespace termination policy, "+
+			"request a reconciliation once it has been removed", ns.Name), true
+	}
+
+	if ts := ns.DeletionTimestamp; ts != nil && now.Sub(ts.Time) > v2.NamespaceTerminationStallThreshold {
+		return fmt.Sprintf("target namespace '%s' has been terminating for more than %s: "+
+			"check the namespace finalizers and the resources remaining in it, "+
+			"and request a reconciliation once it has been removed",
+			ns.Name, v2.NamespaceTerminationStallThreshold.String()), true
+	}
+
+	return fmt.Sprintf("target namespace '%s' is terminating: waiting for removal before reconciling", ns.Name), false
+}
+
+// isSourceSuspended returns true and a message describing the suspension
+// if the given source is suspended.
+func isSourceSuspended(obj sourcev1.Source) (bool, string) {
+	var (
+		kind      string
+		suspended bool
+	)
+	switch o := obj.(type) {
+	case *sourcev1.HelmChart:
+		kind, suspended = sourcev1.HelmChartKind, o.Spec.Suspend
+	case *sourcev1beta2.OCIRepository:
+		kind, suspended = sourcev1beta2.OCIRepositoryKind, o.Spec.Suspend
+	}
+	if !suspended {
+		return false, ""
+	}
+	o := obj.(client.Object)
+	return true, fmt.Sprintf("%s '%s/%s' is suspended", kind, o.GetNamespace(), o.GetName())
+}
+
 func isSourceReady(obj sourcev1.Source) (bool, string) {
+	// An embedded chart is ready as soon as its referent is found, whether
+	// the chart can be loaded is determined when loading it.
+	if _, ok := obj.(*embeddedChartSource); ok {
+		return true, ""
+	}
 	if o, ok := obj.(conditions.Getter); ok {
 		return isReady(o, obj.GetArtifact())
 	}
@@ -872,9 +2000,48 @@ func isReady(obj conditions.Getter, artifact *sourcev1.Artifact) (bool, string)
 	}
 }
 
+// isSourceAuthFailed returns true if the source failed to authenticate with
+// its upstream, as reported by the reason of its Ready condition. It returns
+// a message pointing at the source.
+func isSourceAuthFailed(obj sourcev1.Source) (bool, string) {
+	o, ok := obj.(conditions.Getter)
+	if !ok || !conditions.IsFalse(o, meta.ReadyCondition) ||
+		!conditions.HasAnyReason(o, meta.ReadyCondition, sourcev1.AuthenticationFailedReason) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%s '%s/%s' failed to authenticate: %s", o.GetObjectKind().GroupVersionKind().Kind,
+		o.GetNamespace(), o.GetName(), conditions.GetMessage(o, meta.ReadyCondition))
+}
+
+// isArtifactPending returns true if the source has not produced an artifact
+// yet, while it has not failed to do so. It returns a message pointing at the
+// source.
+func isArtifactPending(obj sourcev1.Source) (bool, string) {
+	o, ok := obj.(conditions.Getter)
+	if !ok || obj.GetArtifact() != nil {
+		return false, ""
+	}
+	if conditions.IsStalled(o) || conditions.IsFalse(o, meta.ReadyCondition) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("%s '%s/%s' does not have an artifact yet",
+		o.GetObjectKind().GroupVersionKind().Kind, o.GetNamespace(), o.GetName())
+}
+
+// getSourceKind returns the kind of the source referenced by the HelmRelease,
+// either through the chartRef or the chart template.
+func getSourceKind(obj *v2.HelmRelease) string {
+	if obj.HasChartRef() {
+		return obj.Spec.ChartRef.Kind
+	}
+	if obj.HasChartTemplate() {
+		return obj.Spec.Chart.Spec.SourceRef.Kind
+	}
+	return ""
+}
+
 func isValidChartRef(obj *v2.HelmRelease) bool {
-	return (obj.HasChartRef() && !obj.HasChartTemplate()) ||
-		(!obj.HasChartRef() && obj.HasChartTemplate())
+	return validation.ChartReference(obj) == nil
 }
 
 func getNamespacedName(obj *v2.HelmRelease) (types.NamespacedName, error) {
@@ -896,6 +2063,13 @@ func getNamespacedName(obj *v2.HelmRelease) (types.NamespacedName, error) {
 	return namespacedName, nil
 }
 
+// isPinnedByDigest returns true if the given source is an OCIRepository
+// which references the chart artifact by digest.
+func isPinnedByDigest(source sourcev1.Source) bool {
+	obj, ok := source.(*sourcev1beta2.OCIRepository)
+	return ok && obj.Spec.Reference != nil && obj.Spec.Reference.Digest != ""
+}
+
 func mutateChartWithSourceRevision(chart *chart.Chart, source sourcev1.Source) (string, error) {
 	// If the source is an OCIRepository, we can try to mutate the chart version
 	// with the artifact revision. The revision is either a <tag>@<digest> or
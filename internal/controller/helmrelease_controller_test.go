@@ -20,6 +20,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -39,6 +41,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
@@ -59,8 +62,10 @@ import (
 	intacl "github.com/fluxcd/helm-controller/internal/acl"
 	"github.com/fluxcd/helm-controller/internal/action"
 	"github.com/fluxcd/helm-controller/internal/chartutil"
+	"github.com/fluxcd/helm-controller/internal/defaults"
 	"github.com/fluxcd/helm-controller/internal/features"
 	"github.com/fluxcd/helm-controller/internal/kube"
+	"github.com/fluxcd/helm-controller/internal/loader"
 	"github.com/fluxcd/helm-controller/internal/postrender"
 	intreconcile "github.com/fluxcd/helm-controller/internal/reconcile"
 	"github.com/fluxcd/helm-controller/internal/release"
@@ -240,7 +245,66 @@ func TestHelmReleaseReconciler_reconcileRelease(t *testing.T) {
 
 		g.Expect(obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{
 			*conditions.TrueCondition(meta.ReconcilingCondition, meta.ProgressingReason, ""),
-			*conditions.FalseCondition(meta.ReadyCondition, "SourceNotReady", "HelmChart 'mock/chart' is not ready"),
+			*conditions.UnknownCondition(meta.ReadyCondition, v2.ArtifactNotReadyReason, "HelmChart 'mock/chart' does not have an artifact yet"),
+		}))
+	})
+
+	t.Run("stalls on HelmChart authentication failure", func(t *testing.T) {
+		g := NewWithT(t)
+
+		chart := &sourcev1.HelmChart{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: sourcev1.GroupVersion.String(),
+				Kind:       sourcev1.HelmChartKind,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "chart",
+				Namespace:  "mock",
+				Generation: 2,
+			},
+			Status: sourcev1.HelmChartStatus{
+				ObservedGeneration: 2,
+				Conditions: []metav1.Condition{
+					{
+						Type:    meta.ReadyCondition,
+						Status:  metav1.ConditionFalse,
+						Reason:  sourcev1.AuthenticationFailedReason,
+						Message: "invalid credentials",
+					},
+				},
+			},
+		}
+
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "release",
+				Namespace: "mock",
+			},
+			Spec: v2.HelmReleaseSpec{
+				Interval:                   metav1.Duration{Duration: 1 * time.Second},
+				ChartPullAuthFailurePolicy: v2.ChartPullAuthFailureFail,
+			},
+			Status: v2.HelmReleaseStatus{
+				HelmChart: "mock/chart",
+			},
+		}
+
+		r := &HelmReleaseReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(NewTestScheme()).
+				WithStatusSubresource(&v2.HelmRelease{}).
+				WithObjects(chart, obj).
+				Build(),
+			EventRecorder: record.NewFakeRecorder(32),
+		}
+
+		_, err := r.reconcileRelease(context.TODO(), patch.NewSerialPatcher(obj, r.Client), obj)
+		g.Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+
+		msg := "HelmChart 'mock/chart' failed to authenticate: invalid credentials"
+		g.Expect(obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{
+			*conditions.TrueCondition(meta.StalledCondition, v2.ArtifactAuthFailedReason, msg),
+			*conditions.FalseCondition(meta.ReadyCondition, v2.ArtifactAuthFailedReason, msg),
 		}))
 	})
 
@@ -362,6 +426,70 @@ func TestHelmReleaseReconciler_reconcileRelease(t *testing.T) {
 		}))
 	})
 
+	t.Run("handles ACL error for values reference", func(t *testing.T) {
+		g := NewWithT(t)
+
+		chart := &sourcev1.HelmChart{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "chart",
+				Namespace:  "mock",
+				Generation: 2,
+			},
+			Spec: sourcev1.HelmChartSpec{
+				Interval: metav1.Duration{Duration: 1 * time.Second},
+			},
+			Status: sourcev1.HelmChartStatus{
+				ObservedGeneration: 2,
+				Artifact:           &sourcev1.Artifact{},
+				Conditions: []metav1.Condition{
+					{
+						Type:   meta.ReadyCondition,
+						Status: metav1.ConditionTrue,
+					},
+				},
+			},
+		}
+
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "release",
+				Namespace: "mock",
+			},
+			Spec: v2.HelmReleaseSpec{
+				ValuesFrom: []v2.ValuesReference{
+					{
+						Kind:      "ConfigMap",
+						Name:      "values",
+						Namespace: "config",
+					},
+				},
+			},
+			Status: v2.HelmReleaseStatus{
+				HelmChart: "mock/chart",
+			},
+		}
+
+		r := &HelmReleaseReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(NewTestScheme()).
+				WithStatusSubresource(&v2.HelmRelease{}).
+				WithObjects(chart, obj).
+				Build(),
+			EventRecorder: record.NewFakeRecorder(32),
+		}
+		r.APIReader = r.Client
+
+		res, err := r.reconcileRelease(context.TODO(), patch.NewSerialPatcher(obj, r.Client), obj)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+		g.Expect(res.IsZero()).To(BeTrue())
+
+		g.Expect(obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{
+			*conditions.TrueCondition(meta.StalledCondition, acl.AccessDeniedReason, "cannot access ConfigMap config/values"),
+			*conditions.FalseCondition(meta.ReadyCondition, acl.AccessDeniedReason, "cannot access ConfigMap config/values"),
+		}))
+	})
+
 	t.Run("reports Helm chart load failure", func(t *testing.T) {
 		g := NewWithT(t)
 
@@ -593,9 +721,15 @@ func TestHelmReleaseReconciler_reconcileRelease(t *testing.T) {
 		g.Expect(obj.Status.StorageNamespace).To(BeEmpty())
 	})
 
-	t.Run("resets failure counts on configuration change", func(t *testing.T) {
+	t.Run("hands over resources to prune if target has changed", func(t *testing.T) {
 		g := NewWithT(t)
 
+		ns, err := testEnv.CreateNamespace(context.TODO(), "hand-over")
+		g.Expect(err).ToNot(HaveOccurred())
+		t.Cleanup(func() {
+			_ = testEnv.Delete(context.TODO(), ns)
+		})
+
 		chartMock := testutil.BuildChart()
 		chartArtifact, err := testutil.SaveChartAsArtifact(chartMock, digest.SHA256, testServer.URL(), testServer.Root())
 		g.Expect(err).ToNot(HaveOccurred())
@@ -603,7 +737,7 @@ func TestHelmReleaseReconciler_reconcileRelease(t *testing.T) {
 		chart := &sourcev1.HelmChart{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:       "chart",
-				Namespace:  "mock",
+				Namespace:  ns.Name,
 				Generation: 1,
 			},
 			Status: sourcev1.HelmChartStatus{
@@ -618,26 +752,51 @@ func TestHelmReleaseReconciler_reconcileRelease(t *testing.T) {
 			},
 		}
 
+		// Create a resource of the current release.
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "orphan",
+				Namespace: ns.Name,
+				Annotations: map[string]string{
+					"meta.helm.sh/release-name":      "old",
+					"meta.helm.sh/release-namespace": ns.Name,
+				},
+			},
+		}
+		g.Expect(testEnv.Create(context.TODO(), cm)).To(Succeed())
+
+		rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+			Name:      "old",
+			Namespace: ns.Name,
+			Version:   1,
+			Chart:     chartMock,
+			Status:    helmrelease.StatusDeployed,
+		})
+		rls.Manifest = fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: orphan\n  namespace: %s\n", ns.Name)
+
+		inventory := &v2.ResourceInventory{
+			Count:   1,
+			Entries: []v2.ResourceRef{{ID: ns.Name + "_orphan__ConfigMap", Version: "v1"}},
+		}
 		obj := &v2.HelmRelease{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:       "release",
-				Namespace:  "mock",
-				Generation: 2,
+				Name:      "release",
+				Namespace: ns.Name,
 			},
 			Spec: v2.HelmReleaseSpec{
-				// Trigger a failure by setting an invalid storage namespace,
-				// preventing the release from actually being installed.
-				// This allows us to just test the failure count reset, without
-				// having to facilitate a full install.
-				StorageNamespace: "not-exist",
+				ReleaseName:      "new",
+				StorageNamespace: ns.Name,
+				Upgrade: &v2.Upgrade{
+					Prune: true,
+				},
 			},
 			Status: v2.HelmReleaseStatus{
-				HelmChart:       "mock/chart",
-				InstallFailures: 2,
-				UpgradeFailures: 3,
-				Failures:        5,
-				// Trigger actual failure reset due to change in spec.
-				LastAttemptedGeneration: 1,
+				History: v2.Snapshots{
+					release.ObservedToSnapshot(release.ObserveRelease(rls)),
+				},
+				HelmChart:        ns.Name + "/chart",
+				StorageNamespace: ns.Name,
+				Inventory:        inventory.DeepCopy(),
 			},
 		}
 
@@ -654,19 +813,41 @@ func TestHelmReleaseReconciler_reconcileRelease(t *testing.T) {
 			EventRecorder:    record.NewFakeRecorder(32),
 		}
 
-		_, err = r.reconcileRelease(context.TODO(), patch.NewSerialPatcher(obj, c), obj)
-		g.Expect(err).To(HaveOccurred())
-		g.Expect(err.Error()).To(ContainSubstring("namespaces \"not-exist\" not found"))
+		getter, err := r.buildRESTClientGetter(context.TODO(), obj)
+		g.Expect(err).ToNot(HaveOccurred())
 
-		// Verify failure counts are reset.
-		g.Expect(obj.Status.InstallFailures).To(Equal(int64(0)))
-		g.Expect(obj.Status.UpgradeFailures).To(Equal(int64(0)))
-		g.Expect(obj.Status.Failures).To(Equal(int64(1)))
+		cfg, err := action.NewConfigFactory(getter, action.WithStorage(helmdriver.SecretsDriverName, obj.Status.StorageNamespace))
+		g.Expect(err).ToNot(HaveOccurred())
+
+		store := helmstorage.Init(cfg.Driver)
+		g.Expect(store.Create(rls)).To(Succeed())
+
+		res, err := r.reconcileRelease(context.TODO(), patch.NewSerialPatcher(obj, c), obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res.Requeue).To(BeTrue())
+
+		// Verify the release has been removed from the storage, while its
+		// resources are kept to be pruned after the install of the new
+		// release.
+		_, err = store.History("old")
+		g.Expect(err).To(MatchError(helmdriver.ErrReleaseNotFound))
+		g.Expect(testEnv.Get(context.TODO(), client.ObjectKeyFromObject(cm), cm)).To(Succeed())
+		g.Expect(cm.DeletionTimestamp).To(BeNil())
+
+		g.Expect(obj.Status.History).To(BeNil())
+		inventory.Release = ns.Name + "/old"
+		g.Expect(obj.Status.Inventory).To(Equal(inventory))
 	})
 
-	t.Run("sets last attempted values", func(t *testing.T) {
+	t.Run("retains created namespace if target has changed", func(t *testing.T) {
 		g := NewWithT(t)
 
+		ns, err := testEnv.CreateNamespace(context.TODO(), "target-changed")
+		g.Expect(err).ToNot(HaveOccurred())
+		t.Cleanup(func() {
+			_ = testEnv.Delete(context.TODO(), ns)
+		})
+
 		chartMock := testutil.BuildChart()
 		chartArtifact, err := testutil.SaveChartAsArtifact(chartMock, digest.SHA256, testServer.URL(), testServer.Root())
 		g.Expect(err).ToNot(HaveOccurred())
@@ -691,28 +872,36 @@ func TestHelmReleaseReconciler_reconcileRelease(t *testing.T) {
 
 		obj := &v2.HelmRelease{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:       "release",
-				Namespace:  "mock",
-				Generation: 2,
+				Name:      "release",
+				Namespace: "mock",
 			},
 			Spec: v2.HelmReleaseSpec{
-				// Trigger a failure by setting an invalid storage namespace,
-				// preventing the release from actually being installed.
-				// This allows us to just test the values being set, without
-				// having to facilitate a full install.
-				StorageNamespace: "not-exist",
-				Values: &apiextensionsv1.JSON{
-					Raw: []byte(`{"foo":"bar"}`),
+				TargetNamespace:  ns.Name,
+				StorageNamespace: "other",
+				Install: &v2.Install{
+					CreateNamespace: true,
+					CreateNamespaceOptions: &v2.CreateNamespaceOptions{
+						DeletePolicy: v2.NamespaceDeletePolicyDelete,
+					},
 				},
 			},
 			Status: v2.HelmReleaseStatus{
-				HelmChart:          "mock/chart",
-				ObservedGeneration: 2,
-				// Confirm deprecated value is cleared.
-				LastAttemptedValuesChecksum: "b5cbcf5c23cfd945d2cdf0ffaab387a46f2d054f",
+				History: v2.Snapshots{
+					{
+						Name:      "mock",
+						Namespace: ns.Name,
+					},
+				},
+				HelmChart:        "mock/chart",
+				StorageNamespace: "mock",
+				CreatedNamespace: ns.Name,
 			},
 		}
 
+		// Label the namespace as created for the release.
+		ns.Labels = action.ReleaseOwnerLabels(obj)
+		g.Expect(testEnv.Update(context.TODO(), ns)).To(Succeed())
+
 		c := fake.NewClientBuilder().
 			WithScheme(NewTestScheme()).
 			WithStatusSubresource(&v2.HelmRelease{}).
@@ -726,46 +915,31 @@ func TestHelmReleaseReconciler_reconcileRelease(t *testing.T) {
 			EventRecorder:    record.NewFakeRecorder(32),
 		}
 
-		_, err = r.reconcileRelease(context.TODO(), patch.NewSerialPatcher(obj, c), obj)
-		g.Expect(err).To(HaveOccurred())
-		g.Expect(err.Error()).To(ContainSubstring("namespaces \"not-exist\" not found"))
+		res, err := r.reconcileRelease(context.TODO(), patch.NewSerialPatcher(obj, c), obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res.Requeue).To(BeTrue())
 
-		// Verify attempted values are set.
-		g.Expect(obj.Status.LastAttemptedGeneration).To(Equal(obj.Generation))
-		g.Expect(obj.Status.LastAttemptedRevision).To(Equal(chartMock.Metadata.Version))
-		g.Expect(obj.Status.LastAttemptedConfigDigest).To(Equal("sha256:1dabc4e3cbbd6a0818bd460f3a6c9855bfe95d506c74726bc0f2edb0aecb1f4e"))
-		g.Expect(obj.Status.LastAttemptedValuesChecksum).To(BeEmpty())
+		// Verify the namespace is retained, and remains on record to be
+		// deleted together with the HelmRelease.
+		g.Expect(testEnv.Get(context.TODO(), client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+		g.Expect(ns.DeletionTimestamp).To(BeNil())
+		g.Expect(obj.Status.CreatedNamespace).To(Equal(ns.Name))
+		g.Expect(obj.Status.StorageNamespace).To(BeEmpty())
 	})
 
-	t.Run("error recovery updates ready condition", func(t *testing.T) {
+	t.Run("does not uninstall on target change while paused", func(t *testing.T) {
 		g := NewWithT(t)
 
-		// Create a test namespace for storing the Helm release mock.
-		ns, err := testEnv.CreateNamespace(context.TODO(), "error-recovery")
-		g.Expect(err).ToNot(HaveOccurred())
-		t.Cleanup(func() {
-			_ = testEnv.Delete(context.TODO(), ns)
-		})
-
-		// Create HelmChart mock.
 		chartMock := testutil.BuildChart()
 		chartArtifact, err := testutil.SaveChartAsArtifact(chartMock, digest.SHA256, testServer.URL(), testServer.Root())
 		g.Expect(err).ToNot(HaveOccurred())
 
 		chart := &sourcev1.HelmChart{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:       "error-recovery",
-				Namespace:  ns.Name,
+				Name:       "chart",
+				Namespace:  "mock",
 				Generation: 1,
 			},
-			Spec: sourcev1.HelmChartSpec{
-				Chart:   "testdata/test-helmrepo",
-				Version: "0.1.0",
-				SourceRef: sourcev1.LocalHelmChartSourceReference{
-					Kind: sourcev1.HelmRepositoryKind,
-					Name: "error-recovery",
-				},
-			},
 			Status: sourcev1.HelmChartStatus{
 				ObservedGeneration: 1,
 				Artifact:           chartArtifact,
@@ -778,19 +952,254 @@ func TestHelmReleaseReconciler_reconcileRelease(t *testing.T) {
 			},
 		}
 
-		// Create a test Helm release storage mock.
-		rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
-			Name:      "error-recovery",
-			Namespace: ns.Name,
-			Version:   1,
-			Chart:     chartMock,
-			Status:    helmrelease.StatusDeployed,
-		}, testutil.ReleaseWithConfig(nil))
-
+		history := v2.Snapshots{
+			{
+				Name:      "mock",
+				Namespace: "mock",
+			},
+		}
 		obj := &v2.HelmRelease{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      "error-recovery",
-				Namespace: ns.Name,
+				Name:      "release",
+				Namespace: "mock",
+				Annotations: map[string]string{
+					v2.PauseAnnotation: "true",
+				},
+			},
+			Spec: v2.HelmReleaseSpec{
+				TargetNamespace: "changed",
+			},
+			Status: v2.HelmReleaseStatus{
+				History:          history.DeepCopy(),
+				HelmChart:        "mock/chart",
+				StorageNamespace: "mock",
+			},
+		}
+
+		c := fake.NewClientBuilder().
+			WithScheme(NewTestScheme()).
+			WithStatusSubresource(&v2.HelmRelease{}).
+			WithObjects(chart, obj).
+			Build()
+
+		r := &HelmReleaseReconciler{
+			Client:           c,
+			APIReader:        c,
+			GetClusterConfig: GetTestClusterConfig,
+			EventRecorder:    record.NewFakeRecorder(32),
+		}
+
+		res, err := r.reconcileRelease(context.TODO(), patch.NewSerialPatcher(obj, c), obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res.Requeue).To(BeFalse())
+		g.Expect(res.RequeueAfter).ToNot(BeZero())
+
+		// Verify the previous release is kept on record, as it was not
+		// uninstalled.
+		g.Expect(obj.Status.History).To(Equal(history))
+		g.Expect(obj.Status.StorageNamespace).To(Equal("mock"))
+		g.Expect(conditions.GetReason(obj, meta.ReadyCondition)).To(Equal(v2.PausedReason))
+		g.Expect(conditions.IsUnknown(obj, meta.ReadyCondition)).To(BeTrue())
+	})
+
+	t.Run("resets failure counts on configuration change", func(t *testing.T) {
+		g := NewWithT(t)
+
+		chartMock := testutil.BuildChart()
+		chartArtifact, err := testutil.SaveChartAsArtifact(chartMock, digest.SHA256, testServer.URL(), testServer.Root())
+		g.Expect(err).ToNot(HaveOccurred())
+
+		chart := &sourcev1.HelmChart{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "chart",
+				Namespace:  "mock",
+				Generation: 1,
+			},
+			Status: sourcev1.HelmChartStatus{
+				ObservedGeneration: 1,
+				Artifact:           chartArtifact,
+				Conditions: []metav1.Condition{
+					{
+						Type:   meta.ReadyCondition,
+						Status: metav1.ConditionTrue,
+					},
+				},
+			},
+		}
+
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "release",
+				Namespace:  "mock",
+				Generation: 2,
+			},
+			Spec: v2.HelmReleaseSpec{
+				// Trigger a failure by setting an invalid storage namespace,
+				// preventing the release from actually being installed.
+				// This allows us to just test the failure count reset, without
+				// having to facilitate a full install.
+				StorageNamespace: "not-exist",
+			},
+			Status: v2.HelmReleaseStatus{
+				HelmChart:       "mock/chart",
+				InstallFailures: 2,
+				UpgradeFailures: 3,
+				Failures:        5,
+				// Trigger actual failure reset due to change in spec.
+				LastAttemptedGeneration: 1,
+			},
+		}
+
+		c := fake.NewClientBuilder().
+			WithScheme(NewTestScheme()).
+			WithStatusSubresource(&v2.HelmRelease{}).
+			WithObjects(chart, obj).
+			Build()
+
+		r := &HelmReleaseReconciler{
+			Client:           c,
+			APIReader:        c,
+			GetClusterConfig: GetTestClusterConfig,
+			EventRecorder:    record.NewFakeRecorder(32),
+		}
+
+		_, err = r.reconcileRelease(context.TODO(), patch.NewSerialPatcher(obj, c), obj)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("namespaces \"not-exist\" not found"))
+
+		// Verify failure counts are reset.
+		g.Expect(obj.Status.InstallFailures).To(Equal(int64(0)))
+		g.Expect(obj.Status.UpgradeFailures).To(Equal(int64(0)))
+		g.Expect(obj.Status.Failures).To(Equal(int64(1)))
+	})
+
+	t.Run("sets last attempted values", func(t *testing.T) {
+		g := NewWithT(t)
+
+		chartMock := testutil.BuildChart()
+		chartArtifact, err := testutil.SaveChartAsArtifact(chartMock, digest.SHA256, testServer.URL(), testServer.Root())
+		g.Expect(err).ToNot(HaveOccurred())
+
+		chart := &sourcev1.HelmChart{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "chart",
+				Namespace:  "mock",
+				Generation: 1,
+			},
+			Status: sourcev1.HelmChartStatus{
+				ObservedGeneration: 1,
+				Artifact:           chartArtifact,
+				Conditions: []metav1.Condition{
+					{
+						Type:   meta.ReadyCondition,
+						Status: metav1.ConditionTrue,
+					},
+				},
+			},
+		}
+
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "release",
+				Namespace:  "mock",
+				Generation: 2,
+			},
+			Spec: v2.HelmReleaseSpec{
+				// Trigger a failure by setting an invalid storage namespace,
+				// preventing the release from actually being installed.
+				// This allows us to just test the values being set, without
+				// having to facilitate a full install.
+				StorageNamespace: "not-exist",
+				Values: &apiextensionsv1.JSON{
+					Raw: []byte(`{"foo":"bar"}`),
+				},
+			},
+			Status: v2.HelmReleaseStatus{
+				HelmChart:          "mock/chart",
+				ObservedGeneration: 2,
+				// Confirm deprecated value is cleared.
+				LastAttemptedValuesChecksum: "b5cbcf5c23cfd945d2cdf0ffaab387a46f2d054f",
+			},
+		}
+
+		c := fake.NewClientBuilder().
+			WithScheme(NewTestScheme()).
+			WithStatusSubresource(&v2.HelmRelease{}).
+			WithObjects(chart, obj).
+			Build()
+
+		r := &HelmReleaseReconciler{
+			Client:           c,
+			APIReader:        c,
+			GetClusterConfig: GetTestClusterConfig,
+			EventRecorder:    record.NewFakeRecorder(32),
+		}
+
+		_, err = r.reconcileRelease(context.TODO(), patch.NewSerialPatcher(obj, c), obj)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("namespaces \"not-exist\" not found"))
+
+		// Verify attempted values are set.
+		g.Expect(obj.Status.LastAttemptedGeneration).To(Equal(obj.Generation))
+		g.Expect(obj.Status.LastAttemptedRevision).To(Equal(chartMock.Metadata.Version))
+		g.Expect(obj.Status.LastAttemptedConfigDigest).To(Equal("sha256:1dabc4e3cbbd6a0818bd460f3a6c9855bfe95d506c74726bc0f2edb0aecb1f4e"))
+		g.Expect(obj.Status.LastAttemptedValuesChecksum).To(BeEmpty())
+	})
+
+	t.Run("error recovery updates ready condition", func(t *testing.T) {
+		g := NewWithT(t)
+
+		// Create a test namespace for storing the Helm release mock.
+		ns, err := testEnv.CreateNamespace(context.TODO(), "error-recovery")
+		g.Expect(err).ToNot(HaveOccurred())
+		t.Cleanup(func() {
+			_ = testEnv.Delete(context.TODO(), ns)
+		})
+
+		// Create HelmChart mock.
+		chartMock := testutil.BuildChart()
+		chartArtifact, err := testutil.SaveChartAsArtifact(chartMock, digest.SHA256, testServer.URL(), testServer.Root())
+		g.Expect(err).ToNot(HaveOccurred())
+
+		chart := &sourcev1.HelmChart{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "error-recovery",
+				Namespace:  ns.Name,
+				Generation: 1,
+			},
+			Spec: sourcev1.HelmChartSpec{
+				Chart:   "testdata/test-helmrepo",
+				Version: "0.1.0",
+				SourceRef: sourcev1.LocalHelmChartSourceReference{
+					Kind: sourcev1.HelmRepositoryKind,
+					Name: "error-recovery",
+				},
+			},
+			Status: sourcev1.HelmChartStatus{
+				ObservedGeneration: 1,
+				Artifact:           chartArtifact,
+				Conditions: []metav1.Condition{
+					{
+						Type:   meta.ReadyCondition,
+						Status: metav1.ConditionTrue,
+					},
+				},
+			},
+		}
+
+		// Create a test Helm release storage mock.
+		rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+			Name:      "error-recovery",
+			Namespace: ns.Name,
+			Version:   1,
+			Chart:     chartMock,
+			Status:    helmrelease.StatusDeployed,
+		}, testutil.ReleaseWithConfig(nil))
+
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "error-recovery",
+				Namespace: ns.Name,
 			},
 			Spec: v2.HelmReleaseSpec{
 				StorageNamespace: ns.Name,
@@ -835,6 +1244,7 @@ func TestHelmReleaseReconciler_reconcileRelease(t *testing.T) {
 			aclv1.AccessDeniedReason,
 			v2.ArtifactFailedReason,
 			"SourceNotReady",
+			v2.ArtifactNotReadyReason,
 			"ValuesError",
 			"RESTClientError",
 			"FactoryError",
@@ -868,6 +1278,9 @@ func TestHelmReleaseReconciler_reconcileReleaseFromHelmChartSource(t *testing.T)
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      "release",
 				Namespace: "mock",
+				Annotations: map[string]string{
+					meta.ReconcileRequestAnnotation: "now",
+				},
 			},
 			Spec: v2.HelmReleaseSpec{
 				ChartRef: &v2.CrossNamespaceSourceReference{
@@ -904,6 +1317,13 @@ func TestHelmReleaseReconciler_reconcileReleaseFromHelmChartSource(t *testing.T)
 		// only chartRef or Chart must be set
 		g.Expect(errors.Is(err, reconcile.TerminalError(fmt.Errorf("invalid Chart reference")))).To(BeTrue())
 		g.Expect(res.IsZero()).To(BeTrue())
+
+		// The reconcile request is handled, even though no release is made.
+		got := &v2.HelmRelease{}
+		g.Expect(r.Get(context.TODO(), client.ObjectKeyFromObject(obj), got)).To(Succeed())
+		g.Expect(got.Status.LastHandledReconcileAt).To(Equal("now"))
+		g.Expect(conditions.IsStalled(got)).To(BeTrue())
+		g.Expect(conditions.GetReason(got, meta.ReadyCondition)).To(Equal(v2.InvalidChartReferenceReason))
 	})
 
 	t.Run("handles ChartRef get failure", func(t *testing.T) {
@@ -1031,7 +1451,7 @@ func TestHelmReleaseReconciler_reconcileReleaseFromHelmChartSource(t *testing.T)
 
 		g.Expect(obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{
 			*conditions.TrueCondition(meta.ReconcilingCondition, meta.ProgressingReason, ""),
-			*conditions.FalseCondition(meta.ReadyCondition, "SourceNotReady", "HelmChart 'mock/chart' is not ready"),
+			*conditions.UnknownCondition(meta.ReadyCondition, v2.ArtifactNotReadyReason, "HelmChart 'mock/chart' does not have an artifact yet"),
 		}))
 	})
 
@@ -1094,38 +1514,82 @@ func TestHelmReleaseReconciler_reconcileReleaseFromHelmChartSource(t *testing.T)
 			*conditions.FalseCondition(meta.ReadyCondition, v2.ArtifactFailedReason, "Source not ready"),
 		}))
 	})
-	t.Run("report helmChart load failure when switching from existing HelmChat to chartRef", func(t *testing.T) {
+	t.Run("reports malformed embedded chart", func(t *testing.T) {
 		g := NewWithT(t)
 
-		chart := &sourcev1.HelmChart{
+		cm := &corev1.ConfigMap{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:       "chart",
-				Namespace:  "mock",
-				Generation: 1,
+				Name:      "chart",
+				Namespace: "mock",
 			},
-			Status: sourcev1.HelmChartStatus{
-				ObservedGeneration: 1,
-				Artifact:           &sourcev1.Artifact{},
-				Conditions: []metav1.Condition{
-					{
-						Type:   meta.ReadyCondition,
-						Status: metav1.ConditionTrue,
-					},
-				},
+			Data: map[string]string{
+				v2.DefaultEmbeddedChartKey: "not a chart!",
 			},
 		}
 
-		sharedChart := &sourcev1.HelmChart{
+		obj := &v2.HelmRelease{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:       "sharedChart",
-				Namespace:  "mock",
-				Generation: 2,
+				Name:      "release",
+				Namespace: "mock",
 			},
-			Spec: sourcev1.HelmChartSpec{
-				Interval: metav1.Duration{Duration: 1 * time.Second},
+			Spec: v2.HelmReleaseSpec{
+				ChartRef: &v2.CrossNamespaceSourceReference{
+					Kind: "ConfigMap",
+					Name: "chart",
+				},
 			},
-			Status: sourcev1.HelmChartStatus{
-				ObservedGeneration: 2,
+		}
+
+		r := &HelmReleaseReconciler{
+			Client: fake.NewClientBuilder().
+				WithScheme(NewTestScheme()).
+				WithStatusSubresource(&v2.HelmRelease{}).
+				WithObjects(cm, obj).
+				Build(),
+			EventRecorder: record.NewFakeRecorder(32),
+		}
+
+		_, err := r.reconcileRelease(context.TODO(), patch.NewSerialPatcher(obj, r.Client), obj)
+		g.Expect(err).To(MatchError(loader.ErrInvalidEmbeddedChart))
+
+		g.Expect(obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{
+			*conditions.TrueCondition(meta.ReconcilingCondition, meta.ProgressingReason, ""),
+			*conditions.FalseCondition(meta.ReadyCondition, v2.InvalidEmbeddedChartReason, "Could not load chart: invalid embedded chart"),
+		}))
+	})
+
+	t.Run("report helmChart load failure when switching from existing HelmChat to chartRef", func(t *testing.T) {
+		g := NewWithT(t)
+
+		chart := &sourcev1.HelmChart{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "chart",
+				Namespace:  "mock",
+				Generation: 1,
+			},
+			Status: sourcev1.HelmChartStatus{
+				ObservedGeneration: 1,
+				Artifact:           &sourcev1.Artifact{},
+				Conditions: []metav1.Condition{
+					{
+						Type:   meta.ReadyCondition,
+						Status: metav1.ConditionTrue,
+					},
+				},
+			},
+		}
+
+		sharedChart := &sourcev1.HelmChart{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "sharedChart",
+				Namespace:  "mock",
+				Generation: 2,
+			},
+			Spec: sourcev1.HelmChartSpec{
+				Interval: metav1.Duration{Duration: 1 * time.Second},
+			},
+			Status: sourcev1.HelmChartStatus{
+				ObservedGeneration: 2,
 				Artifact: &sourcev1.Artifact{
 					URL: testServer.URL() + "/does-not-exist",
 				},
@@ -1498,7 +1962,7 @@ func TestHelmReleaseReconciler_reconcileReleaseFromOCIRepositorySource(t *testin
 
 		g.Expect(obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{
 			*conditions.TrueCondition(meta.ReconcilingCondition, meta.ProgressingReason, ""),
-			*conditions.FalseCondition(meta.ReadyCondition, "SourceNotReady", "OCIRepository 'mock/ocirepo' is not ready"),
+			*conditions.UnknownCondition(meta.ReadyCondition, v2.ArtifactNotReadyReason, "OCIRepository 'mock/ocirepo' does not have an artifact yet"),
 		}))
 	})
 
@@ -2277,6 +2741,99 @@ func TestHelmReleaseReconciler_reconcileReleaseDeletion(t *testing.T) {
 		g.Expect(err).To(MatchError(helmdriver.ErrReleaseNotFound))
 	})
 
+	t.Run("refuses to uninstall with service account override not allowed", func(t *testing.T) {
+		g := NewWithT(t)
+
+		curSA, curOverride := kube.DefaultServiceAccountName, defaults.NoServiceAccountOverride
+		kube.DefaultServiceAccountName, defaults.NoServiceAccountOverride = "default", true
+		t.Cleanup(func() {
+			kube.DefaultServiceAccountName, defaults.NoServiceAccountOverride = curSA, curOverride
+		})
+
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "reconcile-delete",
+				Namespace:         "mock",
+				DeletionTimestamp: &metav1.Time{Time: time.Now()},
+			},
+			Spec: v2.HelmReleaseSpec{
+				ServiceAccountName: "privileged",
+			},
+			Status: v2.HelmReleaseStatus{
+				StorageNamespace: "mock",
+			},
+		}
+
+		recorder := testutil.NewFakeRecorder(10, false)
+		r := &HelmReleaseReconciler{
+			Client:           testEnv.Client,
+			APIReader:        testEnv.Client,
+			GetClusterConfig: GetTestClusterConfig,
+			EventRecorder:    recorder,
+		}
+
+		err := r.reconcileReleaseDeletion(context.TODO(), obj)
+		g.Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+		g.Expect(err).To(MatchError(ContainSubstring("cannot impersonate 'privileged'")))
+
+		g.Expect(obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{
+			*conditions.TrueCondition(meta.StalledCondition, v2.OverrideNotAllowedReason, "overriding the default service account is not allowed: cannot impersonate 'privileged'"),
+			*conditions.FalseCondition(meta.ReadyCondition, v2.OverrideNotAllowedReason, "overriding the default service account is not allowed: cannot impersonate 'privileged'"),
+		}))
+		g.Expect(obj.Status.StorageNamespace).To(Equal("mock"))
+	})
+
+	t.Run("deletes namespace created for Helm release", func(t *testing.T) {
+		g := NewWithT(t)
+
+		ns, err := testEnv.CreateNamespace(context.TODO(), "reconcile-release-deletion")
+		g.Expect(err).ToNot(HaveOccurred())
+		t.Cleanup(func() {
+			_ = testEnv.Delete(context.TODO(), ns)
+		})
+
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "reconcile-delete",
+				Namespace:         ns.Name,
+				DeletionTimestamp: &metav1.Time{Time: time.Now()},
+			},
+			Spec: v2.HelmReleaseSpec{
+				TargetNamespace: ns.Name,
+				Install: &v2.Install{
+					CreateNamespace: true,
+					CreateNamespaceOptions: &v2.CreateNamespaceOptions{
+						DeletePolicy: v2.NamespaceDeletePolicyDelete,
+					},
+				},
+			},
+			Status: v2.HelmReleaseStatus{
+				StorageNamespace: ns.Name,
+				CreatedNamespace: ns.Name,
+			},
+		}
+
+		// Label the namespace as created for the release.
+		ns.Labels = action.ReleaseOwnerLabels(obj)
+		g.Expect(testEnv.Update(context.TODO(), ns)).To(Succeed())
+
+		r := &HelmReleaseReconciler{
+			Client:           testEnv.Client,
+			APIReader:        testEnv.Client,
+			GetClusterConfig: GetTestClusterConfig,
+			EventRecorder:    record.NewFakeRecorder(32),
+		}
+
+		err = r.reconcileReleaseDeletion(context.TODO(), obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(obj.Status.CreatedNamespace).To(BeEmpty())
+
+		// Namespaces are not finalized in the test environment, hence the
+		// namespace is only marked for deletion.
+		g.Expect(testEnv.Get(context.TODO(), client.ObjectKeyFromObject(ns), ns)).To(Succeed())
+		g.Expect(ns.DeletionTimestamp).ToNot(BeNil())
+	})
+
 	t.Run("skip uninstalling Helm release when KubeConfig Secret is missing", func(t *testing.T) {
 		g := NewWithT(t)
 
@@ -2839,6 +3396,42 @@ func TestHelmReleaseReconciler_checkDependencies(t *testing.T) {
 				g.Expect(err.Error()).To(ContainSubstring("is not ready"))
 			},
 		},
+		{
+			name: "error on remediated dependency",
+			obj: &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "dependant",
+					Namespace: "some-namespace",
+				},
+				Spec: v2.HelmReleaseSpec{
+					DependsOn: []meta.NamespacedObjectReference{
+						{
+							Name: "dependency-1",
+						},
+					},
+				},
+			},
+			objects: []client.Object{
+				&v2.HelmRelease{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation: 1,
+						Name:       "dependency-1",
+						Namespace:  "some-namespace",
+					},
+					Status: v2.HelmReleaseStatus{
+						ObservedGeneration: 1,
+						Conditions: []metav1.Condition{
+							{Type: meta.ReadyCondition, Status: metav1.ConditionFalse},
+							{Type: v2.RemediatedCondition, Status: metav1.ConditionTrue, Message: "rolled back"},
+						},
+					},
+				},
+			},
+			expect: func(g *WithT, err error) {
+				g.Expect(err).To(MatchError(errDependencyRemediated))
+				g.Expect(err.Error()).To(ContainSubstring("'some-namespace/dependency-1': rolled back"))
+			},
+		},
 		{
 			name: "error on dependency without conditions",
 			obj: &v2.HelmRelease{
@@ -3114,6 +3707,7 @@ users:
 		spec      v2.HelmReleaseSpec
 		secret    *corev1.Secret
 		want      genericclioptions.RESTClientGetter
+		wantID    *v2.ClientIdentity
 		wantErr   string
 	}{
 		{
@@ -3121,8 +3715,22 @@ users:
 			getConfig: func() (*rest.Config, error) {
 				return clientcmd.RESTConfigFromKubeConfig([]byte(kubeCfg))
 			},
-			spec: v2.HelmReleaseSpec{},
+			spec:   v2.HelmReleaseSpec{},
+			want:   &kube.MemoryRESTClientGetter{},
+			wantID: &v2.ClientIdentity{},
+		},
+		{
+			name: "builds impersonating RESTClientGetter for HelmRelease",
+			getConfig: func() (*rest.Config, error) {
+				return clientcmd.RESTConfigFromKubeConfig([]byte(kubeCfg))
+			},
+			spec: v2.HelmReleaseSpec{
+				ServiceAccountName: "some-sa",
+			},
 			want: &kube.MemoryRESTClientGetter{},
+			wantID: &v2.ClientIdentity{
+				Impersonate: "system:serviceaccount:" + namespace + ":some-sa",
+			},
 		},
 		{
 			name: "returns error when in-cluster GetClusterConfig fails",
@@ -3150,6 +3758,11 @@ users:
 				},
 			},
 			want: &kube.MemoryRESTClientGetter{},
+			wantID: &v2.ClientIdentity{
+				KubeConfigSecret:  "kubeconfig",
+				KubeConfigContext: "dev-frontend",
+				KubeConfigUser:    "developer",
+			},
 		},
 		{
 			name: "error on missing KubeConfig secret",
@@ -3200,19 +3813,21 @@ users:
 				GetClusterConfig: tt.getConfig,
 			}
 
-			getter, err := r.buildRESTClientGetter(context.Background(), &v2.HelmRelease{
+			obj := &v2.HelmRelease{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:      "some-name",
 					Namespace: namespace,
 				},
 				Spec: tt.spec,
-			})
+			}
+			getter, err := r.buildRESTClientGetter(context.Background(), obj)
 			if len(tt.wantErr) > 0 {
 				g.Expect(err).To(HaveOccurred())
 				g.Expect(err.Error()).To(ContainSubstring(tt.wantErr))
 			} else {
 				g.Expect(err).ToNot(HaveOccurred())
 				g.Expect(getter).To(BeAssignableToTypeOf(tt.want))
+				g.Expect(obj.Status.ClientIdentity).To(Equal(tt.wantID))
 			}
 		})
 	}
@@ -3844,3 +4459,828 @@ func Test_TryMutateChartWithSourceRevision(t *testing.T) {
 	}
 
 }
+
+func Test_findDependencyCycle(t *testing.T) {
+	nn := func(namespace, name string) types.NamespacedName {
+		return types.NamespacedName{Namespace: namespace, Name: name}
+	}
+
+	tests := []struct {
+		name      string
+		graph     map[types.NamespacedName][]types.NamespacedName
+		root      types.NamespacedName
+		wantCycle []types.NamespacedName
+		wantErr   bool
+	}{
+		{
+			name: "no dependencies",
+			graph: map[types.NamespacedName][]types.NamespacedName{
+				nn("default", "a"): nil,
+			},
+			root: nn("default", "a"),
+		},
+		{
+			name: "acyclic diamond",
+			graph: map[types.NamespacedName][]types.NamespacedName{
+				nn("default", "a"): {nn("default", "b"), nn("default", "c")},
+				nn("default", "b"): {nn("default", "d")},
+				nn("default", "c"): {nn("default", "d")},
+			},
+			root: nn("default", "a"),
+		},
+		{
+			name: "self dependency",
+			graph: map[types.NamespacedName][]types.NamespacedName{
+				nn("default", "a"): {nn("default", "a")},
+			},
+			root:      nn("default", "a"),
+			wantCycle: []types.NamespacedName{nn("default", "a"), nn("default", "a")},
+		},
+		{
+			name: "cross-namespace cycle",
+			graph: map[types.NamespacedName][]types.NamespacedName{
+				nn("team-a", "a"): {nn("team-b", "b")},
+				nn("team-b", "b"): {nn("team-c", "c")},
+				nn("team-c", "c"): {nn("team-a", "a")},
+			},
+			root:      nn("team-a", "a"),
+			wantCycle: []types.NamespacedName{nn("team-a", "a"), nn("team-b", "b"), nn("team-c", "c"), nn("team-a", "a")},
+		},
+		{
+			name: "deadlock in transitive dependency",
+			graph: map[types.NamespacedName][]types.NamespacedName{
+				nn("default", "a"): {nn("default", "b")},
+				nn("default", "b"): {nn("default", "c")},
+				nn("default", "c"): {nn("default", "b")},
+			},
+			root:      nn("default", "a"),
+			wantCycle: []types.NamespacedName{nn("default", "b"), nn("default", "c"), nn("default", "b")},
+		},
+		{
+			name: "error resolving dependencies",
+			graph: map[types.NamespacedName][]types.NamespacedName{
+				nn("default", "a"): {nn("default", "error")},
+			},
+			root:    nn("default", "a"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cycle, err := findDependencyCycle(tt.root, func(ref types.NamespacedName) ([]types.NamespacedName, error) {
+				if ref.Name == "error" {
+					return nil, errors.New("resolve error")
+				}
+				return tt.graph[ref], nil
+			})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(cycle).To(Equal(tt.wantCycle))
+		})
+	}
+}
+
+func Test_isSourceSuspended(t *testing.T) {
+	tests := []struct {
+		name          string
+		source        sourcev1.Source
+		wantSuspended bool
+		wantMsg       string
+	}{
+		{
+			name: "HelmChart not suspended",
+			source: &sourcev1.HelmChart{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "chart"},
+			},
+		},
+		{
+			name: "HelmChart suspended",
+			source: &sourcev1.HelmChart{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "chart"},
+				Spec:       sourcev1.HelmChartSpec{Suspend: true},
+			},
+			wantSuspended: true,
+			wantMsg:       "HelmChart 'default/chart' is suspended",
+		},
+		{
+			name: "OCIRepository suspended",
+			source: &sourcev1beta2.OCIRepository{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "repo"},
+				Spec:       sourcev1beta2.OCIRepositorySpec{Suspend: true},
+			},
+			wantSuspended: true,
+			wantMsg:       "OCIRepository 'default/repo' is suspended",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			suspended, msg := isSourceSuspended(tt.source)
+			g.Expect(suspended).To(Equal(tt.wantSuspended))
+			g.Expect(msg).To(Equal(tt.wantMsg))
+		})
+	}
+}
+
+func Test_isPinnedByDigest(t *testing.T) {
+	tests := []struct {
+		name   string
+		source sourcev1.Source
+		want   bool
+	}{
+		{
+			name:   "HelmChart",
+			source: &sourcev1.HelmChart{},
+		},
+		{
+			name: "OCIRepository with semver",
+			source: &sourcev1beta2.OCIRepository{
+				Spec: sourcev1beta2.OCIRepositorySpec{
+					Reference: &sourcev1beta2.OCIRepositoryRef{SemVer: ">=1.0.0"},
+				},
+			},
+		},
+		{
+			name: "OCIRepository with digest",
+			source: &sourcev1beta2.OCIRepository{
+				Spec: sourcev1beta2.OCIRepositorySpec{
+					Reference: &sourcev1beta2.OCIRepositoryRef{Digest: "sha256:abc"},
+				},
+			},
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(isPinnedByDigest(tt.source)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_isSourceAuthFailed(t *testing.T) {
+	tests := []struct {
+		name       string
+		conditions []metav1.Condition
+		wantFailed bool
+		wantMsg    string
+	}{
+		{
+			name: "ready",
+			conditions: []metav1.Condition{
+				{Type: meta.ReadyCondition, Status: metav1.ConditionTrue, Reason: meta.SucceededReason},
+			},
+		},
+		{
+			name: "not ready for other reason",
+			conditions: []metav1.Condition{
+				{Type: meta.ReadyCondition, Status: metav1.ConditionFalse, Reason: sourcev1.FetchFailedCondition},
+			},
+		},
+		{
+			name: "authentication failed",
+			conditions: []metav1.Condition{
+				{Type: meta.ReadyCondition, Status: metav1.ConditionFalse, Reason: sourcev1.AuthenticationFailedReason,
+					Message: "failed to login to registry"},
+			},
+			wantFailed: true,
+			wantMsg:    "HelmChart 'default/chart' failed to authenticate: failed to login to registry",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			source := &sourcev1.HelmChart{
+				TypeMeta:   metav1.TypeMeta{Kind: sourcev1.HelmChartKind},
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "chart"},
+				Status:     sourcev1.HelmChartStatus{Conditions: tt.conditions},
+			}
+			failed, msg := isSourceAuthFailed(source)
+			g.Expect(failed).To(Equal(tt.wantFailed))
+			g.Expect(msg).To(Equal(tt.wantMsg))
+		})
+	}
+}
+
+func Test_checkChartDeprecation(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    v2.ChartDeprecationPolicy
+		metadata  *chart.Metadata
+		wantMsg   string
+		wantBlock bool
+	}{
+		{
+			name:     "chart not deprecated",
+			metadata: &chart.Metadata{Name: "podinfo", Version: "6.0.0"},
+		},
+		{
+			name:     "deprecated chart with default policy",
+			metadata: &chart.Metadata{Name: "podinfo", Version: "6.0.0", Deprecated: true},
+			wantMsg:  "chart 'podinfo' version '6.0.0' is deprecated",
+		},
+		{
+			name:      "deprecated chart with block policy",
+			policy:    v2.ChartDeprecationBlock,
+			metadata:  &chart.Metadata{Name: "podinfo", Version: "6.0.0", Deprecated: true},
+			wantMsg:   "blocking release per chart deprecation policy",
+			wantBlock: true,
+		},
+		{
+			name:   "chart without metadata",
+			policy: v2.ChartDeprecationBlock,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &v2.HelmRelease{
+				Spec: v2.HelmReleaseSpec{
+					ChartDeprecationPolicy: tt.policy,
+				},
+			}
+
+			msg, block := checkChartDeprecation(obj, tt.metadata)
+			if tt.wantMsg == "" {
+				g.Expect(msg).To(BeEmpty())
+			} else {
+				g.Expect(msg).To(ContainSubstring(tt.wantMsg))
+			}
+			g.Expect(block).To(Equal(tt.wantBlock))
+		})
+	}
+}
+
+func Test_checkNamespaceTermination(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		policy    v2.NamespaceTerminationPolicy
+		ns        *corev1.Namespace
+		wantMsg   string
+		wantStall bool
+	}{
+		{
+			name: "active namespace",
+			ns: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{Name: "target"},
+				Status:     corev1.NamespaceStatus{Phase: corev1.NamespaceActive},
+			},
+		},
+		{
+			name: "terminating namespace with wait policy",
+			ns: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "target",
+					DeletionTimestamp: &metav1.Time{Time: now.Add(-time.Minute)},
+				},
+				Status: corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+			},
+			wantMsg: "target namespace 'target' is terminating: waiting for removal",
+		},
+		{
+			name:   "terminating namespace with abort policy",
+			policy: v2.NamespaceTerminationAbort,
+			ns: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "target",
+					DeletionTimestamp: &metav1.Time{Time: now},
+				},
+				Status: corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+			},
+			wantMsg:   "aborting reconciliation per namespace termination policy, request a reconciliation once it has been removed",
+			wantStall: true,
+		},
+		{
+			name:   "namespace stuck terminating with wait policy",
+			policy: v2.NamespaceTerminationWait,
+			ns: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "target",
+					DeletionTimestamp: &metav1.Time{Time: now.Add(-v2.NamespaceTerminationStallThreshold - time.Minute)},
+				},
+				Status: corev1.NamespaceStatus{Phase: corev1.NamespaceTerminating},
+			},
+			wantMsg:   "check the namespace finalizers",
+			wantStall: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &v2.HelmRelease{
+				Spec: v2.HelmReleaseSpec{
+					NamespaceTerminationPolicy: tt.policy,
+				},
+			}
+
+			msg, stall := checkNamespaceTermination(obj, tt.ns, now)
+			if tt.wantMsg == "" {
+				g.Expect(msg).To(BeEmpty())
+			} else {
+				g.Expect(msg).To(ContainSubstring(tt.wantMsg))
+			}
+			g.Expect(stall).To(Equal(tt.wantStall))
+		})
+	}
+}
+
+func Test_getTargetNamespace(t *testing.T) {
+	t.Run("existing namespace", func(t *testing.T) {
+		g := NewWithT(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			g.Expect(r.URL.Path).To(Equal("/api/v1/namespaces/target"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"target"},"status":{"phase":"Terminating"}}`))
+		}))
+		t.Cleanup(server.Close)
+
+		getter := kube.NewMemoryRESTClientGetter(&rest.Config{Host: server.URL})
+		ns, err := getTargetNamespace(context.TODO(), getter, "target")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ns).ToNot(BeNil())
+		g.Expect(ns.Status.Phase).To(Equal(corev1.NamespaceTerminating))
+	})
+
+	t.Run("namespace not found", func(t *testing.T) {
+		g := NewWithT(t)
+
+		server := httptest.NewServer(http.NotFoundHandler())
+		t.Cleanup(server.Close)
+
+		getter := kube.NewMemoryRESTClientGetter(&rest.Config{Host: server.URL})
+		ns, err := getTargetNamespace(context.TODO(), getter, "target")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ns).To(BeNil())
+	})
+
+	t.Run("namespace forbidden", func(t *testing.T) {
+		g := NewWithT(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+		}))
+		t.Cleanup(server.Close)
+
+		getter := kube.NewMemoryRESTClientGetter(&rest.Config{Host: server.URL})
+		ns, err := getTargetNamespace(context.TODO(), getter, "target")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(ns).To(BeNil())
+	})
+
+	t.Run("returns error", func(t *testing.T) {
+		g := NewWithT(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		t.Cleanup(server.Close)
+
+		getter := kube.NewMemoryRESTClientGetter(&rest.Config{Host: server.URL})
+		ns, err := getTargetNamespace(context.TODO(), getter, "target")
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(ns).To(BeNil())
+	})
+}
+
+func Test_checkClusterReachable(t *testing.T) {
+	t.Run("reachable cluster", func(t *testing.T) {
+		g := NewWithT(t)
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			g.Expect(r.URL.Path).To(Equal("/version"))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"major":"1","minor":"30","gitVersion":"v1.30.0"}`))
+		}))
+		t.Cleanup(server.Close)
+
+		getter := kube.NewMemoryRESTClientGetter(&rest.Config{Host: server.URL})
+		g.Expect(checkClusterReachable(context.TODO(), getter)).To(Succeed())
+	})
+
+	t.Run("unreachable cluster", func(t *testing.T) {
+		g := NewWithT(t)
+
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		getter := kube.NewMemoryRESTClientGetter(&rest.Config{Host: server.URL})
+		g.Expect(checkClusterReachable(context.TODO(), getter)).ToNot(Succeed())
+	})
+}
+
+func Test_checkValuesReferences(t *testing.T) {
+	tests := []struct {
+		name       string
+		allowCross bool
+		refs       []v2.ValuesReference
+		wantErr    string
+	}{
+		{
+			name: "same namespace",
+			refs: []v2.ValuesReference{
+				{Kind: "ConfigMap", Name: "values"},
+				{Kind: "Secret", Name: "values", Namespace: "mock"},
+			},
+		},
+		{
+			name: "other namespace denied",
+			refs: []v2.ValuesReference{
+				{Kind: "ConfigMap", Name: "values"},
+				{Kind: "Secret", Name: "values", Namespace: "config"},
+			},
+			wantErr: "cross-namespace references are not allowed: cannot access Secret config/values",
+		},
+		{
+			name:       "other namespace allowed",
+			allowCross: true,
+			refs: []v2.ValuesReference{
+				{Kind: "Secret", Name: "values", Namespace: "config"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			curAllow := intacl.AllowCrossNamespaceRef
+			intacl.AllowCrossNamespaceRef = tt.allowCross
+			t.Cleanup(func() { intacl.AllowCrossNamespaceRef = curAllow })
+
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "mock"},
+				Spec:       v2.HelmReleaseSpec{ValuesFrom: tt.refs},
+			}
+			err := checkValuesReferences(obj)
+			if tt.wantErr != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tt.wantErr))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+func Test_retryBackoff(t *testing.T) {
+	t.Run("grows across consecutive failures", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{
+			Spec: v2.HelmReleaseSpec{
+				Interval:     metav1.Duration{Duration: 10 * time.Minute},
+				RetryBackoff: &v2.RetryBackoff{},
+			},
+		}
+		var got []time.Duration
+		for i := 0; i < 8; i++ {
+			obj.Status.ConsecutiveFailures++
+			got = append(got, retryBackoff(obj, 0.5))
+		}
+		g.Expect(got).To(Equal([]time.Duration{
+			10 * time.Second,
+			20 * time.Second,
+			40 * time.Second,
+			80 * time.Second,
+			160 * time.Second,
+			320 * time.Second,
+			10 * time.Minute,
+			10 * time.Minute,
+		}))
+	})
+
+	t.Run("resets on success", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{
+			Spec: v2.HelmReleaseSpec{
+				Interval: metav1.Duration{Duration: 10 * time.Minute},
+				RetryBackoff: &v2.RetryBackoff{
+					BaseInterval: &metav1.Duration{Duration: time.Minute},
+				},
+			},
+			Status: v2.HelmReleaseStatus{
+				ConsecutiveFailures: 3,
+			},
+		}
+		g.Expect(retryBackoff(obj, 0.5)).To(Equal(4 * time.Minute))
+
+		obj.Status.ClearFailures()
+		obj.Status.ConsecutiveFailures++
+		g.Expect(retryBackoff(obj, 0.5)).To(Equal(time.Minute))
+	})
+
+	t.Run("applies jitter", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{
+			Spec: v2.HelmReleaseSpec{
+				Interval: metav1.Duration{Duration: 10 * time.Minute},
+				RetryBackoff: &v2.RetryBackoff{
+					MaxInterval: &metav1.Duration{Duration: time.Hour},
+					Jitter:      ptr.To(50),
+				},
+			},
+			Status: v2.HelmReleaseStatus{
+				ConsecutiveFailures: 2,
+			},
+		}
+		g.Expect(retryBackoff(obj, 0)).To(Equal(10 * time.Second))
+		g.Expect(retryBackoff(obj, 0.5)).To(Equal(20 * time.Second))
+		g.Expect(retryBackoff(obj, 0.75)).To(Equal(25 * time.Second))
+	})
+}
+
+func Test_markRetryBackoff(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	obj := &v2.HelmRelease{}
+	conditions.MarkFalse(obj, meta.ReadyCondition, v2.InstallFailedReason, "install failed")
+	conditions.MarkReconciling(obj, meta.ProgressingWithRetryReason, "install failed")
+
+	markRetryBackoff(obj, 40*time.Second, now)
+	g.Expect(conditions.GetMessage(obj, meta.ReadyCondition)).To(Equal("install failed (retrying in 40s at 2024-05-15T12:00:40Z)"))
+	g.Expect(conditions.GetMessage(obj, meta.ReconcilingCondition)).To(Equal("install failed (retrying in 40s at 2024-05-15T12:00:40Z)"))
+	g.Expect(conditions.GetReason(obj, meta.ReadyCondition)).To(Equal(v2.InstallFailedReason))
+}
+
+func Test_requeueAfter(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name       string
+		adaptive   *v2.AdaptiveRequeue
+		stable     *v2.StableInterval
+		stableRuns int64
+		transition time.Time
+		want       time.Duration
+	}{
+		{
+			name:       "adaptive requeue disabled",
+			transition: now,
+			want:       10 * time.Minute,
+		},
+		{
+			name:     "no Ready condition",
+			adaptive: &v2.AdaptiveRequeue{},
+			want:     150 * time.Second,
+		},
+		{
+			name:       "recently transitioned",
+			adaptive:   &v2.AdaptiveRequeue{},
+			transition: now.Add(-time.Minute),
+			want:       150 * time.Second,
+		},
+		{
+			name:       "stable",
+			adaptive:   &v2.AdaptiveRequeue{},
+			transition: now.Add(-15 * time.Minute),
+			want:       15 * time.Minute,
+		},
+		{
+			name:       "stable beyond maximum",
+			adaptive:   &v2.AdaptiveRequeue{},
+			transition: now.Add(-24 * time.Hour),
+			want:       40 * time.Minute,
+		},
+		{
+			name: "custom bounds",
+			adaptive: &v2.AdaptiveRequeue{
+				MinInterval: &metav1.Duration{Duration: 30 * time.Second},
+				MaxInterval: &metav1.Duration{Duration: time.Hour},
+			},
+			transition: now.Add(-24 * time.Hour),
+			want:       time.Hour,
+		},
+		{
+			name: "maximum lower than minimum",
+			adaptive: &v2.AdaptiveRequeue{
+				MinInterval: &metav1.Duration{Duration: 5 * time.Minute},
+				MaxInterval: &metav1.Duration{Duration: time.Minute},
+			},
+			transition: now.Add(-24 * time.Hour),
+			want:       5 * time.Minute,
+		},
+		{
+			name:       "stable interval below threshold",
+			stable:     &v2.StableInterval{Interval: metav1.Duration{Duration: time.Hour}},
+			stableRuns: 2,
+			transition: now,
+			want:       10 * time.Minute,
+		},
+		{
+			name:       "stable interval at threshold",
+			stable:     &v2.StableInterval{Interval: metav1.Duration{Duration: time.Hour}},
+			stableRuns: 3,
+			transition: now,
+			want:       time.Hour,
+		},
+		{
+			name: "stable interval with custom threshold",
+			stable: &v2.StableInterval{
+				Interval:  metav1.Duration{Duration: time.Hour},
+				Threshold: ptr.To[int64](10),
+			},
+			stableRuns: 5,
+			transition: now,
+			want:       10 * time.Minute,
+		},
+		{
+			name:       "stable interval takes precedence over adaptive requeue",
+			adaptive:   &v2.AdaptiveRequeue{},
+			stable:     &v2.StableInterval{Interval: metav1.Duration{Duration: 2 * time.Hour}},
+			stableRuns: 3,
+			transition: now.Add(-24 * time.Hour),
+			want:       2 * time.Hour,
+		},
+		{
+			name:       "adaptive requeue while not stable",
+			adaptive:   &v2.AdaptiveRequeue{},
+			stable:     &v2.StableInterval{Interval: metav1.Duration{Duration: 2 * time.Hour}},
+			transition: now.Add(-time.Minute),
+			want:       150 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &v2.HelmRelease{
+				Spec: v2.HelmReleaseSpec{
+					Interval:        metav1.Duration{Duration: 10 * time.Minute},
+					AdaptiveRequeue: tt.adaptive,
+					StableInterval:  tt.stable,
+				},
+				Status: v2.HelmReleaseStatus{
+					StableReconciliations: tt.stableRuns,
+				},
+			}
+			if !tt.transition.IsZero() {
+				obj.Status.Conditions = []metav1.Condition{{
+					Type:               meta.ReadyCondition,
+					Status:             metav1.ConditionTrue,
+					LastTransitionTime: metav1.NewTime(tt.transition),
+				}}
+			}
+
+			g.Expect(requeueAfter(obj, now)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestHelmReleaseReconciler_waitForDependents(t *testing.T) {
+	now := time.Now()
+
+	newRelease := func(name string, dependsOn ...string) *v2.HelmRelease {
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "mock",
+			},
+		}
+		for _, d := range dependsOn {
+			obj.Spec.DependsOn = append(obj.Spec.DependsOn, meta.NamespacedObjectReference{Name: d})
+		}
+		return obj
+	}
+
+	tests := []struct {
+		name       string
+		obj        *v2.HelmRelease
+		objects    []client.Object
+		deletedAgo time.Duration
+		wantWait   bool
+		wantReady  *metav1.Condition
+	}{
+		{
+			name:    "no dependents",
+			obj:     newRelease("a"),
+			objects: []client.Object{newRelease("b")},
+		},
+		{
+			name:     "waits for dependents",
+			obj:      newRelease("a"),
+			objects:  []client.Object{newRelease("c", "a"), newRelease("b", "a"), newRelease("d")},
+			wantWait: true,
+			wantReady: conditions.FalseCondition(meta.ReadyCondition, v2.WaitingForDependentsReason,
+				"Waiting for dependents 'mock/b', 'mock/c' to be deleted before uninstalling"),
+		},
+		{
+			name:       "stops waiting after timeout",
+			obj:        newRelease("a"),
+			objects:    []client.Object{newRelease("b", "a")},
+			deletedAgo: v2.DefaultWaitForDependentsTimeout + time.Second,
+		},
+		{
+			name:    "does not wait for dependents in cycle",
+			obj:     newRelease("a", "b"),
+			objects: []client.Object{newRelease("b", "a")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			tt.obj.DeletionTimestamp = &metav1.Time{Time: now.Add(-tt.deletedAgo)}
+
+			c := fake.NewClientBuilder().
+				WithScheme(NewTestScheme()).
+				WithObjects(tt.objects...).
+				Build()
+			r := &HelmReleaseReconciler{
+				Client:            c,
+				APIReader:         c,
+				EventRecorder:     record.NewFakeRecorder(32),
+				requeueDependency: 5 * time.Second,
+			}
+
+			wait, err := r.waitForDependents(context.TODO(), tt.obj, now)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(wait).To(Equal(tt.wantWait))
+
+			if tt.wantReady != nil {
+				g.Expect(tt.obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{*tt.wantReady}))
+			} else {
+				g.Expect(tt.obj.Status.Conditions).To(BeEmpty())
+			}
+		})
+	}
+}
+
+func TestHelmReleaseReconciler_requestSourceRebuild(t *testing.T) {
+	t.Run("requests rebuild of HelmChart", func(t *testing.T) {
+		g := NewWithT(t)
+
+		chart := &sourcev1.HelmChart{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "some-namespace",
+				Name:      "some-chart-name",
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(NewTestScheme()).WithObjects(chart).Build()
+		r := &HelmReleaseReconciler{Client: c, FieldManager: "helm-controller"}
+
+		handled, err := r.requestSourceRebuild(context.TODO(), chart, "token")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(handled).To(BeFalse())
+
+		got := &sourcev1.HelmChart{}
+		g.Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(chart), got)).To(Succeed())
+		g.Expect(got.GetAnnotations()).To(HaveKeyWithValue(meta.ReconcileRequestAnnotation, "token"))
+
+		// The request is pending until source-controller handled it.
+		handled, err = r.requestSourceRebuild(context.TODO(), got, "token")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(handled).To(BeFalse())
+
+		got.Status.SetLastHandledReconcileRequest("token")
+		handled, err = r.requestSourceRebuild(context.TODO(), got, "token")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(handled).To(BeTrue())
+	})
+
+	t.Run("requests rebuild of OCIRepository", func(t *testing.T) {
+		g := NewWithT(t)
+
+		repository := &sourcev1beta2.OCIRepository{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: "some-namespace",
+				Name:      "some-repository-name",
+			},
+		}
+
+		c := fake.NewClientBuilder().WithScheme(NewTestScheme()).WithObjects(repository).Build()
+		r := &HelmReleaseReconciler{Client: c, FieldManager: "helm-controller"}
+
+		handled, err := r.requestSourceRebuild(context.TODO(), repository, "token")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(handled).To(BeFalse())
+
+		got := &sourcev1beta2.OCIRepository{}
+		g.Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(repository), got)).To(Succeed())
+		g.Expect(got.GetAnnotations()).To(HaveKeyWithValue(meta.ReconcileRequestAnnotation, "token"))
+	})
+
+	t.Run("handles request for embedded chart", func(t *testing.T) {
+		g := NewWithT(t)
+
+		r := &HelmReleaseReconciler{FieldManager: "helm-controller"}
+
+		handled, err := r.requestSourceRebuild(context.TODO(), &embeddedChartSource{}, "token")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(handled).To(BeTrue())
+	})
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/fluxcd/pkg/runtime/patch"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// +kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleasegroups,verbs=get;list;watch
+// +kubebuilder:rbac:groups=helm.toolkit.fluxcd.io,resources=helmreleasegroups/status,verbs=get;update;patch
+
+// HelmReleaseGroupReconciler reconciles a HelmReleaseGroup object, by
+// aggregating the readiness of the HelmReleases selected by the group.
+type HelmReleaseGroupReconciler struct {
+	client.Client
+
+	FieldManager string
+}
+
+func (r *HelmReleaseGroupReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v2.HelmReleaseGroup{}, builder.WithPredicates(
+			predicate.GenerationChangedPredicate{},
+		)).
+		Watches(
+			&v2.HelmRelease{},
+			handler.EnqueueRequestsFromMapFunc(r.requestsForHelmReleaseChange),
+		).
+		Complete(r)
+}
+
+func (r *HelmReleaseGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, retErr error) {
+	obj := &v2.HelmReleaseGroup{}
+	if err := r.Get(ctx, req.NamespacedName, obj); err != nil {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Initialize the patch helper with the current version of the object.
+	patchHelper := patch.NewSerialPatcher(obj, r.Client)
+
+	// Always attempt to patch the object after each reconciliation.
+	defer func() {
+		obj.Status.ObservedGeneration = obj.Generation
+		if err := patchHelper.Patch(ctx, obj, patch.WithOwnedConditions{
+			Conditions: []string{meta.ReadyCondition, meta.StalledCondition},
+		}, patch.WithFieldOwner(r.FieldManager)); err != nil {
+			retErr = err
+		}
+	}()
+
+	selector, err := metav1.LabelSelectorAsSelector(&obj.Spec.Selector)
+	if err != nil {
+		conditions.MarkStalled(obj, v2.InvalidSelectorReason, "Invalid selector: %s", err)
+		conditions.MarkFalse(obj, meta.ReadyCondition, v2.InvalidSelectorReason, "Invalid selector: %s", err)
+		return ctrl.Result{}, reconcile.TerminalError(err)
+	}
+	conditions.Delete(obj, meta.StalledCondition)
+
+	var list v2.HelmReleaseList
+	if err = r.List(ctx, &list, client.InNamespace(obj.GetNamespace()),
+		client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, "ListError", "Failed to list HelmReleases: %s", err)
+		return ctrl.Result{}, err
+	}
+
+	summarizeGroup(obj, list.Items)
+	return ctrl.Result{}, nil
+}
+
+// summarizeGroup records the aggregated readiness of the given members in the
+// status of the HelmReleaseGroup.
+func summarizeGroup(obj *v2.HelmReleaseGroup, members []v2.HelmRelease) {
+	obj.Status.Total = len(members)
+	obj.Status.Ready = 0
+	obj.Status.FailingMembers = nil
+
+	for i := range members {
+		member := groupMemberStatus(&members[i])
+		if member.Status == metav1.ConditionTrue {
+			obj.Status.Ready++
+			continue
+		}
+		obj.Status.FailingMembers = append(obj.Status.FailingMembers, member)
+	}
+	sort.Slice(obj.Status.FailingMembers, func(i, j int) bool {
+		return obj.Status.FailingMembers[i].Name < obj.Status.FailingMembers[j].Name
+	})
+
+	switch {
+	case obj.Status.Total == 0:
+		conditions.MarkFalse(obj, meta.ReadyCondition, v2.NoMembersReason, "No HelmReleases match the selector")
+	case len(obj.Status.FailingMembers) > 0:
+		names := make([]string, 0, len(obj.Status.FailingMembers))
+		for _, m := range obj.Status.FailingMembers {
+			names = append(names, m.Name)
+		}
+		conditions.MarkFalse(obj, meta.ReadyCondition, v2.MembersNotReadyReason, "%d/%d HelmReleases are ready, not ready: %s",
+			obj.Status.Ready, obj.Status.Total, strings.Join(names, ", "))
+	default:
+		conditions.MarkTrue(obj, meta.ReadyCondition, meta.SucceededReason, "%d/%d HelmReleases are ready",
+			obj.Status.Ready, obj.Status.Total)
+	}
+}
+
+// groupMemberStatus returns the readiness of the given HelmRelease as a member
+// of a HelmReleaseGroup. A HelmRelease of which the current generation has
+// not been reconciled yet is considered to be progressing.
+func groupMemberStatus(obj *v2.HelmRelease) v2.HelmReleaseGroupMember {
+	member := v2.HelmReleaseGroupMember{
+		Name:   obj.GetName(),
+		Status: metav1.ConditionUnknown,
+	}
+
+	ready := conditions.Get(obj, meta.ReadyCondition)
+	switch {
+	case ready == nil:
+		member.Reason = meta.ProgressingReason
+		member.Message = "reconciliation in progress"
+	case obj.Status.ObservedGeneration != obj.GetGeneration():
+		member.Reason = meta.ProgressingReason
+		member.Message = fmt.Sprintf("reconciliation of generation %d in progress", obj.GetGeneration())
+	default:
+		member.Status = ready.Status
+		member.Reason = ready.Reason
+		member.Message = ready.Message
+	}
+	return member
+}
+
+// requestsForHelmReleaseChange returns the requests for all HelmReleaseGroups
+// in the namespace of the changed HelmRelease. As membership is determined by
+// the labels of the HelmRelease, which may have changed, the groups are not
+// filtered by their selector.
+func (r *HelmReleaseGroupReconciler) requestsForHelmReleaseChange(ctx context.Context, o client.Object) []reconcile.Request {
+	var list v2.HelmReleaseGroupList
+	if err := r.List(ctx, &list, client.InNamespace(o.GetNamespace())); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to list HelmReleaseGroups for HelmRelease change")
+		return nil
+	}
+
+	reqs := make([]reconcile.Request, 0, len(list.Items))
+	for i := range list.Items {
+		reqs = append(reqs, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+	}
+	return reqs
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func Test_summarizeGroup(t *testing.T) {
+	member := func(name string, generation int64, ready *metav1.Condition) v2.HelmRelease {
+		hr := v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Generation: generation,
+			},
+			Status: v2.HelmReleaseStatus{
+				ObservedGeneration: 1,
+			},
+		}
+		if ready != nil {
+			hr.Status.Conditions = []metav1.Condition{*ready}
+		}
+		return hr
+	}
+
+	tests := []struct {
+		name        string
+		members     []v2.HelmRelease
+		wantReady   int
+		wantFailing []v2.HelmReleaseGroupMember
+		wantCond    *metav1.Condition
+	}{
+		{
+			name:     "no members",
+			wantCond: conditions.FalseCondition(meta.ReadyCondition, v2.NoMembersReason, "No HelmReleases match the selector"),
+		},
+		{
+			name: "all members ready",
+			members: []v2.HelmRelease{
+				member("a", 1, conditions.TrueCondition(meta.ReadyCondition, v2.InstallSucceededReason, "installed")),
+				member("b", 1, conditions.TrueCondition(meta.ReadyCondition, v2.UpgradeSucceededReason, "upgraded")),
+			},
+			wantReady: 2,
+			wantCond:  conditions.TrueCondition(meta.ReadyCondition, meta.SucceededReason, "2/2 HelmReleases are ready"),
+		},
+		{
+			name: "failing and progressing members",
+			members: []v2.HelmRelease{
+				member("c", 1, conditions.FalseCondition(meta.ReadyCondition, v2.UpgradeFailedReason, "upgrade failed")),
+				member("a", 1, conditions.TrueCondition(meta.ReadyCondition, v2.InstallSucceededReason, "installed")),
+				member("b", 2, conditions.TrueCondition(meta.ReadyCondition, v2.InstallSucceededReason, "installed")),
+				member("d", 1, nil),
+			},
+			wantReady: 1,
+			wantFailing: []v2.HelmReleaseGroupMember{
+				{Name: "b", Status: metav1.ConditionUnknown, Reason: meta.ProgressingReason, Message: "reconciliation of generation 2 in progress"},
+				{Name: "c", Status: metav1.ConditionFalse, Reason: v2.UpgradeFailedReason, Message: "upgrade failed"},
+				{Name: "d", Status: metav1.ConditionUnknown, Reason: meta.ProgressingReason, Message: "reconciliation in progress"},
+			},
+			wantCond: conditions.FalseCondition(meta.ReadyCondition, v2.MembersNotReadyReason, "1/4 HelmReleases are ready, not ready: b, c, d"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &v2.HelmReleaseGroup{
+				Status: v2.HelmReleaseGroupStatus{
+					FailingMembers: []v2.HelmReleaseGroupMember{{Name: "stale"}},
+				},
+			}
+			summarizeGroup(obj, tt.members)
+
+			g.Expect(obj.Status.Total).To(Equal(len(tt.members)))
+			g.Expect(obj.Status.Ready).To(Equal(tt.wantReady))
+			g.Expect(obj.Status.FailingMembers).To(Equal(tt.wantFailing))
+			g.Expect(obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{*tt.wantCond}))
+		})
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/fluxcd/pkg/runtime/conditions"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/action"
+)
+
+const (
+	// manifestExportKey is the data key of the ConfigMap the manifest of the
+	// latest successful Helm release is exported to.
+	manifestExportKey = "manifest.yaml"
+	// manifestExportMaxSize is the maximum size of an exported manifest. It
+	// leaves room for the metadata of the ConfigMap within the 1MiB limit of
+	// the Kubernetes API.
+	manifestExportMaxSize = 1024*1024 - 16*1024
+)
+
+var (
+	// ReleaseDigestAnnotation is the annotation on an exported manifest
+	// ConfigMap with the digest of the Helm release it was exported from,
+	// which matches the Snapshot.Digest in the history of the HelmRelease.
+	ReleaseDigestAnnotation = v2.GroupVersion.Group + "/release-digest"
+	// ReleaseVersionAnnotation is the annotation on an exported manifest
+	// ConfigMap with the version of the Helm release it was exported from.
+	ReleaseVersionAnnotation = v2.GroupVersion.Group + "/release-version"
+)
+
+// reconcileManifestExport exports the manifest of the latest Helm release to
+// the ConfigMap of the ManifestExport of the HelmRelease, if the release is
+// deployed and has not been exported yet. The outcome is recorded in the
+// ManifestExportedCondition. A failure to export does not fail the
+// reconciliation, as the release itself succeeded, but is retried on the next
+// reconciliation.
+func (r *HelmReleaseReconciler) reconcileManifestExport(ctx context.Context, obj *v2.HelmRelease, cfg *action.ConfigFactory) {
+	if obj.Spec.ManifestExport == nil {
+		conditions.Delete(obj, v2.ManifestExportedCondition)
+		return
+	}
+
+	cur := obj.Status.History.Latest()
+	if cur == nil || cur.Status != helmrelease.StatusDeployed.String() {
+		return
+	}
+
+	if err := r.exportManifest(ctx, obj, cfg, cur); err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to export manifest")
+		conditions.MarkFalse(obj, v2.ManifestExportedCondition, v2.ManifestExportFailedReason, "%s", err)
+		r.Eventf(obj, corev1.EventTypeWarning, v2.ManifestExportFailedReason, err.Error())
+		return
+	}
+	conditions.MarkTrue(obj, v2.ManifestExportedCondition, v2.ManifestExportSucceededReason,
+		"Exported manifest of Helm release %s to ConfigMap '%s'", cur.FullReleaseName(), obj.Spec.ManifestExport.ConfigMapName)
+}
+
+// exportManifest writes the manifest of the Helm release of the given
+// snapshot to the ConfigMap of the ManifestExport of the HelmRelease.
+func (r *HelmReleaseReconciler) exportManifest(ctx context.Context, obj *v2.HelmRelease, cfg *action.ConfigFactory, cur *v2.Snapshot) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      obj.Spec.ManifestExport.ConfigMapName,
+			Namespace: obj.Namespace,
+		},
+	}
+
+	// Refuse to take over a ConfigMap which was not created for the export,
+	// to not overwrite the data of another owner.
+	err := r.Get(ctx, types.NamespacedName{Namespace: cm.Namespace, Name: cm.Name}, cm)
+	switch {
+	case apierrors.IsNotFound(err):
+	case err != nil:
+		return fmt.Errorf("failed to get ConfigMap '%s': %w", cm.Name, err)
+	case !metav1.IsControlledBy(cm, obj):
+		return fmt.Errorf("refusing to export manifest to ConfigMap '%s': ConfigMap is not controlled by the HelmRelease", cm.Name)
+	}
+
+	// Skip the retrieval of the release if it has already been exported.
+	if cm.GetAnnotations()[ReleaseDigestAnnotation] == cur.Digest {
+		return nil
+	}
+
+	rls, err := action.VerifySnapshot(cfg.Build(nil), cur)
+	if err != nil {
+		return fmt.Errorf("failed to get Helm release %s: %w", cur.FullReleaseName(), err)
+	}
+	if size := len(rls.Manifest); size > manifestExportMaxSize {
+		return fmt.Errorf("manifest of Helm release %s is too large to export to a ConfigMap: %d bytes exceeds the limit of %d bytes",
+			cur.FullReleaseName(), size, manifestExportMaxSize)
+	}
+
+	_, err = controllerutil.CreateOrUpdate(ctx, r.Client, cm, func() error {
+		if err := controllerutil.SetControllerReference(obj, cm, r.Scheme()); err != nil {
+			return err
+		}
+		annotations := cm.GetAnnotations()
+		if annotations == nil {
+			annotations = make(map[string]string, 2)
+		}
+		annotations[ReleaseDigestAnnotation] = cur.Digest
+		annotations[ReleaseVersionAnnotation] = strconv.Itoa(cur.Version)
+		cm.SetAnnotations(annotations)
+		cm.Data = map[string]string{manifestExportKey: rls.Manifest}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write ConfigMap '%s': %w", cm.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmstorage "helm.sh/helm/v3/pkg/storage"
+	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/fluxcd/pkg/runtime/conditions"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/kube"
+	"github.com/fluxcd/helm-controller/internal/release"
+	"github.com/fluxcd/helm-controller/internal/testutil"
+)
+
+func TestHelmReleaseReconciler_reconcileManifestExport(t *testing.T) {
+	newRelease := func(version int, manifest string) *helmrelease.Release {
+		rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+			Name:      "release",
+			Namespace: "mock",
+			Version:   version,
+			Status:    helmrelease.StatusDeployed,
+			Chart:     testutil.BuildChart(),
+		})
+		rls.Manifest = manifest
+		return rls
+	}
+
+	t.Run("exports manifest of latest release", func(t *testing.T) {
+		g := NewWithT(t)
+
+		rls := newRelease(1, "apiVersion: v1\nkind: ConfigMap\n")
+		obj, r, cfg := manifestExportFixture(g, rls)
+
+		r.reconcileManifestExport(context.TODO(), obj, cfg)
+		g.Expect(conditions.IsTrue(obj, v2.ManifestExportedCondition)).To(BeTrue())
+
+		cm := &corev1.ConfigMap{}
+		g.Expect(r.Get(context.TODO(), types.NamespacedName{Namespace: "mock", Name: "manifest"}, cm)).To(Succeed())
+		g.Expect(cm.Data).To(HaveKeyWithValue(manifestExportKey, rls.Manifest))
+		g.Expect(cm.Annotations).To(HaveKeyWithValue(ReleaseDigestAnnotation, obj.Status.History.Latest().Digest))
+		g.Expect(cm.Annotations).To(HaveKeyWithValue(ReleaseVersionAnnotation, "1"))
+		g.Expect(metav1.IsControlledBy(cm, obj)).To(BeTrue())
+
+		// A new release is exported.
+		upgraded := newRelease(2, "apiVersion: v1\nkind: Secret\n")
+		g.Expect(helmstorage.Init(cfg.Driver).Create(upgraded)).To(Succeed())
+		obj.Status.History = append(v2.Snapshots{release.ObservedToSnapshot(release.ObserveRelease(upgraded))}, obj.Status.History...)
+
+		r.reconcileManifestExport(context.TODO(), obj, cfg)
+		g.Expect(conditions.IsTrue(obj, v2.ManifestExportedCondition)).To(BeTrue())
+		g.Expect(r.Get(context.TODO(), types.NamespacedName{Namespace: "mock", Name: "manifest"}, cm)).To(Succeed())
+		g.Expect(cm.Data).To(HaveKeyWithValue(manifestExportKey, upgraded.Manifest))
+		g.Expect(cm.Annotations).To(HaveKeyWithValue(ReleaseVersionAnnotation, "2"))
+	})
+
+	t.Run("fails gracefully for too large manifest", func(t *testing.T) {
+		g := NewWithT(t)
+
+		rls := newRelease(1, strings.Repeat("#", manifestExportMaxSize+1))
+		obj, r, cfg := manifestExportFixture(g, rls)
+
+		r.reconcileManifestExport(context.TODO(), obj, cfg)
+		g.Expect(conditions.IsFalse(obj, v2.ManifestExportedCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(obj, v2.ManifestExportedCondition)).To(Equal(v2.ManifestExportFailedReason))
+		g.Expect(conditions.GetMessage(obj, v2.ManifestExportedCondition)).To(ContainSubstring("too large"))
+
+		cm := &corev1.ConfigMap{}
+		err := r.Get(context.TODO(), types.NamespacedName{Namespace: "mock", Name: "manifest"}, cm)
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("refuses to take over ConfigMap not controlled by HelmRelease", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj, r, cfg := manifestExportFixture(g, newRelease(1, "apiVersion: v1\nkind: ConfigMap\n"))
+		existing := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "manifest", Namespace: "mock"},
+			Data:       map[string]string{"key": "value"},
+		}
+		g.Expect(r.Create(context.TODO(), existing)).To(Succeed())
+
+		r.reconcileManifestExport(context.TODO(), obj, cfg)
+		g.Expect(conditions.IsFalse(obj, v2.ManifestExportedCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(obj, v2.ManifestExportedCondition)).To(Equal(v2.ManifestExportFailedReason))
+		g.Expect(conditions.GetMessage(obj, v2.ManifestExportedCondition)).To(ContainSubstring("not controlled by the HelmRelease"))
+
+		cm := &corev1.ConfigMap{}
+		g.Expect(r.Get(context.TODO(), types.NamespacedName{Namespace: "mock", Name: "manifest"}, cm)).To(Succeed())
+		g.Expect(cm.Data).To(Equal(existing.Data))
+		g.Expect(cm.OwnerReferences).To(BeEmpty())
+	})
+
+	t.Run("removes condition when disabled", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj, r, cfg := manifestExportFixture(g, newRelease(1, ""))
+		conditions.MarkTrue(obj, v2.ManifestExportedCondition, v2.ManifestExportSucceededReason, "exported")
+		obj.Spec.ManifestExport = nil
+
+		r.reconcileManifestExport(context.TODO(), obj, cfg)
+		g.Expect(conditions.Has(obj, v2.ManifestExportedCondition)).To(BeFalse())
+	})
+}
+
+// manifestExportFixture returns a HelmRelease with the given release as the
+// latest snapshot, and a reconciler and config factory with the release in
+// the Helm storage.
+func manifestExportFixture(g *WithT, rls *helmrelease.Release) (*v2.HelmRelease, *HelmReleaseReconciler, *action.ConfigFactory) {
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "release",
+			Namespace: "mock",
+			UID:       "uid",
+		},
+		Spec: v2.HelmReleaseSpec{
+			ManifestExport: &v2.ManifestExport{ConfigMapName: "manifest"},
+		},
+		Status: v2.HelmReleaseStatus{
+			History: v2.Snapshots{release.ObservedToSnapshot(release.ObserveRelease(rls))},
+		},
+	}
+
+	r := &HelmReleaseReconciler{
+		Client:        fake.NewClientBuilder().WithScheme(NewTestScheme()).Build(),
+		EventRecorder: record.NewFakeRecorder(32),
+	}
+
+	cfg, err := action.NewConfigFactory(&kube.MemoryRESTClientGetter{},
+		action.WithStorage(helmdriver.MemoryDriverName, "mock"),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(helmstorage.Init(cfg.Driver).Create(rls)).To(Succeed())
+
+	return obj, r, cfg
+}
@@ -0,0 +1,136 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/util/workqueue"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// queueWaitSeconds records the time a release waited in the work queue
+// between becoming ready to be processed and being picked up by a worker.
+// To keep the cardinality bounded, it is labeled by namespace and not by
+// the name of the release.
+var queueWaitSeconds = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "gotk_reconcile_queue_wait_seconds",
+		Help:    "The time an object waited in the work queue before being reconciled.",
+		Buckets: prometheus.ExponentialBuckets(0.005, 2, 16),
+	},
+	[]string{"kind", "namespace"},
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(queueWaitSeconds)
+}
+
+// newQueueWaitRecordingQueue returns a rate limiting work queue constructor
+// for controller.Options.NewQueue, which records the queue wait time of the
+// HelmRelease requests in the queueWaitSeconds metric.
+func newQueueWaitRecordingQueue(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	return &queueWaitRecorder{
+		TypedRateLimitingInterface: workqueue.NewTypedRateLimitingQueueWithConfig(rateLimiter, workqueue.TypedRateLimitingQueueConfig[reconcile.Request]{
+			Name: controllerName,
+		}),
+		rateLimiter: rateLimiter,
+		readyAt:     make(map[reconcile.Request]time.Time),
+		now:         time.Now,
+		observe: func(req reconcile.Request, d time.Duration) {
+			queueWaitSeconds.WithLabelValues(v2.HelmReleaseKind, req.Namespace).Observe(d.Seconds())
+		},
+	}
+}
+
+// queueWaitRecorder wraps a rate limiting work queue to keep track of the
+// time at which the items in the queue became ready to be processed, and
+// observes the time they waited once a worker gets them from the queue.
+type queueWaitRecorder struct {
+	workqueue.TypedRateLimitingInterface[reconcile.Request]
+
+	rateLimiter workqueue.TypedRateLimiter[reconcile.Request]
+
+	mu      sync.Mutex
+	readyAt map[reconcile.Request]time.Time
+
+	now     func() time.Time
+	observe func(req reconcile.Request, d time.Duration)
+}
+
+// Add marks the item as ready now, and adds it to the queue.
+func (q *queueWaitRecorder) Add(item reconcile.Request) {
+	q.markReady(item, q.now())
+	q.TypedRateLimitingInterface.Add(item)
+}
+
+// AddAfter marks the item as ready after the given duration, and adds it to
+// the queue once the duration has passed.
+func (q *queueWaitRecorder) AddAfter(item reconcile.Request, duration time.Duration) {
+	if duration <= 0 {
+		q.Add(item)
+		return
+	}
+	q.markReady(item, q.now().Add(duration))
+	q.TypedRateLimitingInterface.AddAfter(item, duration)
+}
+
+// AddRateLimited adds the item to the queue after the rate limiter says it
+// is ok. It mirrors the implementation of the wrapped queue, to be able to
+// take the delay into account.
+func (q *queueWaitRecorder) AddRateLimited(item reconcile.Request) {
+	q.AddAfter(item, q.rateLimiter.When(item))
+}
+
+// Get gets the next item from the queue, and observes the time it waited.
+func (q *queueWaitRecorder) Get() (reconcile.Request, bool) {
+	item, shutdown := q.TypedRateLimitingInterface.Get()
+	if shutdown {
+		return item, shutdown
+	}
+
+	q.mu.Lock()
+	readyAt, ok := q.readyAt[item]
+	delete(q.readyAt, item)
+	q.mu.Unlock()
+
+	if ok {
+		wait := q.now().Sub(readyAt)
+		if wait < 0 {
+			wait = 0
+		}
+		q.observe(item, wait)
+	}
+	return item, shutdown
+}
+
+// markReady records the time at which the item is ready to be processed,
+// unless it was already marked ready at an earlier time. This matches the
+// deduplication of the queue, where an item which is added multiple times
+// is processed once.
+func (q *queueWaitRecorder) markReady(item reconcile.Request, at time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if cur, ok := q.readyAt[item]; !ok || at.Before(cur) {
+		q.readyAt[item] = at
+	}
+}
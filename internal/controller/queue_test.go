@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func Test_queueWaitRecorder(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	observed := map[reconcile.Request]time.Duration{}
+
+	q := newQueueWaitRecordingQueue("test", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]()).(*queueWaitRecorder)
+	defer q.ShutDown()
+	q.now = func() time.Time { return now }
+	q.observe = func(req reconcile.Request, d time.Duration) {
+		observed[req] = d
+	}
+
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "podinfo"}}
+
+	// Adding the same item multiple times records the earliest time.
+	q.Add(req)
+	now = now.Add(2 * time.Second)
+	q.Add(req)
+	now = now.Add(3 * time.Second)
+
+	item, shutdown := q.Get()
+	g.Expect(shutdown).To(BeFalse())
+	g.Expect(item).To(Equal(req))
+	g.Expect(observed).To(HaveKeyWithValue(req, 5*time.Second))
+	q.Done(item)
+	g.Expect(q.readyAt).To(BeEmpty())
+
+	// A delayed item waits from the moment it becomes ready.
+	q.AddAfter(req, time.Millisecond)
+	g.Expect(q.readyAt).To(HaveKeyWithValue(req, now.Add(time.Millisecond)))
+	now = now.Add(time.Second)
+
+	item, _ = q.Get()
+	g.Expect(item).To(Equal(req))
+	g.Expect(observed).To(HaveKeyWithValue(req, time.Second-time.Millisecond))
+	q.Done(item)
+}
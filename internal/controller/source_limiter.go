@@ -0,0 +1,100 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// sourceLimiter limits the number of HelmReleases which run Helm actions at
+// the same time per chart source. This prevents a change to a chart source
+// shared by many HelmReleases from resulting in all of them upgrading at
+// once.
+type sourceLimiter struct {
+	limit int
+
+	mu     sync.Mutex
+	active map[string]int
+}
+
+// newSourceLimiter returns a sourceLimiter which allows the given number of
+// HelmReleases to run Helm actions at the same time per chart source. A
+// limit of zero or less disables the limiter.
+func newSourceLimiter(limit int) *sourceLimiter {
+	return &sourceLimiter{
+		limit:  limit,
+		active: make(map[string]int),
+	}
+}
+
+// TryAcquire attempts to acquire a slot for the given source key. It returns
+// true if a slot was acquired, which must be released with Release once the
+// Helm actions have finished.
+func (l *sourceLimiter) TryAcquire(key string) bool {
+	if l == nil || l.limit <= 0 {
+		return true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[key] >= l.limit {
+		return false
+	}
+	l.active[key]++
+	return true
+}
+
+// Release releases a slot for the given source key acquired with
+// TryAcquire.
+func (l *sourceLimiter) Release(key string) {
+	if l == nil || l.limit <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[key] <= 1 {
+		delete(l.active, key)
+		return
+	}
+	l.active[key]--
+}
+
+// sourceLimiterKey returns the key of the chart source of the given
+// HelmRelease for the sourceLimiter, in the format <kind>/<namespace>/<name>.
+// For a chart template, this is the source referenced by the template rather
+// than the HelmChart generated for the HelmRelease, as every HelmRelease has
+// its own HelmChart while sharing e.g. a HelmRepository with others.
+func sourceLimiterKey(obj *v2.HelmRelease) (string, error) {
+	switch {
+	case obj.HasChartRef() && !obj.HasChartTemplate():
+		ref := obj.Spec.ChartRef
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = obj.GetNamespace()
+		}
+		return fmt.Sprintf("%s/%s/%s", ref.Kind, namespace, ref.Name), nil
+	case obj.HasChartTemplate() && !obj.HasChartRef():
+		ref := obj.Spec.Chart.Spec.SourceRef
+		return fmt.Sprintf("%s/%s/%s", ref.Kind, obj.Spec.Chart.GetNamespace(obj.GetNamespace()), ref.Name), nil
+	default:
+		return "", fmt.Errorf("one of chartRef or chart must be present")
+	}
+}
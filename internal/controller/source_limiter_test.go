@@ -0,0 +1,181 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+	sourcev1beta2 "github.com/fluxcd/source-controller/api/v1beta2"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func Test_sourceLimiter(t *testing.T) {
+	t.Run("caps concurrent releases sharing a source", func(t *testing.T) {
+		g := NewWithT(t)
+
+		const (
+			limit    = 3
+			releases = 100
+		)
+		l := newSourceLimiter(limit)
+
+		var (
+			wg                  sync.WaitGroup
+			running, maxRunning atomic.Int32
+			completed, requeued atomic.Int32
+			start               = make(chan struct{})
+		)
+		for i := 0; i < releases; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-start
+				// Retry until a slot is acquired, like a requeued release.
+				for !l.TryAcquire("HelmChart/default/shared") {
+					requeued.Add(1)
+					time.Sleep(time.Millisecond)
+				}
+				defer l.Release("HelmChart/default/shared")
+
+				n := running.Add(1)
+				for {
+					cur := maxRunning.Load()
+					if n <= cur || maxRunning.CompareAndSwap(cur, n) {
+						break
+					}
+				}
+				time.Sleep(2 * time.Millisecond)
+				running.Add(-1)
+				completed.Add(1)
+			}()
+		}
+		close(start)
+		wg.Wait()
+
+		g.Expect(completed.Load()).To(Equal(int32(releases)))
+		g.Expect(maxRunning.Load()).To(BeNumerically("<=", limit))
+		g.Expect(maxRunning.Load()).To(BeNumerically(">", 0))
+		g.Expect(requeued.Load()).To(BeNumerically(">", 0))
+		g.Expect(l.active).To(BeEmpty())
+	})
+
+	t.Run("does not limit other sources", func(t *testing.T) {
+		g := NewWithT(t)
+
+		l := newSourceLimiter(1)
+		g.Expect(l.TryAcquire("HelmChart/default/a")).To(BeTrue())
+		g.Expect(l.TryAcquire("HelmChart/default/a")).To(BeFalse())
+		g.Expect(l.TryAcquire("HelmChart/default/b")).To(BeTrue())
+
+		l.Release("HelmChart/default/a")
+		g.Expect(l.TryAcquire("HelmChart/default/a")).To(BeTrue())
+	})
+
+	t.Run("disabled without limit", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var nilLimiter *sourceLimiter
+		g.Expect(nilLimiter.TryAcquire("HelmChart/default/a")).To(BeTrue())
+		nilLimiter.Release("HelmChart/default/a")
+
+		l := newSourceLimiter(0)
+		for i := 0; i < 10; i++ {
+			g.Expect(l.TryAcquire("HelmChart/default/a")).To(BeTrue())
+		}
+		g.Expect(l.active).To(BeEmpty())
+	})
+}
+
+func Test_sourceLimiterKey(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: v2.HelmReleaseSpec{
+			Chart: &v2.HelmChartTemplate{
+				Spec: v2.HelmChartTemplateSpec{
+					Chart: "podinfo",
+					SourceRef: v2.CrossNamespaceObjectReference{
+						Kind: sourcev1.HelmRepositoryKind,
+						Name: "podinfo",
+					},
+				},
+			},
+		},
+	}
+	key, err := sourceLimiterKey(obj)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(key).To(Equal("HelmRepository/default/podinfo"))
+
+	obj.Spec.Chart = nil
+	obj.Spec.ChartRef = &v2.CrossNamespaceSourceReference{
+		Kind:      sourcev1beta2.OCIRepositoryKind,
+		Name:      "podinfo",
+		Namespace: "flux-system",
+	}
+	key, err = sourceLimiterKey(obj)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(key).To(Equal("OCIRepository/flux-system/podinfo"))
+
+	obj.Spec.ChartRef = nil
+	_, err = sourceLimiterKey(obj)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func Test_sourceLimiterKey_sharedHelmRepository(t *testing.T) {
+	g := NewWithT(t)
+
+	l := newSourceLimiter(1)
+	var keys []string
+	for i := 0; i < 3; i++ {
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("podinfo-%d", i), Namespace: "default"},
+			Spec: v2.HelmReleaseSpec{
+				Chart: &v2.HelmChartTemplate{
+					Spec: v2.HelmChartTemplateSpec{
+						Chart: "podinfo",
+						SourceRef: v2.CrossNamespaceObjectReference{
+							Kind:      sourcev1.HelmRepositoryKind,
+							Name:      "podinfo",
+							Namespace: "flux-system",
+						},
+					},
+				},
+			},
+		}
+		key, err := sourceLimiterKey(obj)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(key).To(Equal("HelmRepository/flux-system/podinfo"))
+		keys = append(keys, key)
+	}
+
+	g.Expect(l.TryAcquire(keys[0])).To(BeTrue())
+	g.Expect(l.TryAcquire(keys[1])).To(BeFalse())
+	g.Expect(l.TryAcquire(keys[2])).To(BeFalse())
+
+	l.Release(keys[0])
+	g.Expect(l.TryAcquire(keys[1])).To(BeTrue())
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"fmt"
+	"os"
+
+	"sigs.k8s.io/yaml"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// CapabilityProfiles holds the capability profiles configured at controller
+// level, by name. HelmReleases can refer to these by name, so profiles for a
+// fleet of clusters can be defined in a single place.
+var CapabilityProfiles = map[string]v2.CapabilityProfile{}
+
+// LoadCapabilityProfiles reads a YAML list of v2.CapabilityProfile from the
+// file at the given path, and returns them by name.
+func LoadCapabilityProfiles(path string) (map[string]v2.CapabilityProfile, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read capability profiles: %w", err)
+	}
+	var list []v2.CapabilityProfile
+	if err = yaml.Unmarshal(b, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse capability profiles: %w", err)
+	}
+
+	profiles := make(map[string]v2.CapabilityProfile, len(list))
+	for _, p := range list {
+		if p.Name == "" || p.KubeVersion == "" {
+			return nil, fmt.Errorf("capability profile '%s' must have a name and kubeVersion", p.Name)
+		}
+		if _, ok := profiles[p.Name]; ok {
+			return nil, fmt.Errorf("duplicate capability profile '%s'", p.Name)
+		}
+		profiles[p.Name] = p
+	}
+	return profiles, nil
+}
+
+// ResolveCapabilityProfile returns the given profile, or the profile with
+// the same name from CapabilityProfiles if the given profile does not define
+// a KubeVersion. It returns an error if such a profile does not exist.
+func ResolveCapabilityProfile(profile v2.CapabilityProfile) (v2.CapabilityProfile, error) {
+	if profile.KubeVersion != "" {
+		return profile, nil
+	}
+	central, ok := CapabilityProfiles[profile.Name]
+	if !ok {
+		return profile, fmt.Errorf("capability profile '%s' is not configured at controller level", profile.Name)
+	}
+	return central, nil
+}
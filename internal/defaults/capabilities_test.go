@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func TestLoadCapabilityProfiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    map[string]v2.CapabilityProfile
+		wantErr string
+	}{
+		{
+			name: "profiles",
+			data: `- name: legacy
+  kubeVersion: 1.24.0
+- name: current
+  kubeVersion: 1.30.0
+  apiVersions:
+  - monitoring.coreos.com/v1
+`,
+			want: map[string]v2.CapabilityProfile{
+				"legacy":  {Name: "legacy", KubeVersion: "1.24.0"},
+				"current": {Name: "current", KubeVersion: "1.30.0", APIVersions: []string{"monitoring.coreos.com/v1"}},
+			},
+		},
+		{
+			name: "missing kubeVersion",
+			data: `- name: legacy
+`,
+			wantErr: "must have a name and kubeVersion",
+		},
+		{
+			name: "duplicate name",
+			data: `- name: legacy
+  kubeVersion: 1.24.0
+- name: legacy
+  kubeVersion: 1.25.0
+`,
+			wantErr: "duplicate capability profile 'legacy'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			path := filepath.Join(t.TempDir(), "profiles.yaml")
+			g.Expect(os.WriteFile(path, []byte(tt.data), 0o600)).To(Succeed())
+
+			got, err := LoadCapabilityProfiles(path)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ContainSubstring(tt.wantErr)))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestResolveCapabilityProfile(t *testing.T) {
+	central := map[string]v2.CapabilityProfile{
+		"legacy": {Name: "legacy", KubeVersion: "1.24.0"},
+	}
+
+	tests := []struct {
+		name    string
+		profile v2.CapabilityProfile
+		want    v2.CapabilityProfile
+		wantErr bool
+	}{
+		{
+			name:    "inline profile",
+			profile: v2.CapabilityProfile{Name: "legacy", KubeVersion: "1.23.0"},
+			want:    v2.CapabilityProfile{Name: "legacy", KubeVersion: "1.23.0"},
+		},
+		{
+			name:    "central profile",
+			profile: v2.CapabilityProfile{Name: "legacy"},
+			want:    v2.CapabilityProfile{Name: "legacy", KubeVersion: "1.24.0"},
+		},
+		{
+			name:    "unknown central profile",
+			profile: v2.CapabilityProfile{Name: "other"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			CapabilityProfiles = central
+			t.Cleanup(func() { CapabilityProfiles = map[string]v2.CapabilityProfile{} })
+
+			got, err := ResolveCapabilityProfile(tt.profile)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
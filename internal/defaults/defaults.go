@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package defaults resolves the effective configuration of a HelmRelease
+// from its spec and the defaults configured at controller level.
+package defaults
+
+import (
+	"fmt"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/kube"
+)
+
+var (
+	// DisableWait is the controller level default for disabling waiting for
+	// resources to be ready during the performance of Helm actions.
+	DisableWait = false
+
+	// NoServiceAccountOverride disallows HelmReleases to override the
+	// default service account configured at controller level.
+	NoServiceAccountOverride = false
+)
+
+// OverrideNotAllowedError is returned by Resolve when a HelmRelease
+// overrides a default which is not allowed to be overridden.
+type OverrideNotAllowedError string
+
+func (e OverrideNotAllowedError) Error() string {
+	return string(e)
+}
+
+// Resolve returns the v2.EffectiveConfig of the given HelmRelease. It returns
+// an OverrideNotAllowedError if the HelmRelease overrides a default which is
+// not allowed to be overridden.
+func Resolve(obj *v2.HelmRelease) (*v2.EffectiveConfig, error) {
+	sa := obj.Spec.ServiceAccountName
+	if sa != "" && NoServiceAccountOverride && sa != kube.DefaultServiceAccountName {
+		return nil, OverrideNotAllowedError(fmt.Sprintf("overriding the default service account is not allowed: cannot impersonate '%s'", sa))
+	}
+	if sa == "" {
+		sa = kube.DefaultServiceAccountName
+	}
+
+	return &v2.EffectiveConfig{
+		ServiceAccountName: sa,
+		DisableWait:        disableWait(obj),
+	}, nil
+}
+
+// MustDisableWait returns true if waiting for resources to be ready must be
+// disabled for a Helm action, based on the action specific configuration and
+// the effective default of the HelmRelease.
+func MustDisableWait(obj *v2.HelmRelease, actionDisableWait bool) bool {
+	return actionDisableWait || disableWait(obj)
+}
+
+// disableWait returns the HelmRelease override for disabling wait, or the
+// controller level default.
+func disableWait(obj *v2.HelmRelease) bool {
+	if obj.Spec.DisableWait != nil {
+		return *obj.Spec.DisableWait
+	}
+	return DisableWait
+}
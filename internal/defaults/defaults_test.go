@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaults
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/ptr"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/kube"
+)
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name        string
+		spec        v2.HelmReleaseSpec
+		defaultSA   string
+		defaultWait bool
+		noOverride  bool
+		want        *v2.EffectiveConfig
+		wantErr     bool
+	}{
+		{
+			name: "no defaults",
+			spec: v2.HelmReleaseSpec{},
+			want: &v2.EffectiveConfig{},
+		},
+		{
+			name:        "controller defaults",
+			spec:        v2.HelmReleaseSpec{},
+			defaultSA:   "default",
+			defaultWait: true,
+			want:        &v2.EffectiveConfig{ServiceAccountName: "default", DisableWait: true},
+		},
+		{
+			name:        "release overrides",
+			spec:        v2.HelmReleaseSpec{ServiceAccountName: "other", DisableWait: ptr.To(false)},
+			defaultSA:   "default",
+			defaultWait: true,
+			want:        &v2.EffectiveConfig{ServiceAccountName: "other", DisableWait: false},
+		},
+		{
+			name:       "service account override not allowed",
+			spec:       v2.HelmReleaseSpec{ServiceAccountName: "other"},
+			defaultSA:  "default",
+			noOverride: true,
+			wantErr:    true,
+		},
+		{
+			name:       "service account equal to default with lockdown",
+			spec:       v2.HelmReleaseSpec{ServiceAccountName: "default"},
+			defaultSA:  "default",
+			noOverride: true,
+			want:       &v2.EffectiveConfig{ServiceAccountName: "default"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			curSA, curWait, curOverride := kube.DefaultServiceAccountName, DisableWait, NoServiceAccountOverride
+			kube.DefaultServiceAccountName, DisableWait, NoServiceAccountOverride = tt.defaultSA, tt.defaultWait, tt.noOverride
+			t.Cleanup(func() {
+				kube.DefaultServiceAccountName, DisableWait, NoServiceAccountOverride = curSA, curWait, curOverride
+			})
+
+			got, err := Resolve(&v2.HelmRelease{Spec: tt.spec})
+			if tt.wantErr {
+				var overrideErr OverrideNotAllowedError
+				g.Expect(errors.As(err, &overrideErr)).To(BeTrue())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestMustDisableWait(t *testing.T) {
+	g := NewWithT(t)
+
+	curWait := DisableWait
+	t.Cleanup(func() { DisableWait = curWait })
+
+	DisableWait = false
+	g.Expect(MustDisableWait(&v2.HelmRelease{}, false)).To(BeFalse())
+	g.Expect(MustDisableWait(&v2.HelmRelease{}, true)).To(BeTrue())
+
+	DisableWait = true
+	g.Expect(MustDisableWait(&v2.HelmRelease{}, false)).To(BeTrue())
+	g.Expect(MustDisableWait(&v2.HelmRelease{Spec: v2.HelmReleaseSpec{DisableWait: ptr.To(false)}}, false)).To(BeFalse())
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	ssautil "github.com/fluxcd/pkg/ssa/utils"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// Manifests compares the objects of the current manifest with the objects
+// of the desired manifest, and returns the changes required to go from the
+// current to the desired state. Objects are matched by their group, kind,
+// namespace and name. The changes are returned in the order of the desired
+// manifest, followed by the deletions in the order of the current manifest.
+func Manifests(current, desired string) ([]v2.ResourceChange, error) {
+	currentObjs, err := ssautil.ReadObjects(strings.NewReader(current))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read objects from current manifest: %w", err)
+	}
+	desiredObjs, err := ssautil.ReadObjects(strings.NewReader(desired))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read objects from desired manifest: %w", err)
+	}
+
+	existing := make(map[string]*unstructured.Unstructured, len(currentObjs))
+	for _, obj := range currentObjs {
+		existing[objectKey(obj)] = obj
+	}
+
+	var changes []v2.ResourceChange
+	seen := make(map[string]struct{}, len(desiredObjs))
+	for _, obj := range desiredObjs {
+		key := objectKey(obj)
+		seen[key] = struct{}{}
+		cur, ok := existing[key]
+		switch {
+		case !ok:
+			changes = append(changes, resourceChange(v2.ResourceChangeCreate, obj))
+		case !equality.Semantic.DeepEqual(cur.Object, obj.Object):
+			changes = append(changes, resourceChange(v2.ResourceChangeUpdate, obj))
+		}
+	}
+	for _, obj := range currentObjs {
+		if _, ok := seen[objectKey(obj)]; !ok {
+			changes = append(changes, resourceChange(v2.ResourceChangeDelete, obj))
+		}
+	}
+	return changes, nil
+}
+
+// objectKey returns a key identifying the given object independent of its
+// API version.
+func objectKey(obj *unstructured.Unstructured) string {
+	gk := obj.GroupVersionKind().GroupKind()
+	return fmt.Sprintf("%s/%s/%s", gk.String(), obj.GetNamespace(), obj.GetName())
+}
+
+func resourceChange(t string, obj *unstructured.Unstructured) v2.ResourceChange {
+	return v2.ResourceChange{
+		Type:       t,
+		APIVersion: obj.GetAPIVersion(),
+		Kind:       obj.GetKind(),
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func TestManifests(t *testing.T) {
+	const (
+		cmA = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: default
+data:
+  key: value
+`
+		cmAChanged = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: a
+  namespace: default
+data:
+  key: changed
+`
+		cmB = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: b
+  namespace: default
+`
+		deployV1 = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+`
+		deployBeta = `apiVersion: apps/v1beta1
+kind: Deployment
+metadata:
+  name: app
+  namespace: default
+`
+	)
+
+	tests := []struct {
+		name    string
+		current string
+		desired string
+		want    []v2.ResourceChange
+		wantErr bool
+	}{
+		{
+			name:    "no changes",
+			current: cmA,
+			desired: cmA,
+		},
+		{
+			name:    "install",
+			current: "",
+			desired: cmA + "---\n" + cmB,
+			want: []v2.ResourceChange{
+				{Type: v2.ResourceChangeCreate, APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "a"},
+				{Type: v2.ResourceChangeCreate, APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "b"},
+			},
+		},
+		{
+			name:    "update and delete",
+			current: cmA + "---\n" + cmB,
+			desired: cmAChanged,
+			want: []v2.ResourceChange{
+				{Type: v2.ResourceChangeUpdate, APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "a"},
+				{Type: v2.ResourceChangeDelete, APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "b"},
+			},
+		},
+		{
+			name:    "API version change is an update",
+			current: deployBeta,
+			desired: deployV1,
+			want: []v2.ResourceChange{
+				{Type: v2.ResourceChangeUpdate, APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "app"},
+			},
+		},
+		{
+			name:    "invalid manifest",
+			current: "",
+			desired: "invalid",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := Manifests(tt.current, tt.desired)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
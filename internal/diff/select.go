@@ -0,0 +1,51 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/fluxcd/pkg/ssa/jsondiff"
+)
+
+// SelectDiffSet returns the subset of the given DiffSet for which the labels
+// of the desired object match the given label selector. If the selector is
+// nil, the DiffSet is returned as is.
+func SelectDiffSet(set jsondiff.DiffSet, selector *metav1.LabelSelector) (jsondiff.DiffSet, error) {
+	if selector == nil {
+		return set, nil
+	}
+
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+
+	var selected jsondiff.DiffSet
+	for _, change := range set {
+		if change == nil || change.DesiredObject == nil {
+			continue
+		}
+		if s.Matches(labels.Set(change.DesiredObject.GetLabels())) {
+			selected = append(selected, change)
+		}
+	}
+	return selected, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package diff
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/fluxcd/pkg/ssa/jsondiff"
+)
+
+func TestSelectDiffSet(t *testing.T) {
+	newDiff := func(name string, labels map[string]string) *jsondiff.Diff {
+		obj := &unstructured.Unstructured{}
+		obj.SetKind("ConfigMap")
+		obj.SetName(name)
+		obj.SetLabels(labels)
+		return &jsondiff.Diff{Type: jsondiff.DiffTypeUpdate, DesiredObject: obj}
+	}
+
+	set := jsondiff.DiffSet{
+		newDiff("a", map[string]string{"tier": "frontend"}),
+		newDiff("b", map[string]string{"tier": "backend"}),
+		newDiff("c", nil),
+	}
+
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name: "nil selector",
+			want: []string{"a", "b", "c"},
+		},
+		{
+			name:     "match labels",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "frontend"}},
+			want:     []string{"a"},
+		},
+		{
+			name: "match expressions",
+			selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "tier", Operator: metav1.LabelSelectorOpExists},
+				},
+			},
+			want: []string{"a", "b"},
+		},
+		{
+			name:     "no match",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "database"}},
+			want:     []string{},
+		},
+		{
+			name: "invalid selector",
+			selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "tier", Operator: "Invalid"},
+				},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := SelectDiffSet(set, tt.selector)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+
+			names := []string{}
+			for _, change := range got {
+				names = append(names, change.DesiredObject.GetName())
+			}
+			g.Expect(names).To(Equal(tt.want))
+		})
+	}
+}
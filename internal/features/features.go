@@ -58,6 +58,27 @@ const (
 	// without the need to upgrade the Helm release. But it can be disabled to
 	// avoid potential abuse of the adoption mechanism.
 	AdoptLegacyReleases = "AdoptLegacyReleases"
+
+	// ValuesDigestsInHistory enables the recording of the digest of the
+	// values per top-level key in the history of a HelmRelease, to allow
+	// attributing config changes to a release revision.
+	ValuesDigestsInHistory = "ValuesDigestsInHistory"
+
+	// ValuesSnapshotInHistory enables the recording of a snapshot of the
+	// fully merged values in the history of a HelmRelease, in which the
+	// values sourced from Secrets are replaced by their checksum.
+	ValuesSnapshotInHistory = "ValuesSnapshotInHistory"
+
+	// ReleaseGroups enables the controller for HelmReleaseGroups, which
+	// aggregate the readiness of the HelmReleases selected by their labels.
+	// This requires the HelmReleaseGroup CRD to be installed.
+	ReleaseGroups = "ReleaseGroups"
+
+	// ValidationWebhook enables the validating admission webhook for
+	// HelmReleases, which rejects specifications the reconciler would fail
+	// on. This requires a ValidatingWebhookConfiguration and a serving
+	// certificate to be provisioned.
+	ValidationWebhook = "ValidationWebhook"
 )
 
 var features = map[string]bool{
@@ -79,6 +100,18 @@ var features = map[string]bool{
 	// AdoptLegacyReleases
 	// opt-out from v0.37
 	AdoptLegacyReleases: true,
+	// ValuesDigestsInHistory
+	// opt-in from v1.2
+	ValuesDigestsInHistory: false,
+	// ValuesSnapshotInHistory
+	// opt-in from v1.2
+	ValuesSnapshotInHistory: false,
+	// ReleaseGroups
+	// opt-in from v1.2
+	ReleaseGroups: false,
+	// ValidationWebhook
+	// opt-in from v1.2
+	ValidationWebhook: false,
 }
 
 // FeatureGates contains a list of all supported feature gates and
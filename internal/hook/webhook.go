@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package hook implements the invocation of external webhooks around the
+// Helm actions performed by the controller.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+const (
+	// TokenKey is the key in the Secret data holding a bearer token.
+	TokenKey = "token"
+	// UsernameKey is the key in the Secret data holding a basic auth username.
+	UsernameKey = "username"
+	// PasswordKey is the key in the Secret data holding a basic auth password.
+	PasswordKey = "password"
+	// CACertKey is the key in the Secret data holding a PEM encoded CA
+	// certificate.
+	CACertKey = "ca.crt"
+)
+
+// Phase is the phase of the Helm action a Webhook is invoked for.
+type Phase string
+
+const (
+	// PhasePre is the phase before the Helm action is performed.
+	PhasePre Phase = "pre"
+	// PhasePost is the phase after the Helm action has been performed.
+	PhasePost Phase = "post"
+)
+
+// Payload is the JSON body sent to a Webhook.
+type Payload struct {
+	// Phase of the Helm action the Webhook is invoked for.
+	Phase Phase `json:"phase"`
+	// Action is the name of the Helm action, e.g. "install" or "upgrade".
+	Action string `json:"action"`
+	// Name is the name of the HelmRelease.
+	Name string `json:"name"`
+	// Namespace is the namespace of the HelmRelease.
+	Namespace string `json:"namespace"`
+	// ReleaseName is the name of the Helm release.
+	ReleaseName string `json:"releaseName"`
+	// ReleaseNamespace is the namespace of the Helm release.
+	ReleaseNamespace string `json:"releaseNamespace"`
+	// ChartName is the name of the chart.
+	ChartName string `json:"chartName,omitempty"`
+	// ChartVersion is the version of the chart.
+	ChartVersion string `json:"chartVersion,omitempty"`
+	// Succeeded indicates if the Helm action succeeded. Only set for
+	// PhasePost.
+	Succeeded *bool `json:"succeeded,omitempty"`
+	// Message is the message describing the result of the Helm action. Only
+	// set for PhasePost.
+	Message string `json:"message,omitempty"`
+}
+
+// Webhook is an external webhook which can be invoked with a Payload.
+type Webhook struct {
+	// Address is the HTTP(S) address the request is sent to.
+	Address string
+	// Timeout is the time to wait for the webhook to respond.
+	Timeout time.Duration
+
+	token    string
+	username string
+	password string
+	caData   []byte
+}
+
+// NewWebhook returns a new Webhook configured with the given v2.ReconcileHook
+// and credentials data from the referenced Secret (which may be nil).
+func NewWebhook(hook v2.ReconcileHook, secretData map[string][]byte) *Webhook {
+	return &Webhook{
+		Address:  hook.Address,
+		Timeout:  hook.GetTimeout().Duration,
+		token:    string(secretData[TokenKey]),
+		username: string(secretData[UsernameKey]),
+		password: string(secretData[PasswordKey]),
+		caData:   secretData[CACertKey],
+	}
+}
+
+// Invoke sends the Payload to the Webhook using an HTTP POST request. It
+// returns an error if the request fails, or the response does not have a 2xx
+// status code.
+func (w *Webhook) Invoke(ctx context.Context, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, w.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.Address, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to construct webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case w.token != "":
+		req.Header.Set("Authorization", "Bearer "+w.token)
+	case w.username != "" || w.password != "":
+		req.SetBasicAuth(w.username, w.password)
+	}
+
+	client, err := w.httpClient()
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request to %s failed: %w", w.Address, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook request to %s failed with status %s: %s",
+			w.Address, resp.Status, bytes.TrimSpace(msg))
+	}
+	return nil
+}
+
+// httpClient returns an HTTP client configured with the CA data of the
+// Webhook, if any. The client does not follow redirects, as the target of a
+// redirect has not been checked against the hosts allowed for the Webhook.
+func (w *Webhook) httpClient() (*http.Client, error) {
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	if len(w.caData) == 0 {
+		return client, nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(w.caData) {
+		return nil, errors.New("failed to parse webhook CA certificate")
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	client.Transport = transport
+	return client, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func TestWebhook_Invoke(t *testing.T) {
+	tests := []struct {
+		name       string
+		secretData map[string][]byte
+		status     int
+		wantAuth   string
+		wantErr    string
+	}{
+		{
+			name:   "successful request",
+			status: http.StatusOK,
+		},
+		{
+			name:       "bearer token",
+			secretData: map[string][]byte{TokenKey: []byte("secret")},
+			status:     http.StatusAccepted,
+			wantAuth:   "Bearer secret",
+		},
+		{
+			name:       "basic auth",
+			secretData: map[string][]byte{UsernameKey: []byte("user"), PasswordKey: []byte("pass")},
+			status:     http.StatusNoContent,
+			wantAuth:   "Basic dXNlcjpwYXNz",
+		},
+		{
+			name:    "failure status",
+			status:  http.StatusForbidden,
+			wantErr: "failed with status 403 Forbidden",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			var (
+				gotAuth    string
+				gotPayload Payload
+			)
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotAuth = r.Header.Get("Authorization")
+				_ = json.NewDecoder(r.Body).Decode(&gotPayload)
+				w.WriteHeader(tt.status)
+			}))
+			t.Cleanup(server.Close)
+
+			wh := NewWebhook(v2.ReconcileHook{Address: server.URL}, tt.secretData)
+			payload := Payload{Phase: PhasePre, Action: "install", Name: "release", Namespace: "default"}
+
+			err := wh.Invoke(context.TODO(), payload)
+			if tt.wantErr != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tt.wantErr))
+			} else {
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+			g.Expect(gotAuth).To(Equal(tt.wantAuth))
+			g.Expect(gotPayload).To(Equal(payload))
+		})
+	}
+}
+
+func TestWebhook_Invoke_Redirect(t *testing.T) {
+	g := NewWithT(t)
+
+	var redirected bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(target.Close)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	t.Cleanup(server.Close)
+
+	wh := NewWebhook(v2.ReconcileHook{Address: server.URL}, nil)
+	err := wh.Invoke(context.TODO(), Payload{Phase: PhasePre, Action: "install"})
+	g.Expect(err).To(HaveOccurred())
+	g.Expect(err.Error()).To(ContainSubstring("failed with status 307"))
+	g.Expect(redirected).To(BeFalse())
+}
@@ -125,6 +125,12 @@ func NewInClusterMemoryRESTClientGetter(opts ...Option) (*MemoryRESTClientGetter
 	return NewMemoryRESTClientGetter(cfg, opts...), nil
 }
 
+// Impersonate returns the username the client impersonates, or an empty
+// string if no user is impersonated.
+func (c *MemoryRESTClientGetter) Impersonate() string {
+	return c.impersonate
+}
+
 // ToRESTConfig returns the in-memory REST config.
 func (c *MemoryRESTClientGetter) ToRESTConfig() (*rest.Config, error) {
 	if c.cfg == nil {
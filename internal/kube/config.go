@@ -41,6 +41,42 @@ const (
 // `value.yaml` keys. If a Secret is provided but no key with data can be
 // found, an error is returned.
 func ConfigFromSecret(secret *corev1.Secret, key string, opts client.KubeConfigOptions) (*rest.Config, error) {
+	kubeConfig, err := kubeConfigDataFromSecret(secret, key)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load KubeConfig from secret '%s/%s': %w", secret.Namespace, secret.Name, err)
+	}
+	cfg = client.KubeConfig(cfg, opts)
+	return cfg, nil
+}
+
+// ContextFromSecret returns the name of the current context of the KubeConfig
+// in the given Secret, and the name of the user of this context. The data is
+// looked up in the same way as ConfigFromSecret. No credentials are returned.
+func ContextFromSecret(secret *corev1.Secret, key string) (context, user string, err error) {
+	kubeConfig, err := kubeConfigDataFromSecret(secret, key)
+	if err != nil {
+		return "", "", err
+	}
+
+	cfg, err := clientcmd.Load(kubeConfig)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to load KubeConfig from secret '%s/%s': %w", secret.Namespace, secret.Name, err)
+	}
+	context = cfg.CurrentContext
+	if c, ok := cfg.Contexts[context]; ok && c != nil {
+		user = c.AuthInfo
+	}
+	return context, user, nil
+}
+
+// kubeConfigDataFromSecret returns the KubeConfig data from the provided key
+// in the given Secret, or from the default `value` and `value.yaml` keys.
+func kubeConfigDataFromSecret(secret *corev1.Secret, key string) ([]byte, error) {
 	if secret == nil {
 		return nil, fmt.Errorf("KubeConfig secret is nil")
 	}
@@ -63,11 +99,5 @@ func ConfigFromSecret(secret *corev1.Secret, key string, opts client.KubeConfigO
 		// User did not specify a key, and the 'value' key was not defined.
 		return nil, fmt.Errorf("KubeConfig secret '%s' does not contain a '%s' or '%s' key with data", secretName, DefaultKubeConfigSecretKey, DefaultKubeConfigSecretKeyExt)
 	}
-
-	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeConfig)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load KubeConfig from secret '%s': %w", secretName, err)
-	}
-	cfg = client.KubeConfig(cfg, opts)
-	return cfg, nil
+	return kubeConfig, nil
 }
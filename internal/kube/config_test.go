@@ -204,3 +204,38 @@ func TestConfigFromSecret(t *testing.T) {
 		g.Expect(got.UserAgent).To(Equal("test"))
 	})
 }
+
+func TestContextFromSecret(t *testing.T) {
+	t.Run("with default key", func(t *testing.T) {
+		g := NewWithT(t)
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "super-secret",
+				Namespace: "vault",
+			},
+			Data: map[string][]byte{
+				DefaultKubeConfigSecretKey: []byte(kubeCfg),
+			},
+		}
+		context, user, err := ContextFromSecret(secret, "")
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(context).To(Equal("dev-frontend"))
+		g.Expect(user).To(Equal("developer"))
+	})
+
+	t.Run("invalid key", func(t *testing.T) {
+		g := NewWithT(t)
+
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "super-secret",
+				Namespace: "vault",
+			},
+			Data: map[string][]byte{},
+		}
+		_, _, err := ContextFromSecret(secret, "black-hole")
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(err.Error()).To(ContainSubstring("does not contain a 'black-hole' key"))
+	})
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"sync"
+
+	"k8s.io/client-go/rest"
+)
+
+// WithWarningHandler sets the handler for warnings returned by the
+// Kubernetes API server, for example about the use of deprecated APIs.
+func WithWarningHandler(h rest.WarningHandler) Option {
+	return func(c *MemoryRESTClientGetter) {
+		c.cfg.WarningHandler = h
+	}
+}
+
+// WarningCollector is a rest.WarningHandler which collects the warnings
+// returned by the Kubernetes API server, ignoring duplicates.
+type WarningCollector struct {
+	mu       sync.Mutex
+	seen     map[string]struct{}
+	warnings []string
+}
+
+// NewWarningCollector returns a new WarningCollector.
+func NewWarningCollector() *WarningCollector {
+	return &WarningCollector{seen: make(map[string]struct{})}
+}
+
+// HandleWarningHeader collects the warning message, if it is a valid
+// warning (code 299) which has not been collected before.
+func (c *WarningCollector) HandleWarningHeader(code int, _ string, message string) {
+	if code != 299 || message == "" {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.seen[message]; ok {
+		return
+	}
+	c.seen[message] = struct{}{}
+	c.warnings = append(c.warnings, message)
+}
+
+// Warnings returns the collected warnings, in the order they were first
+// returned.
+func (c *WarningCollector) Warnings() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.warnings...)
+}
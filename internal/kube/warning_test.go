@@ -0,0 +1,53 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/rest"
+)
+
+func TestWithWarningHandler(t *testing.T) {
+	t.Run("sets the warning handler", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := &MemoryRESTClientGetter{
+			cfg: &rest.Config{},
+		}
+		h := NewWarningCollector()
+		WithWarningHandler(h)(c)
+		g.Expect(c.cfg.WarningHandler).To(Equal(h))
+	})
+}
+
+func TestWarningCollector(t *testing.T) {
+	g := NewWithT(t)
+
+	c := NewWarningCollector()
+	c.HandleWarningHeader(299, "", "policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+")
+	c.HandleWarningHeader(299, "", "autoscaling/v2beta2 HorizontalPodAutoscaler is deprecated in v1.23+")
+	c.HandleWarningHeader(299, "", "policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+")
+	c.HandleWarningHeader(199, "", "miscellaneous warning")
+	c.HandleWarningHeader(299, "", "")
+
+	g.Expect(c.Warnings()).To(Equal([]string{
+		"policy/v1beta1 PodDisruptionBudget is deprecated in v1.21+",
+		"autoscaling/v2beta2 HorizontalPodAutoscaler is deprecated in v1.23+",
+	}))
+}
@@ -44,6 +44,9 @@ const (
 var (
 	// ErrFileNotFound is an error type used to signal 404 HTTP status code responses.
 	ErrFileNotFound = errors.New("file not found")
+	// ErrUnauthorized is an error type used to signal 401 and 403 HTTP status
+	// code responses.
+	ErrUnauthorized = errors.New("unauthorized")
 	// ErrIntegrity signals a chart loader failed to verify the integrity of
 	// a chart, for example due to a digest mismatch.
 	ErrIntegrity = errors.New("integrity failure")
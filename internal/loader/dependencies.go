@@ -0,0 +1,469 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/hashicorp/go-retryablehttp"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+
+	"github.com/fluxcd/helm-controller/internal/digest"
+)
+
+const (
+	// MaxRepositoryIndexSize is the maximum size in bytes of the index of a
+	// chart repository from which dependencies are resolved.
+	MaxRepositoryIndexSize = 50 * 1024 * 1024
+	// MaxDependencySize is the maximum size in bytes of a packaged
+	// dependency chart.
+	MaxDependencySize = 10 * 1024 * 1024
+	// DefaultDependencyCacheSize is the default number of sets of built
+	// dependencies kept in a DependencyCache.
+	DefaultDependencyCacheSize = 100
+	// DefaultDependencyCacheMaxBytes is the default maximum size in bytes of
+	// the packaged dependencies kept in a DependencyCache.
+	DefaultDependencyCacheMaxBytes = 256 * 1024 * 1024
+)
+
+// ErrDependencyResolution signals a dependency of a chart could not be
+// resolved or downloaded.
+var ErrDependencyResolution = errors.New("failed to resolve chart dependency")
+
+// RepositoryAuth holds the credentials to access a chart repository.
+type RepositoryAuth struct {
+	// Username and Password for basic authentication, if set.
+	Username string
+	Password string
+	// TLSConfig is the TLS configuration to use for the repository, if set.
+	TLSConfig *tls.Config
+	// PassCredentials allows the credentials to be passed to a chart URL of
+	// which the scheme and host differ from those of the repository.
+	PassCredentials bool
+	// Identity uniquely identifies the credentials, e.g. by the objects they
+	// originate from. Built dependencies are only shared from the
+	// DependencyCache with builds using credentials with the same identity.
+	Identity string
+}
+
+// RepositoryAuthFunc returns the RepositoryAuth for the chart repository at
+// the given URL, or nil if the repository does not require authentication.
+type RepositoryAuthFunc func(ctx context.Context, repoURL string) (*RepositoryAuth, error)
+
+// DependencyCache holds the packaged dependencies of charts, keyed by the
+// namespace of the build, the digest of the Chart.lock of a chart and the
+// identity of the repository credentials. It evicts the oldest sets of
+// dependencies once it holds more than the configured number of sets, or
+// more than the configured number of bytes.
+type DependencyCache struct {
+	mu       sync.Mutex
+	size     int
+	maxBytes int64
+	bytes    int64
+	keys     []string
+	entries  map[string]map[string][]byte
+}
+
+// NewDependencyCache returns a DependencyCache holding up to the given
+// number of sets of dependencies, with a total size of up to the given
+// number of bytes.
+func NewDependencyCache(size int, maxBytes int64) *DependencyCache {
+	return &DependencyCache{size: size, maxBytes: maxBytes, entries: make(map[string]map[string][]byte)}
+}
+
+// get returns the packaged dependencies stored for the given key.
+func (c *DependencyCache) get(key string) (map[string][]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	deps, ok := c.entries[key]
+	return deps, ok
+}
+
+// set stores the packaged dependencies for the given key. Dependencies
+// which exceed the maximum size of the cache on their own are not stored.
+func (c *DependencyCache) set(key string, deps map[string][]byte) {
+	size := dependenciesSize(deps)
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if prev, ok := c.entries[key]; ok {
+		c.bytes -= dependenciesSize(prev)
+	} else {
+		c.keys = append(c.keys, key)
+	}
+	c.entries[key] = deps
+	c.bytes += size
+	for len(c.keys) > c.size || c.bytes > c.maxBytes {
+		c.bytes -= dependenciesSize(c.entries[c.keys[0]])
+		delete(c.entries, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+}
+
+// dependenciesSize returns the total size in bytes of the given packaged
+// dependencies.
+func dependenciesSize(deps map[string][]byte) int64 {
+	var size int64
+	for _, data := range deps {
+		size += int64(len(data))
+	}
+	return size
+}
+
+// DependencyBuilder builds the dependencies of a chart which are listed in
+// its Chart.yaml, but are not vendored in its charts/ directory. It is the
+// equivalent of `helm dependency build` for charts held in memory.
+type DependencyBuilder struct {
+	client    *retryablehttp.Client
+	namespace string
+	authFunc  RepositoryAuthFunc
+	cache     *DependencyCache
+}
+
+// NewDependencyBuilder returns a DependencyBuilder which downloads the
+// dependencies using the given client, with the credentials returned by the
+// given RepositoryAuthFunc. The namespace scopes the dependencies shared
+// through the cache, which is optional.
+func NewDependencyBuilder(client *retryablehttp.Client, namespace string, authFunc RepositoryAuthFunc, cache *DependencyCache) *DependencyBuilder {
+	return &DependencyBuilder{client: client, namespace: namespace, authFunc: authFunc, cache: cache}
+}
+
+// Build resolves the missing dependencies of the given chart from their
+// chart repositories, and adds them to the chart. The versions locked in the
+// Chart.lock take precedence over the version constraints of the Chart.yaml.
+// Built dependencies are cached by the namespace of the DependencyBuilder,
+// the digest of the Chart.lock, or of the dependencies listed in the
+// Chart.yaml if there is no lock, the versions of the dependencies, and the
+// identity of the credentials of the repositories. The version constraints
+// of dependencies which are not locked are resolved from the index of their
+// repository on every build, to pick up newly released versions. It returns
+// an error of type ErrDependencyResolution if a dependency can not be
+// resolved.
+func (b *DependencyBuilder) Build(ctx context.Context, c *chart.Chart) error {
+	missing := MissingDependencies(c)
+	if len(missing) == 0 {
+		return nil
+	}
+
+	auths := make(map[string]*RepositoryAuth, len(missing))
+	for _, dep := range missing {
+		repoURL := strings.TrimSuffix(dep.Repository, "/")
+		if _, ok := auths[repoURL]; ok {
+			continue
+		}
+		auth, err := b.repositoryAuth(ctx, repoURL)
+		if err != nil {
+			return fmt.Errorf("%w '%s' from '%s': %w", ErrDependencyResolution, dep.Name, dep.Repository, err)
+		}
+		auths[repoURL] = auth
+	}
+
+	// Resolve the dependencies which are not locked before looking up the
+	// cache, as their version constraints may match a newer version than
+	// the one which was cached.
+	resolved := make(map[string]*repo.ChartVersion, len(missing))
+	for _, dep := range missing {
+		if _, locked := lockedVersion(c, dep); locked {
+			continue
+		}
+		cv, err := b.resolve(ctx, dep, dep.Version, auths[strings.TrimSuffix(dep.Repository, "/")])
+		if err != nil {
+			return fmt.Errorf("%w '%s' from '%s': %w", ErrDependencyResolution, dep.Name, dep.Repository, err)
+		}
+		resolved[dep.Name] = cv
+	}
+
+	key, err := dependencyCacheKey(b.namespace, c, resolved, auths)
+	if err != nil {
+		return err
+	}
+
+	var packaged map[string][]byte
+	if b.cache != nil {
+		packaged, _ = b.cache.get(key)
+	}
+	if packaged == nil {
+		packaged = make(map[string][]byte, len(missing))
+		for _, dep := range missing {
+			auth := auths[strings.TrimSuffix(dep.Repository, "/")]
+			cv, ok := resolved[dep.Name]
+			if !ok {
+				version, _ := lockedVersion(c, dep)
+				if cv, err = b.resolve(ctx, dep, version, auth); err != nil {
+					return fmt.Errorf("%w '%s' from '%s': %w", ErrDependencyResolution, dep.Name, dep.Repository, err)
+				}
+			}
+			data, err := b.download(ctx, dep, cv, auth)
+			if err != nil {
+				return fmt.Errorf("%w '%s' from '%s': %w", ErrDependencyResolution, dep.Name, dep.Repository, err)
+			}
+			packaged[dep.Name] = data
+		}
+	}
+
+	for _, dep := range missing {
+		data, ok := packaged[dep.Name]
+		if !ok {
+			return fmt.Errorf("%w '%s': not found in cache", ErrDependencyResolution, dep.Name)
+		}
+		sub, err := loader.LoadArchive(bytes.NewReader(data))
+		if err != nil {
+			return fmt.Errorf("%w '%s': %w", ErrDependencyResolution, dep.Name, err)
+		}
+		c.AddDependency(sub)
+	}
+
+	if b.cache != nil {
+		b.cache.set(key, packaged)
+	}
+	return nil
+}
+
+// MissingDependencies returns the dependencies listed in the Chart.yaml of
+// the given chart which are not vendored in the chart.
+func MissingDependencies(c *chart.Chart) []*chart.Dependency {
+	if c.Metadata == nil {
+		return nil
+	}
+
+	vendored := make(map[string]struct{}, len(c.Dependencies()))
+	for _, d := range c.Dependencies() {
+		vendored[d.Name()] = struct{}{}
+	}
+
+	var missing []*chart.Dependency
+	for _, dep := range c.Metadata.Dependencies {
+		if dep == nil {
+			continue
+		}
+		if _, ok := vendored[dep.Name]; !ok {
+			missing = append(missing, dep)
+		}
+	}
+	return missing
+}
+
+// repositoryAuth returns the RepositoryAuth for the chart repository at the
+// given URL, or nil if no RepositoryAuthFunc is configured or the repository
+// does not require authentication.
+func (b *DependencyBuilder) repositoryAuth(ctx context.Context, repoURL string) (*RepositoryAuth, error) {
+	if !strings.HasPrefix(repoURL, "http://") && !strings.HasPrefix(repoURL, "https://") {
+		return nil, errors.New("unsupported repository: only HTTP(S) chart repositories are supported, " +
+			"other dependencies must be vendored in the charts/ directory")
+	}
+	if b.authFunc == nil {
+		return nil, nil
+	}
+	auth, err := b.authFunc(ctx, repoURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get credentials for repository: %w", err)
+	}
+	return auth, nil
+}
+
+// resolve returns the chart version of the dependency matching the given
+// version from the index of its chart repository, using the given
+// credentials.
+func (b *DependencyBuilder) resolve(ctx context.Context, dep *chart.Dependency, version string, auth *RepositoryAuth) (*repo.ChartVersion, error) {
+	repoURL := strings.TrimSuffix(dep.Repository, "/")
+	data, err := b.get(ctx, repoURL+"/index.yaml", auth, MaxRepositoryIndexSize)
+	if err != nil {
+		return nil, err
+	}
+	index := &repo.IndexFile{}
+	if err = yaml.Unmarshal(data, index); err != nil {
+		return nil, fmt.Errorf("failed to parse repository index: %w", err)
+	}
+	index.SortEntries()
+
+	cv, err := index.Get(dep.Name, version)
+	if err != nil {
+		return nil, fmt.Errorf("no chart version matching '%s': %w", version, err)
+	}
+	if len(cv.URLs) == 0 {
+		return nil, fmt.Errorf("chart version '%s' has no download URL", cv.Version)
+	}
+	return cv, nil
+}
+
+// download returns the packaged chart of the given resolved chart version
+// of the dependency. The credentials are only used to download the chart if
+// its URL has the same scheme and host as the repository, unless
+// RepositoryAuth.PassCredentials is set.
+func (b *DependencyBuilder) download(ctx context.Context, dep *chart.Dependency, cv *repo.ChartVersion, auth *RepositoryAuth) ([]byte, error) {
+	repoURL := strings.TrimSuffix(dep.Repository, "/")
+	chartURL, err := repo.ResolveReferenceURL(repoURL, cv.URLs[0])
+	if err != nil {
+		return nil, err
+	}
+	if auth != nil && !auth.PassCredentials {
+		same, err := sameOrigin(repoURL, chartURL)
+		if err != nil {
+			return nil, err
+		}
+		if !same {
+			auth = nil
+		}
+	}
+	return b.get(ctx, chartURL, auth, MaxDependencySize)
+}
+
+// sameOrigin returns true if the given URLs have the same scheme and host.
+func sameOrigin(a, b string) (bool, error) {
+	u1, err := url.Parse(a)
+	if err != nil {
+		return false, err
+	}
+	u2, err := url.Parse(b)
+	if err != nil {
+		return false, err
+	}
+	return u1.Scheme == u2.Scheme && u1.Host == u2.Host, nil
+}
+
+// get downloads the given URL using the given credentials, and returns the
+// data if it does not exceed the given limit.
+func (b *DependencyBuilder) get(ctx context.Context, URL string, auth *RepositoryAuth, limit int64) ([]byte, error) {
+	req, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, URL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	client := b.client
+	if auth != nil {
+		if auth.Username != "" || auth.Password != "" {
+			req.SetBasicAuth(auth.Username, auth.Password)
+		}
+		if auth.TLSConfig != nil {
+			transport := http.DefaultTransport.(*http.Transport).Clone()
+			transport.TLSClientConfig = auth.TLSConfig
+			client = retryablehttp.NewClient()
+			client.HTTPClient.Transport = transport
+			client.RetryWaitMin, client.RetryWaitMax = b.client.RetryWaitMin, b.client.RetryWaitMax
+			client.RetryMax, client.Logger = b.client.RetryMax, b.client.Logger
+		}
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("failed to download '%s' (status: %s): %w", URL, resp.Status, ErrUnauthorized)
+	default:
+		return nil, fmt.Errorf("failed to download '%s' (status: %s)", URL, resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to download '%s': %w", URL, err)
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("size of '%s' exceeds the limit of %d bytes", URL, limit)
+	}
+	return data, nil
+}
+
+// lockedVersion returns the version of the given dependency locked in the
+// Chart.lock of the chart, or the version constraint of the dependency. It
+// returns true if the version is locked.
+func lockedVersion(c *chart.Chart, dep *chart.Dependency) (string, bool) {
+	if c.Lock != nil {
+		for _, l := range c.Lock.Dependencies {
+			if l != nil && l.Name == dep.Name && strings.TrimSuffix(l.Repository, "/") == strings.TrimSuffix(dep.Repository, "/") {
+				return l.Version, true
+			}
+		}
+	}
+	return dep.Version, false
+}
+
+// dependencyCacheKey returns the key of the dependencies of the given chart
+// in a DependencyCache. This is the digest of the given namespace, the
+// Chart.lock, or the dependencies listed in the Chart.yaml if there is no
+// lock, the version of each missing dependency, and the identity of the
+// given credentials per repository URL. The version of a dependency is taken
+// from the given resolved chart versions, or else from the Chart.lock.
+func dependencyCacheKey(namespace string, c *chart.Chart, resolved map[string]*repo.ChartVersion,
+	auths map[string]*RepositoryAuth) (string, error) {
+	var lock string
+	if c.Lock != nil && c.Lock.Digest != "" {
+		lock = c.Lock.Digest
+	} else {
+		b, err := json.Marshal(c.Metadata.Dependencies)
+		if err != nil {
+			return "", fmt.Errorf("failed to compute digest of chart dependencies: %w", err)
+		}
+		lock = digest.Canonical.FromBytes(b).String()
+	}
+
+	repos := make([]string, 0, len(auths))
+	for repoURL := range auths {
+		repos = append(repos, repoURL)
+	}
+	sort.Strings(repos)
+
+	var identities []string
+	for _, repoURL := range repos {
+		identity := ""
+		if auth := auths[repoURL]; auth != nil {
+			identity = auth.Identity
+		}
+		identities = append(identities, repoURL+"="+identity)
+	}
+
+	var versions []string
+	for _, dep := range MissingDependencies(c) {
+		version, _ := lockedVersion(c, dep)
+		if cv, ok := resolved[dep.Name]; ok {
+			version = cv.Version
+		}
+		versions = append(versions, dep.Name+"="+version)
+	}
+
+	b, err := json.Marshal(struct {
+		Namespace   string   `json:"namespace"`
+		Lock        string   `json:"lock"`
+		Versions    []string `json:"versions"`
+		Credentials []string `json:"credentials"`
+	}{namespace, lock, versions, identities})
+	if err != nil {
+		return "", fmt.Errorf("failed to compute digest of chart dependencies: %w", err)
+	}
+	return digest.Canonical.FromBytes(b).String(), nil
+}
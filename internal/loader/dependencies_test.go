@@ -0,0 +1,364 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/hashicorp/go-retryablehttp"
+	. "github.com/onsi/gomega"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+const testRepositoryIndex = `apiVersion: v1
+entries:
+  chart:
+  - apiVersion: v2
+    name: chart
+    version: 0.2.0
+    urls:
+    - chart-0.2.0.tgz
+  - apiVersion: v2
+    name: chart
+    version: 0.1.0
+    urls:
+    - chart-0.1.0.tgz
+`
+
+func newDependencyTestServer(t *testing.T, hits *atomic.Int32) *httptest.Server {
+	t.Helper()
+
+	b, err := os.ReadFile("testdata/chart-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		hits.Add(1)
+		path := req.URL.Path
+		if strings.HasPrefix(path, "/private/") {
+			if u, p, ok := req.BasicAuth(); !ok || u != "user" || p != "pass" {
+				res.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			path = strings.TrimPrefix(path, "/private")
+		}
+		switch path {
+		case "/repo/index.yaml":
+			_, _ = res.Write([]byte(testRepositoryIndex))
+		case "/repo/chart-0.1.0.tgz":
+			_, _ = res.Write(b)
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newDependencyTestChart(repository, version string) *chart.Chart {
+	return &chart.Chart{
+		Metadata: &chart.Metadata{
+			APIVersion: chart.APIVersionV2,
+			Name:       "parent",
+			Version:    "1.0.0",
+			Dependencies: []*chart.Dependency{
+				{Name: "chart", Version: version, Repository: repository},
+			},
+		},
+	}
+}
+
+func newDependencyTestClient() *retryablehttp.Client {
+	client := retryablehttp.NewClient()
+	client.RetryMax = 0
+	client.Logger = nil
+	return client
+}
+
+func TestDependencyBuilder_Build(t *testing.T) {
+	t.Run("builds unvendored dependency", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var hits atomic.Int32
+		server := newDependencyTestServer(t, &hits)
+
+		c := newDependencyTestChart(server.URL+"/repo", "0.1.x")
+		g.Expect(MissingDependencies(c)).To(HaveLen(1))
+
+		err := NewDependencyBuilder(newDependencyTestClient(), "default", nil, nil).Build(context.TODO(), c)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(c.Dependencies()).To(HaveLen(1))
+		g.Expect(c.Dependencies()[0].Name()).To(Equal("chart"))
+		g.Expect(c.Dependencies()[0].Metadata.Version).To(Equal("0.1.0"))
+		g.Expect(MissingDependencies(c)).To(BeEmpty())
+	})
+
+	t.Run("uses version from Chart.lock", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var hits atomic.Int32
+		server := newDependencyTestServer(t, &hits)
+
+		// Without a lock, the constraint resolves to 0.2.0 which can not be
+		// downloaded.
+		c := newDependencyTestChart(server.URL+"/repo", ">=0.1.0")
+		err := NewDependencyBuilder(newDependencyTestClient(), "default", nil, nil).Build(context.TODO(), c)
+		g.Expect(err).To(MatchError(ErrDependencyResolution))
+		g.Expect(err.Error()).To(ContainSubstring("chart-0.2.0.tgz"))
+
+		c = newDependencyTestChart(server.URL+"/repo", ">=0.1.0")
+		c.Lock = &chart.Lock{
+			Digest: "sha256:lock",
+			Dependencies: []*chart.Dependency{
+				{Name: "chart", Version: "0.1.0", Repository: server.URL + "/repo/"},
+			},
+		}
+		g.Expect(NewDependencyBuilder(newDependencyTestClient(), "default", nil, nil).Build(context.TODO(), c)).To(Succeed())
+		g.Expect(c.Dependencies()).To(HaveLen(1))
+	})
+
+	t.Run("reuses cached dependencies", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var hits atomic.Int32
+		server := newDependencyTestServer(t, &hits)
+
+		cache := NewDependencyCache(DefaultDependencyCacheSize, DefaultDependencyCacheMaxBytes)
+		builder := NewDependencyBuilder(newDependencyTestClient(), "default", nil, cache)
+
+		g.Expect(builder.Build(context.TODO(), newDependencyTestChart(server.URL+"/repo", "0.1.0"))).To(Succeed())
+		g.Expect(hits.Load()).To(Equal(int32(2)))
+
+		// The version of a dependency which is not locked is resolved from
+		// the index, but the chart is not downloaded again.
+		c := newDependencyTestChart(server.URL+"/repo", "0.1.0")
+		g.Expect(builder.Build(context.TODO(), c)).To(Succeed())
+		g.Expect(hits.Load()).To(Equal(int32(3)))
+		g.Expect(c.Dependencies()).To(HaveLen(1))
+
+		// A dependency locked in the Chart.lock is served from the cache
+		// without any request.
+		newLockedChart := func() *chart.Chart {
+			c := newDependencyTestChart(server.URL+"/repo", "0.1.x")
+			c.Lock = &chart.Lock{
+				Digest: "sha256:lock",
+				Dependencies: []*chart.Dependency{
+					{Name: "chart", Version: "0.1.0", Repository: server.URL + "/repo"},
+				},
+			}
+			return c
+		}
+		g.Expect(builder.Build(context.TODO(), newLockedChart())).To(Succeed())
+		g.Expect(hits.Load()).To(Equal(int32(5)))
+
+		c = newLockedChart()
+		g.Expect(builder.Build(context.TODO(), c)).To(Succeed())
+		g.Expect(hits.Load()).To(Equal(int32(5)))
+		g.Expect(c.Dependencies()).To(HaveLen(1))
+	})
+
+	t.Run("picks up newer versions of dependencies which are not locked", func(t *testing.T) {
+		g := NewWithT(t)
+
+		b, err := os.ReadFile("testdata/chart-0.1.0.tgz")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var (
+			index      atomic.Value
+			downloaded atomic.Value
+		)
+		index.Store(`apiVersion: v1
+entries:
+  chart:
+  - apiVersion: v2
+    name: chart
+    version: 0.1.0
+    urls:
+    - chart-0.1.0.tgz
+`)
+		server := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			if req.URL.Path == "/index.yaml" {
+				_, _ = res.Write([]byte(index.Load().(string)))
+				return
+			}
+			downloaded.Store(req.URL.Path)
+			_, _ = res.Write(b)
+		}))
+		t.Cleanup(server.Close)
+
+		cache := NewDependencyCache(DefaultDependencyCacheSize, DefaultDependencyCacheMaxBytes)
+		builder := NewDependencyBuilder(newDependencyTestClient(), "default", nil, cache)
+
+		g.Expect(builder.Build(context.TODO(), newDependencyTestChart(server.URL, "0.1.x"))).To(Succeed())
+		g.Expect(downloaded.Load()).To(Equal("/chart-0.1.0.tgz"))
+
+		// Release a new patch version matching the constraint.
+		index.Store(index.Load().(string) + `  - apiVersion: v2
+    name: chart
+    version: 0.1.1
+    urls:
+    - chart-0.1.1.tgz
+`)
+		g.Expect(builder.Build(context.TODO(), newDependencyTestChart(server.URL, "0.1.x"))).To(Succeed())
+		g.Expect(downloaded.Load()).To(Equal("/chart-0.1.1.tgz"))
+	})
+
+	t.Run("does not share cached dependencies across namespaces or credentials", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var hits atomic.Int32
+		server := newDependencyTestServer(t, &hits)
+		repoURL := server.URL + "/private/repo"
+
+		authFunc := func(_ context.Context, _ string) (*RepositoryAuth, error) {
+			return &RepositoryAuth{Username: "user", Password: "pass", Identity: "secret@1"}, nil
+		}
+		cache := NewDependencyCache(DefaultDependencyCacheSize, DefaultDependencyCacheMaxBytes)
+		g.Expect(NewDependencyBuilder(newDependencyTestClient(), "tenant-a", authFunc, cache).
+			Build(context.TODO(), newDependencyTestChart(repoURL, "0.1.0"))).To(Succeed())
+
+		err := NewDependencyBuilder(newDependencyTestClient(), "tenant-b", nil, cache).
+			Build(context.TODO(), newDependencyTestChart(repoURL, "0.1.0"))
+		g.Expect(err).To(MatchError(ErrUnauthorized))
+
+		err = NewDependencyBuilder(newDependencyTestClient(), "tenant-a", nil, cache).
+			Build(context.TODO(), newDependencyTestChart(repoURL, "0.1.0"))
+		g.Expect(err).To(MatchError(ErrUnauthorized))
+	})
+
+	t.Run("does not pass credentials to another host", func(t *testing.T) {
+		g := NewWithT(t)
+
+		b, err := os.ReadFile("testdata/chart-0.1.0.tgz")
+		g.Expect(err).ToNot(HaveOccurred())
+
+		var gotAuth atomic.Bool
+		chartServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			_, _, ok := req.BasicAuth()
+			gotAuth.Store(ok)
+			_, _ = res.Write(b)
+		}))
+		t.Cleanup(chartServer.Close)
+
+		// The loopback address differs in host from the chart server URL.
+		chartURL := strings.Replace(chartServer.URL, "127.0.0.1", "localhost", 1) + "/chart-0.1.0.tgz"
+		repoServer := httptest.NewServer(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+			_, _ = res.Write([]byte(strings.Replace(testRepositoryIndex, "chart-0.1.0.tgz", chartURL, 1)))
+		}))
+		t.Cleanup(repoServer.Close)
+
+		for _, pass := range []bool{false, true} {
+			authFunc := func(_ context.Context, _ string) (*RepositoryAuth, error) {
+				return &RepositoryAuth{Username: "user", Password: "pass", PassCredentials: pass}, nil
+			}
+			c := newDependencyTestChart(repoServer.URL, "0.1.0")
+			g.Expect(NewDependencyBuilder(newDependencyTestClient(), "default", authFunc, nil).Build(context.TODO(), c)).To(Succeed())
+			g.Expect(gotAuth.Load()).To(Equal(pass))
+		}
+	})
+
+	t.Run("uses repository credentials", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var hits atomic.Int32
+		server := newDependencyTestServer(t, &hits)
+		repoURL := server.URL + "/private/repo"
+
+		c := newDependencyTestChart(repoURL, "0.1.0")
+		err := NewDependencyBuilder(newDependencyTestClient(), "default", nil, nil).Build(context.TODO(), c)
+		g.Expect(err).To(MatchError(ErrDependencyResolution))
+		g.Expect(err).To(MatchError(ErrUnauthorized))
+
+		var gotURL string
+		authFunc := func(_ context.Context, u string) (*RepositoryAuth, error) {
+			gotURL = u
+			return &RepositoryAuth{Username: "user", Password: "pass"}, nil
+		}
+		c = newDependencyTestChart(repoURL+"/", "0.1.0")
+		g.Expect(NewDependencyBuilder(newDependencyTestClient(), "default", authFunc, nil).Build(context.TODO(), c)).To(Succeed())
+		g.Expect(gotURL).To(Equal(repoURL))
+		g.Expect(c.Dependencies()).To(HaveLen(1))
+	})
+
+	t.Run("unresolvable dependency", func(t *testing.T) {
+		g := NewWithT(t)
+
+		var hits atomic.Int32
+		server := newDependencyTestServer(t, &hits)
+
+		c := newDependencyTestChart(server.URL+"/repo", "1.0.0")
+		err := NewDependencyBuilder(newDependencyTestClient(), "default", nil, nil).Build(context.TODO(), c)
+		g.Expect(err).To(MatchError(ErrDependencyResolution))
+		g.Expect(err.Error()).To(ContainSubstring("no chart version matching '1.0.0'"))
+	})
+
+	t.Run("unsupported repository", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := newDependencyTestChart("oci://registry.example.com/charts", "0.1.0")
+		err := NewDependencyBuilder(newDependencyTestClient(), "default", nil, nil).Build(context.TODO(), c)
+		g.Expect(err).To(MatchError(ErrDependencyResolution))
+		g.Expect(err.Error()).To(ContainSubstring("must be vendored"))
+	})
+
+	t.Run("vendored dependencies", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := newDependencyTestChart("oci://registry.example.com/charts", "0.1.0")
+		c.AddDependency(&chart.Chart{Metadata: &chart.Metadata{Name: "chart", Version: "0.1.0"}})
+		g.Expect(NewDependencyBuilder(newDependencyTestClient(), "default", nil, nil).Build(context.TODO(), c)).To(Succeed())
+	})
+}
+
+func TestDependencyCache(t *testing.T) {
+	g := NewWithT(t)
+
+	cache := NewDependencyCache(2, DefaultDependencyCacheMaxBytes)
+	cache.set("a", map[string][]byte{"a": nil})
+	cache.set("b", map[string][]byte{"b": nil})
+	cache.set("c", map[string][]byte{"c": nil})
+
+	_, ok := cache.get("a")
+	g.Expect(ok).To(BeFalse())
+	_, ok = cache.get("b")
+	g.Expect(ok).To(BeTrue())
+	_, ok = cache.get("c")
+	g.Expect(ok).To(BeTrue())
+
+	cache = NewDependencyCache(DefaultDependencyCacheSize, 10)
+	cache.set("a", map[string][]byte{"a": make([]byte, 4)})
+	cache.set("b", map[string][]byte{"b": make([]byte, 4)})
+	cache.set("c", map[string][]byte{"c": make([]byte, 4)})
+	cache.set("d", map[string][]byte{"d": make([]byte, 11)})
+
+	_, ok = cache.get("a")
+	g.Expect(ok).To(BeFalse())
+	_, ok = cache.get("b")
+	g.Expect(ok).To(BeTrue())
+	_, ok = cache.get("c")
+	g.Expect(ok).To(BeTrue())
+	_, ok = cache.get("d")
+	g.Expect(ok).To(BeFalse())
+	g.Expect(cache.bytes).To(Equal(int64(8)))
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+// MaxEmbeddedChartSize is the maximum size in bytes of a packaged chart
+// embedded in a ConfigMap or Secret, after base64 decoding.
+const MaxEmbeddedChartSize = 1024 * 1024
+
+// ErrInvalidEmbeddedChart signals a chart embedded in a ConfigMap or Secret
+// could not be loaded.
+var ErrInvalidEmbeddedChart = errors.New("invalid embedded chart")
+
+// gzipMagic is the header of a gzip compressed file.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// LoadEmbeddedChart loads a packaged Helm chart from the given data, which
+// is either a gzipped tarball or the base64 encoding of one. The packaged
+// chart may not exceed the given size limit, and its decompressed contents
+// may not exceed 16 times the limit. It returns the loaded chart.Chart, or
+// an error of type ErrInvalidEmbeddedChart.
+func LoadEmbeddedChart(data []byte, limit int) (*chart.Chart, error) {
+	if !bytes.HasPrefix(data, gzipMagic) {
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+		n, err := base64.StdEncoding.Decode(decoded, bytes.TrimSpace(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: data is neither a gzipped tarball nor base64 encoded: %s",
+				ErrInvalidEmbeddedChart, err)
+		}
+		data = decoded[:n]
+	}
+	if len(data) > limit {
+		return nil, fmt.Errorf("%w: size of %d bytes exceeds the limit of %d bytes",
+			ErrInvalidEmbeddedChart, len(data), limit)
+	}
+	if err := checkDecompressedSize(data, int64(limit)*16); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEmbeddedChart, err)
+	}
+
+	c, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidEmbeddedChart, err)
+	}
+	return c, nil
+}
+
+// checkDecompressedSize returns an error if the given gzip compressed data
+// can not be decompressed, or if its decompressed size exceeds the limit.
+func checkDecompressedSize(data []byte, limit int64) error {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	n, err := io.Copy(io.Discard, io.LimitReader(r, limit+1))
+	if err != nil {
+		return err
+	}
+	if n > limit {
+		return fmt.Errorf("decompressed size exceeds the limit of %d bytes", limit)
+	}
+	return nil
+}
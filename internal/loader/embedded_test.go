@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package loader
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func TestLoadEmbeddedChart(t *testing.T) {
+	b, err := os.ReadFile("testdata/chart-0.1.0.tgz")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	compress := func(data []byte) []byte {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		_, _ = w.Write(data)
+		_ = w.Close()
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		limit   int
+		wantErr string
+	}{
+		{
+			name:  "gzipped tarball",
+			data:  b,
+			limit: MaxEmbeddedChartSize,
+		},
+		{
+			name:  "base64 encoded",
+			data:  []byte(base64.StdEncoding.EncodeToString(b)),
+			limit: MaxEmbeddedChartSize,
+		},
+		{
+			name:  "base64 encoded with trailing newline",
+			data:  []byte(base64.StdEncoding.EncodeToString(b) + "\n"),
+			limit: MaxEmbeddedChartSize,
+		},
+		{
+			name:    "invalid data",
+			data:    []byte("not a chart!"),
+			limit:   MaxEmbeddedChartSize,
+			wantErr: "neither a gzipped tarball nor base64 encoded",
+		},
+		{
+			name:    "exceeds size limit",
+			data:    b,
+			limit:   len(b) - 1,
+			wantErr: "exceeds the limit",
+		},
+		{
+			name:    "exceeds decompressed size limit",
+			data:    compress(make([]byte, 2*MaxEmbeddedChartSize)),
+			limit:   MaxEmbeddedChartSize / 16,
+			wantErr: "decompressed size exceeds the limit",
+		},
+		{
+			name:    "not a tarball",
+			data:    compress([]byte("not a tarball")),
+			limit:   MaxEmbeddedChartSize,
+			wantErr: ErrInvalidEmbeddedChart.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := LoadEmbeddedChart(tt.data, tt.limit)
+			if tt.wantErr != "" {
+				g.Expect(err).To(MatchError(ErrInvalidEmbeddedChart))
+				g.Expect(err.Error()).To(ContainSubstring(tt.wantErr))
+				g.Expect(got).To(BeNil())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got.Name()).To(Equal("chart"))
+			g.Expect(got.Metadata.Version).To(Equal("0.1.0"))
+		})
+	}
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package postrender
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 
 	"github.com/opencontainers/go-digest"
 	helmpostrender "helm.sh/helm/v3/pkg/postrender"
@@ -32,14 +34,25 @@ func BuildPostRenderers(rel *v2.HelmRelease) helmpostrender.PostRenderer {
 		return nil
 	}
 	renderers := make([]helmpostrender.PostRenderer, 0)
-	for _, r := range rel.Spec.PostRenderers {
-		if r.Kustomize != nil {
-			renderers = append(renderers, &Kustomize{
+	for i, r := range rel.Spec.PostRenderers {
+		switch {
+		case r.Kustomize != nil:
+			renderers = append(renderers, newStage(i, "kustomize", &Kustomize{
 				Patches: r.Kustomize.Patches,
 				Images:  r.Kustomize.Images,
-			})
+			}))
+		case len(r.Labels) > 0:
+			renderers = append(renderers, newStage(i, "labels", NewLabels(r.Labels)))
+		case len(r.Annotations) > 0:
+			renderers = append(renderers, newStage(i, "annotations", NewAnnotations(r.Annotations)))
 		}
 	}
+	if r := NewCommonMetadata(rel.Spec.CommonMetadata); r != nil {
+		renderers = append(renderers, r)
+	}
+	if rel.Spec.RequiredLabels != nil && len(rel.Spec.RequiredLabels.Inject) > 0 {
+		renderers = append(renderers, NewRequiredLabels(rel.Spec.RequiredLabels.Inject))
+	}
 	renderers = append(renderers, NewOriginLabels(v2.GroupVersion.Group, rel.Namespace, rel.Name))
 	if len(renderers) == 0 {
 		return nil
@@ -47,6 +60,26 @@ func BuildPostRenderers(rel *v2.HelmRelease) helmpostrender.PostRenderer {
 	return NewCombined(renderers...)
 }
 
+// stage wraps a post renderer defined in the HelmRelease, to identify it in
+// the error returned when it fails.
+type stage struct {
+	index    int
+	kind     string
+	renderer helmpostrender.PostRenderer
+}
+
+func newStage(index int, kind string, renderer helmpostrender.PostRenderer) *stage {
+	return &stage{index: index, kind: kind, renderer: renderer}
+}
+
+func (s *stage) Run(renderedManifests *bytes.Buffer) (modifiedManifests *bytes.Buffer, err error) {
+	modifiedManifests, err = s.renderer.Run(renderedManifests)
+	if err != nil {
+		return nil, fmt.Errorf("post renderer %d (%s) failed: %w", s.index, s.kind, err)
+	}
+	return modifiedManifests, nil
+}
+
 func Digest(algo digest.Algorithm, postrenders []v2.PostRenderer) digest.Digest {
 	digester := algo.Digester()
 	enc := json.NewEncoder(digester.Hash())
@@ -55,3 +88,25 @@ func Digest(algo digest.Algorithm, postrenders []v2.PostRenderer) digest.Digest
 	}
 	return digester.Digest()
 }
+
+// SpecDigest returns the digest of the post-renderers and the common
+// metadata of the given HelmRelease, or an empty string if it has neither.
+// Without common metadata, it equals the Digest of the post-renderers.
+func SpecDigest(algo digest.Algorithm, rel *v2.HelmRelease) string {
+	if rel.Spec.CommonMetadata == nil {
+		if rel.Spec.PostRenderers == nil {
+			return ""
+		}
+		return Digest(algo, rel.Spec.PostRenderers).String()
+	}
+
+	digester := algo.Digester()
+	enc := json.NewEncoder(digester.Hash())
+	if err := enc.Encode(struct {
+		PostRenderers  []v2.PostRenderer  `json:"postRenderers,omitempty"`
+		CommonMetadata *v2.CommonMetadata `json:"commonMetadata"`
+	}{rel.Spec.PostRenderers, rel.Spec.CommonMetadata}); err != nil {
+		return ""
+	}
+	return digester.Digest().String()
+}
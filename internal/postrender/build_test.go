@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opencontainers/go-digest"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fluxcd/pkg/apis/kustomize"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func TestBuildPostRenderers(t *testing.T) {
+	labels := v2.PostRenderer{
+		Labels: map[string]string{"tier": "injected"},
+	}
+	patch := v2.PostRenderer{
+		Kustomize: &v2.Kustomize{
+			Patches: []kustomize.Patch{
+				{
+					Target: &kustomize.Selector{Version: "v1", Kind: "Pod", Name: "json6902"},
+					Patch: `- op: add
+  path: /metadata/labels
+  value:
+    tier: patched
+`,
+				},
+			},
+		},
+	}
+	failingPatch := v2.PostRenderer{
+		Kustomize: &v2.Kustomize{
+			Patches: []kustomize.Patch{
+				{
+					Target: &kustomize.Selector{Version: "v1", Kind: "Pod", Name: "json6902"},
+					Patch: `- op: test
+  path: /metadata/annotations/c
+  value: bar
+`,
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name          string
+		postRenderers []v2.PostRenderer
+		wantContains  string
+		wantErr       string
+	}{
+		{
+			name:          "labels before kustomize",
+			postRenderers: []v2.PostRenderer{labels, patch},
+			wantContains:  "tier: patched",
+		},
+		{
+			name:          "kustomize before labels",
+			postRenderers: []v2.PostRenderer{patch, labels},
+			wantContains:  "tier: injected",
+		},
+		{
+			name:          "annotations",
+			postRenderers: []v2.PostRenderer{{Annotations: map[string]string{"c": "injected"}}},
+			wantContains:  "c: injected",
+		},
+		{
+			name:          "failing stage",
+			postRenderers: []v2.PostRenderer{labels, failingPatch, patch},
+			wantErr:       "post renderer 1 (kustomize) failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "name",
+					Namespace: "namespace",
+				},
+				Spec: v2.HelmReleaseSpec{
+					PostRenderers: tt.postRenderers,
+				},
+			}
+			got, err := BuildPostRenderers(obj).Run(bytes.NewBufferString(json6902Mock))
+			if tt.wantErr != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tt.wantErr))
+				g.Expect(got).To(BeNil())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got.String()).To(ContainSubstring(tt.wantContains))
+		})
+	}
+}
+
+func TestSpecDigest(t *testing.T) {
+	g := NewWithT(t)
+
+	postRenderers := []v2.PostRenderer{{Labels: map[string]string{"tier": "injected"}}}
+	obj := &v2.HelmRelease{}
+	g.Expect(SpecDigest(digest.Canonical, obj)).To(BeEmpty())
+
+	obj.Spec.PostRenderers = postRenderers
+	g.Expect(SpecDigest(digest.Canonical, obj)).To(Equal(Digest(digest.Canonical, postRenderers).String()))
+
+	obj.Spec.CommonMetadata = &v2.CommonMetadata{Labels: map[string]string{"team": "a"}}
+	withMetadata := SpecDigest(digest.Canonical, obj)
+	g.Expect(withMetadata).ToNot(BeEmpty())
+	g.Expect(withMetadata).ToNot(Equal(Digest(digest.Canonical, postRenderers).String()))
+
+	obj.Spec.CommonMetadata.Labels["team"] = "b"
+	g.Expect(SpecDigest(digest.Canonical, obj)).ToNot(Equal(withMetadata))
+
+	obj.Spec.PostRenderers = nil
+	g.Expect(SpecDigest(digest.Canonical, obj)).ToNot(BeEmpty())
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+
+	helmpostrender "helm.sh/helm/v3/pkg/postrender"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// NewCommonMetadata returns a post renderer which sets the labels and
+// annotations of the given CommonMetadata on all the rendered resources. It
+// returns nil if the CommonMetadata does not define any.
+func NewCommonMetadata(m *v2.CommonMetadata) helmpostrender.PostRenderer {
+	if m == nil {
+		return nil
+	}
+	var renderers []helmpostrender.PostRenderer
+	if len(m.Labels) > 0 {
+		renderers = append(renderers, NewLabels(m.Labels))
+	}
+	if len(m.Annotations) > 0 {
+		renderers = append(renderers, NewAnnotations(m.Annotations))
+	}
+	if len(renderers) == 0 {
+		return nil
+	}
+	return NewCombined(renderers...)
+}
+
+// ApplyCommonMetadata sets the labels and annotations of the given
+// CommonMetadata on all the resources in the given manifest, and returns the
+// modified manifest. It is used for the manifests of hooks, which Helm does
+// not pass through the post renderers.
+func ApplyCommonMetadata(manifest string, m *v2.CommonMetadata) (string, error) {
+	renderer := NewCommonMetadata(m)
+	if renderer == nil {
+		return manifest, nil
+	}
+	modified, err := renderer.Run(bytes.NewBufferString(manifest))
+	if err != nil {
+		return "", err
+	}
+	return modified.String(), nil
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+const hookMock = `# Source: chart/templates/hook.yaml
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migration
+  annotations:
+    helm.sh/hook: pre-install
+`
+
+func TestNewCommonMetadata(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(NewCommonMetadata(nil)).To(BeNil())
+	g.Expect(NewCommonMetadata(&v2.CommonMetadata{})).To(BeNil())
+
+	got, err := NewCommonMetadata(&v2.CommonMetadata{
+		Labels:      map[string]string{"team": "platform", "existing": "overwritten"},
+		Annotations: map[string]string{"cost-center": "1234"},
+	}).Run(bytes.NewBufferString(mixedResourceMock))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got.String()).To(Equal(`apiVersion: v1
+kind: Pod
+metadata:
+  annotations:
+    cost-center: "1234"
+  labels:
+    existing: overwritten
+    team: platform
+  name: pod-without-labels
+---
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    cost-center: "1234"
+  labels:
+    existing: overwritten
+    team: platform
+  name: service-with-labels
+`))
+}
+
+func TestApplyCommonMetadata(t *testing.T) {
+	g := NewWithT(t)
+
+	got, err := ApplyCommonMetadata(hookMock, nil)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(Equal(hookMock))
+
+	got, err = ApplyCommonMetadata(hookMock, &v2.CommonMetadata{
+		Labels:      map[string]string{"team": "platform"},
+		Annotations: map[string]string{"cost-center": "1234"},
+	})
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(Equal(`apiVersion: batch/v1
+kind: Job
+metadata:
+  annotations:
+    cost-center: "1234"
+    helm.sh/hook: pre-install
+  labels:
+    team: platform
+  name: migration
+`))
+
+	_, err = ApplyCommonMetadata("invalid: [", &v2.CommonMetadata{Labels: map[string]string{"team": "platform"}})
+	g.Expect(err).To(HaveOccurred())
+}
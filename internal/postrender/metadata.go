@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+	"sigs.k8s.io/kustomize/api/resource"
+)
+
+// NewLabels returns a Metadata post renderer which sets the given labels on
+// all the rendered resources.
+func NewLabels(labels map[string]string) *Metadata {
+	return &Metadata{
+		values: labels,
+		get: func(res *resource.Resource) map[string]string {
+			return res.GetLabels()
+		},
+		set: func(res *resource.Resource, labels map[string]string) error {
+			return res.SetLabels(labels)
+		},
+	}
+}
+
+// NewAnnotations returns a Metadata post renderer which sets the given
+// annotations on all the rendered resources.
+func NewAnnotations(annotations map[string]string) *Metadata {
+	return &Metadata{
+		values: annotations,
+		get: func(res *resource.Resource) map[string]string {
+			return res.GetAnnotations()
+		},
+		set: func(res *resource.Resource, annotations map[string]string) error {
+			return res.SetAnnotations(annotations)
+		},
+	}
+}
+
+// Metadata is a Helm post renderer which sets labels or annotations on all
+// the rendered resources. Unlike RequiredLabels, it overwrites the values
+// set by the chart or a previous post renderer.
+type Metadata struct {
+	values map[string]string
+	get    func(*resource.Resource) map[string]string
+	set    func(*resource.Resource, map[string]string) error
+}
+
+func (k *Metadata) Run(renderedManifests *bytes.Buffer) (modifiedManifests *bytes.Buffer, err error) {
+	resFactory := provider.NewDefaultDepProvider().GetResourceFactory()
+	resMapFactory := resmap.NewFactory(resFactory)
+
+	resMap, err := resMapFactory.NewResMapFromBytes(renderedManifests.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, res := range resMap.Resources() {
+		values := k.get(res)
+		if values == nil {
+			values = make(map[string]string, len(k.values))
+		}
+		for key, value := range k.values {
+			values[key] = value
+		}
+		if err = k.set(res, values); err != nil {
+			return nil, err
+		}
+	}
+
+	yaml, err := resMap.AsYaml()
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewBuffer(yaml), nil
+}
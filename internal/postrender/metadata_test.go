@@ -0,0 +1,82 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_Metadata_Run(t *testing.T) {
+	tests := []struct {
+		name            string
+		renderer        *Metadata
+		expectManifests string
+	}{
+		{
+			name:     "labels",
+			renderer: NewLabels(map[string]string{"existing": "overwritten", "team": "platform"}),
+			expectManifests: `apiVersion: v1
+kind: Pod
+metadata:
+  labels:
+    existing: overwritten
+    team: platform
+  name: pod-without-labels
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    existing: overwritten
+    team: platform
+  name: service-with-labels
+`,
+		},
+		{
+			name:     "annotations",
+			renderer: NewAnnotations(map[string]string{"team": "platform"}),
+			expectManifests: `apiVersion: v1
+kind: Pod
+metadata:
+  annotations:
+    team: platform
+  name: pod-without-labels
+---
+apiVersion: v1
+kind: Service
+metadata:
+  annotations:
+    team: platform
+  labels:
+    existing: label
+  name: service-with-labels
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := tt.renderer.Run(bytes.NewBufferString(mixedResourceMock))
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got.String()).To(Equal(tt.expectManifests))
+		})
+	}
+}
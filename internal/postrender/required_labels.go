@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/provider"
+	"sigs.k8s.io/kustomize/api/resmap"
+
+	ssautil "github.com/fluxcd/pkg/ssa/utils"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/diff"
+)
+
+// NewRequiredLabels returns a RequiredLabels post renderer which sets the
+// given labels on the resources which do not have them.
+func NewRequiredLabels(labels map[string]string) *RequiredLabels {
+	return &RequiredLabels{labels: labels}
+}
+
+// RequiredLabels is a Helm post renderer which sets labels on the rendered
+// resources which do not have them. Unlike OriginLabels, it does not
+// overwrite the labels set by the chart.
+type RequiredLabels struct {
+	labels map[string]string
+}
+
+func (k *RequiredLabels) Run(renderedManifests *bytes.Buffer) (modifiedManifests *bytes.Buffer, err error) {
+	resFactory := provider.NewDefaultDepProvider().GetResourceFactory()
+	resMapFactory := resmap.NewFactory(resFactory)
+
+	resMap, err := resMapFactory.NewResMapFromBytes(renderedManifests.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, res := range resMap.Resources() {
+		labels := res.GetLabels()
+		var changed bool
+		for key, value := range k.labels {
+			if _, ok := labels[key]; ok {
+				continue
+			}
+			if labels == nil {
+				labels = make(map[string]string, len(k.labels))
+			}
+			labels[key] = value
+			changed = true
+		}
+		if changed {
+			if err = res.SetLabels(labels); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	yaml, err := resMap.AsYaml()
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewBuffer(yaml), nil
+}
+
+// CheckRequiredLabels returns a description of each resource in the given
+// manifest which does not have all the given label keys, in the format
+// `Kind/namespace/name (missing: key1, key2)`.
+func CheckRequiredLabels(manifest string, keys []string) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+
+	objects, err := ssautil.ReadObjects(strings.NewReader(manifest))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read objects from release manifest: %w", err)
+	}
+
+	var violations []string
+	for _, obj := range objects {
+		labels := obj.GetLabels()
+		var missing []string
+		for _, key := range keys {
+			if _, ok := labels[key]; !ok {
+				missing = append(missing, key)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			violations = append(violations, fmt.Sprintf("%s (missing: %s)",
+				diff.ResourceName(obj), strings.Join(missing, ", ")))
+		}
+	}
+	return violations, nil
+}
+
+// RequiredLabelsIgnoreRules returns the drift detection ignore rules for the
+// labels injected by the given policy, to exclude them from drift detection.
+func RequiredLabelsIgnoreRules(policy *v2.RequiredLabels) []v2.IgnoreRule {
+	if policy == nil || len(policy.Inject) == 0 {
+		return nil
+	}
+
+	paths := make([]string, 0, len(policy.Inject))
+	for key := range policy.Inject {
+		paths = append(paths, "/metadata/labels/"+escapeJSONPointer(key))
+	}
+	sort.Strings(paths)
+	return []v2.IgnoreRule{{Paths: paths}}
+}
+
+// escapeJSONPointer escapes the given reference token for use in a JSON
+// Pointer (RFC 6901).
+func escapeJSONPointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
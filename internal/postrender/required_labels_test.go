@@ -0,0 +1,96 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package postrender
+
+import (
+	"bytes"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func Test_RequiredLabels_Run(t *testing.T) {
+	g := NewWithT(t)
+
+	k := NewRequiredLabels(map[string]string{"existing": "injected", "team": "platform"})
+	got, err := k.Run(bytes.NewBufferString(mixedResourceMock))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got.String()).To(Equal(`apiVersion: v1
+kind: Pod
+metadata:
+  labels:
+    existing: injected
+    team: platform
+  name: pod-without-labels
+---
+apiVersion: v1
+kind: Service
+metadata:
+  labels:
+    existing: label
+    team: platform
+  name: service-with-labels
+`))
+}
+
+func TestCheckRequiredLabels(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		want []string
+	}{
+		{
+			name: "no required labels",
+		},
+		{
+			name: "empty keys",
+			keys: []string{},
+		},
+		{
+			name: "missing labels",
+			keys: []string{"team", "existing"},
+			want: []string{
+				"Pod/pod-without-labels (missing: existing, team)",
+				"Service/service-with-labels (missing: team)",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := CheckRequiredLabels(mixedResourceMock, tt.keys)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestRequiredLabelsIgnoreRules(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(RequiredLabelsIgnoreRules(nil)).To(BeNil())
+	g.Expect(RequiredLabelsIgnoreRules(&v2.RequiredLabels{Keys: []string{"team"}})).To(BeNil())
+	g.Expect(RequiredLabelsIgnoreRules(&v2.RequiredLabels{
+		Keys:   []string{"team", "example.com/cost-center"},
+		Inject: map[string]string{"team": "platform", "example.com/cost-center": "1234"},
+	})).To(Equal([]v2.IgnoreRule{{
+		Paths: []string{"/metadata/labels/example.com~1cost-center", "/metadata/labels/team"},
+	}}))
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fluxcd/pkg/runtime/conditions"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/release"
+)
+
+// Adopt is an ActionReconciler which adopts the latest release for a
+// Request.Object in the Helm storage, which was not produced by the
+// controller. For example, because it was installed manually using the Helm
+// CLI, or by another tool.
+//
+// The release is observed, and recorded as the only Snapshot in the
+// Status.History. Which allows subsequent actions to determine the state of
+// the release as if it was produced by the controller, e.g. to upgrade it
+// once the desired state differs.
+//
+// The caller is expected to have confirmed the release can be adopted,
+// which is the case if it is deployed and was produced from the same chart.
+//
+// At the end of the reconciliation, the Status.Conditions are summarized and
+// propagated to the Ready condition on the Request.Object.
+type Adopt struct {
+	configFactory *action.ConfigFactory
+	eventRecorder record.EventRecorder
+}
+
+// NewAdopt returns a new Adopt reconciler configured with the provided
+// values.
+func NewAdopt(cfg *action.ConfigFactory, recorder record.EventRecorder) *Adopt {
+	return &Adopt{configFactory: cfg, eventRecorder: recorder}
+}
+
+func (r *Adopt) Reconcile(_ context.Context, req *Request) error {
+	defer summarize(req)
+
+	// Retrieve last release object.
+	rls, err := action.LastRelease(r.configFactory.Build(nil), req.Object.GetReleaseName())
+	if err != nil {
+		// Ignore not found error. Assume caller will decide what to do
+		// when it re-assess state to determine the next action.
+		if errors.Is(err, action.ErrReleaseNotFound) {
+			return nil
+		}
+		// Return any other error to retry.
+		return err
+	}
+
+	// Record the release as the only snapshot, any previous history can not
+	// be relied on.
+	req.Object.Status.ClearHistory()
+	cur := release.ObservedToSnapshot(release.ObserveRelease(rls))
+	req.Object.Status.History = v2.Snapshots{cur}
+
+	r.success(req, cur)
+	return nil
+}
+
+func (r *Adopt) Name() string {
+	return "adopt"
+}
+
+func (r *Adopt) Type() ReconcilerType {
+	return ReconcilerTypeAdopt
+}
+
+const (
+	// fmtAdoptSuccess is the message format for a successful adoption.
+	fmtAdoptSuccess = "Adopted Helm release %s with chart %s"
+)
+
+// success records the success of an adopt action in the status of the given
+// Request.Object by marking ReleasedCondition=True and emitting an event.
+func (r *Adopt) success(req *Request, cur *v2.Snapshot) {
+	// Compose success message.
+	msg := fmt.Sprintf(fmtAdoptSuccess, cur.FullReleaseName(), cur.VersionedChartName())
+
+	// Mark adopt success on object.
+	conditions.MarkTrue(req.Object, v2.ReleasedCondition, v2.AdoptSucceededReason, "%s", msg)
+
+	// Record event.
+	r.eventRecorder.AnnotatedEventf(
+		req.Object,
+		eventMeta(cur.ChartVersion, cur.ConfigDigest, addAppVersion(cur.AppVersion)),
+		corev1.EventTypeNormal,
+		v2.AdoptSucceededReason,
+		msg,
+	)
+}
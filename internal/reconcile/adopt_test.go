@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmstorage "helm.sh/helm/v3/pkg/storage"
+	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/kube"
+	"github.com/fluxcd/helm-controller/internal/release"
+	"github.com/fluxcd/helm-controller/internal/testutil"
+)
+
+func TestAdopt_Reconcile(t *testing.T) {
+	g := NewWithT(t)
+
+	// A release installed with e.g. the Helm CLI, which has been upgraded
+	// once.
+	releases := []*helmrelease.Release{
+		testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+			Name:      mockReleaseName,
+			Namespace: mockReleaseNamespace,
+			Version:   1,
+			Status:    helmrelease.StatusSuperseded,
+			Chart:     testutil.BuildChart(),
+		}),
+		testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+			Name:      mockReleaseName,
+			Namespace: mockReleaseNamespace,
+			Version:   2,
+			Status:    helmrelease.StatusDeployed,
+			Chart:     testutil.BuildChart(),
+		}, testutil.ReleaseWithConfig(map[string]interface{}{"foo": "bar"})),
+	}
+
+	obj := &v2.HelmRelease{
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			TargetNamespace:  mockReleaseNamespace,
+			StorageNamespace: mockReleaseNamespace,
+			Install:          &v2.Install{Adopt: true},
+		},
+	}
+
+	cfg, err := action.NewConfigFactory(&kube.MemoryRESTClientGetter{},
+		action.WithStorage(helmdriver.MemoryDriverName, mockReleaseNamespace),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	store := helmstorage.Init(cfg.Driver)
+	for _, r := range releases {
+		g.Expect(store.Create(r)).To(Succeed())
+	}
+
+	req := &Request{
+		Object: obj,
+		Chart:  testutil.BuildChart(),
+		Values: map[string]interface{}{"foo": "bar"},
+	}
+
+	// The existing release can be adopted.
+	state, err := DetermineReleaseState(context.TODO(), cfg, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(state.Status).To(Equal(ReleaseStatusAdoptable))
+
+	recorder := testutil.NewFakeRecorder(10, false)
+	g.Expect(NewAdopt(cfg, recorder).Reconcile(context.TODO(), req)).To(Succeed())
+
+	// Only the latest release is recorded in the history.
+	g.Expect(obj.Status.History).To(testutil.Equal(v2.Snapshots{
+		release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+	}))
+	g.Expect(obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{
+		*conditions.TrueCondition(meta.ReadyCondition, v2.AdoptSucceededReason, "Adopted Helm release"),
+		*conditions.TrueCondition(v2.ReleasedCondition, v2.AdoptSucceededReason, "Adopted Helm release"),
+	}))
+	g.Expect(recorder.GetEvents()).To(ConsistOf(
+		WithTransform(func(e corev1.Event) string { return e.Reason }, Equal(v2.AdoptSucceededReason)),
+	))
+
+	// Once adopted, the release is managed by the object.
+	state, err = DetermineReleaseState(context.TODO(), cfg, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(state.Status).To(Equal(ReleaseStatusInSync))
+}
@@ -23,12 +23,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Masterminds/semver"
 	"helm.sh/helm/v3/pkg/kube"
+	helmrelease "helm.sh/helm/v3/pkg/release"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/fluxcd/cli-utils/pkg/kstatus/status"
+	"github.com/fluxcd/pkg/apis/kustomize"
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/fluxcd/pkg/runtime/conditions"
 	"github.com/fluxcd/pkg/runtime/logger"
@@ -37,10 +41,14 @@ import (
 
 	v2 "github.com/fluxcd/helm-controller/api/v2"
 	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/chartutil"
+	"github.com/fluxcd/helm-controller/internal/defaults"
 	"github.com/fluxcd/helm-controller/internal/diff"
 	"github.com/fluxcd/helm-controller/internal/digest"
 	interrors "github.com/fluxcd/helm-controller/internal/errors"
+	"github.com/fluxcd/helm-controller/internal/hook"
 	"github.com/fluxcd/helm-controller/internal/postrender"
+	"github.com/fluxcd/helm-controller/internal/release"
 )
 
 // OwnedConditions is a list of Condition types owned by the HelmRelease object.
@@ -48,6 +56,10 @@ var OwnedConditions = []string{
 	v2.ReleasedCondition,
 	v2.RemediatedCondition,
 	v2.TestSuccessCondition,
+	v2.ChartDeprecatedCondition,
+	v2.SourceSuspendedCondition,
+	v2.ManifestExportedCondition,
+	v2.HistoryLimitReachedCondition,
 	meta.ReconcilingCondition,
 	meta.ReadyCondition,
 	meta.StalledCondition,
@@ -65,6 +77,10 @@ var (
 	// ErrMissingRollbackTarget is returned when the rollback target is missing.
 	ErrMissingRollbackTarget = errors.New("missing target release for rollback")
 
+	// ErrDowngradeBlocked is returned when an upgrade would downgrade the
+	// chart of the release, while downgrades are not allowed.
+	ErrDowngradeBlocked = errors.New("chart downgrade not allowed")
+
 	// ErrUnknownReleaseStatus is returned when the release status is unknown
 	// and cannot be acted upon.
 	ErrUnknownReleaseStatus = errors.New("unknown release status")
@@ -72,6 +88,16 @@ var (
 	// ErrUnknownRemediationStrategy is returned when the remediation strategy
 	// is unknown.
 	ErrUnknownRemediationStrategy = errors.New("unknown remediation strategy")
+
+	// ErrFailedResourcesKept is returned when a failed release is not
+	// remediated, as the resources of the failed release must be kept.
+	ErrFailedResourcesKept = errors.New("resources of failed release are kept")
+
+	// ErrWithinRetryDelay is returned when a failed release is not retried,
+	// as the failure occurred within the retry delay of the remediation
+	// strategy. The caller should requeue the object once the delay has
+	// passed, see RetryDelayRemaining.
+	ErrWithinRetryDelay = errors.New("failed release is retried after retry delay")
 )
 
 // AtomicRelease is an ActionReconciler which implements an atomic release
@@ -113,18 +139,102 @@ type AtomicRelease struct {
 	eventRecorder record.EventRecorder
 	strategy      releaseStrategy
 	fieldManager  string
+	preHook       *hook.Webhook
+	postHook      *hook.Webhook
+	pausedReason  string
+	pausedMsg     string
+	paused        bool
+
+	// windowClosed is true if the maintenance windows are closed, with
+	// windowNextOpen the time the next window opens.
+	windowClosed   bool
+	windowNextOpen time.Time
+	windowBypass   bool
+	forceRequested bool
+
+	summaryRecorder  *summaryEventRecorder
+	timelineRecorder *timelineRecorder
+}
+
+// AtomicReleaseOption configures an AtomicRelease reconciler.
+type AtomicReleaseOption func(*AtomicRelease)
+
+// WithReconcileHooks configures the webhooks to invoke before and after a
+// Helm release action is performed. Either may be nil.
+func WithReconcileHooks(pre, post *hook.Webhook) AtomicReleaseOption {
+	return func(r *AtomicRelease) {
+		r.preHook = pre
+		r.postHook = post
+	}
+}
+
+// WithReleaseActionsPaused pauses any action other than the correction of
+// drift. When an action is paused, the object is marked with Ready=False
+// using the given reason and message.
+func WithReleaseActionsPaused(reason, msg string) AtomicReleaseOption {
+	return func(r *AtomicRelease) {
+		r.pausedReason = reason
+		r.pausedMsg = msg
+	}
+}
+
+// WithReconciliationPaused pauses all actions, including the correction of
+// drift. Drift is still detected and reported, and the object is marked with
+// Ready=Unknown and v2.PausedReason. Other conditions and the history of the
+// object are left untouched.
+func WithReconciliationPaused() AtomicReleaseOption {
+	return func(r *AtomicRelease) {
+		r.paused = true
+	}
+}
+
+// WithMaintenanceWindowClosed defers the Helm install and upgrade actions,
+// and the correction of drift, until the given time at which the next
+// maintenance window opens. When allowBypass is true, the correction of
+// drift and releases forced using the v2.ForceRequestAnnotation are not
+// deferred.
+func WithMaintenanceWindowClosed(nextOpen time.Time, allowBypass bool) AtomicReleaseOption {
+	return func(r *AtomicRelease) {
+		r.windowClosed = true
+		r.windowNextOpen = nextOpen
+		r.windowBypass = allowBypass
+	}
+}
+
+// WithSummaryEvents consolidates the Normal events emitted during a
+// reconcile pass into a single event, which is emitted when the pass ends.
+// Warning events are still emitted as distinct events.
+func WithSummaryEvents() AtomicReleaseOption {
+	return func(r *AtomicRelease) {
+		r.summaryRecorder = newSummaryEventRecorder(r.eventRecorder)
+		r.eventRecorder = r.summaryRecorder
+	}
+}
+
+// WithTimelineEvents records the start and end of each action as native
+// Kubernetes events using the given recorder, in addition to the events
+// emitted by the actions. The recorder is expected to not forward the
+// events to the notification-controller.
+func WithTimelineEvents(recorder record.EventRecorder) AtomicReleaseOption {
+	return func(r *AtomicRelease) {
+		r.timelineRecorder = newTimelineRecorder(recorder)
+	}
 }
 
 // NewAtomicRelease returns a new AtomicRelease reconciler configured with the
 // provided values.
-func NewAtomicRelease(patchHelper *patch.SerialPatcher, cfg *action.ConfigFactory, recorder record.EventRecorder, fieldManager string) *AtomicRelease {
-	return &AtomicRelease{
+func NewAtomicRelease(patchHelper *patch.SerialPatcher, cfg *action.ConfigFactory, recorder record.EventRecorder, fieldManager string, opts ...AtomicReleaseOption) *AtomicRelease {
+	r := &AtomicRelease{
 		patchHelper:   patchHelper,
 		eventRecorder: recorder,
 		configFactory: cfg,
 		strategy:      &cleanReleaseStrategy{},
 		fieldManager:  fieldManager,
 	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
 }
 
 // releaseStrategy defines the continue-stop behavior of the reconcile loop.
@@ -165,6 +275,30 @@ func (r *AtomicRelease) Reconcile(ctx context.Context, req *Request) error {
 		previous ReconcilerTypeSet
 		next     ActionReconciler
 	)
+
+	// Remove a stale diff as soon as the diff only mode is disabled.
+	if !req.Object.Spec.DiffOnly {
+		req.Object.Status.LastDiff = nil
+	}
+
+	if r.summaryRecorder != nil {
+		defer r.summaryRecorder.Flush(req.Object)
+	}
+
+	// Record if the object was ready before running any action, to stop
+	// assessing the readiness of an install once it has been confirmed.
+	wasReady := conditions.IsReady(req.Object)
+
+	// Reset the count of stable reconciliations, which is only increased
+	// when the release is found in-sync and ready without running any
+	// action.
+	stableReconciliations := req.Object.Status.StableReconciliations
+	req.Object.Status.StableReconciliations = 0
+
+	// Render the release at most once during this reconciliation, and
+	// share the result between the preflight checks.
+	render := r.lazyRenderRelease(ctx, req)
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -184,11 +318,43 @@ func (r *AtomicRelease) Reconcile(ctx context.Context, req *Request) error {
 			// Determine the current state of the Helm release.
 			log.V(logger.DebugLevel).Info("determining current state of Helm release")
 			state, err := DetermineReleaseState(ctx, r.configFactory, req)
+			if errors.Is(err, action.ErrReleaseNameCollision) {
+				conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.ReleaseNameCollisionReason, "%s", err)
+				r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.ReleaseNameCollisionReason, err.Error())
+				return err
+			}
 			if err != nil {
 				conditions.MarkFalse(req.Object, meta.ReadyCondition, "StateError", "Could not determine release state: %s", err)
 				return fmt.Errorf("cannot determine release state: %w", err)
 			}
 
+			// Record the outcome of the drift check, which is only performed
+			// for a release which is otherwise in-sync.
+			if req.Object.GetDriftDetection().MustDetectChanges() &&
+				(state.Status == ReleaseStatusInSync || state.Status == ReleaseStatusDrifted) {
+				recordDriftCheck(req.Object, state.Status == ReleaseStatusDrifted, time.Now())
+			}
+
+			// Record the chart version available to a pinned release, while
+			// its upgrade is held back.
+			req.Object.Status.AvailableChartVersion = ""
+			if state.HeldBack != "" {
+				req.Object.Status.AvailableChartVersion = req.Chart.Metadata.Version
+			}
+
+			// If reconciliation is paused, report any drift without running
+			// an action for the state.
+			if r.paused {
+				if state.Status == ReleaseStatusDrifted {
+					r.reportDrift(ctx, req, state)
+				}
+				log.Info("not running any action reconciler: reconciliation is paused")
+				conditions.MarkUnknown(req.Object, meta.ReadyCondition, v2.PausedReason,
+					"Reconciliation is paused using the %s annotation", v2.PauseAnnotation)
+				conditions.Delete(req.Object, meta.ReconcilingCondition)
+				return nil
+			}
+
 			// Determine the next action to run based on the current state.
 			log.V(logger.DebugLevel).Info("determining next Helm action based on current state")
 			if next, err = r.actionForState(ctx, req, state); err != nil {
@@ -201,6 +367,23 @@ func (r *AtomicRelease) Reconcile(ctx context.Context, req *Request) error {
 					conditions.MarkStalled(req.Object, "MissingRollbackTarget", "Failed to perform remediation: %s", err)
 					return err
 				}
+				if errors.Is(err, ErrFailedResourcesKept) {
+					r.reportFailedResourcesKept(req)
+					conditions.Delete(req.Object, meta.ReconcilingCondition)
+					summarize(req)
+					return nil
+				}
+				if errors.Is(err, ErrWithinRetryDelay) {
+					conditions.Delete(req.Object, meta.ReconcilingCondition)
+					summarize(req)
+					return err
+				}
+				if errors.Is(err, ErrDowngradeBlocked) {
+					conditions.MarkStalled(req.Object, v2.DowngradeBlockedReason, "%s", err)
+					conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.DowngradeBlockedReason, "%s", err)
+					r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.DowngradeBlockedReason, err.Error())
+					return err
+				}
 				return err
 			}
 
@@ -212,15 +395,101 @@ func (r *AtomicRelease) Reconcile(ctx context.Context, req *Request) error {
 				// written to Ready.
 				summarize(req)
 
-				// remove stale post-renderers digest on successful reconciliation.
-				if conditions.IsReady(req.Object) {
-					req.Object.Status.ObservedPostRenderersDigest = ""
-					if req.Object.Spec.PostRenderers != nil {
-						// Update the post-renderers digest if the post-renderers exist.
-						req.Object.Status.ObservedPostRenderersDigest = postrender.Digest(digest.Canonical, req.Object.Spec.PostRenderers).String()
+				// Warn about a rollback target which is pruned by the next
+				// release. This is informational, and does not fail the
+				// reconciliation.
+				if err = r.recordHistoryLimit(req); err != nil {
+					log.Error(err, "failed to check release history limit")
+				}
+
+				// Check the health of the resources of the release, and
+				// record the result in the status.
+				if req.Object.UsesHealthChecks() {
+					if err = r.assessHealthChecks(ctx, req); err != nil {
+						return err
 					}
+				} else {
+					req.Object.Status.LastAppliedHealth = nil
+				}
+
+				// Assess the health of the resources of the release when the
+				// readiness is computed from a threshold.
+				if conditions.IsReady(req.Object) && req.Object.UsesReadinessThreshold() {
+					if err = r.assessReadiness(ctx, req); err != nil {
+						return err
+					}
+				}
+
+				// Assess the readiness of the resources of an install within
+				// its readiness grace period.
+				if conditions.IsReady(req.Object) && mustAssessInstallReadiness(req, wasReady, previous) {
+					if err = r.assessInstallReadiness(ctx, req); err != nil {
+						return err
+					}
+				}
+
+				// Report the upgrade held back for a pinned release. A
+				// failed or unmanaged release is not ready while its upgrade
+				// is held back.
+				if state.HeldBack != "" {
+					switch {
+					case state.Status == ReleaseStatusFailed || state.Status == ReleaseStatusUnmanaged:
+						conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.ReleasePinnedReason,
+							"Release is pinned, upgrade of %s release to chart version %s is held back: %s",
+							strings.ToLower(state.Status.String()), req.Object.Status.AvailableChartVersion, state.HeldBack)
+					case conditions.IsReady(req.Object):
+						cur := req.Object.Status.History.Latest()
+						conditions.MarkTrue(req.Object, meta.ReadyCondition, v2.ReleasePinnedReason,
+							"Release is pinned to %s, upgrade to chart version %s is held back: %s",
+							cur.VersionedChartName(), req.Object.Status.AvailableChartVersion, state.HeldBack)
+					}
+				}
+
+				// remove stale post-renderers digest on successful reconciliation.
+				// The post-renderers of a pinned release are not observed
+				// until it is upgraded.
+				if conditions.IsReady(req.Object) && state.HeldBack == "" {
+					// Update the post-renderers digest if the post-renderers or
+					// the common metadata exist.
+					req.Object.Status.ObservedPostRenderersDigest = postrender.SpecDigest(digest.Canonical, req.Object)
 				}
 
+				// Count the reconciliation as stable if the release was
+				// in-sync and is ready, without any action having run.
+				if len(previous) == 0 && state.Status == ReleaseStatusInSync && conditions.IsReady(req.Object) {
+					req.Object.Status.StableReconciliations = stableReconciliations + 1
+				}
+
+				return nil
+			}
+
+			// If only a diff is desired, dry-run the release action instead
+			// of performing it.
+			if req.Object.Spec.DiffOnly {
+				switch next.(type) {
+				case *Install, *Upgrade:
+					next = NewDiffOnly(r.configFactory, r.eventRecorder)
+				}
+			}
+
+			// If actions are paused, only allow the correction of drift.
+			if r.pausedReason != "" && next.Type() != ReconcilerTypeDriftCorrection {
+				log.Info(fmt.Sprintf("not running %s action reconciler %s: %s", next.Type(), next.Name(), r.pausedMsg))
+				conditions.MarkFalse(req.Object, meta.ReadyCondition, r.pausedReason, "%s", r.pausedMsg)
+				conditions.Delete(req.Object, meta.ReconcilingCondition)
+				return nil
+			}
+
+			// If the maintenance windows are closed, defer releases and the
+			// correction of drift until the next window opens.
+			if r.mustDeferToMaintenanceWindow(next) {
+				msg := "Helm actions are deferred until the next maintenance window opens"
+				if !r.windowNextOpen.IsZero() {
+					msg = fmt.Sprintf("%s at %s", msg, r.windowNextOpen.UTC().Format(time.RFC3339))
+				}
+				log.Info(fmt.Sprintf("not running %s action reconciler %s: %s", next.Type(), next.Name(), msg))
+				conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.OutsideMaintenanceWindowReason, "%s", msg)
+				conditions.Delete(req.Object, meta.ReconcilingCondition)
 				return nil
 			}
 
@@ -239,6 +508,17 @@ func (r *AtomicRelease) Reconcile(ctx context.Context, req *Request) error {
 				return nil
 			}
 
+			// Validate the values against the JSON schema of the chart, to
+			// fail before running an install or upgrade with a message naming
+			// the invalid values.
+			if mustValidateValuesSchema(next, req.Object) {
+				if err = chartutil.ValidateValuesSchema(req.Chart, req.Values); err != nil {
+					conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.ValuesSchemaInvalidReason, "%s", err)
+					r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.ValuesSchemaInvalidReason, err.Error())
+					return err
+				}
+			}
+
 			// Mark the release as reconciling before we attempt to run the action.
 			// This to show continuous progress, as Helm actions can be long-running.
 			reconcilingMsg := fmt.Sprintf("Running '%s' action with timeout of %s",
@@ -258,15 +538,104 @@ func (r *AtomicRelease) Reconcile(ctx context.Context, req *Request) error {
 				return err
 			}
 
+			// Invoke the pre reconcile hook, a failure blocks the action.
+			if next.Type() == ReconcilerTypeRelease && r.preHook != nil {
+				if err = r.preHook.Invoke(ctx, hookPayload(hook.PhasePre, next, req)); err != nil {
+					conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.PreReconcileHookFailedReason,
+						"Pre reconcile hook for '%s' action failed: %s", next.Name(), err)
+					r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.PreReconcileHookFailedReason,
+						"Pre reconcile hook for '%s' action failed: %s", next.Name(), err)
+					return err
+				}
+			}
+
+			// Check the estimated resource usage against the ResourceQuotas
+			// in the target namespace, to prevent a partial apply.
+			if next.Type() == ReconcilerTypeRelease && req.Object.GetPreflight().ResourceQuota {
+				if err = r.preflightResourceQuota(ctx, req, render); err != nil {
+					conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.ResourceQuotaExceededReason,
+						"Preflight check for '%s' action failed: %s", next.Name(), err)
+					r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.ResourceQuotaExceededReason,
+						"Preflight check for '%s' action failed: %s", next.Name(), err)
+					return err
+				}
+			}
+
+			// Check the rendered resources for the required labels, to
+			// enforce the labeling policy of the HelmRelease.
+			if next.Type() == ReconcilerTypeRelease && req.Object.Spec.RequiredLabels != nil {
+				if err = r.preflightRequiredLabels(ctx, req, render); err != nil {
+					conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.MissingRequiredLabelsReason,
+						"Preflight check for '%s' action failed: %s", next.Name(), err)
+					r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.MissingRequiredLabelsReason,
+						"Preflight check for '%s' action failed: %s", next.Name(), err)
+					return err
+				}
+			}
+
+			// Check if any of the rendered resources is managed by another
+			// Helm release, to prevent releases fighting over resources.
+			if next.Type() == ReconcilerTypeRelease && req.Object.GetPreflight().Conflicts {
+				if err = r.preflightConflicts(ctx, req, render); err != nil {
+					conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.ResourceConflictReason,
+						"Preflight check for '%s' action failed: %s", next.Name(), err)
+					r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.ResourceConflictReason,
+						"Preflight check for '%s' action failed: %s", next.Name(), err)
+					return err
+				}
+			}
+
+			// Render the release against the configured capability
+			// profiles, to report version specific breakage before it is
+			// rolled out to other clusters.
+			if next.Type() == ReconcilerTypeRelease && len(req.Object.GetPreflight().CapabilityProfiles) > 0 {
+				r.preflightCapabilityProfiles(ctx, req)
+			}
+
+			// Render the hooks of the chart, to allow inspection of them
+			// before they are run.
+			if next.Type() == ReconcilerTypeRelease {
+				r.preflightHooks(ctx, req, render)
+			}
+
 			// Run the action sub-reconciler.
 			log.Info(fmt.Sprintf("running '%s' action with timeout of %s", next.Name(), timeoutForAction(next, req.Object).String()))
-			if err = next.Reconcile(ctx, req); err != nil {
+			if r.timelineRecorder != nil {
+				r.timelineRecorder.actionStarted(req, next)
+			}
+			actionStart := time.Now()
+			err = next.Reconcile(ctx, req)
+			req.Object.Status.AddReconcilePhase(next.Name(), time.Since(actionStart))
+			if r.timelineRecorder != nil {
+				r.timelineRecorder.actionFinished(req, next, time.Since(actionStart), err)
+			}
+
+			// Invoke the post reconcile hook, a failure only results in a
+			// warning as the action has already been performed.
+			if next.Type() == ReconcilerTypeRelease && r.postHook != nil {
+				payload := hookPayload(hook.PhasePost, next, req)
+				succeeded := err == nil && conditions.IsTrue(req.Object, v2.ReleasedCondition)
+				payload.Succeeded = &succeeded
+				payload.Message = conditions.GetMessage(req.Object, v2.ReleasedCondition)
+				if hookErr := r.postHook.Invoke(ctx, payload); hookErr != nil {
+					r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.PostReconcileHookFailedReason,
+						"Post reconcile hook for '%s' action failed: %s", next.Name(), hookErr)
+				}
+			}
+
+			if err != nil {
 				if conditions.IsReady(req.Object) {
 					conditions.MarkFalse(req.Object, meta.ReadyCondition, "ReconcileError", "%s", err)
 				}
 				return err
 			}
 
+			// The diff does not change the state of the release, we are done.
+			if next.Type() == ReconcilerTypeDiff {
+				conditions.Delete(req.Object, meta.ReconcilingCondition)
+				return nil
+			}
+
 			// If we must stop after running the action, we are done for now...
 			if r.strategy.MustStop(next.Type(), previous) {
 				log.V(logger.DebugLevel).Info(fmt.Sprintf(
@@ -301,6 +670,22 @@ func (r *AtomicRelease) Reconcile(ctx context.Context, req *Request) error {
 	}
 }
 
+// mustDeferToMaintenanceWindow returns true if the given action must be
+// deferred, as the maintenance windows are closed.
+func (r *AtomicRelease) mustDeferToMaintenanceWindow(next ActionReconciler) bool {
+	if !r.windowClosed {
+		return false
+	}
+	switch next.(type) {
+	case *Install, *Upgrade:
+		return !(r.windowBypass && r.forceRequested)
+	case *CorrectClusterDrift:
+		return !r.windowBypass
+	default:
+		return false
+	}
+}
+
 // actionForState determines the next action to run based on the current state.
 func (r *AtomicRelease) actionForState(ctx context.Context, req *Request, state ReleaseState) (ActionReconciler, error) {
 	log := ctrl.LoggerFrom(ctx)
@@ -311,10 +696,40 @@ func (r *AtomicRelease) actionForState(ctx context.Context, req *Request, state
 	// then forcing an upgrade (due to the release now being in
 	// ReleaseStatusInSync with a yet unhandled force request).
 	forceRequested := v2.ShouldHandleForceRequest(req.Object)
+	r.forceRequested = r.forceRequested || forceRequested
+
+	// Roll back to the previous release if this has been requested, independent
+	// of the remediation strategy. A locked release must be unlocked first, in
+	// which case the request is handled after the unlock.
+	if state.Status != ReleaseStatusLocked && v2.ShouldHandleRollbackRequest(req.Object) {
+		if next := r.manualRollbackForState(ctx, req, state); next != nil {
+			return next, nil
+		}
+	}
+
+	// Restore the release from the latest backup if this has been requested.
+	// Like a rollback, a locked release must be unlocked first.
+	if state.Status != ReleaseStatusLocked && v2.ShouldHandleRestoreRequest(req.Object) {
+		if next := r.restoreForState(ctx, req, state); next != nil {
+			return next, nil
+		}
+	}
+
+	// Re-run the tests of the current release if this has been requested,
+	// without performing any other release action.
+	if v2.ShouldHandleTestRequest(req.Object) {
+		if next := r.testRequestForState(ctx, req, state); next != nil {
+			return next, nil
+		}
+	}
 
 	switch state.Status {
 	case ReleaseStatusInSync:
-		log.Info("release in-sync with desired state")
+		if state.HeldBack != "" {
+			log.Info(msgWithReason("release pinned, holding back upgrade", state.HeldBack))
+		} else {
+			log.Info("release in-sync with desired state")
+		}
 
 		// Remove all history up to the previous release action.
 		// We need to continue to hold on to the previous release result
@@ -326,9 +741,13 @@ func (r *AtomicRelease) actionForState(ctx context.Context, req *Request, state
 		}
 		req.Object.Status.History.Truncate(ignoreFailures)
 
+		// The release matches the desired state, any previous rollback on
+		// request no longer needs to be held on to.
+		req.Object.Status.ManualRollbackActive = false
+
 		if forceRequested {
 			log.Info(msgWithReason("forcing upgrade for in-sync release", "force requested through annotation"))
-			return NewUpgrade(r.configFactory, r.eventRecorder), nil
+			return NewForcedUpgrade(r.configFactory, r.eventRecorder, req.Object.Status.LastHandledForceAt), nil
 		}
 
 		// Since the release is in-sync, remove any remediated condition if
@@ -360,9 +779,16 @@ func (r *AtomicRelease) actionForState(ctx context.Context, req *Request, state
 		}
 
 		return NewInstall(r.configFactory, r.eventRecorder), nil
+	case ReleaseStatusAdoptable:
+		log.Info(msgWithReason("release not managed by controller", state.Reason))
+		return NewAdopt(r.configFactory, r.eventRecorder), nil
 	case ReleaseStatusUnmanaged:
 		log.Info(msgWithReason("release not managed by controller", state.Reason))
 
+		if mustHoldBackUpgrade(ctx, state, forceRequested) {
+			return nil, nil
+		}
+
 		// Clear the history as we can no longer rely on it.
 		req.Object.Status.ClearHistory()
 
@@ -370,6 +796,18 @@ func (r *AtomicRelease) actionForState(ctx context.Context, req *Request, state
 	case ReleaseStatusOutOfSync:
 		log.Info(msgWithReason("release out-of-sync with desired state", state.Reason))
 
+		// Hold on to a release which has been rolled back on request, until
+		// the desired state changes or an upgrade is forced.
+		if req.Object.Status.ManualRollbackActive {
+			if !forceRequested {
+				log.Info(msgWithReason("not upgrading release", "release has been rolled back on request"))
+				return nil, nil
+			}
+			log.Info(msgWithReason("forcing upgrade for release rolled back on request", "force requested through annotation"))
+			req.Object.Status.ManualRollbackActive = false
+			return NewUpgrade(r.configFactory, r.eventRecorder), nil
+		}
+
 		if req.Object.GetUpgrade().GetRemediation().RetriesExhausted(req.Object) {
 			if forceRequested {
 				log.Info(msgWithReason("forcing upgrade while out of retries", "force requested through annotation"))
@@ -379,32 +817,29 @@ func (r *AtomicRelease) actionForState(ctx context.Context, req *Request, state
 			return nil, fmt.Errorf("%w: cannot upgrade release", ErrExceededMaxRetries)
 		}
 
-		return NewUpgrade(r.configFactory, r.eventRecorder), nil
-	case ReleaseStatusDrifted:
-		log.Info(msgWithReason("detected changes in cluster state", diff.SummarizeDiffSetBrief(state.Diff)))
-		for _, change := range state.Diff {
-			switch change.Type {
-			case jsondiff.DiffTypeCreate:
-				log.V(logger.DebugLevel).Info("resource deleted",
-					"resource", diff.ResourceName(change.DesiredObject))
-			case jsondiff.DiffTypeUpdate:
-				patch := change.Patch
-				if change.DesiredObject.GetObjectKind().GroupVersionKind().Kind == "Secret" {
-					patch = jsondiff.MaskSecretPatchData(change.Patch)
-				}
-				log.V(logger.DebugLevel).Info("resource modified",
-					"resource", diff.ResourceName(change.DesiredObject),
-					"patch", patch)
-			}
+		if err := checkDowngrade(req); err != nil {
+			return nil, err
 		}
 
-		r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, "DriftDetected",
-			"Cluster state of release %s has drifted from the desired state:\n%s",
-			req.Object.Status.History.Latest().FullReleaseName(), diff.SummarizeDiffSet(state.Diff),
-		)
+		return NewUpgrade(r.configFactory, r.eventRecorder), nil
+	case ReleaseStatusDrifted:
+		r.reportDrift(ctx, req, state)
 
 		if req.Object.GetDriftDetection().GetMode() == v2.DriftDetectionEnabled {
-			return NewCorrectClusterDrift(r.configFactory, r.eventRecorder, state.Diff, kube.ManagedFieldsManager), nil
+			if remaining := driftCoolDownRemaining(req.Object, time.Now()); remaining > 0 {
+				log.Info(msgWithReason("not correcting drift",
+					fmt.Sprintf("release is in cool-down for another %s", remaining.Round(time.Second))))
+				return nil, nil
+			}
+			changes, err := diff.SelectDiffSet(state.Diff, req.Object.GetDriftDetection().Selector)
+			if err != nil {
+				return nil, fmt.Errorf("failed to select drift to correct: %w", err)
+			}
+			if len(changes) == 0 {
+				log.Info(msgWithReason("not correcting drift", "no drifted resources match the selector"))
+				return nil, nil
+			}
+			return NewCorrectClusterDrift(r.configFactory, r.eventRecorder, changes, kube.ManagedFieldsManager), nil
 		}
 
 		return nil, nil
@@ -435,9 +870,22 @@ func (r *AtomicRelease) actionForState(ctx context.Context, req *Request, state
 		// upgrade the release to see if that fixes the problem.
 		if remediation == nil {
 			log.V(logger.DebugLevel).Info("no active remediation strategy")
+			if mustHoldBackUpgrade(ctx, state, forceRequested) {
+				return nil, nil
+			}
+			if err := checkDowngrade(req); err != nil {
+				return nil, err
+			}
 			return NewUpgrade(r.configFactory, r.eventRecorder), nil
 		}
 
+		// A failure within the retry delay of the remediation strategy is
+		// not counted. Rather than retrying on every reconciliation, the
+		// release is retried once the delay has passed.
+		if !forceRequested && RetryDelayRemaining(req.Object, time.Now()) > 0 {
+			return nil, ErrWithinRetryDelay
+		}
+
 		// If there is no failure count, the conditions under which the failure
 		// occurred must have changed.
 		// Attempt to upgrade the release to see if the problem is resolved.
@@ -445,16 +893,47 @@ func (r *AtomicRelease) actionForState(ctx context.Context, req *Request, state
 		// attempted again.
 		if remediation.GetFailureCount(req.Object) <= 0 {
 			log.Info("release conditions have changed since last failure")
+			if mustHoldBackUpgrade(ctx, state, forceRequested) {
+				return nil, nil
+			}
+			if err := checkDowngrade(req); err != nil {
+				return nil, err
+			}
 			return NewUpgrade(r.configFactory, r.eventRecorder), nil
 		}
 
 		// If the force annotation is set, we can attempt to upgrade the release
-		// without any further checks.
+		// without any further checks. Like a forced upgrade of an out-of-sync
+		// release, this does not block a downgrade.
 		if forceRequested {
 			log.Info(msgWithReason("forcing upgrade for failed release", "force requested through annotation"))
 			return NewUpgrade(r.configFactory, r.eventRecorder), nil
 		}
 
+		// The resources of the failed release must be kept for inspection,
+		// which rules out any remediation or retry.
+		if remediation.MustKeepFailedResources() {
+			return nil, ErrFailedResourcesKept
+		}
+
+		// Only a failure of a class which is configured to trigger
+		// remediation is remediated. Any other failure is retried with an
+		// upgrade, until the retries are exhausted.
+		if class := req.Object.Status.LastFailureClass; !remediation.MustRemediateFailureClass(class) {
+			if remediation.RetriesExhausted(req.Object) {
+				return nil, fmt.Errorf("%w: %s failure does not trigger remediation", ErrExceededMaxRetries, class)
+			}
+			log.Info(msgWithReason("retrying upgrade of failed release without remediation",
+				fmt.Sprintf("%s failure does not trigger remediation", class)))
+			if mustHoldBackUpgrade(ctx, state, forceRequested) {
+				return nil, nil
+			}
+			if err := checkDowngrade(req); err != nil {
+				return nil, err
+			}
+			return NewUpgrade(r.configFactory, r.eventRecorder), nil
+		}
+
 		// We have exhausted the number of retries for the remediation
 		// strategy.
 		if remediation.RetriesExhausted(req.Object) && !remediation.MustRemediateLastFailure() {
@@ -467,6 +946,12 @@ func (r *AtomicRelease) actionForState(ctx context.Context, req *Request, state
 
 		switch remediation.GetStrategy() {
 		case v2.RollbackRemediationStrategy:
+			// An explicit rollback target version is verified by the
+			// rollback itself, as it may not be part of the history.
+			if req.Object.GetRollback().ToVersion > 0 {
+				return NewRollbackRemediation(r.configFactory, r.eventRecorder), nil
+			}
+
 			// Verify the previous release is still in storage and unmodified
 			// before instructing to roll back to it.
 			prev := req.Object.Status.History.Previous(remediation.MustIgnoreTestFailures(req.Object.GetTest().IgnoreFailures))
@@ -481,6 +966,9 @@ func (r *AtomicRelease) actionForState(ctx context.Context, req *Request, state
 					// If the rollback target is in any way corrupt,
 					// the most correct remediation is to reattempt the upgrade.
 					log.Info(msgWithReason("unable to verify previous release in storage to roll back to", err.Error()))
+					if mustHoldBackUpgrade(ctx, state, forceRequested) {
+						return nil, nil
+					}
 					return NewUpgrade(r.configFactory, r.eventRecorder), nil
 				}
 
@@ -498,6 +986,544 @@ func (r *AtomicRelease) actionForState(ctx context.Context, req *Request, state
 	}
 }
 
+// mustHoldBackUpgrade returns true if the upgrade of the release in the
+// given state must be held back, as the release is pinned and an upgrade has
+// not been forced.
+func mustHoldBackUpgrade(ctx context.Context, state ReleaseState, forceRequested bool) bool {
+	if state.HeldBack == "" || forceRequested {
+		return false
+	}
+	ctrl.LoggerFrom(ctx).Info(msgWithReason("release pinned, holding back upgrade", state.HeldBack))
+	return true
+}
+
+// reportDrift logs the changes of the given drifted state, and emits a
+// warning event summarizing them.
+func (r *AtomicRelease) reportDrift(ctx context.Context, req *Request, state ReleaseState) {
+	log := ctrl.LoggerFrom(ctx)
+
+	log.Info(msgWithReason("detected changes in cluster state", diff.SummarizeDiffSetBrief(state.Diff)))
+	for _, change := range state.Diff {
+		switch change.Type {
+		case jsondiff.DiffTypeCreate:
+			log.V(logger.DebugLevel).Info("resource deleted",
+				"resource", diff.ResourceName(change.DesiredObject))
+		case jsondiff.DiffTypeUpdate:
+			patch := change.Patch
+			if change.DesiredObject.GetObjectKind().GroupVersionKind().Kind == "Secret" {
+				patch = jsondiff.MaskSecretPatchData(change.Patch)
+			}
+			log.V(logger.DebugLevel).Info("resource modified",
+				"resource", diff.ResourceName(change.DesiredObject),
+				"patch", patch)
+		}
+	}
+
+	r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, "DriftDetected",
+		"Cluster state of release %s has drifted from the desired state:\n%s",
+		req.Object.Status.History.Latest().FullReleaseName(), diff.SummarizeDiffSet(state.Diff),
+	)
+}
+
+// reportFailedResourcesKept marks the Remediated condition as False to
+// record that the failed release is not remediated, and emits a warning event
+// the first time this is observed for the failed release.
+func (r *AtomicRelease) reportFailedResourcesKept(req *Request) {
+	cur := req.Object.Status.History.Latest()
+	if cur == nil {
+		return
+	}
+
+	msg := fmt.Sprintf("Resources of failed release %s with chart %s@%s are kept for inspection: remediation is disabled",
+		cur.FullReleaseName(), cur.ChartName, cur.ChartVersion)
+	if conditions.HasAnyReason(req.Object, v2.RemediatedCondition, v2.FailedResourcesKeptReason) &&
+		conditions.GetMessage(req.Object, v2.RemediatedCondition) == msg {
+		return
+	}
+
+	conditions.MarkFalse(req.Object, v2.RemediatedCondition, v2.FailedResourcesKeptReason, "%s", msg)
+	r.eventRecorder.AnnotatedEventf(req.Object, eventMeta(cur.ChartVersion, cur.ConfigDigest, addAppVersion(cur.AppVersion)),
+		corev1.EventTypeWarning, v2.FailedResourcesKeptReason, msg)
+}
+
+// recordHistoryLimit marks the HistoryLimitReached condition as True when the
+// release history has reached the MaxHistory, and the release a rollback
+// would target is pruned by the next Helm release action. A warning event is
+// emitted when the condition is first set. The condition is removed once the
+// history shrinks, or the MaxHistory is raised.
+func (r *AtomicRelease) recordHistoryLimit(req *Request) error {
+	msg, err := historyLimitMessage(r.configFactory.Build(nil), req.Object)
+	if err != nil {
+		return err
+	}
+	if msg == "" {
+		conditions.Delete(req.Object, v2.HistoryLimitReachedCondition)
+		return nil
+	}
+
+	if !conditions.IsTrue(req.Object, v2.HistoryLimitReachedCondition) ||
+		conditions.GetMessage(req.Object, v2.HistoryLimitReachedCondition) != msg {
+		cur := req.Object.Status.History.Latest()
+		r.eventRecorder.AnnotatedEventf(req.Object, eventMeta(cur.ChartVersion, cur.ConfigDigest, addAppVersion(cur.AppVersion)),
+			corev1.EventTypeWarning, v2.MaxHistoryReachedReason, msg)
+	}
+	conditions.MarkTrue(req.Object, v2.HistoryLimitReachedCondition, v2.MaxHistoryReachedReason, "%s", msg)
+	return nil
+}
+
+// manualRollbackForState returns a ManualRollback reconciler if the release
+// in the given state can be rolled back to a previous release on request. If
+// it can not, a warning event is emitted explaining why, and nil is returned
+// to continue with the action for the state.
+func (r *AtomicRelease) manualRollbackForState(ctx context.Context, req *Request, state ReleaseState) ActionReconciler {
+	log := ctrl.LoggerFrom(ctx)
+
+	var reason string
+	switch state.Status {
+	case ReleaseStatusAbsent, ReleaseStatusUnmanaged:
+		reason = fmt.Sprintf("%s: %s", ErrNoLatest.Error(), state.Reason)
+	default:
+		prev := req.Object.Status.History.Previous(true)
+		if prev == nil {
+			reason = fmt.Sprintf("%s: no previous release in history", ErrMissingRollbackTarget.Error())
+			break
+		}
+		if _, err := action.VerifySnapshot(r.configFactory.Build(nil), prev); err != nil {
+			reason = fmt.Sprintf("cannot verify previous release %s to roll back to: %s", prev.FullReleaseName(), err)
+			break
+		}
+		log.Info(msgWithReason("rolling back to previous release", "rollback requested through annotation"))
+		return NewManualRollback(r.configFactory, r.eventRecorder)
+	}
+
+	log.Info(msgWithReason("unable to roll back on request", reason))
+	r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.ManualRollbackFailedReason,
+		"Unable to roll back on request: %s", reason)
+	return nil
+}
+
+// restoreForState returns a Restore reconciler if the release in the given
+// state can be restored from a backup on request. If it can not, a warning
+// event is emitted explaining why, and nil is returned to continue with the
+// action for the state.
+func (r *AtomicRelease) restoreForState(ctx context.Context, req *Request, state ReleaseState) ActionReconciler {
+	log := ctrl.LoggerFrom(ctx)
+
+	var reason string
+	switch state.Status {
+	case ReleaseStatusAbsent, ReleaseStatusUnmanaged:
+		reason = fmt.Sprintf("%s: %s", ErrNoLatest.Error(), state.Reason)
+	default:
+		if restoreTarget(req.Object.Status.History) == nil {
+			reason = fmt.Sprintf("%s: no backup of a previous release in history", ErrMissingRollbackTarget.Error())
+			break
+		}
+		log.Info(msgWithReason("restoring release from backup", "restore requested through annotation"))
+		return NewRestore(r.configFactory, r.eventRecorder)
+	}
+
+	log.Info(msgWithReason("unable to restore on request", reason))
+	r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.RestoreFailedReason,
+		"Unable to restore on request: %s", reason)
+	return nil
+}
+
+// testRequestForState returns a Test reconciler if the tests of the release in
+// the given state can be run on request. This is the case when tests are
+// enabled, and the release is deployed and either in-sync or failed due to a
+// test failure. If they can not, a warning event is emitted explaining why,
+// and nil is returned to continue with the action for the state.
+func (r *AtomicRelease) testRequestForState(ctx context.Context, req *Request, state ReleaseState) ActionReconciler {
+	log := ctrl.LoggerFrom(ctx)
+
+	var reason string
+	cur := req.Object.Status.History.Latest()
+	switch {
+	case !req.Object.GetTest().Enable:
+		reason = "tests are not enabled"
+	case state.Status != ReleaseStatusInSync && state.Status != ReleaseStatusFailed:
+		reason = fmt.Sprintf("release state is %s", state.Status)
+		if state.Reason != "" {
+			reason = fmt.Sprintf("%s: %s", reason, state.Reason)
+		}
+	case cur == nil || cur.Status != helmrelease.StatusDeployed.String():
+		reason = "current release is not deployed"
+	default:
+		log.Info(msgWithReason("running tests of current release", "test requested through annotation"))
+		return NewTest(r.configFactory, r.eventRecorder)
+	}
+
+	log.Info(msgWithReason("unable to run tests on request", reason))
+	r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.TestRequestFailedReason,
+		"Unable to run tests on request: %s", reason)
+	return nil
+}
+
+// mustValidateValuesSchema returns true if the values must be validated
+// against the JSON schema of the chart before running the given action. This
+// is the case for an install or upgrade, unless the schema validation is
+// disabled for the action.
+func mustValidateValuesSchema(next ActionReconciler, obj *v2.HelmRelease) bool {
+	switch next.(type) {
+	case *Install:
+		return !obj.GetInstall().DisableSchemaValidation
+	case *Upgrade:
+		return !obj.GetUpgrade().DisableSchemaValidation
+	default:
+		return false
+	}
+}
+
+// assessReadiness assesses the health of the resources of the latest release
+// against the v2.Readiness configuration of the Request.Object, and records
+// the percentage of healthy resources in the status. When the threshold is
+// not met, or a critical resource is unhealthy, the object is marked with
+// Ready=False and ErrMustRequeue is returned.
+func (r *AtomicRelease) assessReadiness(ctx context.Context, req *Request) error {
+	cfg := r.configFactory.Build(nil)
+	readiness := req.Object.Spec.Readiness
+
+	rls, err := action.VerifySnapshot(cfg, req.Object.Status.History.Latest())
+	if err != nil {
+		return fmt.Errorf("cannot verify release to assess readiness: %w", err)
+	}
+
+	health, err := action.AssessHealth(ctx, cfg, rls, readiness.Critical...)
+	if err != nil {
+		conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.HealthCheckFailedReason,
+			"Could not assess health of release resources: %s", err)
+		return err
+	}
+
+	percentage := health.Percentage()
+	req.Object.Status.HealthyPercentage = &percentage
+
+	if !health.Meets(readiness.Threshold) {
+		conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.HealthCheckFailedReason,
+			"Readiness threshold of %d%% not met: %s", readiness.Threshold, health.String())
+		return ErrMustRequeue
+	}
+	return nil
+}
+
+// assessHealthChecks checks the health of the resources of the latest
+// release as configured by the v2.HealthChecks of the Request.Object, and
+// records the result in the status. While the resources are not healthy
+// within the timeout of the health checks, the object is marked with
+// Ready=Unknown and ErrMustRequeue is returned. Once the timeout has passed,
+// the object is marked with Released=False and v2.HealthCheckFailedReason,
+// until the resources become healthy.
+func (r *AtomicRelease) assessHealthChecks(ctx context.Context, req *Request) error {
+	obj := req.Object
+	cur := obj.Status.History.Latest()
+	if cur == nil || cur.Status != helmrelease.StatusDeployed.String() {
+		return nil
+	}
+	healthCheckFailed := conditions.HasAnyReason(obj, v2.ReleasedCondition, v2.HealthCheckFailedReason)
+	if !conditions.IsTrue(obj, v2.ReleasedCondition) && !healthCheckFailed {
+		return nil
+	}
+
+	cfg := r.configFactory.Build(nil)
+	rls, err := action.VerifySnapshot(cfg, cur)
+	if err != nil {
+		return fmt.Errorf("cannot verify release to check health: %w", err)
+	}
+
+	var selectors []kustomize.Selector
+	if !obj.Spec.HealthChecks.All {
+		selectors = obj.Spec.HealthChecks.Resources
+	}
+	resources, err := action.CheckHealth(ctx, cfg, rls, selectors...)
+	if err != nil {
+		conditions.MarkFalse(obj, meta.ReadyCondition, v2.HealthCheckFailedReason,
+			"Could not check health of release resources: %s", err)
+		return err
+	}
+	health := newReleaseHealth(cur.Version, resources, time.Now())
+	obj.Status.LastAppliedHealth = health
+
+	if health.Healthy {
+		// Restore the Released condition of a release which became healthy
+		// after its health check failed.
+		if healthCheckFailed {
+			reason, msg := v2.UpgradeSucceededReason, fmt.Sprintf(fmtUpgradeSuccess, cur.FullReleaseName(), cur.VersionedChartName())
+			if obj.Status.LastAttemptedReleaseAction == v2.ReleaseActionInstall {
+				reason, msg = v2.InstallSucceededReason, fmt.Sprintf(fmtInstallSuccess, cur.FullReleaseName(), cur.VersionedChartName())
+			}
+			conditions.MarkTrue(obj, v2.ReleasedCondition, reason, "%s", msg)
+			summarize(req)
+		}
+		return nil
+	}
+
+	timeout := obj.Spec.HealthChecks.GetTimeout(obj.GetTimeout()).Duration
+	if remaining := timeout - time.Since(cur.LastDeployed.Time); remaining > 0 && !healthCheckFailed {
+		conditions.MarkUnknown(obj, meta.ReadyCondition, meta.ProgressingReason,
+			"Waiting for resources to become healthy (%s remaining): %s",
+			remaining.Round(time.Second).String(), summarizeUnhealthy(health))
+		return ErrMustRequeue
+	}
+
+	msg := fmt.Sprintf("Health check of release %s with chart %s failed after %s: %s",
+		cur.FullReleaseName(), cur.VersionedChartName(), timeout.String(), summarizeUnhealthy(health))
+	if !healthCheckFailed {
+		r.eventRecorder.Eventf(obj, corev1.EventTypeWarning, v2.HealthCheckFailedReason, "%s", msg)
+	}
+	conditions.MarkFalse(obj, v2.ReleasedCondition, v2.HealthCheckFailedReason, "%s", msg)
+	summarize(req)
+	return nil
+}
+
+// newReleaseHealth returns a v2.ReleaseHealth for the given release version
+// from the given resource statuses.
+func newReleaseHealth(version int, resources []action.ResourceStatus, now time.Time) *v2.ReleaseHealth {
+	health := &v2.ReleaseHealth{
+		Version:     version,
+		LastChecked: metav1.NewTime(now),
+		Healthy:     true,
+	}
+	for _, res := range resources {
+		health.Healthy = health.Healthy && res.Healthy()
+		health.Resources = append(health.Resources, v2.ResourceHealthStatus{
+			Name:    res.Name,
+			Status:  res.Status.String(),
+			Message: res.Message,
+		})
+	}
+	return health
+}
+
+// summarizeUnhealthy returns a message listing the resources in the given
+// v2.ReleaseHealth which are not healthy.
+func summarizeUnhealthy(health *v2.ReleaseHealth) string {
+	var unhealthy []string
+	for _, res := range health.Resources {
+		if res.Status != status.CurrentStatus.String() {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s (%s)", res.Name, res.Status))
+		}
+	}
+	return fmt.Sprintf("%d/%d resource(s) not healthy: %s", len(unhealthy), len(health.Resources), strings.Join(unhealthy, ", "))
+}
+
+// mustAssessInstallReadiness returns true if the readiness of the resources
+// of the latest release must be assessed by the controller, because it was
+// installed with a readiness grace period and its readiness has not been
+// confirmed yet.
+func mustAssessInstallReadiness(req *Request, wasReady bool, previous ReconcilerTypeSet) bool {
+	obj := req.Object
+	if obj.GetInstall().ReadinessGracePeriod == nil || obj.UsesReadinessThreshold() ||
+		defaults.MustDisableWait(obj, obj.GetInstall().DisableWait) {
+		return false
+	}
+	if obj.Status.LastAttemptedReleaseAction != v2.ReleaseActionInstall {
+		return false
+	}
+	if cur := obj.Status.History.Latest(); cur == nil || cur.Status != helmrelease.StatusDeployed.String() {
+		return false
+	}
+	return !wasReady || previous.Contains(ReconcilerTypeRelease)
+}
+
+// assessInstallReadiness assesses the readiness of the resources of the
+// latest release, which was installed with a readiness grace period. While
+// the resources are not ready within the grace period, the object is marked
+// with Ready=Unknown and ErrMustRequeue is returned. Once the grace period
+// has passed, the release is marked as failed in the Helm storage, as Helm
+// would have done when waiting for the resources timed out, and recorded on
+// the object, after which the configured install remediation applies.
+func (r *AtomicRelease) assessInstallReadiness(ctx context.Context, req *Request) error {
+	cur := req.Object.Status.History.Latest()
+	cfg := r.configFactory.Build(nil)
+
+	rls, err := action.VerifySnapshot(cfg, cur)
+	if err != nil {
+		return fmt.Errorf("cannot verify release to assess readiness: %w", err)
+	}
+
+	health, err := action.AssessHealth(ctx, cfg, rls)
+	if err != nil {
+		conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.HealthCheckFailedReason,
+			"Could not assess health of release resources: %s", err)
+		return err
+	}
+	if health.Meets(100) {
+		return nil
+	}
+
+	gracePeriod := req.Object.GetInstall().ReadinessGracePeriod.Duration
+	if remaining := gracePeriod - time.Since(cur.FirstDeployed.Time); remaining > 0 {
+		conditions.MarkUnknown(req.Object, meta.ReadyCondition, meta.ProgressingReason,
+			"Waiting for resources to become ready within grace period (%s remaining): %s",
+			remaining.Round(time.Second).String(), health.String())
+		return ErrMustRequeue
+	}
+
+	// The grace period has passed, mark the release as failed.
+	msg := fmt.Sprintf("Helm install of release %s with chart %s failed: resources not ready after readiness grace period of %s: %s",
+		cur.FullReleaseName(), cur.VersionedChartName(), gracePeriod.String(), health.String())
+	rls.SetStatus(helmrelease.StatusFailed, msg)
+	if err = cfg.Releases.Update(rls); err != nil {
+		return fmt.Errorf("failed to mark release as failed: %w", err)
+	}
+	recordOnObject(req.Object, rls)
+
+	req.Object.Status.Failures++
+	req.Object.GetInstall().GetRemediation().IncrementFailureCount(req.Object)
+	conditions.MarkFalse(req.Object, v2.ReleasedCondition, v2.InstallFailedReason, "%s", msg)
+	r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.InstallFailedReason, "%s", msg)
+	summarize(req)
+	return ErrMustRequeue
+}
+
+// lazyRenderRelease returns a function which renders the Helm release for
+// the Request on its first call, and returns the result of that render on
+// any subsequent call.
+func (r *AtomicRelease) lazyRenderRelease(ctx context.Context, req *Request) func() (*helmrelease.Release, error) {
+	var (
+		rendered *helmrelease.Release
+		err      error
+		done     bool
+	)
+	return func() (*helmrelease.Release, error) {
+		if !done {
+			rendered, err = action.RenderRelease(ctx, r.configFactory.Build(nil), req.Object, req.Chart, req.Values)
+			if err != nil {
+				err = fmt.Errorf("failed to render release: %w", err)
+			}
+			done = true
+		}
+		return rendered, err
+	}
+}
+
+// preflightResourceQuota checks the estimated resource usage of the Helm
+// release rendered for the Request against the ResourceQuotas in the target
+// namespace. The usage of the latest release, if any, is taken into account
+// as it is already part of the quota usage.
+func (r *AtomicRelease) preflightResourceQuota(ctx context.Context, req *Request, render func() (*helmrelease.Release, error)) error {
+	ns := req.Object.GetReleaseNamespace()
+
+	rendered, err := render()
+	if err != nil {
+		return err
+	}
+	desired, err := action.EstimateResourceUsage(rendered.Manifest, ns)
+	if err != nil {
+		return err
+	}
+
+	cfg := r.configFactory.Build(nil)
+	var current corev1.ResourceList
+	if cur := req.Object.Status.History.Latest(); cur != nil {
+		if rls, err := action.VerifySnapshot(cfg, cur); err == nil {
+			if current, err = action.EstimateResourceUsage(rls.Manifest, ns); err != nil {
+				return err
+			}
+		}
+	}
+	return action.CheckResourceQuota(ctx, cfg, ns, desired, current)
+}
+
+// preflightRequiredLabels returns an error listing the resources of the
+// Helm release rendered for the Request which do not have all the labels
+// required by the Request.Object.
+func (r *AtomicRelease) preflightRequiredLabels(_ context.Context, req *Request, render func() (*helmrelease.Release, error)) error {
+	rendered, err := render()
+	if err != nil {
+		return err
+	}
+	violations, err := postrender.CheckRequiredLabels(rendered.Manifest, req.Object.Spec.RequiredLabels.Keys)
+	if err != nil {
+		return err
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("%d resource(s) missing required labels: %s", len(violations), strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// preflightConflicts returns an error if any of the resources of the Helm
+// release rendered for the Request is managed by another Helm release.
+func (r *AtomicRelease) preflightConflicts(ctx context.Context, req *Request, render func() (*helmrelease.Release, error)) error {
+	rendered, err := render()
+	if err != nil {
+		return err
+	}
+	return action.CheckResourceConflicts(ctx, r.configFactory.Build(nil), rendered.Manifest,
+		release.ShortenName(req.Object.GetReleaseName()), req.Object.GetReleaseNamespace())
+}
+
+// preflightCapabilityProfiles renders the Helm release for the Request
+// against each of the configured capability profiles, and records the
+// results in the Status.CapabilityProfiles of the Request.Object. A warning
+// event is emitted for the profiles which failed to render.
+func (r *AtomicRelease) preflightCapabilityProfiles(ctx context.Context, req *Request) {
+	profiles := req.Object.GetPreflight().CapabilityProfiles
+	results := make([]v2.CapabilityProfileResult, 0, len(profiles))
+
+	var failed []string
+	for _, p := range profiles {
+		result := v2.CapabilityProfileResult{Name: p.Name, Passed: true}
+		profile, err := defaults.ResolveCapabilityProfile(p)
+		if err == nil {
+			_, err = action.RenderReleaseForProfile(ctx, r.configFactory.Build(nil), req.Object, req.Chart, req.Values, profile)
+		}
+		if err != nil {
+			result.Passed = false
+			result.Message = strings.TrimSpace(err.Error())
+			failed = append(failed, fmt.Sprintf("%s: %s", p.Name, result.Message))
+		}
+		results = append(results, result)
+	}
+	req.Object.Status.CapabilityProfiles = results
+
+	if len(failed) > 0 {
+		r.eventRecorder.Eventf(req.Object, corev1.EventTypeWarning, v2.CapabilityProfileFailedReason,
+			"Release failed to render against %d/%d capability profiles: %s",
+			len(failed), len(profiles), strings.Join(failed, "; "))
+	}
+}
+
+// preflightHooks records the hooks of the Helm release rendered for the
+// Request in the Status.Hooks of the Request.Object when enabled. A failure
+// to render is only logged, as it will surface in the result of the Helm
+// action.
+func (r *AtomicRelease) preflightHooks(ctx context.Context, req *Request, render func() (*helmrelease.Release, error)) {
+	if !req.Object.GetPreflight().Hooks {
+		req.Object.Status.Hooks = nil
+		return
+	}
+
+	rendered, err := render()
+	if err != nil {
+		ctrl.LoggerFrom(ctx).V(logger.DebugLevel).Info(fmt.Sprintf("failed to render hooks: %s", err))
+		return
+	}
+	req.Object.Status.Hooks = &v2.ChartHooks{
+		ChartName:    req.Chart.Metadata.Name,
+		ChartVersion: req.Chart.Metadata.Version,
+		Items:        release.HooksFromRelease(rendered),
+	}
+}
+
+// hookPayload returns a hook.Payload for the given phase and action.
+func hookPayload(phase hook.Phase, next ActionReconciler, req *Request) hook.Payload {
+	payload := hook.Payload{
+		Phase:            phase,
+		Action:           next.Name(),
+		Name:             req.Object.GetName(),
+		Namespace:        req.Object.GetNamespace(),
+		ReleaseName:      release.ShortenName(req.Object.GetReleaseName()),
+		ReleaseNamespace: req.Object.GetReleaseNamespace(),
+	}
+	if req.Chart != nil && req.Chart.Metadata != nil {
+		payload.ChartName = req.Chart.Metadata.Name
+		payload.ChartVersion = req.Chart.Metadata.Version
+	}
+	return payload
+}
+
 func (r *AtomicRelease) Name() string {
 	return "atomic-release"
 }
@@ -539,6 +1565,54 @@ func timeoutForAction(action ActionReconciler, obj *v2.HelmRelease) time.Duratio
 	}
 }
 
+// driftCoolDownRemaining returns the remaining duration of the drift
+// correction cool-down at the given time, which starts when the latest
+// release was deployed. It returns zero if no cool-down is configured, or
+// it has passed.
+func driftCoolDownRemaining(obj *v2.HelmRelease, now time.Time) time.Duration {
+	coolDown := obj.GetDriftDetection().CoolDown
+	cur := obj.Status.History.Latest()
+	if coolDown == nil || cur == nil || cur.LastDeployed.IsZero() {
+		return 0
+	}
+	if remaining := cur.LastDeployed.Add(coolDown.Duration).Sub(now); remaining > 0 {
+		return remaining
+	}
+	return 0
+}
+
+// checkDowngrade returns an error of type ErrDowngradeBlocked if the chart
+// version of the Request is lower than the chart version of the latest
+// release, and downgrades are not allowed. Charts of which the source is
+// pinned to a digest are not checked.
+func checkDowngrade(req *Request) error {
+	if req.Object.Spec.AllowDowngrade || req.ChartPinnedByDigest {
+		return nil
+	}
+
+	cur := req.Object.Status.History.Latest()
+	if cur == nil || req.Chart == nil || req.Chart.Metadata == nil {
+		return nil
+	}
+
+	// Versions which can not be parsed can not be compared, and are
+	// therefore not considered a downgrade.
+	curVer, err := semver.NewVersion(cur.ChartVersion)
+	if err != nil {
+		return nil
+	}
+	newVer, err := semver.NewVersion(req.Chart.Metadata.Version)
+	if err != nil {
+		return nil
+	}
+
+	if newVer.LessThan(curVer) {
+		return fmt.Errorf("%w: chart version %s is lower than version %s of release %s, set allowDowngrade to upgrade",
+			ErrDowngradeBlocked, newVer.Original(), curVer.Original(), cur.FullReleaseName())
+	}
+	return nil
+}
+
 // replaceCondition replaces existing target condition with replacement
 // condition, if present, for the given values, retaining the
 // LastTransitionTime.
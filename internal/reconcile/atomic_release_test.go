@@ -36,6 +36,7 @@ import (
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
+	"github.com/fluxcd/cli-utils/pkg/kstatus/status"
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/fluxcd/pkg/runtime/conditions"
 	"github.com/fluxcd/pkg/runtime/patch"
@@ -210,6 +211,214 @@ func TestAtomicRelease_Reconcile(t *testing.T) {
 		g.Expect(err).ToNot(HaveOccurred())
 		g.Expect(endState).To(Equal(ReleaseState{Status: ReleaseStatusInSync}))
 	})
+
+	t.Run("records timeline events", func(t *testing.T) {
+		g := NewWithT(t)
+
+		namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+		g.Expect(err).NotTo(HaveOccurred())
+		t.Cleanup(func() {
+			_ = testEnv.Delete(context.TODO(), namedNS)
+		})
+		releaseNamespace := namedNS.Name
+
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      mockReleaseName,
+				Namespace: releaseNamespace,
+			},
+			Spec: v2.HelmReleaseSpec{
+				ReleaseName:     mockReleaseName,
+				TargetNamespace: releaseNamespace,
+				Test: &v2.Test{
+					Enable: true,
+				},
+				StorageNamespace: releaseNamespace,
+				Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+			},
+		}
+
+		getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+		g.Expect(err).ToNot(HaveOccurred())
+
+		cfg, err := action.NewConfigFactory(getter,
+			action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+		)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		client := fake.NewClientBuilder().
+			WithScheme(testEnv.Scheme()).
+			WithObjects(obj).
+			WithStatusSubresource(&v2.HelmRelease{}).
+			Build()
+		patchHelper := patch.NewSerialPatcher(obj, client)
+		recorder := testutil.NewFakeRecorder(10, false)
+		timeline := testutil.NewFakeRecorder(10, false)
+
+		req := &Request{
+			Object: obj,
+			Chart:  testutil.BuildChart(testutil.ChartWithTestHook()),
+			Values: nil,
+		}
+		g.Expect(NewAtomicRelease(patchHelper, cfg, recorder, testFieldManager,
+			WithTimelineEvents(timeline)).Reconcile(context.TODO(), req)).ToNot(HaveOccurred())
+
+		reason := func(e corev1.Event) string { return e.Reason }
+		events := timeline.GetEvents()
+		g.Expect(events).To(HaveExactElements(
+			WithTransform(reason, Equal("InstallStarted")),
+			WithTransform(reason, Equal("InstallFinished")),
+			WithTransform(reason, Equal("TestStarted")),
+			WithTransform(reason, Equal("TestFinished")),
+		))
+		for _, e := range events {
+			g.Expect(e.Type).To(Equal(corev1.EventTypeNormal))
+			g.Expect(e.Message).To(ContainSubstring("for release %s/%s with chart hello@0.1.0", releaseNamespace, mockReleaseName))
+		}
+
+		// The timeline events complement the events of the actions.
+		g.Expect(recorder.GetEvents()).To(ContainElements(
+			WithTransform(reason, Equal(v2.InstallSucceededReason)),
+			WithTransform(reason, Equal(v2.TestSucceededReason)),
+		))
+	})
+
+	t.Run("counts stable reconciliations", func(t *testing.T) {
+		g := NewWithT(t)
+
+		namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+		g.Expect(err).NotTo(HaveOccurred())
+		t.Cleanup(func() {
+			_ = testEnv.Delete(context.TODO(), namedNS)
+		})
+		releaseNamespace := namedNS.Name
+
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      mockReleaseName,
+				Namespace: releaseNamespace,
+			},
+			Spec: v2.HelmReleaseSpec{
+				ReleaseName:      mockReleaseName,
+				TargetNamespace:  releaseNamespace,
+				StorageNamespace: releaseNamespace,
+				Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+				StableInterval:   &v2.StableInterval{Interval: metav1.Duration{Duration: time.Hour}},
+			},
+		}
+
+		getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+		g.Expect(err).ToNot(HaveOccurred())
+
+		cfg, err := action.NewConfigFactory(getter,
+			action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+		)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		client := fake.NewClientBuilder().
+			WithScheme(testEnv.Scheme()).
+			WithObjects(obj).
+			WithStatusSubresource(&v2.HelmRelease{}).
+			Build()
+		patchHelper := patch.NewSerialPatcher(obj, client)
+		recorder := new(record.FakeRecorder)
+
+		reconcile := func(chrt *helmchart.Chart) {
+			g.Expect(NewAtomicRelease(patchHelper, cfg, recorder, testFieldManager).Reconcile(context.TODO(), &Request{
+				Object: obj,
+				Chart:  chrt,
+			})).To(Succeed())
+			g.Expect(conditions.IsReady(obj)).To(BeTrue())
+		}
+
+		// The install is not counted as stable.
+		reconcile(testutil.BuildChart())
+		g.Expect(obj.Status.StableReconciliations).To(BeZero())
+
+		// Reconciliations of the in-sync release are counted.
+		for i := int64(1); i <= 3; i++ {
+			reconcile(testutil.BuildChart())
+			g.Expect(obj.Status.StableReconciliations).To(Equal(i))
+		}
+
+		// A change of the chart results in an upgrade, which resets the
+		// count.
+		reconcile(testutil.BuildChart(testutil.ChartWithVersion("0.2.0")))
+		g.Expect(obj.Status.StableReconciliations).To(BeZero())
+
+		reconcile(testutil.BuildChart(testutil.ChartWithVersion("0.2.0")))
+		g.Expect(obj.Status.StableReconciliations).To(Equal(int64(1)))
+	})
+
+	t.Run("remediates upgrade timeout", func(t *testing.T) {
+		g := NewWithT(t)
+
+		namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+		g.Expect(err).NotTo(HaveOccurred())
+		t.Cleanup(func() {
+			_ = testEnv.Delete(context.TODO(), namedNS)
+		})
+		releaseNamespace := namedNS.Name
+
+		obj := &v2.HelmRelease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      mockReleaseName,
+				Namespace: releaseNamespace,
+			},
+			Spec: v2.HelmReleaseSpec{
+				ReleaseName:      mockReleaseName,
+				TargetNamespace:  releaseNamespace,
+				StorageNamespace: releaseNamespace,
+				Timeout:          &metav1.Duration{Duration: time.Minute},
+				Upgrade: &v2.Upgrade{
+					// The timeout of the upgrade takes precedence over the
+					// timeout of the release.
+					Timeout: &metav1.Duration{Duration: 100 * time.Millisecond},
+					Remediation: &v2.UpgradeRemediation{
+						RemediateOn:          []v2.FailureClass{v2.FailureClassTimeout},
+						RemediateLastFailure: ptr.To(true),
+					},
+				},
+			},
+		}
+
+		getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+		g.Expect(err).ToNot(HaveOccurred())
+
+		cfg, err := action.NewConfigFactory(getter,
+			action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+		)
+		g.Expect(err).ToNot(HaveOccurred())
+
+		client := fake.NewClientBuilder().
+			WithScheme(testEnv.Scheme()).
+			WithObjects(obj).
+			WithStatusSubresource(&v2.HelmRelease{}).
+			Build()
+		patchHelper := patch.NewSerialPatcher(obj, client)
+		recorder := new(record.FakeRecorder)
+
+		g.Expect(NewAtomicRelease(patchHelper, cfg, recorder, testFieldManager).Reconcile(context.TODO(), &Request{
+			Object: obj,
+			Chart:  testutil.BuildChart(),
+		})).To(Succeed())
+
+		// The hook of the chart never completes, which makes the upgrade
+		// time out.
+		err = NewAtomicRelease(patchHelper, cfg, recorder, testFieldManager).Reconcile(context.TODO(), &Request{
+			Object: obj,
+			Chart:  testutil.BuildChart(testutil.ChartWithVersion("0.2.0"), testutil.ChartWithFailingHook()),
+		})
+		g.Expect(err).To(MatchError(ErrExceededMaxRetries))
+
+		g.Expect(obj.Status.LastFailureClass).To(Equal(v2.FailureClassTimeout))
+		g.Expect(conditions.IsTrue(obj, v2.RemediatedCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(obj, v2.RemediatedCondition)).To(Equal(v2.RollbackSucceededReason))
+
+		history, err := helmstorage.Init(cfg.Driver).History(mockReleaseName)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(history).To(HaveLen(3))
+	})
 }
 
 func TestAtomicRelease_Reconcile_Scenarios(t *testing.T) {
@@ -728,6 +937,44 @@ func TestAtomicRelease_Reconcile_Scenarios(t *testing.T) {
 			},
 			wantErr: ErrExceededMaxRetries,
 		},
+		{
+			name: "upgrade failure within retry delay",
+			releases: func(namespace string) []*helmrelease.Release {
+				return []*helmrelease.Release{
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Namespace: namespace,
+						Version:   1,
+						Chart:     testutil.BuildChart(),
+						Status:    helmrelease.StatusDeployed,
+					}),
+				}
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{
+						Retries:    3,
+						RetryDelay: &metav1.Duration{Duration: time.Hour},
+					},
+				}
+			},
+			status: func(namespace string, releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+				}
+			},
+			chart: testutil.BuildChart(testutil.ChartWithFailingHook()),
+			expectHistory: func(releases []*helmrelease.Release) v2.Snapshots {
+				// The failed upgrade is not retried within the retry delay.
+				return v2.Snapshots{
+					release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+					release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+				}
+			},
+			wantErr: ErrWithinRetryDelay,
+		},
 		{
 			name: "upgrade failure with rollback remediation",
 			releases: func(namespace string) []*helmrelease.Release {
@@ -1063,36 +1310,427 @@ func TestAtomicRelease_Reconcile_Scenarios(t *testing.T) {
 				history, _ := store.History(mockReleaseName)
 				releaseutil.SortByRevision(history)
 
-				g.Expect(req.Object.Status.History).To(testutil.Equal(tt.expectHistory(history)))
+				g.Expect(req.Object.Status.History).To(testutil.Equal(tt.expectHistory(history), ignoreSnapshotDuration))
 			}
 		})
 	}
 }
 
-func TestAtomicRelease_Reconcile_PostRenderers_Scenarios(t *testing.T) {
+func TestAtomicRelease_Reconcile_ValuesSchema(t *testing.T) {
 	tests := []struct {
-		name              string
-		releases          func(namespace string) []*helmrelease.Release
-		spec              func(spec *v2.HelmReleaseSpec)
-		values            map[string]interface{}
-		status            func(releases []*helmrelease.Release) v2.HelmReleaseStatus
-		wantDigest        string
-		wantReleaseAction v2.ReleaseAction
+		name           string
+		disableUpgrade bool
+		wantErr        bool
 	}{
 		{
-			name: "addition of post renderers",
+			name:    "blocks upgrade with values not matching the schema",
+			wantErr: true,
+		},
+		{
+			name:           "runs upgrade with schema validation disabled",
+			disableUpgrade: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			t.Cleanup(func() {
+				_ = testEnv.Delete(context.TODO(), namedNS)
+			})
+			releaseNamespace := namedNS.Name
+
+			rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+				Name:      mockReleaseName,
+				Namespace: releaseNamespace,
+				Version:   1,
+				Chart:     testutil.BuildChart(),
+				Status:    helmrelease.StatusDeployed,
+			}, testutil.ReleaseWithConfig(nil))
+
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mockReleaseName,
+					Namespace: releaseNamespace,
+				},
+				Spec: v2.HelmReleaseSpec{
+					ReleaseName:      mockReleaseName,
+					TargetNamespace:  releaseNamespace,
+					StorageNamespace: releaseNamespace,
+					Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+					// The install schema validation does not apply to an
+					// upgrade.
+					Install: &v2.Install{DisableSchemaValidation: true},
+					Upgrade: &v2.Upgrade{DisableSchemaValidation: tt.disableUpgrade},
+				},
+				Status: v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(rls)),
+					},
+				},
+			}
+
+			getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+			g.Expect(err).ToNot(HaveOccurred())
+
+			cfg, err := action.NewConfigFactory(getter,
+				action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+			)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			store := helmstorage.Init(cfg.Driver)
+			g.Expect(store.Create(rls)).To(Succeed())
+
+			client := fake.NewClientBuilder().
+				WithScheme(testEnv.Scheme()).
+				WithObjects(obj).
+				WithStatusSubresource(&v2.HelmRelease{}).
+				Build()
+
+			chrt := testutil.BuildChart()
+			chrt.Schema = []byte(`{"type": "object", "properties": {"replicas": {"type": "integer"}}}`)
+
+			req := &Request{
+				Object: obj,
+				Chart:  chrt,
+				Values: map[string]interface{}{"replicas": "two"},
+			}
+			err = NewAtomicRelease(patch.NewSerialPatcher(obj, client), cfg, new(record.FakeRecorder), testFieldManager).
+				Reconcile(context.TODO(), req)
+
+			history, _ := store.History(mockReleaseName)
+			if tt.wantErr {
+				g.Expect(err).To(MatchError(ContainSubstring("replicas: Invalid type")))
+				g.Expect(conditions.GetReason(obj, meta.ReadyCondition)).To(Equal(v2.ValuesSchemaInvalidReason))
+				g.Expect(history).To(HaveLen(1))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(history).To(HaveLen(2))
+		})
+	}
+}
+
+func TestAtomicRelease_Reconcile_Paused(t *testing.T) {
+	tests := []struct {
+		name     string
+		releases func(namespace string) []*helmrelease.Release
+		spec     func(spec *v2.HelmReleaseSpec)
+		chart    *helmchart.Chart
+	}{
+		{
+			name:  "does not install absent release",
+			chart: testutil.BuildChart(),
+		},
+		{
+			name: "does not upgrade out-of-sync release",
 			releases: func(namespace string) []*helmrelease.Release {
 				return []*helmrelease.Release{
 					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
 						Name:      mockReleaseName,
 						Namespace: namespace,
 						Version:   1,
-						Status:    helmrelease.StatusDeployed,
 						Chart:     testutil.BuildChart(),
+						Status:    helmrelease.StatusDeployed,
 					}, testutil.ReleaseWithConfig(nil)),
 				}
 			},
-			spec: func(spec *v2.HelmReleaseSpec) {
+			chart: testutil.BuildChart(testutil.ChartWithVersion("0.2.0")),
+		},
+		{
+			name: "does not test untested release",
+			releases: func(namespace string) []*helmrelease.Release {
+				return []*helmrelease.Release{
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Namespace: namespace,
+						Version:   1,
+						Chart:     testutil.BuildChart(testutil.ChartWithTestHook()),
+						Status:    helmrelease.StatusDeployed,
+					}, testutil.ReleaseWithConfig(nil)),
+				}
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Test = &v2.Test{Enable: true}
+			},
+			chart: testutil.BuildChart(testutil.ChartWithTestHook()),
+		},
+		{
+			name: "does not remediate failed release",
+			releases: func(namespace string) []*helmrelease.Release {
+				return []*helmrelease.Release{
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Namespace: namespace,
+						Version:   1,
+						Chart:     testutil.BuildChart(),
+						Status:    helmrelease.StatusFailed,
+					}, testutil.ReleaseWithConfig(nil)),
+				}
+			},
+			chart: testutil.BuildChart(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			t.Cleanup(func() {
+				_ = testEnv.Delete(context.TODO(), namedNS)
+			})
+			releaseNamespace := namedNS.Name
+
+			var releases []*helmrelease.Release
+			if tt.releases != nil {
+				releases = tt.releases(releaseNamespace)
+			}
+
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      mockReleaseName,
+					Namespace: releaseNamespace,
+					Annotations: map[string]string{
+						v2.PauseAnnotation: "true",
+					},
+				},
+				Spec: v2.HelmReleaseSpec{
+					ReleaseName:      mockReleaseName,
+					TargetNamespace:  releaseNamespace,
+					StorageNamespace: releaseNamespace,
+					Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+				},
+			}
+			if tt.spec != nil {
+				tt.spec(&obj.Spec)
+			}
+			for _, r := range releases {
+				obj.Status.History = append(v2.Snapshots{release.ObservedToSnapshot(release.ObserveRelease(r))}, obj.Status.History...)
+			}
+			conditions.MarkTrue(obj, v2.ReleasedCondition, v2.InstallSucceededReason, "Helm install succeeded")
+			history := obj.Status.History.DeepCopy()
+
+			getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+			g.Expect(err).ToNot(HaveOccurred())
+
+			cfg, err := action.NewConfigFactory(getter,
+				action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+			)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			store := helmstorage.Init(cfg.Driver)
+			for _, r := range releases {
+				g.Expect(store.Create(r)).To(Succeed())
+			}
+
+			client := fake.NewClientBuilder().
+				WithScheme(testEnv.Scheme()).
+				WithObjects(obj).
+				WithStatusSubresource(&v2.HelmRelease{}).
+				Build()
+			patchHelper := patch.NewSerialPatcher(obj, client)
+			recorder := new(record.FakeRecorder)
+
+			req := &Request{
+				Object: obj,
+				Chart:  tt.chart,
+			}
+			g.Expect(NewAtomicRelease(patchHelper, cfg, recorder, testFieldManager,
+				WithReconciliationPaused()).Reconcile(context.TODO(), req)).To(Succeed())
+
+			g.Expect(obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{
+				*conditions.UnknownCondition(meta.ReadyCondition, v2.PausedReason,
+					"Reconciliation is paused using the %s annotation", v2.PauseAnnotation),
+				*conditions.TrueCondition(v2.ReleasedCondition, v2.InstallSucceededReason, "Helm install succeeded"),
+			}))
+			g.Expect(obj.Status.History).To(Equal(history))
+			g.Expect(obj.Status.LastAttemptedReleaseAction).To(BeEmpty())
+
+			storeHistory, _ := store.History(mockReleaseName)
+			g.Expect(storeHistory).To(HaveLen(len(releases)))
+		})
+	}
+}
+
+func TestAtomicRelease_Reconcile_ReadinessGracePeriod(t *testing.T) {
+	g := NewWithT(t)
+
+	namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	t.Cleanup(func() {
+		_ = testEnv.Delete(context.TODO(), namedNS)
+	})
+	releaseNamespace := namedNS.Name
+
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mockReleaseName,
+			Namespace: releaseNamespace,
+		},
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			TargetNamespace:  releaseNamespace,
+			StorageNamespace: releaseNamespace,
+			Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+			Install: &v2.Install{
+				ReadinessGracePeriod: &metav1.Duration{Duration: time.Millisecond},
+				Remediation: &v2.InstallRemediation{
+					Retries: 1,
+				},
+			},
+		},
+	}
+
+	getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cfg, err := action.NewConfigFactory(getter,
+		action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	client := fake.NewClientBuilder().
+		WithScheme(testEnv.Scheme()).
+		WithObjects(obj).
+		WithStatusSubresource(&v2.HelmRelease{}).
+		Build()
+	patchHelper := patch.NewSerialPatcher(obj, client)
+	recorder := testutil.NewFakeRecorder(50, false)
+
+	req := &Request{
+		Object: obj,
+		Chart:  testutil.BuildChart(testutil.ChartWithPod()),
+	}
+
+	// The Pod does not become ready within the grace period, which fails
+	// the install.
+	err = NewAtomicRelease(patchHelper, cfg, recorder, testFieldManager).Reconcile(context.TODO(), req)
+	g.Expect(err).To(MatchError(ErrMustRequeue))
+	g.Expect(conditions.IsFalse(obj, v2.ReleasedCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(obj, v2.ReleasedCondition)).To(Equal(v2.InstallFailedReason))
+	g.Expect(obj.Status.InstallFailures).To(Equal(int64(1)))
+
+	cur := obj.Status.History.Latest()
+	g.Expect(cur.Status).To(Equal(helmrelease.StatusFailed.String()))
+	_, err = action.VerifySnapshot(cfg.Build(nil), cur)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	state, err := DetermineReleaseState(context.TODO(), cfg, req)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(state.Status).To(Equal(ReleaseStatusFailed))
+	_ = recorder.GetEvents()
+
+	// The next reconciliation remediates the failed install by uninstalling
+	// the release, instead of upgrading it as an unmanaged release.
+	_ = NewAtomicRelease(patchHelper, cfg, recorder, testFieldManager).Reconcile(context.TODO(), req)
+	g.Expect(recorder.GetEvents()).To(ContainElement(
+		WithTransform(func(e corev1.Event) string { return e.Reason }, Equal(v2.UninstallSucceededReason)),
+	))
+	g.Expect(obj.Status.UpgradeFailures).To(BeZero())
+}
+
+func TestAtomicRelease_Reconcile_PinnedFailedRelease(t *testing.T) {
+	g := NewWithT(t)
+
+	namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	t.Cleanup(func() {
+		_ = testEnv.Delete(context.TODO(), namedNS)
+	})
+	releaseNamespace := namedNS.Name
+
+	rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+		Name:      mockReleaseName,
+		Namespace: releaseNamespace,
+		Version:   1,
+		Status:    helmrelease.StatusFailed,
+		Chart:     testutil.BuildChart(),
+	}, testutil.ReleaseWithConfig(nil))
+
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mockReleaseName,
+			Namespace: releaseNamespace,
+		},
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			TargetNamespace:  releaseNamespace,
+			StorageNamespace: releaseNamespace,
+			PinRelease:       true,
+		},
+		Status: v2.HelmReleaseStatus{
+			History: v2.Snapshots{
+				release.ObservedToSnapshot(release.ObserveRelease(rls)),
+			},
+			InstallFailures: 1,
+			Failures:        1,
+		},
+	}
+
+	getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cfg, err := action.NewConfigFactory(getter,
+		action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	store := helmstorage.Init(cfg.Driver)
+	g.Expect(store.Create(rls)).To(Succeed())
+
+	client := fake.NewClientBuilder().
+		WithScheme(testEnv.Scheme()).
+		WithObjects(obj).
+		WithStatusSubresource(&v2.HelmRelease{}).
+		Build()
+	patchHelper := patch.NewSerialPatcher(obj, client)
+	recorder := testutil.NewFakeRecorder(10, false)
+
+	req := &Request{
+		Object: obj,
+		Chart:  testutil.BuildChart(testutil.ChartWithVersion("0.2.0")),
+		Values: rls.Config,
+	}
+
+	// The failed release is not upgraded to the new chart version, as the
+	// release is pinned.
+	g.Expect(NewAtomicRelease(patchHelper, cfg, recorder, testFieldManager).Reconcile(context.TODO(), req)).To(Succeed())
+	g.Expect(conditions.IsFalse(obj, meta.ReadyCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(obj, meta.ReadyCondition)).To(Equal(v2.ReleasePinnedReason))
+	g.Expect(obj.Status.AvailableChartVersion).To(Equal("0.2.0"))
+
+	latest, err := store.Last(mockReleaseName)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(latest.Version).To(Equal(1))
+}
+
+func TestAtomicRelease_Reconcile_PostRenderers_Scenarios(t *testing.T) {
+	tests := []struct {
+		name              string
+		releases          func(namespace string) []*helmrelease.Release
+		spec              func(spec *v2.HelmReleaseSpec)
+		values            map[string]interface{}
+		status            func(releases []*helmrelease.Release) v2.HelmReleaseStatus
+		wantDigest        string
+		wantReleaseAction v2.ReleaseAction
+	}{
+		{
+			name: "addition of post renderers",
+			releases: func(namespace string) []*helmrelease.Release {
+				return []*helmrelease.Release{
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Namespace: namespace,
+						Version:   1,
+						Status:    helmrelease.StatusDeployed,
+						Chart:     testutil.BuildChart(),
+					}, testutil.ReleaseWithConfig(nil)),
+				}
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
 				spec.PostRenderers = postRenderers
 			},
 			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
@@ -1299,6 +1937,7 @@ func TestAtomicRelease_actionForState(t *testing.T) {
 		annotations      map[string]string
 		spec             func(spec *v2.HelmReleaseSpec)
 		status           func(releases []*helmrelease.Release) v2.HelmReleaseStatus
+		chart            *helmchart.Chart
 		state            ReleaseState
 		want             ActionReconciler
 		wantEvent        *corev1.Event
@@ -1391,6 +2030,11 @@ func TestAtomicRelease_actionForState(t *testing.T) {
 			state: ReleaseState{Status: ReleaseStatusUnmanaged},
 			want:  &Upgrade{},
 		},
+		{
+			name:  "adoptable release triggers adopt",
+			state: ReleaseState{Status: ReleaseStatusAdoptable},
+			want:  &Adopt{},
+		},
 		{
 			name: "drifted release triggers correction if enabled",
 			state: ReleaseState{Status: ReleaseStatusDrifted, Diff: jsondiff.DiffSet{
@@ -1540,98 +2184,125 @@ func TestAtomicRelease_actionForState(t *testing.T) {
 			wantErr: ErrExceededMaxRetries,
 		},
 		{
-			name:  "untested release triggers test action",
-			state: ReleaseState{Status: ReleaseStatusUntested},
-			want:  &Test{},
-		},
-		{
-			name: "untested release with stale remediated condition",
+			name: "out-of-sync release rolled back on request does not trigger any action",
+			state: ReleaseState{
+				Status: ReleaseStatusOutOfSync,
+			},
 			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
 				return v2.HelmReleaseStatus{
-					History: v2.Snapshots{
-						{Version: 1},
-					},
-					Conditions: []metav1.Condition{
-						*conditions.FalseCondition(v2.ReleasedCondition, v2.UpgradeFailedReason, "upgrade failed"),
-						*conditions.TrueCondition(v2.RemediatedCondition, v2.RollbackSucceededReason, "rolled back"),
-					},
+					ManualRollbackActive: true,
 				}
 			},
-			state: ReleaseState{Status: ReleaseStatusUntested},
-			want:  &Test{},
-			assertConditions: []metav1.Condition{
-				*conditions.TrueCondition(v2.ReleasedCondition, v2.UpgradeSucceededReason, "upgrade succeeded"),
-			},
+			want: nil,
 		},
 		{
-			name:  "failed release without active remediation triggers upgrade",
-			state: ReleaseState{Status: ReleaseStatusFailed},
+			name: "out-of-sync release rolled back on request with force annotation triggers upgrade",
+			state: ReleaseState{
+				Status: ReleaseStatusOutOfSync,
+			},
+			annotations: map[string]string{
+				meta.ReconcileRequestAnnotation: "force",
+				v2.ForceRequestAnnotation:       "force",
+			},
 			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
 				return v2.HelmReleaseStatus{
-					LastAttemptedReleaseAction: "",
-					InstallFailures:            1,
+					ManualRollbackActive: true,
 				}
 			},
 			want: &Upgrade{},
 		},
 		{
-			name:  "failed release without failure count triggers upgrade",
-			state: ReleaseState{Status: ReleaseStatusFailed},
+			name:  "in-sync release with test annotation triggers test",
+			state: ReleaseState{Status: ReleaseStatusInSync},
+			annotations: map[string]string{
+				meta.ReconcileRequestAnnotation: "test",
+				v2.TestRequestAnnotation:        "test",
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Test = &v2.Test{Enable: true}
+			},
 			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
 				return v2.HelmReleaseStatus{
-					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
-					UpgradeFailures:            0,
+					History: v2.Snapshots{
+						{Version: 1, Status: helmrelease.StatusDeployed.String()},
+					},
 				}
 			},
-			want: &Upgrade{},
+			want: &Test{},
 		},
 		{
-			name:  "failed release with exhausted retries and force annotation triggers upgrade",
-			state: ReleaseState{Status: ReleaseStatusFailed},
+			name:  "release with failed tests and test annotation triggers test",
+			state: ReleaseState{Status: ReleaseStatusFailed, Reason: "release has test in failed phase"},
 			annotations: map[string]string{
-				meta.ReconcileRequestAnnotation: "force",
-				v2.ForceRequestAnnotation:       "force",
+				meta.ReconcileRequestAnnotation: "test",
+				v2.TestRequestAnnotation:        "test",
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Test = &v2.Test{Enable: true}
 			},
 			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
 				return v2.HelmReleaseStatus{
-					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
-					UpgradeFailures:            1,
+					History: v2.Snapshots{
+						{Version: 1, Status: helmrelease.StatusDeployed.String()},
+					},
 				}
 			},
-			want: &Upgrade{},
+			want: &Test{},
 		},
 		{
-			name:  "failed release with exhausted retries returns error",
-			state: ReleaseState{Status: ReleaseStatusFailed},
+			name:  "in-sync release with test annotation without tests enabled emits event",
+			state: ReleaseState{Status: ReleaseStatusInSync},
+			annotations: map[string]string{
+				meta.ReconcileRequestAnnotation: "test",
+				v2.TestRequestAnnotation:        "test",
+			},
 			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
 				return v2.HelmReleaseStatus{
-					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
-					UpgradeFailures:            1,
+					History: v2.Snapshots{
+						{Version: 1, Status: helmrelease.StatusDeployed.String()},
+					},
 				}
 			},
-			wantErr: ErrExceededMaxRetries,
+			want: nil,
+			wantEvent: &corev1.Event{
+				Reason:  v2.TestRequestFailedReason,
+				Type:    corev1.EventTypeWarning,
+				Message: "Unable to run tests on request: tests are not enabled",
+			},
 		},
 		{
-			name:  "failed release with active install remediation triggers uninstall",
-			state: ReleaseState{Status: ReleaseStatusFailed},
+			name:  "out-of-sync release with test annotation emits event and triggers upgrade",
+			state: ReleaseState{Status: ReleaseStatusOutOfSync, Reason: "chart changed"},
+			annotations: map[string]string{
+				meta.ReconcileRequestAnnotation: "test",
+				v2.TestRequestAnnotation:        "test",
+			},
 			spec: func(spec *v2.HelmReleaseSpec) {
-				spec.Install = &v2.Install{
-					Remediation: &v2.InstallRemediation{
-						Retries: 3,
-					},
-				}
+				spec.Test = &v2.Test{Enable: true}
 			},
 			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
 				return v2.HelmReleaseStatus{
-					LastAttemptedReleaseAction: v2.ReleaseActionInstall,
-					InstallFailures:            2,
+					History: v2.Snapshots{
+						{Version: 1, Status: helmrelease.StatusDeployed.String()},
+					},
 				}
 			},
-			want: &UninstallRemediation{},
+			want: &Upgrade{},
+			wantEvent: &corev1.Event{
+				Reason:  v2.TestRequestFailedReason,
+				Type:    corev1.EventTypeWarning,
+				Message: "Unable to run tests on request: release state is OutOfSync: chart changed",
+			},
 		},
 		{
-			name:  "failed release with active upgrade remediation triggers rollback",
-			state: ReleaseState{Status: ReleaseStatusFailed},
+			name: "release with rollback annotation triggers manual rollback",
+			state: ReleaseState{
+				Status: ReleaseStatusInSync,
+			},
+			annotations: map[string]string{
+				meta.ReconcileRequestAnnotation: "rollback",
+				v2.RollbackRequestAnnotation:    "rollback",
+			},
 			releases: []*helmrelease.Release{
 				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
 					Name:      mockReleaseName,
@@ -1644,15 +2315,514 @@ func TestAtomicRelease_actionForState(t *testing.T) {
 					Name:      mockReleaseName,
 					Namespace: mockReleaseNamespace,
 					Version:   2,
-					Status:    helmrelease.StatusFailed,
+					Status:    helmrelease.StatusDeployed,
 					Chart:     testutil.BuildChart(),
 				}),
 			},
-			spec: func(spec *v2.HelmReleaseSpec) {
-				spec.Upgrade = &v2.Upgrade{
-					Remediation: &v2.UpgradeRemediation{
-						Retries: 2,
-					},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+				}
+			},
+			want: &ManualRollback{},
+		},
+		{
+			name: "release with rollback annotation without previous release emits event",
+			state: ReleaseState{
+				Status: ReleaseStatusInSync,
+			},
+			annotations: map[string]string{
+				meta.ReconcileRequestAnnotation: "rollback",
+				v2.RollbackRequestAnnotation:    "rollback",
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						{Version: 1},
+					},
+				}
+			},
+			want: nil,
+			wantEvent: &corev1.Event{
+				Reason:  v2.ManualRollbackFailedReason,
+				Type:    corev1.EventTypeWarning,
+				Message: "Unable to roll back on request: missing target release for rollback: no previous release in history",
+			},
+		},
+		{
+			name: "absent release with rollback annotation emits event and triggers install",
+			state: ReleaseState{
+				Status: ReleaseStatusAbsent,
+				Reason: "no release in storage for object",
+			},
+			annotations: map[string]string{
+				meta.ReconcileRequestAnnotation: "rollback",
+				v2.RollbackRequestAnnotation:    "rollback",
+			},
+			want: &Install{},
+			wantEvent: &corev1.Event{
+				Reason:  v2.ManualRollbackFailedReason,
+				Type:    corev1.EventTypeWarning,
+				Message: "Unable to roll back on request: no latest release: no release in storage for object",
+			},
+		},
+		{
+			name:  "untested release triggers test action",
+			state: ReleaseState{Status: ReleaseStatusUntested},
+			want:  &Test{},
+		},
+		{
+			name: "untested release with stale remediated condition",
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						{Version: 1},
+					},
+					Conditions: []metav1.Condition{
+						*conditions.FalseCondition(v2.ReleasedCondition, v2.UpgradeFailedReason, "upgrade failed"),
+						*conditions.TrueCondition(v2.RemediatedCondition, v2.RollbackSucceededReason, "rolled back"),
+					},
+				}
+			},
+			state: ReleaseState{Status: ReleaseStatusUntested},
+			want:  &Test{},
+			assertConditions: []metav1.Condition{
+				*conditions.TrueCondition(v2.ReleasedCondition, v2.UpgradeSucceededReason, "upgrade succeeded"),
+			},
+		},
+		{
+			name:  "failed release without active remediation triggers upgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					LastAttemptedReleaseAction: "",
+					InstallFailures:            1,
+				}
+			},
+			want: &Upgrade{},
+		},
+		{
+			name:  "pinned failed release without active remediation holds back upgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed, HeldBack: "release chart changed"},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.PinRelease = true
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					InstallFailures: 1,
+				}
+			},
+			want: nil,
+		},
+		{
+			name:  "pinned failed release without failure count holds back upgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed, HeldBack: "release chart changed"},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.PinRelease = true
+				spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{
+						Retries: 2,
+					},
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+					UpgradeFailures:            0,
+				}
+			},
+			want: nil,
+		},
+		{
+			name:  "pinned failed release with force annotation triggers upgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed, HeldBack: "release chart changed"},
+			annotations: map[string]string{
+				meta.ReconcileRequestAnnotation: "force",
+				v2.ForceRequestAnnotation:       "force",
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.PinRelease = true
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					InstallFailures: 1,
+				}
+			},
+			want: &Upgrade{},
+		},
+		{
+			name:  "pinned unmanaged release holds back upgrade",
+			state: ReleaseState{Status: ReleaseStatusUnmanaged, HeldBack: "found existing release in storage"},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.PinRelease = true
+			},
+			want: nil,
+		},
+		{
+			name:  "failed release without failure count triggers upgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+					UpgradeFailures:            0,
+				}
+			},
+			want: &Upgrade{},
+		},
+		{
+			name:  "failed release without active remediation blocks downgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						{Name: mockReleaseName, Namespace: mockReleaseNamespace, Version: 1, ChartVersion: "0.2.0"},
+					},
+					InstallFailures: 1,
+				}
+			},
+			chart:   testutil.BuildChart(testutil.ChartWithVersion("0.1.0")),
+			wantErr: ErrDowngradeBlocked,
+		},
+		{
+			name:  "failed release without failure count blocks downgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						{Name: mockReleaseName, Namespace: mockReleaseNamespace, Version: 1, ChartVersion: "0.2.0"},
+					},
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+				}
+			},
+			chart:   testutil.BuildChart(testutil.ChartWithVersion("0.1.0")),
+			wantErr: ErrDowngradeBlocked,
+		},
+		{
+			name:  "failed release with downgrade allowed triggers upgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.AllowDowngrade = true
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						{Name: mockReleaseName, Namespace: mockReleaseNamespace, Version: 1, ChartVersion: "0.2.0"},
+					},
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+				}
+			},
+			chart: testutil.BuildChart(testutil.ChartWithVersion("0.1.0")),
+			want:  &Upgrade{},
+		},
+		{
+			name:  "failed release within retry delay returns error",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{
+						RetryDelay: &metav1.Duration{Duration: time.Hour},
+					},
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+					FirstFailureTime:           &metav1.Time{Time: time.Now()},
+				}
+			},
+			wantErr: ErrWithinRetryDelay,
+		},
+		{
+			name:  "failed release after retry delay triggers upgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{
+						RetryDelay: &metav1.Duration{Duration: time.Hour},
+					},
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+					FirstFailureTime:           &metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+				}
+			},
+			want: &Upgrade{},
+		},
+		{
+			name:  "failed release with exhausted retries and force annotation triggers upgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			annotations: map[string]string{
+				meta.ReconcileRequestAnnotation: "force",
+				v2.ForceRequestAnnotation:       "force",
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+					UpgradeFailures:            1,
+				}
+			},
+			want: &Upgrade{},
+		},
+		{
+			name:  "failed release with exhausted retries returns error",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+					UpgradeFailures:            1,
+				}
+			},
+			wantErr: ErrExceededMaxRetries,
+		},
+		{
+			name:  "failed release with active install remediation triggers uninstall",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Install = &v2.Install{
+					Remediation: &v2.InstallRemediation{
+						Retries: 3,
+					},
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					LastAttemptedReleaseAction: v2.ReleaseActionInstall,
+					InstallFailures:            2,
+				}
+			},
+			want: &UninstallRemediation{},
+		},
+		{
+			name:  "failed release with active upgrade remediation triggers rollback",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusSuperseded,
+					Chart:     testutil.BuildChart(),
+				}),
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   2,
+					Status:    helmrelease.StatusFailed,
+					Chart:     testutil.BuildChart(),
+				}),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{
+						Retries: 2,
+					},
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+					UpgradeFailures:            1,
+				}
+			},
+			want: &RollbackRemediation{},
+		},
+		{
+			name:  "failed release with failure class to remediate triggers rollback",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusSuperseded,
+					Chart:     testutil.BuildChart(),
+				}),
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   2,
+					Status:    helmrelease.StatusFailed,
+					Chart:     testutil.BuildChart(),
+				}),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{
+						Retries:     2,
+						RemediateOn: []v2.FailureClass{v2.FailureClassRollout, v2.FailureClassTimeout},
+					},
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+					UpgradeFailures:            1,
+					LastFailureClass:           v2.FailureClassTimeout,
+				}
+			},
+			want: &RollbackRemediation{},
+		},
+		{
+			name:  "failed release with failure class not to remediate triggers upgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusSuperseded,
+					Chart:     testutil.BuildChart(),
+				}),
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   2,
+					Status:    helmrelease.StatusFailed,
+					Chart:     testutil.BuildChart(),
+				}),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{
+						Retries:     2,
+						RemediateOn: []v2.FailureClass{v2.FailureClassRollout},
+					},
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+					UpgradeFailures:            1,
+					LastFailureClass:           v2.FailureClassRender,
+				}
+			},
+			want: &Upgrade{},
+		},
+		{
+			name:  "failed release with failure class not to remediate and exhausted retries triggers error",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusSuperseded,
+					Chart:     testutil.BuildChart(),
+				}),
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   2,
+					Status:    helmrelease.StatusFailed,
+					Chart:     testutil.BuildChart(),
+				}),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{
+						Retries:     2,
+						RemediateOn: []v2.FailureClass{v2.FailureClassRollout},
+					},
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+					UpgradeFailures:            3,
+					LastFailureClass:           v2.FailureClassRender,
+				}
+			},
+			wantErr: ErrExceededMaxRetries,
+		},
+		{
+			name:  "failed release with kept resources triggers error",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusSuperseded,
+					Chart:     testutil.BuildChart(),
+				}),
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   2,
+					Status:    helmrelease.StatusFailed,
+					Chart:     testutil.BuildChart(),
+				}),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{
+						Retries:             2,
+						KeepFailedResources: true,
+					},
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+					LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+					UpgradeFailures:            1,
+				}
+			},
+			wantErr: ErrFailedResourcesKept,
+		},
+		{
+			name:  "failed release with kept resources and force annotation triggers upgrade",
+			state: ReleaseState{Status: ReleaseStatusFailed},
+			annotations: map[string]string{
+				meta.ReconcileRequestAnnotation: "force",
+				v2.ForceRequestAnnotation:       "force",
+			},
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusSuperseded,
+					Chart:     testutil.BuildChart(),
+				}),
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   2,
+					Status:    helmrelease.StatusFailed,
+					Chart:     testutil.BuildChart(),
+				}),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{
+						Retries:             2,
+						KeepFailedResources: true,
+					},
 				}
 			},
 			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
@@ -1665,7 +2835,7 @@ func TestAtomicRelease_actionForState(t *testing.T) {
 					UpgradeFailures:            1,
 				}
 			},
-			want: &RollbackRemediation{},
+			want: &Upgrade{},
 		},
 		{
 			name:  "failed release with active upgrade remediation and no previous release triggers error",
@@ -1811,7 +2981,7 @@ func TestAtomicRelease_actionForState(t *testing.T) {
 
 			recorder := testutil.NewFakeRecorder(1, false)
 			r := &AtomicRelease{configFactory: cfg, eventRecorder: recorder}
-			got, err := r.actionForState(context.TODO(), &Request{Object: obj}, tt.state)
+			got, err := r.actionForState(context.TODO(), &Request{Object: obj, Chart: tt.chart}, tt.state)
 
 			if tt.wantErr != nil {
 				g.Expect(got).To(BeNil())
@@ -1837,6 +3007,66 @@ func TestAtomicRelease_actionForState(t *testing.T) {
 	}
 }
 
+func TestAtomicRelease_actionForState_forceRequestToken(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				meta.ReconcileRequestAnnotation: "token",
+				v2.ForceRequestAnnotation:       "token",
+			},
+		},
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			TargetNamespace:  mockReleaseNamespace,
+			StorageNamespace: mockReleaseNamespace,
+		},
+		Status: v2.HelmReleaseStatus{
+			History: v2.Snapshots{
+				{Version: 1},
+			},
+		},
+	}
+
+	cfg, err := action.NewConfigFactory(&kube.MemoryRESTClientGetter{},
+		action.WithStorage(helmdriver.MemoryDriverName, mockReleaseNamespace),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	r := &AtomicRelease{configFactory: cfg, eventRecorder: testutil.NewFakeRecorder(1, false)}
+	req := &Request{Object: obj}
+	state := ReleaseState{Status: ReleaseStatusInSync}
+
+	// The first reconcile with the token forces an upgrade.
+	got, err := r.actionForState(context.TODO(), req, state)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(BeAssignableToTypeOf(&Upgrade{}))
+	g.Expect(got.(*Upgrade).forceToken).To(Equal("token"))
+	g.Expect(obj.Status.LastHandledForceAt).To(Equal("token"))
+
+	// Subsequent reconciles with the same stale token do not.
+	for i := 0; i < 3; i++ {
+		got, err = r.actionForState(context.TODO(), req, state)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(got).To(BeNil())
+	}
+
+	// A reconcile request without a new force token does not either.
+	obj.Annotations[meta.ReconcileRequestAnnotation] = "other"
+	got, err = r.actionForState(context.TODO(), req, state)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(BeNil())
+
+	// A new token forces an upgrade again.
+	obj.Annotations[meta.ReconcileRequestAnnotation] = "new-token"
+	obj.Annotations[v2.ForceRequestAnnotation] = "new-token"
+	got, err = r.actionForState(context.TODO(), req, state)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(BeAssignableToTypeOf(&Upgrade{}))
+	g.Expect(got.(*Upgrade).forceToken).To(Equal("new-token"))
+}
+
 func Test_replaceCondition(t *testing.T) {
 	g := NewWithT(t)
 	timestamp, err := time.Parse(time.UnixDate, "Wed Feb 25 11:06:39 GMT 2015")
@@ -1950,3 +3180,518 @@ func Test_replaceCondition(t *testing.T) {
 		})
 	}
 }
+
+func Test_newReleaseHealth(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name        string
+		resources   []action.ResourceStatus
+		wantHealthy bool
+		wantSummary string
+	}{
+		{
+			name:        "no resources",
+			wantHealthy: true,
+			wantSummary: "0/0 resource(s) not healthy: ",
+		},
+		{
+			name: "all resources healthy",
+			resources: []action.ResourceStatus{
+				{Name: "Deployment/default/a", Status: status.CurrentStatus},
+				{Name: "Service/default/a", Status: status.CurrentStatus},
+			},
+			wantHealthy: true,
+		},
+		{
+			name: "unhealthy resources",
+			resources: []action.ResourceStatus{
+				{Name: "Deployment/default/a", Status: status.InProgressStatus, Message: "Available: 0/1"},
+				{Name: "Service/default/a", Status: status.CurrentStatus},
+				{Name: "ConfigMap/default/a", Status: status.NotFoundStatus, Message: "resource not found"},
+			},
+			wantSummary: "2/3 resource(s) not healthy: Deployment/default/a (InProgress), ConfigMap/default/a (NotFound)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			health := newReleaseHealth(3, tt.resources, now)
+			g.Expect(health.Version).To(Equal(3))
+			g.Expect(health.LastChecked.Time).To(Equal(now))
+			g.Expect(health.Healthy).To(Equal(tt.wantHealthy))
+			g.Expect(health.Resources).To(HaveLen(len(tt.resources)))
+			for i, res := range tt.resources {
+				g.Expect(health.Resources[i]).To(Equal(v2.ResourceHealthStatus{
+					Name:    res.Name,
+					Status:  res.Status.String(),
+					Message: res.Message,
+				}))
+			}
+			if tt.wantSummary != "" {
+				g.Expect(summarizeUnhealthy(health)).To(Equal(tt.wantSummary))
+			}
+		})
+	}
+}
+
+func Test_mustValidateValuesSchema(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{
+		Spec: v2.HelmReleaseSpec{
+			Upgrade: &v2.Upgrade{DisableSchemaValidation: true},
+		},
+	}
+	g.Expect(mustValidateValuesSchema(&Install{}, obj)).To(BeTrue())
+	g.Expect(mustValidateValuesSchema(&Upgrade{}, obj)).To(BeFalse())
+	g.Expect(mustValidateValuesSchema(&Test{}, obj)).To(BeFalse())
+
+	obj.Spec.Install = &v2.Install{DisableSchemaValidation: true}
+	obj.Spec.Upgrade = nil
+	g.Expect(mustValidateValuesSchema(&Install{}, obj)).To(BeFalse())
+	g.Expect(mustValidateValuesSchema(&Upgrade{}, obj)).To(BeTrue())
+}
+
+func Test_mustAssessInstallReadiness(t *testing.T) {
+	gracePeriod := &metav1.Duration{Duration: 10 * time.Minute}
+	deployed := v2.Snapshots{{Name: "release", Version: 1, Status: helmrelease.StatusDeployed.String()}}
+
+	tests := []struct {
+		name     string
+		spec     v2.HelmReleaseSpec
+		status   v2.HelmReleaseStatus
+		wasReady bool
+		previous ReconcilerTypeSet
+		want     bool
+	}{
+		{
+			name:   "no grace period",
+			spec:   v2.HelmReleaseSpec{},
+			status: v2.HelmReleaseStatus{LastAttemptedReleaseAction: v2.ReleaseActionInstall, History: deployed},
+			want:   false,
+		},
+		{
+			name:   "install within grace period not confirmed",
+			spec:   v2.HelmReleaseSpec{Install: &v2.Install{ReadinessGracePeriod: gracePeriod}},
+			status: v2.HelmReleaseStatus{LastAttemptedReleaseAction: v2.ReleaseActionInstall, History: deployed},
+			want:   true,
+		},
+		{
+			name:     "install confirmed ready",
+			spec:     v2.HelmReleaseSpec{Install: &v2.Install{ReadinessGracePeriod: gracePeriod}},
+			status:   v2.HelmReleaseStatus{LastAttemptedReleaseAction: v2.ReleaseActionInstall, History: deployed},
+			wasReady: true,
+			want:     false,
+		},
+		{
+			name:     "install performed during reconciliation",
+			spec:     v2.HelmReleaseSpec{Install: &v2.Install{ReadinessGracePeriod: gracePeriod}},
+			status:   v2.HelmReleaseStatus{LastAttemptedReleaseAction: v2.ReleaseActionInstall, History: deployed},
+			wasReady: true,
+			previous: ReconcilerTypeSet{ReconcilerTypeRelease},
+			want:     true,
+		},
+		{
+			name:   "upgrade",
+			spec:   v2.HelmReleaseSpec{Install: &v2.Install{ReadinessGracePeriod: gracePeriod}},
+			status: v2.HelmReleaseStatus{LastAttemptedReleaseAction: v2.ReleaseActionUpgrade, History: deployed},
+			want:   false,
+		},
+		{
+			name: "failed install",
+			spec: v2.HelmReleaseSpec{Install: &v2.Install{ReadinessGracePeriod: gracePeriod}},
+			status: v2.HelmReleaseStatus{
+				LastAttemptedReleaseAction: v2.ReleaseActionInstall,
+				History:                    v2.Snapshots{{Name: "release", Version: 1, Status: helmrelease.StatusFailed.String()}},
+			},
+			want: false,
+		},
+		{
+			name: "wait disabled",
+			spec: v2.HelmReleaseSpec{Install: &v2.Install{ReadinessGracePeriod: gracePeriod, DisableWait: true}},
+			status: v2.HelmReleaseStatus{
+				LastAttemptedReleaseAction: v2.ReleaseActionInstall,
+				History:                    deployed,
+			},
+			want: false,
+		},
+		{
+			name: "readiness threshold",
+			spec: v2.HelmReleaseSpec{
+				Install:   &v2.Install{ReadinessGracePeriod: gracePeriod},
+				Readiness: &v2.Readiness{Threshold: 80},
+			},
+			status: v2.HelmReleaseStatus{LastAttemptedReleaseAction: v2.ReleaseActionInstall, History: deployed},
+			want:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			req := &Request{Object: &v2.HelmRelease{Spec: tt.spec, Status: tt.status}}
+			g.Expect(mustAssessInstallReadiness(req, tt.wasReady, tt.previous)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_checkDowngrade(t *testing.T) {
+	deployed := func(version string) v2.Snapshots {
+		return v2.Snapshots{{Name: "release", Namespace: "ns", Version: 1, ChartVersion: version, Status: helmrelease.StatusDeployed.String()}}
+	}
+
+	tests := []struct {
+		name           string
+		spec           v2.HelmReleaseSpec
+		status         v2.HelmReleaseStatus
+		pinnedByDigest bool
+		chartVersion   string
+		wantErr        bool
+	}{
+		{
+			name:         "upgrade",
+			status:       v2.HelmReleaseStatus{History: deployed("1.0.0")},
+			chartVersion: "1.1.0",
+		},
+		{
+			name:         "downgrade",
+			status:       v2.HelmReleaseStatus{History: deployed("1.1.0")},
+			chartVersion: "1.0.0",
+			wantErr:      true,
+		},
+		{
+			name:         "downgrade allowed",
+			spec:         v2.HelmReleaseSpec{AllowDowngrade: true},
+			status:       v2.HelmReleaseStatus{History: deployed("1.1.0")},
+			chartVersion: "1.0.0",
+		},
+		{
+			name:         "downgrade to pre-release",
+			status:       v2.HelmReleaseStatus{History: deployed("1.0.0")},
+			chartVersion: "1.0.0-rc.1",
+			wantErr:      true,
+		},
+		{
+			name:         "upgrade from pre-release",
+			status:       v2.HelmReleaseStatus{History: deployed("1.0.0-rc.1")},
+			chartVersion: "1.0.0",
+		},
+		{
+			name:         "upgrade between pre-releases",
+			status:       v2.HelmReleaseStatus{History: deployed("1.0.0-rc.2")},
+			chartVersion: "1.0.0-rc.10",
+		},
+		{
+			name: "downgrade pinned by digest",
+			status: v2.HelmReleaseStatus{
+				History:                     deployed("1.1.0+aaaaaaaaaaaa"),
+				LastAttemptedRevisionDigest: "sha256:bbbbbbbbbbbb",
+			},
+			pinnedByDigest: true,
+			chartVersion:   "1.0.0+bbbbbbbbbbbb",
+		},
+		{
+			name: "OCI downgrade selected by semver",
+			status: v2.HelmReleaseStatus{
+				History:                     deployed("1.1.0+aaaaaaaaaaaa"),
+				LastAttemptedRevisionDigest: "sha256:bbbbbbbbbbbb",
+			},
+			chartVersion: "1.0.0+bbbbbbbbbbbb",
+			wantErr:      true,
+		},
+		{
+			name:         "no release",
+			chartVersion: "1.0.0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			req := &Request{
+				Object:              &v2.HelmRelease{Spec: tt.spec, Status: tt.status},
+				Chart:               testutil.BuildChart(testutil.ChartWithVersion(tt.chartVersion)),
+				ChartPinnedByDigest: tt.pinnedByDigest,
+			}
+			err := checkDowngrade(req)
+			if tt.wantErr {
+				g.Expect(err).To(MatchError(ErrDowngradeBlocked))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
+
+func Test_driftCoolDownRemaining(t *testing.T) {
+	now := time.Now()
+	deployedAt := func(d time.Duration) v2.Snapshots {
+		return v2.Snapshots{{Name: "release", Version: 1, LastDeployed: metav1.NewTime(now.Add(-d))}}
+	}
+
+	tests := []struct {
+		name     string
+		coolDown *metav1.Duration
+		history  v2.Snapshots
+		want     time.Duration
+	}{
+		{
+			name:    "no cool-down",
+			history: deployedAt(time.Minute),
+			want:    0,
+		},
+		{
+			name:     "within cool-down",
+			coolDown: &metav1.Duration{Duration: 5 * time.Minute},
+			history:  deployedAt(time.Minute),
+			want:     4 * time.Minute,
+		},
+		{
+			name:     "after cool-down",
+			coolDown: &metav1.Duration{Duration: 5 * time.Minute},
+			history:  deployedAt(10 * time.Minute),
+			want:     0,
+		},
+		{
+			name:     "no release",
+			coolDown: &metav1.Duration{Duration: 5 * time.Minute},
+			want:     0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &v2.HelmRelease{
+				Spec: v2.HelmReleaseSpec{
+					DriftDetection: &v2.DriftDetection{
+						Mode:     v2.DriftDetectionEnabled,
+						CoolDown: tt.coolDown,
+					},
+				},
+				Status: v2.HelmReleaseStatus{History: tt.history},
+			}
+			g.Expect(driftCoolDownRemaining(obj, now)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestAtomicRelease_reportFailedResourcesKept(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{
+		Spec: v2.HelmReleaseSpec{
+			Upgrade: &v2.Upgrade{
+				Remediation: &v2.UpgradeRemediation{KeepFailedResources: true},
+			},
+		},
+		Status: v2.HelmReleaseStatus{
+			History: v2.Snapshots{
+				{Name: mockReleaseName, Namespace: mockReleaseNamespace, Version: 2, Status: helmrelease.StatusFailed.String(), ChartName: "hello", ChartVersion: "0.1.0"},
+			},
+		},
+	}
+	req := &Request{Object: obj}
+
+	recorder := testutil.NewFakeRecorder(10, false)
+	r := &AtomicRelease{eventRecorder: recorder}
+
+	r.reportFailedResourcesKept(req)
+	g.Expect(conditions.IsFalse(obj, v2.RemediatedCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(obj, v2.RemediatedCondition)).To(Equal(v2.FailedResourcesKeptReason))
+	g.Expect(conditions.GetMessage(obj, v2.RemediatedCondition)).To(ContainSubstring("hello@0.1.0 are kept for inspection"))
+
+	summarize(req)
+	g.Expect(conditions.IsFalse(obj, meta.ReadyCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(obj, meta.ReadyCondition)).To(Equal(v2.FailedResourcesKeptReason))
+
+	// The event is only emitted the first time the failed release is kept.
+	r.reportFailedResourcesKept(req)
+	g.Expect(recorder.GetEvents()).To(ConsistOf(
+		WithTransform(func(e corev1.Event) string { return e.Reason }, Equal(v2.FailedResourcesKeptReason)),
+	))
+}
+
+func TestAtomicRelease_recordHistoryLimit(t *testing.T) {
+	g := NewWithT(t)
+
+	cfg, err := action.NewConfigFactory(&kube.MemoryRESTClientGetter{},
+		action.WithStorage(helmdriver.MemoryDriverName, mockReleaseNamespace),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+	store := helmstorage.Init(cfg.Driver)
+
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{Name: mockReleaseName, Namespace: mockReleaseNamespace},
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			StorageNamespace: mockReleaseNamespace,
+			MaxHistory:       ptr.To(2),
+		},
+	}
+	req := &Request{Object: obj}
+	recorder := testutil.NewFakeRecorder(10, false)
+	r := &AtomicRelease{configFactory: cfg, eventRecorder: recorder}
+
+	addRelease := func(version int, status helmrelease.Status) {
+		rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+			Name:      mockReleaseName,
+			Namespace: mockReleaseNamespace,
+			Version:   version,
+			Status:    status,
+			Chart:     testutil.BuildChart(),
+		})
+		g.Expect(store.Create(rls)).To(Succeed())
+		obj.Status.History = append(v2.Snapshots{release.ObservedToSnapshot(release.ObserveRelease(rls))}, obj.Status.History...)
+	}
+
+	// Below the boundary, there is no rollback target to prune.
+	addRelease(1, helmrelease.StatusDeployed)
+	g.Expect(r.recordHistoryLimit(req)).To(Succeed())
+	g.Expect(conditions.Has(obj, v2.HistoryLimitReachedCondition)).To(BeFalse())
+
+	// At the boundary, the next release prunes the rollback target.
+	obj.Status.History[0].Status = helmrelease.StatusSuperseded.String()
+	addRelease(2, helmrelease.StatusDeployed)
+	g.Expect(r.recordHistoryLimit(req)).To(Succeed())
+	g.Expect(conditions.IsTrue(obj, v2.HistoryLimitReachedCondition)).To(BeTrue())
+	g.Expect(conditions.GetReason(obj, v2.HistoryLimitReachedCondition)).To(Equal(v2.MaxHistoryReachedReason))
+	g.Expect(conditions.GetMessage(obj, v2.HistoryLimitReachedCondition)).To(ContainSubstring("rollback target mock-ns/mock-release.v1"))
+
+	// The event is only emitted when the condition is first set.
+	g.Expect(r.recordHistoryLimit(req)).To(Succeed())
+	g.Expect(recorder.GetEvents()).To(ConsistOf(
+		WithTransform(func(e corev1.Event) string { return e.Reason }, Equal(v2.MaxHistoryReachedReason)),
+	))
+
+	// Raising the MaxHistory clears the condition.
+	obj.Spec.MaxHistory = ptr.To(3)
+	g.Expect(r.recordHistoryLimit(req)).To(Succeed())
+	g.Expect(conditions.Has(obj, v2.HistoryLimitReachedCondition)).To(BeFalse())
+}
+
+func TestAtomicRelease_mustDeferToMaintenanceWindow(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           []AtomicReleaseOption
+		forceRequested bool
+		next           ActionReconciler
+		want           bool
+	}{
+		{
+			name: "window open",
+			next: &Upgrade{},
+			want: false,
+		},
+		{
+			name: "install outside window",
+			opts: []AtomicReleaseOption{WithMaintenanceWindowClosed(time.Now().Add(time.Hour), false)},
+			next: &Install{},
+			want: true,
+		},
+		{
+			name: "upgrade outside window",
+			opts: []AtomicReleaseOption{WithMaintenanceWindowClosed(time.Now().Add(time.Hour), false)},
+			next: &Upgrade{},
+			want: true,
+		},
+		{
+			name: "drift correction outside window",
+			opts: []AtomicReleaseOption{WithMaintenanceWindowClosed(time.Now().Add(time.Hour), false)},
+			next: &CorrectClusterDrift{},
+			want: true,
+		},
+		{
+			name: "drift correction outside window with bypass",
+			opts: []AtomicReleaseOption{WithMaintenanceWindowClosed(time.Now().Add(time.Hour), true)},
+			next: &CorrectClusterDrift{},
+			want: false,
+		},
+		{
+			name:           "forced upgrade outside window",
+			opts:           []AtomicReleaseOption{WithMaintenanceWindowClosed(time.Now().Add(time.Hour), false)},
+			forceRequested: true,
+			next:           &Upgrade{},
+			want:           true,
+		},
+		{
+			name:           "forced upgrade outside window with bypass",
+			opts:           []AtomicReleaseOption{WithMaintenanceWindowClosed(time.Now().Add(time.Hour), true)},
+			forceRequested: true,
+			next:           &Upgrade{},
+			want:           false,
+		},
+		{
+			name: "upgrade outside window with bypass",
+			opts: []AtomicReleaseOption{WithMaintenanceWindowClosed(time.Now().Add(time.Hour), true)},
+			next: &Upgrade{},
+			want: true,
+		},
+		{
+			name: "test outside window",
+			opts: []AtomicReleaseOption{WithMaintenanceWindowClosed(time.Now().Add(time.Hour), false)},
+			next: &Test{},
+			want: false,
+		},
+		{
+			name: "remediation outside window",
+			opts: []AtomicReleaseOption{WithMaintenanceWindowClosed(time.Now().Add(time.Hour), false)},
+			next: &RollbackRemediation{},
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := NewAtomicRelease(nil, nil, nil, "helm-controller", tt.opts...)
+			r.forceRequested = tt.forceRequested
+			g.Expect(r.mustDeferToMaintenanceWindow(tt.next)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestAtomicRelease_lazyRenderRelease(t *testing.T) {
+	g := NewWithT(t)
+
+	namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	t.Cleanup(func() {
+		_ = testEnv.Delete(context.TODO(), namedNS)
+	})
+	releaseNamespace := namedNS.Name
+
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mockReleaseName,
+			Namespace: releaseNamespace,
+		},
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			TargetNamespace:  releaseNamespace,
+			StorageNamespace: releaseNamespace,
+		},
+	}
+
+	getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cfg, err := action.NewConfigFactory(getter,
+		action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	r := &AtomicRelease{configFactory: cfg}
+	render := r.lazyRenderRelease(context.TODO(), &Request{Object: obj, Chart: testutil.BuildChart()})
+
+	first, err := render()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(first.Manifest).ToNot(BeEmpty())
+
+	// Subsequent calls share the result of the first render.
+	second, err := render()
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(second).To(BeIdenticalTo(first))
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// recoveryReasons are the reasons of the Normal events which reset the
+// suppression of Warning events for an object.
+var recoveryReasons = []string{
+	v2.InstallSucceededReason,
+	v2.UpgradeSucceededReason,
+	v2.TestSucceededReason,
+	v2.RollbackSucceededReason,
+	v2.UninstallSucceededReason,
+}
+
+// DedupEventRecorder is a record.EventRecorder which suppresses repeated
+// identical Warning events for an object. A Warning event is identical to
+// the previous Warning event for the object if it has the same reason and
+// message. It is emitted again once the interval has passed since it was
+// last emitted.
+//
+// Warning events with a different reason or message, and Normal events, are
+// never suppressed. A Normal event for a successful Helm action resets the
+// suppression for the object, so that the next failure is emitted. Other
+// Normal events, e.g. reporting progress, do not reset the suppression.
+type DedupEventRecorder struct {
+	record.EventRecorder
+
+	interval time.Duration
+	now      func() time.Time
+
+	mu   sync.Mutex
+	last map[string]emittedEvent
+}
+
+// emittedEvent is the last Warning event emitted for an object.
+type emittedEvent struct {
+	fingerprint string
+	time        time.Time
+}
+
+// NewDedupEventRecorder returns a DedupEventRecorder emitting events using
+// the given recorder, and emitting identical Warning events at most once per
+// interval.
+func NewDedupEventRecorder(recorder record.EventRecorder, interval time.Duration) *DedupEventRecorder {
+	return &DedupEventRecorder{
+		EventRecorder: recorder,
+		interval:      interval,
+		now:           time.Now,
+		last:          make(map[string]emittedEvent),
+	}
+}
+
+// Event emits the event, unless it is suppressed.
+func (r *DedupEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, "%s", message)
+}
+
+// Eventf emits the event, unless it is suppressed.
+func (r *DedupEventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, messageFmt, args...)
+}
+
+// AnnotatedEventf emits the event, unless it is suppressed.
+func (r *DedupEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	if r.mustSuppress(object, eventtype, reason, fmt.Sprintf(messageFmt, args...)) {
+		return
+	}
+	r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+}
+
+// mustSuppress returns true if the event is a Warning event identical to the
+// previous Warning event emitted for the object within the interval. It
+// records the event if it is not suppressed.
+func (r *DedupEventRecorder) mustSuppress(object runtime.Object, eventtype, reason, message string) bool {
+	key, ok := eventObjectKey(object)
+	if !ok {
+		return false
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if eventtype != corev1.EventTypeWarning {
+		if isRecovery(reason, message) {
+			delete(r.last, key)
+		}
+		return false
+	}
+
+	now := r.now()
+	fingerprint := fmt.Sprintf("%s/%x", reason, sha256.Sum256([]byte(message)))
+	if last, ok := r.last[key]; ok && last.fingerprint == fingerprint && now.Sub(last.time) < r.interval {
+		return true
+	}
+
+	// Forget about events which can no longer be suppressed, to not hold on
+	// to the events of deleted objects.
+	for k, last := range r.last {
+		if now.Sub(last.time) >= r.interval {
+			delete(r.last, k)
+		}
+	}
+	r.last[key] = emittedEvent{fingerprint: fingerprint, time: now}
+	return false
+}
+
+// isRecovery returns true if the Normal event with the given reason and
+// message reports a successful Helm action. For a consolidated event with the
+// ReconcileSummaryReason, the reasons of the events it summarizes are
+// checked.
+func isRecovery(reason, message string) bool {
+	for _, r := range recoveryReasons {
+		if reason == r {
+			return true
+		}
+		if reason == ReconcileSummaryReason && (strings.HasPrefix(message, r+":") || strings.Contains(message, "\n"+r+":")) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventObjectKey returns the key of the given object, or false if it does
+// not have object metadata.
+func eventObjectKey(object runtime.Object) (string, bool) {
+	obj, err := apimeta.Accessor(object)
+	if err != nil {
+		return "", false
+	}
+	return fmt.Sprintf("%s/%s/%s", obj.GetNamespace(), obj.GetName(), obj.GetUID()), true
+}
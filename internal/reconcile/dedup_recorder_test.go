@@ -0,0 +1,106 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/testutil"
+)
+
+func TestDedupEventRecorder(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "default"}}
+	other := &v2.HelmRelease{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "default"}}
+
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	recorder := testutil.NewFakeRecorder(10, false)
+	r := NewDedupEventRecorder(recorder, time.Hour)
+	r.now = func() time.Time { return now }
+
+	warning := corev1.Event{Type: corev1.EventTypeWarning, Reason: v2.UpgradeFailedReason, Message: "upgrade failed"}
+
+	// The first failure is emitted, identical failures are suppressed.
+	r.Eventf(obj, corev1.EventTypeWarning, v2.UpgradeFailedReason, "upgrade %s", "failed")
+	r.Eventf(obj, corev1.EventTypeWarning, v2.UpgradeFailedReason, "upgrade %s", "failed")
+	g.Expect(recorder.GetEvents()).To(Equal([]corev1.Event{warning}))
+
+	// The failure of another object is emitted.
+	r.Event(other, corev1.EventTypeWarning, v2.UpgradeFailedReason, "upgrade failed")
+	g.Expect(recorder.GetEvents()).To(Equal([]corev1.Event{warning}))
+
+	// A different failure is emitted.
+	r.Event(obj, corev1.EventTypeWarning, v2.UpgradeFailedReason, "upgrade timed out")
+	r.Event(obj, corev1.EventTypeWarning, v2.RollbackFailedReason, "upgrade timed out")
+	g.Expect(recorder.GetEvents()).To(HaveLen(2))
+
+	// An identical failure is emitted again after the interval.
+	r.Event(obj, corev1.EventTypeWarning, v2.RollbackFailedReason, "upgrade timed out")
+	g.Expect(recorder.GetEvents()).To(BeEmpty())
+	now = now.Add(time.Hour)
+	r.Event(obj, corev1.EventTypeWarning, v2.RollbackFailedReason, "upgrade timed out")
+	g.Expect(recorder.GetEvents()).To(HaveLen(1))
+
+	// A recovery is always emitted, and resets the suppression.
+	r.Event(obj, corev1.EventTypeNormal, v2.UpgradeSucceededReason, "upgrade succeeded")
+	r.Event(obj, corev1.EventTypeNormal, v2.UpgradeSucceededReason, "upgrade succeeded")
+	g.Expect(recorder.GetEvents()).To(HaveLen(2))
+	r.Event(obj, corev1.EventTypeWarning, v2.RollbackFailedReason, "upgrade timed out")
+	g.Expect(recorder.GetEvents()).To(HaveLen(1))
+
+	// Events of which the suppression expired are forgotten.
+	g.Expect(r.last).To(HaveLen(1))
+}
+
+func TestDedupEventRecorder_NormalEvents(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{ObjectMeta: metav1.ObjectMeta{Name: "release", Namespace: "default"}}
+
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+	recorder := testutil.NewFakeRecorder(10, false)
+	r := NewDedupEventRecorder(recorder, time.Hour)
+	r.now = func() time.Time { return now }
+
+	// Normal events which do not report a recovery are emitted, but do not
+	// reset the suppression of identical failures.
+	r.Event(obj, corev1.EventTypeNormal, "Progressing", "waiting for resources")
+	r.Event(obj, corev1.EventTypeWarning, v2.UpgradeFailedReason, "upgrade failed")
+	r.Event(obj, corev1.EventTypeNormal, "Progressing", "waiting for resources")
+	r.Event(obj, corev1.EventTypeWarning, v2.UpgradeFailedReason, "upgrade failed")
+	g.Expect(recorder.GetEvents()).To(Equal([]corev1.Event{
+		{Type: corev1.EventTypeNormal, Reason: "Progressing", Message: "waiting for resources"},
+		{Type: corev1.EventTypeWarning, Reason: v2.UpgradeFailedReason, Message: "upgrade failed"},
+		{Type: corev1.EventTypeNormal, Reason: "Progressing", Message: "waiting for resources"},
+	}))
+
+	// A summary of a successful action resets the suppression.
+	r.Event(obj, corev1.EventTypeNormal, ReconcileSummaryReason, "Progressing: waiting for resources")
+	r.Event(obj, corev1.EventTypeWarning, v2.UpgradeFailedReason, "upgrade failed")
+	g.Expect(recorder.GetEvents()).To(HaveLen(1))
+	r.Event(obj, corev1.EventTypeNormal, ReconcileSummaryReason,
+		"Progressing: waiting for resources\n"+v2.TestSucceededReason+": test succeeded")
+	r.Event(obj, corev1.EventTypeWarning, v2.UpgradeFailedReason, "upgrade failed")
+	g.Expect(recorder.GetEvents()).To(HaveLen(2))
+}
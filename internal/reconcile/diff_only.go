@@ -0,0 +1,198 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/fluxcd/pkg/runtime/logger"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/chartutil"
+	"github.com/fluxcd/helm-controller/internal/diff"
+	"github.com/fluxcd/helm-controller/internal/digest"
+)
+
+// DiffOnly is an ActionReconciler which performs a server-side dry-run of
+// the Helm install or upgrade action the Request.Object would otherwise
+// perform, and records the changes it would make to the resources of the
+// release in the Status.LastDiff.
+//
+// An upgrade is dry-run if a release exists in the Helm storage, in which
+// case the manifest of the last release is compared to the candidate
+// manifest. Otherwise, an install is dry-run.
+//
+// On success, the object is marked with Ready=False with DiffOnlyReason,
+// as the desired state has not been applied, and an event summarizing the
+// changes is emitted. On failure, the object is marked with Ready=False and
+// a warning event is emitted. Neither the Helm storage nor the
+// Status.History are modified.
+type DiffOnly struct {
+	configFactory *action.ConfigFactory
+	eventRecorder record.EventRecorder
+}
+
+// NewDiffOnly returns a new DiffOnly reconciler configured with the provided
+// values.
+func NewDiffOnly(cfg *action.ConfigFactory, recorder record.EventRecorder) *DiffOnly {
+	return &DiffOnly{configFactory: cfg, eventRecorder: recorder}
+}
+
+func (r *DiffOnly) Reconcile(ctx context.Context, req *Request) error {
+	var (
+		logBuf = action.NewLogBuffer(action.NewDebugLog(ctrl.LoggerFrom(ctx).V(logger.DebugLevel)), 10)
+		cfg    = r.configFactory.Build(logBuf.Log)
+
+		releaseAction = v2.ReleaseActionInstall
+		current       string
+		rls           *helmrelease.Release
+	)
+
+	last, err := action.LastRelease(cfg, req.Object.GetReleaseName())
+	if err != nil && !errors.Is(err, action.ErrReleaseNotFound) {
+		r.failure(req, releaseAction, logBuf, err)
+		return err
+	}
+	if last != nil {
+		releaseAction = v2.ReleaseActionUpgrade
+		current = last.Manifest
+		rls, err = action.DryRunUpgrade(ctx, cfg, req.Object, req.Chart, req.Values)
+	} else {
+		rls, err = action.DryRunInstall(ctx, cfg, req.Object, req.Chart, req.Values)
+	}
+	if err != nil {
+		r.failure(req, releaseAction, logBuf, err)
+		return err
+	}
+
+	changes, err := diff.Manifests(current, rls.Manifest)
+	if err != nil {
+		r.failure(req, releaseAction, logBuf, err)
+		return err
+	}
+
+	req.Object.Status.LastDiff = &v2.ReleaseDiff{
+		Action:       releaseAction,
+		ChartName:    req.Chart.Name(),
+		ChartVersion: req.Chart.Metadata.Version,
+		ConfigDigest: chartutil.DigestValues(digest.Canonical, req.Values).String(),
+		Time:         metav1.Now(),
+		Changes:      changes,
+	}
+	r.success(req)
+	return nil
+}
+
+func (r *DiffOnly) Name() string {
+	return "diff"
+}
+
+func (r *DiffOnly) Type() ReconcilerType {
+	return ReconcilerTypeDiff
+}
+
+const (
+	// fmtDiffFailure is the message format for a dry-run failure.
+	fmtDiffFailure = "Helm %s dry-run failed for release %s/%s with chart %s@%s: %s"
+	// fmtDiffSuccess is the message format for a successful dry-run.
+	fmtDiffSuccess = "Helm %s of release %s/%s with chart %s@%s would %s"
+)
+
+// failure records the failure of the dry-run in the status of the given
+// Request.Object by marking Ready=False, and emits a warning event.
+func (r *DiffOnly) failure(req *Request, releaseAction v2.ReleaseAction, buffer *action.LogBuffer, err error) {
+	msg := fmt.Sprintf(fmtDiffFailure, releaseAction, req.Object.GetReleaseNamespace(), req.Object.GetReleaseName(),
+		req.Chart.Name(), req.Chart.Metadata.Version, releaseErrorMessage(req, err))
+
+	conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.DiffFailedReason, "%s", msg)
+	r.eventRecorder.AnnotatedEventf(
+		req.Object,
+		eventMeta(req.Chart.Metadata.Version, chartutil.DigestValues(digest.Canonical, req.Values).String(),
+			addAppVersion(req.Chart.AppVersion()), addOCIDigest(req.Object.Status.LastAttemptedRevisionDigest)),
+		corev1.EventTypeWarning,
+		v2.DiffFailedReason,
+		eventMessageWithLog(msg, buffer),
+	)
+}
+
+// success records the result of the dry-run in the status of the given
+// Request.Object by marking Ready=False with DiffOnlyReason, and emits an
+// event listing the changes.
+func (r *DiffOnly) success(req *Request) {
+	d := req.Object.Status.LastDiff
+	msg := fmt.Sprintf(fmtDiffSuccess, d.Action, req.Object.GetReleaseNamespace(), req.Object.GetReleaseName(),
+		d.ChartName, d.ChartVersion, summarizeChanges(d.Changes))
+
+	conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.DiffOnlyReason, "%s", msg)
+
+	eventMsg := msg
+	if len(d.Changes) > 0 {
+		var sb strings.Builder
+		for _, c := range d.Changes {
+			sb.WriteString(fmt.Sprintf("\n%s %s", c.Type, resourceChangeName(c)))
+		}
+		eventMsg += "\n" + sb.String()
+	}
+	r.eventRecorder.AnnotatedEventf(
+		req.Object,
+		eventMeta(d.ChartVersion, d.ConfigDigest, addAppVersion(req.Chart.AppVersion()),
+			addOCIDigest(req.Object.Status.LastAttemptedRevisionDigest)),
+		corev1.EventTypeNormal,
+		v2.DiffOnlyReason,
+		eventMsg,
+	)
+}
+
+// summarizeChanges returns a short summary of the number of changes of each
+// type.
+func summarizeChanges(changes []v2.ResourceChange) string {
+	if len(changes) == 0 {
+		return "not change any resources"
+	}
+	count := map[string]int{}
+	for _, c := range changes {
+		count[c.Type]++
+	}
+	var parts []string
+	for _, t := range []string{v2.ResourceChangeCreate, v2.ResourceChangeUpdate, v2.ResourceChangeDelete} {
+		if n := count[t]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%s %d", t, n))
+		}
+	}
+	return strings.Join(parts, ", ") + " resource(s)"
+}
+
+// resourceChangeName returns the name of the resource of the given change
+// in the format 'Kind/namespace/name'.
+func resourceChangeName(c v2.ResourceChange) string {
+	if c.Namespace == "" {
+		return fmt.Sprintf("%s/%s", c.Kind, c.Name)
+	}
+	return fmt.Sprintf("%s/%s/%s", c.Kind, c.Namespace, c.Name)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmstorage "helm.sh/helm/v3/pkg/storage"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/testutil"
+)
+
+func TestDiffOnly_Reconcile(t *testing.T) {
+	tests := []struct {
+		name string
+		// releases is the list of releases that are stored in the driver
+		// before the dry-run.
+		releases func(namespace string) []*helmrelease.Release
+		// wantAction is the expected action of the diff.
+		wantAction v2.ReleaseAction
+		// wantChanges is the expected list of changes of the diff.
+		wantChanges func(namespace string) []v2.ResourceChange
+	}{
+		{
+			name:       "dry-run install",
+			wantAction: v2.ReleaseActionInstall,
+			wantChanges: func(namespace string) []v2.ResourceChange {
+				return []v2.ResourceChange{
+					{Type: v2.ResourceChangeCreate, APIVersion: "v1", Kind: "ConfigMap", Namespace: namespace, Name: "cm"},
+				}
+			},
+		},
+		{
+			name: "dry-run upgrade",
+			releases: func(namespace string) []*helmrelease.Release {
+				return []*helmrelease.Release{
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Namespace: namespace,
+						Chart:     testutil.BuildChart(),
+						Version:   1,
+						Status:    helmrelease.StatusDeployed,
+					}),
+				}
+			},
+			wantAction: v2.ReleaseActionUpgrade,
+			wantChanges: func(namespace string) []v2.ResourceChange {
+				return []v2.ResourceChange{
+					{Type: v2.ResourceChangeCreate, APIVersion: "v1", Kind: "ConfigMap", Namespace: namespace, Name: "cm"},
+					{Type: v2.ResourceChangeDelete, APIVersion: "v1", Kind: "Secret", Name: "fixture"},
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			t.Cleanup(func() {
+				_ = testEnv.Delete(context.TODO(), namedNS)
+			})
+			releaseNamespace := namedNS.Name
+
+			obj := &v2.HelmRelease{
+				Spec: v2.HelmReleaseSpec{
+					ReleaseName:      mockReleaseName,
+					TargetNamespace:  releaseNamespace,
+					StorageNamespace: releaseNamespace,
+					Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+					DiffOnly:         true,
+				},
+			}
+
+			getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+			g.Expect(err).ToNot(HaveOccurred())
+
+			cfg, err := action.NewConfigFactory(getter,
+				action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+			)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			store := helmstorage.Init(cfg.Driver)
+			var releases []*helmrelease.Release
+			if tt.releases != nil {
+				releases = tt.releases(releaseNamespace)
+			}
+			for _, r := range releases {
+				g.Expect(store.Create(r)).To(Succeed())
+			}
+
+			recorder := testutil.NewFakeRecorder(10, false)
+			got := NewDiffOnly(cfg, recorder).Reconcile(context.TODO(), &Request{
+				Object: obj,
+				Chart:  testutil.BuildChart(),
+			})
+			g.Expect(got).ToNot(HaveOccurred())
+
+			g.Expect(obj.Status.LastDiff).ToNot(BeNil())
+			g.Expect(obj.Status.LastDiff.Action).To(Equal(tt.wantAction))
+			g.Expect(obj.Status.LastDiff.Changes).To(Equal(tt.wantChanges(releaseNamespace)))
+			g.Expect(conditions.GetReason(obj, meta.ReadyCondition)).To(Equal(v2.DiffOnlyReason))
+			g.Expect(recorder.GetEvents()).To(HaveLen(1))
+
+			// The dry-run must not have modified the Helm storage.
+			history, _ := store.History(mockReleaseName)
+			g.Expect(history).To(HaveLen(len(releases)))
+			g.Expect(obj.Status.History).To(BeEmpty())
+		})
+	}
+}
+
+func Test_summarizeChanges(t *testing.T) {
+	tests := []struct {
+		name    string
+		changes []v2.ResourceChange
+		want    string
+	}{
+		{
+			name: "no changes",
+			want: "not change any resources",
+		},
+		{
+			name: "changes",
+			changes: []v2.ResourceChange{
+				{Type: v2.ResourceChangeDelete},
+				{Type: v2.ResourceChangeCreate},
+				{Type: v2.ResourceChangeCreate},
+			},
+			want: "create 2, delete 1 resource(s)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(summarizeChanges(tt.changes)).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+var (
+	// driftDetectedTotal counts the drift checks which detected drift of the
+	// cluster state from the Helm release.
+	driftDetectedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gotk_release_drift_detected_total",
+			Help: "The number of drift checks which detected drift of the cluster state from the release of an object.",
+		},
+		[]string{"kind", "name", "namespace"},
+	)
+
+	// driftCorrectedTotal counts the successful corrections of cluster
+	// state drift.
+	driftCorrectedTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gotk_release_drift_corrected_total",
+			Help: "The number of times drift of the cluster state from the release of an object was corrected.",
+		},
+		[]string{"kind", "name", "namespace"},
+	)
+
+	// driftCheckTimestampSeconds records the time of the last successful
+	// comparison of the cluster state with the Helm release. The time since
+	// the last check can be computed with e.g. time() - metric in PromQL.
+	driftCheckTimestampSeconds = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gotk_release_drift_check_timestamp_seconds",
+			Help: "The Unix time of the last successful drift check of the release of an object.",
+		},
+		[]string{"kind", "name", "namespace"},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(driftDetectedTotal, driftCorrectedTotal, driftCheckTimestampSeconds)
+}
+
+// recordDriftCheck records the outcome of a successful drift check for the
+// given HelmRelease at the given time.
+func recordDriftCheck(obj *v2.HelmRelease, drifted bool, now time.Time) {
+	driftCheckTimestampSeconds.WithLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace).Set(float64(now.Unix()))
+	if drifted {
+		driftDetectedTotal.WithLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace).Inc()
+	}
+}
+
+// recordDriftCorrected records the successful correction of drift for the
+// given HelmRelease.
+func recordDriftCorrected(obj *v2.HelmRelease) {
+	driftCorrectedTotal.WithLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace).Inc()
+}
+
+// DeleteDriftMetrics removes the drift metrics for the given HelmRelease.
+func DeleteDriftMetrics(obj *v2.HelmRelease) {
+	driftDetectedTotal.DeleteLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)
+	driftCorrectedTotal.DeleteLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)
+	driftCheckTimestampSeconds.DeleteLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)
+}
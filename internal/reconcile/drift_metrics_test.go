@@ -0,0 +1,65 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func Test_recordDriftMetrics(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "drift-metrics",
+			Namespace: "default",
+		},
+	}
+	detected := driftDetectedTotal.WithLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)
+	corrected := driftCorrectedTotal.WithLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)
+	checked := driftCheckTimestampSeconds.WithLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)
+	t.Cleanup(func() { DeleteDriftMetrics(obj) })
+
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.UTC)
+
+	// A check without drift only records the time of the check.
+	recordDriftCheck(obj, false, now)
+	g.Expect(promtestutil.ToFloat64(detected)).To(BeZero())
+	g.Expect(promtestutil.ToFloat64(checked)).To(Equal(float64(now.Unix())))
+
+	// Drift is counted for every check which detects it.
+	now = now.Add(time.Minute)
+	recordDriftCheck(obj, true, now)
+	recordDriftCheck(obj, true, now)
+	g.Expect(promtestutil.ToFloat64(detected)).To(Equal(float64(2)))
+	g.Expect(promtestutil.ToFloat64(checked)).To(Equal(float64(now.Unix())))
+
+	recordDriftCorrected(obj)
+	g.Expect(promtestutil.ToFloat64(corrected)).To(Equal(float64(1)))
+
+	DeleteDriftMetrics(obj)
+	g.Expect(driftDetectedTotal.DeleteLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)).To(BeFalse())
+	g.Expect(driftCorrectedTotal.DeleteLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)).To(BeFalse())
+	g.Expect(driftCheckTimestampSeconds.DeleteLabelValues(v2.HelmReleaseKind, obj.Name, obj.Namespace)).To(BeFalse())
+}
@@ -19,9 +19,10 @@ package reconcile
 import (
 	"context"
 	"fmt"
-	"strings"
+	"time"
 
 	"github.com/fluxcd/pkg/runtime/logger"
+	helmrelease "helm.sh/helm/v3/pkg/release"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -71,7 +72,7 @@ func (r *Install) Reconcile(ctx context.Context, req *Request) error {
 	var (
 		logBuf      = action.NewLogBuffer(action.NewDebugLog(ctrl.LoggerFrom(ctx).V(logger.DebugLevel)), 10)
 		obsReleases = make(observedReleases)
-		cfg         = r.configFactory.Build(logBuf.Log, observeRelease(obsReleases))
+		cfg         = r.configFactory.Build(logBuf.Log, observeRelease(obsReleases, time.Now()))
 	)
 
 	defer summarize(req)
@@ -88,13 +89,42 @@ func (r *Install) Reconcile(ctx context.Context, req *Request) error {
 	conditions.Delete(req.Object, v2.TestSuccessCondition)
 	conditions.Delete(req.Object, v2.RemediatedCondition)
 
+	// Create the target namespace, and record it when it was created for
+	// the release, to only delete namespaces made by the controller.
+	created, err := action.CreateNamespace(cfg, req.Object)
+	if err != nil {
+		r.failure(req, logBuf, err)
+		return err
+	}
+	if created {
+		req.Object.Status.CreatedNamespace = req.Object.Spec.TargetNamespace
+	}
+
 	// Run the Helm install action.
-	_, err := action.Install(ctx, cfg, req.Object, req.Chart, req.Values)
+	_, err = action.Install(ctx, cfg, req.Object, req.Chart, req.Values)
 
-	// Record the history of releases observed during the install.
-	obsReleases.recordOnObject(req.Object, mutateOCIDigest)
+	// Record the history and notes of the releases observed during the
+	// install.
+	obsReleases.recordOnObject(req.Object, mutateOCIDigest, mutateValues(req), mutateVerifiedDigest(req), mutateChartSource(req),
+		mutateCRDsPolicy(req.Object.GetInstall().CRDs))
+	obsReleases.recordNotesOnObject(req.Object)
+
+	// Prune the releases which exceed the retention of the history.
+	if pruneErr := pruneHistory(r.configFactory.Build(nil), req.Object, time.Now()); pruneErr != nil {
+		ctrl.LoggerFrom(ctx).Error(pruneErr, "failed to prune release history")
+	}
 
 	if err != nil {
+		// Uninstall the release of a failed atomic install before recording
+		// the failure, to allow the next attempt to start from a clean state.
+		if req.Object.GetInstall().Atomic && len(obsReleases) > 0 {
+			if uninstallErr := r.uninstallAtomic(ctx, req, logBuf); uninstallErr != nil {
+				err = fmt.Errorf("%w (atomic uninstall failed: %s)", err, uninstallErr)
+			} else {
+				err = fmt.Errorf("%w (release uninstalled)", err)
+			}
+		}
+
 		r.failure(req, logBuf, err)
 
 		// Return error if we did not store a release, as this does not
@@ -109,14 +139,49 @@ func (r *Install) Reconcile(ctx context.Context, req *Request) error {
 		// without a new release in storage there is nothing to remediate,
 		// and the action can be retried immediately without causing
 		// storage drift.
-		req.Object.GetInstall().GetRemediation().IncrementFailureCount(req.Object)
+		if !countFailure(req.Object, req.Object.GetInstall().GetRemediation(), time.Now()) {
+			ctrl.LoggerFrom(ctx).Info(fmt.Sprintf("not counting install failure within retry delay of %s",
+				req.Object.GetInstall().GetRemediation().GetRetryDelay().String()))
+		}
 		return nil
 	}
 
+	// Record the inventory of the resources of the release. The resources
+	// of a previous inventory which was kept across a change of the release
+	// target are pruned when they are absent from the installed release.
+	prevInventory := req.Object.Status.Inventory
+	if invErr := obsReleases.recordInventoryOnObject(r.configFactory.Build(nil), req.Object); invErr != nil {
+		ctrl.LoggerFrom(ctx).Error(invErr, "failed to record resource inventory")
+	} else if req.Object.GetUpgrade().Prune {
+		pruneOrphans(ctx, r.configFactory, r.eventRecorder, req, prevInventory,
+			req.Object.Status.History.Latest().ConfigDigest)
+	}
+
 	r.success(req)
 	return nil
 }
 
+// uninstallAtomic uninstalls the latest release of the Request.Object, and
+// purges it from the Helm storage. The uninstall is observed and recorded in
+// the Status.History of the Request.Object.
+func (r *Install) uninstallAtomic(ctx context.Context, req *Request, buffer *action.LogBuffer) error {
+	cur := req.Object.Status.History.Latest()
+	if cur == nil {
+		return fmt.Errorf("%w: required to uninstall", ErrNoLatest)
+	}
+
+	cfg := r.configFactory.Build(buffer.Log, observeUninstall(req.Object))
+	if _, err := action.Uninstall(ctx, cfg, req.Object, cur.Name, action.WithoutUninstallHistory()); err != nil {
+		return err
+	}
+
+	// The release is purged from the storage, which is observed while it
+	// is still uninstalling.
+	req.Object.Status.History.Latest().Status = helmrelease.StatusUninstalled.String()
+	req.Object.Status.History.Latest().StatusReason = "Uninstallation complete"
+	return nil
+}
+
 func (r *Install) Name() string {
 	return "install"
 }
@@ -144,11 +209,13 @@ const (
 func (r *Install) failure(req *Request, buffer *action.LogBuffer, err error) {
 	// Compose failure message.
 	msg := fmt.Sprintf(fmtInstallFailure, req.Object.GetReleaseNamespace(), req.Object.GetReleaseName(), req.Chart.Name(),
-		req.Chart.Metadata.Version, strings.TrimSpace(err.Error()))
+		req.Chart.Metadata.Version, releaseErrorMessage(req, err))
 
 	// Mark install failure on object.
 	req.Object.Status.Failures++
-	conditions.MarkFalse(req.Object, v2.ReleasedCondition, v2.InstallFailedReason, "%s", msg)
+	req.Object.Status.LastFailureClass = classifyFailure(err)
+	reason := releaseFailureReason(err, v2.InstallFailedReason)
+	conditions.MarkFalse(req.Object, v2.ReleasedCondition, reason, "%s", msg)
 
 	// Record warning event, this message contains more data than the
 	// Condition summary.
@@ -157,7 +224,7 @@ func (r *Install) failure(req *Request, buffer *action.LogBuffer, err error) {
 		eventMeta(req.Chart.Metadata.Version, chartutil.DigestValues(digest.Canonical, req.Values).String(),
 			addAppVersion(req.Chart.AppVersion()), addOCIDigest(req.Object.Status.LastAttemptedRevisionDigest)),
 		corev1.EventTypeWarning,
-		v2.InstallFailedReason,
+		reason,
 		eventMessageWithLog(msg, buffer),
 	)
 }
@@ -174,6 +241,12 @@ func (r *Install) success(req *Request) {
 
 	// Mark install success on object.
 	conditions.MarkTrue(req.Object, v2.ReleasedCondition, v2.InstallSucceededReason, "%s", msg)
+
+	// A successful release starts a new retry delay and backoff on the
+	// next failure.
+	req.Object.Status.FirstFailureTime = nil
+	req.Object.Status.ConsecutiveFailures = 0
+	req.Object.Status.LastFailureClass = ""
 	if req.Object.GetTest().Enable && !cur.HasBeenTested() {
 		conditions.MarkUnknown(req.Object, v2.TestSuccessCondition, "AwaitingTests", fmtTestPending,
 			cur.FullReleaseName(), cur.VersionedChartName())
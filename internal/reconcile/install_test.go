@@ -31,8 +31,11 @@ import (
 	helmstorage "helm.sh/helm/v3/pkg/storage"
 	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
@@ -288,7 +291,7 @@ func TestInstall_Reconcile(t *testing.T) {
 			releaseutil.SortByRevision(releases)
 
 			if tt.expectHistory != nil {
-				g.Expect(obj.Status.History).To(testutil.Equal(tt.expectHistory(releases)))
+				g.Expect(obj.Status.History).To(testutil.Equal(tt.expectHistory(releases), ignoreSnapshotDuration))
 			} else {
 				g.Expect(obj.Status.History).To(BeEmpty(), "expected history to be empty")
 			}
@@ -300,6 +303,352 @@ func TestInstall_Reconcile(t *testing.T) {
 	}
 }
 
+func TestInstall_Reconcile_Atomic(t *testing.T) {
+	g := NewWithT(t)
+
+	namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	t.Cleanup(func() {
+		_ = testEnv.Delete(context.TODO(), namedNS)
+	})
+	releaseNamespace := namedNS.Name
+
+	obj := &v2.HelmRelease{
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			TargetNamespace:  releaseNamespace,
+			StorageNamespace: releaseNamespace,
+			Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+			Install: &v2.Install{
+				Atomic: true,
+			},
+		},
+	}
+
+	getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cfg, err := action.NewConfigFactory(getter,
+		action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	recorder := new(record.FakeRecorder)
+	got := NewInstall(cfg, recorder).Reconcile(context.TODO(), &Request{
+		Object: obj,
+		Chart:  testutil.BuildChart(testutil.ChartWithFailingHook()),
+	})
+	g.Expect(got).ToNot(HaveOccurred())
+
+	g.Expect(obj.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{
+		*conditions.FalseCondition(meta.ReadyCondition, v2.InstallFailedReason,
+			"failed post-install"),
+		*conditions.FalseCondition(v2.ReleasedCondition, v2.InstallFailedReason,
+			"(release uninstalled)"),
+	}))
+
+	// The failed release is purged from the storage.
+	store := helmstorage.Init(cfg.Driver)
+	releases, _ := store.History(mockReleaseName)
+	g.Expect(releases).To(BeEmpty())
+
+	// The history reflects the uninstall of the failed release.
+	g.Expect(obj.Status.History).To(HaveLen(1))
+	g.Expect(obj.Status.History.Latest().Status).To(Equal(helmrelease.StatusUninstalled.String()))
+
+	// The failure still counts towards the remediation retries.
+	g.Expect(obj.Status.Failures).To(Equal(int64(1)))
+	g.Expect(obj.Status.InstallFailures).To(Equal(int64(1)))
+}
+
+func TestInstall_Reconcile_Notes(t *testing.T) {
+	g := NewWithT(t)
+
+	namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	t.Cleanup(func() {
+		_ = testEnv.Delete(context.TODO(), namedNS)
+	})
+	releaseNamespace := namedNS.Name
+
+	obj := &v2.HelmRelease{
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			TargetNamespace:  releaseNamespace,
+			StorageNamespace: releaseNamespace,
+			Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+		},
+	}
+
+	getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cfg, err := action.NewConfigFactory(getter,
+		action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	recorder := new(record.FakeRecorder)
+	got := NewInstall(cfg, recorder).Reconcile(context.TODO(), &Request{
+		Object: obj,
+		Chart:  testutil.BuildChart(testutil.ChartWithNotes("Visit {{ .Release.Name }} in {{ .Release.Namespace }}.")),
+	})
+	g.Expect(got).ToNot(HaveOccurred())
+	g.Expect(conditions.IsTrue(obj, v2.ReleasedCondition)).To(BeTrue())
+
+	g.Expect(obj.Status.LastReleaseNotes).To(Equal(fmt.Sprintf("Visit %s in %s.", mockReleaseName, releaseNamespace)))
+}
+
+func TestInstall_Reconcile_Inventory(t *testing.T) {
+	g := NewWithT(t)
+
+	namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	t.Cleanup(func() {
+		_ = testEnv.Delete(context.TODO(), namedNS)
+	})
+	releaseNamespace := namedNS.Name
+
+	obj := &v2.HelmRelease{
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			TargetNamespace:  releaseNamespace,
+			StorageNamespace: releaseNamespace,
+			Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+		},
+	}
+
+	getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cfg, err := action.NewConfigFactory(getter,
+		action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	recorder := new(record.FakeRecorder)
+	got := NewInstall(cfg, recorder).Reconcile(context.TODO(), &Request{
+		Object: obj,
+		Chart:  testutil.BuildChart(testutil.ChartWithImmutableConfigMap("a")),
+	})
+	g.Expect(got).ToNot(HaveOccurred())
+	g.Expect(conditions.IsTrue(obj, v2.ReleasedCondition)).To(BeTrue())
+
+	// The inventory holds the rendered resources, but not the hooks.
+	g.Expect(obj.Status.Inventory).ToNot(BeNil())
+	g.Expect(obj.Status.Inventory.Count).To(Equal(2))
+	g.Expect(obj.Status.Inventory.Release).To(Equal(releaseNamespace + "/" + mockReleaseName))
+	g.Expect(obj.Status.Inventory.Entries).To(Equal([]v2.ResourceRef{
+		{ID: releaseNamespace + "_cm__ConfigMap", Version: "v1"},
+		{ID: releaseNamespace + "_immutable__ConfigMap", Version: "v1"},
+	}))
+}
+
+func TestInstall_Reconcile_Prune(t *testing.T) {
+	g := NewWithT(t)
+
+	namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	t.Cleanup(func() {
+		_ = testEnv.Delete(context.TODO(), namedNS)
+	})
+	releaseNamespace := namedNS.Name
+
+	// Simulate the resources left behind by the release of a previous chart,
+	// which was handed over on a change of the release target. Of these, one
+	// is rendered by the new chart, one is protected from being pruned, and
+	// one has been adopted by another release.
+	previousOwner := func(annotations map[string]string) map[string]string {
+		owner := map[string]string{
+			"meta.helm.sh/release-name":      "previous",
+			"meta.helm.sh/release-namespace": releaseNamespace,
+		}
+		for k, v := range annotations {
+			owner[k] = v
+		}
+		return owner
+	}
+	shared := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: releaseNamespace, Annotations: previousOwner(nil)},
+	}
+	orphan := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "orphan", Namespace: releaseNamespace, Annotations: previousOwner(nil)},
+	}
+	protected := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "protected",
+			Namespace:   releaseNamespace,
+			Annotations: previousOwner(map[string]string{v2.PruneAnnotation: v2.PruneDisabledValue}),
+		},
+	}
+	adopted := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "adopted",
+			Namespace: releaseNamespace,
+			Annotations: map[string]string{
+				"meta.helm.sh/release-name":      "other",
+				"meta.helm.sh/release-namespace": releaseNamespace,
+			},
+		},
+	}
+	for _, o := range []client.Object{shared, orphan, protected, adopted} {
+		g.Expect(testEnv.Create(context.TODO(), o)).To(Succeed())
+	}
+
+	sharedRef := v2.ResourceRef{ID: releaseNamespace + "_cm__ConfigMap", Version: "v1"}
+	orphanRef := v2.ResourceRef{ID: releaseNamespace + "_orphan__ConfigMap", Version: "v1"}
+	protectedRef := v2.ResourceRef{ID: releaseNamespace + "_protected__ConfigMap", Version: "v1"}
+	adoptedRef := v2.ResourceRef{ID: releaseNamespace + "_adopted__ConfigMap", Version: "v1"}
+
+	obj := &v2.HelmRelease{
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			TargetNamespace:  releaseNamespace,
+			StorageNamespace: releaseNamespace,
+			Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+			Upgrade: &v2.Upgrade{
+				Prune: true,
+			},
+		},
+		Status: v2.HelmReleaseStatus{
+			Inventory: &v2.ResourceInventory{
+				Count:   4,
+				Entries: []v2.ResourceRef{adoptedRef, sharedRef, orphanRef, protectedRef},
+				Release: releaseNamespace + "/previous",
+			},
+		},
+	}
+
+	getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cfg, err := action.NewConfigFactory(getter,
+		action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	recorder := testutil.NewFakeRecorder(10, false)
+	g.Expect(NewInstall(cfg, recorder).Reconcile(context.TODO(), &Request{
+		Object: obj,
+		Chart:  testutil.BuildChart(testutil.ChartWithName("other-chart")),
+	})).To(Succeed())
+	g.Expect(conditions.IsTrue(obj, v2.ReleasedCondition)).To(BeTrue())
+
+	// The resource rendered by the new chart has been taken over by the new
+	// release, and the orphan of the previous release has been pruned.
+	g.Expect(testEnv.Get(context.TODO(), client.ObjectKeyFromObject(shared), shared)).To(Succeed())
+	g.Expect(shared.GetAnnotations()).To(HaveKeyWithValue("meta.helm.sh/release-name", mockReleaseName))
+	err = testEnv.Get(context.TODO(), client.ObjectKeyFromObject(orphan), &corev1.ConfigMap{})
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	g.Expect(testEnv.Get(context.TODO(), client.ObjectKeyFromObject(protected), &corev1.ConfigMap{})).To(Succeed())
+	g.Expect(testEnv.Get(context.TODO(), client.ObjectKeyFromObject(adopted), &corev1.ConfigMap{})).To(Succeed())
+
+	g.Expect(obj.Status.Inventory.Release).To(Equal(releaseNamespace + "/" + mockReleaseName))
+	g.Expect(obj.Status.Inventory.Entries).To(ContainElement(sharedRef))
+	g.Expect(obj.Status.Inventory.Entries).ToNot(ContainElements(orphanRef, protectedRef, adoptedRef))
+	events := recorder.GetEvents()
+	g.Expect(events).To(ContainElement(SatisfyAll(
+		WithTransform(func(e corev1.Event) string { return e.Reason }, Equal(v2.ResourcesPrunedReason)),
+		WithTransform(func(e corev1.Event) string { return e.Message }, SatisfyAll(
+			ContainSubstring(orphanRef.ID), Not(ContainSubstring(sharedRef.ID)), Not(ContainSubstring(protectedRef.ID)),
+		)),
+	)))
+	g.Expect(events).To(ContainElement(SatisfyAll(
+		WithTransform(func(e corev1.Event) string { return e.Reason }, Equal(v2.PruneSkippedReason)),
+		WithTransform(func(e corev1.Event) string { return e.Message }, ContainSubstring(adoptedRef.ID)),
+	)))
+}
+
+func TestInstall_Reconcile_CreateNamespace(t *testing.T) {
+	tests := []struct {
+		name        string
+		preexisting bool
+	}{
+		{name: "creates and deletes namespace"},
+		{name: "retains pre-existing namespace", preexisting: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			storageNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			t.Cleanup(func() {
+				_ = testEnv.Delete(context.TODO(), storageNS)
+			})
+
+			targetNamespace := "create-ns-" + rand.String(5)
+			if tt.preexisting {
+				ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}}
+				g.Expect(testEnv.Create(context.TODO(), ns)).To(Succeed())
+			}
+			t.Cleanup(func() {
+				_ = testEnv.Delete(context.TODO(), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: targetNamespace}})
+			})
+
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Name: mockReleaseName, Namespace: storageNS.Name},
+				Spec: v2.HelmReleaseSpec{
+					ReleaseName:      mockReleaseName,
+					TargetNamespace:  targetNamespace,
+					StorageNamespace: storageNS.Name,
+					Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+					CommonMetadata: &v2.CommonMetadata{
+						Labels:      map[string]string{"team": "a"},
+						Annotations: map[string]string{"owner": "team-a"},
+					},
+					Install: &v2.Install{
+						CreateNamespace: true,
+						CreateNamespaceOptions: &v2.CreateNamespaceOptions{
+							CommonMetadata: true,
+							DeletePolicy:   v2.NamespaceDeletePolicyDelete,
+						},
+					},
+				},
+			}
+
+			getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+			g.Expect(err).ToNot(HaveOccurred())
+
+			cfg, err := action.NewConfigFactory(getter,
+				action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+			)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			got := NewInstall(cfg, new(record.FakeRecorder)).Reconcile(context.TODO(), &Request{
+				Object: obj,
+				Chart:  testutil.BuildChart(),
+			})
+			g.Expect(got).ToNot(HaveOccurred())
+			g.Expect(conditions.IsTrue(obj, v2.ReleasedCondition)).To(BeTrue())
+
+			ns := &corev1.Namespace{}
+			g.Expect(testEnv.Get(context.TODO(), client.ObjectKey{Name: targetNamespace}, ns)).To(Succeed())
+
+			deleted, err := action.DeleteCreatedNamespace(cfg.Build(nil), obj)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(testEnv.Get(context.TODO(), client.ObjectKey{Name: targetNamespace}, ns)).To(Succeed())
+
+			if tt.preexisting {
+				g.Expect(obj.Status.CreatedNamespace).To(BeEmpty())
+				g.Expect(ns.Labels).ToNot(HaveKey(action.OwnerNameLabel))
+				g.Expect(deleted).To(BeFalse())
+				g.Expect(ns.DeletionTimestamp).To(BeNil())
+				return
+			}
+
+			g.Expect(obj.Status.CreatedNamespace).To(Equal(targetNamespace))
+			g.Expect(ns.Labels).To(HaveKeyWithValue("team", "a"))
+			g.Expect(ns.Labels).To(HaveKeyWithValue(action.OwnerNameLabel, mockReleaseName))
+			g.Expect(ns.Annotations).To(HaveKeyWithValue("owner", "team-a"))
+			g.Expect(deleted).To(BeTrue())
+			// Namespaces are not finalized in the test environment, hence
+			// the namespace is only marked for deletion.
+			g.Expect(ns.DeletionTimestamp).ToNot(BeNil())
+		})
+	}
+}
+
 func TestInstall_failure(t *testing.T) {
 	var (
 		obj = &v2.HelmRelease{
@@ -397,6 +746,7 @@ func TestInstall_success(t *testing.T) {
 		req := &Request{
 			Object: obj.DeepCopy(),
 		}
+		req.Object.Status.ConsecutiveFailures = 3
 		r.success(req)
 
 		expectMsg := fmt.Sprintf(fmtInstallSuccess,
@@ -420,6 +770,7 @@ func TestInstall_success(t *testing.T) {
 				},
 			},
 		}))
+		g.Expect(req.Object.Status.ConsecutiveFailures).To(BeZero())
 	})
 
 	t.Run("records success with TestSuccess=False", func(t *testing.T) {
@@ -0,0 +1,177 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/fluxcd/pkg/runtime/logger"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/chartutil"
+	"github.com/fluxcd/helm-controller/internal/digest"
+)
+
+// ManualRollback is an ActionReconciler which rolls back a Request.Object to
+// the previous deployed release in the Status.History, on request of the
+// user through the v2.RollbackRequestAnnotation. Contrary to
+// RollbackRemediation, it does not depend on the remediation strategy of the
+// object, and does not take test failures of the previous release into
+// account.
+//
+// The writes to the Helm storage during the rollback are observed, and update
+// the Status.History field.
+//
+// After a successful rollback, the object is marked with Remediated=True,
+// Status.ManualRollbackActive is set to prevent the release from being
+// upgraded again until the chart or values change, and an event is emitted.
+// When the rollback fails, the object is marked with Remediated=False and a
+// warning event is emitted.
+//
+// When the Request.Object does not have a previous deployed release, it
+// returns an error of type ErrMissingRollbackTarget. In addition, it returns
+// ErrReleaseMismatch if the name and/or namespace of the latest and previous
+// release do not match. Any other returned error indicates the caller should
+// retry as it did not cause a change to the Helm storage.
+//
+// At the end of the reconciliation, the Status.Conditions are summarized and
+// propagated to the Ready condition on the Request.Object.
+//
+// The caller is assumed to have verified the integrity of Request.Object using
+// e.g. action.VerifySnapshot before calling Reconcile.
+type ManualRollback struct {
+	configFactory *action.ConfigFactory
+	eventRecorder record.EventRecorder
+}
+
+// NewManualRollback returns a new ManualRollback reconciler configured with
+// the provided values.
+func NewManualRollback(configFactory *action.ConfigFactory, eventRecorder record.EventRecorder) *ManualRollback {
+	return &ManualRollback{
+		configFactory: configFactory,
+		eventRecorder: eventRecorder,
+	}
+}
+
+func (r *ManualRollback) Reconcile(ctx context.Context, req *Request) error {
+	var (
+		cur    = req.Object.Status.History.Latest().DeepCopy()
+		logBuf = action.NewLogBuffer(action.NewDebugLog(ctrl.LoggerFrom(ctx).V(logger.DebugLevel)), 10)
+		cfg    = r.configFactory.Build(logBuf.Log, observeRollback(req.Object, time.Now()))
+	)
+
+	defer summarize(req)
+
+	// Previous is required to determine what version to roll back to.
+	prev := req.Object.Status.History.Previous(true)
+	if prev == nil {
+		return fmt.Errorf("%w: required to rollback", ErrMissingRollbackTarget)
+	}
+
+	// Confirm previous and current point to the same release.
+	if prev.Name != cur.Name || prev.Namespace != cur.Namespace {
+		return fmt.Errorf("%w: previous release name or namespace %s does not match current %s",
+			ErrReleaseMismatch, prev.FullReleaseName(), cur.FullReleaseName())
+	}
+
+	// Run the Helm rollback action.
+	if err := action.Rollback(cfg, req.Object, prev.Name, action.RollbackToVersion(prev.Version)); err != nil {
+		r.failure(req, prev, logBuf, err)
+
+		// Return error if we did not store a release, as this does not
+		// affect state and the caller should e.g. retry.
+		if newCur := req.Object.Status.History.Latest(); newCur == nil || newCur.Digest == cur.Digest {
+			return err
+		}
+
+		return nil
+	}
+
+	r.success(req, prev)
+	return nil
+}
+
+func (r *ManualRollback) Name() string {
+	return "manual rollback"
+}
+
+func (r *ManualRollback) Type() ReconcilerType {
+	return ReconcilerTypeRemediate
+}
+
+const (
+	// fmtManualRollbackFailure is the message format for a failed rollback
+	// requested through the annotation.
+	fmtManualRollbackFailure = "Helm rollback on request to previous release %s with chart %s failed: %s"
+	// fmtManualRollbackSuccess is the message format for a successful
+	// rollback requested through the annotation.
+	fmtManualRollbackSuccess = "Helm rollback on request to previous release %s with chart %s succeeded"
+)
+
+// failure records the failure of a Helm rollback action in the status of the
+// given Request.Object by marking Remediated=False and emitting a warning
+// event.
+func (r *ManualRollback) failure(req *Request, prev *v2.Snapshot, buffer *action.LogBuffer, err error) {
+	// Compose failure message.
+	msg := fmt.Sprintf(fmtManualRollbackFailure, prev.FullReleaseName(), prev.VersionedChartName(), strings.TrimSpace(err.Error()))
+
+	// Mark rollback failure on object.
+	req.Object.Status.Failures++
+	conditions.MarkFalse(req.Object, v2.RemediatedCondition, v2.ManualRollbackFailedReason, "%s", msg)
+
+	// Record warning event, this message contains more data than the
+	// Condition summary.
+	r.eventRecorder.AnnotatedEventf(
+		req.Object,
+		eventMeta(prev.ChartVersion, chartutil.DigestValues(digest.Canonical, req.Values).String(),
+			addAppVersion(prev.AppVersion), addOCIDigest(prev.OCIDigest)),
+		corev1.EventTypeWarning,
+		v2.ManualRollbackFailedReason,
+		eventMessageWithLog(msg, buffer),
+	)
+}
+
+// success records the success of a Helm rollback action in the status of the
+// given Request.Object by marking Remediated=True, holding the release at
+// the rolled back version, and emitting an event.
+func (r *ManualRollback) success(req *Request, prev *v2.Snapshot) {
+	// Compose success message.
+	msg := fmt.Sprintf(fmtManualRollbackSuccess, prev.FullReleaseName(), prev.VersionedChartName())
+
+	// Mark rollback success on object.
+	conditions.MarkTrue(req.Object, v2.RemediatedCondition, v2.ManualRollbackSucceededReason, "%s", msg)
+	req.Object.Status.ManualRollbackActive = true
+
+	// Record event.
+	r.eventRecorder.AnnotatedEventf(
+		req.Object,
+		eventMeta(prev.ChartVersion, chartutil.DigestValues(digest.Canonical, req.Values).String(),
+			addAppVersion(prev.AppVersion), addOCIDigest(prev.OCIDigest)),
+		corev1.EventTypeNormal,
+		v2.ManualRollbackSucceededReason,
+		msg,
+	)
+}
@@ -0,0 +1,231 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmreleaseutil "helm.sh/helm/v3/pkg/releaseutil"
+	helmstorage "helm.sh/helm/v3/pkg/storage"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/chartutil"
+	"github.com/fluxcd/helm-controller/internal/digest"
+	"github.com/fluxcd/helm-controller/internal/release"
+	"github.com/fluxcd/helm-controller/internal/testutil"
+)
+
+func TestManualRollback_Reconcile(t *testing.T) {
+	tests := []struct {
+		name string
+		// releases is the list of releases that are stored in the driver
+		// before rollback.
+		releases func(namespace string) []*helmrelease.Release
+		// status to configure on the HelmRelease before rollback.
+		status func(releases []*helmrelease.Release) v2.HelmReleaseStatus
+		// wantErr is the error that is expected to be returned.
+		wantErr error
+		// expectedConditions are the conditions that are expected to be set on
+		// the HelmRelease after rolling back.
+		expectConditions []metav1.Condition
+		// expectHistory is the expected History on the HelmRelease after
+		// rolling back.
+		expectHistory func(releases []*helmrelease.Release) v2.Snapshots
+		// expectManualRollbackActive is the expected ManualRollbackActive
+		// value on the HelmRelease.
+		expectManualRollbackActive bool
+	}{
+		{
+			name: "rollback to previous release",
+			releases: func(namespace string) []*helmrelease.Release {
+				return []*helmrelease.Release{
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Version:   1,
+						Chart:     testutil.BuildChart(),
+						Status:    helmrelease.StatusSuperseded,
+						Namespace: namespace,
+					}),
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Version:   2,
+						Chart:     testutil.BuildChart(),
+						Status:    helmrelease.StatusDeployed,
+						Namespace: namespace,
+					}),
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+				}
+			},
+			expectConditions: []metav1.Condition{
+				*conditions.FalseCondition(meta.ReadyCondition, v2.ManualRollbackSucceededReason, "succeeded"),
+				*conditions.TrueCondition(v2.RemediatedCondition, v2.ManualRollbackSucceededReason, "succeeded"),
+			},
+			expectHistory: func(releases []*helmrelease.Release) v2.Snapshots {
+				return v2.Snapshots{
+					release.ObservedToSnapshot(release.ObserveRelease(releases[2])),
+					release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+					release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+				}
+			},
+			expectManualRollbackActive: true,
+		},
+		{
+			name: "rollback without previous release",
+			releases: func(namespace string) []*helmrelease.Release {
+				return []*helmrelease.Release{
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Version:   1,
+						Chart:     testutil.BuildChart(),
+						Status:    helmrelease.StatusDeployed,
+						Namespace: namespace,
+					}),
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+				}
+			},
+			wantErr: ErrMissingRollbackTarget,
+			expectHistory: func(releases []*helmrelease.Release) v2.Snapshots {
+				return v2.Snapshots{
+					release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			t.Cleanup(func() {
+				_ = testEnv.Delete(context.TODO(), namedNS)
+			})
+			releaseNamespace := namedNS.Name
+
+			releases := tt.releases(releaseNamespace)
+			helmreleaseutil.SortByRevision(releases)
+
+			obj := &v2.HelmRelease{
+				Spec: v2.HelmReleaseSpec{
+					ReleaseName:      mockReleaseName,
+					TargetNamespace:  releaseNamespace,
+					StorageNamespace: releaseNamespace,
+					Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+				},
+			}
+			obj.Status = tt.status(releases)
+
+			getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+			g.Expect(err).ToNot(HaveOccurred())
+
+			cfg, err := action.NewConfigFactory(getter,
+				action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+			)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			store := helmstorage.Init(cfg.Driver)
+			for _, r := range releases {
+				g.Expect(store.Create(r)).To(Succeed())
+			}
+
+			recorder := new(record.FakeRecorder)
+			got := NewManualRollback(cfg, recorder).Reconcile(context.TODO(), &Request{
+				Object: obj,
+			})
+			if tt.wantErr != nil {
+				g.Expect(errors.Is(got, tt.wantErr)).To(BeTrue())
+			} else {
+				g.Expect(got).ToNot(HaveOccurred())
+			}
+
+			g.Expect(obj.Status.Conditions).To(conditions.MatchConditions(tt.expectConditions))
+
+			releases, _ = store.History(mockReleaseName)
+			helmreleaseutil.SortByRevision(releases)
+			g.Expect(obj.Status.History).To(testutil.Equal(tt.expectHistory(releases), ignoreSnapshotDuration))
+
+			g.Expect(obj.Status.ManualRollbackActive).To(Equal(tt.expectManualRollbackActive))
+		})
+	}
+}
+
+func TestManualRollback_success(t *testing.T) {
+	g := NewWithT(t)
+
+	var prev = testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+		Name:    mockReleaseName,
+		Chart:   testutil.BuildChart(),
+		Version: 4,
+	})
+
+	recorder := testutil.NewFakeRecorder(10, false)
+	r := &ManualRollback{
+		eventRecorder: recorder,
+	}
+	req := &Request{Object: &v2.HelmRelease{}, Values: map[string]interface{}{"foo": "bar"}}
+	r.success(req, release.ObservedToSnapshot(release.ObserveRelease(prev)))
+
+	expectMsg := fmt.Sprintf(fmtManualRollbackSuccess,
+		fmt.Sprintf("%s/%s.v%d", prev.Namespace, prev.Name, prev.Version),
+		fmt.Sprintf("%s@%s", prev.Chart.Name(), prev.Chart.Metadata.Version))
+
+	g.Expect(req.Object.Status.Conditions).To(conditions.MatchConditions([]metav1.Condition{
+		*conditions.TrueCondition(v2.RemediatedCondition, v2.ManualRollbackSucceededReason, expectMsg),
+	}))
+	g.Expect(req.Object.Status.ManualRollbackActive).To(BeTrue())
+	g.Expect(recorder.GetEvents()).To(ConsistOf([]corev1.Event{
+		{
+			Type:    corev1.EventTypeNormal,
+			Reason:  v2.ManualRollbackSucceededReason,
+			Message: expectMsg,
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{
+					eventMetaGroupKey(eventv1.MetaRevisionKey): prev.Chart.Metadata.Version,
+					eventMetaGroupKey(metaAppVersionKey):       prev.Chart.Metadata.AppVersion,
+					eventMetaGroupKey(eventv1.MetaTokenKey):    chartutil.DigestValues(digest.Canonical, req.Values).String(),
+				},
+			},
+		},
+	}))
+}
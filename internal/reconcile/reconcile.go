@@ -38,9 +38,16 @@ const (
 	// release in a stale pending state. It differs from ReconcilerTypeRemediate
 	// in that it does not produce a new Helm release.
 	ReconcilerTypeUnlock ReconcilerType = "unlock"
+	// ReconcilerTypeAdopt is an ActionReconciler which adopts a Helm release
+	// which was not produced by the controller. Like ReconcilerTypeUnlock, it
+	// does not produce a new Helm release.
+	ReconcilerTypeAdopt ReconcilerType = "adopt"
 	// ReconcilerTypeDriftCorrection is an ActionReconciler which corrects
 	// Helm releases which have drifted from the cluster state.
 	ReconcilerTypeDriftCorrection ReconcilerType = "drift correction"
+	// ReconcilerTypeDiff is an ActionReconciler which reports the changes
+	// a release action would make, without performing it.
+	ReconcilerTypeDiff ReconcilerType = "diff"
 )
 
 // ReconcilerType is a string which identifies the type of ActionReconciler.
@@ -83,6 +90,29 @@ type Request struct {
 	// Values is the Helm chart values to be used for the installation or
 	// upgrade.
 	Values helmchartutil.Values
+	// ValuesChecksum is the checksum of the Values merged with the default
+	// values of the Chart, to be recorded with the release.
+	ValuesChecksum string
+	// ValuesSnapshot is the snapshot of the Values merged with the default
+	// values of the Chart, to be recorded with the release.
+	ValuesSnapshot string
+	// VerifiedDigest is the digest of the OCI artifact of the Chart, of
+	// which the signature was verified, to be recorded with the release.
+	VerifiedDigest string
+	// VerificationDigest is the digest of the verification configuration
+	// with which the VerifiedDigest was verified, to be recorded with the
+	// release.
+	VerificationDigest string
+	// ChartSourceRevision is the revision of the source artifact from which
+	// the Chart was loaded, to be recorded with the release.
+	ChartSourceRevision string
+	// ChartDigest is the digest of the source artifact from which the Chart
+	// was loaded, to be recorded with the release.
+	ChartDigest string
+	// ChartPinnedByDigest indicates the source of the Chart references it
+	// by digest, in which case a lower chart version is not considered a
+	// downgrade.
+	ChartPinnedByDigest bool
 }
 
 // ActionReconciler is an interface which defines the methods that a reconciler
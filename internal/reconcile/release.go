@@ -17,17 +17,26 @@ limitations under the License.
 package reconcile
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
 	"github.com/fluxcd/pkg/runtime/conditions"
+	helmaction "helm.sh/helm/v3/pkg/action"
 	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	v2 "github.com/fluxcd/helm-controller/api/v2"
 	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/chartutil"
 	"github.com/fluxcd/helm-controller/internal/release"
 	"github.com/fluxcd/helm-controller/internal/storage"
 )
@@ -62,41 +71,231 @@ func (r observedReleases) sortedVersions() (versions []int) {
 }
 
 // recordOnObject records the observed releases on the HelmRelease object.
+//
+// The latest observed release is recorded as the new latest Snapshot, and
+// the Snapshot it replaces as the latest is marked as superseded by it. Any
+// other observed release updates the Snapshot of the same version, which
+// is marked as superseded by the latest observed release when Helm
+// superseded it.
 func (r observedReleases) recordOnObject(obj *v2.HelmRelease, mutators ...mutateObservedRelease) {
-	switch len(r) {
-	case 0:
+	if len(r) == 0 {
 		return
-	case 1:
-		var obs release.Observation
-		for _, o := range r {
-			obs = o
+	}
+
+	versions := r.sortedVersions()
+	obs := r[versions[0]]
+	for _, mut := range mutators {
+		obs = mut(obj, obs)
+	}
+	if prev := obj.Status.History.Latest(); prev != nil && prev.Name == obs.Name &&
+		prev.Namespace == obs.Namespace && prev.Version < obs.Version {
+		prev.SupersededBy = obs.Version
+	}
+	obj.Status.History = append(v2.Snapshots{release.ObservedToSnapshot(obs)}, obj.Status.History...)
+
+	for _, ver := range versions[1:] {
+		for i := range obj.Status.History {
+			snap := obj.Status.History[i]
+			if snap.Targets(r[ver].Name, r[ver].Namespace, r[ver].Version) {
+				obs := r[ver]
+				obs.OCIDigest = snap.OCIDigest
+				newSnap := release.ObservedToSnapshot(obs)
+				newSnap.SetTestHooks(snap.GetTestHooks())
+				newSnap.SupersededBy = snap.SupersededBy
+				newSnap.Duration = snap.Duration
+				newSnap.ValuesChecksum = snap.ValuesChecksum
+				newSnap.Values = snap.Values
+				newSnap.VerifiedDigest = snap.VerifiedDigest
+				newSnap.VerificationDigest = snap.VerificationDigest
+				newSnap.ChartSourceRevision = snap.ChartSourceRevision
+				newSnap.ChartDigest = snap.ChartDigest
+				newSnap.CRDsPolicy = snap.CRDsPolicy
+				newSnap.Backup = snap.Backup
+				if newSnap.SupersededBy == 0 && obs.Info.Status == helmrelease.StatusSuperseded {
+					newSnap.SupersededBy = versions[0]
+				}
+				obj.Status.History[i] = newSnap
+				break
+			}
 		}
-		for _, mut := range mutators {
-			obs = mut(obj, obs)
+	}
+}
+
+// recordNotesOnObject records the notes of the latest observed release on
+// the HelmRelease object, truncated to v2.MaxReleaseNotesSize.
+func (r observedReleases) recordNotesOnObject(obj *v2.HelmRelease) {
+	if len(r) == 0 {
+		return
+	}
+	obj.Status.LastReleaseNotes = truncateReleaseNotes(r[r.sortedVersions()[0]].Info.Notes, v2.MaxReleaseNotesSize)
+}
+
+// recordInventoryOnObject records the inventory of the resources of the
+// latest observed release on the HelmRelease object. The inventory is removed
+// when it can not be built, as it would no longer reflect the release.
+func (r observedReleases) recordInventoryOnObject(cfg *helmaction.Configuration, obj *v2.HelmRelease) error {
+	if len(r) == 0 {
+		return nil
+	}
+	latest := r[r.sortedVersions()[0]]
+	inventory, err := action.Inventory(cfg, latest.Manifest)
+	if err != nil {
+		obj.Status.Inventory = nil
+		return err
+	}
+	inventory.Release = latest.Namespace + "/" + latest.Name
+	obj.Status.Inventory = inventory
+	return nil
+}
+
+// releaseNotesTruncatedMarker is appended to release notes which exceeded
+// the maximum size.
+const releaseNotesTruncatedMarker = "\n[truncated]\n"
+
+// truncateReleaseNotes returns the given notes truncated to the given size
+// limit, including the releaseNotesTruncatedMarker which is appended when the
+// notes exceed the limit. A multibyte character cut off by the truncation is
+// removed.
+func truncateReleaseNotes(notes string, limit int) string {
+	if len(notes) <= limit {
+		return notes
+	}
+	notes = strings.ToValidUTF8(notes[:max(limit-len(releaseNotesTruncatedMarker), 0)], "")
+	return notes + releaseNotesTruncatedMarker
+}
+
+// pruneHistory removes the releases which were last deployed before the
+// HistoryRetention.MaxAge of the given object from its Status.History and
+// the Helm storage. The latest release and the latest successfully deployed
+// release are always retained.
+func pruneHistory(cfg *helmaction.Configuration, obj *v2.HelmRelease, now time.Time) error {
+	retention := obj.Spec.HistoryRetention
+	if retention == nil || retention.MaxAge == nil {
+		return nil
+	}
+	cutoff := now.Add(-retention.MaxAge.Duration)
+	obj.Status.History.PruneDeployedBefore(cutoff)
+
+	releases, err := cfg.Releases.History(release.ShortenName(obj.GetReleaseName()))
+	if err != nil {
+		if errors.Is(err, helmdriver.ErrReleaseNotFound) {
+			return nil
 		}
-		obj.Status.History = append(v2.Snapshots{release.ObservedToSnapshot(obs)}, obj.Status.History...)
-	default:
-		versions := r.sortedVersions()
-		obs := r[versions[0]]
-		for _, mut := range mutators {
-			obs = mut(obj, obs)
+		return fmt.Errorf("failed to get release history: %w", err)
+	}
+	for _, rls := range expiredReleases(releases, cutoff) {
+		if _, err = cfg.Releases.Delete(rls.Name, rls.Version); err != nil {
+			return fmt.Errorf("failed to delete release %s/%s.v%d: %w", rls.Namespace, rls.Name, rls.Version, err)
 		}
-		obj.Status.History = append(v2.Snapshots{release.ObservedToSnapshot(obs)}, obj.Status.History...)
-
-		for _, ver := range versions[1:] {
-			for i := range obj.Status.History {
-				snap := obj.Status.History[i]
-				if snap.Targets(r[ver].Name, r[ver].Namespace, r[ver].Version) {
-					obs := r[ver]
-					obs.OCIDigest = snap.OCIDigest
-					newSnap := release.ObservedToSnapshot(obs)
-					newSnap.SetTestHooks(snap.GetTestHooks())
-					obj.Status.History[i] = newSnap
-					return
-				}
-			}
+	}
+	return nil
+}
+
+// pruneBackups removes the backups of the releases of the given object which
+// are no longer referenced by a Snapshot in its Status.History, because they
+// have been truncated or pruned according to the HistoryRetention.
+func pruneBackups(store action.BackupStore, obj *v2.HelmRelease) error {
+	referenced := make(map[string]struct{})
+	for _, snap := range obj.Status.History {
+		if snap.Backup != "" {
+			referenced[snap.Backup] = struct{}{}
 		}
 	}
+	if store == nil || (len(referenced) == 0 && !obj.GetUpgrade().Backup) {
+		return nil
+	}
+
+	names, err := store.List(release.ShortenName(obj.GetReleaseName()))
+	if err != nil {
+		return fmt.Errorf("failed to list release backups: %w", err)
+	}
+	for _, name := range names {
+		if _, ok := referenced[name]; ok {
+			continue
+		}
+		if err = store.Delete(name); err != nil {
+			return fmt.Errorf("failed to delete release backup %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// expiredReleases returns the given releases which were last deployed
+// before the given cutoff, except for the latest release and the latest
+// deployed or superseded release.
+func expiredReleases(releases []*helmrelease.Release, cutoff time.Time) []*helmrelease.Release {
+	sorted := make([]*helmrelease.Release, 0, len(releases))
+	for _, rls := range releases {
+		if rls != nil && rls.Info != nil {
+			sorted = append(sorted, rls)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Version > sorted[j].Version
+	})
+
+	var (
+		expired    []*helmrelease.Release
+		successful bool
+	)
+	for i, rls := range sorted {
+		keep := i == 0 || !rls.Info.LastDeployed.Time.Before(cutoff)
+		if !successful && (rls.Info.Status == helmrelease.StatusDeployed || rls.Info.Status == helmrelease.StatusSuperseded) {
+			successful, keep = true, true
+		}
+		if !keep {
+			expired = append(expired, rls)
+		}
+	}
+	return expired
+}
+
+// historyLimitMessage returns a message when the number of releases stored by
+// Helm for the given object has reached its MaxHistory, and the release a
+// rollback would target is no longer retained by the next Helm release
+// action. When storing a new release, Helm prunes the oldest releases while
+// retaining the latest MaxHistory-1 releases and the latest deployed release.
+// It returns an empty string if the MaxHistory is unlimited or has not been
+// reached, or if there is no rollback target.
+func historyLimitMessage(cfg *helmaction.Configuration, obj *v2.HelmRelease) (string, error) {
+	maxHistory := obj.GetMaxHistory()
+	if maxHistory <= 0 {
+		return "", nil
+	}
+
+	ignoreFailures := obj.GetTest().IgnoreFailures
+	if remediation := obj.GetActiveRemediation(); remediation != nil {
+		ignoreFailures = remediation.MustIgnoreTestFailures(ignoreFailures)
+	}
+	target := obj.Status.History.Previous(ignoreFailures)
+	if target == nil {
+		return "", nil
+	}
+
+	releases, err := cfg.Releases.History(release.ShortenName(obj.GetReleaseName()))
+	if err != nil {
+		if errors.Is(err, helmdriver.ErrReleaseNotFound) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to get release history: %w", err)
+	}
+	if len(releases) < maxHistory {
+		return "", nil
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].Version > releases[j].Version
+	})
+	var deployed bool
+	for i, rls := range releases {
+		latestDeployed := !deployed && rls.Info != nil && rls.Info.Status == helmrelease.StatusDeployed
+		if rls.Version == target.Version && (i < maxHistory-1 || latestDeployed) {
+			return "", nil
+		}
+		deployed = deployed || latestDeployed
+	}
+	return fmt.Sprintf("Release history has reached the maximum of %d revisions: rollback target %s is not retained by the next release",
+		maxHistory, target.FullReleaseName()), nil
 }
 
 func mutateOCIDigest(obj *v2.HelmRelease, obs release.Observation) release.Observation {
@@ -104,19 +303,90 @@ func mutateOCIDigest(obj *v2.HelmRelease, obs release.Observation) release.Obser
 	return obs
 }
 
+// mutateValues returns a mutateObservedRelease which sets the checksum and
+// snapshot of the merged values of the given Request on the Observation.
+func mutateValues(req *Request) mutateObservedRelease {
+	return func(_ *v2.HelmRelease, obs release.Observation) release.Observation {
+		obs.ValuesChecksum = req.ValuesChecksum
+		obs.Values = req.ValuesSnapshot
+		return obs
+	}
+}
+
+// mutateVerifiedDigest returns a mutateObservedRelease which sets the
+// verified digest and verification digest of the given Request on the
+// Observation.
+func mutateVerifiedDigest(req *Request) mutateObservedRelease {
+	return func(_ *v2.HelmRelease, obs release.Observation) release.Observation {
+		obs.VerifiedDigest = req.VerifiedDigest
+		obs.VerificationDigest = req.VerificationDigest
+		return obs
+	}
+}
+
+// mutateChartSource returns a mutateObservedRelease which sets the source
+// artifact revision and digest of the chart of the given Request on the
+// Observation.
+func mutateChartSource(req *Request) mutateObservedRelease {
+	return func(_ *v2.HelmRelease, obs release.Observation) release.Observation {
+		obs.ChartSourceRevision = req.ChartSourceRevision
+		obs.ChartDigest = req.ChartDigest
+		return obs
+	}
+}
+
+// mutateCRDsPolicy returns a mutateObservedRelease which sets the given
+// policy for the CustomResourceDefinitions of the chart on the Observation.
+// An empty policy is recorded as the default policy.
+func mutateCRDsPolicy(policy v2.CRDsPolicy) mutateObservedRelease {
+	return func(_ *v2.HelmRelease, obs release.Observation) release.Observation {
+		if policy == "" {
+			policy = action.DefaultCRDPolicy
+		}
+		obs.CRDsPolicy = policy
+		return obs
+	}
+}
+
 func releaseToObservation(rls *helmrelease.Release, snapshot *v2.Snapshot) release.Observation {
 	obs := release.ObserveRelease(rls)
 	obs.OCIDigest = snapshot.OCIDigest
+	obs.ChartSourceRevision = snapshot.ChartSourceRevision
+	obs.ChartDigest = snapshot.ChartDigest
+	obs.CRDsPolicy = snapshot.CRDsPolicy
+	obs.Backup = snapshot.Backup
 	return obs
 }
 
+// recordOnObject records the given Helm release, of which the state has been
+// changed by the controller outside of a Helm action, on the Snapshot in the
+// history of the given object that targets it. The digest, status and
+// timestamps of the Snapshot are updated, while the data which can not be
+// observed from the release is retained.
+func recordOnObject(obj *v2.HelmRelease, rls *helmrelease.Release) {
+	for _, snap := range obj.Status.History {
+		if !snap.Targets(rls.Name, rls.Namespace, rls.Version) {
+			continue
+		}
+		observed := release.ObservedToSnapshot(releaseToObservation(rls, snap))
+		snap.Digest = observed.Digest
+		snap.Status = observed.Status
+		snap.StatusReason = observed.StatusReason
+		snap.LastDeployed = observed.LastDeployed
+		snap.Deleted = observed.Deleted
+		return
+	}
+}
+
 // observeRelease returns a storage.ObserveFunc that stores the observed
-// releases in the given observedReleases map.
+// releases in the given observedReleases map, with the time elapsed since
+// the given start of the Helm action as their Duration.
 // It can be used for Helm actions that modify multiple releases in the
 // Helm storage, such as install and upgrade.
-func observeRelease(observed observedReleases) storage.ObserveFunc {
+func observeRelease(observed observedReleases, started time.Time) storage.ObserveFunc {
 	return func(rls *helmrelease.Release) {
 		obs := release.ObserveRelease(rls)
+		obs.Duration = time.Since(started)
 		observed[obs.Version] = obs
 	}
 }
@@ -125,7 +395,9 @@ func observeRelease(observed observedReleases) storage.ObserveFunc {
 // Released conditions of the given Request.Object, and sets it on the object.
 //
 // The composition is made by sorting them by highest generation and priority
-// of the summary conditions, taking the first result.
+// of the summary conditions, taking the first result. The priority defaults
+// to Remediated, TestSuccess and Released, and can be configured using the
+// v2.SummaryPriorityAnnotation.
 //
 // Not taking the generation of the object itself into account ensures that if
 // the change in generation of the resource does not result in a release, the
@@ -134,22 +406,36 @@ func observeRelease(observed observedReleases) storage.ObserveFunc {
 //
 // It takes the current specification of the object into account, and deals
 // with the conditional handling of TestSuccess. Deleting the condition when
-// tests are not enabled, and excluding it when failures must be ignored.
+// tests are not enabled, unless it must be retained in which case it is
+// marked as stale, and excluding it when failures must be ignored.
 //
 // If Ready=True, any Stalled condition is removed.
 //
+// The RemediationRetriesRemaining is updated for the active remediation
+// strategy.
+//
 // The ObservedPostRenderersDigest is updated if the post-renderers exist.
 func summarize(req *Request) {
-	var sumConds = []string{v2.RemediatedCondition, v2.ReleasedCondition}
-	if req.Object.GetTest().Enable && !req.Object.GetTest().IgnoreFailures {
-		sumConds = []string{v2.RemediatedCondition, v2.TestSuccessCondition, v2.ReleasedCondition}
+	var sumConds []string
+	for _, t := range summaryPriority(req.Object) {
+		if t == v2.TestSuccessCondition && (!req.Object.GetTest().Enable || req.Object.GetTest().IgnoreFailures) {
+			continue
+		}
+		sumConds = append(sumConds, t)
 	}
 
-	// Remove any stale TestSuccess condition as soon as tests are disabled.
+	// Remove any stale TestSuccess condition as soon as tests are disabled,
+	// or mark it as stale if it must be retained.
 	if !req.Object.GetTest().Enable {
-		conditions.Delete(req.Object, v2.TestSuccessCondition)
+		if req.Object.GetTest().RetainConditionWhenDisabled {
+			markTestSuccessStale(req.Object)
+		} else {
+			conditions.Delete(req.Object, v2.TestSuccessCondition)
+		}
 	}
 
+	req.Object.Status.RemediationRetriesRemaining = remediationRetriesRemaining(req.Object)
+
 	conds := req.Object.Status.Conditions
 	if len(conds) == 0 {
 		// Nothing to summarize if there are no conditions.
@@ -170,10 +456,18 @@ func summarize(req *Request) {
 		return (conds[i].ObservedGeneration >= conds[j].ObservedGeneration) && (iPos < jPos)
 	})
 
-	status := conds[0].Status
+	summary := &conds[0]
+
+	// A failed health check of the resources of the latest release takes
+	// precedence, as it invalidates the success of the other conditions.
+	if conditions.HasAnyReason(req.Object, v2.ReleasedCondition, v2.HealthCheckFailedReason) {
+		summary = conditions.Get(req.Object, v2.ReleasedCondition)
+	}
+
+	status := summary.Status
 
 	// Any remediated state is considered an error.
-	if conds[0].Type == v2.RemediatedCondition {
+	if summary.Type == v2.RemediatedCondition {
 		status = metav1.ConditionFalse
 	}
 
@@ -184,12 +478,226 @@ func summarize(req *Request) {
 	conditions.Set(req.Object, &metav1.Condition{
 		Type:               meta.ReadyCondition,
 		Status:             status,
-		Reason:             conds[0].Reason,
-		Message:            conds[0].Message,
+		Reason:             summary.Reason,
+		Message:            summary.Message,
 		ObservedGeneration: req.Object.Generation,
 	})
 }
 
+// defaultSummaryPriority is the default priority of the conditions which are
+// summarized into the Ready condition.
+var defaultSummaryPriority = []string{v2.RemediatedCondition, v2.TestSuccessCondition, v2.ReleasedCondition}
+
+// msgTestSuccessStale is appended to the message of a TestSuccess condition
+// which is retained while tests are disabled.
+const msgTestSuccessStale = " (stale: tests are disabled)"
+
+// markTestSuccessStale marks the TestSuccess condition of the given object
+// as stale by appending msgTestSuccessStale to its message, if it is not
+// marked already.
+func markTestSuccessStale(obj *v2.HelmRelease) {
+	cond := conditions.Get(obj, v2.TestSuccessCondition)
+	if cond == nil || strings.HasSuffix(cond.Message, msgTestSuccessStale) {
+		return
+	}
+	stale := cond.DeepCopy()
+	stale.Message += msgTestSuccessStale
+	conditions.Set(obj, stale)
+}
+
+// summaryPriority returns the priority of the conditions which are summarized
+// into the Ready condition of the given object, as configured using the
+// v2.SummaryPriorityAnnotation. The condition types listed in the annotation
+// take precedence in the order given, followed by the remaining condition
+// types in their default order. When the annotation is not set, or contains
+// an unknown or duplicate condition type, the default priority is returned.
+func summaryPriority(obj *v2.HelmRelease) []string {
+	value, ok := obj.GetAnnotations()[v2.SummaryPriorityAnnotation]
+	if !ok || strings.TrimSpace(value) == "" {
+		return defaultSummaryPriority
+	}
+
+	priority := make([]string, 0, len(defaultSummaryPriority))
+	for _, t := range strings.Split(value, ",") {
+		pos, ok := inStringSlice(defaultSummaryPriority, strings.TrimSpace(t))
+		if !ok {
+			return defaultSummaryPriority
+		}
+		if _, ok = inStringSlice(priority, defaultSummaryPriority[pos]); ok {
+			return defaultSummaryPriority
+		}
+		priority = append(priority, defaultSummaryPriority[pos])
+	}
+	for _, t := range defaultSummaryPriority {
+		if _, ok := inStringSlice(priority, t); !ok {
+			priority = append(priority, t)
+		}
+	}
+	return priority
+}
+
+// releaseErrorMessage returns the message of the given Helm release action
+// error. When the error is the result of the chart failing to render, the
+// message is enriched with the paths of any required values which are
+// missing.
+func releaseErrorMessage(req *Request, err error) string {
+	msg := strings.TrimSpace(err.Error())
+	if chartutil.IsRenderError(err) {
+		if missing := chartutil.MissingRequiredValues(req.Chart, req.Values); len(missing) > 0 {
+			msg = fmt.Sprintf("%s (missing required values: %s)", msg, strings.Join(missing, ", "))
+		}
+	}
+	return msg
+}
+
+// releaseFailureReason returns the condition reason for the failure of a
+// Helm action with the given error. This is v2.CRDConflictReason if the
+// CustomResourceDefinitions of the chart could not be applied due to a
+// conflict, or the given reason otherwise.
+func releaseFailureReason(err error, reason string) string {
+	if errors.Is(err, action.ErrCRDConflict) {
+		return v2.CRDConflictReason
+	}
+	return reason
+}
+
+// classifyFailure returns the v2.FailureClass of the failure of a Helm
+// action with the given error. Errors which can not be attributed to the
+// rendering of the chart, the Helm storage, a timeout or a change of an
+// immutable field are classified as a failure to roll out the release.
+func classifyFailure(err error) v2.FailureClass {
+	if err == nil {
+		return ""
+	}
+	msg := err.Error()
+	switch {
+	case errors.Is(err, action.ErrJobsNotComplete):
+		return v2.FailureClassJobTimeout
+	case errors.Is(err, context.DeadlineExceeded), wait.Interrupted(err),
+		strings.Contains(msg, "timed out waiting for the condition"),
+		strings.Contains(msg, context.DeadlineExceeded.Error()):
+		return v2.FailureClassTimeout
+	case errors.Is(err, helmdriver.ErrReleaseExists), errors.Is(err, helmdriver.ErrInvalidKey),
+		strings.Contains(msg, "create: failed to create"),
+		strings.Contains(msg, "update: failed to update"),
+		strings.Contains(msg, "query: failed to query"):
+		return v2.FailureClassStorage
+	case strings.Contains(msg, "execution error at"),
+		strings.Contains(msg, "parse error at"),
+		strings.Contains(msg, "YAML parse error"),
+		strings.Contains(msg, "values don't meet the specifications of the schema"),
+		strings.Contains(msg, "unable to build kubernetes objects from release manifest"):
+		return v2.FailureClassRender
+	case action.IsImmutableFieldError(err):
+		return v2.FailureClassImmutableField
+	default:
+		return v2.FailureClassRollout
+	}
+}
+
+// countFailure increments the failure count of the given remediation for
+// the given object, unless the failure occurred within the retry delay of
+// the remediation since the first failure. The time of the first failure is
+// recorded on the object if not set. It returns true if the failure was
+// counted.
+func countFailure(obj *v2.HelmRelease, remediation v2.Remediation, now time.Time) bool {
+	if obj.Status.FirstFailureTime == nil {
+		t := metav1.NewTime(now)
+		obj.Status.FirstFailureTime = &t
+	}
+	if retryDelayRemaining(obj, remediation, now) > 0 {
+		return false
+	}
+	remediation.IncrementFailureCount(obj)
+	return true
+}
+
+// RetryDelayRemaining returns the time remaining of the retry delay of the
+// active remediation of the given object, since its first failure. It
+// returns zero if there is no active remediation or failure, or the delay
+// has passed.
+func RetryDelayRemaining(obj *v2.HelmRelease, now time.Time) time.Duration {
+	remediation := obj.GetActiveRemediation()
+	if remediation == nil {
+		return 0
+	}
+	return retryDelayRemaining(obj, remediation, now)
+}
+
+// retryDelayRemaining returns the time remaining of the retry delay of the
+// given remediation since the first failure of the given object, or zero if
+// there was no failure or the delay has passed.
+func retryDelayRemaining(obj *v2.HelmRelease, remediation v2.Remediation, now time.Time) time.Duration {
+	if obj.Status.FirstFailureTime == nil {
+		return 0
+	}
+	return max(obj.Status.FirstFailureTime.Add(remediation.GetRetryDelay()).Sub(now), 0)
+}
+
+// remediationRetriesRemaining returns the number of retries remaining for
+// the active remediation strategy of the given object. It returns nil if
+// there is no active remediation, the retries are unlimited, or the latest
+// release succeeded without being remediated or failing its tests.
+func remediationRetriesRemaining(obj *v2.HelmRelease) *int64 {
+	remediation := obj.GetActiveRemediation()
+	if remediation == nil || remediation.GetRetries() < 0 {
+		return nil
+	}
+	if conditions.IsTrue(obj, v2.ReleasedCondition) && !conditions.Has(obj, v2.RemediatedCondition) &&
+		!conditions.IsFalse(obj, v2.TestSuccessCondition) {
+		return nil
+	}
+	remaining := int64(remediation.GetRetries()) - remediation.GetFailureCount(obj)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &remaining
+}
+
+// fmtRemediationCause is the format used to append the cause of a
+// remediation to the message of the remediation result.
+const fmtRemediationCause = "%s (remediation of: %s)"
+
+// fmtRemediationCauseWithClass is the format used to append the cause of a
+// remediation to the message of the remediation result, when the class of
+// the failure is known.
+const fmtRemediationCauseWithClass = "%s (remediation of %s failure: %s)"
+
+// remediationCause returns the message of the failure which triggered the
+// remediation of the Helm release of the given object. This is either the
+// Released condition message, or the TestSuccess condition message if the
+// release succeeded but its tests failed.
+func remediationCause(obj *v2.HelmRelease) string {
+	if conditions.IsFalse(obj, v2.ReleasedCondition) {
+		return conditions.GetMessage(obj, v2.ReleasedCondition)
+	}
+	if conditions.IsFalse(obj, v2.TestSuccessCondition) {
+		return conditions.GetMessage(obj, v2.TestSuccessCondition)
+	}
+	return ""
+}
+
+// recordRemediation records the remediation action taken against the given
+// release on the status of the object, and returns the message with the
+// cause of the remediation appended to it.
+func recordRemediation(obj *v2.HelmRelease, action, release string, succeeded bool, msg string) string {
+	cause := remediationCause(obj)
+	obj.Status.RecordRemediation(v2.RemediationRecord{
+		Action:    action,
+		Release:   release,
+		Cause:     cause,
+		Succeeded: succeeded,
+		Time:      metav1.Now(),
+	})
+	if cause != "" {
+		if class := obj.Status.LastFailureClass; class != "" && conditions.IsFalse(obj, v2.ReleasedCondition) {
+			return fmt.Sprintf(fmtRemediationCauseWithClass, msg, class, cause)
+		}
+		msg = fmt.Sprintf(fmtRemediationCause, msg, cause)
+	}
+	return msg
+}
+
 // eventMessageWithLog returns an event message composed out of the given
 // message and any log messages by appending them to the message.
 func eventMessageWithLog(msg string, log *action.LogBuffer) string {
@@ -208,6 +716,10 @@ const (
 
 	// metaAppVersionKey is the key for the app version found in chart metadata.
 	metaAppVersionKey = "app-version"
+
+	// metaTestResultsKey is the key for the JSON encoded results of the test
+	// hooks.
+	metaTestResultsKey = "test-results"
 )
 
 // eventMeta returns the event (annotation) metadata based on the given
@@ -253,6 +765,57 @@ func addAppVersion(appVersion string) addMeta {
 	}
 }
 
+// testHookResult is the machine-readable result of a test hook, as included
+// in the event metadata.
+type testHookResult struct {
+	Name          string       `json:"name"`
+	Phase         string       `json:"phase,omitempty"`
+	LastStarted   *metav1.Time `json:"lastStarted,omitempty"`
+	LastCompleted *metav1.Time `json:"lastCompleted,omitempty"`
+	Duration      string       `json:"duration,omitempty"`
+}
+
+// addTestResults adds the results of the given test hooks as a JSON array
+// sorted by hook name. It does not add anything if there are no hooks, or
+// if the results can not be encoded.
+func addTestResults(hooks map[string]*v2.TestHookStatus) addMeta {
+	return func(m map[string]string) {
+		if len(hooks) == 0 {
+			return
+		}
+
+		results := make([]testHookResult, 0, len(hooks))
+		for name, h := range hooks {
+			result := testHookResult{Name: name}
+			if h != nil {
+				result.Phase = h.Phase
+				if !h.LastStarted.IsZero() {
+					result.LastStarted = h.LastStarted.DeepCopy()
+				}
+				if !h.LastCompleted.IsZero() {
+					result.LastCompleted = h.LastCompleted.DeepCopy()
+					if result.LastStarted != nil {
+						result.Duration = h.LastCompleted.Sub(h.LastStarted.Time).String()
+					}
+				}
+			}
+			results = append(results, result)
+		}
+		sort.Slice(results, func(i, j int) bool {
+			return results[i].Name < results[j].Name
+		})
+
+		b, err := json.Marshal(results)
+		if err != nil {
+			return
+		}
+		if m == nil {
+			m = make(map[string]string)
+		}
+		m[eventMetaGroupKey(metaTestResultsKey)] = string(b)
+	}
+}
+
 // eventMetaGroupKey returns the event (annotation) metadata key prefixed with
 // the group.
 func eventMetaGroupKey(key string) string {
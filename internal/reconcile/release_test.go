@@ -17,12 +17,22 @@ limitations under the License.
 package reconcile
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"testing"
+	"time"
+	"unicode/utf8"
 
 	"github.com/go-logr/logr"
 	. "github.com/onsi/gomega"
+	helmaction "helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmstorage "helm.sh/helm/v3/pkg/storage"
+	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
+	helmtime "helm.sh/helm/v3/pkg/time"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/fluxcd/pkg/apis/kustomize"
@@ -31,6 +41,8 @@ import (
 
 	v2 "github.com/fluxcd/helm-controller/api/v2"
 	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/release"
+	"github.com/fluxcd/helm-controller/internal/testutil"
 )
 
 const (
@@ -90,6 +102,7 @@ func Test_summarize(t *testing.T) {
 	tests := []struct {
 		name           string
 		generation     int64
+		annotations    map[string]string
 		spec           *v2.HelmReleaseSpec
 		status         v2.HelmReleaseStatus
 		expectedStatus *v2.HelmReleaseStatus
@@ -134,6 +147,158 @@ func Test_summarize(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:       "with tests disabled deletes test condition",
+			generation: 1,
+			status: v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.TestSuccessCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.TestFailedReason,
+						Message:            "test hook(s) failure",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+			spec: &v2.HelmReleaseSpec{
+				Test: &v2.Test{
+					Enable:                      false,
+					RetainConditionWhenDisabled: false,
+				},
+			},
+			expectedStatus: &v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               meta.ReadyCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+		},
+		{
+			name:       "with tests disabled retains stale test condition",
+			generation: 1,
+			status: v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.TestSuccessCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.TestFailedReason,
+						Message:            "test hook(s) failure",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+			spec: &v2.HelmReleaseSpec{
+				Test: &v2.Test{
+					Enable:                      false,
+					RetainConditionWhenDisabled: true,
+				},
+			},
+			expectedStatus: &v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               meta.ReadyCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.TestSuccessCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.TestFailedReason,
+						Message:            "test hook(s) failure (stale: tests are disabled)",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+		},
+		{
+			name:       "with tests disabled retains test condition marked stale once",
+			generation: 1,
+			status: v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.TestSuccessCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.TestFailedReason,
+						Message:            "test hook(s) failure (stale: tests are disabled)",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+			spec: &v2.HelmReleaseSpec{
+				Test: &v2.Test{
+					Enable:                      false,
+					RetainConditionWhenDisabled: true,
+				},
+			},
+			expectedStatus: &v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               meta.ReadyCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.TestSuccessCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.TestFailedReason,
+						Message:            "test hook(s) failure (stale: tests are disabled)",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+		},
 		{
 			name:       "with tests enabled",
 			generation: 1,
@@ -356,22 +521,35 @@ func Test_summarize(t *testing.T) {
 			},
 		},
 		{
-			name:       "with remediation success",
-			generation: 1,
+			name:        "with custom priority",
+			generation:  1,
+			annotations: map[string]string{v2.SummaryPriorityAnnotation: "TestSuccess, Remediated"},
+			spec: &v2.HelmReleaseSpec{
+				Test: &v2.Test{
+					Enable: true,
+				},
+			},
 			status: v2.HelmReleaseStatus{
 				Conditions: []metav1.Condition{
 					{
 						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.TestSuccessCondition,
 						Status:             metav1.ConditionFalse,
-						Reason:             v2.UpgradeFailedReason,
-						Message:            "Upgrade failure",
+						Reason:             v2.TestFailedReason,
+						Message:            "test hook(s) failure",
 						ObservedGeneration: 1,
 					},
 					{
 						Type:               v2.RemediatedCondition,
-						Status:             metav1.ConditionTrue,
-						Reason:             v2.RollbackSucceededReason,
-						Message:            "Uninstall complete",
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.UninstallFailedReason,
+						Message:            "Uninstall failure",
 						ObservedGeneration: 1,
 					},
 				},
@@ -381,43 +559,64 @@ func Test_summarize(t *testing.T) {
 					{
 						Type:               meta.ReadyCondition,
 						Status:             metav1.ConditionFalse,
-						Reason:             v2.RollbackSucceededReason,
-						Message:            "Uninstall complete",
+						Reason:             v2.TestFailedReason,
+						Message:            "test hook(s) failure",
 						ObservedGeneration: 1,
 					},
 					{
 						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.TestSuccessCondition,
 						Status:             metav1.ConditionFalse,
-						Reason:             v2.UpgradeFailedReason,
-						Message:            "Upgrade failure",
+						Reason:             v2.TestFailedReason,
+						Message:            "test hook(s) failure",
 						ObservedGeneration: 1,
 					},
 					{
 						Type:               v2.RemediatedCondition,
-						Status:             metav1.ConditionTrue,
-						Reason:             v2.RollbackSucceededReason,
-						Message:            "Uninstall complete",
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.UninstallFailedReason,
+						Message:            "Uninstall failure",
 						ObservedGeneration: 1,
 					},
 				},
 			},
 		},
 		{
-			name:       "with stale ready",
-			generation: 1,
+			name:        "with invalid custom priority",
+			generation:  1,
+			annotations: map[string]string{v2.SummaryPriorityAnnotation: "TestSuccess,Unknown"},
+			spec: &v2.HelmReleaseSpec{
+				Test: &v2.Test{
+					Enable: true,
+				},
+			},
 			status: v2.HelmReleaseStatus{
 				Conditions: []metav1.Condition{
-					{
-						Type:    meta.ReadyCondition,
-						Status:  metav1.ConditionFalse,
-						Reason:  "ChartNotFound",
-						Message: "chart not found",
-					},
 					{
 						Type:               v2.ReleasedCondition,
 						Status:             metav1.ConditionTrue,
-						Reason:             v2.UpgradeSucceededReason,
-						Message:            "Upgrade finished",
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.TestSuccessCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.TestFailedReason,
+						Message:            "test hook(s) failure",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.RemediatedCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.UninstallFailedReason,
+						Message:            "Uninstall failure",
 						ObservedGeneration: 1,
 					},
 				},
@@ -426,42 +625,198 @@ func Test_summarize(t *testing.T) {
 				Conditions: []metav1.Condition{
 					{
 						Type:               meta.ReadyCondition,
-						Status:             metav1.ConditionTrue,
-						Reason:             v2.UpgradeSucceededReason,
-						Message:            "Upgrade finished",
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.UninstallFailedReason,
+						Message:            "Uninstall failure",
 						ObservedGeneration: 1,
 					},
 					{
 						Type:               v2.ReleasedCondition,
 						Status:             metav1.ConditionTrue,
-						Reason:             v2.UpgradeSucceededReason,
-						Message:            "Upgrade finished",
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.TestSuccessCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.TestFailedReason,
+						Message:            "test hook(s) failure",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.RemediatedCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.UninstallFailedReason,
+						Message:            "Uninstall failure",
 						ObservedGeneration: 1,
 					},
 				},
 			},
 		},
 		{
-			name:       "with stale observed generation",
-			generation: 5,
-			spec: &v2.HelmReleaseSpec{
-				Test: &v2.Test{
-					Enable: true,
-				},
-			},
+			name:        "with custom priority and tests disabled",
+			generation:  1,
+			annotations: map[string]string{v2.SummaryPriorityAnnotation: "testsuccess,Released"},
+			spec:        &v2.HelmReleaseSpec{},
 			status: v2.HelmReleaseStatus{
 				Conditions: []metav1.Condition{
 					{
 						Type:               v2.ReleasedCondition,
 						Status:             metav1.ConditionTrue,
-						Reason:             v2.UpgradeSucceededReason,
-						Message:            "Upgrade finished",
-						ObservedGeneration: 4,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
 					},
 					{
-						Type:               v2.RemediatedCondition,
-						Status:             metav1.ConditionTrue,
-						Reason:             v2.RollbackSucceededReason,
+						Type:               v2.TestSuccessCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.TestFailedReason,
+						Message:            "test hook(s) failure",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.RemediatedCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.UninstallFailedReason,
+						Message:            "Uninstall failure",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+			expectedStatus: &v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               meta.ReadyCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.InstallSucceededReason,
+						Message:            "Install complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.RemediatedCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.UninstallFailedReason,
+						Message:            "Uninstall failure",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+		},
+		{
+			name:       "with remediation success",
+			generation: 1,
+			status: v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.UpgradeFailedReason,
+						Message:            "Upgrade failure",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.RemediatedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.RollbackSucceededReason,
+						Message:            "Uninstall complete",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+			expectedStatus: &v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               meta.ReadyCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.RollbackSucceededReason,
+						Message:            "Uninstall complete",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.UpgradeFailedReason,
+						Message:            "Upgrade failure",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.RemediatedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.RollbackSucceededReason,
+						Message:            "Uninstall complete",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+		},
+		{
+			name:       "with stale ready",
+			generation: 1,
+			status: v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:    meta.ReadyCondition,
+						Status:  metav1.ConditionFalse,
+						Reason:  "ChartNotFound",
+						Message: "chart not found",
+					},
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.UpgradeSucceededReason,
+						Message:            "Upgrade finished",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+			expectedStatus: &v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               meta.ReadyCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.UpgradeSucceededReason,
+						Message:            "Upgrade finished",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.UpgradeSucceededReason,
+						Message:            "Upgrade finished",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+		},
+		{
+			name:       "with stale observed generation",
+			generation: 5,
+			spec: &v2.HelmReleaseSpec{
+				Test: &v2.Test{
+					Enable: true,
+				},
+			},
+			status: v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.UpgradeSucceededReason,
+						Message:            "Upgrade finished",
+						ObservedGeneration: 4,
+					},
+					{
+						Type:               v2.RemediatedCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.RollbackSucceededReason,
 						Message:            "Rollback finished",
 						ObservedGeneration: 3,
 					},
@@ -507,6 +862,58 @@ func Test_summarize(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:       "with failed health check",
+			generation: 1,
+			status: v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.HealthCheckFailedReason,
+						Message:            "Health check failed",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.TestSuccessCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.TestSucceededReason,
+						Message:            "test hook(s) succeeded",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+			spec: &v2.HelmReleaseSpec{
+				Test: &v2.Test{
+					Enable: true,
+				},
+			},
+			expectedStatus: &v2.HelmReleaseStatus{
+				Conditions: []metav1.Condition{
+					{
+						Type:               meta.ReadyCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.HealthCheckFailedReason,
+						Message:            "Health check failed",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.ReleasedCondition,
+						Status:             metav1.ConditionFalse,
+						Reason:             v2.HealthCheckFailedReason,
+						Message:            "Health check failed",
+						ObservedGeneration: 1,
+					},
+					{
+						Type:               v2.TestSuccessCondition,
+						Status:             metav1.ConditionTrue,
+						Reason:             v2.TestSucceededReason,
+						Message:            "test hook(s) succeeded",
+						ObservedGeneration: 1,
+					},
+				},
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -515,7 +922,8 @@ func Test_summarize(t *testing.T) {
 
 			obj := &v2.HelmRelease{
 				ObjectMeta: metav1.ObjectMeta{
-					Generation: tt.generation,
+					Generation:  tt.generation,
+					Annotations: tt.annotations,
 				},
 				Status: tt.status,
 			}
@@ -696,6 +1104,132 @@ func Test_RecordOnObject(t *testing.T) {
 				return nil
 			},
 		},
+		{
+			name: "record superseded release on upgrade",
+			obj: &v2.HelmRelease{
+				Status: v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						{Name: mockReleaseName, Namespace: mockReleaseNamespace, Version: 1, Status: helmrelease.StatusDeployed.String()},
+					},
+				},
+			},
+			r: observedReleases{
+				1: {
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Info:      helmrelease.Info{Status: helmrelease.StatusSuperseded},
+				},
+				2: {
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   2,
+					Info:      helmrelease.Info{Status: helmrelease.StatusDeployed},
+				},
+			},
+			testFunc: func(obj *v2.HelmRelease) error {
+				if len(obj.Status.History) != 2 {
+					return fmt.Errorf("want history length 2, got %d", len(obj.Status.History))
+				}
+				if latest := obj.Status.History.Latest(); latest.Version != 2 || latest.SupersededBy != 0 {
+					return fmt.Errorf("want latest version 2 not superseded, got version %d superseded by %d",
+						latest.Version, latest.SupersededBy)
+				}
+				if prev := obj.Status.History[1]; prev.Status != helmrelease.StatusSuperseded.String() || prev.SupersededBy != 2 {
+					return fmt.Errorf("want previous release superseded by 2, got status %s superseded by %d",
+						prev.Status, prev.SupersededBy)
+				}
+				return nil
+			},
+		},
+		{
+			name: "record status reasons and preserve test hooks",
+			obj: &v2.HelmRelease{
+				Status: v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						func() *v2.Snapshot {
+							snap := &v2.Snapshot{Name: mockReleaseName, Namespace: mockReleaseNamespace, Version: 1,
+								Status: helmrelease.StatusDeployed.String(), StatusReason: "Install complete"}
+							snap.SetTestHooks(map[string]*v2.TestHookStatus{"test": {Phase: helmrelease.HookPhaseSucceeded.String()}})
+							return snap
+						}(),
+					},
+				},
+			},
+			r: observedReleases{
+				1: {
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Info:      helmrelease.Info{Status: helmrelease.StatusSuperseded, Description: "Install complete"},
+				},
+				2: {
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   2,
+					Info: helmrelease.Info{Status: helmrelease.StatusFailed,
+						Description: fmt.Sprintf("Upgrade %q failed: timed out", mockReleaseName)},
+				},
+			},
+			testFunc: func(obj *v2.HelmRelease) error {
+				if len(obj.Status.History) != 2 {
+					return fmt.Errorf("want history length 2, got %d", len(obj.Status.History))
+				}
+				latest, prev := obj.Status.History[0], obj.Status.History[1]
+				if latest.Version != 2 || latest.Status != helmrelease.StatusFailed.String() ||
+					latest.StatusReason != fmt.Sprintf("Upgrade %q failed: timed out", mockReleaseName) {
+					return fmt.Errorf("want latest version 2 failed with reason, got version %d %s: %s",
+						latest.Version, latest.Status, latest.StatusReason)
+				}
+				if latest.HasBeenTested() {
+					return fmt.Errorf("want latest version 2 without test hooks")
+				}
+				if prev.Version != 1 || prev.Status != helmrelease.StatusSuperseded.String() || prev.StatusReason != "Install complete" {
+					return fmt.Errorf("want previous version 1 superseded with reason, got version %d %s: %s",
+						prev.Version, prev.Status, prev.StatusReason)
+				}
+				if hooks := prev.GetTestHooks(); len(hooks) != 1 || hooks["test"] == nil {
+					return fmt.Errorf("want test hooks of version 1 to be preserved, got %v", hooks)
+				}
+				return nil
+			},
+		},
+		{
+			name: "record superseded failed release on rollback",
+			obj: &v2.HelmRelease{
+				Status: v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						{Name: mockReleaseName, Namespace: mockReleaseNamespace, Version: 2, Status: helmrelease.StatusFailed.String()},
+						{Name: mockReleaseName, Namespace: mockReleaseNamespace, Version: 1, Status: helmrelease.StatusSuperseded.String(), SupersededBy: 2},
+					},
+				},
+			},
+			r: observedReleases{
+				3: {
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   3,
+					Info:      helmrelease.Info{Status: helmrelease.StatusDeployed},
+				},
+			},
+			testFunc: func(obj *v2.HelmRelease) error {
+				if len(obj.Status.History) != 3 {
+					return fmt.Errorf("want history length 3, got %d", len(obj.Status.History))
+				}
+				for _, want := range []struct{ version, supersededBy int }{{3, 0}, {2, 3}, {1, 2}} {
+					var got *v2.Snapshot
+					for _, snap := range obj.Status.History {
+						if snap.Version == want.version {
+							got = snap
+						}
+					}
+					if got == nil || got.SupersededBy != want.supersededBy {
+						return fmt.Errorf("want version %d superseded by %d, got %v", want.version, want.supersededBy, got)
+					}
+				}
+				return nil
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -713,3 +1247,532 @@ func Test_RecordOnObject(t *testing.T) {
 	}
 
 }
+
+func Test_recordRemediation(t *testing.T) {
+	t.Run("records remediation with cause", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{}
+		conditions.MarkFalse(obj, v2.ReleasedCondition, v2.UpgradeFailedReason, "upgrade failed")
+
+		msg := recordRemediation(obj, v2.RemediationActionRollback, "ns/name.v1", true, "rollback succeeded")
+		g.Expect(msg).To(Equal("rollback succeeded (remediation of: upgrade failed)"))
+		g.Expect(obj.Status.Remediations).To(HaveLen(1))
+		g.Expect(obj.Status.Remediations[0].Attempt).To(Equal(int64(1)))
+		g.Expect(obj.Status.Remediations[0].Action).To(Equal(v2.RemediationActionRollback))
+		g.Expect(obj.Status.Remediations[0].Release).To(Equal("ns/name.v1"))
+		g.Expect(obj.Status.Remediations[0].Cause).To(Equal("upgrade failed"))
+		g.Expect(obj.Status.Remediations[0].Succeeded).To(BeTrue())
+	})
+
+	t.Run("records remediation with failure class", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{}
+		obj.Status.LastFailureClass = v2.FailureClassRollout
+		conditions.MarkFalse(obj, v2.ReleasedCondition, v2.UpgradeFailedReason, "upgrade failed")
+
+		msg := recordRemediation(obj, v2.RemediationActionRollback, "ns/name.v1", true, "rollback succeeded")
+		g.Expect(msg).To(Equal("rollback succeeded (remediation of Rollout failure: upgrade failed)"))
+		g.Expect(obj.Status.Remediations[0].Cause).To(Equal("upgrade failed"))
+	})
+
+	t.Run("uses test failure as cause", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{}
+		conditions.MarkTrue(obj, v2.ReleasedCondition, v2.UpgradeSucceededReason, "upgrade succeeded")
+		conditions.MarkFalse(obj, v2.TestSuccessCondition, v2.TestFailedReason, "test failed")
+
+		msg := recordRemediation(obj, v2.RemediationActionUninstall, "ns/name.v2", false, "uninstall failed")
+		g.Expect(msg).To(Equal("uninstall failed (remediation of: test failed)"))
+		g.Expect(obj.Status.Remediations[0].Cause).To(Equal("test failed"))
+		g.Expect(obj.Status.Remediations[0].Succeeded).To(BeFalse())
+	})
+
+	t.Run("accumulates bounded records", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{}
+		for i := 0; i < v2.MaxRemediationRecords+2; i++ {
+			msg := recordRemediation(obj, v2.RemediationActionRollback, "ns/name.v1", true, "rollback succeeded")
+			g.Expect(msg).To(Equal("rollback succeeded"))
+		}
+		g.Expect(obj.Status.Remediations).To(HaveLen(v2.MaxRemediationRecords))
+		g.Expect(obj.Status.Remediations[0].Attempt).To(Equal(int64(v2.MaxRemediationRecords + 2)))
+
+		obj.Status.ClearFailures()
+		g.Expect(obj.Status.Remediations).To(BeEmpty())
+	})
+}
+
+func Test_classifyFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want v2.FailureClass
+	}{
+		{
+			name: "no error",
+		},
+		{
+			name: "render error",
+			err:  errors.New("template: chart/templates/deployment.yaml:12:4: executing \"chart/templates/deployment.yaml\" at <fail>: execution error at (chart/templates/deployment.yaml:12:4): boom"),
+			want: v2.FailureClassRender,
+		},
+		{
+			name: "manifest build error",
+			err:  errors.New("unable to build kubernetes objects from release manifest: resource mapping not found"),
+			want: v2.FailureClassRender,
+		},
+		{
+			name: "storage error",
+			err:  errors.New("create: failed to create: secrets \"sh.helm.release.v1.name.v1\" is forbidden"),
+			want: v2.FailureClassStorage,
+		},
+		{
+			name: "storage driver error",
+			err:  fmt.Errorf("upgrade failed: %w", helmdriver.ErrReleaseExists),
+			want: v2.FailureClassStorage,
+		},
+		{
+			name: "timeout error",
+			err:  fmt.Errorf("resource not ready: %w", context.DeadlineExceeded),
+			want: v2.FailureClassTimeout,
+		},
+		{
+			name: "wait timeout error",
+			err:  errors.New("timed out waiting for the condition"),
+			want: v2.FailureClassTimeout,
+		},
+		{
+			name: "job timeout error",
+			err:  fmt.Errorf("%w: %w", action.ErrJobsNotComplete, context.DeadlineExceeded),
+			want: v2.FailureClassJobTimeout,
+		},
+		{
+			name: "immutable field error",
+			err:  errors.New("cannot patch \"name\" with kind Deployment: field is immutable"),
+			want: v2.FailureClassImmutableField,
+		},
+		{
+			name: "rollout error",
+			err:  errors.New("cannot patch \"name\" with kind Deployment: admission webhook \"validate\" denied the request"),
+			want: v2.FailureClassRollout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(classifyFailure(tt.err)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_releaseFailureReason(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(releaseFailureReason(fmt.Errorf("timed out"), v2.UpgradeFailedReason)).To(Equal(v2.UpgradeFailedReason))
+	g.Expect(releaseFailureReason(fmt.Errorf("failed to apply CustomResourceDefinitions: %w", action.ErrCRDConflict),
+		v2.UpgradeFailedReason)).To(Equal(v2.CRDConflictReason))
+}
+
+func Test_mutateCRDsPolicy(t *testing.T) {
+	g := NewWithT(t)
+
+	obs := mutateCRDsPolicy("")(&v2.HelmRelease{}, release.Observation{})
+	g.Expect(obs.CRDsPolicy).To(Equal(action.DefaultCRDPolicy))
+
+	obs = mutateCRDsPolicy(v2.CreateReplace)(&v2.HelmRelease{}, release.Observation{})
+	g.Expect(obs.CRDsPolicy).To(Equal(v2.CreateReplace))
+}
+
+func Test_countFailure(t *testing.T) {
+	now := time.Now()
+	remediation := v2.UpgradeRemediation{RetryDelay: &metav1.Duration{Duration: time.Minute}}
+
+	t.Run("counts failure without retry delay", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{}
+		g.Expect(countFailure(obj, v2.UpgradeRemediation{}, now)).To(BeTrue())
+		g.Expect(obj.Status.UpgradeFailures).To(Equal(int64(1)))
+		g.Expect(obj.Status.FirstFailureTime).ToNot(BeNil())
+	})
+
+	t.Run("does not count failures within retry delay", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{}
+		g.Expect(countFailure(obj, remediation, now)).To(BeFalse())
+		g.Expect(countFailure(obj, remediation, now.Add(30*time.Second))).To(BeFalse())
+		g.Expect(obj.Status.UpgradeFailures).To(BeZero())
+		g.Expect(obj.Status.FirstFailureTime.Time).To(BeTemporally("==", now))
+	})
+
+	t.Run("counts failures after retry delay", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{}
+		g.Expect(countFailure(obj, remediation, now)).To(BeFalse())
+		g.Expect(countFailure(obj, remediation, now.Add(time.Minute))).To(BeTrue())
+		g.Expect(countFailure(obj, remediation, now.Add(2*time.Minute))).To(BeTrue())
+		g.Expect(obj.Status.UpgradeFailures).To(Equal(int64(2)))
+	})
+
+	t.Run("starts a new retry delay after reset", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{}
+		g.Expect(countFailure(obj, remediation, now)).To(BeFalse())
+		g.Expect(countFailure(obj, remediation, now.Add(time.Minute))).To(BeTrue())
+
+		obj.Status.ClearFailures()
+		g.Expect(obj.Status.FirstFailureTime).To(BeNil())
+		g.Expect(countFailure(obj, remediation, now.Add(2*time.Minute))).To(BeFalse())
+		g.Expect(obj.Status.UpgradeFailures).To(BeZero())
+	})
+}
+
+func TestRetryDelayRemaining(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	obj := &v2.HelmRelease{
+		Spec: v2.HelmReleaseSpec{
+			Upgrade: &v2.Upgrade{
+				Remediation: &v2.UpgradeRemediation{RetryDelay: &metav1.Duration{Duration: time.Minute}},
+			},
+		},
+	}
+	g.Expect(RetryDelayRemaining(obj, now)).To(BeZero())
+
+	obj.Status.FirstFailureTime = &metav1.Time{Time: now}
+	g.Expect(RetryDelayRemaining(obj, now)).To(BeZero())
+
+	obj.Status.LastAttemptedReleaseAction = v2.ReleaseActionUpgrade
+	g.Expect(RetryDelayRemaining(obj, now.Add(20*time.Second))).To(Equal(40 * time.Second))
+	g.Expect(RetryDelayRemaining(obj, now.Add(2*time.Minute))).To(BeZero())
+}
+
+func Test_remediationRetriesRemaining(t *testing.T) {
+	t.Run("counts down across consecutive failures", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{
+			Spec: v2.HelmReleaseSpec{
+				Upgrade: &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{Retries: 3},
+				},
+			},
+			Status: v2.HelmReleaseStatus{
+				LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+			},
+		}
+		req := &Request{Object: obj}
+		remediation := obj.GetActiveRemediation()
+
+		conditions.MarkTrue(obj, v2.ReleasedCondition, v2.InstallSucceededReason, "install succeeded")
+		summarize(req)
+		g.Expect(obj.Status.RemediationRetriesRemaining).To(BeNil())
+
+		for _, want := range []int64{2, 1, 0, 0} {
+			remediation.IncrementFailureCount(obj)
+			conditions.MarkFalse(obj, v2.ReleasedCondition, v2.UpgradeFailedReason, "upgrade failed")
+			summarize(req)
+			g.Expect(obj.Status.RemediationRetriesRemaining).ToNot(BeNil())
+			g.Expect(*obj.Status.RemediationRetriesRemaining).To(Equal(want))
+
+			conditions.MarkTrue(obj, v2.RemediatedCondition, v2.RollbackSucceededReason, "rollback succeeded")
+			summarize(req)
+			g.Expect(*obj.Status.RemediationRetriesRemaining).To(Equal(want))
+		}
+
+		// A successful upgrade clears the Remediated condition, which resets
+		// the countdown.
+		conditions.Delete(obj, v2.RemediatedCondition)
+		conditions.MarkTrue(obj, v2.ReleasedCondition, v2.UpgradeSucceededReason, "upgrade succeeded")
+		summarize(req)
+		g.Expect(obj.Status.RemediationRetriesRemaining).To(BeNil())
+	})
+
+	t.Run("counts test failures", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{
+			Spec: v2.HelmReleaseSpec{
+				Install: &v2.Install{
+					Remediation: &v2.InstallRemediation{Retries: 1},
+				},
+				Test: &v2.Test{Enable: true},
+			},
+			Status: v2.HelmReleaseStatus{
+				LastAttemptedReleaseAction: v2.ReleaseActionInstall,
+				InstallFailures:            1,
+			},
+		}
+		conditions.MarkTrue(obj, v2.ReleasedCondition, v2.InstallSucceededReason, "install succeeded")
+		conditions.MarkFalse(obj, v2.TestSuccessCondition, v2.TestFailedReason, "test failed")
+
+		summarize(&Request{Object: obj})
+		g.Expect(obj.Status.RemediationRetriesRemaining).ToNot(BeNil())
+		g.Expect(*obj.Status.RemediationRetriesRemaining).To(BeZero())
+	})
+
+	t.Run("not set for unlimited retries", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{
+			Spec: v2.HelmReleaseSpec{
+				Upgrade: &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{Retries: -1},
+				},
+			},
+			Status: v2.HelmReleaseStatus{
+				LastAttemptedReleaseAction: v2.ReleaseActionUpgrade,
+				UpgradeFailures:            5,
+			},
+		}
+		conditions.MarkFalse(obj, v2.ReleasedCondition, v2.UpgradeFailedReason, "upgrade failed")
+
+		summarize(&Request{Object: obj})
+		g.Expect(obj.Status.RemediationRetriesRemaining).To(BeNil())
+	})
+
+	t.Run("not set without active remediation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		obj := &v2.HelmRelease{}
+		conditions.MarkFalse(obj, v2.ReleasedCondition, v2.UpgradeFailedReason, "upgrade failed")
+
+		summarize(&Request{Object: obj})
+		g.Expect(obj.Status.RemediationRetriesRemaining).To(BeNil())
+	})
+}
+
+func Test_truncateReleaseNotes(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(truncateReleaseNotes("", 32)).To(BeEmpty())
+	g.Expect(truncateReleaseNotes("Thank you for installing.", 32)).To(Equal("Thank you for installing."))
+
+	got := truncateReleaseNotes(strings.Repeat("a", 64), 32)
+	g.Expect(got).To(HaveLen(32))
+	g.Expect(got).To(HaveSuffix(releaseNotesTruncatedMarker))
+
+	// A multibyte character cut off by the truncation is removed.
+	got = truncateReleaseNotes(strings.Repeat("é", 32), 32)
+	g.Expect(utf8.ValidString(got)).To(BeTrue())
+	g.Expect(len(got)).To(BeNumerically("<=", 32))
+	g.Expect(got).To(HaveSuffix(releaseNotesTruncatedMarker))
+}
+
+func Test_observedReleases_recordNotesOnObject(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{}
+	observedReleases{}.recordNotesOnObject(obj)
+	g.Expect(obj.Status.LastReleaseNotes).To(BeEmpty())
+
+	observedReleases{
+		1: {Version: 1, Info: helmrelease.Info{Notes: "first"}},
+		2: {Version: 2, Info: helmrelease.Info{Notes: "second"}},
+	}.recordNotesOnObject(obj)
+	g.Expect(obj.Status.LastReleaseNotes).To(Equal("second"))
+}
+
+func Test_pruneHistory(t *testing.T) {
+	now := time.Now()
+
+	// A long history with mixed statuses and deploy timestamps, with the
+	// age of each release in hours by version.
+	history := []struct {
+		version int
+		status  helmrelease.Status
+		age     time.Duration
+	}{
+		{1, helmrelease.StatusSuperseded, 240},
+		{2, helmrelease.StatusSuperseded, 200},
+		{3, helmrelease.StatusFailed, 150},
+		{4, helmrelease.StatusSuperseded, 100},
+		{5, helmrelease.StatusSuperseded, 30},
+		{6, helmrelease.StatusFailed, 26},
+		{7, helmrelease.StatusSuperseded, 20},
+		{8, helmrelease.StatusDeployed, 12},
+		{9, helmrelease.StatusFailed, 2},
+	}
+
+	tests := []struct {
+		name          string
+		retention     *v2.HistoryRetention
+		wantVersions  []int
+		wantSnapshots []int
+	}{
+		{
+			name:          "no retention",
+			wantVersions:  []int{1, 2, 3, 4, 5, 6, 7, 8, 9},
+			wantSnapshots: []int{1, 2, 3, 4, 5, 6, 7, 8, 9},
+		},
+		{
+			name:          "prunes releases older than max age",
+			retention:     &v2.HistoryRetention{MaxAge: &metav1.Duration{Duration: 24 * time.Hour}},
+			wantVersions:  []int{7, 8, 9},
+			wantSnapshots: []int{9, 8, 7},
+		},
+		{
+			name:          "retains latest successful release beyond max age",
+			retention:     &v2.HistoryRetention{MaxAge: &metav1.Duration{Duration: time.Hour}},
+			wantVersions:  []int{8, 9},
+			wantSnapshots: []int{9, 8},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cfg := &helmaction.Configuration{Releases: helmstorage.Init(helmdriver.NewMemory())}
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Name: mockReleaseName, Namespace: mockReleaseNamespace},
+				Spec:       v2.HelmReleaseSpec{HistoryRetention: tt.retention},
+			}
+			for _, h := range history {
+				rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   h.version,
+					Status:    h.status,
+					Chart:     testutil.BuildChart(),
+				})
+				rls.Info.LastDeployed = helmtime.Time{Time: now.Add(-h.age * time.Hour)}
+				g.Expect(cfg.Releases.Create(rls)).To(Succeed())
+				obj.Status.History = append(obj.Status.History, release.ObservedToSnapshot(release.ObserveRelease(rls)))
+			}
+
+			g.Expect(pruneHistory(cfg, obj, now)).To(Succeed())
+
+			releases, err := cfg.Releases.History(mockReleaseName)
+			g.Expect(err).ToNot(HaveOccurred())
+			var versions []int
+			for _, rls := range releases {
+				versions = append(versions, rls.Version)
+			}
+			g.Expect(versions).To(ConsistOf(tt.wantVersions))
+
+			var snapshots []int
+			for _, s := range obj.Status.History {
+				snapshots = append(snapshots, s.Version)
+			}
+			g.Expect(snapshots).To(Equal(tt.wantSnapshots))
+		})
+	}
+}
+
+func Test_historyLimitMessage(t *testing.T) {
+	tests := []struct {
+		name       string
+		maxHistory int
+		statuses   []helmrelease.Status
+		want       string
+	}{
+		{
+			name:       "unlimited history",
+			maxHistory: 0,
+			statuses:   []helmrelease.Status{helmrelease.StatusSuperseded, helmrelease.StatusDeployed},
+		},
+		{
+			name:       "no rollback target",
+			maxHistory: 1,
+			statuses:   []helmrelease.Status{helmrelease.StatusDeployed},
+		},
+		{
+			name:       "below max history",
+			maxHistory: 3,
+			statuses:   []helmrelease.Status{helmrelease.StatusSuperseded, helmrelease.StatusDeployed},
+		},
+		{
+			name:       "max history reached with retained rollback target",
+			maxHistory: 3,
+			statuses:   []helmrelease.Status{helmrelease.StatusSuperseded, helmrelease.StatusSuperseded, helmrelease.StatusDeployed},
+		},
+		{
+			name:       "max history reached with pruned rollback target",
+			maxHistory: 2,
+			statuses:   []helmrelease.Status{helmrelease.StatusSuperseded, helmrelease.StatusDeployed},
+			want:       "Release history has reached the maximum of 2 revisions: rollback target mock-ns/mock-release.v1 is not retained by the next release",
+		},
+		{
+			name:       "max history exceeded with pruned rollback target",
+			maxHistory: 2,
+			statuses:   []helmrelease.Status{helmrelease.StatusSuperseded, helmrelease.StatusSuperseded, helmrelease.StatusDeployed},
+			want:       "Release history has reached the maximum of 2 revisions: rollback target mock-ns/mock-release.v2 is not retained by the next release",
+		},
+		{
+			name:       "max history reached with deployed rollback target",
+			maxHistory: 3,
+			statuses: []helmrelease.Status{helmrelease.StatusSuperseded, helmrelease.StatusDeployed,
+				helmrelease.StatusFailed, helmrelease.StatusFailed},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cfg := &helmaction.Configuration{Releases: helmstorage.Init(helmdriver.NewMemory())}
+			obj := &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Name: mockReleaseName, Namespace: mockReleaseNamespace},
+				Spec:       v2.HelmReleaseSpec{MaxHistory: &tt.maxHistory},
+			}
+			for i, status := range tt.statuses {
+				rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   i + 1,
+					Status:    status,
+					Chart:     testutil.BuildChart(),
+				})
+				g.Expect(cfg.Releases.Create(rls)).To(Succeed())
+				obj.Status.History = append(obj.Status.History, release.ObservedToSnapshot(release.ObserveRelease(rls)))
+			}
+
+			got, err := historyLimitMessage(cfg, obj)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_pruneBackups(t *testing.T) {
+	g := NewWithT(t)
+
+	store := action.NewMemoryBackupStore()
+	for v := 1; v <= 3; v++ {
+		_, err := store.Save(testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+			Name:      mockReleaseName,
+			Namespace: mockReleaseNamespace,
+			Version:   v,
+		}))
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+
+	obj := &v2.HelmRelease{
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName: mockReleaseName,
+			Upgrade:     &v2.Upgrade{Backup: true},
+		},
+		Status: v2.HelmReleaseStatus{
+			History: v2.Snapshots{
+				{Name: mockReleaseName, Version: 4},
+				{Name: mockReleaseName, Version: 3, Backup: action.BackupName(mockReleaseName, 3)},
+			},
+		},
+	}
+
+	// Only the backup of the release in the history is retained.
+	g.Expect(pruneBackups(store, obj)).To(Succeed())
+	names, err := store.List(mockReleaseName)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(names).To(Equal([]string{action.BackupName(mockReleaseName, 3)}))
+
+	// Without a store, nothing is pruned.
+	g.Expect(pruneBackups(nil, obj)).To(Succeed())
+}
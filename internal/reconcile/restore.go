@@ -0,0 +1,221 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	"github.com/fluxcd/pkg/runtime/conditions"
+	"github.com/fluxcd/pkg/runtime/logger"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/chartutil"
+	"github.com/fluxcd/helm-controller/internal/digest"
+)
+
+// Restore is an ActionReconciler which restores the Helm release of a
+// Request.Object from the latest backup recorded in the Status.History, on
+// request of the user through the v2.RestoreRequestAnnotation. Backups are
+// taken before an upgrade when Upgrade.Backup is enabled.
+//
+// When the backed up release version is no longer present in the Helm
+// storage, it is re-created in the storage from the backup. The release is
+// then rolled back to this version, which results in a new release with the
+// state of the backup. The writes to the Helm storage during the rollback are
+// observed, and update the Status.History field.
+//
+// After a successful restore, the object is marked with Remediated=True,
+// Status.ManualRollbackActive is set to prevent the release from being
+// upgraded again until the chart or values change, and an event is emitted.
+// When the restore fails, the object is marked with Remediated=False and a
+// warning event is emitted.
+//
+// When the Request.Object does not have a backup of a previous release in
+// the Status.History, it returns an error of type ErrMissingRollbackTarget.
+// Any other returned error indicates the caller should retry as it did not
+// cause a change to the Helm storage.
+//
+// At the end of the reconciliation, the Status.Conditions are summarized and
+// propagated to the Ready condition on the Request.Object.
+type Restore struct {
+	configFactory *action.ConfigFactory
+	eventRecorder record.EventRecorder
+}
+
+// NewRestore returns a new Restore reconciler configured with the provided
+// values.
+func NewRestore(configFactory *action.ConfigFactory, eventRecorder record.EventRecorder) *Restore {
+	return &Restore{
+		configFactory: configFactory,
+		eventRecorder: eventRecorder,
+	}
+}
+
+func (r *Restore) Reconcile(ctx context.Context, req *Request) error {
+	var (
+		cur    = req.Object.Status.History.Latest().DeepCopy()
+		logBuf = action.NewLogBuffer(action.NewDebugLog(ctrl.LoggerFrom(ctx).V(logger.DebugLevel)), 10)
+		cfg    = r.configFactory.Build(logBuf.Log, observeRollback(req.Object, time.Now()))
+	)
+
+	defer summarize(req)
+
+	target := restoreTarget(req.Object.Status.History)
+	if target == nil {
+		return fmt.Errorf("%w: no backup of a previous release to restore", ErrMissingRollbackTarget)
+	}
+	target = target.DeepCopy()
+
+	// Confirm the backup and current point to the same release.
+	if target.Name != cur.Name || target.Namespace != cur.Namespace {
+		return fmt.Errorf("%w: backed up release name or namespace %s does not match current %s",
+			ErrReleaseMismatch, target.FullReleaseName(), cur.FullReleaseName())
+	}
+
+	// Re-create the backed up release if it has been pruned.
+	if err := r.recreate(target); err != nil {
+		r.failure(req, target, logBuf, err)
+		return err
+	}
+
+	// Run the Helm rollback action to the backed up release.
+	if err := action.Rollback(cfg, req.Object, target.Name, action.RollbackToVersion(target.Version)); err != nil {
+		r.failure(req, target, logBuf, err)
+
+		// Return error if we did not store a release, as this does not
+		// affect state and the caller should e.g. retry.
+		if newCur := req.Object.Status.History.Latest(); newCur == nil || newCur.Digest == cur.Digest {
+			return err
+		}
+
+		return nil
+	}
+
+	r.success(req, target)
+	return nil
+}
+
+// recreate re-creates the release of the given Snapshot in the Helm storage
+// from its backup, if it is no longer present in the storage.
+func (r *Restore) recreate(snap *v2.Snapshot) error {
+	cfg := r.configFactory.Build(nil)
+	if _, err := cfg.Releases.Get(snap.Name, snap.Version); err == nil {
+		return nil
+	} else if !errors.Is(err, helmdriver.ErrReleaseNotFound) {
+		return fmt.Errorf("failed to get release %s: %w", snap.FullReleaseName(), err)
+	}
+
+	if r.configFactory.Backups == nil {
+		return fmt.Errorf("failed to load backup %s: no backup store configured", snap.Backup)
+	}
+	rls, err := r.configFactory.Backups.Load(snap.Backup)
+	if err != nil {
+		return fmt.Errorf("failed to load backup %s: %w", snap.Backup, err)
+	}
+	if rls.Info != nil {
+		rls.Info.Status = helmrelease.StatusSuperseded
+	}
+	if err = cfg.Releases.Create(rls); err != nil {
+		return fmt.Errorf("failed to re-create release %s from backup %s: %w", snap.FullReleaseName(), snap.Backup, err)
+	}
+	return nil
+}
+
+func (r *Restore) Name() string {
+	return "restore"
+}
+
+func (r *Restore) Type() ReconcilerType {
+	return ReconcilerTypeRemediate
+}
+
+const (
+	// fmtRestoreFailure is the message format for a failed restore
+	// requested through the annotation.
+	fmtRestoreFailure = "Helm restore of release %s with chart %s from backup %s failed: %s"
+	// fmtRestoreSuccess is the message format for a successful restore
+	// requested through the annotation.
+	fmtRestoreSuccess = "Helm restore of release %s with chart %s from backup %s succeeded"
+)
+
+// failure records the failure of a restore in the status of the given
+// Request.Object by marking Remediated=False and emitting a warning event.
+func (r *Restore) failure(req *Request, target *v2.Snapshot, buffer *action.LogBuffer, err error) {
+	// Compose failure message.
+	msg := fmt.Sprintf(fmtRestoreFailure, target.FullReleaseName(), target.VersionedChartName(), target.Backup,
+		strings.TrimSpace(err.Error()))
+
+	// Mark restore failure on object.
+	req.Object.Status.Failures++
+	conditions.MarkFalse(req.Object, v2.RemediatedCondition, v2.RestoreFailedReason, "%s", msg)
+
+	// Record warning event, this message contains more data than the
+	// Condition summary.
+	r.eventRecorder.AnnotatedEventf(
+		req.Object,
+		eventMeta(target.ChartVersion, chartutil.DigestValues(digest.Canonical, req.Values).String(),
+			addAppVersion(target.AppVersion), addOCIDigest(target.OCIDigest)),
+		corev1.EventTypeWarning,
+		v2.RestoreFailedReason,
+		eventMessageWithLog(msg, buffer),
+	)
+}
+
+// success records the success of a restore in the status of the given
+// Request.Object by marking Remediated=True, holding the release at the
+// restored version, and emitting an event.
+func (r *Restore) success(req *Request, target *v2.Snapshot) {
+	// Compose success message.
+	msg := fmt.Sprintf(fmtRestoreSuccess, target.FullReleaseName(), target.VersionedChartName(), target.Backup)
+
+	// Mark restore success on object.
+	conditions.MarkTrue(req.Object, v2.RemediatedCondition, v2.RestoreSucceededReason, "%s", msg)
+	req.Object.Status.ManualRollbackActive = true
+
+	// Record event.
+	r.eventRecorder.AnnotatedEventf(
+		req.Object,
+		eventMeta(target.ChartVersion, chartutil.DigestValues(digest.Canonical, req.Values).String(),
+			addAppVersion(target.AppVersion), addOCIDigest(target.OCIDigest)),
+		corev1.EventTypeNormal,
+		v2.RestoreSucceededReason,
+		msg,
+	)
+}
+
+// restoreTarget returns the most recent Snapshot in the given history, other
+// than the latest, of which a backup was taken. It returns nil if there is
+// none.
+func restoreTarget(history v2.Snapshots) *v2.Snapshot {
+	for i, snap := range history {
+		if i > 0 && snap.Backup != "" {
+			return snap
+		}
+	}
+	return nil
+}
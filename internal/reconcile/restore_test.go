@@ -0,0 +1,168 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmstorage "helm.sh/helm/v3/pkg/storage"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/fluxcd/pkg/runtime/conditions"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/action"
+	"github.com/fluxcd/helm-controller/internal/release"
+	"github.com/fluxcd/helm-controller/internal/testutil"
+)
+
+func TestRestore_Reconcile(t *testing.T) {
+	tests := []struct {
+		name string
+		// backup configures whether a backup is taken of the first release.
+		backup bool
+		// pruned configures whether the first release is removed from the
+		// storage before restoring.
+		pruned bool
+		// wantErr is the error that is expected to be returned.
+		wantErr error
+		// expectVersion is the version of the latest release after
+		// restoring.
+		expectVersion int
+	}{
+		{
+			name:          "restore from backup of pruned release",
+			backup:        true,
+			pruned:        true,
+			expectVersion: 3,
+		},
+		{
+			name:          "restore from backup of release in storage",
+			backup:        true,
+			expectVersion: 3,
+		},
+		{
+			name:          "restore without backup",
+			wantErr:       ErrMissingRollbackTarget,
+			expectVersion: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			t.Cleanup(func() {
+				_ = testEnv.Delete(context.TODO(), namedNS)
+			})
+			releaseNamespace := namedNS.Name
+
+			releases := []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Version:   1,
+					Chart:     testutil.BuildChart(),
+					Status:    helmrelease.StatusSuperseded,
+					Namespace: releaseNamespace,
+				}),
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Version:   2,
+					Chart:     testutil.BuildChart(testutil.ChartWithName("other")),
+					Status:    helmrelease.StatusDeployed,
+					Namespace: releaseNamespace,
+				}),
+			}
+
+			obj := &v2.HelmRelease{
+				Spec: v2.HelmReleaseSpec{
+					ReleaseName:      mockReleaseName,
+					TargetNamespace:  releaseNamespace,
+					StorageNamespace: releaseNamespace,
+					Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+				},
+				Status: v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+				},
+			}
+
+			getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+			g.Expect(err).ToNot(HaveOccurred())
+
+			cfg, err := action.NewConfigFactory(getter,
+				action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+			)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			store := helmstorage.Init(cfg.Driver)
+			for _, r := range releases {
+				g.Expect(store.Create(r)).To(Succeed())
+			}
+			if tt.backup {
+				name, err := cfg.Backups.Save(releases[0])
+				g.Expect(err).ToNot(HaveOccurred())
+				obj.Status.History[1].Backup = name
+			}
+			if tt.pruned {
+				_, err = store.Delete(mockReleaseName, 1)
+				g.Expect(err).ToNot(HaveOccurred())
+			}
+
+			recorder := testutil.NewFakeRecorder(10, false)
+			got := NewRestore(cfg, recorder).Reconcile(context.TODO(), &Request{
+				Object: obj,
+			})
+			if tt.wantErr != nil {
+				g.Expect(errors.Is(got, tt.wantErr)).To(BeTrue())
+				g.Expect(obj.Status.ManualRollbackActive).To(BeFalse())
+			} else {
+				g.Expect(got).ToNot(HaveOccurred())
+				g.Expect(conditions.IsTrue(obj, v2.RemediatedCondition)).To(BeTrue())
+				g.Expect(conditions.GetReason(obj, v2.RemediatedCondition)).To(Equal(v2.RestoreSucceededReason))
+				g.Expect(obj.Status.ManualRollbackActive).To(BeTrue())
+			}
+
+			latest, err := store.Last(mockReleaseName)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(latest.Version).To(Equal(tt.expectVersion))
+			g.Expect(obj.Status.History.Latest().Version).To(Equal(tt.expectVersion))
+			if tt.wantErr == nil {
+				g.Expect(latest.Chart.Name()).To(Equal(releases[0].Chart.Name()))
+				g.Expect(obj.Status.History.Latest().ChartName).To(Equal(releases[0].Chart.Name()))
+			}
+		})
+	}
+}
+
+func Test_restoreTarget(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(restoreTarget(nil)).To(BeNil())
+	g.Expect(restoreTarget(v2.Snapshots{{Version: 2, Backup: "latest"}, {Version: 1}})).To(BeNil())
+
+	history := v2.Snapshots{{Version: 3}, {Version: 2, Backup: "v2"}, {Version: 1, Backup: "v1"}}
+	g.Expect(restoreTarget(history)).To(Equal(history[1]))
+}
@@ -18,10 +18,15 @@ package reconcile
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
+	helmaction "helm.sh/helm/v3/pkg/action"
 	helmrelease "helm.sh/helm/v3/pkg/release"
+	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -77,13 +82,22 @@ func (r *RollbackRemediation) Reconcile(ctx context.Context, req *Request) error
 	var (
 		cur    = req.Object.Status.History.Latest().DeepCopy()
 		logBuf = action.NewLogBuffer(action.NewDebugLog(ctrl.LoggerFrom(ctx).V(logger.DebugLevel)), 10)
-		cfg    = r.configFactory.Build(logBuf.Log, observeRollback(req.Object))
+		cfg    = r.configFactory.Build(logBuf.Log, observeRollback(req.Object, time.Now()))
 	)
 
 	defer summarize(req)
 
-	// Previous is required to determine what version to roll back to.
+	// Previous is required to determine what version to roll back to,
+	// unless an explicit version is configured.
 	prev := req.Object.Status.History.Previous(req.Object.GetUpgrade().GetRemediation().MustIgnoreTestFailures(req.Object.GetTest().IgnoreFailures))
+	if version := req.Object.GetRollback().ToVersion; version > 0 {
+		var err error
+		if prev, err = rollbackToVersionTarget(cfg, req.Object, version); err != nil {
+			conditions.MarkFalse(req.Object, v2.RemediatedCondition, v2.RollbackFailedReason,
+				"Helm rollback to release version %d failed: %s", version, err)
+			return err
+		}
+	}
 	if prev == nil {
 		return fmt.Errorf("%w: required to rollback", ErrMissingRollbackTarget)
 	}
@@ -111,6 +125,51 @@ func (r *RollbackRemediation) Reconcile(ctx context.Context, req *Request) error
 	return nil
 }
 
+// rollbackToVersionTarget returns the Snapshot of the release with the given
+// version to roll back to. The Snapshot is taken from the Status.History of
+// the object if present and verified against the Helm storage, or else
+// constructed from the release in the Helm storage.
+//
+// It returns an error of type ErrMissingRollbackTarget if the version is the
+// current release, or is not present in the Helm storage.
+func rollbackToVersionTarget(cfg *helmaction.Configuration, obj *v2.HelmRelease, version int) (*v2.Snapshot, error) {
+	if cur := obj.Status.History.Latest(); cur != nil && cur.Version == version {
+		return nil, fmt.Errorf("%w: version %d is the current release", ErrMissingRollbackTarget, version)
+	}
+
+	for _, snap := range obj.Status.History {
+		if snap.Version != version {
+			continue
+		}
+		if _, err := action.VerifySnapshot(cfg, snap); err != nil {
+			if errors.Is(err, action.ErrReleaseNotFound) {
+				return nil, fmt.Errorf("%w: version %d is not present in the Helm storage", ErrMissingRollbackTarget, version)
+			}
+			return nil, fmt.Errorf("cannot verify release version %d to roll back to: %w", version, err)
+		}
+		return snap, nil
+	}
+
+	releases, err := cfg.Releases.History(release.ShortenName(obj.GetReleaseName()))
+	if err != nil && !errors.Is(err, helmdriver.ErrReleaseNotFound) {
+		return nil, fmt.Errorf("cannot get release history to roll back to version %d: %w", version, err)
+	}
+	observed := make(observedReleases, len(releases))
+	for _, rls := range releases {
+		observed[rls.Version] = release.ObserveRelease(rls)
+	}
+	if obs, ok := observed[version]; ok {
+		return release.ObservedToSnapshot(obs), nil
+	}
+
+	available := make([]string, 0, len(observed))
+	for _, v := range observed.sortedVersions() {
+		available = append(available, strconv.Itoa(v))
+	}
+	return nil, fmt.Errorf("%w: version %d is not present in the history or the Helm storage (available versions: %s)",
+		ErrMissingRollbackTarget, version, strings.Join(available, ", "))
+}
+
 func (r *RollbackRemediation) Name() string {
 	return "rollback"
 }
@@ -129,11 +188,12 @@ const (
 )
 
 // failure records the failure of a Helm rollback action in the status of the
-// given Request.Object by marking Remediated=False and emitting a warning
-// event.
+// given Request.Object by recording the remediation, marking
+// Remediated=False and emitting a warning event.
 func (r *RollbackRemediation) failure(req *Request, prev *v2.Snapshot, buffer *action.LogBuffer, err error) {
 	// Compose failure message.
 	msg := fmt.Sprintf(fmtRollbackRemediationFailure, prev.FullReleaseName(), prev.VersionedChartName(), strings.TrimSpace(err.Error()))
+	msg = recordRemediation(req.Object, v2.RemediationActionRollback, prev.FullReleaseName(), false, msg)
 
 	// Mark remediation failure on object.
 	req.Object.Status.Failures++
@@ -152,10 +212,12 @@ func (r *RollbackRemediation) failure(req *Request, prev *v2.Snapshot, buffer *a
 }
 
 // success records the success of a Helm rollback action in the status of the
-// given Request.Object by marking Remediated=True and emitting an event.
+// given Request.Object by recording the remediation, marking Remediated=True
+// and emitting an event.
 func (r *RollbackRemediation) success(req *Request, prev *v2.Snapshot) {
 	// Compose success message.
 	msg := fmt.Sprintf(fmtRollbackRemediationSuccess, prev.FullReleaseName(), prev.VersionedChartName())
+	msg = recordRemediation(req.Object, v2.RemediationActionRollback, prev.FullReleaseName(), true, msg)
 
 	// Mark remediation success on object.
 	conditions.MarkTrue(req.Object, v2.RemediatedCondition, v2.RollbackSucceededReason, "%s", msg)
@@ -179,19 +241,31 @@ func (r *RollbackRemediation) success(req *Request, prev *v2.Snapshot) {
 // the observed release data.
 // If no matching snapshot is found, it creates a new snapshot and prepends it
 // to the release history.
-func observeRollback(obj *v2.HelmRelease) storage.ObserveFunc {
+// The release created by the rollback is observed with the time elapsed
+// since the given start of the Helm action as its Duration.
+func observeRollback(obj *v2.HelmRelease, started time.Time) storage.ObserveFunc {
+	var created int
 	return func(rls *helmrelease.Release) {
 		for i := range obj.Status.History {
 			snap := obj.Status.History[i]
 			if snap.Targets(rls.Name, rls.Namespace, rls.Version) {
-				newSnap := release.ObservedToSnapshot(releaseToObservation(rls, snap))
+				obs := releaseToObservation(rls, snap)
+				if rls.Version == created {
+					obs.Duration = time.Since(started)
+				}
+				newSnap := release.ObservedToSnapshot(obs)
 				newSnap.SetTestHooks(snap.GetTestHooks())
+				if newSnap.Duration == nil {
+					newSnap.Duration = snap.Duration
+				}
 				obj.Status.History[i] = newSnap
 				return
 			}
 		}
 
 		obs := release.ObserveRelease(rls)
+		obs.Duration = time.Since(started)
+		created = obs.Version
 		obj.Status.History = append(v2.Snapshots{release.ObservedToSnapshot(obs)}, obj.Status.History...)
 	}
 }
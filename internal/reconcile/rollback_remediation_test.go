@@ -154,6 +154,99 @@ func TestRollbackRemediation_Reconcile(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "rollback to version",
+			releases: func(namespace string) []*helmrelease.Release {
+				return []*helmrelease.Release{
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Version:   1,
+						Chart:     testutil.BuildChart(),
+						Status:    helmrelease.StatusSuperseded,
+						Namespace: namespace,
+					}),
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Version:   2,
+						Chart:     testutil.BuildChart(),
+						Status:    helmrelease.StatusSuperseded,
+						Namespace: namespace,
+					}),
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Version:   3,
+						Chart:     testutil.BuildChart(),
+						Status:    helmrelease.StatusFailed,
+						Namespace: namespace,
+					}),
+				}
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Rollback = &v2.Rollback{ToVersion: 1}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[2])),
+						release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+					},
+				}
+			},
+			expectConditions: []metav1.Condition{
+				*conditions.FalseCondition(meta.ReadyCondition, v2.RollbackSucceededReason, "succeeded"),
+				*conditions.TrueCondition(v2.RemediatedCondition, v2.RollbackSucceededReason, "succeeded"),
+			},
+			expectHistory: func(releases []*helmrelease.Release) v2.Snapshots {
+				return v2.Snapshots{
+					release.ObservedToSnapshot(release.ObserveRelease(releases[3])),
+					release.ObservedToSnapshot(release.ObserveRelease(releases[2])),
+					release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+				}
+			},
+		},
+		{
+			name: "rollback to missing version",
+			releases: func(namespace string) []*helmrelease.Release {
+				return []*helmrelease.Release{
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Version:   1,
+						Chart:     testutil.BuildChart(),
+						Status:    helmrelease.StatusSuperseded,
+						Namespace: namespace,
+					}),
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Version:   2,
+						Chart:     testutil.BuildChart(),
+						Status:    helmrelease.StatusFailed,
+						Namespace: namespace,
+					}),
+				}
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Rollback = &v2.Rollback{ToVersion: 5}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+				}
+			},
+			wantErr: ErrMissingRollbackTarget,
+			expectConditions: []metav1.Condition{
+				*conditions.FalseCondition(v2.RemediatedCondition, v2.RollbackFailedReason,
+					"rollback to release version 5 failed"),
+			},
+			expectHistory: func(releases []*helmrelease.Release) v2.Snapshots {
+				return v2.Snapshots{
+					release.ObservedToSnapshot(release.ObserveRelease(releases[1])),
+					release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+				}
+			},
+		},
 		{
 			name: "rollback failure",
 			releases: func(namespace string) []*helmrelease.Release {
@@ -321,6 +414,9 @@ func TestRollbackRemediation_Reconcile(t *testing.T) {
 					Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
 				},
 			}
+			if tt.spec != nil {
+				tt.spec(&obj.Spec)
+			}
 			if tt.status != nil {
 				obj.Status = tt.status(releases)
 			}
@@ -358,7 +454,7 @@ func TestRollbackRemediation_Reconcile(t *testing.T) {
 			helmreleaseutil.SortByRevision(releases)
 
 			if tt.expectHistory != nil {
-				g.Expect(obj.Status.History).To(testutil.Equal(tt.expectHistory(releases)))
+				g.Expect(obj.Status.History).To(testutil.Equal(tt.expectHistory(releases), ignoreSnapshotDuration))
 			} else {
 				g.Expect(obj.Status.History).To(BeEmpty(), "expected history to be empty")
 			}
@@ -493,12 +589,14 @@ func Test_observeRollback(t *testing.T) {
 			Version:   2,
 			Status:    helmrelease.StatusPendingRollback,
 		})
-		observeRollback(obj)(rls)
+		observeRollback(obj, time.Now())(rls)
 		expect := release.ObservedToSnapshot(release.ObserveRelease(rls))
 
+		g.Expect(obj.Status.History).To(HaveLen(1))
+		g.Expect(obj.Status.History[0].Duration).ToNot(BeNil())
 		g.Expect(obj.Status.History).To(testutil.Equal(v2.Snapshots{
 			expect,
-		}))
+		}, ignoreSnapshotDuration))
 	})
 
 	t.Run("rollback with latest", func(t *testing.T) {
@@ -525,11 +623,11 @@ func Test_observeRollback(t *testing.T) {
 		})
 		expect := release.ObservedToSnapshot(release.ObserveRelease(rls))
 
-		observeRollback(obj)(rls)
+		observeRollback(obj, time.Now())(rls)
 		g.Expect(obj.Status.History).To(testutil.Equal(v2.Snapshots{
 			expect,
 			latest,
-		}))
+		}, ignoreSnapshotDuration))
 	})
 
 	t.Run("rollback with update to previous deployed", func(t *testing.T) {
@@ -564,7 +662,7 @@ func Test_observeRollback(t *testing.T) {
 		})
 		expect := release.ObservedToSnapshot(release.ObserveRelease(rls))
 
-		observeRollback(obj)(rls)
+		observeRollback(obj, time.Now())(rls)
 		g.Expect(obj.Status.History).To(testutil.Equal(v2.Snapshots{
 			latest,
 			expect,
@@ -609,7 +707,7 @@ func Test_observeRollback(t *testing.T) {
 		expect := release.ObservedToSnapshot(release.ObserveRelease(rls))
 		expect.SetTestHooks(previous.GetTestHooks())
 
-		observeRollback(obj)(rls)
+		observeRollback(obj, time.Now())(rls)
 		g.Expect(obj.Status.History).To(testutil.Equal(v2.Snapshots{
 			latest,
 			expect,
@@ -652,7 +750,7 @@ func Test_observeRollback(t *testing.T) {
 		obs.OCIDigest = "sha256:fcdc2b0de1581a3633ada4afee3f918f6eaa5b5ab38c3fef03d5b48d3f85d9f6"
 		expect := release.ObservedToSnapshot(obs)
 
-		observeRollback(obj)(rls)
+		observeRollback(obj, time.Now())(rls)
 		g.Expect(obj.Status.History).To(testutil.Equal(v2.Snapshots{
 			latest,
 			expect,
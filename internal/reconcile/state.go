@@ -28,6 +28,7 @@ import (
 	helmrelease "helm.sh/helm/v3/pkg/release"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	v2 "github.com/fluxcd/helm-controller/api/v2"
 	"github.com/fluxcd/helm-controller/internal/action"
 	"github.com/fluxcd/helm-controller/internal/digest"
 	interrors "github.com/fluxcd/helm-controller/internal/errors"
@@ -53,6 +54,10 @@ const (
 	// ReleaseStatusUnmanaged indicates that the release is present in the Helm
 	// storage, but is not managed by the v2.HelmRelease object.
 	ReleaseStatusUnmanaged ReleaseStatus = "Unmanaged"
+	// ReleaseStatusAdoptable indicates that the release is present in the
+	// Helm storage, is not managed by the v2.HelmRelease object, but can be
+	// adopted by it.
+	ReleaseStatusAdoptable ReleaseStatus = "Adoptable"
 	// ReleaseStatusOutOfSync indicates that the release is present in the Helm
 	// storage, but is not in sync with the v2.HelmRelease object.
 	ReleaseStatusOutOfSync ReleaseStatus = "OutOfSync"
@@ -84,12 +89,39 @@ type ReleaseState struct {
 	// Diff contains any differences between the Helm storage manifest and the
 	// cluster state when Status equals ReleaseStatusDrifted.
 	Diff jsondiff.DiffSet
+	// HeldBack contains the reason the release is out-of-sync with the
+	// v2.HelmRelease object, when the upgrade is held back as the release is
+	// pinned.
+	HeldBack string
 }
 
 // DetermineReleaseState determines the state of the Helm release as compared
 // to the v2.HelmRelease object. It returns a ReleaseState that indicates
 // the status of the release, and an error if the state could not be determined.
+//
+// For a pinned release, the state has HeldBack set when the release is
+// out-of-sync, failed or unmanaged, as any upgrade of it is held back.
 func DetermineReleaseState(ctx context.Context, cfg *action.ConfigFactory, req *Request) (ReleaseState, error) {
+	state, err := determineReleaseState(ctx, cfg, req)
+	if err != nil || !req.Object.Spec.PinRelease || state.HeldBack != "" {
+		return state, err
+	}
+	switch state.Status {
+	case ReleaseStatusFailed:
+		state.HeldBack = "release is in a failed state"
+		if state.Reason != "" {
+			state.HeldBack = state.Reason
+		}
+	case ReleaseStatusUnmanaged:
+		state.HeldBack = "release not managed by controller"
+		if state.Reason != "" {
+			state.HeldBack = state.Reason
+		}
+	}
+	return state, nil
+}
+
+func determineReleaseState(ctx context.Context, cfg *action.ConfigFactory, req *Request) (ReleaseState, error) {
 	rls, err := action.LastRelease(cfg.Build(nil), req.Object.GetReleaseName())
 	if err != nil {
 		if errors.Is(err, action.ErrReleaseNotFound) {
@@ -98,6 +130,12 @@ func DetermineReleaseState(ctx context.Context, cfg *action.ConfigFactory, req *
 		return ReleaseState{Status: ReleaseStatusUnknown}, fmt.Errorf("failed to retrieve last release from storage: %w", err)
 	}
 
+	// Refuse to act on a release with the same name which is owned by
+	// another object, instead of overwriting it.
+	if err = action.VerifyReleaseOwner(req.Object, rls); err != nil {
+		return ReleaseState{Status: ReleaseStatusUnknown}, err
+	}
+
 	// If the release is in a pending state, it must be unlocked before any
 	// further action can be taken.
 	if rls.Info.Status.IsPending() {
@@ -109,6 +147,15 @@ func DetermineReleaseState(ctx context.Context, cfg *action.ConfigFactory, req *
 		if rls.Info.Status == helmrelease.StatusUninstalled {
 			return ReleaseState{Status: ReleaseStatusAbsent, Reason: "found uninstalled release in storage"}, nil
 		}
+		// Adopt the release instead of taking it over with an upgrade, if
+		// allowed and it was produced from the same chart.
+		if req.Object.GetInstall().Adopt {
+			reason, ok := adoptable(req, rls)
+			if ok {
+				return ReleaseState{Status: ReleaseStatusAdoptable, Reason: reason}, nil
+			}
+			return ReleaseState{Status: ReleaseStatusUnmanaged, Reason: reason}, nil
+		}
 		return ReleaseState{Status: ReleaseStatusUnmanaged, Reason: "found existing release in storage"}, err
 	}
 
@@ -135,32 +182,19 @@ func DetermineReleaseState(ctx context.Context, cfg *action.ConfigFactory, req *
 	case helmrelease.StatusUninstalled:
 		return ReleaseState{Status: ReleaseStatusAbsent, Reason: "found uninstalled release in storage"}, nil
 	case helmrelease.StatusDeployed:
-		// Verify the release is in sync with the desired configuration.
-		if err = action.VerifyRelease(rls, cur, req.Chart.Metadata, req.Values); err != nil {
-			switch err {
-			case action.ErrChartChanged, action.ErrConfigDigest:
-				return ReleaseState{Status: ReleaseStatusOutOfSync, Reason: err.Error()}, nil
-			default:
-				return ReleaseState{Status: ReleaseStatusUnknown}, err
-			}
+		// Verify the release is in sync with the desired configuration. The
+		// upgrade of a pinned release is held back, while it continues to be
+		// tested and checked for drift.
+		var state ReleaseState
+		outOfSync, err := verifyReleaseInSync(req, rls, cur)
+		if err != nil {
+			return ReleaseState{Status: ReleaseStatusUnknown}, err
 		}
-
-		// Verify if postrender digest has changed if config has not been
-		// processed. For the processed or partially processed generation, the
-		// updated observation will only be reflected at the end of a successful
-		// reconciliation.  Comparing here would result the reconciliation to
-		// get stuck in this check due to a mismatch forever.  The value can't
-		// change without a new generation. Hence, compare the observed digest
-		// for new generations only.
-		ready := conditions.Get(req.Object, meta.ReadyCondition)
-		if ready != nil && ready.ObservedGeneration != req.Object.Generation {
-			var postrenderersDigest string
-			if req.Object.Spec.PostRenderers != nil {
-				postrenderersDigest = postrender.Digest(digest.Canonical, req.Object.Spec.PostRenderers).String()
-			}
-			if postrenderersDigest != req.Object.Status.ObservedPostRenderersDigest {
-				return ReleaseState{Status: ReleaseStatusOutOfSync, Reason: "postrenderers digest has changed"}, nil
+		if outOfSync != "" {
+			if !req.Object.Spec.PinRelease {
+				return ReleaseState{Status: ReleaseStatusOutOfSync, Reason: outOfSync}, nil
 			}
+			state.HeldBack = outOfSync
 		}
 
 		// For the further determination of test results, we look at the
@@ -169,19 +203,24 @@ func DetermineReleaseState(ctx context.Context, cfg *action.ConfigFactory, req *
 		if testSpec := req.Object.GetTest(); testSpec.Enable {
 			// Confirm the release has been tested if enabled.
 			if !cur.HasBeenTested() {
-				return ReleaseState{Status: ReleaseStatusUntested}, nil
+				state.Status = ReleaseStatusUntested
+				return state, nil
 			}
 
 			// Act on any observed test failure.
 			remediation := req.Object.GetActiveRemediation()
 			if remediation != nil && !remediation.MustIgnoreTestFailures(testSpec.IgnoreFailures) && cur.HasTestInPhase(helmrelease.HookPhaseFailed.String()) {
-				return ReleaseState{Status: ReleaseStatusFailed, Reason: "release has test in failed phase"}, nil
+				state.Status, state.Reason = ReleaseStatusFailed, "release has test in failed phase"
+				return state, nil
 			}
 		}
 
 		// Confirm the cluster state matches the desired config.
 		if diffOpts := req.Object.GetDriftDetection(); diffOpts.MustDetectChanges() {
-			diffSet, err := action.Diff(ctx, cfg.Build(nil), rls, kube.ManagedFieldsManager, req.Object.GetDriftDetection().Ignore...)
+			var ignore []v2.IgnoreRule
+			ignore = append(ignore, diffOpts.Ignore...)
+			ignore = append(ignore, postrender.RequiredLabelsIgnoreRules(req.Object.Spec.RequiredLabels)...)
+			diffSet, err := action.Diff(ctx, cfg.Build(nil), rls, kube.ManagedFieldsManager, ignore...)
 			hasChanges := diffSet.HasChanges()
 			if err != nil {
 				if !hasChanges {
@@ -190,12 +229,73 @@ func DetermineReleaseState(ctx context.Context, cfg *action.ConfigFactory, req *
 				ctrl.LoggerFrom(ctx).Error(err, "diff of release against cluster state completed with error")
 			}
 			if hasChanges {
-				return ReleaseState{Status: ReleaseStatusDrifted, Diff: diffSet}, nil
+				state.Status, state.Diff = ReleaseStatusDrifted, diffSet
+				return state, nil
 			}
 		}
 
-		return ReleaseState{Status: ReleaseStatusInSync}, nil
+		state.Status = ReleaseStatusInSync
+		return state, nil
 	default:
 		return ReleaseState{Status: ReleaseStatusUnknown}, fmt.Errorf("unable to determine state for release with status '%s'", rls.Info.Status)
 	}
 }
+
+// adoptable returns if the existing release in the Helm storage can be
+// adopted by the Request.Object, with the reason why (not). A release can be
+// adopted if it is deployed, and was produced from a chart with the same name
+// as the Request.Chart.
+func adoptable(req *Request, rls *helmrelease.Release) (string, bool) {
+	if rls.Info.Status != helmrelease.StatusDeployed {
+		return fmt.Sprintf("found existing release in storage with status '%s', which can not be adopted", rls.Info.Status), false
+	}
+	var rlsChart, reqChart string
+	if rls.Chart != nil && rls.Chart.Metadata != nil {
+		rlsChart = rls.Chart.Metadata.Name
+	}
+	if req.Chart != nil && req.Chart.Metadata != nil {
+		reqChart = req.Chart.Metadata.Name
+	}
+	if rlsChart == "" || rlsChart != reqChart {
+		return fmt.Sprintf("found existing release in storage with chart '%s', which can not be adopted with chart '%s'", rlsChart, reqChart), false
+	}
+	return fmt.Sprintf("found existing release in storage with chart '%s' to adopt", rlsChart), true
+}
+
+// verifyReleaseInSync verifies the given deployed release is in sync with the
+// desired configuration of the Request. It returns the reason the release is
+// out-of-sync, or an empty string if it is in sync.
+func verifyReleaseInSync(req *Request, rls *helmrelease.Release, cur *v2.Snapshot) (string, error) {
+	if err := action.VerifyRelease(rls, cur, req.Chart.Metadata, req.Values); err != nil {
+		switch err {
+		case action.ErrChartChanged, action.ErrConfigDigest:
+			return err.Error(), nil
+		default:
+			return "", err
+		}
+	}
+
+	// Verify the fully merged values, which include the default values
+	// of the chart, have not changed. This detects changes to the chart
+	// defaults which are not accompanied by a change of the chart
+	// version.
+	if cur.ValuesChecksum != "" && req.ValuesChecksum != "" && cur.ValuesChecksum != req.ValuesChecksum {
+		return "release values checksum changed", nil
+	}
+
+	// Verify if postrender digest has changed if config has not been
+	// processed. For the processed or partially processed generation, the
+	// updated observation will only be reflected at the end of a successful
+	// reconciliation.  Comparing here would result the reconciliation to
+	// get stuck in this check due to a mismatch forever.  The value can't
+	// change without a new generation. Hence, compare the observed digest
+	// for new generations only.
+	ready := conditions.Get(req.Object, meta.ReadyCondition)
+	if ready != nil && ready.ObservedGeneration != req.Object.Generation {
+		postrenderersDigest := postrender.SpecDigest(digest.Canonical, req.Object)
+		if postrenderersDigest != req.Object.Status.ObservedPostRenderersDigest {
+			return "postrenderers digest has changed", nil
+		}
+	}
+	return "", nil
+}
@@ -52,6 +52,7 @@ func Test_DetermineReleaseState(t *testing.T) {
 		status   func(releases []*helmrelease.Release) v2.HelmReleaseStatus
 		chart    *helmchart.Chart
 		values   helmchartutil.Values
+		checksum string
 		want     ReleaseState
 		wantErr  bool
 	}{
@@ -120,6 +121,89 @@ func Test_DetermineReleaseState(t *testing.T) {
 				Status: ReleaseStatusUnmanaged,
 			},
 		},
+		{
+			name: "existing release to adopt",
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusDeployed,
+					Chart:     testutil.BuildChart(),
+				}),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Install = &v2.Install{Adopt: true}
+			},
+			chart: testutil.BuildChart(),
+			want: ReleaseState{
+				Status: ReleaseStatusAdoptable,
+				Reason: "to adopt",
+			},
+		},
+		{
+			name: "existing release of other chart not adopted",
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusDeployed,
+					Chart:     testutil.BuildChart(testutil.ChartWithName("other")),
+				}),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Install = &v2.Install{Adopt: true}
+			},
+			chart: testutil.BuildChart(),
+			want: ReleaseState{
+				Status: ReleaseStatusUnmanaged,
+				Reason: "with chart 'other', which can not be adopted",
+			},
+		},
+		{
+			name: "existing failed release not adopted",
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusFailed,
+					Chart:     testutil.BuildChart(),
+				}),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Install = &v2.Install{Adopt: true}
+			},
+			chart: testutil.BuildChart(),
+			want: ReleaseState{
+				Status: ReleaseStatusUnmanaged,
+				Reason: "with status 'failed', which can not be adopted",
+			},
+		},
+		{
+			name: "existing release owned by other object",
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusDeployed,
+					Chart:     testutil.BuildChart(),
+				}, testutil.ReleaseWithLabels(map[string]string{
+					action.OwnerNameLabel:      "other",
+					action.OwnerNamespaceLabel: "other-ns",
+				})),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.Install = &v2.Install{Adopt: true}
+			},
+			chart:   testutil.BuildChart(),
+			wantErr: true,
+			want: ReleaseState{
+				Status: ReleaseStatusUnknown,
+			},
+		},
 		{
 			name: "release digest parse error",
 			releases: []*helmrelease.Release{
@@ -369,6 +453,33 @@ func Test_DetermineReleaseState(t *testing.T) {
 				Status: ReleaseStatusFailed,
 			},
 		},
+		{
+			name: "pinned failed release",
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusFailed,
+					Chart:     testutil.BuildChart(),
+				}),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.PinRelease = true
+			},
+			chart: testutil.BuildChart(testutil.ChartWithVersion("0.2.0")),
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+				}
+			},
+			want: ReleaseState{
+				Status:   ReleaseStatusFailed,
+				HeldBack: "release is in a failed state",
+			},
+		},
 		{
 			name: "uninstalled release",
 			releases: []*helmrelease.Release{
@@ -432,6 +543,63 @@ func Test_DetermineReleaseState(t *testing.T) {
 				Status: ReleaseStatusOutOfSync,
 			},
 		},
+		{
+			name: "pinned release with chart changed",
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusDeployed,
+					Chart:     testutil.BuildChart(),
+				}, testutil.ReleaseWithConfig(map[string]interface{}{"foo": "bar"})),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.PinRelease = true
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+				}
+			},
+			chart:  testutil.BuildChart(testutil.ChartWithVersion("0.2.0")),
+			values: map[string]interface{}{"foo": "bar"},
+			want: ReleaseState{
+				Status:   ReleaseStatusInSync,
+				HeldBack: "release chart changed",
+			},
+		},
+		{
+			name: "pinned untested release with values changed",
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusDeployed,
+					Chart:     testutil.BuildChart(),
+				}, testutil.ReleaseWithConfig(map[string]interface{}{"foo": "bar"})),
+			},
+			spec: func(spec *v2.HelmReleaseSpec) {
+				spec.PinRelease = true
+				spec.Test = &v2.Test{Enable: true}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(release.ObserveRelease(releases[0])),
+					},
+				}
+			},
+			chart:  testutil.BuildChart(),
+			values: map[string]interface{}{"bar": "foo"},
+			want: ReleaseState{
+				Status:   ReleaseStatusUntested,
+				HeldBack: "release config values changed",
+			},
+		},
 		{
 			name: "values changed",
 			releases: []*helmrelease.Release{
@@ -456,6 +624,61 @@ func Test_DetermineReleaseState(t *testing.T) {
 				Status: ReleaseStatusOutOfSync,
 			},
 		},
+		{
+			name: "values checksum changed",
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusDeployed,
+					Chart:     testutil.BuildChart(),
+				}, testutil.ReleaseWithConfig(map[string]interface{}{"foo": "bar"})),
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				obs := release.ObserveRelease(releases[0])
+				obs.ValuesChecksum = "sha256:4f5a2e51b4d8ef0b8a5b24e7b0a9c1f5ab4d0c5b7b0b5e2a6fdd3b6c6b1a2f3e"
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(obs),
+					},
+				}
+			},
+			chart:    testutil.BuildChart(),
+			values:   map[string]interface{}{"foo": "bar"},
+			checksum: "sha256:9b1c2a8e4f0d7a6b3c5e1f2d8a7b6c4e3f1a0d9c8b7a6e5f4d3c2b1a0f9e8d7c",
+			want: ReleaseState{
+				Status: ReleaseStatusOutOfSync,
+				Reason: "release values checksum changed",
+			},
+		},
+		{
+			name: "values checksum unchanged",
+			releases: []*helmrelease.Release{
+				testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+					Name:      mockReleaseName,
+					Namespace: mockReleaseNamespace,
+					Version:   1,
+					Status:    helmrelease.StatusDeployed,
+					Chart:     testutil.BuildChart(),
+				}, testutil.ReleaseWithConfig(map[string]interface{}{"foo": "bar"})),
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				obs := release.ObserveRelease(releases[0])
+				obs.ValuesChecksum = "sha256:4f5a2e51b4d8ef0b8a5b24e7b0a9c1f5ab4d0c5b7b0b5e2a6fdd3b6c6b1a2f3e"
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{
+						release.ObservedToSnapshot(obs),
+					},
+				}
+			},
+			chart:    testutil.BuildChart(),
+			values:   map[string]interface{}{"foo": "bar"},
+			checksum: "sha256:4f5a2e51b4d8ef0b8a5b24e7b0a9c1f5ab4d0c5b7b0b5e2a6fdd3b6c6b1a2f3e",
+			want: ReleaseState{
+				Status: ReleaseStatusInSync,
+			},
+		},
 		{
 			name: "postRenderers changed",
 			releases: []*helmrelease.Release{
@@ -563,19 +786,21 @@ func Test_DetermineReleaseState(t *testing.T) {
 			}
 
 			got, err := DetermineReleaseState(context.TODO(), cfg, &Request{
-				Object: obj,
-				Chart:  tt.chart,
-				Values: tt.values,
+				Object:         obj,
+				Chart:          tt.chart,
+				Values:         tt.values,
+				ValuesChecksum: tt.checksum,
 			})
 			if tt.wantErr {
-				g.Expect(got).To(BeNil())
 				g.Expect(err).To(HaveOccurred())
+				g.Expect(got.Status).To(Equal(tt.want.Status))
 				return
 			}
 
 			g.Expect(err).ToNot(HaveOccurred())
 			g.Expect(got.Status).To(Equal(tt.want.Status))
 			g.Expect(got.Reason).To(ContainSubstring(tt.want.Reason))
+			g.Expect(got.HeldBack).To(Equal(tt.want.HeldBack))
 		})
 	}
 }
@@ -585,6 +810,7 @@ func TestDetermineReleaseState_DriftDetection(t *testing.T) {
 		name          string
 		driftMode     v2.DriftDetectionMode
 		applyManifest bool
+		pinned        bool
 		want          func(namespace string) ReleaseState
 	}{
 		{
@@ -667,6 +893,40 @@ func TestDetermineReleaseState_DriftDetection(t *testing.T) {
 				return ReleaseState{Status: ReleaseStatusInSync}
 			},
 		},
+		{
+			name:      "with drift of pinned release and detection mode enabled",
+			driftMode: v2.DriftDetectionEnabled,
+			pinned:    true,
+			want: func(namespace string) ReleaseState {
+				return ReleaseState{
+					Status: ReleaseStatusDrifted,
+					Diff: jsondiff.DiffSet{
+						{
+							Type: jsondiff.DiffTypeCreate,
+							DesiredObject: &unstructured.Unstructured{
+								Object: map[string]interface{}{
+									"apiVersion": "v1",
+									"kind":       "Secret",
+									"metadata": map[string]interface{}{
+										"name":              "fixture",
+										"namespace":         namespace,
+										"creationTimestamp": nil,
+										"labels": map[string]interface{}{
+											"app.kubernetes.io/managed-by": "Helm",
+										},
+										"annotations": map[string]interface{}{
+											"meta.helm.sh/release-name":      mockReleaseName,
+											"meta.helm.sh/release-namespace": namespace,
+										},
+									},
+								},
+							},
+						},
+					},
+					HeldBack: "release chart changed",
+				}
+			},
+		},
 		{
 			name:      "drift detection mode disabled",
 			driftMode: v2.DriftDetectionDisabled,
@@ -723,6 +983,7 @@ func TestDetermineReleaseState_DriftDetection(t *testing.T) {
 					DriftDetection: &v2.DriftDetection{
 						Mode: tt.driftMode,
 					},
+					PinRelease: tt.pinned,
 				},
 				Status: v2.HelmReleaseStatus{
 					History: v2.Snapshots{
@@ -742,9 +1003,14 @@ func TestDetermineReleaseState_DriftDetection(t *testing.T) {
 			store := helmstorage.Init(cfg.Driver)
 			g.Expect(store.Create(rls)).To(Succeed())
 
+			desired := testutil.BuildChart()
+			if tt.pinned {
+				desired = testutil.BuildChart(testutil.ChartWithVersion("0.2.0"))
+			}
+
 			got, err := DetermineReleaseState(context.TODO(), cfg, &Request{
 				Object: obj,
-				Chart:  testutil.BuildChart(),
+				Chart:  desired,
 				Values: rls.Config,
 			})
 			g.Expect(err).ToNot(HaveOccurred())
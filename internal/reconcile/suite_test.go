@@ -22,6 +22,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/google/go-cmp/cmp/cmpopts"
 	"helm.sh/helm/v3/pkg/kube"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -47,6 +48,10 @@ import (
 
 const testFieldManager = "helm-controller"
 
+// ignoreSnapshotDuration ignores the Duration of a Snapshot in comparisons,
+// as it depends on the time the observed Helm action took.
+var ignoreSnapshotDuration = cmpopts.IgnoreFields(v2.Snapshot{}, "Duration")
+
 var (
 	ctx     = ctrl.SetupSignalHandler()
 	testEnv *testenv.Environment
@@ -0,0 +1,95 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// ReconcileSummaryReason is the reason of the consolidated event emitted
+// by a summaryEventRecorder.
+const ReconcileSummaryReason = "ReconcileSummary"
+
+// summaryEventRecorder is a record.EventRecorder which collects the Normal
+// events recorded during a reconcile pass, to emit them as a single
+// consolidated event. Warning events are passed through immediately, so that
+// failures are still emitted as distinct events.
+type summaryEventRecorder struct {
+	record.EventRecorder
+
+	mu          sync.Mutex
+	messages    []string
+	annotations map[string]string
+}
+
+// newSummaryEventRecorder returns a summaryEventRecorder emitting events
+// using the given recorder.
+func newSummaryEventRecorder(recorder record.EventRecorder) *summaryEventRecorder {
+	return &summaryEventRecorder{EventRecorder: recorder}
+}
+
+// Event records the event if it is a Normal event, or passes it through.
+func (r *summaryEventRecorder) Event(object runtime.Object, eventtype, reason, message string) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, "%s", message)
+}
+
+// Eventf records the event if it is a Normal event, or passes it through.
+func (r *summaryEventRecorder) Eventf(object runtime.Object, eventtype, reason, messageFmt string, args ...interface{}) {
+	r.AnnotatedEventf(object, nil, eventtype, reason, messageFmt, args...)
+}
+
+// AnnotatedEventf records the event if it is a Normal event, or passes it
+// through. The annotations of recorded events are merged, with those of
+// later events taking precedence.
+func (r *summaryEventRecorder) AnnotatedEventf(object runtime.Object, annotations map[string]string, eventtype, reason, messageFmt string, args ...interface{}) {
+	if eventtype != corev1.EventTypeNormal {
+		r.EventRecorder.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.messages = append(r.messages, fmt.Sprintf("%s: %s", reason, fmt.Sprintf(messageFmt, args...)))
+	for k, v := range annotations {
+		if r.annotations == nil {
+			r.annotations = make(map[string]string, len(annotations))
+		}
+		r.annotations[k] = v
+	}
+}
+
+// Flush emits the recorded events as a single Normal event with the
+// ReconcileSummaryReason, and resets the recorder. It does nothing if no
+// events were recorded.
+func (r *summaryEventRecorder) Flush(object runtime.Object) {
+	r.mu.Lock()
+	messages, annotations := r.messages, r.annotations
+	r.messages, r.annotations = nil, nil
+	r.mu.Unlock()
+
+	if len(messages) == 0 {
+		return
+	}
+	r.EventRecorder.AnnotatedEventf(object, annotations, corev1.EventTypeNormal, ReconcileSummaryReason,
+		"%s", strings.Join(messages, "\n"))
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/testutil"
+)
+
+func Test_summaryEventRecorder(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &v2.HelmRelease{}
+	recorder := testutil.NewFakeRecorder(10, false)
+	r := newSummaryEventRecorder(recorder)
+
+	r.AnnotatedEventf(obj, map[string]string{"revision": "1.0.0", "token": "a"},
+		corev1.EventTypeNormal, v2.UpgradeSucceededReason, "upgraded to %s", "1.0.0")
+	r.Eventf(obj, corev1.EventTypeWarning, v2.TestFailedReason, "test %s failed", "smoke")
+	r.AnnotatedEventf(obj, map[string]string{"token": "b"},
+		corev1.EventTypeNormal, "DriftCorrected", "corrected drift")
+
+	// Warning events are emitted immediately.
+	g.Expect(recorder.GetEvents()).To(Equal([]corev1.Event{
+		{Type: corev1.EventTypeWarning, Reason: v2.TestFailedReason, Message: "test smoke failed"},
+	}))
+
+	r.Flush(obj)
+	events := recorder.GetEvents()
+	g.Expect(events).To(HaveLen(1))
+	g.Expect(events[0].Type).To(Equal(corev1.EventTypeNormal))
+	g.Expect(events[0].Reason).To(Equal(ReconcileSummaryReason))
+	g.Expect(events[0].Message).To(Equal("UpgradeSucceeded: upgraded to 1.0.0\nDriftCorrected: corrected drift"))
+	g.Expect(events[0].Annotations).To(Equal(map[string]string{"revision": "1.0.0", "token": "b"}))
+
+	// Flushing without recorded events does not emit an event.
+	r.Flush(obj)
+	g.Expect(recorder.GetEvents()).To(BeEmpty())
+}
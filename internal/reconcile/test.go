@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/fluxcd/pkg/runtime/logger"
 	helmrelease "helm.sh/helm/v3/pkg/release"
@@ -83,15 +84,40 @@ func (r *Test) Reconcile(ctx context.Context, req *Request) error {
 		return fmt.Errorf("%w: required for test", ErrNoLatest)
 	}
 
-	// Run the Helm test action.
-	rls, err := action.Test(ctx, cfg, req.Object)
+	var (
+		timeout  = req.Object.GetTest().GetTimeout(req.Object.GetTimeout()).Duration
+		deadline = time.Now().Add(timeout)
+		attempts = make(map[string]*v2.TestHookStatus)
+		rls      *helmrelease.Release
+		err      error
+	)
+	for attempt := 0; ; attempt++ {
+		// Run the Helm test action, within the time remaining of the
+		// timeout for all attempts.
+		started := time.Now()
+		rls, err = action.Test(ctx, cfg, req.Object, action.WithTestTimeout(deadline.Sub(started)))
+
+		// The Helm test action does always target the latest release. Before
+		// accepting results, we need to confirm this is actually the release we
+		// have recorded as latest.
+		if rls != nil && !release.ObserveRelease(rls).Targets(cur.Name, cur.Namespace, cur.Version) {
+			err = fmt.Errorf("%w: tested release %s/%s.v%d != current release %s/%s.v%d",
+				ErrReleaseMismatch, rls.Namespace, rls.Name, rls.Version, cur.Namespace, cur.Name, cur.Version)
+			break
+		}
+
+		failed := countTestAttempts(attempts, req.Object.Status.History.Latest().GetTestHooks(), started)
 
-	// The Helm test action does always target the latest release. Before
-	// accepting results, we need to confirm this is actually the release we
-	// have recorded as latest.
-	if rls != nil && !release.ObserveRelease(rls).Targets(cur.Name, cur.Namespace, cur.Version) {
-		err = fmt.Errorf("%w: tested release %s/%s.v%d != current release %s/%s.v%d",
-			ErrReleaseMismatch, rls.Namespace, rls.Name, rls.Version, cur.Namespace, cur.Name, cur.Version)
+		// Only retry when a test hook failed during this attempt, and there
+		// is time left.
+		if err == nil || !failed || attempt >= req.Object.GetTest().Retries || !time.Now().Before(deadline) {
+			break
+		}
+		ctrl.LoggerFrom(ctx).Info(fmt.Sprintf("retrying failed Helm test (%d/%d): %s",
+			attempt+1, req.Object.GetTest().Retries, strings.TrimSpace(err.Error())))
+	}
+	if req.Object.GetTest().Retries > 0 {
+		setTestAttempts(req.Object.Status.History.Latest(), attempts)
 	}
 
 	// Something went wrong.
@@ -145,7 +171,8 @@ func (r *Test) failure(req *Request, err error) {
 	// Condition summary.
 	r.eventRecorder.AnnotatedEventf(
 		req.Object,
-		eventMeta(cur.ChartVersion, cur.ConfigDigest, addAppVersion(cur.AppVersion), addOCIDigest(cur.OCIDigest)),
+		eventMeta(cur.ChartVersion, cur.ConfigDigest, addAppVersion(cur.AppVersion), addOCIDigest(cur.OCIDigest),
+			addTestResults(cur.GetTestHooks())),
 		corev1.EventTypeWarning,
 		v2.TestFailedReason,
 		msg,
@@ -188,6 +215,39 @@ func (r *Test) success(req *Request) {
 	)
 }
 
+// countTestAttempts counts the runs of the given test hooks which started
+// at or after the given time in attempts, indexed by name. It returns true
+// if any of the counted runs failed.
+func countTestAttempts(attempts, hooks map[string]*v2.TestHookStatus, started time.Time) (failed bool) {
+	for name, h := range hooks {
+		if h == nil || h.LastStarted.Time.Before(started) {
+			continue
+		}
+		a, ok := attempts[name]
+		if !ok {
+			a = &v2.TestHookStatus{}
+			attempts[name] = a
+		}
+		a.Attempts++
+		if h.Phase == helmrelease.HookPhaseFailed.String() {
+			a.FailedAttempts++
+			failed = true
+		}
+	}
+	return failed
+}
+
+// setTestAttempts sets the counted attempts on the test hooks of the given
+// Snapshot.
+func setTestAttempts(snap *v2.Snapshot, attempts map[string]*v2.TestHookStatus) {
+	for name, h := range snap.GetTestHooks() {
+		if a, ok := attempts[name]; ok && h != nil {
+			h.Attempts = a.Attempts
+			h.FailedAttempts = a.FailedAttempts
+		}
+	}
+}
+
 // observeTest returns a storage.ObserveFunc to track test results of a
 // HelmRelease.
 // It only accepts test results for the latest release and updates the
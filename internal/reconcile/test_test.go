@@ -224,6 +224,83 @@ func TestTest_Reconcile(t *testing.T) {
 			expectConditions: []metav1.Condition{},
 			wantErr:          ErrNoLatest,
 		},
+		{
+			name: "test rerun of tested release",
+			releases: func(namespace string) []*helmrelease.Release {
+				return []*helmrelease.Release{
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Namespace: namespace,
+						Version:   1,
+						Chart:     testutil.BuildChart(testutil.ChartWithTestHook()),
+						Status:    helmrelease.StatusDeployed,
+					}, testutil.ReleaseWithTestHook()),
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				failed := release.ObservedToSnapshot(release.ObserveRelease(releases[0]))
+				failed.SetTestHooks(map[string]*v2.TestHookStatus{
+					"test-hook": {Phase: helmrelease.HookPhaseFailed.String()},
+				})
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{failed},
+					Conditions: []metav1.Condition{
+						*conditions.FalseCondition(v2.TestSuccessCondition, v2.TestFailedReason, "test hook failed"),
+					},
+				}
+			},
+			expectConditions: []metav1.Condition{
+				*conditions.TrueCondition(meta.ReadyCondition, v2.TestSucceededReason,
+					"1 test hook completed successfully"),
+				*conditions.TrueCondition(v2.TestSuccessCondition, v2.TestSucceededReason,
+					"1 test hook completed successfully"),
+			},
+			expectHistory: func(releases []*helmrelease.Release) v2.Snapshots {
+				withTests := release.ObservedToSnapshot(release.ObserveRelease(releases[0]))
+				withTests.SetTestHooks(release.TestHooksFromRelease(releases[0]))
+				return v2.Snapshots{withTests}
+			},
+		},
+		{
+			name: "test rerun of tested release replaced since",
+			releases: func(namespace string) []*helmrelease.Release {
+				return []*helmrelease.Release{
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Namespace: namespace,
+						Version:   1,
+						Chart:     testutil.BuildChart(testutil.ChartWithTestHook()),
+						Status:    helmrelease.StatusSuperseded,
+					}, testutil.ReleaseWithTestHook()),
+					testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+						Name:      mockReleaseName,
+						Namespace: namespace,
+						Version:   2,
+						Chart:     testutil.BuildChart(testutil.ChartWithTestHook()),
+						Status:    helmrelease.StatusDeployed,
+					}),
+				}
+			},
+			status: func(releases []*helmrelease.Release) v2.HelmReleaseStatus {
+				tested := release.ObservedToSnapshot(release.ObserveRelease(releases[0]))
+				tested.SetTestHooks(release.TestHooksFromRelease(releases[0]))
+				return v2.HelmReleaseStatus{
+					History: v2.Snapshots{tested},
+				}
+			},
+			expectConditions: []metav1.Condition{
+				*conditions.FalseCondition(meta.ReadyCondition, v2.TestFailedReason,
+					ErrReleaseMismatch.Error()),
+				*conditions.FalseCondition(v2.TestSuccessCondition, v2.TestFailedReason,
+					ErrReleaseMismatch.Error()),
+			},
+			expectHistory: func(releases []*helmrelease.Release) v2.Snapshots {
+				tested := release.ObservedToSnapshot(release.ObserveRelease(releases[0]))
+				tested.SetTestHooks(release.TestHooksFromRelease(releases[0]))
+				return v2.Snapshots{tested}
+			},
+			expectFailures: 1,
+		},
 		{
 			name: "test with stale current",
 			releases: func(namespace string) []*helmrelease.Release {
@@ -346,6 +423,82 @@ func TestTest_Reconcile(t *testing.T) {
 	}
 }
 
+func TestTest_Reconcile_ExcludeFromManifest(t *testing.T) {
+	tests := []struct {
+		name    string
+		exclude bool
+	}{
+		{name: "test hooks in manifest"},
+		{name: "test hooks excluded from manifest", exclude: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			t.Cleanup(func() {
+				_ = testEnv.Delete(context.TODO(), namedNS)
+			})
+			releaseNamespace := namedNS.Name
+
+			obj := &v2.HelmRelease{
+				Spec: v2.HelmReleaseSpec{
+					ReleaseName:      mockReleaseName,
+					TargetNamespace:  releaseNamespace,
+					StorageNamespace: releaseNamespace,
+					Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+					Test: &v2.Test{
+						Enable:              true,
+						ExcludeFromManifest: tt.exclude,
+					},
+				},
+			}
+
+			getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+			g.Expect(err).ToNot(HaveOccurred())
+
+			cfg, err := action.NewConfigFactory(getter,
+				action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+			)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			recorder := new(record.FakeRecorder)
+			g.Expect(NewInstall(cfg, recorder).Reconcile(context.TODO(), &Request{
+				Object: obj,
+				Chart:  testutil.BuildChart(testutil.ChartWithTestHook()),
+			})).To(Succeed())
+
+			// The test hooks are only persisted when not excluded, and the
+			// release is not recorded as tested either way.
+			store := helmstorage.Init(cfg.Driver)
+			rls, err := store.Last(mockReleaseName)
+			g.Expect(err).ToNot(HaveOccurred())
+			if tt.exclude {
+				g.Expect(release.GetTestHooks(rls)).To(BeEmpty())
+			} else {
+				g.Expect(release.GetTestHooks(rls)).To(HaveKey("test-hook"))
+			}
+			g.Expect(obj.Status.History.Latest().HasBeenTested()).To(BeFalse())
+
+			// The test hooks are run, and recorded for the release.
+			g.Expect(NewTest(cfg, recorder).Reconcile(context.TODO(), &Request{
+				Object: obj,
+			})).To(Succeed())
+			g.Expect(conditions.IsTrue(obj, v2.TestSuccessCondition)).To(BeTrue())
+			g.Expect(conditions.GetMessage(obj, v2.TestSuccessCondition)).To(ContainSubstring("1 test hook completed successfully"))
+			g.Expect(obj.Status.History.Latest().HasBeenTested()).To(BeTrue())
+			g.Expect(obj.Status.History.Latest().GetTestHooks()).To(HaveKey("test-hook"))
+			g.Expect(obj.Status.History.Latest().GetTestHooks()["test-hook"].Phase).To(Equal(helmrelease.HookPhaseSucceeded.String()))
+
+			// The release holds the test hooks which ran.
+			rls, err = store.Last(mockReleaseName)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(release.GetTestHooks(rls)).To(HaveKey("test-hook"))
+		})
+	}
+}
+
 func Test_observeTest(t *testing.T) {
 	t.Run("test with current", func(t *testing.T) {
 		g := NewWithT(t)
@@ -514,6 +667,38 @@ func TestTest_failure(t *testing.T) {
 		}))
 	})
 
+	t.Run("records test hook results", func(t *testing.T) {
+		g := NewWithT(t)
+
+		recorder := testutil.NewFakeRecorder(10, false)
+		r := &Test{
+			eventRecorder: recorder,
+		}
+
+		started := metav1.NewTime(time.Date(2024, 5, 7, 4, 48, 50, 0, time.UTC))
+		completed := metav1.NewTime(started.Add(7 * time.Second))
+		obj := obj.DeepCopy()
+		obj.Status.History.Latest().SetTestHooks(map[string]*v2.TestHookStatus{
+			"test-b": {
+				Phase: helmrelease.HookPhaseRunning.String(),
+			},
+			"test-a": {
+				LastStarted:   started,
+				LastCompleted: completed,
+				Phase:         helmrelease.HookPhaseFailed.String(),
+			},
+		})
+		req := &Request{Object: obj}
+		r.failure(req, err)
+
+		events := recorder.GetEvents()
+		g.Expect(events).To(HaveLen(1))
+		g.Expect(events[0].Annotations).To(HaveKeyWithValue(eventMetaGroupKey(eventv1.MetaRevisionKey), cur.Chart.Metadata.Version))
+		g.Expect(events[0].Annotations).To(HaveKeyWithValue(eventMetaGroupKey(metaTestResultsKey),
+			`[{"name":"test-a","phase":"Failed","lastStarted":"2024-05-07T04:48:50Z","lastCompleted":"2024-05-07T04:48:57Z","duration":"7s"},`+
+				`{"name":"test-b","phase":"Running"}]`))
+	})
+
 	t.Run("increases remediation failure count", func(t *testing.T) {
 		g := NewWithT(t)
 
@@ -625,3 +810,38 @@ func TestTest_success(t *testing.T) {
 		g.Expect(req.Object.Status.Conditions[0].Message).To(ContainSubstring("no test hooks"))
 	})
 }
+
+func Test_countTestAttempts(t *testing.T) {
+	g := NewWithT(t)
+
+	first := time.Now()
+	second := first.Add(time.Second)
+	attempts := make(map[string]*v2.TestHookStatus)
+
+	// First attempt, in which one of the tests fails.
+	failed := countTestAttempts(attempts, map[string]*v2.TestHookStatus{
+		"passing": {LastStarted: metav1.NewTime(first), Phase: helmrelease.HookPhaseSucceeded.String()},
+		"flaky":   {LastStarted: metav1.NewTime(first), Phase: helmrelease.HookPhaseFailed.String()},
+		"never":   nil,
+	}, first)
+	g.Expect(failed).To(BeTrue())
+
+	// Retry, in which all tests succeed.
+	hooks := map[string]*v2.TestHookStatus{
+		"passing": {LastStarted: metav1.NewTime(second), Phase: helmrelease.HookPhaseSucceeded.String()},
+		"flaky":   {LastStarted: metav1.NewTime(second), Phase: helmrelease.HookPhaseSucceeded.String()},
+		"stale":   {LastStarted: metav1.NewTime(first.Add(-time.Hour)), Phase: helmrelease.HookPhaseFailed.String()},
+	}
+	failed = countTestAttempts(attempts, hooks, second)
+	g.Expect(failed).To(BeFalse())
+
+	snap := &v2.Snapshot{}
+	snap.SetTestHooks(hooks)
+	setTestAttempts(snap, attempts)
+
+	g.Expect(snap.GetTestHooks()["passing"].Attempts).To(Equal(2))
+	g.Expect(snap.GetTestHooks()["passing"].FailedAttempts).To(Equal(0))
+	g.Expect(snap.GetTestHooks()["flaky"].Attempts).To(Equal(2))
+	g.Expect(snap.GetTestHooks()["flaky"].FailedAttempts).To(Equal(1))
+	g.Expect(snap.GetTestHooks()["stale"].Attempts).To(Equal(0))
+}
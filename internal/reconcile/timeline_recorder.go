@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+)
+
+const (
+	// timelineStartedSuffix is the suffix of the reason of the timeline
+	// event recorded before an action is run.
+	timelineStartedSuffix = "Started"
+	// timelineFinishedSuffix is the suffix of the reason of the timeline
+	// event recorded after an action has run.
+	timelineFinishedSuffix = "Finished"
+)
+
+// timelineRecorder records the start and end of the actions run for a
+// Request.Object as native Kubernetes events, to make the timeline of a
+// reconciliation visible in e.g. `kubectl describe`.
+//
+// The reasons of the timeline events are distinct from those of the events
+// emitted by the ActionReconcilers, and the events are expected to be
+// recorded using a record.EventRecorder which does not forward them to the
+// notification-controller.
+type timelineRecorder struct {
+	recorder record.EventRecorder
+}
+
+// newTimelineRecorder returns a timelineRecorder recording events using the
+// given recorder.
+func newTimelineRecorder(recorder record.EventRecorder) *timelineRecorder {
+	return &timelineRecorder{recorder: recorder}
+}
+
+// actionStarted records a Normal event for the start of the given action.
+func (r *timelineRecorder) actionStarted(req *Request, action ActionReconciler) {
+	r.recorder.AnnotatedEventf(req.Object, timelineEventMeta(req), corev1.EventTypeNormal,
+		timelineReason(action, timelineStartedSuffix), "Started '%s' action for release %s",
+		action.Name(), timelineReleaseName(req))
+}
+
+// actionFinished records an event for the end of the given action, which ran
+// for the given duration and returned the given error. The event is a
+// Warning if the action returned an error or resulted in Ready=False, and
+// its message holds the error or the message of the Ready condition.
+func (r *timelineRecorder) actionFinished(req *Request, action ActionReconciler, duration time.Duration, err error) {
+	eventType, result := corev1.EventTypeNormal, conditions.GetMessage(req.Object, meta.ReadyCondition)
+	switch {
+	case err != nil:
+		eventType, result = corev1.EventTypeWarning, err.Error()
+	case conditions.IsFalse(req.Object, meta.ReadyCondition):
+		eventType = corev1.EventTypeWarning
+	}
+
+	msg := fmt.Sprintf("Finished '%s' action for release %s in %s", action.Name(), timelineReleaseName(req),
+		duration.Round(time.Millisecond).String())
+	if result != "" {
+		msg = fmt.Sprintf("%s: %s", msg, result)
+	}
+	r.recorder.AnnotatedEventf(req.Object, timelineEventMeta(req), eventType,
+		timelineReason(action, timelineFinishedSuffix), "%s", msg)
+}
+
+// timelineReason returns the reason of a timeline event for the given
+// action, composed of the name of the action in PascalCase and the given
+// suffix. For example, "InstallStarted" or "ManualRollbackFinished".
+func timelineReason(action ActionReconciler, suffix string) string {
+	var b strings.Builder
+	for _, w := range strings.FieldsFunc(action.Name(), func(r rune) bool {
+		return r == ' ' || r == '-'
+	}) {
+		rs := []rune(w)
+		rs[0] = unicode.ToUpper(rs[0])
+		b.WriteString(string(rs))
+	}
+	b.WriteString(suffix)
+	return b.String()
+}
+
+// timelineReleaseName returns the full name of the release targeted by the
+// Request, including the version of the chart if known.
+func timelineReleaseName(req *Request) string {
+	name := req.Object.GetReleaseNamespace() + "/" + req.Object.GetReleaseName()
+	if req.Chart != nil && req.Chart.Metadata != nil {
+		name = fmt.Sprintf("%s with chart %s@%s", name, req.Chart.Name(), req.Chart.Metadata.Version)
+	}
+	return name
+}
+
+// timelineEventMeta returns the event metadata of the timeline events for
+// the Request, based on the chart of the Request.
+func timelineEventMeta(req *Request) map[string]string {
+	if req.Chart == nil || req.Chart.Metadata == nil {
+		return nil
+	}
+	return eventMeta(req.Chart.Metadata.Version, req.Object.Status.LastAttemptedConfigDigest,
+		addAppVersion(req.Chart.Metadata.AppVersion))
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reconcile
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
+	"github.com/fluxcd/pkg/apis/meta"
+	"github.com/fluxcd/pkg/runtime/conditions"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/testutil"
+)
+
+func Test_timelineReason(t *testing.T) {
+	tests := []struct {
+		action ActionReconciler
+		suffix string
+		want   string
+	}{
+		{action: &Install{}, suffix: timelineStartedSuffix, want: "InstallStarted"},
+		{action: &Upgrade{}, suffix: timelineFinishedSuffix, want: "UpgradeFinished"},
+		{action: &ManualRollback{}, suffix: timelineStartedSuffix, want: "ManualRollbackStarted"},
+		{action: &CorrectClusterDrift{}, suffix: timelineFinishedSuffix, want: "CorrectClusterDriftFinished"},
+		{action: &AtomicRelease{}, suffix: timelineStartedSuffix, want: "AtomicReleaseStarted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(timelineReason(tt.action, tt.suffix)).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_timelineRecorder(t *testing.T) {
+	newRequest := func() *Request {
+		return &Request{
+			Object: &v2.HelmRelease{
+				ObjectMeta: metav1.ObjectMeta{Name: mockReleaseName, Namespace: mockReleaseNamespace},
+			},
+			Chart: testutil.BuildChart(),
+		}
+	}
+
+	t.Run("records started event", func(t *testing.T) {
+		g := NewWithT(t)
+
+		recorder := testutil.NewFakeRecorder(10, false)
+		newTimelineRecorder(recorder).actionStarted(newRequest(), &Upgrade{})
+
+		events := recorder.GetEvents()
+		g.Expect(events).To(HaveLen(1))
+		g.Expect(events[0].Type).To(Equal(corev1.EventTypeNormal))
+		g.Expect(events[0].Reason).To(Equal("UpgradeStarted"))
+		g.Expect(events[0].Message).To(Equal("Started 'upgrade' action for release mock-ns/mock-release with chart hello@0.1.0"))
+		g.Expect(events[0].Annotations).To(HaveKeyWithValue(eventMetaGroupKey(eventv1.MetaRevisionKey), "0.1.0"))
+	})
+
+	t.Run("records finished event", func(t *testing.T) {
+		g := NewWithT(t)
+
+		req := newRequest()
+		conditions.MarkTrue(req.Object, meta.ReadyCondition, v2.UpgradeSucceededReason, "Helm upgrade succeeded")
+
+		recorder := testutil.NewFakeRecorder(10, false)
+		newTimelineRecorder(recorder).actionFinished(req, &Upgrade{}, 1500*time.Millisecond, nil)
+
+		events := recorder.GetEvents()
+		g.Expect(events).To(HaveLen(1))
+		g.Expect(events[0].Type).To(Equal(corev1.EventTypeNormal))
+		g.Expect(events[0].Reason).To(Equal("UpgradeFinished"))
+		g.Expect(events[0].Message).To(Equal("Finished 'upgrade' action for release mock-ns/mock-release with chart hello@0.1.0 in 1.5s: Helm upgrade succeeded"))
+	})
+
+	t.Run("records warning for failed action", func(t *testing.T) {
+		g := NewWithT(t)
+
+		req := newRequest()
+		conditions.MarkFalse(req.Object, meta.ReadyCondition, v2.TestFailedReason, "test hook failed")
+
+		recorder := testutil.NewFakeRecorder(10, false)
+		newTimelineRecorder(recorder).actionFinished(req, &Test{}, time.Second, nil)
+
+		events := recorder.GetEvents()
+		g.Expect(events).To(HaveLen(1))
+		g.Expect(events[0].Type).To(Equal(corev1.EventTypeWarning))
+		g.Expect(events[0].Reason).To(Equal("TestFinished"))
+		g.Expect(events[0].Message).To(HaveSuffix(": test hook failed"))
+	})
+
+	t.Run("records warning for action error", func(t *testing.T) {
+		g := NewWithT(t)
+
+		recorder := testutil.NewFakeRecorder(10, false)
+		newTimelineRecorder(recorder).actionFinished(newRequest(), &Install{}, time.Second, errors.New("storage error"))
+
+		events := recorder.GetEvents()
+		g.Expect(events).To(HaveLen(1))
+		g.Expect(events[0].Type).To(Equal(corev1.EventTypeWarning))
+		g.Expect(events[0].Message).To(HaveSuffix(": storage error"))
+	})
+}
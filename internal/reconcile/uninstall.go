@@ -194,6 +194,9 @@ func (r *Uninstall) success(req *Request) {
 	// Mark remediation success on object.
 	conditions.MarkFalse(req.Object, v2.ReleasedCondition, v2.UninstallSucceededReason, "%s", msg)
 
+	// The release no longer manages any resources.
+	req.Object.Status.Inventory = nil
+
 	// Record warning event, this message contains more data than the
 	// Condition summary.
 	r.eventRecorder.AnnotatedEventf(
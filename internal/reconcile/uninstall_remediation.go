@@ -145,6 +145,7 @@ func (r *UninstallRemediation) failure(req *Request, buffer *action.LogBuffer, e
 	// Compose success message.
 	cur := req.Object.Status.History.Latest()
 	msg := fmt.Sprintf(fmtUninstallRemediationFailure, cur.FullReleaseName(), cur.VersionedChartName(), strings.TrimSpace(err.Error()))
+	msg = recordRemediation(req.Object, v2.RemediationActionUninstall, cur.FullReleaseName(), false, msg)
 
 	// Mark uninstall failure on object.
 	req.Object.Status.Failures++
@@ -168,10 +169,14 @@ func (r *UninstallRemediation) success(req *Request) {
 	// Compose success message.
 	cur := req.Object.Status.History.Latest()
 	msg := fmt.Sprintf(fmtUninstallRemediationSuccess, cur.FullReleaseName(), cur.VersionedChartName())
+	msg = recordRemediation(req.Object, v2.RemediationActionUninstall, cur.FullReleaseName(), true, msg)
 
 	// Mark remediation success on object.
 	conditions.MarkTrue(req.Object, v2.RemediatedCondition, v2.UninstallSucceededReason, "%s", msg)
 
+	// The release no longer manages any resources.
+	req.Object.Status.Inventory = nil
+
 	// Record event.
 	r.eventRecorder.AnnotatedEventf(
 		req.Object,
@@ -605,10 +605,12 @@ func TestUninstall_success(t *testing.T) {
 			History: v2.Snapshots{
 				release.ObservedToSnapshot(release.ObserveRelease(cur)),
 			},
+			Inventory: &v2.ResourceInventory{Count: 1, Digest: "sha256:inventory"},
 		},
 	}
 	req := &Request{Object: obj}
 	r.success(req)
+	g.Expect(req.Object.Status.Inventory).To(BeNil())
 
 	expectMsg := fmt.Sprintf(fmtUninstallSuccess,
 		fmt.Sprintf("%s/%s.v%d", cur.Namespace, cur.Name, cur.Version),
@@ -18,8 +18,10 @@ package reconcile
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/record"
@@ -50,11 +52,19 @@ import (
 // At the end of the reconciliation, the Status.Conditions are summarized and
 // propagated to the Ready condition on the Request.Object.
 //
+// When the upgrade is forced using the v2.ForceRequestAnnotation, the token
+// of the force request is included in the token of the emitted events.
+//
+// When the upgrade fails due to a change of an immutable field, and
+// v2.Upgrade.RecreateImmutable is set, the conflicting resources are deleted
+// and the upgrade is retried once.
+//
 // The caller is assumed to have verified the integrity of Request.Object using
 // e.g. action.VerifySnapshot before calling Reconcile.
 type Upgrade struct {
 	configFactory *action.ConfigFactory
 	eventRecorder record.EventRecorder
+	forceToken    string
 }
 
 // NewUpgrade returns a new Upgrade reconciler configured with the provided
@@ -63,11 +73,18 @@ func NewUpgrade(cfg *action.ConfigFactory, recorder record.EventRecorder) *Upgra
 	return &Upgrade{configFactory: cfg, eventRecorder: recorder}
 }
 
+// NewForcedUpgrade returns a new Upgrade reconciler configured with the
+// provided values, for an upgrade forced by the request with the given
+// token.
+func NewForcedUpgrade(cfg *action.ConfigFactory, recorder record.EventRecorder, token string) *Upgrade {
+	return &Upgrade{configFactory: cfg, eventRecorder: recorder, forceToken: token}
+}
+
 func (r *Upgrade) Reconcile(ctx context.Context, req *Request) error {
 	var (
 		logBuf      = action.NewLogBuffer(action.NewDebugLog(ctrl.LoggerFrom(ctx).V(logger.DebugLevel)), 10)
 		obsReleases = make(observedReleases)
-		cfg         = r.configFactory.Build(logBuf.Log, observeRelease(obsReleases))
+		cfg         = r.configFactory.Build(logBuf.Log, observeRelease(obsReleases, time.Now()))
 	)
 
 	defer summarize(req)
@@ -79,11 +96,45 @@ func (r *Upgrade) Reconcile(ctx context.Context, req *Request) error {
 	conditions.Delete(req.Object, v2.TestSuccessCondition)
 	conditions.Delete(req.Object, v2.RemediatedCondition)
 
+	// Back up the current release before upgrading it, so that it can be
+	// restored even after it has been pruned from the Helm storage.
+	if req.Object.GetUpgrade().Backup {
+		if err := r.backup(req); err != nil {
+			conditions.MarkFalse(req.Object, v2.ReleasedCondition, v2.BackupFailedReason, "%s", err)
+			r.eventRecorder.Event(req.Object, corev1.EventTypeWarning, v2.BackupFailedReason, err.Error())
+			return err
+		}
+	}
+
 	// Run the Helm upgrade action.
 	_, err := action.Upgrade(ctx, cfg, req.Object, req.Chart, req.Values)
 
-	// Record the history of releases observed during the upgrade.
-	obsReleases.recordOnObject(req.Object, mutateOCIDigest)
+	// Record the history and notes of the releases observed during the
+	// upgrade.
+	r.record(req, obsReleases)
+
+	// Recreate the resources of which an immutable field conflicts with the
+	// desired state, and retry the upgrade.
+	if conflicts := action.ImmutableFieldConflicts(err); len(conflicts) > 0 && req.Object.GetUpgrade().RecreateImmutable {
+		if recreateErr := r.recreateImmutable(ctx, req, logBuf, conflicts); recreateErr != nil {
+			ctrl.LoggerFrom(ctx).Error(recreateErr, "failed to recreate resources with immutable field conflicts")
+		} else {
+			obsReleases = make(observedReleases)
+			cfg = r.configFactory.Build(logBuf.Log, observeRelease(obsReleases, time.Now()))
+			_, err = action.Upgrade(ctx, cfg, req.Object, req.Chart, req.Values)
+			r.record(req, obsReleases)
+		}
+	}
+
+	// Prune the releases which exceed the retention of the history.
+	if pruneErr := pruneHistory(r.configFactory.Build(nil), req.Object, time.Now()); pruneErr != nil {
+		ctrl.LoggerFrom(ctx).Error(pruneErr, "failed to prune release history")
+	}
+
+	// Remove the backups of releases which are no longer in the history.
+	if pruneErr := pruneBackups(r.configFactory.Backups, req.Object); pruneErr != nil {
+		ctrl.LoggerFrom(ctx).Error(pruneErr, "failed to prune release backups")
+	}
 
 	if err != nil {
 		r.failure(req, logBuf, err)
@@ -100,11 +151,152 @@ func (r *Upgrade) Reconcile(ctx context.Context, req *Request) error {
 		// without a new release in storage there is nothing to remediate,
 		// and the action can be retried immediately without causing
 		// storage drift.
-		req.Object.GetUpgrade().GetRemediation().IncrementFailureCount(req.Object)
+		if !countFailure(req.Object, req.Object.GetUpgrade().GetRemediation(), time.Now()) {
+			ctrl.LoggerFrom(ctx).Info(fmt.Sprintf("not counting upgrade failure within retry delay of %s",
+				req.Object.GetUpgrade().GetRemediation().GetRetryDelay().String()))
+		}
+		return nil
+	}
+
+	// Record the inventory of the resources of the release, and prune the
+	// resources of the previous inventory which are no longer part of it.
+	prevInventory := req.Object.Status.Inventory
+	if invErr := obsReleases.recordInventoryOnObject(r.configFactory.Build(nil), req.Object); invErr != nil {
+		ctrl.LoggerFrom(ctx).Error(invErr, "failed to record resource inventory")
+	} else if req.Object.GetUpgrade().Prune {
+		pruneOrphans(ctx, r.configFactory, r.eventRecorder, req, prevInventory,
+			r.eventToken(req.Object.Status.History.Latest().ConfigDigest))
+	}
+
+	r.success(req, action.AppliedBatches(cfg))
+	return nil
+}
+
+// record records the history and notes of the given releases observed
+// during the upgrade on the Request.Object.
+func (r *Upgrade) record(req *Request, obsReleases observedReleases) {
+	obsReleases.recordOnObject(req.Object, mutateOCIDigest, mutateValues(req), mutateVerifiedDigest(req), mutateChartSource(req),
+		mutateCRDsPolicy(req.Object.GetUpgrade().CRDs))
+	obsReleases.recordNotesOnObject(req.Object)
+}
+
+// recreateImmutable deletes the given resources of the failed release of the
+// Request.Object, so that they are recreated by a next upgrade. It emits an
+// event listing the resources when they have been deleted.
+func (r *Upgrade) recreateImmutable(ctx context.Context, req *Request, buffer *action.LogBuffer, conflicts []action.ImmutableResource) error {
+	cur := req.Object.Status.History.Latest()
+	if cur == nil {
+		return errors.New("no release to recreate resources of")
+	}
+
+	cfg := r.configFactory.Build(buffer.Log)
+	rls, err := cfg.Releases.Get(cur.Name, cur.Version)
+	if err != nil {
+		return fmt.Errorf("failed to get release %s: %w", cur.FullReleaseName(), err)
+	}
+
+	names := make([]string, 0, len(conflicts))
+	for _, c := range conflicts {
+		names = append(names, c.String())
+	}
+	ctrl.LoggerFrom(ctx).Info(fmt.Sprintf("recreating resources with immutable field conflicts: %s", strings.Join(names, ", ")))
+
+	timeout := req.Object.GetUpgrade().GetTimeout(req.Object.GetTimeout()).Duration
+	if err = action.DeleteImmutableResources(cfg, rls.Manifest, conflicts, timeout); err != nil {
+		return err
+	}
+
+	r.eventRecorder.AnnotatedEventf(
+		req.Object,
+		eventMeta(cur.ChartVersion, r.eventToken(cur.ConfigDigest), addAppVersion(cur.AppVersion)),
+		corev1.EventTypeNormal,
+		v2.ImmutableResourcesRecreatedReason,
+		"Recreating resources of release %s with immutable field conflicts: %s",
+		cur.FullReleaseName(), strings.Join(names, ", "),
+	)
+	return nil
+}
+
+// pruneOrphans deletes the resources of the given previous inventory which
+// are absent from the current inventory of the Request.Object. It emits an
+// event with the given token listing the pruned resources, and a warning
+// event if any resource could not be pruned. Resources which are owned by
+// another release are not pruned, and are reported in a separate event. A
+// failure to prune does not fail the release action, as the release itself
+// succeeded.
+func pruneOrphans(ctx context.Context, cfg *action.ConfigFactory, recorder record.EventRecorder, req *Request,
+	previous *v2.ResourceInventory, token string) {
+	cur := req.Object.Status.History.Latest()
+	pruned, disowned, err := action.PruneOrphans(cfg.Build(nil), cur.Name, cur.Namespace,
+		previous, req.Object.Status.Inventory)
+
+	if len(pruned) > 0 {
+		ids := make([]string, 0, len(pruned))
+		for _, ref := range pruned {
+			ids = append(ids, ref.ID)
+		}
+		ctrl.LoggerFrom(ctx).Info(fmt.Sprintf("pruned orphaned resources: %s", strings.Join(ids, ", ")))
+		recorder.AnnotatedEventf(
+			req.Object,
+			eventMeta(cur.ChartVersion, token, addAppVersion(cur.AppVersion)),
+			corev1.EventTypeNormal,
+			v2.ResourcesPrunedReason,
+			"Pruned %d orphaned resource(s) of release %s: %s",
+			len(pruned), cur.FullReleaseName(), strings.Join(ids, ", "),
+		)
+	}
+
+	if len(disowned) > 0 {
+		ids := make([]string, 0, len(disowned))
+		for _, ref := range disowned {
+			ids = append(ids, ref.ID)
+		}
+		ctrl.LoggerFrom(ctx).Info(fmt.Sprintf("skipped pruning resources owned by another release: %s", strings.Join(ids, ", ")))
+		recorder.AnnotatedEventf(
+			req.Object,
+			eventMeta(cur.ChartVersion, token, addAppVersion(cur.AppVersion)),
+			corev1.EventTypeNormal,
+			v2.PruneSkippedReason,
+			"Skipped pruning %d orphaned resource(s) of release %s owned by another release: %s",
+			len(disowned), cur.FullReleaseName(), strings.Join(ids, ", "),
+		)
+	}
+
+	if err != nil {
+		ctrl.LoggerFrom(ctx).Error(err, "failed to prune orphaned resources")
+		recorder.AnnotatedEventf(
+			req.Object,
+			eventMeta(cur.ChartVersion, token, addAppVersion(cur.AppVersion)),
+			corev1.EventTypeWarning,
+			v2.PruneFailedReason,
+			"Failed to prune orphaned resources of release %s: %s",
+			cur.FullReleaseName(), err.Error(),
+		)
+	}
+}
+
+// backup saves a backup of the current release of the Request.Object to the
+// BackupStore of the ConfigFactory, and records the name of the backup on the
+// Snapshot of the release. A release which has already been backed up, e.g.
+// during a previous upgrade attempt, is not backed up again.
+func (r *Upgrade) backup(req *Request) error {
+	cur := req.Object.Status.History.Latest()
+	if cur == nil || cur.Backup != "" {
 		return nil
 	}
+	if r.configFactory.Backups == nil {
+		return fmt.Errorf("failed to back up release %s: no backup store configured", cur.FullReleaseName())
+	}
 
-	r.success(req)
+	rls, err := r.configFactory.Build(nil).Releases.Get(cur.Name, cur.Version)
+	if err != nil {
+		return fmt.Errorf("failed to get release %s to back up: %w", cur.FullReleaseName(), err)
+	}
+	name, err := r.configFactory.Backups.Save(rls)
+	if err != nil {
+		return fmt.Errorf("failed to back up release %s: %w", cur.FullReleaseName(), err)
+	}
+	cur.Backup = name
 	return nil
 }
 
@@ -134,20 +326,22 @@ const (
 // result in Helm storage drift.
 func (r *Upgrade) failure(req *Request, buffer *action.LogBuffer, err error) {
 	// Compose failure message.
-	msg := fmt.Sprintf(fmtUpgradeFailure, req.Object.GetReleaseNamespace(), req.Object.GetReleaseName(), req.Chart.Name(), req.Chart.Metadata.Version, strings.TrimSpace(err.Error()))
+	msg := fmt.Sprintf(fmtUpgradeFailure, req.Object.GetReleaseNamespace(), req.Object.GetReleaseName(), req.Chart.Name(), req.Chart.Metadata.Version, releaseErrorMessage(req, err))
 
 	// Mark upgrade failure on object.
 	req.Object.Status.Failures++
-	conditions.MarkFalse(req.Object, v2.ReleasedCondition, v2.UpgradeFailedReason, "%s", msg)
+	req.Object.Status.LastFailureClass = classifyFailure(err)
+	reason := releaseFailureReason(err, v2.UpgradeFailedReason)
+	conditions.MarkFalse(req.Object, v2.ReleasedCondition, reason, "%s", msg)
 
 	// Record warning event, this message contains more data than the
 	// Condition summary.
 	r.eventRecorder.AnnotatedEventf(
 		req.Object,
-		eventMeta(req.Chart.Metadata.Version, chartutil.DigestValues(digest.Canonical, req.Values).String(),
+		eventMeta(req.Chart.Metadata.Version, r.eventToken(chartutil.DigestValues(digest.Canonical, req.Values).String()),
 			addAppVersion(req.Chart.AppVersion()), addOCIDigest(req.Object.Status.LastAttemptedRevisionDigest)),
 		corev1.EventTypeWarning,
-		v2.UpgradeFailedReason,
+		reason,
 		eventMessageWithLog(msg, buffer),
 	)
 }
@@ -156,14 +350,24 @@ func (r *Upgrade) failure(req *Request, buffer *action.LogBuffer, err error) {
 // given Request.Object by marking ReleasedCondition=True and emitting an
 // event. In addition, it marks TestSuccessCondition=False when tests are
 // enabled to indicate we are awaiting test results after having made the
-// release.
-func (r *Upgrade) success(req *Request) {
+// release. When the resources were applied in batches, the message
+// includes the given progress.
+func (r *Upgrade) success(req *Request, batches action.BatchProgress) {
 	// Compose success message.
 	cur := req.Object.Status.History.Latest()
 	msg := fmt.Sprintf(fmtUpgradeSuccess, cur.FullReleaseName(), cur.VersionedChartName())
+	if batches.Total > 0 {
+		msg = fmt.Sprintf("%s, %s", msg, batches)
+	}
 
 	// Mark upgrade success on object.
 	conditions.MarkTrue(req.Object, v2.ReleasedCondition, v2.UpgradeSucceededReason, "%s", msg)
+
+	// A successful release starts a new retry delay and backoff on the
+	// next failure.
+	req.Object.Status.FirstFailureTime = nil
+	req.Object.Status.ConsecutiveFailures = 0
+	req.Object.Status.LastFailureClass = ""
 	if req.Object.GetTest().Enable && !cur.HasBeenTested() {
 		conditions.MarkUnknown(req.Object, v2.TestSuccessCondition, "AwaitingTests", fmtTestPending,
 			cur.FullReleaseName(), cur.VersionedChartName())
@@ -172,9 +376,20 @@ func (r *Upgrade) success(req *Request) {
 	// Record event.
 	r.eventRecorder.AnnotatedEventf(
 		req.Object,
-		eventMeta(cur.ChartVersion, cur.ConfigDigest, addAppVersion(cur.AppVersion), addOCIDigest(cur.OCIDigest)),
+		eventMeta(cur.ChartVersion, r.eventToken(cur.ConfigDigest), addAppVersion(cur.AppVersion), addOCIDigest(cur.OCIDigest)),
 		corev1.EventTypeNormal,
 		v2.UpgradeSucceededReason,
 		msg,
 	)
 }
+
+// eventToken returns the token for the events of the upgrade, which is the
+// given config digest suffixed with the force request token when the upgrade
+// was forced. This ensures the events of a forced upgrade are not considered
+// duplicates of the events of the previous upgrade with the same config.
+func (r *Upgrade) eventToken(configDigest string) string {
+	if r.forceToken == "" {
+		return configDigest
+	}
+	return configDigest + "/" + r.forceToken
+}
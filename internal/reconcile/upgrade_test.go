@@ -31,8 +31,10 @@ import (
 	helmstorage "helm.sh/helm/v3/pkg/storage"
 	helmdriver "helm.sh/helm/v3/pkg/storage/driver"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	eventv1 "github.com/fluxcd/pkg/apis/event/v1beta1"
 	"github.com/fluxcd/pkg/apis/meta"
@@ -419,7 +421,7 @@ func TestUpgrade_Reconcile(t *testing.T) {
 			helmreleaseutil.SortByRevision(releases)
 
 			if tt.expectHistory != nil {
-				g.Expect(obj.Status.History).To(testutil.Equal(tt.expectHistory(releases)))
+				g.Expect(obj.Status.History).To(testutil.Equal(tt.expectHistory(releases), ignoreSnapshotDuration))
 			} else {
 				g.Expect(obj.Status.History).To(BeEmpty(), "expected history to be empty")
 			}
@@ -431,6 +433,259 @@ func TestUpgrade_Reconcile(t *testing.T) {
 	}
 }
 
+func TestUpgrade_Reconcile_RecreateImmutable(t *testing.T) {
+	tests := []struct {
+		name              string
+		recreateImmutable bool
+		wantReleased      bool
+	}{
+		{
+			name:         "immutable field conflict fails upgrade",
+			wantReleased: false,
+		},
+		{
+			name:              "immutable field conflict recreates resource",
+			recreateImmutable: true,
+			wantReleased:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+			g.Expect(err).NotTo(HaveOccurred())
+			t.Cleanup(func() {
+				_ = testEnv.Delete(context.TODO(), namedNS)
+			})
+			releaseNamespace := namedNS.Name
+
+			obj := &v2.HelmRelease{
+				Spec: v2.HelmReleaseSpec{
+					ReleaseName:      mockReleaseName,
+					TargetNamespace:  releaseNamespace,
+					StorageNamespace: releaseNamespace,
+					Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+					Upgrade: &v2.Upgrade{
+						Timeout:           &metav1.Duration{Duration: 10 * time.Second},
+						RecreateImmutable: tt.recreateImmutable,
+					},
+				},
+			}
+
+			getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+			g.Expect(err).ToNot(HaveOccurred())
+
+			cfg, err := action.NewConfigFactory(getter,
+				action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+			)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			recorder := testutil.NewFakeRecorder(10, false)
+			g.Expect(NewInstall(cfg, recorder).Reconcile(context.TODO(), &Request{
+				Object: obj,
+				Chart:  testutil.BuildChart(testutil.ChartWithImmutableConfigMap("bar")),
+			})).To(Succeed())
+			g.Expect(conditions.IsTrue(obj, v2.ReleasedCondition)).To(BeTrue())
+			_ = recorder.GetEvents()
+
+			g.Expect(NewUpgrade(cfg, recorder).Reconcile(context.TODO(), &Request{
+				Object: obj,
+				Chart:  testutil.BuildChart(testutil.ChartWithImmutableConfigMap("baz")),
+			})).To(Succeed())
+
+			reason := func(e corev1.Event) string { return e.Reason }
+			cm := &corev1.ConfigMap{}
+			g.Expect(testEnv.Get(context.TODO(), client.ObjectKey{Namespace: releaseNamespace, Name: "immutable"}, cm)).To(Succeed())
+
+			if !tt.wantReleased {
+				g.Expect(conditions.IsFalse(obj, v2.ReleasedCondition)).To(BeTrue())
+				g.Expect(obj.Status.LastFailureClass).To(Equal(v2.FailureClassImmutableField))
+				g.Expect(obj.Status.UpgradeFailures).To(Equal(int64(1)))
+				g.Expect(cm.Data).To(HaveKeyWithValue("foo", "bar"))
+				g.Expect(recorder.GetEvents()).ToNot(ContainElement(
+					WithTransform(reason, Equal(v2.ImmutableResourcesRecreatedReason))))
+				return
+			}
+
+			g.Expect(conditions.IsTrue(obj, v2.ReleasedCondition)).To(BeTrue())
+			g.Expect(obj.Status.LastFailureClass).To(BeEmpty())
+			g.Expect(obj.Status.UpgradeFailures).To(BeZero())
+			g.Expect(cm.Data).To(HaveKeyWithValue("foo", "baz"))
+			g.Expect(obj.Status.Inventory).ToNot(BeNil())
+			g.Expect(obj.Status.Inventory.Entries).To(ContainElement(
+				v2.ResourceRef{ID: releaseNamespace + "_immutable__ConfigMap", Version: "v1"}))
+			g.Expect(recorder.GetEvents()).To(ContainElement(SatisfyAll(
+				WithTransform(reason, Equal(v2.ImmutableResourcesRecreatedReason)),
+				WithTransform(func(e corev1.Event) string { return e.Message }, ContainSubstring("ConfigMap/immutable")),
+			)))
+
+			// The failed release which triggered the recreation is recorded
+			// in the history.
+			g.Expect(obj.Status.History).To(HaveLen(3))
+			g.Expect(obj.Status.History[0].Status).To(Equal(helmrelease.StatusDeployed.String()))
+			g.Expect(obj.Status.History[1].Version).To(Equal(2))
+		})
+	}
+}
+
+func TestUpgrade_Reconcile_Prune(t *testing.T) {
+	g := NewWithT(t)
+
+	namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	t.Cleanup(func() {
+		_ = testEnv.Delete(context.TODO(), namedNS)
+	})
+	releaseNamespace := namedNS.Name
+
+	obj := &v2.HelmRelease{
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			TargetNamespace:  releaseNamespace,
+			StorageNamespace: releaseNamespace,
+			Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+			Upgrade: &v2.Upgrade{
+				Prune: true,
+			},
+		},
+	}
+
+	getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cfg, err := action.NewConfigFactory(getter,
+		action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	recorder := testutil.NewFakeRecorder(10, false)
+	g.Expect(NewInstall(cfg, recorder).Reconcile(context.TODO(), &Request{
+		Object: obj,
+		Chart:  testutil.BuildChart(),
+	})).To(Succeed())
+	g.Expect(conditions.IsTrue(obj, v2.ReleasedCondition)).To(BeTrue())
+	_ = recorder.GetEvents()
+
+	// Simulate resources left behind by a previous chart, of which one is
+	// protected from being pruned, and one has been adopted by another
+	// release.
+	owner := map[string]string{
+		"meta.helm.sh/release-name":      mockReleaseName,
+		"meta.helm.sh/release-namespace": releaseNamespace,
+	}
+	orphan := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "orphan", Namespace: releaseNamespace, Annotations: owner},
+	}
+	protected := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "protected",
+			Namespace: releaseNamespace,
+			Annotations: map[string]string{
+				"meta.helm.sh/release-name":      mockReleaseName,
+				"meta.helm.sh/release-namespace": releaseNamespace,
+				v2.PruneAnnotation:               v2.PruneDisabledValue,
+			},
+		},
+	}
+	adopted := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "adopted",
+			Namespace: releaseNamespace,
+			Annotations: map[string]string{
+				"meta.helm.sh/release-name":      "other",
+				"meta.helm.sh/release-namespace": releaseNamespace,
+			},
+		},
+	}
+	g.Expect(testEnv.Create(context.TODO(), orphan)).To(Succeed())
+	g.Expect(testEnv.Create(context.TODO(), protected)).To(Succeed())
+	g.Expect(testEnv.Create(context.TODO(), adopted)).To(Succeed())
+
+	orphanRef := v2.ResourceRef{ID: releaseNamespace + "_orphan__ConfigMap", Version: "v1"}
+	protectedRef := v2.ResourceRef{ID: releaseNamespace + "_protected__ConfigMap", Version: "v1"}
+	adoptedRef := v2.ResourceRef{ID: releaseNamespace + "_adopted__ConfigMap", Version: "v1"}
+	obj.Status.Inventory.Entries = append(obj.Status.Inventory.Entries, orphanRef, protectedRef, adoptedRef)
+	obj.Status.Inventory.Count = len(obj.Status.Inventory.Entries)
+
+	g.Expect(NewUpgrade(cfg, recorder).Reconcile(context.TODO(), &Request{
+		Object: obj,
+		Chart:  testutil.BuildChart(),
+	})).To(Succeed())
+	g.Expect(conditions.IsTrue(obj, v2.ReleasedCondition)).To(BeTrue())
+
+	err = testEnv.Get(context.TODO(), client.ObjectKeyFromObject(orphan), &corev1.ConfigMap{})
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	g.Expect(testEnv.Get(context.TODO(), client.ObjectKeyFromObject(protected), &corev1.ConfigMap{})).To(Succeed())
+	g.Expect(testEnv.Get(context.TODO(), client.ObjectKeyFromObject(adopted), &corev1.ConfigMap{})).To(Succeed())
+
+	g.Expect(obj.Status.Inventory.Entries).ToNot(ContainElements(orphanRef, protectedRef, adoptedRef))
+	events := recorder.GetEvents()
+	g.Expect(events).To(ContainElement(SatisfyAll(
+		WithTransform(func(e corev1.Event) string { return e.Reason }, Equal(v2.ResourcesPrunedReason)),
+		WithTransform(func(e corev1.Event) string { return e.Message }, SatisfyAll(
+			ContainSubstring(orphanRef.ID), Not(ContainSubstring(protectedRef.ID)), Not(ContainSubstring(adoptedRef.ID)),
+		)),
+	)))
+	g.Expect(events).To(ContainElement(SatisfyAll(
+		WithTransform(func(e corev1.Event) string { return e.Reason }, Equal(v2.PruneSkippedReason)),
+		WithTransform(func(e corev1.Event) string { return e.Message }, ContainSubstring(adoptedRef.ID)),
+	)))
+}
+
+func TestUpgrade_Reconcile_ApplyBatches(t *testing.T) {
+	g := NewWithT(t)
+
+	pause := action.ApplyBatchPause
+	action.ApplyBatchPause = 0
+	t.Cleanup(func() {
+		action.ApplyBatchPause = pause
+	})
+
+	namedNS, err := testEnv.CreateNamespace(context.TODO(), mockReleaseNamespace)
+	g.Expect(err).NotTo(HaveOccurred())
+	t.Cleanup(func() {
+		_ = testEnv.Delete(context.TODO(), namedNS)
+	})
+	releaseNamespace := namedNS.Name
+
+	obj := &v2.HelmRelease{
+		Spec: v2.HelmReleaseSpec{
+			ReleaseName:      mockReleaseName,
+			TargetNamespace:  releaseNamespace,
+			StorageNamespace: releaseNamespace,
+			Timeout:          &metav1.Duration{Duration: 100 * time.Millisecond},
+			Upgrade: &v2.Upgrade{
+				ApplyBatchSize: 1,
+			},
+		},
+	}
+
+	getter, err := RESTClientGetterFromManager(testEnv.Manager, obj.GetReleaseNamespace())
+	g.Expect(err).ToNot(HaveOccurred())
+
+	cfg, err := action.NewConfigFactory(getter,
+		action.WithStorage(action.DefaultStorageDriver, obj.GetStorageNamespace()),
+	)
+	g.Expect(err).ToNot(HaveOccurred())
+
+	recorder := testutil.NewFakeRecorder(10, false)
+	chart := testutil.BuildChart(testutil.ChartWithImmutableConfigMap("bar"))
+	g.Expect(NewInstall(cfg, recorder).Reconcile(context.TODO(), &Request{
+		Object: obj,
+		Chart:  chart,
+	})).To(Succeed())
+	g.Expect(conditions.IsTrue(obj, v2.ReleasedCondition)).To(BeTrue())
+
+	// The chart has two resources, which are applied in a batch each.
+	g.Expect(NewUpgrade(cfg, recorder).Reconcile(context.TODO(), &Request{
+		Object: obj,
+		Chart:  chart,
+	})).To(Succeed())
+	g.Expect(conditions.IsTrue(obj, v2.ReleasedCondition)).To(BeTrue())
+	g.Expect(conditions.GetMessage(obj, v2.ReleasedCondition)).To(HaveSuffix(", applied 2/2 batches"))
+}
+
 func TestUpgrade_failure(t *testing.T) {
 	var (
 		obj = &v2.HelmRelease{
@@ -528,7 +783,8 @@ func TestUpgrade_success(t *testing.T) {
 		req := &Request{
 			Object: obj.DeepCopy(),
 		}
-		r.success(req)
+		req.Object.Status.ConsecutiveFailures = 3
+		r.success(req, action.BatchProgress{})
 
 		expectMsg := fmt.Sprintf(fmtUpgradeSuccess,
 			fmt.Sprintf("%s/%s.v%d", mockReleaseNamespace, mockReleaseName, obj.Status.History.Latest().Version),
@@ -551,6 +807,27 @@ func TestUpgrade_success(t *testing.T) {
 				},
 			},
 		}))
+		g.Expect(req.Object.Status.ConsecutiveFailures).To(BeZero())
+	})
+
+	t.Run("records success of forced upgrade", func(t *testing.T) {
+		g := NewWithT(t)
+
+		recorder := testutil.NewFakeRecorder(10, false)
+		r := &Upgrade{
+			eventRecorder: recorder,
+			forceToken:    "force-token",
+		}
+
+		req := &Request{
+			Object: obj.DeepCopy(),
+		}
+		r.success(req, action.BatchProgress{})
+
+		events := recorder.GetEvents()
+		g.Expect(events).To(HaveLen(1))
+		g.Expect(events[0].Annotations).To(HaveKeyWithValue(eventMetaGroupKey(eventv1.MetaTokenKey),
+			obj.Status.History.Latest().ConfigDigest+"/force-token"))
 	})
 
 	t.Run("records success with TestSuccess=False", func(t *testing.T) {
@@ -565,7 +842,7 @@ func TestUpgrade_success(t *testing.T) {
 		obj.Spec.Test = &v2.Test{Enable: true}
 
 		req := &Request{Object: obj}
-		r.success(req)
+		r.success(req, action.BatchProgress{})
 
 		g.Expect(conditions.IsTrue(req.Object, v2.ReleasedCondition)).To(BeTrue())
 
@@ -19,6 +19,8 @@ package release
 import (
 	"encoding/json"
 	"io"
+	"strings"
+	"time"
 
 	"github.com/mitchellh/copystructure"
 	"helm.sh/helm/v3/pkg/chart"
@@ -28,6 +30,7 @@ import (
 	v2 "github.com/fluxcd/helm-controller/api/v2"
 	"github.com/fluxcd/helm-controller/internal/chartutil"
 	"github.com/fluxcd/helm-controller/internal/digest"
+	"github.com/fluxcd/helm-controller/internal/features"
 )
 
 var (
@@ -81,6 +84,42 @@ type Observation struct {
 	Namespace string `json:"namespace"`
 	// OCIDigest is the digest of the OCI artifact that was used to
 	OCIDigest string `json:"ociDigest,omitempty"`
+	// Duration is the time elapsed between the start of the Helm action and
+	// the observation of the release. It is not encoded, to not affect the
+	// digest of the Observation.
+	Duration time.Duration `json:"-"`
+	// ValuesChecksum is the checksum of the fully merged values of the
+	// release, as composed by the controller. It is not encoded, to not
+	// affect the digest of the Observation.
+	ValuesChecksum string `json:"-"`
+	// Values is a snapshot of the fully merged values of the release, as
+	// composed by the controller. It is not encoded, to not affect the
+	// digest of the Observation.
+	Values string `json:"-"`
+	// VerifiedDigest is the digest of the OCI artifact of the release, of
+	// which the signature was verified by the controller. It is not encoded,
+	// to not affect the digest of the Observation.
+	VerifiedDigest string `json:"-"`
+	// VerificationDigest is the digest of the verification configuration
+	// with which the VerifiedDigest was verified. It is not encoded, to not
+	// affect the digest of the Observation.
+	VerificationDigest string `json:"-"`
+	// ChartSourceRevision is the revision of the source artifact from which
+	// the chart of the release was loaded. It is not encoded, to not affect
+	// the digest of the Observation.
+	ChartSourceRevision string `json:"-"`
+	// ChartDigest is the digest of the source artifact from which the chart
+	// of the release was loaded. It is not encoded, to not affect the digest
+	// of the Observation.
+	ChartDigest string `json:"-"`
+	// CRDsPolicy is the policy with which the CustomResourceDefinitions of
+	// the chart were applied. It is not encoded, to not affect the digest of
+	// the Observation.
+	CRDsPolicy v2.CRDsPolicy `json:"-"`
+	// Backup is the name of the backup of the release taken by the
+	// controller. It is not encoded, to not affect the digest of the
+	// Observation.
+	Backup string `json:"-"`
 }
 
 // Targets returns if the release matches the given name, namespace and
@@ -154,25 +193,64 @@ func ObserveRelease(rel *helmrelease.Release, filter ...DataFilter) Observation
 
 // ObservedToSnapshot returns a v2.Snapshot constructed from the
 // Observation data. Calculating the (config) digest using the
-// digest.Canonical algorithm.
+// digest.Canonical algorithm. When the ValuesDigestsInHistory feature is
+// enabled, the digests of the values per top-level key are included.
+// The Duration, ValuesChecksum, Values, VerifiedDigest, VerificationDigest,
+// ChartSourceRevision, ChartDigest, CRDsPolicy and Backup of the Observation
+// are included if set.
 func ObservedToSnapshot(rls Observation) *v2.Snapshot {
+	var valuesDigests map[string]string
+	if ok, _ := features.Enabled(features.ValuesDigestsInHistory); ok {
+		valuesDigests = chartutil.DigestValuesByKey(digest.Canonical, rls.Config)
+	}
+
+	var duration *metav1.Duration
+	if rls.Duration > 0 {
+		duration = &metav1.Duration{Duration: rls.Duration.Round(time.Millisecond)}
+	}
+
 	return &v2.Snapshot{
-		Digest:        Digest(digest.Canonical, rls).String(),
-		Name:          rls.Name,
-		Namespace:     rls.Namespace,
-		Version:       rls.Version,
-		AppVersion:    rls.ChartMetadata.AppVersion,
-		ChartName:     rls.ChartMetadata.Name,
-		ChartVersion:  rls.ChartMetadata.Version,
-		ConfigDigest:  chartutil.DigestValues(digest.Canonical, rls.Config).String(),
-		FirstDeployed: metav1.NewTime(rls.Info.FirstDeployed.Time),
-		LastDeployed:  metav1.NewTime(rls.Info.LastDeployed.Time),
-		Deleted:       metav1.NewTime(rls.Info.Deleted.Time),
-		Status:        rls.Info.Status.String(),
-		OCIDigest:     rls.OCIDigest,
+		Digest:              Digest(digest.Canonical, rls).String(),
+		Name:                rls.Name,
+		Namespace:           rls.Namespace,
+		Version:             rls.Version,
+		AppVersion:          rls.ChartMetadata.AppVersion,
+		ChartName:           rls.ChartMetadata.Name,
+		ChartVersion:        rls.ChartMetadata.Version,
+		ChartSourceRevision: rls.ChartSourceRevision,
+		ChartDigest:         rls.ChartDigest,
+		CRDsPolicy:          rls.CRDsPolicy,
+		ConfigDigest:        chartutil.DigestValues(digest.Canonical, rls.Config).String(),
+		FirstDeployed:       metav1.NewTime(rls.Info.FirstDeployed.Time),
+		LastDeployed:        metav1.NewTime(rls.Info.LastDeployed.Time),
+		Deleted:             metav1.NewTime(rls.Info.Deleted.Time),
+		Status:              rls.Info.Status.String(),
+		StatusReason:        statusReason(rls.Info.Description),
+		OCIDigest:           rls.OCIDigest,
+		ValuesDigests:       valuesDigests,
+		Duration:            duration,
+		ValuesChecksum:      rls.ValuesChecksum,
+		Values:              rls.Values,
+		VerifiedDigest:      rls.VerifiedDigest,
+		VerificationDigest:  rls.VerificationDigest,
+		Backup:              rls.Backup,
 	}
 }
 
+// maxStatusReasonLength is the maximum length in characters of the
+// v2.Snapshot.StatusReason.
+const maxStatusReasonLength = 256
+
+// statusReason returns the given description of a Helm release as the
+// reason for its status, truncated to maxStatusReasonLength characters.
+func statusReason(description string) string {
+	description = strings.TrimSpace(description)
+	if r := []rune(description); len(r) > maxStatusReasonLength {
+		return string(r[:maxStatusReasonLength-3]) + "..."
+	}
+	return description
+}
+
 // TestHooksFromRelease returns the list of v2.TestHookStatus for the
 // given release, indexed by name.
 func TestHooksFromRelease(rls *helmrelease.Release) map[string]*v2.TestHookStatus {
@@ -190,3 +268,31 @@ func TestHooksFromRelease(rls *helmrelease.Release) map[string]*v2.TestHookStatu
 	}
 	return hooks
 }
+
+// HooksFromRelease returns the list of v2.HookResource for the hooks of the
+// given release, in the order they were rendered.
+func HooksFromRelease(rls *helmrelease.Release) []v2.HookResource {
+	if rls == nil || len(rls.Hooks) == 0 {
+		return nil
+	}
+	hooks := make([]v2.HookResource, 0, len(rls.Hooks))
+	for _, h := range rls.Hooks {
+		if h == nil {
+			continue
+		}
+		hr := v2.HookResource{
+			Name:   h.Name,
+			Kind:   h.Kind,
+			Path:   h.Path,
+			Weight: h.Weight,
+		}
+		for _, e := range h.Events {
+			hr.Events = append(hr.Events, e.String())
+		}
+		for _, p := range h.DeletePolicies {
+			hr.DeletePolicies = append(hr.DeletePolicies, p.String())
+		}
+		hooks = append(hooks, hr)
+	}
+	return hooks
+}
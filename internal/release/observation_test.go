@@ -18,7 +18,9 @@ package release
 
 import (
 	"bytes"
+	"strings"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 	"github.com/opencontainers/go-digest"
@@ -308,6 +310,7 @@ func TestObservedToSnapshot(t *testing.T) {
 	g.Expect(got.ChartName).To(Equal(obs.ChartMetadata.Name))
 	g.Expect(got.ChartVersion).To(Equal(obs.ChartMetadata.Version))
 	g.Expect(got.Status).To(BeEquivalentTo(obs.Info.Status))
+	g.Expect(got.StatusReason).To(Equal("Release mock"))
 
 	g.Expect(obs.Info.FirstDeployed.Time.Equal(got.FirstDeployed.Time)).To(BeTrue())
 	g.Expect(obs.Info.LastDeployed.Time.Equal(got.LastDeployed.Time)).To(BeTrue())
@@ -318,6 +321,73 @@ func TestObservedToSnapshot(t *testing.T) {
 
 	g.Expect(got.ConfigDigest).ToNot(BeEmpty())
 	g.Expect(digest.Digest(got.ConfigDigest).Validate()).To(Succeed())
+
+	g.Expect(got.Duration).To(BeNil())
+}
+
+func TestObservedToSnapshot_duration(t *testing.T) {
+	g := NewWithT(t)
+
+	obs := ObserveRelease(testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+		Name:      "foo",
+		Namespace: "namespace",
+		Version:   1,
+		Chart:     testutil.BuildChart(),
+	}))
+	withoutDuration := ObservedToSnapshot(obs)
+
+	obs.Duration = 1500*time.Millisecond + 300*time.Microsecond
+	got := ObservedToSnapshot(obs)
+
+	g.Expect(got.Duration).ToNot(BeNil())
+	g.Expect(got.Duration.Duration).To(Equal(1500 * time.Millisecond))
+	// The duration does not affect the digest of the release.
+	g.Expect(got.Digest).To(Equal(withoutDuration.Digest))
+}
+
+func TestObservedToSnapshot_statusReason(t *testing.T) {
+	g := NewWithT(t)
+
+	obs := ObserveRelease(testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+		Name:      "foo",
+		Namespace: "namespace",
+		Version:   1,
+		Status:    helmrelease.StatusFailed,
+		Chart:     testutil.BuildChart(),
+	}))
+
+	obs.Info.Description = "Upgrade \"foo\" failed: context deadline exceeded\n"
+	got := ObservedToSnapshot(obs)
+	g.Expect(got.Status).To(Equal(helmrelease.StatusFailed.String()))
+	g.Expect(got.StatusReason).To(Equal("Upgrade \"foo\" failed: context deadline exceeded"))
+
+	obs.Info.Description = strings.Repeat("ä", maxStatusReasonLength+1)
+	got = ObservedToSnapshot(obs)
+	g.Expect([]rune(got.StatusReason)).To(HaveLen(maxStatusReasonLength))
+	g.Expect(got.StatusReason).To(HaveSuffix("..."))
+}
+
+func TestObservedToSnapshot_chartSource(t *testing.T) {
+	g := NewWithT(t)
+
+	obs := ObserveRelease(testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+		Name:      "foo",
+		Namespace: "namespace",
+		Version:   1,
+		Chart:     testutil.BuildChart(),
+	}))
+	withoutSource := ObservedToSnapshot(obs)
+	g.Expect(withoutSource.ChartSourceRevision).To(BeEmpty())
+	g.Expect(withoutSource.ChartDigest).To(BeEmpty())
+
+	obs.ChartSourceRevision = "6.6.1@sha256:9c3bdd4d3e80d6b2b1f8f7a63cd6d41e1a1c0b0b3e7f1d4e7ab4ad3c0d1f7b2e"
+	obs.ChartDigest = "sha256:1b4e8c0e7a7f2d1a5c6b3e9f0d8a7c6b5e4f3a2d1c0b9a8f7e6d5c4b3a2f1e0d"
+	got := ObservedToSnapshot(obs)
+
+	g.Expect(got.ChartSourceRevision).To(Equal(obs.ChartSourceRevision))
+	g.Expect(got.ChartDigest).To(Equal(obs.ChartDigest))
+	// The chart source does not affect the digest of the release.
+	g.Expect(got.Digest).To(Equal(withoutSource.Digest))
 }
 
 func TestTestHooksFromRelease(t *testing.T) {
@@ -371,3 +441,51 @@ func TestTestHooksFromRelease(t *testing.T) {
 		},
 	}))
 }
+
+func TestHooksFromRelease_resources(t *testing.T) {
+	g := NewWithT(t)
+
+	rls := testutil.BuildRelease(&helmrelease.MockReleaseOptions{
+		Name:      "foo",
+		Namespace: "bar",
+		Version:   1,
+		Chart:     testutil.BuildChart(),
+	}, testutil.ReleaseWithHooks([]*helmrelease.Hook{
+		{
+			Name:           "pre-install-job",
+			Kind:           "Job",
+			Path:           "templates/pre-install-job.yaml",
+			Events:         []helmrelease.HookEvent{helmrelease.HookPreInstall, helmrelease.HookPreUpgrade},
+			Weight:         -5,
+			DeletePolicies: []helmrelease.HookDeletePolicy{helmrelease.HookBeforeHookCreation},
+		},
+		{
+			Name:   "test",
+			Kind:   "Pod",
+			Events: []helmrelease.HookEvent{helmrelease.HookTest},
+		},
+	}))
+
+	g.Expect(HooksFromRelease(rls)).To(Equal([]v2.HookResource{
+		{
+			Name:   "pre-install-hook",
+			Kind:   "Job",
+			Path:   "pre-install-hook.yaml",
+			Events: []string{"pre-install"},
+		},
+		{
+			Name:           "pre-install-job",
+			Kind:           "Job",
+			Path:           "templates/pre-install-job.yaml",
+			Events:         []string{"pre-install", "pre-upgrade"},
+			Weight:         -5,
+			DeletePolicies: []string{"before-hook-creation"},
+		},
+		{
+			Name:   "test",
+			Kind:   "Pod",
+			Events: []string{"test"},
+		},
+	}))
+	g.Expect(HooksFromRelease(&helmrelease.Release{})).To(BeNil())
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schedule provides a way to determine if the maintenance windows
+// of a HelmRelease are open.
+package schedule
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+// Evaluate returns true if any of the given windows is open at the given
+// time. If none is open, it returns the time at which the first window
+// opens next. It returns an error if the schedule of any of the windows
+// cannot be parsed.
+func Evaluate(windows []v2.MaintenanceWindow, now time.Time) (bool, time.Time, error) {
+	var nextOpen time.Time
+	for _, w := range windows {
+		sched, err := parse(w)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+
+		// The first activation after the start of a window which would
+		// still be open now, is either within the window or the next
+		// time it opens.
+		opens := sched.Next(now.Add(-w.Duration.Duration))
+		if opens.IsZero() {
+			continue
+		}
+		if !opens.After(now) {
+			return true, time.Time{}, nil
+		}
+		if nextOpen.IsZero() || opens.Before(nextOpen) {
+			nextOpen = opens
+		}
+	}
+	return false, nextOpen, nil
+}
+
+// Validate returns an error if the schedule of any of the given windows
+// cannot be parsed.
+func Validate(windows []v2.MaintenanceWindow) error {
+	for _, w := range windows {
+		if _, err := parse(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parse parses the cron schedule of the given window.
+func parse(w v2.MaintenanceWindow) (cron.Schedule, error) {
+	sched, err := cron.ParseStandard(w.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("invalid maintenance window schedule '%s': %w", w.Schedule, err)
+	}
+	return sched, nil
+}
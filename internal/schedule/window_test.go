@@ -0,0 +1,127 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func TestEvaluate(t *testing.T) {
+	// Wednesday.
+	now := time.Date(2024, 5, 15, 23, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		windows      []v2.MaintenanceWindow
+		wantOpen     bool
+		wantNextOpen time.Time
+		wantErr      string
+	}{
+		{
+			name: "within window",
+			windows: []v2.MaintenanceWindow{
+				{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+			},
+			wantOpen: true,
+		},
+		{
+			name: "after window closed",
+			windows: []v2.MaintenanceWindow{
+				{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+			wantNextOpen: time.Date(2024, 5, 16, 22, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "window closes at now",
+			windows: []v2.MaintenanceWindow{
+				{Schedule: "30 22 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+			wantNextOpen: time.Date(2024, 5, 16, 22, 30, 0, 0, time.UTC),
+		},
+		{
+			name: "window opens at now",
+			windows: []v2.MaintenanceWindow{
+				{Schedule: "30 23 * * *", Duration: metav1.Duration{Duration: time.Minute}},
+			},
+			wantOpen: true,
+		},
+		{
+			name: "earliest next window",
+			windows: []v2.MaintenanceWindow{
+				{Schedule: "0 2 * * 6", Duration: metav1.Duration{Duration: time.Hour}},
+				{Schedule: "0 1 * * 5", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+			wantNextOpen: time.Date(2024, 5, 17, 1, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "any open window",
+			windows: []v2.MaintenanceWindow{
+				{Schedule: "0 2 * * 6", Duration: metav1.Duration{Duration: time.Hour}},
+				{Schedule: "0 23 * * 3", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+			wantOpen: true,
+		},
+		{
+			name: "window with timezone",
+			windows: []v2.MaintenanceWindow{
+				{Schedule: "CRON_TZ=Europe/Amsterdam 0 1 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+			wantOpen: true,
+		},
+		{
+			name: "invalid schedule",
+			windows: []v2.MaintenanceWindow{
+				{Schedule: "0 22 * *", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+			wantErr: "invalid maintenance window schedule '0 22 * *'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			open, nextOpen, err := Evaluate(tt.windows, now)
+			if tt.wantErr != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tt.wantErr))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(open).To(Equal(tt.wantOpen))
+			g.Expect(nextOpen.Equal(tt.wantNextOpen)).To(BeTrue(), "expected next open %s, got %s", tt.wantNextOpen, nextOpen)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(Validate(nil)).To(Succeed())
+	g.Expect(Validate([]v2.MaintenanceWindow{
+		{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+	})).To(Succeed())
+	g.Expect(Validate([]v2.MaintenanceWindow{
+		{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+		{Schedule: "0 25 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+	})).To(MatchError(ContainSubstring("invalid maintenance window schedule '0 25 * * *'")))
+}
@@ -82,6 +82,28 @@ spec:
   restartPolicy: Never
 `
 
+var manifestWithImmutableConfigMapTmpl = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: immutable
+  namespace: %[1]s
+immutable: true
+data:
+  foo: %[2]s
+`
+
+var manifestWithPodTmpl = `apiVersion: v1
+kind: Pod
+metadata:
+  name: pod
+  namespace: %[1]s
+spec:
+  containers:
+  - name: test
+    image: alpine
+    command: ["/bin/sh", "-c", "sleep infinity"]
+`
+
 // ChartOptions is a helper to build a Helm chart object.
 type ChartOptions struct {
 	*helmchart.Chart
@@ -166,3 +188,36 @@ func ChartWithFailingTestHook() ChartOption {
 		})
 	}
 }
+
+// ChartWithNotes adds a NOTES.txt template with the given content to the
+// chart.
+func ChartWithNotes(notes string) ChartOption {
+	return func(opts *ChartOptions) {
+		opts.Templates = append(opts.Templates, &helmchart.File{
+			Name: "templates/NOTES.txt",
+			Data: []byte(notes),
+		})
+	}
+}
+
+// ChartWithImmutableConfigMap appends an immutable ConfigMap with the given
+// data value to the chart.
+func ChartWithImmutableConfigMap(value string) ChartOption {
+	return func(opts *ChartOptions) {
+		opts.Templates = append(opts.Templates, &helmchart.File{
+			Name: "templates/immutable",
+			Data: []byte(fmt.Sprintf(manifestWithImmutableConfigMapTmpl, "{{ default .Release.Namespace }}", value)),
+		})
+	}
+}
+
+// ChartWithPod appends a Pod to the chart, which does not become ready
+// without a kubelet running it.
+func ChartWithPod() ChartOption {
+	return func(opts *ChartOptions) {
+		opts.Templates = append(opts.Templates, &helmchart.File{
+			Name: "templates/pod",
+			Data: []byte(fmt.Sprintf(manifestWithPodTmpl, "{{ default .Release.Namespace }}")),
+		})
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package validation validates the constraints of a HelmRelease which span
+// multiple fields, or depend on the configuration of the controller, and
+// can therefore not be expressed in the CRD schema. It is shared by the
+// admission webhook and the reconciler, to reject the specifications the
+// reconciler would fail on.
+package validation
+
+import (
+	"errors"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	sourcev1 "github.com/fluxcd/source-controller/api/v1"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	intacl "github.com/fluxcd/helm-controller/internal/acl"
+	"github.com/fluxcd/helm-controller/internal/schedule"
+)
+
+// ErrInvalidChartReference is returned when a HelmRelease does not refer to
+// its chart using exactly one of chart or chartRef.
+var ErrInvalidChartReference = errors.New("either chart or chartRef must be set")
+
+// HelmRelease returns the violations of the constraints of the given
+// HelmRelease.
+func HelmRelease(obj *v2.HelmRelease) field.ErrorList {
+	spec := field.NewPath("spec")
+
+	var errs field.ErrorList
+	if err := ChartReference(obj); err != nil {
+		errs = append(errs, field.Invalid(spec.Child("chartRef"), obj.Spec.ChartRef, err.Error()))
+	}
+	errs = append(errs, SourceReferences(obj)...)
+	errs = append(errs, Remediation(obj)...)
+	errs = append(errs, StorageDriver(obj)...)
+	for i, w := range obj.Spec.MaintenanceWindows {
+		if err := schedule.Validate([]v2.MaintenanceWindow{w}); err != nil {
+			errs = append(errs, field.Invalid(spec.Child("maintenanceWindows").Index(i).Child("schedule"), w.Schedule, err.Error()))
+		}
+	}
+	if err := obj.GetUpgrade().ValidateValuesOptions(); err != nil {
+		errs = append(errs, field.Forbidden(spec.Child("upgrade", "resetValues"), err.Error()))
+	}
+	return errs
+}
+
+// ChartReference returns ErrInvalidChartReference if the given HelmRelease
+// does not refer to its chart using exactly one of chart or chartRef.
+func ChartReference(obj *v2.HelmRelease) error {
+	if obj.HasChartRef() == obj.HasChartTemplate() {
+		return ErrInvalidChartReference
+	}
+	return nil
+}
+
+// SourceReferences returns the references of the given HelmRelease to a
+// chart source, or to values, which are denied by the ACL policy of the
+// controller. This includes source kinds which are not allowed, and
+// cross-namespace references while these are not allowed.
+func SourceReferences(obj *v2.HelmRelease) field.ErrorList {
+	spec := field.NewPath("spec")
+
+	var errs field.ErrorList
+	switch {
+	case obj.HasChartRef():
+		ref, path := obj.Spec.ChartRef, spec.Child("chartRef")
+		if err := intacl.AllowsSourceKind(ref.Kind); err != nil {
+			errs = append(errs, field.Forbidden(path.Child("kind"), err.Error()))
+		}
+		namespace := ref.Namespace
+		if namespace == "" {
+			namespace = obj.GetNamespace()
+		}
+		if err := intacl.AllowsAccessTo(obj, ref.Kind, types.NamespacedName{Namespace: namespace, Name: ref.Name}); err != nil {
+			errs = append(errs, field.Forbidden(path.Child("namespace"), err.Error()))
+		}
+	case obj.HasChartTemplate():
+		ref, path := obj.Spec.Chart.Spec.SourceRef, spec.Child("chart", "spec", "sourceRef")
+		if err := intacl.AllowsSourceKind(ref.Kind); err != nil {
+			errs = append(errs, field.Forbidden(path.Child("kind"), err.Error()))
+		}
+		name := types.NamespacedName{Namespace: obj.Spec.Chart.GetNamespace(obj.GetNamespace()), Name: obj.GetHelmChartName()}
+		if err := intacl.AllowsAccessTo(obj, sourcev1.HelmChartKind, name); err != nil {
+			errs = append(errs, field.Forbidden(path.Child("namespace"), err.Error()))
+		}
+	}
+
+	for i, ref := range obj.Spec.ValuesFrom {
+		name := types.NamespacedName{Namespace: ref.GetNamespace(obj.GetNamespace()), Name: ref.Name}
+		if err := intacl.AllowsAccessTo(obj, ref.Kind, name); err != nil {
+			errs = append(errs, field.Forbidden(spec.Child("valuesFrom").Index(i).Child("namespace"), err.Error()))
+		}
+	}
+	return errs
+}
+
+// Remediation returns the configuration of the given HelmRelease which
+// conflicts with the remediation strategy of the Helm upgrade action.
+func Remediation(obj *v2.HelmRelease) field.ErrorList {
+	spec := field.NewPath("spec")
+
+	upgrade := obj.GetUpgrade()
+	if upgrade.GetRemediation().GetStrategy() != v2.UninstallRemediationStrategy {
+		return nil
+	}
+
+	var errs field.ErrorList
+	if upgrade.GetRemediation().MustKeepFailedResources() {
+		errs = append(errs, field.Forbidden(spec.Child("upgrade", "remediation", "keepFailedResources"),
+			"keepFailedResources can not be combined with the uninstall remediation strategy"))
+	}
+	if obj.GetRollback().ToVersion > 0 {
+		errs = append(errs, field.Forbidden(spec.Child("rollback", "toVersion"),
+			"toVersion can not be combined with the uninstall remediation strategy"))
+	}
+	return errs
+}
+
+// StorageDriver returns the storage driver of the given HelmRelease if it is
+// not supported. This guards against objects stored before the CRD schema
+// restricted the storage driver.
+func StorageDriver(obj *v2.HelmRelease) field.ErrorList {
+	switch obj.Spec.StorageDriver {
+	case "", v2.StorageDriverSecret, v2.StorageDriverConfigMap:
+		return nil
+	default:
+		return field.ErrorList{field.NotSupported(field.NewPath("spec", "storageDriver"), obj.Spec.StorageDriver,
+			[]string{v2.StorageDriverSecret, v2.StorageDriverConfigMap})}
+	}
+}
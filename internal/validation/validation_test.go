@@ -0,0 +1,228 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	intacl "github.com/fluxcd/helm-controller/internal/acl"
+)
+
+func newHelmRelease() *v2.HelmRelease {
+	return &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: v2.HelmReleaseSpec{
+			Chart: &v2.HelmChartTemplate{
+				Spec: v2.HelmChartTemplateSpec{
+					Chart:     "podinfo",
+					SourceRef: v2.CrossNamespaceObjectReference{Kind: "HelmRepository", Name: "podinfo"},
+				},
+			},
+		},
+	}
+}
+
+func TestHelmRelease(t *testing.T) {
+	tests := []struct {
+		name               string
+		mutate             func(obj *v2.HelmRelease)
+		noCrossNamespace   bool
+		allowedSourceKinds []string
+		wantFields         []string
+	}{
+		{
+			name: "valid",
+			mutate: func(obj *v2.HelmRelease) {
+				obj.Spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{Strategy: ptr.To(v2.RollbackRemediationStrategy)},
+				}
+				obj.Spec.Rollback = &v2.Rollback{ToVersion: 3}
+				obj.Spec.MaintenanceWindows = []v2.MaintenanceWindow{
+					{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+				}
+			},
+		},
+		{
+			name: "chart and chartRef",
+			mutate: func(obj *v2.HelmRelease) {
+				obj.Spec.ChartRef = &v2.CrossNamespaceSourceReference{Kind: "OCIRepository", Name: "podinfo"}
+			},
+			wantFields: []string{"spec.chartRef"},
+		},
+		{
+			name: "neither chart nor chartRef",
+			mutate: func(obj *v2.HelmRelease) {
+				obj.Spec.Chart = nil
+			},
+			wantFields: []string{"spec.chartRef"},
+		},
+		{
+			name: "source kind not allowed",
+			mutate: func(obj *v2.HelmRelease) {
+				obj.Spec.Chart = nil
+				obj.Spec.ChartRef = &v2.CrossNamespaceSourceReference{Kind: "HelmChart", Name: "podinfo"}
+			},
+			allowedSourceKinds: []string{"OCIRepository"},
+			wantFields:         []string{"spec.chartRef.kind"},
+		},
+		{
+			name: "cross-namespace references not allowed",
+			mutate: func(obj *v2.HelmRelease) {
+				obj.Spec.Chart.Spec.SourceRef.Namespace = "flux-system"
+				obj.Spec.ValuesFrom = []v2.ValuesReference{
+					{Kind: "ConfigMap", Name: "values"},
+					{Kind: "Secret", Name: "values", Namespace: "flux-system"},
+				}
+			},
+			noCrossNamespace: true,
+			wantFields:       []string{"spec.chart.spec.sourceRef.namespace", "spec.valuesFrom[1].namespace"},
+		},
+		{
+			name: "keepFailedResources with uninstall strategy",
+			mutate: func(obj *v2.HelmRelease) {
+				obj.Spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{
+						Strategy:            ptr.To(v2.UninstallRemediationStrategy),
+						KeepFailedResources: true,
+					},
+				}
+			},
+			wantFields: []string{"spec.upgrade.remediation.keepFailedResources"},
+		},
+		{
+			name: "rollback toVersion with uninstall strategy",
+			mutate: func(obj *v2.HelmRelease) {
+				obj.Spec.Upgrade = &v2.Upgrade{
+					Remediation: &v2.UpgradeRemediation{Strategy: ptr.To(v2.UninstallRemediationStrategy)},
+				}
+				obj.Spec.Rollback = &v2.Rollback{ToVersion: 3}
+			},
+			wantFields: []string{"spec.rollback.toVersion"},
+		},
+		{
+			name: "invalid maintenance window schedule",
+			mutate: func(obj *v2.HelmRelease) {
+				obj.Spec.MaintenanceWindows = []v2.MaintenanceWindow{
+					{Schedule: "0 22 * * *", Duration: metav1.Duration{Duration: 2 * time.Hour}},
+					{Schedule: "every night", Duration: metav1.Duration{Duration: time.Hour}},
+				}
+			},
+			wantFields: []string{"spec.maintenanceWindows[1].schedule"},
+		},
+		{
+			name: "memory storage driver",
+			mutate: func(obj *v2.HelmRelease) {
+				obj.Spec.StorageDriver = "memory"
+			},
+			wantFields: []string{"spec.storageDriver"},
+		},
+		{
+			name: "resetValues with reuseValues",
+			mutate: func(obj *v2.HelmRelease) {
+				obj.Spec.Upgrade = &v2.Upgrade{ResetValues: true, ReuseValues: true}
+			},
+			wantFields: []string{"spec.upgrade.resetValues"},
+		},
+		{
+			name: "multiple violations",
+			mutate: func(obj *v2.HelmRelease) {
+				obj.Spec.Upgrade = &v2.Upgrade{
+					ResetValues:    true,
+					PreserveValues: true,
+					Remediation: &v2.UpgradeRemediation{
+						Strategy:            ptr.To(v2.UninstallRemediationStrategy),
+						KeepFailedResources: true,
+					},
+				}
+				obj.Spec.MaintenanceWindows = []v2.MaintenanceWindow{{Schedule: "* * *"}}
+			},
+			wantFields: []string{
+				"spec.upgrade.remediation.keepFailedResources",
+				"spec.maintenanceWindows[0].schedule",
+				"spec.upgrade.resetValues",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			curAllow, curAllowed := intacl.AllowCrossNamespaceRef, intacl.AllowedSourceKinds
+			intacl.AllowCrossNamespaceRef, intacl.AllowedSourceKinds = !tt.noCrossNamespace, tt.allowedSourceKinds
+			t.Cleanup(func() { intacl.AllowCrossNamespaceRef, intacl.AllowedSourceKinds = curAllow, curAllowed })
+
+			obj := newHelmRelease()
+			if tt.mutate != nil {
+				tt.mutate(obj)
+			}
+
+			var fields []string
+			for _, err := range HelmRelease(obj) {
+				fields = append(fields, err.Field)
+			}
+			g.Expect(fields).To(Equal(tt.wantFields))
+		})
+	}
+}
+
+func TestChartReference(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := newHelmRelease()
+	g.Expect(ChartReference(obj)).To(Succeed())
+
+	obj.Spec.ChartRef = &v2.CrossNamespaceSourceReference{Kind: "OCIRepository", Name: "podinfo"}
+	g.Expect(ChartReference(obj)).To(MatchError(ErrInvalidChartReference))
+
+	obj.Spec.Chart = nil
+	g.Expect(ChartReference(obj)).To(Succeed())
+}
+
+func TestRemediation(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := newHelmRelease()
+	obj.Spec.Rollback = &v2.Rollback{ToVersion: 3}
+	obj.Spec.Upgrade = &v2.Upgrade{Remediation: &v2.UpgradeRemediation{KeepFailedResources: true}}
+	g.Expect(Remediation(obj)).To(BeEmpty())
+
+	obj.Spec.Upgrade.Remediation.Strategy = ptr.To(v2.UninstallRemediationStrategy)
+	errs := Remediation(obj)
+	g.Expect(errs).To(HaveLen(2))
+	g.Expect(errs.ToAggregate()).To(MatchError(ContainSubstring("keepFailedResources can not be combined with the uninstall remediation strategy")))
+}
+
+func TestStorageDriver(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := newHelmRelease()
+	g.Expect(StorageDriver(obj)).To(BeEmpty())
+
+	obj.Spec.StorageDriver = v2.StorageDriverConfigMap
+	g.Expect(StorageDriver(obj)).To(BeEmpty())
+
+	obj.Spec.StorageDriver = "memory"
+	errs := StorageDriver(obj)
+	g.Expect(errs).To(HaveLen(1))
+	g.Expect(errs.ToAggregate()).To(MatchError(ContainSubstring(`Unsupported value: "memory"`)))
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package webhook provides the validating admission webhook for
+// HelmReleases, which rejects the specifications the reconciler would fail
+// on at admission time.
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+	"github.com/fluxcd/helm-controller/internal/validation"
+)
+
+// HelmReleaseValidator validates HelmReleases on admission, using the
+// validation shared with the reconciler.
+//
+// The webhook is served for the v2 API version. Registered with the
+// "Equivalent" match policy, objects of the other served API versions are
+// converted to v2 by the API server before they are validated.
+type HelmReleaseValidator struct {
+	// DryRun makes the validator admit invalid HelmReleases, while returning
+	// the violations as warnings to the client. This allows observing the
+	// effect of the webhook before enforcing it.
+	DryRun bool
+}
+
+// SetupWebhookWithManager registers the validator with the webhook server
+// of the given manager.
+func (v *HelmReleaseValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&v2.HelmRelease{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate validates the HelmRelease on creation.
+func (v *HelmReleaseValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	hr, ok := obj.(*v2.HelmRelease)
+	if !ok {
+		return nil, fmt.Errorf("expected a HelmRelease but got %T", obj)
+	}
+	return v.validate(hr)
+}
+
+// ValidateUpdate validates the HelmRelease on update. Updates which do not
+// change the spec, or are made while the HelmRelease is being deleted, are
+// always admitted, to not block e.g. the removal of the finalizer of a
+// HelmRelease which became invalid due to a change of the configuration of
+// the controller.
+func (v *HelmReleaseValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldHR, ok := oldObj.(*v2.HelmRelease)
+	if !ok {
+		return nil, fmt.Errorf("expected a HelmRelease but got %T", oldObj)
+	}
+	newHR, ok := newObj.(*v2.HelmRelease)
+	if !ok {
+		return nil, fmt.Errorf("expected a HelmRelease but got %T", newObj)
+	}
+	if !newHR.DeletionTimestamp.IsZero() || apiequality.Semantic.DeepEqual(oldHR.Spec, newHR.Spec) {
+		return nil, nil
+	}
+	return v.validate(newHR)
+}
+
+// ValidateDelete admits the deletion of any HelmRelease.
+func (v *HelmReleaseValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate returns an Invalid error for the violations of the constraints
+// of the given HelmRelease, or warnings when running in dry-run mode.
+func (v *HelmReleaseValidator) validate(obj *v2.HelmRelease) (admission.Warnings, error) {
+	errs := validation.HelmRelease(obj)
+	if len(errs) == 0 {
+		return nil, nil
+	}
+	if v.DryRun {
+		warnings := make(admission.Warnings, 0, len(errs))
+		for _, err := range errs {
+			warnings = append(warnings, err.Error())
+		}
+		return warnings, nil
+	}
+	return nil, apierrors.NewInvalid(v2.GroupVersion.WithKind(v2.HelmReleaseKind).GroupKind(), obj.GetName(), errs)
+}
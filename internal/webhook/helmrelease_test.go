@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Flux authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+
+	v2 "github.com/fluxcd/helm-controller/api/v2"
+)
+
+func newHelmRelease() *v2.HelmRelease {
+	return &v2.HelmRelease{
+		ObjectMeta: metav1.ObjectMeta{Name: "podinfo", Namespace: "default"},
+		Spec: v2.HelmReleaseSpec{
+			ChartRef: &v2.CrossNamespaceSourceReference{Kind: "OCIRepository", Name: "podinfo"},
+		},
+	}
+}
+
+func newInvalidHelmRelease() *v2.HelmRelease {
+	obj := newHelmRelease()
+	obj.Spec.Upgrade = &v2.Upgrade{
+		Remediation: &v2.UpgradeRemediation{Strategy: ptr.To(v2.UninstallRemediationStrategy)},
+	}
+	obj.Spec.Rollback = &v2.Rollback{ToVersion: 2}
+	obj.Spec.MaintenanceWindows = []v2.MaintenanceWindow{
+		{Schedule: "0 25 * * *", Duration: metav1.Duration{Duration: time.Hour}},
+	}
+	return obj
+}
+
+func TestHelmReleaseValidator_ValidateCreate(t *testing.T) {
+	t.Run("admits valid HelmRelease", func(t *testing.T) {
+		g := NewWithT(t)
+
+		warnings, err := (&HelmReleaseValidator{}).ValidateCreate(context.TODO(), newHelmRelease())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(warnings).To(BeEmpty())
+	})
+
+	t.Run("rejects invalid HelmRelease", func(t *testing.T) {
+		g := NewWithT(t)
+
+		warnings, err := (&HelmReleaseValidator{}).ValidateCreate(context.TODO(), newInvalidHelmRelease())
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+		g.Expect(err.Error()).To(ContainSubstring("spec.rollback.toVersion: Forbidden: toVersion can not be combined with the uninstall remediation strategy"))
+		g.Expect(err.Error()).To(ContainSubstring("spec.maintenanceWindows[0].schedule: Invalid value: \"0 25 * * *\""))
+		g.Expect(warnings).To(BeEmpty())
+	})
+
+	t.Run("warns about invalid HelmRelease in dry-run mode", func(t *testing.T) {
+		g := NewWithT(t)
+
+		warnings, err := (&HelmReleaseValidator{DryRun: true}).ValidateCreate(context.TODO(), newInvalidHelmRelease())
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(warnings).To(HaveLen(2))
+		g.Expect(warnings[0]).To(HavePrefix("spec.rollback.toVersion: Forbidden"))
+	})
+
+	t.Run("rejects other object", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := (&HelmReleaseValidator{}).ValidateCreate(context.TODO(), &v2.HelmReleaseGroup{})
+		g.Expect(err).To(MatchError(ContainSubstring("expected a HelmRelease")))
+	})
+}
+
+func TestHelmReleaseValidator_ValidateUpdate(t *testing.T) {
+	t.Run("rejects invalid spec change", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := (&HelmReleaseValidator{}).ValidateUpdate(context.TODO(), newHelmRelease(), newInvalidHelmRelease())
+		g.Expect(apierrors.IsInvalid(err)).To(BeTrue())
+	})
+
+	t.Run("admits update without spec change", func(t *testing.T) {
+		g := NewWithT(t)
+
+		oldObj, newObj := newInvalidHelmRelease(), newInvalidHelmRelease()
+		newObj.Finalizers = []string{v2.HelmReleaseFinalizer}
+
+		_, err := (&HelmReleaseValidator{}).ValidateUpdate(context.TODO(), oldObj, newObj)
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+
+	t.Run("admits update of deleted object", func(t *testing.T) {
+		g := NewWithT(t)
+
+		newObj := newInvalidHelmRelease()
+		newObj.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+		_, err := (&HelmReleaseValidator{}).ValidateUpdate(context.TODO(), newHelmRelease(), newObj)
+		g.Expect(err).ToNot(HaveOccurred())
+	})
+}
+
+func TestHelmReleaseValidator_ValidateDelete(t *testing.T) {
+	g := NewWithT(t)
+
+	_, err := (&HelmReleaseValidator{}).ValidateDelete(context.TODO(), newInvalidHelmRelease())
+	g.Expect(err).ToNot(HaveOccurred())
+}
@@ -28,12 +28,14 @@ import (
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
+	kuberecorder "k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
 	ctrlcache "sigs.k8s.io/controller-runtime/pkg/cache"
 	ctrlclient "sigs.k8s.io/controller-runtime/pkg/client"
 	ctrlcfg "sigs.k8s.io/controller-runtime/pkg/config"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	ctrlwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	"github.com/fluxcd/pkg/runtime/acl"
 	"github.com/fluxcd/pkg/runtime/client"
@@ -56,9 +58,12 @@ import (
 
 	intacl "github.com/fluxcd/helm-controller/internal/acl"
 	"github.com/fluxcd/helm-controller/internal/controller"
+	"github.com/fluxcd/helm-controller/internal/defaults"
 	"github.com/fluxcd/helm-controller/internal/features"
 	intkube "github.com/fluxcd/helm-controller/internal/kube"
 	"github.com/fluxcd/helm-controller/internal/oomwatch"
+	intreconcile "github.com/fluxcd/helm-controller/internal/reconcile"
+	"github.com/fluxcd/helm-controller/internal/webhook"
 )
 
 const controllerName = "helm-controller"
@@ -100,6 +105,17 @@ func main() {
 		oomWatchMaxMemoryPath     string
 		oomWatchCurrentMemoryPath string
 		snapshotDigestAlgo        string
+		allowedSourceKinds        []string
+		deniedValuesPaths         []string
+		allowedReconcileHookHosts []string
+		capabilityProfilesFile    string
+		summaryEvents             bool
+		timelineEvents            bool
+		eventDedupInterval        time.Duration
+		concurrentPerSource       int
+		webhookPort               int
+		webhookCertDir            string
+		validationWebhookDryRun   bool
 	)
 
 	flag.StringVar(&metricsAddr, "metrics-addr", ":8080",
@@ -110,6 +126,8 @@ func main() {
 		"The address the health endpoint binds to.")
 	flag.IntVar(&concurrent, "concurrent", 4,
 		"The number of concurrent HelmRelease reconciles.")
+	flag.IntVar(&concurrentPerSource, "concurrent-per-source", 0,
+		"The maximum number of HelmReleases sharing the same chart source which run Helm actions at the same time, the rest is requeued. Defaults to 0, which does not limit the concurrency per source.")
 	flag.DurationVar(&requeueDependency, "requeue-dependency", 30*time.Second,
 		"The interval at which failing dependencies are reevaluated.")
 	flag.DurationVar(&gracefulShutdownTimeout, "graceful-shutdown-timeout", 600*time.Second,
@@ -118,6 +136,10 @@ func main() {
 		"The maximum number of retries when failing to fetch artifacts over HTTP.")
 	flag.StringVar(&intkube.DefaultServiceAccountName, "default-service-account", "",
 		"Default service account used for impersonation.")
+	flag.BoolVar(&defaults.NoServiceAccountOverride, "no-service-account-override", false,
+		"Disallow HelmReleases to use a service account other than the default service account for impersonation.")
+	flag.BoolVar(&defaults.DisableWait, "default-disable-wait", false,
+		"Disable waiting for resources to be ready by default for HelmReleases which do not override it.")
 	flag.Uint8Var(&oomWatchMemoryThreshold, "oom-watch-memory-threshold", 95,
 		"The memory threshold in percentage at which the OOM watcher will trigger a graceful shutdown. Requires feature gate 'OOMWatch' to be enabled.")
 	flag.DurationVar(&oomWatchInterval, "oom-watch-interval", 500*time.Millisecond,
@@ -128,6 +150,26 @@ func main() {
 		"The path to the cgroup current memory usage file. Requires feature gate 'OOMWatch' to be enabled. If not set, the path will be automatically detected.")
 	flag.StringVar(&snapshotDigestAlgo, "snapshot-digest-algo", intdigest.Canonical.String(),
 		"The algorithm to use to calculate the digest of Helm release storage snapshots.")
+	flag.StringSliceVar(&allowedSourceKinds, "allowed-source-kinds", nil,
+		"The source kinds HelmReleases are allowed to reference (e.g. OCIRepository). Defaults to allowing all kinds.")
+	flag.StringSliceVar(&deniedValuesPaths, "denied-values-paths", nil,
+		"The dot-notation paths of values HelmReleases are not allowed to set (e.g. hostNetwork or **.securityContext). A '*' segment matches any single key, and '**' any number of nested keys.")
+	flag.StringSliceVar(&allowedReconcileHookHosts, "allowed-reconcile-hook-hosts", nil,
+		"The hosts (e.g. hooks.example.com or hooks.example.com:8443) the reconcile hooks of HelmReleases are allowed to send requests to. Defaults to not allowing reconcile hooks.")
+	flag.StringVar(&capabilityProfilesFile, "capability-profiles-file", "",
+		"The path to a YAML file with a list of capability profiles HelmReleases can refer to by name.")
+	flag.BoolVar(&summaryEvents, "summary-events", false,
+		"Emit a single consolidated event per HelmRelease reconciliation instead of an event for each successful action. Failures are still emitted as distinct events.")
+	flag.BoolVar(&timelineEvents, "timeline-events", false,
+		"Record native Kubernetes Events on the HelmRelease for the start and end of each Helm action. These events are not forwarded to the notification-controller.")
+	flag.DurationVar(&eventDedupInterval, "event-dedup-interval", 0,
+		"The interval at which identical failure events of a HelmRelease are emitted again. A different failure or a successful action is always emitted. Defaults to 0, which disables the suppression of identical events.")
+	flag.IntVar(&webhookPort, "webhook-port", ctrlwebhook.DefaultPort,
+		"The port the validating admission webhook server binds to. Only used when the ValidationWebhook feature gate is enabled.")
+	flag.StringVar(&webhookCertDir, "webhook-cert-dir", "",
+		"The directory containing the tls.crt and tls.key of the validating admission webhook server. Defaults to <tmp>/k8s-webhook-server/serving-certs.")
+	flag.BoolVar(&validationWebhookDryRun, "validation-webhook-dry-run", false,
+		"Admit invalid HelmReleases in the validating admission webhook, while returning the violations as warnings to the client.")
 
 	clientOptions.BindFlags(flag.CommandLine)
 	logOptions.BindFlags(flag.CommandLine)
@@ -186,6 +228,9 @@ func main() {
 
 	// Configure the ACL policy.
 	intacl.AllowCrossNamespaceRef = !aclOptions.NoCrossNamespaceRefs
+	intacl.AllowedSourceKinds = allowedSourceKinds
+	intacl.DeniedValuesPaths = deniedValuesPaths
+	intacl.AllowedReconcileHookHosts = allowedReconcileHookHosts
 
 	// Configure the digest algorithm.
 	if snapshotDigestAlgo != intdigest.Canonical.String() {
@@ -197,6 +242,16 @@ func main() {
 		intdigest.Canonical = algo
 	}
 
+	// Load the capability profiles configured at controller level.
+	if capabilityProfilesFile != "" {
+		profiles, err := defaults.LoadCapabilityProfiles(capabilityProfilesFile)
+		if err != nil {
+			setupLog.Error(err, "unable to load capability profiles")
+			os.Exit(1)
+		}
+		defaults.CapabilityProfiles = profiles
+	}
+
 	restConfig := client.GetConfigOrDie(clientOptions)
 
 	mgrConfig := ctrl.Options{
@@ -230,6 +285,22 @@ func main() {
 		},
 	}
 
+	validationWebhook, _ := features.Enabled(features.ValidationWebhook)
+	if validationWebhook {
+		mgrConfig.WebhookServer = ctrlwebhook.NewServer(ctrlwebhook.Options{
+			Port:    webhookPort,
+			CertDir: webhookCertDir,
+		})
+	}
+
+	// Restrict the watch of ConfigMaps and Secrets for changes to embedded
+	// charts to the labeled ones, unless they are cached for all reads.
+	if !shouldCache {
+		for obj, byObject := range controller.EmbeddedChartCacheByObject() {
+			mgrConfig.Cache.ByObject[obj] = byObject
+		}
+	}
+
 	if watchNamespace != "" {
 		mgrConfig.Cache.DefaultNamespaces = map[string]ctrlcache.Config{
 			watchNamespace: ctrlcache.Config{},
@@ -268,23 +339,55 @@ func main() {
 		ctx = ow.Watch(ctx)
 	}
 
+	var releaseEventRecorder kuberecorder.EventRecorder = eventRecorder
+	if eventDedupInterval > 0 {
+		releaseEventRecorder = intreconcile.NewDedupEventRecorder(eventRecorder, eventDedupInterval)
+	}
+
+	var timelineEventRecorder kuberecorder.EventRecorder
+	if timelineEvents {
+		timelineEventRecorder = mgr.GetEventRecorderFor(controllerName)
+	}
+
 	if err = (&controller.HelmReleaseReconciler{
-		Client:           mgr.GetClient(),
-		APIReader:        mgr.GetAPIReader(),
-		EventRecorder:    eventRecorder,
-		Metrics:          metricsH,
-		GetClusterConfig: ctrl.GetConfig,
-		ClientOpts:       clientOptions,
-		KubeConfigOpts:   kubeConfigOpts,
-		FieldManager:     controllerName,
+		Client:                mgr.GetClient(),
+		APIReader:             mgr.GetAPIReader(),
+		EventRecorder:         releaseEventRecorder,
+		Metrics:               metricsH,
+		GetClusterConfig:      ctrl.GetConfig,
+		ClientOpts:            clientOptions,
+		KubeConfigOpts:        kubeConfigOpts,
+		FieldManager:          controllerName,
+		SummaryEvents:         summaryEvents,
+		TimelineEventRecorder: timelineEventRecorder,
 	}).SetupWithManager(ctx, mgr, controller.HelmReleaseReconcilerOptions{
 		DependencyRequeueInterval: requeueDependency,
 		HTTPRetry:                 httpRetry,
 		RateLimiter:               helper.GetRateLimiter(rateLimiterOptions),
+		ConcurrentPerSource:       concurrentPerSource,
 	}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", v2.HelmReleaseKind)
 		os.Exit(1)
 	}
+
+	if ok, _ := features.Enabled(features.ReleaseGroups); ok {
+		if err = (&controller.HelmReleaseGroupReconciler{
+			Client:       mgr.GetClient(),
+			FieldManager: controllerName,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", v2.HelmReleaseGroupKind)
+			os.Exit(1)
+		}
+	}
+
+	if validationWebhook {
+		if err = (&webhook.HelmReleaseValidator{
+			DryRun: validationWebhookDryRun,
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", v2.HelmReleaseKind)
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
 	setupLog.Info("starting manager")